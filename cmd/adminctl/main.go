@@ -0,0 +1,143 @@
+// adminctl 以本機 AWS 認證直接操作 repository 與排程，給習慣用終端機而非 admin API 的維運人員使用。
+// table 與 function 名稱沿用 Lambda 的環境變數：
+//
+//	export VOCABULARY_TABLE_NAME=language-assistant-dev-vocabulary USER_TABLE_NAME=language-assistant-dev-user
+//	export AUDIT_TABLE_NAME=language-assistant-dev-schedule-audit
+//	export VOCABULARY_FUNCTION_ARN=arn:aws:lambda:...:function:language-vocabulary SCHEDULER_ROLE_ARN=arn:aws:iam::...
+//
+//	go run ./cmd/adminctl user get U1234
+//	go run ./cmd/adminctl user set U1234 -daily-words 5 -push-time 07:30
+//	go run ./cmd/adminctl push U1234 -dry-run -words 3
+//	go run ./cmd/adminctl schedule get|create|delete U1234
+//	go run ./cmd/adminctl bloom show|rebuild U1234 toeic
+//	go run ./cmd/adminctl bloom backfill -course toeic -dry-run
+//	go run ./cmd/adminctl migrate list
+//	go run ./cmd/adminctl migrate run 20250601-per-word-items -dry-run
+//	go run ./cmd/adminctl parse-failures translation -limit 10
+//	go run ./cmd/adminctl kill-switch disable tts -reason "TTS cost spike"
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `usage: adminctl <command> [arguments]
+
+commands:
+  user get <userId>                     show a user's config
+  user set <userId> [flags]             update course, level, push settings, card format, beta status or plan
+  user receipts <userId>                list the user's confirmed payments, newest first
+  push <userId> [flags]                 trigger the daily word push now, or preview it with -dry-run
+  schedule get|create|delete <userId>   inspect or manage the daily push schedule
+  bloom show <userId> <course>          show pushed-word bloom filter stats
+  bloom rebuild <userId> <course>       rebuild the bloom filter from word history and push logs
+  bloom backfill [flags]                seed every user's pushed-word history and bloom filter from their vocabulary
+  migrate list                          list registered migrations
+  migrate run <migrationId> [flags]     run a migration from its checkpoint
+  migrate verify <migrationId>          count items still pending and the migration's totals
+  parse-failures <feature> [flags]      show recent OpenAI responses that failed to parse, e.g. translation
+  kill-switch list                      show which subsystems (tts, daily-push) are disabled
+  kill-switch disable <switch> -reason  turn a subsystem off within a minute, without a deploy
+  kill-switch enable <switch>           turn a subsystem back on`
+
+// app 持有各指令共用的 AWS client，repository 依指令需要的 table 建立
+type app struct {
+	logger    *logrus.Entry
+	dynamodb  *dynamodb.Client
+	lambda    *lambda.Client
+	scheduler *scheduler.Client
+	actor     string // 寫入排程稽核紀錄的操作者
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// CLI 的輸出以結果為主，repository 的 log 只保留警告以上
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+
+	a := &app{
+		logger:    logger.WithField("component", "adminctl"),
+		dynamodb:  dynamodb.NewFromConfig(cfg),
+		lambda:    lambda.NewFromConfig(cfg),
+		scheduler: scheduler.NewFromConfig(cfg),
+		actor:     "adminctl",
+	}
+	if u, err := user.Current(); err == nil {
+		a.actor = "adminctl:" + u.Username
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "user":
+		err = a.user(args)
+	case "push":
+		err = a.push(args)
+	case "schedule":
+		err = a.schedule(args)
+	case "bloom":
+		err = a.bloom(args)
+	case "migrate":
+		err = a.migrate(args)
+	case "parse-failures":
+		err = a.parseFailures(args)
+	case "kill-switch":
+		err = a.killSwitch(args)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// requireEnv 取得必要的環境變數，未設定時直接結束
+func requireEnv(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		log.Fatalf("%s is not set", name)
+	}
+	return value
+}
+
+// subcommand 拆出子指令與其餘參數，缺少時回傳錯誤
+func subcommand(args []string, name string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("%s requires a subcommand\n\n%s", name, usage)
+	}
+	return args[0], args[1:], nil
+}
+
+func printJSON(v any) {
+	output, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(output))
+}
+
+// vocabularyFunction 每日推播的 Lambda，未設定 ARN 時以名稱呼叫
+func vocabularyFunction() *string {
+	if arn := os.Getenv("VOCABULARY_FUNCTION_ARN"); arn != "" {
+		return aws.String(arn)
+	}
+	return aws.String("language-vocabulary")
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"language-assistant/internal/courses"
+	"language-assistant/internal/migrations"
+	"language-assistant/internal/models"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+	"strings"
+	"time"
+)
+
+// bloom 查看或重建已推播單字的 bloom filter，或以單字庫回填所有用戶的已推播單字
+func (a *app) bloom(args []string) error {
+	sub, args, err := subcommand(args, "bloom")
+	if err != nil {
+		return err
+	}
+	if sub == "backfill" {
+		return a.bloomBackfill(args)
+	}
+	if len(args) != 2 {
+		return errors.New("usage: adminctl bloom show|rebuild <userId> <course>")
+	}
+
+	tableName := requireEnv("VOCABULARY_TABLE_NAME")
+	repo := repository.NewBloomFilterRepository(a.logger, a.dynamodb, tableName)
+	switch sub {
+	case "show":
+		return a.bloomShow(repo, args[0], args[1])
+	case "rebuild":
+		rebuilder := utils.NewBloomFilterRebuilder(
+			repo,
+			repository.NewWordHistoryRepository(a.logger, a.dynamodb, tableName),
+			repository.NewPushLogRepository(a.logger, a.dynamodb, tableName),
+		)
+		result, err := rebuilder.Rebuild(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		printJSON(result)
+		return nil
+	default:
+		return fmt.Errorf("unknown bloom subcommand %q", sub)
+	}
+}
+
+// bloomBackfill 以單字庫回填每位用戶目前課程的單字歷史與 bloom filter，讓 bloom filter 上線前透過翻譯學過的單字不再被推播。
+// 個別用戶失敗時記錄後繼續，可重複執行（已回填的單字不會重複寫入）
+func (a *app) bloomBackfill(args []string) error {
+	flags := flag.NewFlagSet("bloom backfill", flag.ContinueOnError)
+	course := flags.String("course", "", "only backfill users of this course (default all courses)")
+	dryRun := flags.Bool("dry-run", false, "count words that would be added without writing")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	courseIDs := []string{}
+	if *course != "" {
+		if _, ok := courses.Get(*course); !ok {
+			return fmt.Errorf("unknown course %q", *course)
+		}
+		courseIDs = append(courseIDs, *course)
+	} else {
+		for _, c := range courses.All() {
+			courseIDs = append(courseIDs, c.ID)
+		}
+	}
+
+	vocabularyTableName := requireEnv("VOCABULARY_TABLE_NAME")
+	userConfigRepo := repository.NewUserConfigRepository(a.logger, a.dynamodb, requireEnv("USER_TABLE_NAME"))
+	backfiller := utils.NewVocabularyBackfiller(
+		repository.NewVocabularyRepository(a.logger, a.dynamodb, vocabularyTableName),
+		repository.NewWordHistoryRepository(a.logger, a.dynamodb, vocabularyTableName),
+		repository.NewBloomFilterRepository(a.logger, a.dynamodb, vocabularyTableName),
+	)
+
+	summary := map[string]int{"users": 0, "failed": 0, "historyAdded": 0, "bloomAdded": 0}
+	for _, courseID := range courseIDs {
+		users, err := userConfigRepo.GetUsersByCourse(courseID)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			result, err := backfiller.Backfill(user.UserID, courseID, utils.LevelBand(courseID, user.Level), *dryRun)
+			if err != nil {
+				summary["failed"]++
+				fmt.Fprintf(os.Stderr, "%s %s: %v\n", user.UserID, courseID, err)
+				continue
+			}
+			summary["users"]++
+			summary["historyAdded"] += result.HistoryAdded
+			summary["bloomAdded"] += result.BloomAdded
+			if result.HistoryAdded > 0 || result.BloomAdded > 0 {
+				printJSON(result)
+			}
+		}
+	}
+
+	printJSON(map[string]any{"dryRun": *dryRun, "summary": summary})
+	if summary["failed"] > 0 {
+		return fmt.Errorf("backfill failed for %d users", summary["failed"])
+	}
+	return nil
+}
+
+// bloomShow 顯示 filter 的使用狀況，填充率過高時誤判（新單字被當成重複而略過）會變多
+func (a *app) bloomShow(repo utils.BloomFilterRepository, userID, course string) error {
+	filter, err := repo.GetBloomFilter(userID, course)
+	if err != nil {
+		return err
+	}
+
+	bitsSet := filter.SetPositions()
+	printJSON(map[string]any{
+		"userId":    filter.UserID,
+		"course":    course,
+		"size":      filter.Size,
+		"hashCount": filter.HashCount,
+		"counting":  filter.IsCounting(),
+		"bitsSet":   bitsSet,
+		"fillRatio": float64(bitsSet) / float64(filter.Size),
+		"updatedAt": filter.UpdatedAt,
+	})
+	return nil
+}
+
+// migrate 與 cmd/migrate、language-migration Lambda 共用 runner 與 checkpoint
+func (a *app) migrate(args []string) error {
+	sub, args, err := subcommand(args, "migrate")
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "list":
+		all := migrations.All()
+		if len(all) == 0 {
+			fmt.Println("No migrations registered")
+		}
+		for _, m := range all {
+			fmt.Printf("%s\t%s\t%s\n", m.ID, m.Table, m.Description)
+		}
+		return nil
+	case "run":
+		if len(args) == 0 {
+			return errors.New("usage: adminctl migrate run <migrationId> [-dry-run] [-reset] [-max-pages N]")
+		}
+		migration, err := migrations.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		flags := flag.NewFlagSet("migrate run", flag.ContinueOnError)
+		dryRun := flags.Bool("dry-run", false, "count items that would change without writing")
+		reset := flags.Bool("reset", false, "discard the saved checkpoint and start from the beginning")
+		maxPages := flags.Int("max-pages", 0, "stop after this many pages (0 = until done)")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		checkpointRepo := repository.NewMigrationRepository(a.logger, a.dynamodb, requireEnv("VOCABULARY_TABLE_NAME"))
+		runner := migrations.NewRunner(a.logger, a.dynamodb, checkpointRepo, migrations.TablesFromEnv(os.Getenv))
+		checkpoint, err := runner.Run(context.Background(), migration, migrations.Options{
+			DryRun:   *dryRun,
+			Reset:    *reset,
+			MaxPages: *maxPages,
+		})
+		if err != nil {
+			return err
+		}
+		printJSON(checkpoint)
+		return nil
+	case "verify":
+		if len(args) == 0 {
+			return errors.New("usage: adminctl migrate verify <migrationId>")
+		}
+		migration, err := migrations.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		checkpointRepo := repository.NewMigrationRepository(a.logger, a.dynamodb, requireEnv("VOCABULARY_TABLE_NAME"))
+		runner := migrations.NewRunner(a.logger, a.dynamodb, checkpointRepo, migrations.TablesFromEnv(os.Getenv))
+		result, err := runner.Verify(context.Background(), migration, 0)
+		if err != nil {
+			return err
+		}
+		printJSON(result)
+		if result.Pending > 0 {
+			return fmt.Errorf("%d items still need migration %s", result.Pending, migration.ID)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q", sub)
+	}
+}
+
+// parseFailures 列出某個 OpenAI 功能最近保存的解析失敗輸出，修正 prompt 前先看實際失敗的樣子
+func (a *app) parseFailures(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: adminctl parse-failures <feature> [-limit N]")
+	}
+	feature := utils.OpenAIFeature(strings.ToUpper(args[0]))
+	switch feature {
+	case utils.FeatureTranslation, utils.FeatureWordGeneration, utils.FeatureArticleSummary, utils.FeatureExampleRegeneration, utils.FeatureGrammarCorrection:
+	default:
+		return fmt.Errorf("unknown OpenAI feature %q", args[0])
+	}
+
+	flags := flag.NewFlagSet("parse-failures", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "number of most recent failures to show")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *limit <= 0 {
+		return errors.New("-limit must be positive")
+	}
+
+	repo := repository.NewParseFailureRepository(a.logger, a.dynamodb, requireEnv("VOCABULARY_TABLE_NAME"))
+	failures, err := repo.GetRecentParseFailures(string(feature), *limit)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		fmt.Println("No parse failures recorded")
+		return nil
+	}
+	printJSON(failures)
+	return nil
+}
+
+// killSwitch 查看或切換高成本子系統的 kill switch，各 Lambda 在 utils.KillSwitchCacheTTL 內生效
+func (a *app) killSwitch(args []string) error {
+	sub, args, err := subcommand(args, "kill-switch")
+	if err != nil {
+		return err
+	}
+	repo := repository.NewKillSwitchRepository(a.logger, a.dynamodb, requireEnv("VOCABULARY_TABLE_NAME"))
+
+	if sub == "list" {
+		states, err := repo.GetKillSwitches()
+		if err != nil {
+			return err
+		}
+		result := make([]models.KillSwitchState, 0, len(models.KillSwitches))
+		for _, sw := range models.KillSwitches {
+			state, ok := states[sw]
+			if !ok {
+				state = models.KillSwitchState{Switch: sw}
+			}
+			result = append(result, state)
+		}
+		printJSON(result)
+		return nil
+	}
+
+	if sub != "disable" && sub != "enable" {
+		return fmt.Errorf("unknown kill-switch subcommand %q", sub)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: adminctl kill-switch %s <switch> [-reason text]", sub)
+	}
+	sw, err := models.ParseKillSwitch(args[0])
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("kill-switch "+sub, flag.ContinueOnError)
+	reason := flags.String("reason", "", "why the subsystem is disabled, e.g. an incident or cost spike (required to disable)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	disabled := sub == "disable"
+	if disabled && strings.TrimSpace(*reason) == "" {
+		return errors.New("-reason is required to disable a subsystem")
+	}
+
+	state := models.KillSwitchState{
+		Switch:    sw,
+		Disabled:  disabled,
+		Reason:    strings.TrimSpace(*reason),
+		UpdatedBy: a.actor,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := repo.SaveKillSwitch(state); err != nil {
+		return err
+	}
+	printJSON(state)
+	return nil
+}
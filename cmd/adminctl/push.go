@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+)
+
+// push 同步呼叫 language-vocabulary，等待推播完成後印出結果；-dry-run 只印出產生的單字，不推播給用戶
+func (a *app) push(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: adminctl push <userId> [-dry-run] [-words N]")
+	}
+
+	flags := flag.NewFlagSet("push", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "generate words without pushing or recording them")
+	words := flags.Int("words", 0, fmt.Sprintf("override the user's daily word count (1-%d, 0 = user setting)", models.MaxWordPushWordCount))
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	payload, err := utils.NewWordPushPayloadWithOptions(args[0], models.WordPushSourceAdminctl, utils.WordPushOptions{
+		DryRun:    *dryRun,
+		WordCount: *words,
+	})
+	if err != nil {
+		return err
+	}
+	output, err := a.lambda.Invoke(context.TODO(), &lambda.InvokeInput{
+		FunctionName:   vocabularyFunction(),
+		InvocationType: lambdatypes.InvocationTypeRequestResponse,
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to invoke language-vocabulary: %w", err)
+	}
+	if output.FunctionError != nil {
+		return fmt.Errorf("language-vocabulary failed (%s): %s", aws.ToString(output.FunctionError), output.Payload)
+	}
+
+	fmt.Println(string(output.Payload))
+	return nil
+}
+
+func (a *app) schedule(args []string) error {
+	sub, args, err := subcommand(args, "schedule")
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: adminctl schedule %s <userId>", sub)
+	}
+	userID := args[0]
+
+	switch sub {
+	case "get":
+		output, err := a.scheduler.GetSchedule(context.TODO(), &scheduler.GetScheduleInput{
+			Name:      aws.String(utils.ScheduleName(userID)),
+			GroupName: aws.String(utils.ScheduleGroupName),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get schedule: %w", err)
+		}
+		printJSON(map[string]any{
+			"name":       aws.ToString(output.Name),
+			"expression": aws.ToString(output.ScheduleExpression),
+			"state":      output.State,
+			"target":     aws.ToString(output.Target.Arn),
+			"input":      aws.ToString(output.Target.Input),
+		})
+		return nil
+	case "create":
+		return a.scheduleCreate(userID)
+	case "delete":
+		return a.scheduleDelete(userID)
+	default:
+		return fmt.Errorf("unknown schedule subcommand %q", sub)
+	}
+}
+
+// scheduleCreate 依用戶目前的推播時間重建排程（已存在時先刪除），與用戶在 LINE 上設定的結果相同
+func (a *app) scheduleCreate(userID string) error {
+	userConfig, err := a.userConfigRepo().GetUserConfig(userID)
+	if err != nil {
+		return err
+	}
+	if userConfig == nil {
+		return utils.ErrUserNotFound
+	}
+
+	expression, err := utils.DailyCronExpression(userConfig.PushTime, userConfig.Timezone, time.Now())
+	if err != nil {
+		return err
+	}
+	payload, err := utils.NewWordPushPayload(userID, models.WordPushSourceSchedule)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.deleteSchedules(userID); err != nil {
+		return err
+	}
+
+	scheduleName := utils.ScheduleName(userID)
+	_, err = a.scheduler.CreateSchedule(context.TODO(), &scheduler.CreateScheduleInput{
+		Name:      aws.String(scheduleName),
+		GroupName: aws.String(utils.ScheduleGroupName),
+		FlexibleTimeWindow: &types.FlexibleTimeWindow{
+			Mode: types.FlexibleTimeWindowModeOff,
+		},
+		ScheduleExpression: aws.String(expression),
+		Target: &types.Target{
+			Arn:     aws.String(requireEnv("VOCABULARY_FUNCTION_ARN")),
+			RoleArn: aws.String(requireEnv("SCHEDULER_ROLE_ARN")),
+			Input:   aws.String(string(payload)),
+		},
+	})
+	a.auditSchedule(userID, scheduleName, models.ScheduleOperationCreate, err)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	if err := a.userConfigRepo().SetScheduleName(userID, scheduleName); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s (%s)\n", scheduleName, expression)
+	return nil
+}
+
+func (a *app) scheduleDelete(userID string) error {
+	deleted, err := a.deleteSchedules(userID)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		fmt.Println("No schedule found")
+	}
+	for _, name := range deleted {
+		fmt.Printf("Deleted %s\n", name)
+	}
+	return nil
+}
+
+// deleteSchedules 刪除用戶的排程，包含舊版以 userID 命名的排程；回傳實際刪除的排程名稱
+func (a *app) deleteSchedules(userID string) ([]string, error) {
+	scheduleNames := []string{utils.ScheduleName(userID)}
+	if legacyName := utils.LegacyScheduleName(userID); legacyName != "" {
+		scheduleNames = append(scheduleNames, legacyName)
+	}
+
+	var deleted []string
+	for _, scheduleName := range scheduleNames {
+		_, err := a.scheduler.DeleteSchedule(context.TODO(), &scheduler.DeleteScheduleInput{
+			Name:      aws.String(scheduleName),
+			GroupName: aws.String(utils.ScheduleGroupName),
+		})
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			continue
+		}
+		a.auditSchedule(userID, scheduleName, models.ScheduleOperationDelete, err)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete schedule %s: %w", scheduleName, err)
+		}
+		deleted = append(deleted, scheduleName)
+	}
+	return deleted, nil
+}
+
+// auditSchedule 與 language-handler 相同寫入排程稽核紀錄，actor 標示為 adminctl 與本機使用者
+func (a *app) auditSchedule(userID, scheduleName, operation string, opErr error) {
+	entry := models.ScheduleAuditEntry{
+		UserID:       userID,
+		ScheduleName: scheduleName,
+		Operation:    operation,
+		Actor:        a.actor,
+		Reason:       "adminctl",
+		Result:       models.ScheduleResultSuccess,
+	}
+	if opErr != nil {
+		entry.Result = models.ScheduleResultFailure
+		entry.Error = opErr.Error()
+	}
+
+	repo := repository.NewScheduleAuditRepository(a.logger, a.dynamodb, requireEnv("AUDIT_TABLE_NAME"))
+	if err := repo.RecordScheduleOperation(entry); err != nil {
+		a.logger.WithError(err).Warn("Failed to record schedule audit entry")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"language-assistant/internal/courses"
+	"language-assistant/internal/models"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"time"
+)
+
+func (a *app) userConfigRepo() utils.UserConfigRepository {
+	return repository.NewUserConfigRepository(a.logger, a.dynamodb, requireEnv("USER_TABLE_NAME"))
+}
+
+func (a *app) user(args []string) error {
+	sub, args, err := subcommand(args, "user")
+	if err != nil {
+		return err
+	}
+
+	switch sub {
+	case "get":
+		if len(args) != 1 {
+			return errors.New("usage: adminctl user get <userId>")
+		}
+		userConfig, err := a.userConfigRepo().GetUserConfig(args[0])
+		if err != nil {
+			return err
+		}
+		if userConfig == nil {
+			return utils.ErrUserNotFound
+		}
+		printJSON(userConfig)
+		return nil
+	case "set":
+		return a.userSet(args)
+	case "receipts":
+		if len(args) != 1 {
+			return errors.New("usage: adminctl user receipts <userId>")
+		}
+		receipts, err := repository.NewPaymentRepository(a.logger, a.dynamodb, requireEnv("VOCABULARY_TABLE_NAME")).GetPaymentReceipts(args[0])
+		if err != nil {
+			return err
+		}
+		printJSON(receipts)
+		return nil
+	default:
+		return fmt.Errorf("unknown user subcommand %q", sub)
+	}
+}
+
+// userSet 只更新有指定的欄位；修改推播時間或時區後需另外執行 schedule create 才會生效
+func (a *app) userSet(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: adminctl user set <userId> [flags]")
+	}
+	userID := args[0]
+
+	flags := flag.NewFlagSet("user set", flag.ContinueOnError)
+	course := flags.String("course", "", "course id from internal/courses/courses.yaml, e.g. toeic")
+	level := flags.Int("level", -1, "exam score")
+	dailyWords := flags.Int("daily-words", 0, "words per daily push")
+	pushTime := flags.String("push-time", "", "daily push time (HH:MM)")
+	timezone := flags.String("timezone", "", "IANA timezone, e.g. Asia/Taipei")
+	cardFormat := flags.String("card-format", "", "text or image")
+	betaStatus := flags.String("beta-status", "", "pending, approved or rejected")
+	planName := flags.String("plan", "", "free or supporter")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	var plan models.Plan
+	if *planName != "" {
+		parsed, err := models.ParsePlan(*planName)
+		if err != nil {
+			return err
+		}
+		plan = parsed
+	}
+	var status models.BetaStatus
+	if *betaStatus != "" {
+		parsed, err := models.ParseBetaStatus(*betaStatus)
+		if err != nil {
+			return err
+		}
+		status = parsed
+	}
+	if *course != "" {
+		if _, ok := courses.Get(*course); !ok {
+			return fmt.Errorf("unknown course %q", *course)
+		}
+	}
+	var format models.CardFormat
+	if *cardFormat != "" {
+		parsed, err := models.ParseCardFormat(*cardFormat)
+		if err != nil {
+			return err
+		}
+		format = parsed
+	}
+
+	repo := a.userConfigRepo()
+	userConfig, err := repo.GetUserConfig(userID)
+	if err != nil {
+		return err
+	}
+	if userConfig == nil {
+		return utils.ErrUserNotFound
+	}
+
+	pushChanged := false
+	if *course != "" || *level >= 0 || *dailyWords > 0 || *pushTime != "" || *timezone != "" {
+		if *course != "" {
+			userConfig.Course = *course
+		}
+		if *level >= 0 {
+			userConfig.Level = *level
+		}
+		if *dailyWords > 0 {
+			userConfig.DailyWords = *dailyWords
+		}
+		if *pushTime != "" || *timezone != "" {
+			if *pushTime != "" {
+				userConfig.PushTime = *pushTime
+			}
+			if *timezone != "" {
+				userConfig.Timezone = *timezone
+			}
+			// 先驗證，避免寫入排程建立不了的設定
+			if _, err := utils.DailyCronExpression(userConfig.PushTime, userConfig.Timezone, time.Now()); err != nil {
+				return err
+			}
+			pushChanged = true
+		}
+		if err := repo.SaveUserConfig(userID, userConfig.DisplayName, userConfig.Course, userConfig.Level, userConfig.DailyWords, userConfig.PushTime, userConfig.Timezone); err != nil {
+			return err
+		}
+	}
+	if format != "" {
+		if err := repo.SetCardFormat(userID, format); err != nil {
+			return err
+		}
+	}
+	if status != "" {
+		if err := repo.SetBetaStatus(userID, status); err != nil {
+			return err
+		}
+	}
+	if plan != "" {
+		if err := repo.SetPlan(userID, plan); err != nil {
+			return err
+		}
+	}
+
+	updated, err := repo.GetUserConfig(userID)
+	if err != nil {
+		return err
+	}
+	printJSON(updated)
+	if pushChanged {
+		fmt.Printf("\nPush time changed; run `adminctl schedule create %s` to apply it\n", userID)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// migrate 在本機執行已登記的 migration，與 language-migration Lambda 共用同一套 runner 與 checkpoint，
+// 可以先在 Lambda 跑一部分再用 CLI 接著跑（反之亦然）。table 名稱由與 Lambda 相同的環境變數指定：
+//
+//	VOCABULARY_TABLE_NAME=language-assistant-dev-vocabulary go run ./cmd/migrate -list
+//	VOCABULARY_TABLE_NAME=... USER_TABLE_NAME=... go run ./cmd/migrate -migration 20250601-per-word-items -dry-run
+//	VOCABULARY_TABLE_NAME=... go run ./cmd/migrate -migration 20250601-per-word-items -verify
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"language-assistant/internal/migrations"
+	"language-assistant/internal/repository"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	list := flag.Bool("list", false, "list registered migrations")
+	migrationID := flag.String("migration", "", "ID of the migration to run")
+	dryRun := flag.Bool("dry-run", false, "count items that would change without writing")
+	reset := flag.Bool("reset", false, "discard the saved checkpoint and start from the beginning")
+	pageSize := flag.Int("page-size", 0, "items per scan page (default 100)")
+	maxPages := flag.Int("max-pages", 0, "stop after this many pages (0 = until done)")
+	verify := flag.Bool("verify", false, "scan the table and report items still pending and the migration's counts")
+	flag.Parse()
+
+	if *list {
+		all := migrations.All()
+		if len(all) == 0 {
+			fmt.Println("No migrations registered")
+		}
+		for _, m := range all {
+			fmt.Printf("%s\t%s\t%s\n", m.ID, m.Table, m.Description)
+		}
+		return
+	}
+
+	if *migrationID == "" {
+		flag.Usage()
+		log.Fatal("-migration or -list is required")
+	}
+	migration, err := migrations.Get(*migrationID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	vocabularyTableName := os.Getenv("VOCABULARY_TABLE_NAME")
+	if vocabularyTableName == "" {
+		log.Fatal(errors.New("VOCABULARY_TABLE_NAME is not set"))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		log.Fatal(err)
+	}
+	dynamodbClient := dynamodb.NewFromConfig(cfg)
+
+	logger := logrus.WithField("component", "migrate")
+	checkpointRepo := repository.NewMigrationRepository(logger, dynamodbClient, vocabularyTableName)
+	runner := migrations.NewRunner(logger, dynamodbClient, checkpointRepo, migrations.TablesFromEnv(os.Getenv))
+
+	if *verify {
+		result, err := runner.Verify(context.Background(), migration, int32(*pageSize))
+		if err != nil {
+			log.Fatal(err)
+		}
+		output, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	// 本機沒有 deadline，一次跑完（或跑到 -max-pages）
+	checkpoint, err := runner.Run(context.Background(), migration, migrations.Options{
+		DryRun:   *dryRun,
+		Reset:    *reset,
+		PageSize: int32(*pageSize),
+		MaxPages: *maxPages,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	output, _ := json.MarshalIndent(checkpoint, "", "  ")
+	fmt.Println(string(output))
+}
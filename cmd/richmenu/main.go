@@ -0,0 +1,200 @@
+// richmenu 建立並管理官方帳號的圖文選單（定義見 internal/richmenu）。選單 ID 依 stage 存放在 SSM，
+// language-handler 部署時讀入 RICH_MENU_ID，因此建立新選單後需要重新部署才會綁定給新加入的用戶：
+//
+//	export CHANNEL_SECRET=... CHANNEL_TOKEN=...
+//
+//	go run ./cmd/richmenu create -stage dev -image ./richmenu.png -default
+//	go run ./cmd/richmenu show -stage dev
+//	go run ./cmd/richmenu link -stage dev U1234 U5678
+//
+// 圖片需為 2500x1686 的 PNG 或 JPEG，按鈕位置與文字需對齊 richmenu.Menu() 的區塊。
+// 加上 -default 時同時設為所有用戶的預設選單，讓加入好友時還沒有綁定的舊用戶也能看到
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"language-assistant/internal/richmenu"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+const usage = `usage: richmenu <command> -stage <stage> [arguments]
+
+commands:
+  create -image <path> [-default]   create the rich menu, upload its image and store the ID in SSM
+  show                              show the rich menu stored in SSM
+  link <userId>...                  link the stored rich menu to existing users`
+
+type app struct {
+	line  *linebot.Client
+	ssm   *ssm.Client
+	stage string
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	stage := flags.String("stage", "", "deployment stage whose SSM parameter stores the rich menu ID")
+	image := flags.String("image", "", "rich menu image (create only)")
+	setDefault := flags.Bool("default", false, "also set the new rich menu as the default for all users (create only)")
+	flags.Parse(os.Args[2:])
+	if *stage == "" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	line, err := linebot.New(requireEnv("CHANNEL_SECRET"), requireEnv("CHANNEL_TOKEN"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		log.Fatal(err)
+	}
+	a := &app{line: line, ssm: ssm.NewFromConfig(cfg), stage: *stage}
+
+	switch command {
+	case "create":
+		err = a.create(*image, *setDefault)
+	case "show":
+		err = a.show()
+	case "link":
+		err = a.link(flags.Args())
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// create 建立新選單並覆寫 SSM 參數；舊選單不刪除，已綁定的用戶在重新綁定前仍看得到舊選單
+func (a *app) create(image string, setDefault bool) error {
+	if image == "" {
+		return errors.New("-image is required")
+	}
+
+	previous, err := a.storedID()
+	if err != nil {
+		return err
+	}
+
+	richMenuID, err := richmenu.Provision(a.line, image)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.ssm.PutParameter(context.TODO(), &ssm.PutParameterInput{
+		Name:      aws.String(richmenu.ParameterName(a.stage)),
+		Value:     aws.String(richMenuID),
+		Type:      types.ParameterTypeString,
+		Overwrite: aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store rich menu ID %s: %w", richMenuID, err)
+	}
+
+	if setDefault {
+		if _, err := a.line.SetDefaultRichMenu(richMenuID).Do(); err != nil {
+			return fmt.Errorf("failed to set default rich menu: %w", err)
+		}
+	}
+
+	printJSON(map[string]any{
+		"richMenuId": richMenuID,
+		"previousId": previous,
+		"parameter":  richmenu.ParameterName(a.stage),
+		"default":    setDefault,
+	})
+	fmt.Fprintln(os.Stderr, "Redeploy language-handler so new followers are linked to this menu")
+	return nil
+}
+
+func (a *app) show() error {
+	richMenuID, err := a.requireStoredID()
+	if err != nil {
+		return err
+	}
+
+	menu, err := a.line.GetRichMenu(richMenuID).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get rich menu %s: %w", richMenuID, err)
+	}
+	printJSON(menu)
+	return nil
+}
+
+func (a *app) link(userIDs []string) error {
+	if len(userIDs) == 0 {
+		return errors.New("usage: richmenu link -stage <stage> <userId>...")
+	}
+
+	richMenuID, err := a.requireStoredID()
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if _, err := a.line.LinkUserRichMenu(userID, richMenuID).Do(); err != nil {
+			return fmt.Errorf("failed to link rich menu to %s: %w", userID, err)
+		}
+		fmt.Printf("Linked %s to %s\n", userID, richMenuID)
+	}
+	return nil
+}
+
+// storedID 讀取 SSM 中的選單 ID，參數不存在時回傳空字串
+func (a *app) storedID() (string, error) {
+	output, err := a.ssm.GetParameter(context.TODO(), &ssm.GetParameterInput{
+		Name: aws.String(richmenu.ParameterName(a.stage)),
+	})
+	var notFound *types.ParameterNotFound
+	if errors.As(err, &notFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get rich menu ID: %w", err)
+	}
+	return aws.ToString(output.Parameter.Value), nil
+}
+
+func (a *app) requireStoredID() (string, error) {
+	richMenuID, err := a.storedID()
+	if err != nil {
+		return "", err
+	}
+	if richMenuID == "" {
+		return "", fmt.Errorf("%s is not set, run richmenu create first", richmenu.ParameterName(a.stage))
+	}
+	return richMenuID, nil
+}
+
+// requireEnv 取得必要的環境變數，未設定時直接結束
+func requireEnv(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		log.Fatalf("%s is not set", name)
+	}
+	return value
+}
+
+func printJSON(v any) {
+	output, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(output))
+}
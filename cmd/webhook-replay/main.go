@@ -0,0 +1,159 @@
+// webhook-replay 依序重播擷取到的 LINE webhook payload，用來在本機或 staging 重現對話流程的 bug。
+//
+// 先用 admin API 標記用戶（PUT /admin/users/{userId}/webhook-capture），重現問題後下載擷取檔：
+//
+//	aws s3 sync s3://<exports bucket>/webhooks/<pseudonym>/ ./captures
+//
+// 重播到 staging 的 webhook（以 CHANNEL_SECRET 重新簽章）：
+//
+//	CHANNEL_SECRET=... go run ./cmd/webhook-replay -dir ./captures -target https://<staging api>/webhook/language-receiver -user U<staging test user>
+//
+// 或重播到本機以 Lambda Runtime Interface Emulator 執行的 language-handler build：
+//
+//	CHANNEL_SECRET=... go run ./cmd/webhook-replay -dir ./captures -lambda http://localhost:9000/2015-03-31/functions/function/invocations
+//
+// language-handler 會略過處理過的 webhookEventId，因此預設每次重播都換上新的事件 ID；
+// 加上 -keep-event-ids 可以驗證重送事件確實被略過。
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"language-assistant/internal/utils"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func main() {
+	dir := flag.String("dir", "", "directory containing captured webhook payloads (*.json)")
+	target := flag.String("target", "", "webhook URL to POST each payload to")
+	lambdaURL := flag.String("lambda", "", "Lambda Runtime Interface Emulator invoke URL")
+	userID := flag.String("user", "", "replace the pseudonymized user ID with this user (e.g. a staging test account)")
+	delay := flag.Duration("delay", time.Second, "wait between payloads")
+	keepEventIDs := flag.Bool("keep-event-ids", false, "keep the captured webhookEventIds so the handler treats the replay as redelivery")
+	flag.Parse()
+
+	if *dir == "" || (*target == "") == (*lambdaURL == "") {
+		flag.Usage()
+		log.Fatal("-dir and exactly one of -target or -lambda are required")
+	}
+
+	channelSecret := os.Getenv("CHANNEL_SECRET")
+	if channelSecret == "" {
+		log.Fatal(errors.New("CHANNEL_SECRET is not set"))
+	}
+
+	files, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// 檔名為擷取時間，排序後即為原本的事件順序
+	sort.Strings(files)
+
+	eventIDSuffix := ""
+	if !*keepEventIDs {
+		eventIDSuffix = fmt.Sprintf("-replay%d", time.Now().Unix())
+	}
+
+	for i, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("failed to read %s: %v", file, err)
+		}
+		if *userID != "" || eventIDSuffix != "" {
+			if body, err = rewriteEvents(body, *userID, eventIDSuffix); err != nil {
+				log.Fatalf("failed to rewrite %s: %v", file, err)
+			}
+		}
+
+		signature := utils.SignWebhookBody(channelSecret, body)
+		var status string
+		if *target != "" {
+			status, err = postWebhook(*target, body, signature)
+		} else {
+			status, err = invokeLambda(*lambdaURL, body, signature)
+		}
+		if err != nil {
+			log.Fatalf("failed to replay %s: %v", file, err)
+		}
+		fmt.Printf("[%d/%d] %s -> %s\n", i+1, len(files), filepath.Base(file), status)
+
+		if i < len(files)-1 {
+			time.Sleep(*delay)
+		}
+	}
+}
+
+// rewriteEvents 將所有事件的來源用戶改成指定的用戶，並在 webhookEventId 後加上後綴（皆為空字串時不變更）
+func rewriteEvents(body []byte, userID, eventIDSuffix string) ([]byte, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	rawEvents, _ := payload["events"].([]interface{})
+	for _, rawEvent := range rawEvents {
+		event, _ := rawEvent.(map[string]interface{})
+		if source, ok := event["source"].(map[string]interface{}); ok && userID != "" {
+			source["userId"] = userID
+		}
+		if eventID, ok := event["webhookEventId"].(string); ok && eventIDSuffix != "" {
+			event["webhookEventId"] = eventID + eventIDSuffix
+		}
+	}
+	return json.Marshal(payload)
+}
+
+func postWebhook(url string, body []byte, signature string) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Line-Signature", signature)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.Status, nil
+}
+
+// invokeLambda 將 payload 包成 API Gateway proxy event，交給本機 Runtime Interface Emulator 執行
+func invokeLambda(url string, body []byte, signature string) (string, error) {
+	event, err := json.Marshal(events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodPost,
+		Path:       "/webhook/language-receiver",
+		Headers: map[string]string{
+			"Content-Type":     "application/json",
+			"X-Line-Signature": signature,
+		},
+		Body: string(body),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(event))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result events.APIGatewayProxyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode lambda response: %w", err)
+	}
+	return fmt.Sprintf("%d %s", result.StatusCode, result.Body), nil
+}
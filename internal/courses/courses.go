@@ -0,0 +1,196 @@
+// Package courses 是支援課程（考試）的登記表，內容來自 courses.yaml。
+// 課程選單、分數輸入驗證、程度級距與單字生成 prompt 都透過這裡取得課程資訊，新增考試不需要修改程式。
+package courses
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed courses.yaml
+var coursesYAML []byte
+
+// 課程的學習語言（Course.Language）
+const (
+	LanguageEnglish  = "en"
+	LanguageJapanese = "ja"
+)
+
+// Course 描述一個考試課程
+type Course struct {
+	ID             string  `yaml:"id"`              // 儲存在 UserConfig.Course 的值，例如 "toeic"
+	Name           string  `yaml:"name"`            // 顯示名稱，例如「多益」
+	ExamName       string  `yaml:"exam_name"`       // prompt 中使用的考試名稱，例如 "TOEIC"
+	Emoji          string  `yaml:"emoji"`           // 課程選單與設定訊息使用的圖示
+	Description    string  `yaml:"description"`     // 課程選單上的說明（LINE 限制 60 字）
+	MinScore       float64 `yaml:"min_score"`       // 分數下限（實際分數）
+	MaxScore       float64 `yaml:"max_score"`       // 分數上限（實際分數）
+	ScoreScale     int     `yaml:"score_scale"`     // 儲存 level 時乘上的倍數，大於 1 代表接受小數分數
+	ScoreExample   string  `yaml:"score_example"`   // 提示用戶輸入分數時的範例
+	BandThresholds []int   `yaml:"band_thresholds"` // 進入 B1、B2、C1 的 level 門檻
+	PromptGuidance string  `yaml:"prompt_guidance"` // 單字生成 prompt 中此考試的選字方向
+
+	Language string   `yaml:"language"` // 學習語言，LanguageEnglish（預設）或 LanguageJapanese
+	Levels   []string `yaml:"levels"`   // 以級數名稱選擇程度時由易到難的級數（例如 JLPT N5~N1），level 存 1 起算的索引
+}
+
+var registry []Course
+
+func init() {
+	var file struct {
+		Courses []Course `yaml:"courses"`
+	}
+	if err := yaml.Unmarshal(coursesYAML, &file); err != nil {
+		panic(fmt.Sprintf("failed to parse courses.yaml: %v", err))
+	}
+	seen := map[string]bool{}
+	for i := range file.Courses {
+		course := &file.Courses[i]
+		if course.Language == "" {
+			course.Language = LanguageEnglish
+		}
+		if len(course.Levels) > 0 {
+			// 級數制課程的分數範圍由級數決定，不在 yaml 中另外設定
+			course.MinScore, course.MaxScore, course.ScoreScale = 1, float64(len(course.Levels)), 1
+		}
+		if course.ID == "" || course.Name == "" || course.ScoreScale <= 0 || course.MaxScore <= course.MinScore {
+			panic(fmt.Sprintf("course %q is missing id, name, score_scale or score range", course.ID))
+		}
+		if seen[course.ID] {
+			panic(fmt.Sprintf("course %q registered twice", course.ID))
+		}
+		seen[course.ID] = true
+	}
+	registry = file.Courses
+}
+
+// All 依 courses.yaml 的順序回傳所有課程
+func All() []Course {
+	return registry
+}
+
+// Get 以課程 ID 取得課程
+func Get(id string) (Course, bool) {
+	for _, course := range registry {
+		if course.ID == id {
+			return course, true
+		}
+	}
+	return Course{}, false
+}
+
+// Lookup 以 ID、顯示名稱或考試名稱（不分大小寫）找課程，用於解析用戶輸入的指令參數
+func Lookup(text string) (Course, bool) {
+	text = strings.TrimSpace(text)
+	for _, course := range registry {
+		if strings.EqualFold(text, course.ID) || text == course.Name || strings.EqualFold(text, course.ExamName) {
+			return course, true
+		}
+	}
+	return Course{}, false
+}
+
+// FromInterestText 解析課程選單上「我對 X 有興趣」按鈕送出的文字
+func FromInterestText(text string) (Course, bool) {
+	for _, course := range registry {
+		if text == course.InterestText() {
+			return course, true
+		}
+	}
+	return Course{}, false
+}
+
+// DisplayName 回傳課程的顯示名稱，未登記的課程直接回傳 ID
+func DisplayName(id string) string {
+	if course, ok := Get(id); ok {
+		return course.Name
+	}
+	return id
+}
+
+// Names 以「、」串接所有課程的顯示名稱，例如「多益」、「雅思」
+func Names() string {
+	names := make([]string, 0, len(registry))
+	for _, course := range registry {
+		names = append(names, "「"+course.Name+"」")
+	}
+	return strings.Join(names, "、")
+}
+
+// Title 課程選單上的標題，例如 "📘 多益"
+func (c Course) Title() string {
+	return strings.TrimSpace(c.Emoji + " " + c.Name)
+}
+
+// InterestText 課程選單「有興趣」按鈕送出的文字
+func (c Course) InterestText() string {
+	return "我對" + c.Name + "有興趣"
+}
+
+// ScoreRange 分數範圍的顯示文字，例如 "0-990"；級數制課程為 "N5-N1"
+func (c Course) ScoreRange() string {
+	if c.NamedLevels() {
+		return c.Levels[0] + "-" + c.Levels[len(c.Levels)-1]
+	}
+	return formatFloat(c.MinScore) + "-" + formatFloat(c.MaxScore)
+}
+
+// NamedLevels 是否以級數名稱（例如 JLPT N3）而不是分數表示程度
+func (c Course) NamedLevels() bool {
+	return len(c.Levels) > 0
+}
+
+// DecimalScore 是否接受小數分數（例如雅思 6.5）
+func (c Course) DecimalScore() bool {
+	return c.ScoreScale > 1
+}
+
+// ParseScore 將用戶輸入的分數（級數制課程為級數名稱，不分大小寫）轉為儲存用的 level；無法解析時回傳 false
+func (c Course) ParseScore(text string) (int, bool) {
+	if c.NamedLevels() {
+		for i, name := range c.Levels {
+			if strings.EqualFold(strings.TrimSpace(text), name) {
+				return i + 1, true
+			}
+		}
+		return 0, false
+	}
+	if c.DecimalScore() {
+		var score float64
+		if _, err := fmt.Sscanf(text, "%f", &score); err != nil {
+			return 0, false
+		}
+		return int(math.Round(score * float64(c.ScoreScale))), true
+	}
+	var score int
+	if _, err := fmt.Sscanf(text, "%d", &score); err != nil {
+		return 0, false
+	}
+	return score, true
+}
+
+// ValidLevel 檢查 level 是否在課程的分數範圍內
+func (c Course) ValidLevel(level int) bool {
+	score := float64(level) / float64(c.ScoreScale)
+	return score >= c.MinScore && score <= c.MaxScore
+}
+
+// FormatLevel 將儲存的 level 轉回實際分數的文字，例如雅思 65 → "6.5"、JLPT 3 → "N3"
+func (c Course) FormatLevel(level int) string {
+	if c.NamedLevels() && level >= 1 && level <= len(c.Levels) {
+		return c.Levels[level-1]
+	}
+	if c.DecimalScore() {
+		return strconv.FormatFloat(float64(level)/float64(c.ScoreScale), 'f', 1, 64)
+	}
+	return strconv.Itoa(level)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
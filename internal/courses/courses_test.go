@@ -0,0 +1,88 @@
+package courses
+
+import "testing"
+
+func TestRegistry(t *testing.T) {
+	for _, id := range []string{"toeic", "ielts", "toefl", "gre", "japanese"} {
+		course, ok := Get(id)
+		if !ok {
+			t.Fatalf("Expected course %q to be registered", id)
+		}
+		if len(course.BandThresholds) != 3 {
+			t.Errorf("Expected 3 band thresholds for %q, got %v", id, course.BandThresholds)
+		}
+		if course.PromptGuidance == "" {
+			t.Errorf("Expected prompt guidance for %q", id)
+		}
+		if len([]rune(course.Description)) > 60 {
+			t.Errorf("Description of %q exceeds the LINE carousel limit", id)
+		}
+	}
+
+	if course, ok := Lookup("雅思"); !ok || course.ID != "ielts" {
+		t.Errorf("Expected 雅思 to resolve to ielts, got %+v", course)
+	}
+	if course, ok := Lookup("TOEFL iBT"); !ok || course.ID != "toefl" {
+		t.Errorf("Expected exam name lookup to resolve to toefl, got %+v", course)
+	}
+	if course, ok := FromInterestText("我對多益有興趣"); !ok || course.ID != "toeic" {
+		t.Errorf("Expected interest text to resolve to toeic, got %+v", course)
+	}
+	if _, ok := Get("unknown"); ok {
+		t.Error("Expected unknown course to be missing")
+	}
+}
+
+func TestScores(t *testing.T) {
+	tests := []struct {
+		course string
+		input  string
+		level  int
+		valid  bool
+		shown  string
+	}{
+		{"toeic", "750", 750, true, "750"},
+		{"toeic", "995", 995, false, "995"},
+		{"ielts", "6.5", 65, true, "6.5"},
+		{"ielts", "6.3", 63, true, "6.3"},
+		{"ielts", "9.5", 95, false, "9.5"},
+		{"toefl", "100", 100, true, "100"},
+		{"gre", "120", 120, false, "120"},
+		{"gre", "165", 165, true, "165"},
+		{"japanese", "N5", 1, true, "N5"},
+		{"japanese", "n3", 3, true, "N3"},
+		{"japanese", "N1", 5, true, "N1"},
+	}
+
+	for _, tt := range tests {
+		course, _ := Get(tt.course)
+		level, ok := course.ParseScore(tt.input)
+		if !ok || level != tt.level {
+			t.Errorf("%s ParseScore(%q) = %d, %v; expected %d", tt.course, tt.input, level, ok, tt.level)
+		}
+		if valid := course.ValidLevel(level); valid != tt.valid {
+			t.Errorf("%s ValidLevel(%d) = %v, expected %v", tt.course, level, valid, tt.valid)
+		}
+		if shown := course.FormatLevel(level); shown != tt.shown {
+			t.Errorf("%s FormatLevel(%d) = %q, expected %q", tt.course, level, shown, tt.shown)
+		}
+	}
+
+	toeic, _ := Get("toeic")
+	if _, ok := toeic.ParseScore("很多分"); ok {
+		t.Error("Expected non-numeric input to be rejected")
+	}
+	if toeic.ScoreRange() != "0-990" {
+		t.Errorf("Unexpected score range %q", toeic.ScoreRange())
+	}
+
+	japanese, _ := Get("japanese")
+	for _, input := range []string{"3", "N6", "JLPT"} {
+		if _, ok := japanese.ParseScore(input); ok {
+			t.Errorf("Expected JLPT level %q to be rejected", input)
+		}
+	}
+	if japanese.ScoreRange() != "N5-N1" || japanese.Language != LanguageJapanese || toeic.Language != LanguageEnglish {
+		t.Errorf("Unexpected JLPT course %+v", japanese)
+	}
+}
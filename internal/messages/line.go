@@ -0,0 +1,317 @@
+package messages
+
+import (
+	"language-assistant/internal/courses"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+// maxActionLabelLength LINE action label 的字數上限
+const maxActionLabelLength = 20
+
+// DailyWordsOptions 是推播設定中可選擇的每日單字量
+var DailyWordsOptions = []int{5, 10, 15, 20}
+
+// PushTimeOptions 是推播設定中可選擇的推播時間
+var PushTimeOptions = []string{"08:00", "12:00", "19:00"}
+
+// TimezoneOptions 是推播設定中以按鈕提供的常用時區（亞洲、歐洲、北美），其他時區可直接輸入 IANA 名稱
+var TimezoneOptions = []string{"Asia/Taipei", "Asia/Tokyo", "Asia/Singapore", "Europe/London", "Europe/Berlin", "America/New_York", "America/Los_Angeles"}
+
+// Postback actions of the setup flow buttons built in this file（data 格式為 URL query string，
+// 其他按鈕的 action 見 models 的 Postback* 常數）
+const (
+	PostbackCourseInterest  = "course_interest"  // 課程選單的「有興趣」，course=課程 ID
+	PostbackPushSettings    = "push_settings"    // 推播設定的步驟按鈕，step=PushSettingsStep*
+	PostbackDailyWords      = "daily_words"      // 每日單字量，count=數量
+	PostbackPushTime        = "push_time"        // 推播時間，time=HH:MM
+	PostbackTimezone        = "timezone"         // 推播時區，tz=IANA 時區名稱
+	PostbackTranslationOnly = "translation_only" // 不選課程、只使用翻譯功能
+	PostbackConsent         = "consent"          // 隱私設定，choice=ConsentChoice*，或 type=同意項目&granted=true|false 切換單一項目
+	PostbackTermsAccept     = "terms_accept"     // 同意服務條款，version=條款版本
+)
+
+// Consent choices carried by PostbackConsent
+const (
+	ConsentChoiceAll       = "all"       // 全部同意
+	ConsentChoiceEssential = "essential" // 只保留必要（全部不同意）
+	ConsentChoiceCustom    = "custom"    // 顯示隱私設定，個別切換
+)
+
+// Push settings steps carried by PostbackPushSettings
+const (
+	PushSettingsStepStart   = "start"   // 從課程選擇開始
+	PushSettingsStepCustom  = "custom"  // 自訂單字量與推播時間
+	PushSettingsStepDefault = "default" // 使用預設設定
+)
+
+// composer 將渲染好的文字訊息組成實際送出的 LINE 訊息（加上 Quick Reply 或 Template）
+type composer func(text *linebot.TextMessage) []linebot.SendingMessage
+
+var composers = map[Key]composer{
+	Greeting:                   withCourseCarousel(CourseCarouselAlt, translationOnlyReplies),
+	PushSettingsStart:          withCourseCarousel(PushSettingsStartAlt, nil),
+	PushSettingsPrompt:         withQuickReplies(pushSettingsPromptReplies),
+	PushSettingsDailyWords:     withQuickReplies(dailyWordsReplies),
+	PushSettingsCourseSelected: withQuickReplies(dailyWordsReplies),
+	DailyWordsSelected:         withQuickReplies(pushTimeReplies),
+	PushTimeSelected:           withQuickReplies(timezoneReplies),
+	TimezoneInvalid:            withQuickReplies(timezoneReplies),
+	SetupNudgeCourse:           withQuickReplies(setupNudgeCourseReplies),
+	SetupNudgePush:             withQuickReplies(pushSettingsPromptReplies),
+	ConsentPrompt:              withQuickReplies(consentPromptReplies),
+}
+
+// Build renders the named template and returns the exact LINE messages sent for it,
+// including any quick replies or templates attached to that message
+func Build(key Key, data interface{}) []linebot.SendingMessage {
+	text := linebot.NewTextMessage(Render(key, data))
+	if compose, ok := composers[key]; ok {
+		return compose(text)
+	}
+	return []linebot.SendingMessage{text}
+}
+
+// Keys returns all catalog keys in sorted order
+func Keys() []Key {
+	keys := make([]Key, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func withQuickReplies(items func() *linebot.QuickReplyItems) composer {
+	return func(text *linebot.TextMessage) []linebot.SendingMessage {
+		return []linebot.SendingMessage{text.WithQuickReplies(items())}
+	}
+}
+
+// withCourseCarousel 在文字訊息後附上課程選單，items 不為 nil 時在選單下方加上 Quick Reply
+func withCourseCarousel(altText Key, items func() *linebot.QuickReplyItems) composer {
+	return func(text *linebot.TextMessage) []linebot.SendingMessage {
+		carousel := linebot.NewTemplateMessage(Text(altText), CourseSelectionCarousel())
+		if items != nil {
+			carousel.WithQuickReplies(items())
+		}
+		return []linebot.SendingMessage{text, carousel}
+	}
+}
+
+// CourseSelectionCarousel 課程選擇的 CarouselTemplate，每個登記的課程一欄
+func CourseSelectionCarousel() *linebot.CarouselTemplate {
+	interestLabel := Text(CourseCarouselInterestLabel)
+
+	var columns []*linebot.CarouselColumn
+	for _, course := range courses.All() {
+		columns = append(columns, linebot.NewCarouselColumn(
+			"", // 不使用圖片
+			course.Title(),
+			course.Description,
+			postbackAction(interestLabel, PostbackCourseInterest, "course", course.ID),
+		))
+	}
+	return linebot.NewCarouselTemplate(columns...)
+}
+
+// WordAckReplies 每日單字下方的「記住了」按鈕，以 postback 回傳互動資料
+func WordAckReplies(postbackData string) *linebot.QuickReplyItems {
+	label := Text(DailyPushAckLabel)
+	return linebot.NewQuickReplyItems(
+		linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData, "", label, "", "")),
+	)
+}
+
+// VocabularyHistoryNextReplies /歷史 卡片下方的「下一頁」按鈕
+func VocabularyHistoryNextReplies(postbackData string) *linebot.QuickReplyItems {
+	label := Text(VocabularyHistoryNextLabel)
+	return linebot.NewQuickReplyItems(
+		linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData, "", label, "", "")),
+	)
+}
+
+// ReviewResponseReplies 互動回顧單字下方的「我記得 / 忘記了」按鈕
+func ReviewResponseReplies(rememberedData, forgottenData string) *linebot.QuickReplyItems {
+	remembered := Text(ReviewRememberedLabel)
+	forgotten := Text(ReviewForgottenLabel)
+	return linebot.NewQuickReplyItems(
+		linebot.NewQuickReplyButton("", linebot.NewPostbackAction(remembered, rememberedData, "", remembered, "", "")),
+		linebot.NewQuickReplyButton("", linebot.NewPostbackAction(forgotten, forgottenData, "", forgotten, "", "")),
+	)
+}
+
+// QuizOptionReplies 測驗題目下方的選項按鈕，labels 與 postbackData 依序對應
+func QuizOptionReplies(labels, postbackData []string) *linebot.QuickReplyItems {
+	var buttons []*linebot.QuickReplyButton
+	for i, label := range labels {
+		buttons = append(buttons, linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData[i], "", label, "", "")))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
+
+// DeckAddReplies 翻譯結果下方的「加入「清單」」按鈕，names 與 postbackData 依序對應
+func DeckAddReplies(names, postbackData []string) *linebot.QuickReplyItems {
+	var buttons []*linebot.QuickReplyButton
+	for i, name := range names {
+		label := Render(DeckAddLabel, Data{"Name": name})
+		buttons = append(buttons, linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData[i], "", label, "", "")))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
+
+// RegenerateExampleButtons 翻譯結果下方的「換個例句」按鈕，words 與 postbackData 依序對應。
+// 回傳按鈕而非 QuickReplyItems，讓 handler 與其他按鈕合併在同一組 quick reply
+func RegenerateExampleButtons(words, postbackData []string) []*linebot.QuickReplyButton {
+	var buttons []*linebot.QuickReplyButton
+	for i, word := range words {
+		label := truncateLabel(Render(RegenerateExampleLabel, Data{"Word": word}))
+		buttons = append(buttons, linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData[i], "", label, "", "")))
+	}
+	return buttons
+}
+
+// ContentReportButtons 翻譯結果下方的「回報問題」按鈕，words 與 postbackData 依序對應。
+// 與 RegenerateExampleButtons 相同回傳按鈕，讓 handler 合併在同一組 quick reply
+func ContentReportButtons(words, postbackData []string) []*linebot.QuickReplyButton {
+	var buttons []*linebot.QuickReplyButton
+	for i, word := range words {
+		label := truncateLabel(Render(ContentReportWordLabel, Data{"Word": word}))
+		buttons = append(buttons, linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData[i], "", label, "", "")))
+	}
+	return buttons
+}
+
+// ContentReportReasonReplies 選擇回報原因的按鈕，labels 與 postbackData 一一對應
+func ContentReportReasonReplies(labels, postbackData []string) *linebot.QuickReplyItems {
+	var buttons []*linebot.QuickReplyButton
+	for i, label := range labels {
+		buttons = append(buttons, linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData[i], "", label, "", "")))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
+
+// truncateLabel LINE 按鈕的 label 上限為 20 字，超過時整則回覆會被拒絕，長單字截斷並加上省略號
+func truncateLabel(label string) string {
+	runes := []rune(label)
+	if len(runes) <= maxActionLabelLength {
+		return label
+	}
+	return string(runes[:maxActionLabelLength-1]) + "…"
+}
+
+// LevelReplies 級數制課程（例如 JLPT）選擇程度的按鈕，送出級數名稱讓 handler 當作分數輸入處理
+func LevelReplies(levels []string) *linebot.QuickReplyItems {
+	var buttons []*linebot.QuickReplyButton
+	for _, level := range levels {
+		buttons = append(buttons, linebot.NewQuickReplyButton("", linebot.NewMessageAction(level, level)))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
+
+// WordHistoryResetTemplate 清除單字推播紀錄前的確認按鈕
+func WordHistoryResetTemplate(courseName, confirmData, cancelData string) *linebot.TemplateMessage {
+	confirmLabel := Text(WordHistoryResetConfirmLabel)
+	cancelLabel := Text(WordHistoryResetCancelLabel)
+	return linebot.NewTemplateMessage(Text(WordHistoryResetAlt), linebot.NewConfirmTemplate(
+		Render(WordHistoryResetConfirm, Data{"CourseName": courseName}),
+		linebot.NewPostbackAction(confirmLabel, confirmData, "", confirmLabel, "", ""),
+		linebot.NewPostbackAction(cancelLabel, cancelData, "", cancelLabel, "", ""),
+	))
+}
+
+// SupporterPaymentTemplate 支持者方案的說明與前往 LINE Pay 付款的按鈕
+func SupporterPaymentTemplate(price int, currency, paymentURL string) *linebot.TemplateMessage {
+	return linebot.NewTemplateMessage(Text(SupporterPaymentAlt), linebot.NewButtonsTemplate(
+		"", "",
+		Render(SupporterPayment, Data{"Price": price, "Currency": currency}),
+		linebot.NewURIAction(Text(SupporterPaymentLabel), paymentURL),
+	))
+}
+
+// TermsTemplate 服務條款更新的說明，附上閱讀全文的連結與同意按鈕
+func TermsTemplate(version, termsURL string) *linebot.TemplateMessage {
+	return linebot.NewTemplateMessage(Text(TermsPromptAlt), linebot.NewButtonsTemplate(
+		"", "",
+		Render(TermsPrompt, Data{"Version": version}),
+		linebot.NewURIAction(Text(TermsReadLabel), termsURL),
+		postbackAction(Text(TermsAcceptLabel), PostbackTermsAccept, "version", version),
+	))
+}
+
+// postbackAction 建立以 postback 回傳結構化資料的按鈕，聊天室中仍顯示按鈕文字，但不會被當成用戶輸入的訊息
+func postbackAction(label, action, key, value string) *linebot.PostbackAction {
+	values := url.Values{}
+	values.Set("action", action)
+	values.Set(key, value)
+	return linebot.NewPostbackAction(label, values.Encode(), "", label, "", "")
+}
+
+func pushSettingsPromptReplies() *linebot.QuickReplyItems {
+	return linebot.NewQuickReplyItems(
+		linebot.NewQuickReplyButton("", postbackAction(Text(PushSettingsPromptCustomLabel), PostbackPushSettings, "step", PushSettingsStepCustom)),
+		linebot.NewQuickReplyButton("", postbackAction(Text(PushSettingsPromptDefaultLabel), PostbackPushSettings, "step", PushSettingsStepDefault)),
+	)
+}
+
+func consentPromptReplies() *linebot.QuickReplyItems {
+	return linebot.NewQuickReplyItems(
+		linebot.NewQuickReplyButton("", postbackAction(Text(ConsentAcceptAllLabel), PostbackConsent, "choice", ConsentChoiceAll)),
+		linebot.NewQuickReplyButton("", postbackAction(Text(ConsentEssentialLabel), PostbackConsent, "choice", ConsentChoiceEssential)),
+		linebot.NewQuickReplyButton("", postbackAction(Text(ConsentCustomizeLabel), PostbackConsent, "choice", ConsentChoiceCustom)),
+	)
+}
+
+// ConsentToggleReplies 隱私設定下方切換各同意項目的按鈕，labels 與 postbackData 一一對應
+func ConsentToggleReplies(labels, postbackData []string) *linebot.QuickReplyItems {
+	var buttons []*linebot.QuickReplyButton
+	for i, label := range labels {
+		buttons = append(buttons, linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, postbackData[i], "", label, "", "")))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
+
+func translationOnlyReplies() *linebot.QuickReplyItems {
+	label := Text(TranslationOnlyLabel)
+	values := url.Values{}
+	values.Set("action", PostbackTranslationOnly)
+	return linebot.NewQuickReplyItems(
+		linebot.NewQuickReplyButton("", linebot.NewPostbackAction(label, values.Encode(), "", label, "", "")),
+	)
+}
+
+func setupNudgeCourseReplies() *linebot.QuickReplyItems {
+	return linebot.NewQuickReplyItems(
+		linebot.NewQuickReplyButton("", postbackAction(Text(SetupNudgeCourseLabel), PostbackPushSettings, "step", PushSettingsStepStart)),
+	)
+}
+
+func dailyWordsReplies() *linebot.QuickReplyItems {
+	var buttons []*linebot.QuickReplyButton
+	for _, count := range DailyWordsOptions {
+		label := Render(DailyWordsOptionLabel, Data{"Count": count})
+		buttons = append(buttons, linebot.NewQuickReplyButton("", postbackAction(label, PostbackDailyWords, "count", strconv.Itoa(count))))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
+
+func pushTimeReplies() *linebot.QuickReplyItems {
+	labels := []Key{PushTimeMorningLabel, PushTimeNoonLabel, PushTimeEveningLabel}
+	var buttons []*linebot.QuickReplyButton
+	for i, pushTime := range PushTimeOptions {
+		buttons = append(buttons, linebot.NewQuickReplyButton("", postbackAction(Text(labels[i]), PostbackPushTime, "time", pushTime)))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
+
+func timezoneReplies() *linebot.QuickReplyItems {
+	labels := []Key{TimezoneTaipeiLabel, TimezoneTokyoLabel, TimezoneSingaporeLabel, TimezoneLondonLabel, TimezoneBerlinLabel, TimezoneNewYorkLabel, TimezoneLosAngelesLabel}
+	var buttons []*linebot.QuickReplyButton
+	for i, timezone := range TimezoneOptions {
+		buttons = append(buttons, linebot.NewQuickReplyButton("", postbackAction(Text(labels[i]), PostbackTimezone, "tz", timezone)))
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
@@ -0,0 +1,347 @@
+package messages
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed catalog.yaml
+var catalogYAML []byte
+
+// Key identifies a named template in the message catalog
+type Key string
+
+const (
+	Greeting       Key = "greeting"
+	UnknownCommand Key = "unknown_command"
+
+	StickerReceived   Key = "sticker_received"
+	EmojiOnlyReceived Key = "emoji_only_received"
+
+	CourseCarouselAlt           Key = "course_carousel_alt"
+	CourseCarouselInterestLabel Key = "course_carousel_interest_label"
+	CourseInterest              Key = "course_interest"
+
+	RichMenuChatBar           Key = "rich_menu_chat_bar"
+	RichMenuHelpLabel         Key = "rich_menu_help_label"
+	RichMenuPushSettingsLabel Key = "rich_menu_push_settings_label"
+	RichMenuSettingsLabel     Key = "rich_menu_settings_label"
+	RichMenuQuizLabel         Key = "rich_menu_quiz_label"
+	RichMenuTodayWordsLabel   Key = "rich_menu_today_words_label"
+
+	TranslationOnlyLabel  Key = "translation_only_label"
+	TranslationOnlyDone   Key = "translation_only_done"
+	TranslationOnlyFailed Key = "translation_only_failed"
+
+	ScoreSet        Key = "score_set"
+	ScoreInvalid    Key = "score_invalid"
+	ScoreSaveFailed Key = "score_save_failed"
+
+	SetupNudgeCourse      Key = "setup_nudge_course"
+	SetupNudgeCourseLabel Key = "setup_nudge_course_label"
+	SetupNudgeScore       Key = "setup_nudge_score"
+	SetupNudgePush        Key = "setup_nudge_push"
+
+	PushSettingsPrompt             Key = "push_settings_prompt"
+	PushSettingsPromptCustomLabel  Key = "push_settings_prompt_custom_label"
+	PushSettingsPromptDefaultLabel Key = "push_settings_prompt_default_label"
+	PushSettingsStart              Key = "push_settings_start"
+	PushSettingsStartAlt           Key = "push_settings_start_alt"
+	PushSettingsDailyWords         Key = "push_settings_daily_words"
+	PushSettingsCourseSelected     Key = "push_settings_course_selected"
+	DailyWordsOptionLabel          Key = "daily_words_option_label"
+	DailyWordsSelected             Key = "daily_words_selected"
+	PushTimeMorningLabel           Key = "push_time_morning_label"
+	PushTimeNoonLabel              Key = "push_time_noon_label"
+	PushTimeEveningLabel           Key = "push_time_evening_label"
+	PushTimeSelected               Key = "push_time_selected"
+	TimezoneTaipeiLabel            Key = "timezone_taipei_label"
+	TimezoneTokyoLabel             Key = "timezone_tokyo_label"
+	TimezoneSingaporeLabel         Key = "timezone_singapore_label"
+	TimezoneLondonLabel            Key = "timezone_london_label"
+	TimezoneBerlinLabel            Key = "timezone_berlin_label"
+	TimezoneNewYorkLabel           Key = "timezone_new_york_label"
+	TimezoneLosAngelesLabel        Key = "timezone_los_angeles_label"
+	TimezoneInvalid                Key = "timezone_invalid"
+	PushSettingsDone               Key = "push_settings_done"
+	PushSettingsDefaultDone        Key = "push_settings_default_done"
+	NextPush                       Key = "next_push"
+	SetupRequired                  Key = "setup_required"
+	SetupFailed                    Key = "setup_failed"
+	ScheduleFailed                 Key = "schedule_failed"
+
+	SettingsLoadFailed Key = "settings_load_failed"
+	SettingsNotFound   Key = "settings_not_found"
+	UserSettings       Key = "user_settings"
+
+	PushHistory               Key = "push_history"
+	PushHistoryEmpty          Key = "push_history_empty"
+	PushHistoryLoadFailed     Key = "push_history_load_failed"
+	VocabularyStats           Key = "vocabulary_stats"
+	VocabularyStatsEmpty      Key = "vocabulary_stats_empty"
+	VocabularyStatsLoadFailed Key = "vocabulary_stats_load_failed"
+	RepushUsage               Key = "repush_usage"
+	RepushHeader              Key = "repush_header"
+	RepushNotFound            Key = "repush_not_found"
+
+	VocabularyHistoryAlt        Key = "vocabulary_history_alt"
+	VocabularyHistoryWordCount  Key = "vocabulary_history_word_count"
+	VocabularyHistoryMore       Key = "vocabulary_history_more"
+	VocabularyHistoryNextLabel  Key = "vocabulary_history_next_label"
+	VocabularyHistoryEnd        Key = "vocabulary_history_end"
+	VocabularyHistoryLoadFailed Key = "vocabulary_history_load_failed"
+
+	WordHistoryResetConfirm      Key = "word_history_reset_confirm"
+	WordHistoryResetAlt          Key = "word_history_reset_alt"
+	WordHistoryResetConfirmLabel Key = "word_history_reset_confirm_label"
+	WordHistoryResetCancelLabel  Key = "word_history_reset_cancel_label"
+	WordHistoryResetDone         Key = "word_history_reset_done"
+	WordHistoryResetCancelled    Key = "word_history_reset_cancelled"
+	WordHistoryResetFailed       Key = "word_history_reset_failed"
+	WordBankCleared              Key = "word_bank_cleared"
+	WordBankWordRemoved          Key = "word_bank_word_removed"
+	WordBankResetFailed          Key = "word_bank_reset_failed"
+	LowerBandWordsStatus         Key = "lower_band_words_status"
+	LowerBandWordsUpdated        Key = "lower_band_words_updated"
+	LowerBandWordsFailed         Key = "lower_band_words_failed"
+	DifficultyStatus             Key = "difficulty_status"
+	DifficultyUpdated            Key = "difficulty_updated"
+	DifficultyLimit              Key = "difficulty_limit"
+	DifficultyFailed             Key = "difficulty_failed"
+
+	WordSearchUsage    Key = "word_search_usage"
+	WordSearchResult   Key = "word_search_result"
+	WordSearchNotFound Key = "word_search_not_found"
+	WordSearchFailed   Key = "word_search_failed"
+
+	ExportUsage        Key = "export_usage"
+	ExportRangeTooLong Key = "export_range_too_long"
+	ExportEmpty        Key = "export_empty"
+	ExportTitle        Key = "export_title"
+	ExportReady        Key = "export_ready"
+	ExportFailed       Key = "export_failed"
+
+	WebLoginCode   Key = "web_login_code"
+	WebLoginFailed Key = "web_login_failed"
+
+	PublicProfileStatus   Key = "public_profile_status"
+	PublicProfileEnabled  Key = "public_profile_enabled"
+	PublicProfileDisabled Key = "public_profile_disabled"
+	PublicProfileFailed   Key = "public_profile_failed"
+
+	DailyPushReviewAddLabel  Key = "daily_push_review_add_label"
+	DailyPushReviewAdded     Key = "daily_push_review_added"
+	DailyPushReviewAddFailed Key = "daily_push_review_add_failed"
+
+	QuizQuestionWordToMeaning Key = "quiz_question_word_to_meaning"
+	QuizQuestionMeaningToWord Key = "quiz_question_meaning_to_word"
+	QuizCorrect               Key = "quiz_correct"
+	QuizWrong                 Key = "quiz_wrong"
+	QuizFinished              Key = "quiz_finished"
+	QuizNotEnoughWords        Key = "quiz_not_enough_words"
+	QuizFailed                Key = "quiz_failed"
+	QuizStale                 Key = "quiz_stale"
+
+	InteractiveReviewStatus   Key = "interactive_review_status"
+	InteractiveReviewUpdated  Key = "interactive_review_updated"
+	InteractiveReviewFailed   Key = "interactive_review_failed"
+	InteractiveReviewIntro    Key = "interactive_review_intro"
+	InteractiveReviewCard     Key = "interactive_review_card"
+	InteractiveReviewAnswer   Key = "interactive_review_answer"
+	InteractiveReviewFinished Key = "interactive_review_finished"
+	InteractiveReviewStale    Key = "interactive_review_stale"
+	InteractiveReviewExpired  Key = "interactive_review_expired"
+	ReviewRememberedLabel     Key = "review_remembered_label"
+	ReviewForgottenLabel      Key = "review_forgotten_label"
+
+	DeckCreated     Key = "deck_created"
+	DeckExists      Key = "deck_exists"
+	DeckNameInvalid Key = "deck_name_invalid"
+	DeckLimit       Key = "deck_limit"
+	DeckFailed      Key = "deck_failed"
+	DeckList        Key = "deck_list"
+	DeckListEmpty   Key = "deck_list_empty"
+	DeckNotFound    Key = "deck_not_found"
+	DeckEmpty       Key = "deck_empty"
+	DeckHeader      Key = "deck_header"
+	DeckAddLabel    Key = "deck_add_label"
+	DeckAdded       Key = "deck_added"
+	DeckAddFailed   Key = "deck_add_failed"
+
+	RegenerateExampleLabel  Key = "regenerate_example_label"
+	ExampleRegenerated      Key = "example_regenerated"
+	ExampleRegenerateFailed Key = "example_regenerate_failed"
+
+	ContentReportLabel              Key = "content_report_label"
+	ContentReportWordLabel          Key = "content_report_word_label"
+	ContentReportReasonPrompt       Key = "content_report_reason_prompt"
+	ContentReportIncorrectLabel     Key = "content_report_incorrect_label"
+	ContentReportInappropriateLabel Key = "content_report_inappropriate_label"
+	ContentReportOtherLabel         Key = "content_report_other_label"
+	ContentReportReceived           Key = "content_report_received"
+	ContentReportFailed             Key = "content_report_failed"
+
+	BetaJoinRequested Key = "beta_join_requested"
+	BetaJoinPending   Key = "beta_join_pending"
+	BetaJoinApproved  Key = "beta_join_approved"
+	BetaJoinFailed    Key = "beta_join_failed"
+	BetaFeatureLocked Key = "beta_feature_locked"
+
+	CardFormatStatus  Key = "card_format_status"
+	CardFormatUpdated Key = "card_format_updated"
+	CardFormatFailed  Key = "card_format_failed"
+
+	PronunciationStatus  Key = "pronunciation_status"
+	PronunciationUpdated Key = "pronunciation_updated"
+	PronunciationFailed  Key = "pronunciation_failed"
+	PronunciationLocked  Key = "pronunciation_locked"
+
+	ModelStatus  Key = "model_status"
+	ModelUpdated Key = "model_updated"
+	ModelFailed  Key = "model_failed"
+
+	GrammarModeStatus  Key = "grammar_mode_status"
+	GrammarModeUpdated Key = "grammar_mode_updated"
+	GrammarModeFailed  Key = "grammar_mode_failed"
+	GrammarCorrection  Key = "grammar_correction"
+	GrammarFailed      Key = "grammar_failed"
+
+	ConsentPrompt         Key = "consent_prompt"
+	ConsentAcceptAllLabel Key = "consent_accept_all_label"
+	ConsentEssentialLabel Key = "consent_essential_label"
+	ConsentCustomizeLabel Key = "consent_customize_label"
+	ConsentStatus         Key = "consent_status"
+	ConsentToggleLabel    Key = "consent_toggle_label"
+	ConsentAnalyticsName  Key = "consent_analytics_name"
+	ConsentMarketingName  Key = "consent_marketing_name"
+	ConsentAIDataName     Key = "consent_ai_data_name"
+	ConsentUpdated        Key = "consent_updated"
+	ConsentFailed         Key = "consent_failed"
+
+	TermsPrompt       Key = "terms_prompt"
+	TermsPromptAlt    Key = "terms_prompt_alt"
+	TermsReadLabel    Key = "terms_read_label"
+	TermsAcceptLabel  Key = "terms_accept_label"
+	TermsAccepted     Key = "terms_accepted"
+	TermsAcceptFailed Key = "terms_accept_failed"
+
+	TranslationCard Key = "translation_card"
+
+	ArticleSummary       Key = "article_summary"
+	ArticleSummaryFailed Key = "article_summary_failed"
+
+	OpenAIRateLimited Key = "openai_rate_limited"
+
+	FeatureMaintenance       Key = "feature_maintenance"
+	KillSwitchTTSLabel       Key = "kill_switch_tts_label"
+	KillSwitchDailyPushLabel Key = "kill_switch_daily_push_label"
+
+	TranslationQuotaExceeded Key = "translation_quota_exceeded"
+	TranslationRateLimited   Key = "translation_rate_limited"
+
+	SupporterPayment          Key = "supporter_payment"
+	SupporterPaymentAlt       Key = "supporter_payment_alt"
+	SupporterPaymentLabel     Key = "supporter_payment_label"
+	SupporterPaymentFailed    Key = "supporter_payment_failed"
+	SupporterPaymentConfirmed Key = "supporter_payment_confirmed"
+	SupporterAlready          Key = "supporter_already"
+	SupporterUnavailable      Key = "supporter_unavailable"
+
+	TrialStarted     Key = "trial_started"
+	TrialAlreadyUsed Key = "trial_already_used"
+	TrialNotNeeded   Key = "trial_not_needed"
+	TrialFailed      Key = "trial_failed"
+	TrialEndingSoon  Key = "trial_ending_soon"
+	TrialExpired     Key = "trial_expired"
+
+	DailyPushHeader   Key = "daily_push_header"
+	DailyPushWord     Key = "daily_push_word"
+	DailyPushAckLabel Key = "daily_push_ack_label"
+	DailyPushAckReply Key = "daily_push_ack_reply"
+
+	ReviewEmpty     Key = "review_empty"
+	ReviewHeader    Key = "review_header"
+	ReviewSeparator Key = "review_separator"
+	WordRecordCard  Key = "word_record_card"
+	ReviewWord      Key = "review_word"
+
+	GroupGreeting       Key = "group_greeting"
+	GroupTranslateUsage Key = "group_translate_usage"
+	GroupRanking        Key = "group_ranking"
+	GroupRankingEmpty   Key = "group_ranking_empty"
+	GroupRankingFailed  Key = "group_ranking_failed"
+	GroupRecapHeader    Key = "group_recap_header"
+	GroupMemberFallback Key = "group_member_fallback"
+
+	WordSourceTranslationLabel Key = "word_source_translation_label"
+	WordSourceDailyPushLabel   Key = "word_source_daily_push_label"
+	WordSourceImportedLabel    Key = "word_source_imported_label"
+	WordSourceCuratedPackLabel Key = "word_source_curated_pack_label"
+
+	ReviewSourceStatus  Key = "review_source_status"
+	ReviewSourceUpdated Key = "review_source_updated"
+	ReviewSourceFailed  Key = "review_source_failed"
+
+	StreakMilestone Key = "streak_milestone"
+)
+
+// Data holds the template variables passed to Render
+type Data map[string]interface{}
+
+type catalogFile struct {
+	Messages map[string]string `yaml:"messages"`
+}
+
+// 模板可用的輔助函式
+var funcs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}
+
+var templates = mustParseCatalog(catalogYAML)
+
+func mustParseCatalog(raw []byte) map[Key]*template.Template {
+	var catalog catalogFile
+	if err := yaml.Unmarshal(raw, &catalog); err != nil {
+		panic(fmt.Errorf("error parsing message catalog yaml: %w", err))
+	}
+
+	parsed := make(map[Key]*template.Template, len(catalog.Messages))
+	for name, text := range catalog.Messages {
+		tmpl, err := template.New(name).Option("missingkey=zero").Funcs(funcs).Parse(text)
+		if err != nil {
+			panic(fmt.Errorf("error parsing message template %q: %w", name, err))
+		}
+		parsed[Key(name)] = tmpl
+	}
+	return parsed
+}
+
+// Has reports whether the catalog defines the given key
+func Has(key Key) bool {
+	_, ok := templates[key]
+	return ok
+}
+
+// Render executes the named template with the given data.
+// Unknown keys or execution errors fall back to the key itself so a copy mistake never blocks a reply.
+func Render(key Key, data interface{}) string {
+	tmpl, ok := templates[key]
+	if !ok {
+		return string(key)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return string(key)
+	}
+	return buf.String()
+}
+
+// Text renders a template that takes no variables
+func Text(key Key) string {
+	return Render(key, nil)
+}
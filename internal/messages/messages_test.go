@@ -0,0 +1,178 @@
+package messages
+
+import (
+	"encoding/json"
+	"language-assistant/internal/courses"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("Template variables", func(t *testing.T) {
+		got := Render(ScoreSet, Data{"CourseName": "雅思", "Score": "6.5"})
+		expected := "✅ 已設定你的雅思分數為 6.5 分！"
+		if got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("Score invalid shows example for decimal scores", func(t *testing.T) {
+		got := Render(ScoreInvalid, Data{"CourseName": "雅思", "ScoreRange": "0-9", "ScoreExample": "6.5", "Decimal": true})
+		expected := "雅思分數應該在 0-9 分之間（例如：6.5），請重新輸入。"
+		if got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("Unknown key falls back to key", func(t *testing.T) {
+		if got := Text(Key("does_not_exist")); got != "does_not_exist" {
+			t.Errorf("Expected fallback to key, got %q", got)
+		}
+	})
+
+	// Test case: 個人設定的條件區塊
+	t.Run("User settings complete", func(t *testing.T) {
+		got := Render(UserSettings, Data{
+			"DisplayName": "Barney",
+			"CourseName":  "多益 (TOEIC)",
+			"LevelInfo":   "750 分",
+			"DailyWords":  10,
+			"PushTime":    "08:00",
+			"Timezone":    "Asia/Taipei",
+			"Complete":    true,
+		})
+		expected := "⚙️ 個人設定資訊\n\n" +
+			"👤 用戶名稱：Barney\n" +
+			"📚 課程：多益 (TOEIC)\n" +
+			"📊 程度：750 分\n" +
+			"📱 每日推播：10 個單字\n" +
+			"⏰ 推播時間：08:00\n" +
+			"🌏 時區：Asia/Taipei\n" +
+			"\n" +
+			"✅ 設定已完成！\n\n💡 可使用「/設定推播」重新調整推播設定"
+		if got != expected {
+			t.Errorf("User settings mismatch.\nExpected:\n%s\nGot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("User settings with streak", func(t *testing.T) {
+		got := Render(UserSettings, Data{"Timezone": "Asia/Taipei", "Streak": 3, "LongestStreak": 12})
+		if !strings.Contains(got, "🌏 時區：Asia/Taipei\n🔥 連續學習：3 天（最長 12 天）\n\n") {
+			t.Errorf("Expected streak line after timezone, got:\n%s", got)
+		}
+	})
+
+	t.Run("Next push", func(t *testing.T) {
+		got := Render(NextPush, Data{"DaysAhead": 1, "Date": "3/2", "Time": "08:00", "Timezone": "Asia/Taipei", "Hours": 14, "Minutes": 840})
+		if got != "下次推播：明天 08:00 (台北時間)，約 14 小時後" {
+			t.Errorf("Unexpected next push text: %s", got)
+		}
+
+		got = Render(NextPush, Data{"DaysAhead": 0, "Date": "3/1", "Time": "19:00", "Timezone": "Asia/Tokyo", "Hours": 0, "Minutes": 25})
+		if got != "下次推播：今天 19:00 (Asia/Tokyo)，約 25 分鐘後" {
+			t.Errorf("Unexpected next push text: %s", got)
+		}
+	})
+
+	t.Run("User settings incomplete", func(t *testing.T) {
+		got := Render(UserSettings, Data{})
+		expected := "⚙️ 個人設定資訊\n\n" +
+			"📚 課程：尚未選擇\n" +
+			"📊 程度：尚未設定\n" +
+			"📱 每日推播：尚未設定\n" +
+			"⏰ 推播時間：尚未設定\n" +
+			"\n" +
+			"⚠️ 設定尚未完整\n\n💡 使用「/設定推播」完成剩餘設定"
+		if got != expected {
+			t.Errorf("User settings mismatch.\nExpected:\n%s\nGot:\n%s", expected, got)
+		}
+	})
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("Quick replies attached", func(t *testing.T) {
+		built := Build(PushSettingsCourseSelected, Data{"CourseName": "多益"})
+		if len(built) != 1 {
+			t.Fatalf("Expected 1 message, got %d", len(built))
+		}
+
+		payload, err := json.Marshal(built[0])
+		if err != nil {
+			t.Fatalf("Unexpected marshal error: %v", err)
+		}
+		var decoded struct {
+			Text       string `json:"text"`
+			QuickReply struct {
+				Items []struct{} `json:"items"`
+			} `json:"quickReply"`
+		}
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("Unexpected unmarshal error: %v", err)
+		}
+		if len(decoded.QuickReply.Items) != len(DailyWordsOptions) {
+			t.Errorf("Expected %d quick replies, got %d", len(DailyWordsOptions), len(decoded.QuickReply.Items))
+		}
+		if !strings.Contains(decoded.Text, "多益") {
+			t.Errorf("Expected rendered course name in text, got %q", decoded.Text)
+		}
+	})
+
+	t.Run("Greeting includes course carousel", func(t *testing.T) {
+		if built := Build(Greeting, nil); len(built) != 2 {
+			t.Errorf("Expected text + carousel, got %d messages", len(built))
+		}
+	})
+
+	t.Run("Course carousel lists registered courses", func(t *testing.T) {
+		carousel := CourseSelectionCarousel()
+		if len(carousel.Columns) != len(courses.All()) {
+			t.Fatalf("Expected %d columns, got %d", len(courses.All()), len(carousel.Columns))
+		}
+		if carousel.Columns[0].Title != "📘 多益" {
+			t.Errorf("Unexpected first column title %q", carousel.Columns[0].Title)
+		}
+	})
+
+	t.Run("Plain text message", func(t *testing.T) {
+		if built := Build(SetupFailed, nil); len(built) != 1 {
+			t.Errorf("Expected 1 message, got %d", len(built))
+		}
+	})
+}
+
+func TestRenderArticleSummary(t *testing.T) {
+	type example struct{ En string }
+	type word struct {
+		Word, PartOfSpeech, Meaning string
+		Example                     example
+	}
+
+	got := Render(ArticleSummary, Data{
+		"Summary":       "摘要內容",
+		"KeyVocabulary": []word{{Word: "postpone", PartOfSpeech: "v.", Meaning: "延後", Example: example{En: "They postponed it."}}},
+	})
+	expected := "📰 文章摘要\n摘要內容\n\n🔑 關鍵單字\n\n1. 【postpone】(v.) 延後\n   They postponed it.\n\n💡 這些單字已加入今天的單字紀錄"
+	if got != expected {
+		t.Errorf("Article summary mismatch.\nExpected:\n%q\nGot:\n%q", expected, got)
+	}
+}
+
+func TestRenderGrammarCorrection(t *testing.T) {
+	type correction struct{ Original, Corrected, Explanation string }
+
+	got := Render(GrammarCorrection, Data{
+		"Corrected":   "She goes to school.",
+		"Translation": "她去上學。",
+		"Corrections": []correction{{Original: "go", Corrected: "goes", Explanation: "主詞是第三人稱單數，動詞要加 s"}},
+	})
+	expected := "✍️ 文法修正\n✅ She goes to school.\n\n1. go → goes\n   主詞是第三人稱單數，動詞要加 s\n\n🈶 她去上學。"
+	if got != expected {
+		t.Errorf("Grammar correction mismatch.\nExpected:\n%q\nGot:\n%q", expected, got)
+	}
+
+	got = Render(GrammarCorrection, Data{"Corrected": "I like it.", "Translation": "我喜歡。", "Corrections": []correction{}})
+	expected = "✍️ 文法修正\n👍 這個句子沒有文法錯誤！\nI like it.\n\n🈶 我喜歡。"
+	if got != expected {
+		t.Errorf("Grammar correction mismatch.\nExpected:\n%q\nGot:\n%q", expected, got)
+	}
+}
@@ -0,0 +1,105 @@
+package messages
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+const (
+	// MaxTextLength LINE 單則文字訊息的長度上限（以 Length 計算）
+	MaxTextLength = 5000
+	// MaxMessagesPerRequest LINE 單次 reply/push 最多可帶的訊息數
+	MaxMessagesPerRequest = 5
+)
+
+// Length counts text the way LINE enforces its limits: in UTF-16 code units. len() counts a CJK character as
+// 3 bytes and utf8.RuneCountInString counts an emoji outside the BMP (e.g. 📚) as 1, while LINE counts them as
+// 1 and 2 respectively
+func Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16Len(r)
+	}
+	return length
+}
+
+// Split joins parts with sep into as few texts as possible, each within MaxTextLength. Texts only break
+// between parts (e.g. between word cards); a single part that is too long is broken at line boundaries,
+// and a single line that is too long at character boundaries
+func Split(parts []string, sep string) []string {
+	return split(parts, sep, MaxTextLength)
+}
+
+// TextMessages wraps texts from Split as LINE text messages; callers sending more than MaxMessagesPerRequest
+// must batch them
+func TextMessages(texts []string) []linebot.SendingMessage {
+	result := make([]linebot.SendingMessage, 0, len(texts))
+	for _, text := range texts {
+		result = append(result, linebot.NewTextMessage(text))
+	}
+	return result
+}
+
+// utf16Len 回傳 r 以 UTF-16 編碼時佔的長度，BMP 以外（多數 emoji）佔 2
+func utf16Len(r rune) int {
+	if r > 0xFFFF && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+func split(parts []string, sep string, limit int) []string {
+	var texts []string
+	var current strings.Builder
+	currentLength, started := 0, false
+	sepLength := Length(sep)
+
+	for _, part := range parts {
+		for _, piece := range fit(part, limit) {
+			pieceLength := Length(piece)
+			if started && currentLength+sepLength+pieceLength > limit {
+				texts = append(texts, current.String())
+				current.Reset()
+				currentLength, started = 0, false
+			}
+			if started {
+				current.WriteString(sep)
+				currentLength += sepLength
+			}
+			current.WriteString(piece)
+			currentLength += pieceLength
+			started = true
+		}
+	}
+	if started {
+		texts = append(texts, current.String())
+	}
+	return texts
+}
+
+// fit breaks a single part that exceeds limit into pieces that don't
+func fit(text string, limit int) []string {
+	if Length(text) <= limit {
+		return []string{text}
+	}
+	if lines := strings.Split(text, "\n"); len(lines) > 1 {
+		return split(lines, "\n", limit)
+	}
+
+	var pieces []string
+	start, length := 0, 0
+	for i, r := range text {
+		runeLength := utf16Len(r)
+		if length+runeLength > limit {
+			pieces = append(pieces, text[start:i])
+			start, length = i, 0
+		}
+		length += runeLength
+	}
+	if start < len(text) || len(pieces) == 0 {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
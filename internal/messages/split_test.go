@@ -0,0 +1,60 @@
+package messages
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLength(t *testing.T) {
+	tests := map[string]int{
+		"book":      4,
+		"書本":        2, // CJK 每字佔 1，不是 3 bytes
+		"📚":         2, // BMP 以外的 emoji 佔 2
+		"【本日單字回顧】📚": 10,
+	}
+	for text, want := range tests {
+		if got := Length(text); got != want {
+			t.Errorf("Length(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	t.Run("keeps short output in one message", func(t *testing.T) {
+		got := Split([]string{"a", "b"}, "\n---\n")
+		if want := []string{"a\n---\nb"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Split() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("breaks between parts", func(t *testing.T) {
+		got := split([]string{"單字一", "單字二", "單字三"}, "|", 7)
+		if want := []string{"單字一|單字二", "單字三"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("split() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("breaks an oversized part at lines, then characters", func(t *testing.T) {
+		got := split([]string{"ab\ncd", "📚📚📚"}, "|", 4)
+		if want := []string{"ab", "cd", "📚📚", "📚"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("split() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("every message fits the LINE limit", func(t *testing.T) {
+		parts := make([]string, 200)
+		for i := range parts {
+			parts[i] = strings.Repeat("字📚", 20)
+		}
+		texts := Split(parts, "\n-------------------\n")
+		if len(texts) < 2 {
+			t.Fatalf("Expected output to be split, got %d message", len(texts))
+		}
+		for i, text := range texts {
+			if Length(text) > MaxTextLength {
+				t.Errorf("Message %d has length %d, over %d", i, Length(text), MaxTextLength)
+			}
+		}
+	})
+}
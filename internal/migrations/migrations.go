@@ -0,0 +1,101 @@
+// Package migrations 提供資料格式變更用的 migration 框架：逐頁 Scan 整個 table、轉換 item 後寫回，
+// 並在每頁之後記錄 checkpoint，中斷（例如 Lambda timeout）後可從上次的位置繼續。
+//
+// 新增 migration 時在本 package 新增檔案，於 init 呼叫 Register。
+// Transform 必須是 idempotent 的：頁面中途失敗時整頁會重跑，已轉換過的 item 也會再次經過 Transform。
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Logical table names a migration can target; resolved to real table names via Tables
+const (
+	TableVocabulary    = "vocabulary"
+	TableUser          = "user"
+	TableScheduleAudit = "schedule-audit"
+	TablePairing       = "pairing"
+	TableAnalytics     = "analytics"
+)
+
+// Item is a raw DynamoDB item
+type Item = map[string]types.AttributeValue
+
+// Change describes the writes a migration makes for one scanned item
+type Change struct {
+	Puts    []Item // 要寫入（覆寫）的 item
+	Deletes []Item // 要刪除的 item key，例如拆分或改 key 後的舊 item
+	// Merge 不為 nil 時，Put 的 key 已有 item 會先讀出並交給 Merge 合併後再寫入，
+	// 避免覆寫 migration 期間以新格式寫入的資料；必須是 idempotent 的（同一筆資料合併兩次不會重複）
+	Merge func(existing, put Item) (Item, error)
+}
+
+// Migration is a versioned data shape change applied to every item of one table
+type Migration struct {
+	ID          string // 唯一且依執行順序排序，例如 "20250601-per-word-items"
+	Description string
+	Table       string // 上方的 Table* 常數
+	// Transform 回傳 nil 表示此 item 不需變更
+	Transform func(item Item) (*Change, error)
+	// Count 選填，Verify 時依回傳的名稱累計每個 item 的數量（例如舊格式與新格式的 item 數、單字數），
+	// 用來確認 migration 前後的資料量一致
+	Count func(item Item) map[string]int
+}
+
+// ErrUnknownMigration is returned when a migration ID is not registered
+var ErrUnknownMigration = errors.New("unknown migration")
+
+var registry = map[string]Migration{}
+
+// Register 登記 migration，ID 重複或欄位缺漏時 panic（只會在 init 時呼叫）
+func Register(m Migration) {
+	if m.ID == "" || m.Table == "" || m.Transform == nil {
+		panic(fmt.Sprintf("migration %q is missing id, table or transform", m.ID))
+	}
+	if _, ok := registry[m.ID]; ok {
+		panic(fmt.Sprintf("migration %q registered twice", m.ID))
+	}
+	registry[m.ID] = m
+}
+
+// Get 取得已登記的 migration
+func Get(id string) (Migration, error) {
+	m, ok := registry[id]
+	if !ok {
+		return Migration{}, fmt.Errorf("%w: %s", ErrUnknownMigration, id)
+	}
+	return m, nil
+}
+
+// All 依 ID 排序回傳所有已登記的 migration
+func All() []Migration {
+	result := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// TablesFromEnv 由環境變數組出邏輯名稱 → 實際 table 名稱，Lambda 與 CLI 共用；未設定的 table 不會出現在結果中
+func TablesFromEnv(getenv func(string) string) map[string]string {
+	envNames := map[string]string{
+		TableVocabulary:    "VOCABULARY_TABLE_NAME",
+		TableUser:          "USER_TABLE_NAME",
+		TableScheduleAudit: "AUDIT_TABLE_NAME",
+		TablePairing:       "PAIRING_TABLE_NAME",
+		TableAnalytics:     "ANALYTICS_TABLE_NAME",
+	}
+
+	tables := map[string]string{}
+	for table, env := range envNames {
+		if name := getenv(env); name != "" {
+			tables[table] = name
+		}
+	}
+	return tables
+}
@@ -0,0 +1,302 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultPageSize = 100
+	// deadlineMargin 距離 Lambda timeout 不足此時間時不再開始新的一頁，留時間寫入 checkpoint
+	deadlineMargin = 30 * time.Second
+)
+
+// Options controls a single run of a migration
+type Options struct {
+	DryRun   bool  // 只計算會變更的 item 數，不寫入資料也不更新 checkpoint
+	Reset    bool  // 忽略既有 checkpoint，從頭掃描
+	PageSize int32 // 每頁 Scan 的 item 數，0 為預設值
+	MaxPages int   // 本次最多處理幾頁，0 為不限制
+}
+
+// Runner scans a table page by page, applies a migration's Transform and records checkpoints.
+// Holds no mutable state, but two runs of the same migration race on its checkpoint; run one at a time
+type Runner struct {
+	logger      *logrus.Entry
+	dynamodb    utils.DynamoDbAPI
+	checkpoints utils.MigrationCheckpointRepository
+	tables      map[string]string
+	now         func() time.Time
+}
+
+func NewRunner(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, checkpoints utils.MigrationCheckpointRepository, tables map[string]string) *Runner {
+	return &Runner{
+		logger:      logger,
+		dynamodb:    dynamodb,
+		checkpoints: checkpoints,
+		tables:      tables,
+		now:         time.Now,
+	}
+}
+
+// Run 從上次的 checkpoint 繼續執行 migration，直到掃描完整個 table、達到 MaxPages 或接近 ctx 的 deadline。
+// 回傳的 checkpoint 狀態為 running 時表示尚未完成，需要再執行一次
+func (r *Runner) Run(ctx context.Context, m Migration, opts Options) (*models.MigrationCheckpoint, error) {
+	tableName, ok := r.tables[m.Table]
+	if !ok {
+		return nil, fmt.Errorf("table %q for migration %s is not configured", m.Table, m.ID)
+	}
+	logger := r.logger.WithFields(logrus.Fields{"migrationId": m.ID, "table": tableName, "dryRun": opts.DryRun})
+
+	checkpoint, err := r.loadCheckpoint(m.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint.Status == models.MigrationStatusCompleted {
+		logger.Info("Migration already completed")
+		return checkpoint, nil
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	for page := 0; opts.MaxPages == 0 || page < opts.MaxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return checkpoint, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && deadline.Sub(r.now()) < deadlineMargin {
+			logger.Info("Approaching deadline, stopping migration at checkpoint")
+			break
+		}
+
+		output, err := r.dynamodb.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(tableName),
+			ExclusiveStartKey: checkpoint.LastEvaluatedKey,
+			Limit:             aws.Int32(pageSize),
+		})
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan table for migration")
+			return checkpoint, fmt.Errorf("failed to scan %s: %w", tableName, err)
+		}
+
+		changed, err := r.applyPage(ctx, tableName, m, output.Items, opts.DryRun)
+		if err != nil {
+			logger.WithError(err).Error("Failed to migrate page")
+			return checkpoint, err
+		}
+
+		checkpoint.Scanned += len(output.Items)
+		checkpoint.Changed += changed
+		checkpoint.LastEvaluatedKey = output.LastEvaluatedKey
+		checkpoint.UpdatedAt = r.now().UTC().Format(time.RFC3339)
+		if len(output.LastEvaluatedKey) == 0 {
+			checkpoint.Status = models.MigrationStatusCompleted
+			checkpoint.CompletedAt = checkpoint.UpdatedAt
+		}
+
+		if !opts.DryRun {
+			if err := r.checkpoints.SaveMigrationCheckpoint(*checkpoint); err != nil {
+				return checkpoint, err
+			}
+		}
+		if checkpoint.Status == models.MigrationStatusCompleted {
+			break
+		}
+	}
+
+	logger.WithFields(logrus.Fields{
+		"status":  checkpoint.Status,
+		"scanned": checkpoint.Scanned,
+		"changed": checkpoint.Changed,
+	}).Info("Migration run finished")
+	return checkpoint, nil
+}
+
+func (r *Runner) loadCheckpoint(migrationID string, opts Options) (*models.MigrationCheckpoint, error) {
+	fresh := &models.MigrationCheckpoint{
+		MigrationID: migrationID,
+		Status:      models.MigrationStatusRunning,
+		StartedAt:   r.now().UTC().Format(time.RFC3339),
+	}
+	// dry run 一律從頭計算，不影響正式執行的進度
+	if opts.DryRun {
+		return fresh, nil
+	}
+	if opts.Reset {
+		if err := r.checkpoints.DeleteMigrationCheckpoint(migrationID); err != nil {
+			return nil, err
+		}
+		return fresh, nil
+	}
+
+	checkpoint, err := r.checkpoints.GetMigrationCheckpoint(migrationID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil {
+		return fresh, nil
+	}
+	return checkpoint, nil
+}
+
+// applyPage 轉換一頁的 item 並寫回，回傳有變更的 item 數
+func (r *Runner) applyPage(ctx context.Context, tableName string, m Migration, items []Item, dryRun bool) (int, error) {
+	changed := 0
+	for _, item := range items {
+		if isCheckpointItem(item) {
+			continue
+		}
+
+		change, err := m.Transform(item)
+		if err != nil {
+			return changed, fmt.Errorf("failed to transform item %s: %w", keyOf(item), err)
+		}
+		if change == nil || (len(change.Puts) == 0 && len(change.Deletes) == 0) {
+			continue
+		}
+		changed++
+		if dryRun {
+			continue
+		}
+
+		// 先寫入新 item 再刪除舊 item，中途失敗時最多留下重複資料而不會遺失
+		for _, put := range change.Puts {
+			if change.Merge != nil {
+				if put, err = r.mergeExisting(ctx, tableName, put, change.Merge); err != nil {
+					return changed, err
+				}
+			}
+			if _, err := r.dynamodb.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(tableName), Item: put}); err != nil {
+				return changed, fmt.Errorf("failed to put migrated item %s: %w", keyOf(put), err)
+			}
+		}
+		for _, key := range change.Deletes {
+			if _, err := r.dynamodb.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(tableName), Key: key}); err != nil {
+				return changed, fmt.Errorf("failed to delete migrated item %s: %w", keyOf(key), err)
+			}
+		}
+	}
+	return changed, nil
+}
+
+// mergeExisting 讀出與 put 相同 key 的 item，存在時回傳合併後的 item
+func (r *Runner) mergeExisting(ctx context.Context, tableName string, put Item, merge func(existing, put Item) (Item, error)) (Item, error) {
+	key := Item{}
+	for _, name := range []string{"pk", "sk"} {
+		if v, ok := put[name]; ok {
+			key[name] = v
+		}
+	}
+	output, err := r.dynamodb.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(tableName),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing item %s: %w", keyOf(key), err)
+	}
+	if output.Item == nil {
+		return put, nil
+	}
+	merged, err := merge(output.Item, put)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge item %s: %w", keyOf(key), err)
+	}
+	return merged, nil
+}
+
+// VerifyResult is the outcome of scanning a table after (or before) a migration
+type VerifyResult struct {
+	MigrationID string         `json:"migrationId"`
+	Scanned     int            `json:"scanned"`
+	Pending     int            `json:"pending"`          // Transform 仍會變更的 item 數，migration 完成後應為 0
+	Counts      map[string]int `json:"counts,omitempty"` // Migration.Count 的累計
+}
+
+// Verify 掃描整個 table，計算 Transform 仍會變更的 item 數與 Migration.Count 的累計，不寫入任何資料。
+// 執行前後各跑一次可比對資料量；沒有 deadline 檢查，會掃描到結束或 ctx 被取消
+func (r *Runner) Verify(ctx context.Context, m Migration, pageSize int32) (*VerifyResult, error) {
+	tableName, ok := r.tables[m.Table]
+	if !ok {
+		return nil, fmt.Errorf("table %q for migration %s is not configured", m.Table, m.ID)
+	}
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	result := &VerifyResult{MigrationID: m.ID, Counts: map[string]int{}}
+	var startKey Item
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		output, err := r.dynamodb.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(tableName),
+			ExclusiveStartKey: startKey,
+			Limit:             aws.Int32(pageSize),
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to scan %s: %w", tableName, err)
+		}
+
+		for _, item := range output.Items {
+			if isCheckpointItem(item) {
+				continue
+			}
+			result.Scanned++
+			change, err := m.Transform(item)
+			if err != nil {
+				return result, fmt.Errorf("failed to transform item %s: %w", keyOf(item), err)
+			}
+			if change != nil && (len(change.Puts) > 0 || len(change.Deletes) > 0) {
+				result.Pending++
+			}
+			if m.Count != nil {
+				for name, n := range m.Count(item) {
+					result.Counts[name] += n
+				}
+			}
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = output.LastEvaluatedKey
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"migrationId": m.ID,
+		"scanned":     result.Scanned,
+		"pending":     result.Pending,
+		"counts":      result.Counts,
+	}).Info("Migration verification finished")
+	return result, nil
+}
+
+// isCheckpointItem 略過 migration 自己寫在 vocabulary table 的進度紀錄
+func isCheckpointItem(item Item) bool {
+	pk, ok := item["pk"].(*types.AttributeValueMemberS)
+	return ok && strings.HasPrefix(pk.Value, utils.MigrationCheckpointPrefix)
+}
+
+// keyOf 取出 item 的 key 欄位供錯誤訊息使用
+func keyOf(item Item) string {
+	var parts []string
+	for _, name := range []string{"pk", "sk", "userId"} {
+		if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+			parts = append(parts, name+"="+v.Value)
+		}
+	}
+	return strings.Join(parts, ",")
+}
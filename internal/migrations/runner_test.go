@@ -0,0 +1,134 @@
+package migrations
+
+import (
+	"context"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeTable 依 pk 排序的記憶體 table，Scan 以 pk 當分頁 key
+type fakeTable struct {
+	utils.DynamoDbAPI
+	pks   []string
+	items map[string]Item
+	puts  int
+}
+
+func newFakeTable(pks ...string) *fakeTable {
+	table := &fakeTable{pks: pks, items: map[string]Item{}}
+	for _, pk := range pks {
+		table.items[pk] = Item{"pk": &types.AttributeValueMemberS{Value: pk}}
+	}
+	return table
+}
+
+func (f *fakeTable) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	start := 0
+	if params.ExclusiveStartKey != nil {
+		last := params.ExclusiveStartKey["pk"].(*types.AttributeValueMemberS).Value
+		for i, pk := range f.pks {
+			if pk == last {
+				start = i + 1
+			}
+		}
+	}
+	end := start + int(aws.ToInt32(params.Limit))
+	if end > len(f.pks) {
+		end = len(f.pks)
+	}
+
+	output := &dynamodb.ScanOutput{}
+	for _, pk := range f.pks[start:end] {
+		output.Items = append(output.Items, f.items[pk])
+	}
+	if end < len(f.pks) {
+		output.LastEvaluatedKey = Item{"pk": &types.AttributeValueMemberS{Value: f.pks[end-1]}}
+	}
+	return output, nil
+}
+
+func (f *fakeTable) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.puts++
+	f.items[params.Item["pk"].(*types.AttributeValueMemberS).Value] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+type memoryCheckpoints map[string]models.MigrationCheckpoint
+
+func (m memoryCheckpoints) GetMigrationCheckpoint(migrationID string) (*models.MigrationCheckpoint, error) {
+	checkpoint, ok := m[migrationID]
+	if !ok {
+		return nil, nil
+	}
+	return &checkpoint, nil
+}
+
+func (m memoryCheckpoints) SaveMigrationCheckpoint(checkpoint models.MigrationCheckpoint) error {
+	m[checkpoint.MigrationID] = checkpoint
+	return nil
+}
+
+func (m memoryCheckpoints) DeleteMigrationCheckpoint(migrationID string) error {
+	delete(m, migrationID)
+	return nil
+}
+
+func TestRunnerResumesFromCheckpoint(t *testing.T) {
+	table := newFakeTable("a", "b", utils.MigrationCheckpointPrefix+"other", "c", "d")
+	checkpoints := memoryCheckpoints{}
+	runner := NewRunner(logrus.NewEntry(logrus.New()), table, checkpoints, map[string]string{TableVocabulary: "vocabulary"})
+
+	migration := Migration{
+		ID:    "test-add-version",
+		Table: TableVocabulary,
+		Transform: func(item Item) (*Change, error) {
+			if _, ok := item["version"]; ok {
+				return nil, nil
+			}
+			out := Item{"version": &types.AttributeValueMemberN{Value: strconv.Itoa(2)}}
+			for k, v := range item {
+				out[k] = v
+			}
+			return &Change{Puts: []Item{out}}, nil
+		},
+	}
+
+	dryRun, err := runner.Run(context.Background(), migration, Options{DryRun: true, PageSize: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dryRun.Changed != 4 || table.puts != 0 || len(checkpoints) != 0 {
+		t.Fatalf("Dry run should count 4 changes without writing, got changed=%d puts=%d checkpoints=%d", dryRun.Changed, table.puts, len(checkpoints))
+	}
+
+	first, err := runner.Run(context.Background(), migration, Options{PageSize: 2, MaxPages: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.Status != models.MigrationStatusRunning || first.Scanned != 2 || table.puts != 2 {
+		t.Fatalf("Expected first run to stop after one page, got %+v (puts=%d)", first, table.puts)
+	}
+
+	second, err := runner.Run(context.Background(), migration, Options{PageSize: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.Status != models.MigrationStatusCompleted || second.Scanned != 5 || second.Changed != 4 || table.puts != 4 {
+		t.Fatalf("Expected second run to resume and complete, got %+v (puts=%d)", second, table.puts)
+	}
+	if _, ok := table.items[utils.MigrationCheckpointPrefix+"other"]["version"]; ok {
+		t.Errorf("Checkpoint items should not be migrated")
+	}
+
+	again, err := runner.Run(context.Background(), migration, Options{PageSize: 2})
+	if err != nil || again.Scanned != 5 || table.puts != 4 {
+		t.Errorf("Completed migrations should not rescan, got %+v (puts=%d, err=%v)", again, table.puts, err)
+	}
+}
@@ -0,0 +1,140 @@
+package migrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"language-assistant/internal/models"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// VocabularyUserKeysID 將以日期為 key 的舊單字紀錄改寫為 PK = userId#vocabulary、SK = date
+const VocabularyUserKeysID = "20261018-vocabulary-user-keys"
+
+// 單字紀錄 PK 的後綴，與 repository 寫入的格式一致
+const vocabularyKeySuffix = "#vocabulary"
+
+func init() {
+	Register(Migration{
+		ID:          VocabularyUserKeysID,
+		Description: "rewrite date-keyed vocabulary items to pk=userId#vocabulary, sk=date",
+		Table:       TableVocabulary,
+		Transform:   migrateVocabularyKey,
+		Count:       countVocabularyItems,
+	})
+}
+
+// legacyVocabulary 判斷 item 是否為舊格式的單字紀錄：有 userId、date 與 words 欄位，但 key 不是新格式。
+// 只依欄位判斷，不假設舊 key 的組成（PK 是日期或 userId 都能處理）
+func legacyVocabulary(item Item) (userID, date string, ok bool) {
+	userID, date = stringAttr(item, "userId"), stringAttr(item, "date")
+	if userID == "" || date == "" {
+		return "", "", false
+	}
+	if _, hasWords := item["words"].(*types.AttributeValueMemberS); !hasWords {
+		return "", "", false
+	}
+	if stringAttr(item, "pk") == userID+vocabularyKeySuffix && stringAttr(item, "sk") == date {
+		return "", "", false
+	}
+	return userID, date, true
+}
+
+// migrateVocabularyKey 以新 key 寫入同樣的欄位並刪除舊 item；新 key 已有當天的紀錄時合併兩邊的單字
+func migrateVocabularyKey(item Item) (*Change, error) {
+	userID, date, ok := legacyVocabulary(item)
+	if !ok {
+		return nil, nil
+	}
+
+	put := Item{}
+	for name, value := range item {
+		put[name] = value
+	}
+	put["pk"] = &types.AttributeValueMemberS{Value: userID + vocabularyKeySuffix}
+	put["sk"] = &types.AttributeValueMemberS{Value: date}
+
+	oldKey := Item{}
+	for _, name := range []string{"pk", "sk"} {
+		if value, ok := item[name]; ok {
+			oldKey[name] = value
+		}
+	}
+	return &Change{Puts: []Item{put}, Deletes: []Item{oldKey}, Merge: mergeVocabularyWords}, nil
+}
+
+// mergeVocabularyWords 合併兩筆同一天的單字紀錄，以 (單字, 時間) 去除重複並依時間排序，
+// 重跑時已合併過的單字不會重複出現；其他欄位保留新格式的值，updatedAt 取較新者
+func mergeVocabularyWords(existing, put Item) (Item, error) {
+	existingWords, err := parseWords(existing)
+	if err != nil {
+		return nil, err
+	}
+	putWords, err := parseWords(put)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var words []models.WordRecord
+	for _, word := range append(existingWords, putWords...) {
+		key := word.Word + "\x00" + word.Timestamp
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		words = append(words, word)
+	}
+	sort.SliceStable(words, func(i, j int) bool { return words[i].Timestamp < words[j].Timestamp })
+
+	wordsJSON, err := json.Marshal(words)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged words: %w", err)
+	}
+	merged := Item{}
+	for name, value := range existing {
+		merged[name] = value
+	}
+	merged["words"] = &types.AttributeValueMemberS{Value: string(wordsJSON)}
+	if stringAttr(put, "updatedAt") > stringAttr(existing, "updatedAt") {
+		merged["updatedAt"] = put["updatedAt"]
+	}
+	return merged, nil
+}
+
+// countVocabularyItems 分別累計舊格式與新格式的單字紀錄數與單字數；
+// migration 完成後 legacyItems 應為 0，vocabularyWords 應等於執行前兩者單字數的總和（扣除合併時去除的重複）
+func countVocabularyItems(item Item) map[string]int {
+	words, err := parseWords(item)
+	if err != nil {
+		return map[string]int{"unparsableItems": 1}
+	}
+	if _, _, legacy := legacyVocabulary(item); legacy {
+		return map[string]int{"legacyItems": 1, "legacyWords": len(words)}
+	}
+	if strings.HasSuffix(stringAttr(item, "pk"), vocabularyKeySuffix) {
+		return map[string]int{"vocabularyItems": 1, "vocabularyWords": len(words)}
+	}
+	return nil
+}
+
+func parseWords(item Item) ([]models.WordRecord, error) {
+	attr, ok := item["words"].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, nil
+	}
+	var words []models.WordRecord
+	if err := json.Unmarshal([]byte(attr.Value), &words); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal words of %s: %w", keyOf(item), err)
+	}
+	return words, nil
+}
+
+func stringAttr(item Item, name string) string {
+	if attr, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return attr.Value
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func vocabularyItem(pk, sk, words, updatedAt string) Item {
+	return Item{
+		"pk":        &types.AttributeValueMemberS{Value: pk},
+		"sk":        &types.AttributeValueMemberS{Value: sk},
+		"userId":    &types.AttributeValueMemberS{Value: "U1"},
+		"date":      &types.AttributeValueMemberS{Value: "2025-01-02"},
+		"words":     &types.AttributeValueMemberS{Value: words},
+		"updatedAt": &types.AttributeValueMemberS{Value: updatedAt},
+	}
+}
+
+func TestMigrateVocabularyKey(t *testing.T) {
+	legacy := vocabularyItem("2025-01-02", "U1", `[{"word":"apple","timestamp":"2025-01-02T01:00:00Z"}]`, "2025-01-02T01:00:00Z")
+
+	change, err := migrateVocabularyKey(legacy)
+	if err != nil || change == nil || len(change.Puts) != 1 || len(change.Deletes) != 1 {
+		t.Fatalf("Expected one put and one delete, got %+v (err %v)", change, err)
+	}
+	put := change.Puts[0]
+	if stringAttr(put, "pk") != "U1#vocabulary" || stringAttr(put, "sk") != "2025-01-02" || stringAttr(change.Deletes[0], "pk") != "2025-01-02" {
+		t.Fatalf("Unexpected keys: put %s, delete %s", keyOf(put), keyOf(change.Deletes[0]))
+	}
+	if again, _ := migrateVocabularyKey(put); again != nil {
+		t.Errorf("Expected migrated items to be left alone, got %+v", again)
+	}
+
+	// migration 期間以新格式寫入的同一天紀錄要保留，重跑時不重複加入單字
+	existing := vocabularyItem("U1#vocabulary", "2025-01-02", `[{"word":"banana","timestamp":"2025-01-02T03:00:00Z"}]`, "2025-01-02T03:00:00Z")
+	merged, err := change.Merge(existing, put)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	merged, _ = change.Merge(merged, put)
+	words, _ := parseWords(merged)
+	if len(words) != 2 || words[0].Word != "apple" || words[1].Word != "banana" || stringAttr(merged, "updatedAt") != "2025-01-02T03:00:00Z" {
+		t.Errorf("Expected both words in time order, got %+v (updatedAt %s)", words, stringAttr(merged, "updatedAt"))
+	}
+
+	if counts := countVocabularyItems(legacy); counts["legacyItems"] != 1 || counts["legacyWords"] != 1 {
+		t.Errorf("Unexpected legacy counts %v", counts)
+	}
+	if counts := countVocabularyItems(merged); counts["vocabularyItems"] != 1 || counts["vocabularyWords"] != 2 {
+		t.Errorf("Unexpected migrated counts %v", counts)
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+// WeeklyActivity marks a user as active during a week (UTC, weeks start on Monday)
+type WeeklyActivity struct {
+	Week       string `json:"week" dynamodbav:"week"` // 該週星期一 YYYY-MM-DD
+	UserID     string `json:"userId" dynamodbav:"userId"`
+	CohortWeek string `json:"cohortWeek" dynamodbav:"cohortWeek"` // 用戶第一次活躍（加入）的那一週
+}
+
+// CohortRetention is the weekly retention of users who joined in the same week
+type CohortRetention struct {
+	Cohort     string    `json:"cohort" dynamodbav:"cohort"`     // 該週星期一 YYYY-MM-DD
+	Size       int       `json:"size" dynamodbav:"size"`         // cohort 人數
+	Retained   []int     `json:"retained" dynamodbav:"retained"` // index n 為第 X+n 週仍活躍的人數
+	Rates      []float64 `json:"rates" dynamodbav:"rates"`
+	ComputedAt string    `json:"computedAt" dynamodbav:"computedAt"`
+}
@@ -10,6 +10,7 @@ import (
 type BloomFilter struct {
 	UserID    string `json:"userId"`
 	BitArray  []byte `json:"bitArray"`  // Serialized bit array
+	Counters  []byte `json:"counters,omitempty"` // 4-bit counters of a counting filter (see NewCountingBloomFilter)
 	Size      int    `json:"size"`      // Size of the bit array in bits
 	HashCount int    `json:"hashCount"` // Number of hash functions
 	UpdatedAt string `json:"updatedAt"` // ISO timestamp
@@ -32,6 +33,10 @@ func NewBloomFilter(userID string, expectedElements int) *BloomFilter {
 
 // Add adds a word to the Bloom Filter
 func (bf *BloomFilter) Add(word string) {
+	if bf.IsCounting() {
+		bf.addCounting(word)
+		return
+	}
 	hashes := bf.getHashes(word)
 	for i, hash := range hashes {
 		index := hash % uint64(bf.Size)
@@ -53,6 +58,9 @@ func (bf *BloomFilter) Add(word string) {
 
 // Contains checks if a word might be in the Bloom Filter
 func (bf *BloomFilter) Contains(word string) bool {
+	if bf.IsCounting() {
+		return bf.containsCounting(word)
+	}
 	hashes := bf.getHashes(word)
 	for _, hash := range hashes {
 		index := hash % uint64(bf.Size)
@@ -0,0 +1,24 @@
+package models
+
+// Postback actions carried in LINE postback data（格式為 URL query string）
+const (
+	PostbackWordAck          = "word_ack"           // 每日單字下方的「記住了」按鈕
+	PostbackReviewAdd        = "review_add"         // 每日單字卡片上的「加入複習」按鈕
+	PostbackResetWordHistory = "reset_word_history" // 清除單字推播紀錄的確認按鈕
+)
+
+// CardFormatMetrics counts daily pushes and engagements for one card format on one day
+type CardFormatMetrics struct {
+	Date        string `json:"date" dynamodbav:"date"` // YYYY-MM-DD (UTC)
+	Format      string `json:"format" dynamodbav:"format"`
+	Pushes      int    `json:"pushes" dynamodbav:"pushes"`
+	Engagements int    `json:"engagements" dynamodbav:"engagements"`
+}
+
+// EngagementRate returns the fraction of pushes the user tapped on
+func (m CardFormatMetrics) EngagementRate() float64 {
+	if m.Pushes == 0 {
+		return 0
+	}
+	return float64(m.Engagements) / float64(m.Pushes)
+}
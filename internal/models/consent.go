@@ -0,0 +1,31 @@
+package models
+
+// ConsentType 用戶可個別同意或撤回的資料使用項目，onboarding 時詢問，之後透過 /隱私設定 修改
+type ConsentType string
+
+const (
+	ConsentAnalytics ConsentType = "analytics" // 互動與每週活躍紀錄用於統計分析（含資料倉儲串流）
+	ConsentMarketing ConsentType = "marketing" // 接收管理後台發送的公告
+	ConsentAIData    ConsentType = "ai_data"   // AI 回覆無法解析時保存去識別化的輸出，用來改善 prompt
+)
+
+// ConsentTypes 列出所有同意項目，新增項目時一併在 UserConfig 加上欄位
+var ConsentTypes = []ConsentType{ConsentAnalytics, ConsentMarketing, ConsentAIData}
+
+// ParseConsentType 驗證同意項目
+func ParseConsentType(value string) (ConsentType, error) {
+	return parseEnum("consent type", value, ConsentTypes)
+}
+
+// HasConsent 回傳用戶是否同意該項目；尚未選擇過隱私設定的用戶視為不同意
+func (c UserConfig) HasConsent(consent ConsentType) bool {
+	switch consent {
+	case ConsentAnalytics:
+		return c.ConsentAnalytics
+	case ConsentMarketing:
+		return c.ConsentMarketing
+	case ConsentAIData:
+		return c.ConsentAIData
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package models
+
+// PostbackContentReport AI 產生的字卡下方「回報內容問題」按鈕的 postback action，
+// kind=ContentKind*，word/pos/meaning/example 為字卡內容；選擇原因後再加上 reason=ContentReportReason
+const PostbackContentReport = "content_report"
+
+// Content kinds：被回報的是哪一種 AI 產生的內容
+const (
+	ContentKindTranslation = "translation" // 翻譯結果
+	ContentKindDailyWord   = "daily_word"  // 每日單字字卡
+)
+
+// ContentReportReason 用戶回報內容問題的原因
+type ContentReportReason string
+
+const (
+	ContentReportIncorrect     ContentReportReason = "incorrect"     // 意思、詞性或例句錯誤
+	ContentReportInappropriate ContentReportReason = "inappropriate" // 內容不恰當或令人不舒服
+	ContentReportOther         ContentReportReason = "other"
+)
+
+// ContentReportReasons lists every report reason, in the order the buttons are shown
+var ContentReportReasons = []ContentReportReason{ContentReportIncorrect, ContentReportInappropriate, ContentReportOther}
+
+// ParseContentReportReason 驗證回報原因
+func ParseContentReportReason(value string) (ContentReportReason, error) {
+	return parseEnum("content report reason", value, ContentReportReasons)
+}
+
+// ContentReport is a user's report about AI-generated content, queued for moderation until an admin resolves it
+type ContentReport struct {
+	ReportID   string              `json:"reportId" dynamodbav:"reportId"`
+	UserID     string              `json:"userId" dynamodbav:"userId"`
+	Kind       string              `json:"kind" dynamodbav:"kind"` // ContentKind*
+	Word       string              `json:"word" dynamodbav:"word"`
+	CacheKey   string              `json:"cacheKey,omitempty" dynamodbav:"cacheKey,omitempty"` // 翻譯回報：用戶查詢原文的翻譯快取 key
+	Content    string              `json:"content" dynamodbav:"content"`                       // 回報當下字卡上的詞性、意思與例句
+	Reason     ContentReportReason `json:"reason" dynamodbav:"reason"`
+	Course     string              `json:"course,omitempty" dynamodbav:"course,omitempty"` // 以下為回報當下的用戶設定，供審核時重現
+	Level      int                 `json:"level,omitempty" dynamodbav:"level,omitempty"`
+	Model      string              `json:"model,omitempty" dynamodbav:"model,omitempty"`
+	Suppressed bool                `json:"suppressed,omitempty" dynamodbav:"suppressed,omitempty"` // 審核前暫停在每日單字中再次使用這個單字
+	ReportedAt string              `json:"reportedAt" dynamodbav:"reportedAt"`                     // ISO timestamp
+}
@@ -0,0 +1,90 @@
+package models
+
+import "math/bits"
+
+// counterBits 每個計數器佔用的 bit 數，兩個計數器共用一個 byte
+const counterBits = 4
+
+// maxCounter 計數器的上限，到達上限後不再增減，避免溢位與誤刪造成 false negative
+const maxCounter = 1<<counterBits - 1
+
+// NewCountingBloomFilter creates a counting Bloom Filter that supports Remove.
+// Each position keeps a 4-bit counter instead of a single bit, so the filter is four times larger than NewBloomFilter.
+func NewCountingBloomFilter(userID string) *BloomFilter {
+	filter := NewBloomFilter(userID, 0)
+	filter.BitArray = nil
+	filter.Counters = make([]byte, (filter.Size*counterBits+7)/8)
+	return filter
+}
+
+// IsCounting reports whether the filter keeps counters and therefore supports Remove
+func (bf *BloomFilter) IsCounting() bool {
+	return bf.Counters != nil
+}
+
+// Remove removes a word from a counting Bloom Filter. It returns false when the filter
+// does not support removal or the word is not in the filter.
+func (bf *BloomFilter) Remove(word string) bool {
+	if !bf.IsCounting() || !bf.Contains(word) {
+		return false
+	}
+	for _, index := range bf.indexes(word) {
+		if count := bf.counter(index); count < maxCounter {
+			bf.setCounter(index, count-1)
+		}
+	}
+	return true
+}
+
+func (bf *BloomFilter) addCounting(word string) {
+	for _, index := range bf.indexes(word) {
+		if count := bf.counter(index); count < maxCounter {
+			bf.setCounter(index, count+1)
+		}
+	}
+}
+
+func (bf *BloomFilter) containsCounting(word string) bool {
+	for _, index := range bf.indexes(word) {
+		if bf.counter(index) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (bf *BloomFilter) indexes(word string) []uint64 {
+	hashes := bf.getHashes(word)
+	for i, hash := range hashes {
+		hashes[i] = hash % uint64(bf.Size)
+	}
+	return hashes
+}
+
+// counter 讀取第 index 個計數器，偶數 index 使用低 4 bit、奇數使用高 4 bit
+func (bf *BloomFilter) counter(index uint64) byte {
+	shift := (index % 2) * counterBits
+	return (bf.Counters[index/2] >> shift) & maxCounter
+}
+
+func (bf *BloomFilter) setCounter(index uint64, count byte) {
+	shift := (index % 2) * counterBits
+	bf.Counters[index/2] = bf.Counters[index/2]&^(maxCounter<<shift) | count<<shift
+}
+
+// SetPositions 回傳已被設定的位置數（一般 filter 為 1 的 bit，counting filter 為非零的計數器），用來估算填充率
+func (bf *BloomFilter) SetPositions() int {
+	set := 0
+	if !bf.IsCounting() {
+		for _, b := range bf.BitArray {
+			set += bits.OnesCount8(b)
+		}
+		return set
+	}
+	for index := 0; index < bf.Size; index++ {
+		if bf.counter(uint64(index)) > 0 {
+			set++
+		}
+	}
+	return set
+}
@@ -0,0 +1,48 @@
+package models
+
+import "testing"
+
+func TestCountingBloomFilterRemove(t *testing.T) {
+	filter := NewCountingBloomFilter("U1")
+	filter.Add("resilient")
+	filter.Add("meticulous")
+
+	if !filter.Contains("resilient") || !filter.Contains("meticulous") {
+		t.Fatal("expected added words to be contained")
+	}
+	if !filter.Remove("resilient") {
+		t.Fatal("expected Remove to succeed for an added word")
+	}
+	if filter.Contains("resilient") {
+		t.Error("expected removed word to no longer be contained")
+	}
+	if !filter.Contains("meticulous") {
+		t.Error("expected other words to survive Remove")
+	}
+	if filter.Remove("resilient") {
+		t.Error("expected second Remove to report false")
+	}
+}
+
+func TestCountingBloomFilterSaturatedCounter(t *testing.T) {
+	filter := NewCountingBloomFilter("U1")
+	for i := 0; i < maxCounter+5; i++ {
+		filter.Add("resilient")
+	}
+	// 計數器飽和後不再遞減，移除一次仍然保留，避免其他單字被誤刪
+	filter.Remove("resilient")
+	if !filter.Contains("resilient") {
+		t.Error("expected saturated counters to stay set")
+	}
+}
+
+func TestBloomFilterRemoveUnsupported(t *testing.T) {
+	filter := NewBloomFilter("U1", 0)
+	filter.Add("meticulous")
+	if filter.Remove("meticulous") {
+		t.Error("expected plain bloom filter to refuse Remove")
+	}
+	if !filter.Contains("meticulous") || filter.SetPositions() == 0 {
+		t.Error("expected plain bloom filter to keep the word")
+	}
+}
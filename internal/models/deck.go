@@ -0,0 +1,53 @@
+package models
+
+import "language-assistant/internal/messages"
+
+// PostbackDeckAdd 翻譯結果下方「加入清單」按鈕的 postback action
+const PostbackDeckAdd = "deck_add"
+
+// DeckWord is a translated word saved into a deck
+type DeckWord struct {
+	Word         string `json:"word" dynamodbav:"word"`
+	PartOfSpeech string `json:"partOfSpeech" dynamodbav:"partOfSpeech"`
+	Translation  string `json:"translation" dynamodbav:"translation"`
+	Sentence     string `json:"sentence" dynamodbav:"sentence"`
+	AddedAt      string `json:"addedAt" dynamodbav:"addedAt"` // ISO timestamp
+}
+
+// Deck is a user-named word list (e.g. 旅遊英文) that can be reviewed and quizzed on its own
+type Deck struct {
+	UserID    string     `json:"userId" dynamodbav:"userId"`
+	Name      string     `json:"name" dynamodbav:"deckName"`
+	Words     []DeckWord `json:"words" dynamodbav:"words"`
+	CreatedAt string     `json:"createdAt" dynamodbav:"createdAt"` // ISO timestamp
+	UpdatedAt string     `json:"updatedAt" dynamodbav:"updatedAt"` // ISO timestamp
+}
+
+// WordRecords 將清單中的單字轉成單字庫的格式，供回顧與測驗共用
+func (d Deck) WordRecords() []WordRecord {
+	records := make([]WordRecord, 0, len(d.Words))
+	for _, w := range d.Words {
+		records = append(records, WordRecord{
+			Word:         w.Word,
+			PartOfSpeech: w.PartOfSpeech,
+			Translation:  w.Translation,
+			Sentence:     w.Sentence,
+			Source:       WordSourceTranslation,
+			Timestamp:    w.AddedAt,
+		})
+	}
+	return records
+}
+
+// FormatDeck 格式化清單中的所有單字，以清單名稱作為標題；內容超過 LINE 單則上限時在單字之間分成多則訊息
+func FormatDeck(deck Deck) []string {
+	cards := make([]string, 0, len(deck.Words))
+	for _, w := range deck.WordRecords() {
+		cards = append(cards, messages.Render(messages.ReviewWord, w))
+	}
+	if len(cards) == 0 {
+		return []string{messages.Render(messages.DeckEmpty, messages.Data{"Name": deck.Name})}
+	}
+	cards[0] = messages.Render(messages.DeckHeader, messages.Data{"Name": deck.Name, "Count": len(cards)}) + cards[0]
+	return messages.Split(cards, messages.Text(messages.ReviewSeparator))
+}
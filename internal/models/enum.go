@@ -0,0 +1,14 @@
+package models
+
+import "fmt"
+
+// parseEnum 驗證來自外部（postback、管理 API、CLI 參數）的字串是否為 values 之一，
+// 避免未知的值一路流到 switch 的 else 分支被默默當成某個預設值處理
+func parseEnum[T ~string](kind, value string, values []T) (T, error) {
+	for _, v := range values {
+		if string(v) == value {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid %s %q", kind, value)
+}
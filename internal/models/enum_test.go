@@ -0,0 +1,168 @@
+package models
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEnums(t *testing.T) {
+	for _, format := range CardFormats {
+		if parsed, err := ParseCardFormat(string(format)); err != nil || parsed != format {
+			t.Errorf("ParseCardFormat(%q) = %q, %v", format, parsed, err)
+		}
+	}
+	for _, status := range BetaStatuses {
+		if parsed, err := ParseBetaStatus(string(status)); err != nil || parsed != status {
+			t.Errorf("ParseBetaStatus(%q) = %q, %v", status, parsed, err)
+		}
+	}
+	for _, value := range []string{"", "Image", "video"} {
+		if _, err := ParseCardFormat(value); err == nil {
+			t.Errorf("Expected card format %q to be rejected", value)
+		}
+	}
+	if _, err := ParseBetaStatus("APPROVED"); err == nil {
+		t.Errorf("Expected beta status to be case sensitive")
+	}
+}
+
+// enumTypes 以 switch 比對時必須列出所有值（或寫 default）的型別
+var enumTypes = map[string][]string{
+	"CardFormat": enumNames(CardFormats),
+	"BetaStatus": enumNames(BetaStatuses),
+	"QuizStatus": enumNames(QuizStatuses),
+	"PushStatus": enumNames(PushStatuses),
+}
+
+func enumNames[T ~string](values []T) []string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, string(v))
+	}
+	return names
+}
+
+// enumConstants 從 models 的原始碼找出各 enum 型別的常數名稱，並確認與 CardFormats 等清單一致，
+// 避免新增常數卻忘了加進清單
+func enumConstants(t *testing.T) map[string]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info fs.FileInfo) bool { return !strings.HasSuffix(info.Name(), "_test.go") }, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse models: %v", err)
+	}
+
+	owner := map[string]string{} // 常數名稱 -> 型別
+	values := map[string][]string{}
+	for _, file := range pkgs["models"].Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.ValueSpec)
+				typ, ok := spec.Type.(*ast.Ident)
+				if !ok || enumTypes[typ.Name] == nil {
+					continue
+				}
+				for i, name := range spec.Names {
+					owner[name.Name] = typ.Name
+					if lit, ok := spec.Values[i].(*ast.BasicLit); ok {
+						values[typ.Name] = append(values[typ.Name], strings.Trim(lit.Value, `"`))
+					}
+				}
+			}
+		}
+	}
+
+	for typ, listed := range enumTypes {
+		if strings.Join(values[typ], ",") != strings.Join(listed, ",") {
+			t.Errorf("%s constants %v do not match the exported list %v", typ, values[typ], listed)
+		}
+	}
+	return owner
+}
+
+// TestEnumSwitchesAreExhaustive 檢查整個 repo 中以 enum 常數為 case 的 switch：沒有 default 時必須列出所有值，
+// 避免新增一個值後落入其他分支被當成既有的值處理
+func TestEnumSwitchesAreExhaustive(t *testing.T) {
+	owner := enumConstants(t)
+	root := filepath.Join("..", "..")
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		inModels := file.Name.Name == "models"
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			stmt, ok := n.(*ast.SwitchStmt)
+			if !ok {
+				return true
+			}
+			covered := map[string]map[string]bool{}
+			hasDefault := false
+			for _, clause := range stmt.Body.List {
+				clause := clause.(*ast.CaseClause)
+				if clause.List == nil {
+					hasDefault = true
+				}
+				for _, expr := range clause.List {
+					name := ""
+					switch e := expr.(type) {
+					case *ast.Ident:
+						if inModels {
+							name = e.Name
+						}
+					case *ast.SelectorExpr:
+						if pkg, ok := e.X.(*ast.Ident); ok && pkg.Name == "models" {
+							name = e.Sel.Name
+						}
+					}
+					if typ, ok := owner[name]; ok {
+						if covered[typ] == nil {
+							covered[typ] = map[string]bool{}
+						}
+						covered[typ][name] = true
+					}
+				}
+			}
+			if hasDefault {
+				return true
+			}
+			for typ, names := range covered {
+				for constant, constType := range owner {
+					if constType == typ && !names[constant] {
+						t.Errorf("%s: switch on %s is missing %s and has no default", fset.Position(stmt.Pos()), typ, constant)
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk repository: %v", err)
+	}
+}
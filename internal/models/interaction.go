@@ -0,0 +1,33 @@
+package models
+
+// Interaction features（用來分組功能使用量與漏斗分析）
+const (
+	FeatureOnboarding   = "onboarding"
+	FeaturePushSettings = "push_settings"
+	FeatureDailyPush    = "daily_push"
+	FeatureQuiz         = "quiz"
+	FeatureReview       = "review"
+)
+
+// Interaction actions
+const (
+	InteractionFollow             = "follow"
+	InteractionCourseSelected     = "course_selected"
+	InteractionScoreSet           = "score_set"
+	InteractionDailyWordsSelected = "daily_words_selected"
+	InteractionPushConfigured     = "push_configured"
+	InteractionWordAck            = "word_ack"
+	InteractionQuizAnswer         = "quiz_answer"
+	InteractionReviewResponse     = "review_response"
+	InteractionTranslationOnly    = "translation_only"
+)
+
+// Interaction records a single postback or quick reply tap
+type Interaction struct {
+	UserID     string `json:"userId" dynamodbav:"userId"`
+	Feature    string `json:"feature" dynamodbav:"feature"`
+	Action     string `json:"action" dynamodbav:"action"`
+	Variant    string `json:"variant,omitempty" dynamodbav:"variant,omitempty"`     // 例如字卡格式、課程、預設或自訂設定
+	LatencyMs  int64  `json:"latencyMs,omitempty" dynamodbav:"latencyMs,omitempty"` // 從訊息送出到用戶點擊的時間，無法得知時為 0
+	OccurredAt string `json:"occurredAt" dynamodbav:"occurredAt"`                   // ISO timestamp
+}
@@ -0,0 +1,26 @@
+package models
+
+// KillSwitch 可以由維運人員即時關閉的高成本子系統
+type KillSwitch string
+
+const (
+	KillSwitchTTS       KillSwitch = "tts"        // 單字發音（OpenAI TTS）
+	KillSwitchDailyPush KillSwitch = "daily-push" // 每日單字推播（OpenAI 產生單字）
+)
+
+// KillSwitches 所有可關閉的子系統，新增時一併在 messages 設定維護中顯示的名稱
+var KillSwitches = []KillSwitch{KillSwitchTTS, KillSwitchDailyPush}
+
+// ParseKillSwitch 驗證子系統名稱
+func ParseKillSwitch(value string) (KillSwitch, error) {
+	return parseEnum("kill switch", value, KillSwitches)
+}
+
+// KillSwitchState is the operator-set state of one kill switch; a switch without a stored state is enabled
+type KillSwitchState struct {
+	Switch    KillSwitch `json:"switch" dynamodbav:"switch"`
+	Disabled  bool       `json:"disabled" dynamodbav:"disabled"`
+	Reason    string     `json:"reason,omitempty" dynamodbav:"reason,omitempty"` // 關閉的原因，例如事故或費用異常
+	UpdatedBy string     `json:"updatedBy" dynamodbav:"updatedBy"`
+	UpdatedAt string     `json:"updatedAt" dynamodbav:"updatedAt"` // ISO timestamp
+}
@@ -0,0 +1,43 @@
+package models
+
+// Badge is an achievement shown on a user's public profile
+type Badge struct {
+	ID    string `json:"id"`
+	Emoji string `json:"emoji"`
+	Name  string `json:"name"`
+}
+
+// LearningStats summarizes a user's lookup history without exposing word content
+type LearningStats struct {
+	TotalWords    int            `json:"totalWords"`
+	WordsBySource map[string]int `json:"wordsBySource"` // 依單字來源（WordSource*）分類的單字數
+	ActiveDays    int            `json:"activeDays"`
+	CurrentStreak int            `json:"currentStreak"` // 連續學習天數（今天或昨天仍有查詢才算延續）
+	LongestStreak int            `json:"longestStreak"`
+	Badges        []Badge        `json:"badges"`
+}
+
+// VocabularyStats is the /統計 summary of a user's saved words and quiz results
+type VocabularyStats struct {
+	TotalWords       int
+	WeekWords        int     // 本週（週一起算，UTC）存入的單字數
+	MonthWords       int     // 本月存入的單字數
+	AveragePerDay    float64 // 從第一次存字到今天的每日平均
+	TopPartsOfSpeech []PartOfSpeechCount
+	QuizAnswered     int
+	QuizCorrect      int
+}
+
+// PartOfSpeechCount is the number of saved words with one part of speech
+type PartOfSpeechCount struct {
+	PartOfSpeech string
+	Count        int
+}
+
+// QuizAccuracy 回傳測驗正確率（百分比，四捨五入），尚未作答時為 0
+func (s VocabularyStats) QuizAccuracy() int {
+	if s.QuizAnswered == 0 {
+		return 0
+	}
+	return (s.QuizCorrect*100 + s.QuizAnswered/2) / s.QuizAnswered
+}
@@ -0,0 +1,22 @@
+package models
+
+import "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+// Migration checkpoint statuses
+const (
+	MigrationStatusRunning   = "running"
+	MigrationStatusCompleted = "completed"
+)
+
+// MigrationCheckpoint records how far a migration has scanned so an interrupted run can resume
+type MigrationCheckpoint struct {
+	MigrationID string `json:"migrationId" dynamodbav:"migrationId"`
+	Status      string `json:"status" dynamodbav:"status"`
+	Scanned     int    `json:"scanned" dynamodbav:"scanned"` // 已掃描的 item 數
+	Changed     int    `json:"changed" dynamodbav:"changed"` // 有寫回或刪除的 item 數
+	StartedAt   string `json:"startedAt" dynamodbav:"startedAt"`
+	UpdatedAt   string `json:"updatedAt" dynamodbav:"updatedAt"`
+	CompletedAt string `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"`
+	// LastEvaluatedKey 下一頁 Scan 的起點；attributevalue 無法直接 marshal AttributeValue，由 repository 自行讀寫
+	LastEvaluatedKey map[string]types.AttributeValue `json:"-" dynamodbav:"-"`
+}
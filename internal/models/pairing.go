@@ -0,0 +1,18 @@
+package models
+
+// PairingCode is a short-lived, single-use code that links a web dashboard login to a LINE user
+type PairingCode struct {
+	Code      string `json:"code" dynamodbav:"code"`
+	UserID    string `json:"userId" dynamodbav:"userId"`
+	CreatedAt string `json:"createdAt" dynamodbav:"createdAt"` // ISO timestamp
+	ExpiresAt int64  `json:"expiresAt" dynamodbav:"expiresAt"` // Unix 秒，同時作為 DynamoDB TTL
+}
+
+// WebSession is a dashboard session bound to a LINE user.
+// 只儲存 token 的雜湊值，原始 token 僅在交換當下回傳給前端
+type WebSession struct {
+	TokenHash string `json:"-" dynamodbav:"tokenHash"`
+	UserID    string `json:"userId" dynamodbav:"userId"`
+	CreatedAt string `json:"createdAt" dynamodbav:"createdAt"` // ISO timestamp
+	ExpiresAt int64  `json:"expiresAt" dynamodbav:"expiresAt"` // Unix 秒，同時作為 DynamoDB TTL
+}
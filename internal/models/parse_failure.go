@@ -0,0 +1,14 @@
+package models
+
+// ParseFailure is a sampled OpenAI response that could not be parsed into the expected JSON, kept (sanitized) as a
+// real example to drive prompt fixes
+type ParseFailure struct {
+	Feature       string `json:"feature" dynamodbav:"feature"`                                 // utils.OpenAIFeature，例如 TRANSLATION
+	PromptVersion string `json:"promptVersion,omitempty" dynamodbav:"promptVersion,omitempty"` // 翻譯 prompt rollout 的版本，其他功能為空字串
+	Model         string `json:"model" dynamodbav:"model"`
+	Output        string `json:"output" dynamodbav:"output"`       // 去識別化後的模型原始輸出
+	Truncated     bool   `json:"truncated" dynamodbav:"truncated"` // 輸出過長而被截斷
+	Error         string `json:"error" dynamodbav:"error"`
+	OccurredAt    string `json:"occurredAt" dynamodbav:"occurredAt"` // ISO timestamp
+	ExpiresAt     int64  `json:"expiresAt,omitempty" dynamodbav:"expiresAt,omitempty"`
+}
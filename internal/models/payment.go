@@ -0,0 +1,45 @@
+package models
+
+// PaymentStatus 付款的狀態，只會從 pending 轉成其他狀態
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentConfirmed PaymentStatus = "confirmed"
+	PaymentFailed    PaymentStatus = "failed"
+	PaymentCancelled PaymentStatus = "cancelled"
+)
+
+// PaymentStatuses 所有有效的付款狀態
+var PaymentStatuses = []PaymentStatus{PaymentPending, PaymentConfirmed, PaymentFailed, PaymentCancelled}
+
+// ParsePaymentStatus 驗證付款狀態
+func ParsePaymentStatus(value string) (PaymentStatus, error) {
+	return parseEnum("payment status", value, PaymentStatuses)
+}
+
+// Payment is one payment request sent to the payment provider to upgrade a user's plan
+type Payment struct {
+	OrderID       string        `json:"orderId" dynamodbav:"orderId"`
+	UserID        string        `json:"userId" dynamodbav:"userId"`
+	Plan          Plan          `json:"plan" dynamodbav:"plan"` // 付款成功後切換的方案
+	Amount        int           `json:"amount" dynamodbav:"amount"`
+	Currency      string        `json:"currency" dynamodbav:"currency"`
+	Provider      string        `json:"provider" dynamodbav:"provider"`                               // 例如 linepay
+	TransactionID string        `json:"transactionId,omitempty" dynamodbav:"transactionId,omitempty"` // 金流商的交易編號
+	Status        PaymentStatus `json:"status" dynamodbav:"status"`
+	CreatedAt     string        `json:"createdAt" dynamodbav:"createdAt"`                     // ISO timestamp
+	UpdatedAt     string        `json:"updatedAt,omitempty" dynamodbav:"updatedAt,omitempty"` // 狀態最後一次變更的時間
+}
+
+// PaymentReceipt is the record kept for every confirmed payment, listed per user for support and bookkeeping
+type PaymentReceipt struct {
+	UserID        string `json:"userId" dynamodbav:"userId"`
+	OrderID       string `json:"orderId" dynamodbav:"orderId"`
+	TransactionID string `json:"transactionId" dynamodbav:"transactionId"`
+	Provider      string `json:"provider" dynamodbav:"provider"`
+	Plan          Plan   `json:"plan" dynamodbav:"plan"`
+	Amount        int    `json:"amount" dynamodbav:"amount"`
+	Currency      string `json:"currency" dynamodbav:"currency"`
+	ConfirmedAt   string `json:"confirmedAt" dynamodbav:"confirmedAt"` // ISO timestamp
+}
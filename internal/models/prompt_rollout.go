@@ -0,0 +1,41 @@
+package models
+
+// Prompt names that support staged rollout
+const (
+	PromptTranslation = "translation"
+)
+
+// Prompt rollout statuses
+const (
+	PromptRolloutActive     = "active"
+	PromptRolloutRolledBack = "rolled_back"
+	PromptRolloutStopped    = "stopped"
+)
+
+// PromptRollout routes a percentage of traffic to a candidate prompt version;
+// the rest keeps using the embedded baseline prompt
+type PromptRollout struct {
+	Prompt           string `json:"prompt" dynamodbav:"prompt"`
+	RolloutID        string `json:"rolloutId" dynamodbav:"rolloutId"` // 開始時間，用來區分每次 rollout 的指標
+	BaselineVersion  string `json:"baselineVersion" dynamodbav:"baselineVersion"`
+	CandidateVersion string `json:"candidateVersion" dynamodbav:"candidateVersion"`
+	Percent          int    `json:"percent" dynamodbav:"percent"` // 0-100
+	Status           string `json:"status" dynamodbav:"status"`
+	Reason           string `json:"reason,omitempty" dynamodbav:"reason,omitempty"`
+	UpdatedAt        string `json:"updatedAt" dynamodbav:"updatedAt"`
+}
+
+// PromptMetrics counts outcomes for one prompt version during a rollout
+type PromptMetrics struct {
+	Version       string `json:"version" dynamodbav:"version"`
+	Requests      int    `json:"requests" dynamodbav:"requests"`
+	ParseFailures int    `json:"parseFailures" dynamodbav:"parseFailures"`
+}
+
+// ParseFailureRate returns the fraction of requests whose response could not be parsed
+func (m PromptMetrics) ParseFailureRate() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return float64(m.ParseFailures) / float64(m.Requests)
+}
@@ -0,0 +1,27 @@
+package models
+
+// PushStatus is the outcome of a daily word push
+type PushStatus string
+
+const (
+	PushStatusDelivered PushStatus = "delivered"
+	PushStatusFailed    PushStatus = "failed"
+)
+
+// PushStatuses lists every push status
+var PushStatuses = []PushStatus{PushStatusDelivered, PushStatusFailed}
+
+// PushLog records a single daily word push attempt for a user
+type PushLog struct {
+	UserID     string     `json:"userId" dynamodbav:"userId"`
+	Date       string     `json:"date" dynamodbav:"date"`         // YYYY-MM-DD（用戶時區）
+	PushedAt   string     `json:"pushedAt" dynamodbav:"pushedAt"` // ISO timestamp
+	Course     string     `json:"course" dynamodbav:"course"`
+	WordCount  int        `json:"wordCount" dynamodbav:"wordCount"`
+	Status     PushStatus `json:"status" dynamodbav:"status"` // delivered or failed
+	Error      string     `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	Words      []string   `json:"words" dynamodbav:"words"`                               // 推播的單字
+	Message    string     `json:"message,omitempty" dynamodbav:"message,omitempty"`       // 實際推播的訊息內容
+	CardFormat CardFormat `json:"cardFormat,omitempty" dynamodbav:"cardFormat,omitempty"` // text 或 image
+	Experiment bool       `json:"experiment,omitempty" dynamodbav:"experiment,omitempty"` // 是否為 A/B 測試隨機分派
+}
@@ -0,0 +1,49 @@
+package models
+
+// Quiz question directions
+const (
+	QuizWordToMeaning = "word_to_meaning" // 看英文選中文意思
+	QuizMeaningToWord = "meaning_to_word" // 看中文選英文單字
+)
+
+// QuizStatus is the state of a quiz session
+type QuizStatus string
+
+const (
+	QuizStatusActive    QuizStatus = "active"
+	QuizStatusCompleted QuizStatus = "completed"
+)
+
+// QuizStatuses lists every quiz session status
+var QuizStatuses = []QuizStatus{QuizStatusActive, QuizStatusCompleted}
+
+// PostbackQuizAnswer 測驗選項按鈕的 postback action
+const PostbackQuizAnswer = "quiz_answer"
+
+// QuizQuestion is a single multiple-choice question generated from the user's saved vocabulary
+type QuizQuestion struct {
+	Direction    string   `json:"direction" dynamodbav:"direction"`
+	Word         string   `json:"word" dynamodbav:"word"`
+	PartOfSpeech string   `json:"partOfSpeech" dynamodbav:"partOfSpeech"`
+	Translation  string   `json:"translation" dynamodbav:"translation"`
+	Options      []string `json:"options" dynamodbav:"options"`
+	Answer       int      `json:"answer" dynamodbav:"answer"` // 正確選項的 index
+	Choice       int      `json:"choice" dynamodbav:"choice"` // 用戶選擇的 index，尚未作答時為 -1
+}
+
+// Answered reports whether the user has chosen an option
+func (q QuizQuestion) Answered() bool {
+	return q.Choice >= 0
+}
+
+// QuizSession tracks a user's progress through one quiz
+type QuizSession struct {
+	UserID      string         `json:"userId" dynamodbav:"userId"`
+	SessionID   string         `json:"sessionId" dynamodbav:"sessionId"` // 開始時間（RFC3339Nano）
+	Status      QuizStatus     `json:"status" dynamodbav:"status"`
+	Questions   []QuizQuestion `json:"questions" dynamodbav:"questions"`
+	Current     int            `json:"current" dynamodbav:"current"` // 目前作答中的題目 index
+	Correct     int            `json:"correct" dynamodbav:"correct"`
+	CompletedAt string         `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"` // ISO timestamp
+	ExpiresAt   int64          `json:"expiresAt,omitempty" dynamodbav:"expiresAt,omitempty"`     // Unix 秒，同時作為 DynamoDB TTL，0 表示不過期
+}
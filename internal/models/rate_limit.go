@@ -0,0 +1,8 @@
+package models
+
+// RateLimitBucket is the token bucket state of one user for one rate-limited feature
+type RateLimitBucket struct {
+	Tokens    float64 `json:"tokens" dynamodbav:"tokens"`       // 目前剩餘的 token，每次請求消耗 1
+	UpdatedAt int64   `json:"updatedAt" dynamodbav:"updatedAt"` // 最後一次補充 token 的時間（Unix 毫秒）
+	Version   int64   `json:"version" dynamodbav:"version"`     // 每次寫入加一，作為條件寫入的版本
+}
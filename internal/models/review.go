@@ -0,0 +1,45 @@
+package models
+
+// PostbackReviewResponse 每晚互動回顧中「我記得 / 忘記了」按鈕的 postback action
+const PostbackReviewResponse = "review_response"
+
+// Review responses
+const (
+	ReviewRemembered = "remembered"
+	ReviewForgotten  = "forgotten"
+)
+
+// ReviewItem is one word in an interactive nightly review
+type ReviewItem struct {
+	Word         string `json:"word" dynamodbav:"word"`
+	PartOfSpeech string `json:"partOfSpeech" dynamodbav:"partOfSpeech"`
+	Translation  string `json:"translation" dynamodbav:"translation"`
+	Sentence     string `json:"sentence" dynamodbav:"sentence"`
+	Response     string `json:"response,omitempty" dynamodbav:"response,omitempty"` // ReviewRemembered 或 ReviewForgotten，尚未回答時為空字串
+}
+
+// ReviewSession tracks a user's progress through one night's interactive review; one session per user per day
+type ReviewSession struct {
+	UserID      string       `json:"userId" dynamodbav:"userId"`
+	Date        string       `json:"date" dynamodbav:"reviewDate"` // YYYY-MM-DD，同時作為 session ID；不使用 date 避免寫入 DateIndex
+	Items       []ReviewItem `json:"items" dynamodbav:"items"`
+	Current     int          `json:"current" dynamodbav:"current"` // 目前回答中的單字 index
+	Remembered  int          `json:"remembered" dynamodbav:"remembered"`
+	CompletedAt string       `json:"completedAt,omitempty" dynamodbav:"completedAt,omitempty"` // ISO timestamp
+	ExpiresAt   int64        `json:"expiresAt,omitempty" dynamodbav:"expiresAt,omitempty"`     // Unix 秒，同時作為 DynamoDB TTL，0 表示不過期
+}
+
+// Completed reports whether every word in the session has a response
+func (s ReviewSession) Completed() bool {
+	return s.Current >= len(s.Items)
+}
+
+// ReviewRecord is the spaced-repetition state of one word, updated by every review response
+type ReviewRecord struct {
+	Word           string `json:"word" dynamodbav:"word"`       // 正規化後的單字
+	Box            int    `json:"box" dynamodbav:"box"`         // Leitner box，記得時加一、忘記時歸零，決定下次複習的間隔
+	DueDate        string `json:"dueDate" dynamodbav:"dueDate"` // YYYY-MM-DD，此日期（含）之後優先複習
+	Remembered     int    `json:"remembered" dynamodbav:"remembered"`
+	Forgotten      int    `json:"forgotten" dynamodbav:"forgotten"`
+	LastReviewedAt string `json:"lastReviewedAt" dynamodbav:"lastReviewedAt"` // ISO timestamp
+}
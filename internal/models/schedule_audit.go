@@ -0,0 +1,28 @@
+package models
+
+// Schedule audit operations
+const (
+	ScheduleOperationCreate  = "create"
+	ScheduleOperationUpdate  = "update"
+	ScheduleOperationDelete  = "delete"
+	ScheduleOperationDisable = "disable"
+	ScheduleOperationEnable  = "enable"
+)
+
+// Schedule audit results
+const (
+	ScheduleResultSuccess = "success"
+	ScheduleResultFailure = "failure"
+)
+
+// ScheduleAuditEntry records a single EventBridge schedule operation for a user
+type ScheduleAuditEntry struct {
+	UserID       string `json:"userId" dynamodbav:"userId"`
+	Timestamp    string `json:"timestamp" dynamodbav:"timestamp"` // ISO timestamp
+	ScheduleName string `json:"scheduleName" dynamodbav:"scheduleName"`
+	Operation    string `json:"operation" dynamodbav:"operation"` // create, update, delete, disable, enable
+	Actor        string `json:"actor" dynamodbav:"actor"`         // 觸發者，例如 "user:<userId>" 或 "system:language-vocabulary"
+	Reason       string `json:"reason" dynamodbav:"reason"`
+	Result       string `json:"result" dynamodbav:"result"` // success or failure
+	Error        string `json:"error,omitempty" dynamodbav:"error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// SetupDraft holds the push settings a user has picked so far (course, daily words, push time) until the final
+// timezone step saves them. The steps arrive as separate webhooks that may reach different Lambda instances,
+// so the draft is stored in DynamoDB rather than in memory
+type SetupDraft struct {
+	UserID     string `json:"userId" dynamodbav:"userId"`
+	Course     string `json:"course,omitempty" dynamodbav:"course,omitempty"`         // 推播設定流程開始時用戶已有的課程
+	DailyWords int    `json:"dailyWords,omitempty" dynamodbav:"dailyWords,omitempty"` // 選擇的每日單字量
+	PushTime   string `json:"pushTime,omitempty" dynamodbav:"pushTime,omitempty"`     // 選擇的推播時間 HH:MM
+	ExpiresAt  int64  `json:"expiresAt" dynamodbav:"expiresAt"`                       // Unix 秒，同時作為 DynamoDB TTL
+}
@@ -0,0 +1,21 @@
+package models
+
+// StreakMilestones 連續學習達到這些天數時，由 reminder Lambda 推播恭喜訊息
+var StreakMilestones = []int{7, 30, 100}
+
+// Streak tracks consecutive days on which a user translated a word or finished a quiz
+type Streak struct {
+	UserID         string `json:"userId" dynamodbav:"userId"`
+	Current        int    `json:"current" dynamodbav:"current"`               // 目前連續天數（以 LastActiveDate 為止）
+	Longest        int    `json:"longest" dynamodbav:"longest"`               // 歷史最長連續天數
+	LastActiveDate string `json:"lastActiveDate" dynamodbav:"lastActiveDate"` // 最後一次學習的日期 YYYY-MM-DD（用戶時區）
+	Milestone      int    `json:"milestone" dynamodbav:"milestone"`           // 本次連續期間已達成的最高里程碑，中斷後歸零
+	UpdatedAt      string `json:"updatedAt" dynamodbav:"updatedAt"`           // ISO timestamp
+}
+
+// StreakMilestone is a reached milestone waiting for the reminder Lambda to congratulate the user
+type StreakMilestone struct {
+	UserID    string `json:"userId" dynamodbav:"userId"`
+	Days      int    `json:"days" dynamodbav:"days"`
+	ReachedAt string `json:"reachedAt" dynamodbav:"reachedAt"` // ISO timestamp
+}
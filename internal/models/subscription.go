@@ -0,0 +1,43 @@
+package models
+
+// PremiumFeature 需要支持者方案或試用中才能使用的功能
+type PremiumFeature string
+
+const (
+	PremiumFeatureAudio PremiumFeature = "audio" // 每日單字推播附上發音音檔
+)
+
+// PremiumFeatures 所有付費功能，新增功能時一併在 utils.FeatureGate 設定試用天數
+var PremiumFeatures = []PremiumFeature{PremiumFeatureAudio}
+
+// ParsePremiumFeature 驗證付費功能
+func ParsePremiumFeature(value string) (PremiumFeature, error) {
+	return parseEnum("premium feature", value, PremiumFeatures)
+}
+
+// TrialStatus 試用的狀態，只會從 active 轉成 expired
+type TrialStatus string
+
+const (
+	TrialActive  TrialStatus = "active"
+	TrialExpired TrialStatus = "expired"
+)
+
+// TrialStatuses 所有有效的試用狀態
+var TrialStatuses = []TrialStatus{TrialActive, TrialExpired}
+
+// ParseTrialStatus 驗證試用狀態
+func ParseTrialStatus(value string) (TrialStatus, error) {
+	return parseEnum("trial status", value, TrialStatuses)
+}
+
+// Trial is a time-boxed trial of one premium feature; each user can try each feature once
+type Trial struct {
+	UserID         string         `json:"userId" dynamodbav:"userId"`
+	Feature        PremiumFeature `json:"feature" dynamodbav:"feature"`
+	Status         TrialStatus    `json:"status" dynamodbav:"status"`
+	StartedAt      string         `json:"startedAt" dynamodbav:"startedAt"`                               // ISO timestamp
+	ExpiresAt      string         `json:"expiresAt" dynamodbav:"expiresAt"`                               // ISO timestamp，過了這個時間即不能再使用
+	EndingNotified bool           `json:"endingNotified,omitempty" dynamodbav:"endingNotified,omitempty"` // 是否已推播即將到期的提醒
+	ExpiredAt      string         `json:"expiredAt,omitempty" dynamodbav:"expiredAt,omitempty"`           // 實際完成到期處理（降級與通知）的時間
+}
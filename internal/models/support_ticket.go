@@ -0,0 +1,24 @@
+package models
+
+// User failure sources
+const (
+	FailureTranslation = "translation"
+	FailurePush        = "push"
+)
+
+// UserFailure records a single user-facing failure, used to track each user's error budget
+type UserFailure struct {
+	UserID        string `json:"userId" dynamodbav:"userId"`
+	Source        string `json:"source" dynamodbav:"source"`               // translation / push
+	CorrelationID string `json:"correlationId" dynamodbav:"correlationId"` // API Gateway / Lambda request ID，用來對照 log
+	Error         string `json:"error" dynamodbav:"error"`
+	OccurredAt    string `json:"occurredAt" dynamodbav:"occurredAt"` // ISO timestamp
+}
+
+// SupportTicket is opened automatically when a user exhausts their error budget
+type SupportTicket struct {
+	UserID         string        `json:"userId" dynamodbav:"userId"`
+	CorrelationIDs []string      `json:"correlationIds" dynamodbav:"correlationIds"`
+	Failures       []UserFailure `json:"failures" dynamodbav:"failures"`
+	OpenedAt       string        `json:"openedAt" dynamodbav:"openedAt"` // ISO timestamp
+}
@@ -0,0 +1,12 @@
+package models
+
+// TokenUsage is one user's daily OpenAI token usage for one feature (UTC date), aggregated over every call that day
+type TokenUsage struct {
+	Date             string  `json:"date" dynamodbav:"date"` // YYYY-MM-DD（UTC）
+	UserID           string  `json:"userId" dynamodbav:"userId"`
+	Feature          string  `json:"feature" dynamodbav:"feature"` // utils.OpenAIFeature，例如 TRANSLATION
+	Requests         int     `json:"requests" dynamodbav:"requests"`
+	PromptTokens     int     `json:"promptTokens" dynamodbav:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens" dynamodbav:"completionTokens"`
+	CostUSD          float64 `json:"costUsd" dynamodbav:"costUsd"` // 依呼叫當時的模型單價估算
+}
@@ -0,0 +1,10 @@
+package models
+
+// TranslationCacheEntry is a cached translation of a short input, shared across users until it expires
+type TranslationCacheEntry struct {
+	Text         string `json:"text" dynamodbav:"text"`                 // 正規化後的輸入文字
+	Variant      string `json:"variant" dynamodbav:"variant"`           // 語言、模型與 prompt 版本，不同組合各自快取
+	Translations string `json:"translations" dynamodbav:"translations"` // 翻譯結果（JSON）
+	CachedAt     string `json:"cachedAt" dynamodbav:"cachedAt"`         // ISO timestamp
+	ExpiresAt    int64  `json:"expiresAt" dynamodbav:"expiresAt"`       // Unix 秒，同時作為 DynamoDB TTL
+}
@@ -1,12 +1,119 @@
 package models
 
+// CardFormat 每日單字推播的呈現方式，空字串表示尚未選擇
+type CardFormat string
+
+const (
+	CardFormatText  CardFormat = "text"
+	CardFormatImage CardFormat = "image"
+)
+
+// CardFormats 列出所有字卡格式，新增格式時一併加入
+var CardFormats = []CardFormat{CardFormatText, CardFormatImage}
+
+// ParseCardFormat 驗證字卡格式
+func ParseCardFormat(value string) (CardFormat, error) {
+	return parseEnum("card format", value, CardFormats)
+}
+
+// DefaultTimezone 未選擇時區的用戶使用台灣時間
+const DefaultTimezone = "Asia/Taipei"
+
+// 程度提升到新的級距後，較低級距推播過的單字如何處理
+const (
+	LowerBandWordsSkip   = "skip"   // 不再推播（預設）
+	LowerBandWordsReview = "review" // 允許再次出現當作複習
+)
+
+// MaxDifficultyOffset 用戶透過 /難度調整 最多可以提高或降低的 CEFR 等級數
+const MaxDifficultyOffset = 2
+
+// 用戶可選的 OpenAI 模型，空字串表示使用各功能的預設模型
+const (
+	ModelGPT4oMini = "gpt-4o-mini"
+	ModelGPT4o     = "gpt-4o"
+)
+
+// BetaStatus Beta 測試申請狀態，空字串表示未申請
+type BetaStatus string
+
+const (
+	BetaStatusPending  BetaStatus = "pending"
+	BetaStatusApproved BetaStatus = "approved"
+	BetaStatusRejected BetaStatus = "rejected"
+)
+
+// BetaStatuses 列出所有申請狀態
+var BetaStatuses = []BetaStatus{BetaStatusPending, BetaStatusApproved, BetaStatusRejected}
+
+// ParseBetaStatus 驗證申請狀態
+func ParseBetaStatus(value string) (BetaStatus, error) {
+	return parseEnum("beta status", value, BetaStatuses)
+}
+
+// Plan 用戶的方案，決定每日可使用的額度（見 utils.PlanQuotas），空字串視為 PlanFree
+type Plan string
+
+const (
+	PlanFree      Plan = "free"
+	PlanSupporter Plan = "supporter"
+)
+
+// Plans 列出所有方案，新增方案時一併在 utils.PlanQuotas 設定額度
+var Plans = []Plan{PlanFree, PlanSupporter}
+
+// ParsePlan 驗證方案
+func ParsePlan(value string) (Plan, error) {
+	return parseEnum("plan", value, Plans)
+}
+
 type UserConfig struct {
-	UserID      string `json:"userId"`
-	DisplayName string `json:"displayName"` // LINE 用戶顯示名稱
-	Course      string `json:"course"`      // "toeic" or "ielts"
-	Level       int    `json:"level"`       // 分數
-	DailyWords  int    `json:"dailyWords"`  // 每天推播單字量 (預設10)
-	PushTime    string `json:"pushTime"`    // 推播時間 "HH:MM" (預設"08:00")
-	Timezone    string `json:"timezone"`    // 時區 (預設"Asia/Taipei")
-	UpdatedAt   string `json:"updatedAt"`   // ISO timestamp
+	UserID              string     `json:"userId"`
+	DisplayName         string     `json:"displayName"`         // LINE 用戶顯示名稱
+	Course              string     `json:"course"`              // internal/courses 登記的課程 ID，例如 "toeic"
+	Level               int        `json:"level"`               // 分數
+	DailyWords          int        `json:"dailyWords"`          // 每天推播單字量 (預設10)
+	PushTime            string     `json:"pushTime"`            // 推播時間 "HH:MM" (預設"08:00")
+	Timezone            string     `json:"timezone"`            // 時區 (預設"Asia/Taipei")
+	ScheduleName        string     `json:"scheduleName"`        // EventBridge 排程名稱，用於反查用戶
+	FanoutPush          bool       `json:"fanoutPush"`          // 個人排程已刪除，改由 language-dispatcher 推播
+	ProfileSlug         string     `json:"profileSlug"`         // 公開個人頁面的網址代碼，空字串表示未公開
+	CardFormat          CardFormat `json:"cardFormat"`          // 每日單字呈現方式 "text" 或 "image"，空字串表示尚未選擇（納入 A/B 測試）
+	LowerBandWords      string     `json:"lowerBandWords"`      // 較低級距的舊單字 "skip" 或 "review"，空字串視為 skip
+	ReviewSource        string     `json:"reviewSource"`        // 每晚回顧與測驗只使用此來源（WordSource*）的單字，空字串表示全部
+	PronunciationAudio  bool       `json:"pronunciationAudio"`  // 每日單字推播是否附上每個單字的發音音檔
+	GrammarMode         bool       `json:"grammarMode"`         // 輸入完整英文句子時改為文法修正，而不是逐字翻譯
+	InteractiveReview   bool       `json:"interactiveReview"`   // 每晚回顧改為逐字回答「我記得 / 忘記了」，回答結果用於間隔複習
+	TranslationOnly     bool       `json:"translationOnly"`     // 只使用翻譯功能，不推播每日單字也不提醒完成設定；選擇課程後自動清除
+	Model               string     `json:"model"`               // 翻譯與單字生成使用的 OpenAI 模型，空字串表示使用預設模型
+	DifficultyOffset    int        `json:"difficultyOffset"`    // 每日單字難度相對分數對應 CEFR 等級的調整，正數較難，範圍 ±MaxDifficultyOffset
+	BetaStatus          BetaStatus `json:"betaStatus"`          // Beta 測試申請狀態，空字串表示未申請
+	BetaRequestedAt     string     `json:"betaRequestedAt"`     // 申請加入測試的時間
+	Plan                Plan       `json:"plan"`                // 方案，空字串表示尚未設定（免費方案）
+	FirstActiveAt       string     `json:"firstActiveAt"`       // 第一次互動時間，用來決定留存分析的 cohort
+	LastActiveAt        string     `json:"lastActiveAt"`        // 最後一次互動時間（約每小時更新一次）
+	WebhookCaptureUntil string     `json:"webhookCaptureUntil"` // 除錯用：在此時間前擷取此用戶的 webhook payload，空字串表示不擷取
+	DeactivatedAt       string     `json:"deactivatedAt"`       // 取消追蹤（封鎖）的時間，空字串表示仍在使用
+	BlockedPushes       int        `json:"blockedPushes"`       // 連續因用戶封鎖而失敗的推播次數，推播成功時歸零
+	ConsentAnalytics    bool       `json:"consentAnalytics"`    // 同意使用紀錄用於統計分析（ConsentAnalytics）
+	ConsentMarketing    bool       `json:"consentMarketing"`    // 同意接收公告（ConsentMarketing）
+	ConsentAIData       bool       `json:"consentAiData"`       // 同意保存 AI 輸出用於改善品質（ConsentAIData）
+	ConsentUpdatedAt    string     `json:"consentUpdatedAt"`    // 最後一次選擇隱私設定的時間，空字串表示尚未選擇
+	TermsVersion        string     `json:"termsVersion"`        // 已同意的服務條款版本（utils.Terms），空字串表示尚未同意任何版本
+	TermsAcceptedAt     string     `json:"termsAcceptedAt"`     // 同意服務條款的時間
+	TermsPrompted       string     `json:"termsPrompted"`       // 已主動提示過的服務條款版本，每個版本只提示一次
+	LineDestination     string     `json:"lineDestination"`     // 用戶加入的官方帳號（webhook 的 destination），推播依此選擇 channel，空字串使用預設 channel
+	UpdatedAt           string     `json:"updatedAt"`           // ISO timestamp
+
+	// MissingPushSettings 表示 dailyWords 或 pushTime 沒有儲存（例如更換課程時被清除），
+	// DailyWords 與 PushTime 是讀取時補上的預設值；不寫入資料庫
+	MissingPushSettings bool `json:"-"`
+}
+
+// EffectivePlan 回傳用戶實際適用的方案，尚未設定方案的用戶為免費方案
+func (c UserConfig) EffectivePlan() Plan {
+	if c.Plan == "" {
+		return PlanFree
+	}
+	return c.Plan
 }
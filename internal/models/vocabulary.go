@@ -1,52 +1,119 @@
 package models
 
 import (
-	"fmt"
-	"strings"
+	"language-assistant/internal/messages"
 )
 
 type UserVocabulary struct {
 	UserID    string       `json:"userId"`
-	Date      string       `json:"date"` // YYYY-MM-DD
+	GroupID   string       `json:"groupId,omitempty"` // 群組的單字庫（UserID 同為群組 ID），個人單字庫為空字串
+	Date      string       `json:"date"`              // YYYY-MM-DD
 	Words     []WordRecord `json:"words"`
 	UpdatedAt string       `json:"updatedAt"` // ISO timestamp
 }
 
+// PostbackRegenerateExample 翻譯結果下方「換個例句」按鈕的 postback action
+const PostbackRegenerateExample = "regenerate_example"
+
+// PostbackVocabularyHistory /歷史 下方「下一頁」按鈕的 postback action，before=上一頁最後一天的日期
+const PostbackVocabularyHistory = "vocabulary_history"
+
+// Word sources：單字是從哪裡加入單字庫的
+const (
+	WordSourceTranslation = "translation"  // 用戶自己查詢（包含長文摘要的關鍵單字）
+	WordSourceDailyPush   = "daily_push"   // 從每日推播卡片加入複習
+	WordSourceImported    = "imported"     // 從外部單字表匯入
+	WordSourceCuratedPack = "curated_pack" // 精選單字包
+)
+
+var wordSourceLabels = map[string]messages.Key{
+	WordSourceTranslation: messages.WordSourceTranslationLabel,
+	WordSourceDailyPush:   messages.WordSourceDailyPushLabel,
+	WordSourceImported:    messages.WordSourceImportedLabel,
+	WordSourceCuratedPack: messages.WordSourceCuratedPackLabel,
+}
+
 type WordRecord struct {
 	Word         string `json:"word"`
 	PartOfSpeech string `json:"partOfSpeech"`
 	Translation  string `json:"translation"`
 	Sentence     string `json:"sentence"`
-	Timestamp    string `json:"timestamp"` // ISO timestamp
+	Source       string `json:"source,omitempty"`  // WordSource*，加入來源前的舊紀錄為空字串
+	AddedBy      string `json:"addedBy,omitempty"` // 群組單字庫中查詢這個單字的成員 userId
+	Timestamp    string `json:"timestamp"`         // ISO timestamp
+}
+
+// WordSource 回傳單字來源；舊紀錄沒有來源，當時只有查詢翻譯會寫入單字庫，因此視為自己查詢
+func (w WordRecord) WordSource() string {
+	if w.Source == "" {
+		return WordSourceTranslation
+	}
+	return w.Source
+}
+
+// SourceLabel 回傳顯示在回顧與查詢結果中的來源標籤，舊紀錄不顯示
+func (w WordRecord) SourceLabel() string {
+	key, ok := wordSourceLabels[w.Source]
+	if !ok {
+		return ""
+	}
+	return messages.Text(key)
 }
 
-func FormatWordRecords(records interface{}) string {
-	var sb strings.Builder
+// FilterWordsBySource 只保留指定來源的單字，source 為空字串時不過濾
+func FilterWordsBySource(words []WordRecord, source string) []WordRecord {
+	if source == "" {
+		return words
+	}
+	var filtered []WordRecord
+	for _, word := range words {
+		if word.WordSource() == source {
+			filtered = append(filtered, word)
+		}
+	}
+	return filtered
+}
+
+// FilterVocabulariesBySource 對每天的單字套用 FilterWordsBySource，沒有剩下單字的日期會被移除
+func FilterVocabulariesBySource(vocabularies []UserVocabulary, source string) []UserVocabulary {
+	if source == "" {
+		return vocabularies
+	}
+	var filtered []UserVocabulary
+	for _, vocabulary := range vocabularies {
+		if words := FilterWordsBySource(vocabulary.Words, source); len(words) > 0 {
+			vocabulary.Words = words
+			filtered = append(filtered, vocabulary)
+		}
+	}
+	return filtered
+}
+
+// WordOccurrence is one saved record of a word together with the date it was saved on
+type WordOccurrence struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	WordRecord
+}
 
+// FormatWordRecords 格式化單字紀錄，內容超過 LINE 單則上限時在單字之間分成多則訊息
+func FormatWordRecords(records interface{}) []string {
 	switch v := records.(type) {
 	case WordRecord:
 		// 單個單字格式化（不包含標題）
-		sb.WriteString(fmt.Sprintf("【%s】(%s)\n", v.Word, v.PartOfSpeech))
-		sb.WriteString(fmt.Sprintf("翻譯：%s\n", v.Translation))
-		sb.WriteString("例句：\n")
-		sb.WriteString(fmt.Sprintf("  %s\n", v.Sentence))
+		return messages.Split([]string{messages.Render(messages.WordRecordCard, v)}, "")
 	case []WordRecord:
 		// 多個單字格式化（包含標題）
 		if len(v) == 0 {
-			return "今天還沒有學習任何單字喔！"
+			return []string{messages.Text(messages.ReviewEmpty)}
 		}
 
-		sb.WriteString("【本日單字回顧】📚\n\n")
-		for i, w := range v {
-			if i > 0 {
-				sb.WriteString("\n-------------------\n")
-			}
+		cards := make([]string, 0, len(v))
+		for _, w := range v {
 			// 直接格式化單字內容，不要再調用 FormatWordRecords
-			sb.WriteString(fmt.Sprintf("%s (%s)\n", w.Word, w.PartOfSpeech))
-			sb.WriteString(fmt.Sprintf("翻譯：%s\n", w.Translation))
-			sb.WriteString("例句：\n")
-			sb.WriteString(fmt.Sprintf("  %s\n", w.Sentence))
+			cards = append(cards, messages.Render(messages.ReviewWord, w))
 		}
+		cards[0] = messages.Text(messages.ReviewHeader) + cards[0]
+		return messages.Split(cards, messages.Text(messages.ReviewSeparator))
 	}
-	return sb.String()
+	return nil
 }
@@ -0,0 +1,11 @@
+package models
+
+// WebhookEvent records a processed LINE webhook event so redelivered copies can be skipped
+type WebhookEvent struct {
+	EventID      string `json:"eventId" dynamodbav:"eventId"` // LINE 的 webhookEventId，重送時不變
+	UserID       string `json:"userId" dynamodbav:"userId"`
+	Type         string `json:"type" dynamodbav:"type"`
+	IsRedelivery bool   `json:"isRedelivery" dynamodbav:"isRedelivery"` // 第一次收到時 LINE 是否已標記為重送
+	ReceivedAt   string `json:"receivedAt" dynamodbav:"receivedAt"`     // ISO timestamp
+	ExpiresAt    int64  `json:"expiresAt" dynamodbav:"expiresAt"`       // Unix 秒，同時作為 DynamoDB TTL
+}
@@ -0,0 +1,10 @@
+package models
+
+// WordHistoryEntry is one word that has been pushed to a user for a course; unlike the bloom filter it is exact
+type WordHistoryEntry struct {
+	UserID   string `json:"userId" dynamodbav:"userId"`
+	Course   string `json:"course" dynamodbav:"course"`
+	Word     string `json:"word" dynamodbav:"word"`                     // 正規化（小寫）後的單字，同時作為 SK
+	Band     string `json:"band,omitempty" dynamodbav:"band,omitempty"` // 推播當時用戶的程度級距（A2~C1）
+	PushedAt string `json:"pushedAt" dynamodbav:"pushedAt"`
+}
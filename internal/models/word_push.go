@@ -0,0 +1,26 @@
+package models
+
+// WordPushPayloadVersion 目前產生的每日單字推播 payload 版本。
+// 只有不相容的變更（改名、改型別、移除欄位）才需要升版；新增選填欄位不升版，舊版 language-vocabulary 會直接忽略
+const WordPushPayloadVersion = 1
+
+// Word push sources, recorded for logging only
+const (
+	WordPushSourceSchedule   = "schedule"   // EventBridge Scheduler 的每日排程
+	WordPushSourceSettings   = "settings"   // 完成推播設定後立即推播一次
+	WordPushSourceAdminctl   = "adminctl"   // 管理者手動觸發
+	WordPushSourceDispatcher = "dispatcher" // fan-out 模式下 language-dispatcher 經由 SQS 送出
+)
+
+// MaxWordPushWordCount 是 payload 覆寫單字數時的上限，與設定選單的最大值相同
+const MaxWordPushWordCount = 20
+
+// WordPushPayload is the JSON contract for invoking language-vocabulary, shared by the
+// EventBridge schedule target input, direct Lambda invokes and SQS message bodies
+type WordPushPayload struct {
+	Version   int    `json:"version"` // 0 表示版本欄位出現前的舊 payload（只有 userId），視同版本 1
+	UserID    string `json:"userId"`
+	Source    string `json:"source,omitempty"`
+	DryRun    bool   `json:"dryRun,omitempty"`    // 只產生單字並回傳，不推播也不寫入推播紀錄、單字歷史與 bloom filter
+	WordCount int    `json:"wordCount,omitempty"` // 覆寫用戶設定的每日單字數，0 表示使用設定值
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// 週活躍：PK = activity#<week>，SK = userId
+// 留存報表：PK = retention#weekly，SK = cohort
+const weeklyRetentionKey = "retention#weekly"
+
+type analyticsRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewAnalyticsRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.AnalyticsRepository {
+	return &analyticsRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+func weeklyActivityKey(week string) string {
+	return "activity#" + week
+}
+
+// RecordWeeklyActivity 記錄用戶在某週有活躍，同一週重複寫入會覆蓋同一筆
+func (r *analyticsRepository) RecordWeeklyActivity(activity models.WeeklyActivity) error {
+	item, err := attributevalue.MarshalMap(activity)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal weekly activity")
+		return fmt.Errorf("failed to marshal weekly activity: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: weeklyActivityKey(activity.Week)}
+	item["sk"] = &types.AttributeValueMemberS{Value: activity.UserID}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save weekly activity to DynamoDB")
+		return fmt.Errorf("failed to save weekly activity: %w", err)
+	}
+
+	return nil
+}
+
+func (r *analyticsRepository) GetWeeklyActivity(week string) ([]models.WeeklyActivity, error) {
+	activities := []models.WeeklyActivity{}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: weeklyActivityKey(week)},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query weekly activity from DynamoDB")
+			return nil, fmt.Errorf("failed to query weekly activity: %w", err)
+		}
+
+		var page []models.WeeklyActivity
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			r.logger.WithError(err).Error("Failed to unmarshal weekly activity")
+			return nil, fmt.Errorf("failed to unmarshal weekly activity: %w", err)
+		}
+		activities = append(activities, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return activities, nil
+}
+
+func (r *analyticsRepository) SaveCohortRetention(retention models.CohortRetention) error {
+	item, err := attributevalue.MarshalMap(retention)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal cohort retention")
+		return fmt.Errorf("failed to marshal cohort retention: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: weeklyRetentionKey}
+	item["sk"] = &types.AttributeValueMemberS{Value: retention.Cohort}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save cohort retention to DynamoDB")
+		return fmt.Errorf("failed to save cohort retention: %w", err)
+	}
+
+	return nil
+}
+
+// GetCohortRetention 取得最近 limit 個 cohort 的留存報表（最新的在前）
+func (r *analyticsRepository) GetCohortRetention(limit int) ([]models.CohortRetention, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: weeklyRetentionKey},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query cohort retention from DynamoDB")
+		return nil, fmt.Errorf("failed to query cohort retention: %w", err)
+	}
+
+	retention := []models.CohortRetention{}
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &retention); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal cohort retention")
+		return nil, fmt.Errorf("failed to unmarshal cohort retention: %w", err)
+	}
+
+	return retention, nil
+}
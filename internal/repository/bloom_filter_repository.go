@@ -45,7 +45,7 @@ func (r *BloomFilterRepository) GetBloomFilter(userID, course string) (*models.B
 	if result.Item == nil {
 		// Return a new Bloom Filter if one doesn't exist
 		r.logger.Infof("No existing bloom filter found for user %s course %s, creating new one", userID, course)
-		return models.NewBloomFilter(userID, 10000), nil
+		return models.NewCountingBloomFilter(userID), nil
 	}
 
 	var bloomFilter models.BloomFilter
@@ -135,14 +135,14 @@ func (r *BloomFilterRepository) AddWordsToBloomFilter(userID, course string, wor
 		return fmt.Errorf("failed to get bloom filter: %w", err)
 	}
 
-	r.logger.Infof("Before adding words: BitArray size=%d, first 10 bytes: %v", len(filter.BitArray), filter.BitArray[:10])
+	r.logger.Infof("Before adding words: %d of %d positions set", filter.SetPositions(), filter.Size)
 
 	for i, word := range words {
 		r.logger.Debugf("Adding word %d: %s", i+1, word.Word)
 		filter.Add(word.Word)
 	}
 
-	r.logger.Infof("After adding words: first 10 bytes: %v", filter.BitArray[:10])
+	r.logger.Infof("After adding words: %d of %d positions set", filter.SetPositions(), filter.Size)
 
 	err = r.SaveBloomFilter(filter, course)
 	if err != nil {
@@ -151,4 +151,34 @@ func (r *BloomFilterRepository) AddWordsToBloomFilter(userID, course string, wor
 
 	r.logger.Infof("Added %d words to bloom filter for user %s course %s", len(words), userID, course)
 	return nil
-}
\ No newline at end of file
+}
+// RemoveWordFromBloomFilter removes a word so it can be pushed again. Filters created before counting
+// filters were introduced cannot remove words, in which case false is returned and nothing is saved.
+func (r *BloomFilterRepository) RemoveWordFromBloomFilter(userID, word, course string) (bool, error) {
+	filter, err := r.GetBloomFilter(userID, course)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bloom filter: %w", err)
+	}
+
+	if !filter.Remove(word) {
+		r.logger.Infof("Word '%s' not removed from bloom filter for user %s course %s (counting=%t)", word, userID, course, filter.IsCounting())
+		return false, nil
+	}
+
+	if err := r.SaveBloomFilter(filter, course); err != nil {
+		return false, fmt.Errorf("failed to save updated bloom filter: %w", err)
+	}
+
+	r.logger.Infof("Removed word '%s' from bloom filter for user %s course %s", word, userID, course)
+	return true, nil
+}
+
+// ClearBloomFilter replaces the course's bloom filter with an empty counting filter
+func (r *BloomFilterRepository) ClearBloomFilter(userID, course string) error {
+	if err := r.SaveBloomFilter(models.NewCountingBloomFilter(userID), course); err != nil {
+		return fmt.Errorf("failed to clear bloom filter: %w", err)
+	}
+
+	r.logger.Infof("Cleared bloom filter for user %s course %s", userID, course)
+	return nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// PK = experiment#card_format，SK = <date>#<format>
+const cardFormatExperimentKey = "experiment#card_format"
+
+type cardFormatExperimentRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewCardFormatExperimentRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.CardFormatExperimentRepository {
+	return &cardFormatExperimentRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+func (r *cardFormatExperimentRepository) RecordCardFormatPush(date string, format models.CardFormat) error {
+	return r.increment(date, string(format), "pushes")
+}
+
+func (r *cardFormatExperimentRepository) RecordCardFormatEngagement(date string, format models.CardFormat) error {
+	return r.increment(date, string(format), "engagements")
+}
+
+// increment 以原子加法累計指定日期、格式的計數
+func (r *cardFormatExperimentRepository) increment(date, format, counter string) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: cardFormatExperimentKey},
+			"sk": &types.AttributeValueMemberS{Value: date + "#" + format},
+		},
+		UpdateExpression: aws.String("SET #date = :date, #format = :format ADD #counter :one"),
+		ExpressionAttributeNames: map[string]string{
+			"#date":    "date",
+			"#format":  "format",
+			"#counter": counter,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":date":   &types.AttributeValueMemberS{Value: date},
+			":format": &types.AttributeValueMemberS{Value: format},
+			":one":    &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to record card format experiment in DynamoDB")
+		return fmt.Errorf("failed to record card format %s: %w", counter, err)
+	}
+
+	return nil
+}
+
+// GetCardFormatMetrics 取得日期區間（含頭尾）內每天、每種格式的指標，日期由舊到新
+func (r *cardFormatExperimentRepository) GetCardFormatMetrics(fromDate, toDate string) ([]models.CardFormatMetrics, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: cardFormatExperimentKey},
+			":from": &types.AttributeValueMemberS{Value: fromDate},
+			":to":   &types.AttributeValueMemberS{Value: toDate + "#~"}, // "~" 排在所有格式名稱之後
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query card format metrics from DynamoDB")
+		return nil, fmt.Errorf("failed to query card format metrics: %w", err)
+	}
+
+	metrics := []models.CardFormatMetrics{}
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &metrics); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal card format metrics")
+		return nil, fmt.Errorf("failed to unmarshal card format metrics: %w", err)
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// 未審核的回報與暫停使用的單字各放在同一個 PK 下，方便管理後台列出與每日單字一次讀取
+const (
+	openContentReportsKey = "contentReport#open"
+	suppressedWordsKey    = "contentReport#suppressed"
+)
+
+type contentReportRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewContentReportRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.ContentReportRepository {
+	return &contentReportRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// OpenContentReport 將回報加入審核佇列，SK 為 reportId
+func (r *contentReportRepository) OpenContentReport(report models.ContentReport) error {
+	item, err := attributevalue.MarshalMap(report)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal content report")
+		return fmt.Errorf("failed to marshal content report: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: openContentReportsKey}
+	item["sk"] = &types.AttributeValueMemberS{Value: report.ReportID}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save content report to DynamoDB")
+		return fmt.Errorf("failed to save content report: %w", err)
+	}
+
+	return nil
+}
+
+// GetOpenContentReports 列出所有未審核的回報，依回報時間排序
+func (r *contentReportRepository) GetOpenContentReports() ([]models.ContentReport, error) {
+	reports := []models.ContentReport{}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: openContentReportsKey},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query content reports from DynamoDB")
+			return nil, fmt.Errorf("failed to query content reports: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var report models.ContentReport
+			if err := attributevalue.UnmarshalMap(item, &report); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal content report")
+				continue
+			}
+			reports = append(reports, report)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return reports, nil
+}
+
+// ResolveContentReport 審核完畢後將回報移出佇列，回傳被移除的回報；回報不存在時回傳 nil
+func (r *contentReportRepository) ResolveContentReport(reportID string) (*models.ContentReport, error) {
+	result, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: openContentReportsKey},
+			"sk": &types.AttributeValueMemberS{Value: reportID},
+		},
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete content report from DynamoDB")
+		return nil, fmt.Errorf("failed to delete content report: %w", err)
+	}
+	if len(result.Attributes) == 0 {
+		return nil, nil
+	}
+
+	var report models.ContentReport
+	if err := attributevalue.UnmarshalMap(result.Attributes, &report); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal content report")
+		return nil, fmt.Errorf("failed to unmarshal content report: %w", err)
+	}
+	return &report, nil
+}
+
+// SuppressWord 暫停在每日單字中使用 word（呼叫端先以 NormalizeHistoryWord 正規化），記錄是哪一筆回報造成的
+func (r *contentReportRepository) SuppressWord(word, reportID string) error {
+	_, err := r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item: map[string]types.AttributeValue{
+			"pk":           &types.AttributeValueMemberS{Value: suppressedWordsKey},
+			"sk":           &types.AttributeValueMemberS{Value: word},
+			"reportId":     &types.AttributeValueMemberS{Value: reportID},
+			"suppressedAt": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save suppressed word to DynamoDB")
+		return fmt.Errorf("failed to save suppressed word: %w", err)
+	}
+
+	return nil
+}
+
+// UnsuppressWord 恢復使用 word；只有最後一次暫停是由 reportID 造成時才移除，避免審核舊回報時解除較新的回報。
+// 回傳是否有移除
+func (r *contentReportRepository) UnsuppressWord(word, reportID string) (bool, error) {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: suppressedWordsKey},
+			"sk": &types.AttributeValueMemberS{Value: word},
+		},
+		ConditionExpression: aws.String("reportId = :reportId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":reportId": &types.AttributeValueMemberS{Value: reportID},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to delete suppressed word from DynamoDB")
+		return false, fmt.Errorf("failed to delete suppressed word: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetSuppressedWords 回傳所有暫停使用的單字（正規化後），可直接傳給 FilterUnseenWords
+func (r *contentReportRepository) GetSuppressedWords() (map[string]bool, error) {
+	words := map[string]bool{}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: suppressedWordsKey},
+			},
+			ProjectionExpression: aws.String("sk"),
+			ExclusiveStartKey:    startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query suppressed words from DynamoDB")
+			return nil, fmt.Errorf("failed to query suppressed words: %w", err)
+		}
+
+		for _, item := range result.Items {
+			if attr, ok := item["sk"].(*types.AttributeValueMemberS); ok {
+				words[attr.Value] = true
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return words, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type deckRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewDeckRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.DeckRepository {
+	return &deckRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#deck，SK = 清單名稱
+func deckKey(userID string) string {
+	return userID + "#deck"
+}
+
+// CreateDeck 建立空的單字清單，同名清單已存在時回傳 false
+func (r *deckRepository) CreateDeck(userID, name string) (bool, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	item, err := attributevalue.MarshalMap(models.Deck{
+		UserID:    userID,
+		Name:      name,
+		Words:     []models.DeckWord{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal deck")
+		return false, fmt.Errorf("failed to marshal deck: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: deckKey(userID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: name}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to save deck to DynamoDB")
+		return false, fmt.Errorf("failed to save deck: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetDeck 取得指定名稱的清單，不存在時回傳 nil
+func (r *deckRepository) GetDeck(userID, name string) (*models.Deck, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: deckKey(userID)},
+			"sk": &types.AttributeValueMemberS{Value: name},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get deck from DynamoDB")
+		return nil, fmt.Errorf("failed to get deck: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var deck models.Deck
+	if err := attributevalue.UnmarshalMap(result.Item, &deck); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal deck")
+		return nil, fmt.Errorf("failed to unmarshal deck: %w", err)
+	}
+
+	return &deck, nil
+}
+
+// GetDecks 回傳用戶所有清單，依名稱排序
+func (r *deckRepository) GetDecks(userID string) ([]models.Deck, error) {
+	decks := []models.Deck{}
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: deckKey(userID)},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query decks from DynamoDB")
+			return nil, fmt.Errorf("failed to query decks: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var deck models.Deck
+			if err := attributevalue.UnmarshalMap(item, &deck); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal deck, skipping")
+				continue
+			}
+			decks = append(decks, deck)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return decks, nil
+}
+
+// SaveDeck 覆寫清單的單字，清單已被刪除時不會重新建立
+func (r *deckRepository) SaveDeck(deck models.Deck) error {
+	deck.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	item, err := attributevalue.MarshalMap(deck)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal deck")
+		return fmt.Errorf("failed to marshal deck: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: deckKey(deck.UserID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: deck.Name}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(pk)"),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save deck to DynamoDB")
+		return fmt.Errorf("failed to save deck: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type interactionRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewInteractionRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.InteractionRepository {
+	return &interactionRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = interaction#<date>（UTC），SK = occurredAt#userId，方便依日期區間做彙總分析
+func interactionKey(date string) string {
+	return "interaction#" + date
+}
+
+func (r *interactionRepository) RecordInteraction(interaction models.Interaction) error {
+	occurredAt := time.Now().UTC()
+	if interaction.OccurredAt != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, interaction.OccurredAt)
+		if err != nil {
+			return fmt.Errorf("invalid occurredAt %q: %w", interaction.OccurredAt, err)
+		}
+		occurredAt = parsed.UTC()
+	}
+	interaction.OccurredAt = occurredAt.Format(time.RFC3339Nano)
+
+	item, err := attributevalue.MarshalMap(interaction)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal interaction")
+		return fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: interactionKey(occurredAt.Format("2006-01-02"))}
+	item["sk"] = &types.AttributeValueMemberS{Value: interaction.OccurredAt + "#" + interaction.UserID}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save interaction to DynamoDB")
+		return fmt.Errorf("failed to save interaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetInteractionsByDate 取得某天（UTC）的所有互動紀錄，依發生時間排序
+func (r *interactionRepository) GetInteractionsByDate(date string) ([]models.Interaction, error) {
+	interactions := []models.Interaction{}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: interactionKey(date)},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query interactions from DynamoDB")
+			return nil, fmt.Errorf("failed to query interactions: %w", err)
+		}
+
+		var page []models.Interaction
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			r.logger.WithError(err).Error("Failed to unmarshal interactions")
+			return nil, fmt.Errorf("failed to unmarshal interactions: %w", err)
+		}
+		interactions = append(interactions, page...)
+
+		// 單日互動量可能超過單次 Query 上限（1MB），需要分頁
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return interactions, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// 所有 kill switch 放在同一個 PK 下（SK = 子系統名稱），各 Lambda 一次 Query 即可取得全部狀態
+const killSwitchesKey = "killswitch"
+
+type killSwitchRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewKillSwitchRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.KillSwitchRepository {
+	return &killSwitchRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// GetKillSwitches 取得所有設定過的 kill switch 狀態，沒有設定過的子系統不會出現在結果中
+func (r *killSwitchRepository) GetKillSwitches() (map[models.KillSwitch]models.KillSwitchState, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: killSwitchesKey},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query kill switches from DynamoDB")
+		return nil, fmt.Errorf("failed to query kill switches: %w", err)
+	}
+
+	states := make(map[models.KillSwitch]models.KillSwitchState, len(result.Items))
+	for _, item := range result.Items {
+		var state models.KillSwitchState
+		if err := attributevalue.UnmarshalMap(item, &state); err != nil {
+			r.logger.WithError(err).Warn("Failed to unmarshal kill switch")
+			continue
+		}
+		states[state.Switch] = state
+	}
+	return states, nil
+}
+
+// SaveKillSwitch 覆寫 kill switch 的狀態
+func (r *killSwitchRepository) SaveKillSwitch(state models.KillSwitchState) error {
+	item, err := attributevalue.MarshalMap(state)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal kill switch")
+		return fmt.Errorf("failed to marshal kill switch: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: killSwitchesKey}
+	item["sk"] = &types.AttributeValueMemberS{Value: string(state.Switch)}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save kill switch to DynamoDB")
+		return fmt.Errorf("failed to save kill switch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type migrationRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewMigrationRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.MigrationCheckpointRepository {
+	return &migrationRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = migration#<id>，SK = checkpoint
+func migrationKey(migrationID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: utils.MigrationCheckpointPrefix + migrationID},
+		"sk": &types.AttributeValueMemberS{Value: "checkpoint"},
+	}
+}
+
+// GetMigrationCheckpoint 取得 migration 的進度，尚未執行過時回傳 nil
+func (r *migrationRepository) GetMigrationCheckpoint(migrationID string) (*models.MigrationCheckpoint, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName:      aws.String(r.tableName),
+		Key:            migrationKey(migrationID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get migration checkpoint from DynamoDB")
+		return nil, fmt.Errorf("failed to get migration checkpoint: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var checkpoint models.MigrationCheckpoint
+	if err := attributevalue.UnmarshalMap(result.Item, &checkpoint); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal migration checkpoint")
+		return nil, fmt.Errorf("failed to unmarshal migration checkpoint: %w", err)
+	}
+	if key, ok := result.Item["lastEvaluatedKey"].(*types.AttributeValueMemberM); ok {
+		checkpoint.LastEvaluatedKey = key.Value
+	}
+
+	return &checkpoint, nil
+}
+
+// SaveMigrationCheckpoint 覆寫 migration 的進度
+func (r *migrationRepository) SaveMigrationCheckpoint(checkpoint models.MigrationCheckpoint) error {
+	item, err := attributevalue.MarshalMap(checkpoint)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal migration checkpoint")
+		return fmt.Errorf("failed to marshal migration checkpoint: %w", err)
+	}
+	for k, v := range migrationKey(checkpoint.MigrationID) {
+		item[k] = v
+	}
+	if len(checkpoint.LastEvaluatedKey) > 0 {
+		item["lastEvaluatedKey"] = &types.AttributeValueMemberM{Value: checkpoint.LastEvaluatedKey}
+	}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save migration checkpoint to DynamoDB")
+		return fmt.Errorf("failed to save migration checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMigrationCheckpoint 清除進度，下次執行會從頭掃描
+func (r *migrationRepository) DeleteMigrationCheckpoint(migrationID string) error {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       migrationKey(migrationID),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete migration checkpoint from DynamoDB")
+		return fmt.Errorf("failed to delete migration checkpoint: %w", err)
+	}
+
+	return nil
+}
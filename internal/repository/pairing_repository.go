@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type pairingRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewPairingRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.PairingRepository {
+	return &pairingRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+func pairingCodeKey(code string) string {
+	return "pairing#" + code
+}
+
+func webSessionKey(tokenHash string) string {
+	return "session#" + tokenHash
+}
+
+func (r *pairingRepository) SavePairingCode(code models.PairingCode) error {
+	item, err := attributevalue.MarshalMap(code)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal pairing code")
+		return fmt.Errorf("failed to marshal pairing code: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: pairingCodeKey(code.Code)}
+
+	// 避免極少數情況下覆蓋到尚未使用的相同代碼
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save pairing code to DynamoDB")
+		return fmt.Errorf("failed to save pairing code: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumePairingCode 刪除並回傳配對代碼，確保每個代碼只能使用一次。
+// 代碼不存在或已過期時回傳 nil（DynamoDB TTL 刪除有延遲，因此需自行檢查 expiresAt）
+func (r *pairingRepository) ConsumePairingCode(code string) (*models.PairingCode, error) {
+	result, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: pairingCodeKey(code)},
+		},
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to consume pairing code from DynamoDB")
+		return nil, fmt.Errorf("failed to consume pairing code: %w", err)
+	}
+
+	if len(result.Attributes) == 0 {
+		return nil, nil
+	}
+
+	var pairing models.PairingCode
+	if err := attributevalue.UnmarshalMap(result.Attributes, &pairing); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal pairing code")
+		return nil, fmt.Errorf("failed to unmarshal pairing code: %w", err)
+	}
+
+	if pairing.ExpiresAt <= time.Now().Unix() {
+		return nil, nil
+	}
+
+	return &pairing, nil
+}
+
+func (r *pairingRepository) SaveWebSession(session models.WebSession) error {
+	item, err := attributevalue.MarshalMap(session)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal web session")
+		return fmt.Errorf("failed to marshal web session: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: webSessionKey(session.TokenHash)}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save web session to DynamoDB")
+		return fmt.Errorf("failed to save web session: %w", err)
+	}
+
+	return nil
+}
+
+// GetWebSession 取得有效的 session，不存在或已過期時回傳 nil
+func (r *pairingRepository) GetWebSession(tokenHash string) (*models.WebSession, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: webSessionKey(tokenHash)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get web session from DynamoDB")
+		return nil, fmt.Errorf("failed to get web session: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var session models.WebSession
+	if err := attributevalue.UnmarshalMap(result.Item, &session); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal web session")
+		return nil, fmt.Errorf("failed to unmarshal web session: %w", err)
+	}
+
+	if session.ExpiresAt <= time.Now().Unix() {
+		return nil, nil
+	}
+
+	return &session, nil
+}
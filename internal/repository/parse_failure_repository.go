@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type parseFailureRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewParseFailureRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.ParseFailureRepository {
+	return &parseFailureRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = parseFailure#<feature>，SK = occurredAt，方便依功能列出最近的失敗
+func parseFailureKey(feature string) string {
+	return "parseFailure#" + feature
+}
+
+func (r *parseFailureRepository) SaveParseFailure(failure models.ParseFailure) error {
+	if failure.OccurredAt == "" {
+		failure.OccurredAt = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	item, err := attributevalue.MarshalMap(failure)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal parse failure")
+		return fmt.Errorf("failed to marshal parse failure: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: parseFailureKey(failure.Feature)}
+	item["sk"] = &types.AttributeValueMemberS{Value: failure.OccurredAt}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save parse failure to DynamoDB")
+		return fmt.Errorf("failed to save parse failure: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecentParseFailures 取得某個功能最近的 limit 筆解析失敗，新的在前
+func (r *parseFailureRepository) GetRecentParseFailures(feature string, limit int) ([]models.ParseFailure, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: parseFailureKey(feature)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query parse failures from DynamoDB")
+		return nil, fmt.Errorf("failed to query parse failures: %w", err)
+	}
+
+	failures := []models.ParseFailure{}
+	for _, item := range result.Items {
+		var failure models.ParseFailure
+		if err := attributevalue.UnmarshalMap(item, &failure); err != nil {
+			r.logger.WithError(err).Warn("Failed to unmarshal parse failure")
+			continue
+		}
+		failures = append(failures, failure)
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type paymentRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewPaymentRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.PaymentRepository {
+	return &paymentRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// 付款確認時只帶回 orderId，所以付款以 orderId 為 key：PK = payment#<orderId>，SK = payment
+func paymentKey(orderID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "payment#" + orderID},
+		"sk": &types.AttributeValueMemberS{Value: "payment"},
+	}
+}
+
+// 收據依用戶列出：PK = userId#receipt，SK = confirmedAt#orderId
+func receiptKey(userID string) string {
+	return userID + "#receipt"
+}
+
+// SavePayment 建立一筆新的付款，orderId 重複時回傳錯誤
+func (r *paymentRepository) SavePayment(payment models.Payment) error {
+	item, err := attributevalue.MarshalMap(payment)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal payment")
+		return fmt.Errorf("failed to marshal payment: %w", err)
+	}
+	for name, value := range paymentKey(payment.OrderID) {
+		item[name] = value
+	}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save payment to DynamoDB")
+		return fmt.Errorf("failed to save payment: %w", err)
+	}
+
+	return nil
+}
+
+// GetPayment 取得付款，不存在時回傳 nil
+func (r *paymentRepository) GetPayment(orderID string) (*models.Payment, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       paymentKey(orderID),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get payment from DynamoDB")
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var payment models.Payment
+	if err := attributevalue.UnmarshalMap(result.Item, &payment); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal payment")
+		return nil, fmt.Errorf("failed to unmarshal payment: %w", err)
+	}
+	return &payment, nil
+}
+
+// UpdatePaymentStatus 只在付款目前是 from 狀態時改成 to，回傳是否有更新；
+// 金流商重複呼叫確認網址時，只有第一次會成功，避免重複處理同一筆付款
+func (r *paymentRepository) UpdatePaymentStatus(orderID string, from, to models.PaymentStatus, updatedAt string) (bool, error) {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tableName),
+		Key:                 paymentKey(orderID),
+		UpdateExpression:    aws.String("SET #status = :to, updatedAt = :updatedAt"),
+		ConditionExpression: aws.String("#status = :from"),
+		// status 是 DynamoDB 的保留字
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":from":      &types.AttributeValueMemberS{Value: string(from)},
+			":to":        &types.AttributeValueMemberS{Value: string(to)},
+			":updatedAt": &types.AttributeValueMemberS{Value: updatedAt},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to update payment status in DynamoDB")
+		return false, fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *paymentRepository) SavePaymentReceipt(receipt models.PaymentReceipt) error {
+	item, err := attributevalue.MarshalMap(receipt)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal payment receipt")
+		return fmt.Errorf("failed to marshal payment receipt: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: receiptKey(receipt.UserID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: receipt.ConfirmedAt + "#" + receipt.OrderID}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save payment receipt to DynamoDB")
+		return fmt.Errorf("failed to save payment receipt: %w", err)
+	}
+
+	return nil
+}
+
+// GetPaymentReceipts 列出用戶所有的收據，新的在前
+func (r *paymentRepository) GetPaymentReceipts(userID string) ([]models.PaymentReceipt, error) {
+	receipts := []models.PaymentReceipt{}
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: receiptKey(userID)},
+			},
+			ScanIndexForward:  aws.Bool(false),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query payment receipts from DynamoDB")
+			return nil, fmt.Errorf("failed to query payment receipts: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var receipt models.PaymentReceipt
+			if err := attributevalue.UnmarshalMap(item, &receipt); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal payment receipt")
+				continue
+			}
+			receipts = append(receipts, receipt)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return receipts, nil
+}
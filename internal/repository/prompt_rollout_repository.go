@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type promptRolloutRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewPromptRolloutRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.PromptRolloutRepository {
+	return &promptRolloutRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = prompt#<name>，SK = rollout（目前設定）或 metrics#<rolloutId>#<version>
+func promptRolloutKey(prompt string) string {
+	return "prompt#" + prompt
+}
+
+func promptMetricsPrefix(rolloutID string) string {
+	return "metrics#" + rolloutID + "#"
+}
+
+func (r *promptRolloutRepository) GetPromptRollout(prompt string) (*models.PromptRollout, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: promptRolloutKey(prompt)},
+			"sk": &types.AttributeValueMemberS{Value: "rollout"},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get prompt rollout from DynamoDB")
+		return nil, fmt.Errorf("failed to get prompt rollout: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var rollout models.PromptRollout
+	if err := attributevalue.UnmarshalMap(result.Item, &rollout); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal prompt rollout")
+		return nil, fmt.Errorf("failed to unmarshal prompt rollout: %w", err)
+	}
+
+	return &rollout, nil
+}
+
+func (r *promptRolloutRepository) SavePromptRollout(rollout models.PromptRollout) error {
+	rollout.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	item, err := attributevalue.MarshalMap(rollout)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal prompt rollout")
+		return fmt.Errorf("failed to marshal prompt rollout: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: promptRolloutKey(rollout.Prompt)}
+	item["sk"] = &types.AttributeValueMemberS{Value: "rollout"}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save prompt rollout to DynamoDB")
+		return fmt.Errorf("failed to save prompt rollout: %w", err)
+	}
+
+	return nil
+}
+
+// EndPromptRollout 將進行中的 rollout 改為指定狀態。
+// 只有在同一個 rollout 仍為 active 時才會更新，回傳是否真的有變更（避免重複告警）
+func (r *promptRolloutRepository) EndPromptRollout(prompt, rolloutID, status, reason string) (bool, error) {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: promptRolloutKey(prompt)},
+			"sk": &types.AttributeValueMemberS{Value: "rollout"},
+		},
+		UpdateExpression:    aws.String("SET #status = :status, #reason = :reason, updatedAt = :updatedAt"),
+		ConditionExpression: aws.String("#status = :active AND rolloutId = :rolloutId"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+			"#reason": "reason",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status":    &types.AttributeValueMemberS{Value: status},
+			":reason":    &types.AttributeValueMemberS{Value: reason},
+			":updatedAt": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":active":    &types.AttributeValueMemberS{Value: models.PromptRolloutActive},
+			":rolloutId": &types.AttributeValueMemberS{Value: rolloutID},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to end prompt rollout in DynamoDB")
+		return false, fmt.Errorf("failed to end prompt rollout: %w", err)
+	}
+
+	return true, nil
+}
+
+// RecordPromptOutcome 以原子加法累計某版本在此次 rollout 中的請求數與解析失敗數
+func (r *promptRolloutRepository) RecordPromptOutcome(prompt, rolloutID, version string, parseFailed bool) error {
+	failures := "0"
+	if parseFailed {
+		failures = "1"
+	}
+
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: promptRolloutKey(prompt)},
+			"sk": &types.AttributeValueMemberS{Value: promptMetricsPrefix(rolloutID) + version},
+		},
+		UpdateExpression: aws.String("SET version = :version ADD requests :one, parseFailures :failures"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version":  &types.AttributeValueMemberS{Value: version},
+			":one":      &types.AttributeValueMemberN{Value: "1"},
+			":failures": &types.AttributeValueMemberN{Value: failures},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to record prompt outcome in DynamoDB")
+		return fmt.Errorf("failed to record prompt outcome: %w", err)
+	}
+
+	return nil
+}
+
+// GetPromptMetrics 取得此次 rollout 各版本的指標，key 為版本
+func (r *promptRolloutRepository) GetPromptMetrics(prompt, rolloutID string) (map[string]models.PromptMetrics, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: promptRolloutKey(prompt)},
+			":prefix": &types.AttributeValueMemberS{Value: promptMetricsPrefix(rolloutID)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query prompt metrics from DynamoDB")
+		return nil, fmt.Errorf("failed to query prompt metrics: %w", err)
+	}
+
+	var metrics []models.PromptMetrics
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &metrics); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal prompt metrics")
+		return nil, fmt.Errorf("failed to unmarshal prompt metrics: %w", err)
+	}
+
+	byVersion := make(map[string]models.PromptMetrics, len(metrics))
+	for _, m := range metrics {
+		byVersion[m.Version] = m
+	}
+
+	return byVersion, nil
+}
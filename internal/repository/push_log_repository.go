@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type pushLogRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewPushLogRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.PushLogRepository {
+	return &pushLogRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+func (r *pushLogRepository) SavePushLog(log models.PushLog) error {
+	if log.PushedAt == "" {
+		log.PushedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	item, err := attributevalue.MarshalMap(log)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal push log")
+		return fmt.Errorf("failed to marshal push log: %w", err)
+	}
+
+	// PK = userId#pushLog, SK = date#pushedAt（同一天可能有多次推播）
+	item["pk"] = &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#pushLog", log.UserID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: log.Date + "#" + log.PushedAt}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save push log to DynamoDB")
+		return fmt.Errorf("failed to save push log: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecentPushLogs 取得最近 N 天的推播紀錄（最新的在前）
+func (r *pushLogRepository) GetRecentPushLogs(userID string, days int) ([]models.PushLog, error) {
+	since := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk AND sk >= :since"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#pushLog", userID)},
+			":since": &types.AttributeValueMemberS{Value: since},
+		},
+		ScanIndexForward: aws.Bool(false), // 最新的推播在前
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query push logs from DynamoDB")
+		return nil, fmt.Errorf("failed to query push logs: %w", err)
+	}
+
+	logs := []models.PushLog{}
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &logs); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal push logs")
+		return nil, fmt.Errorf("failed to unmarshal push logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetPushLogsByDate 取得指定日期（用戶時區）的所有推播紀錄（最新的在前）
+func (r *pushLogRepository) GetPushLogsByDate(userID, date string) ([]models.PushLog, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :date)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#pushLog", userID)},
+			":date": &types.AttributeValueMemberS{Value: date + "#"},
+		},
+		ScanIndexForward: aws.Bool(false),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query push logs by date from DynamoDB")
+		return nil, fmt.Errorf("failed to query push logs by date: %w", err)
+	}
+
+	logs := []models.PushLog{}
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &logs); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal push logs")
+		return nil, fmt.Errorf("failed to unmarshal push logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetAllPushLogs 取得用戶全部的推播紀錄（最舊的在前），供重建 bloom filter 等維運作業使用
+func (r *pushLogRepository) GetAllPushLogs(userID string) ([]models.PushLog, error) {
+	logs := []models.PushLog{}
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#pushLog", userID)},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query all push logs from DynamoDB")
+			return nil, fmt.Errorf("failed to query all push logs: %w", err)
+		}
+
+		var page []models.PushLog
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			r.logger.WithError(err).Error("Failed to unmarshal push logs")
+			return nil, fmt.Errorf("failed to unmarshal push logs: %w", err)
+		}
+		logs = append(logs, page...)
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return logs, nil
+}
+
+// PK = userId#scheduledPush，SK = 日期（用戶時區）
+func scheduledPushKey(userID, date string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: userID + "#scheduledPush"},
+		"sk": &types.AttributeValueMemberS{Value: date},
+	}
+}
+
+// ClaimScheduledPush 以條件寫入登記用戶當天的排程推播，回傳 false 表示當天已有其他排程（個人排程或 dispatcher）推播過
+func (r *pushLogRepository) ClaimScheduledPush(userID, date string, expiresAt int64) (bool, error) {
+	item := scheduledPushKey(userID, date)
+	item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)}
+
+	_, err := r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to save scheduled push claim to DynamoDB")
+		return false, fmt.Errorf("failed to save scheduled push claim: %w", err)
+	}
+
+	return true, nil
+}
+
+// ReleaseScheduledPush 刪除登記，讓推播失敗時當天的其他排程仍可以推播
+func (r *pushLogRepository) ReleaseScheduledPush(userID, date string) error {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       scheduledPushKey(userID, date),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete scheduled push claim from DynamoDB")
+		return fmt.Errorf("failed to delete scheduled push claim: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type quizRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewQuizRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.QuizRepository {
+	return &quizRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#quiz，SK = sessionId（開始時間），保留每次測驗的作答紀錄
+func quizKey(userID string) string {
+	return userID + "#quiz"
+}
+
+// SaveQuizSession 寫入整個測驗（含每題作答），每次作答後覆寫
+func (r *quizRepository) SaveQuizSession(session models.QuizSession) error {
+	item, err := attributevalue.MarshalMap(session)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal quiz session")
+		return fmt.Errorf("failed to marshal quiz session: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: quizKey(session.UserID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: session.SessionID}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save quiz session to DynamoDB")
+		return fmt.Errorf("failed to save quiz session: %w", err)
+	}
+
+	return nil
+}
+
+// GetQuizSession 取得指定的測驗，不存在時回傳 nil
+func (r *quizRepository) GetQuizSession(userID, sessionID string) (*models.QuizSession, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: quizKey(userID)},
+			"sk": &types.AttributeValueMemberS{Value: sessionID},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get quiz session from DynamoDB")
+		return nil, fmt.Errorf("failed to get quiz session: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var session models.QuizSession
+	if err := attributevalue.UnmarshalMap(result.Item, &session); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal quiz session")
+		return nil, fmt.Errorf("failed to unmarshal quiz session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// GetQuizSessions 取得用戶所有的測驗紀錄（依開始時間排序）
+func (r *quizRepository) GetQuizSessions(userID string) ([]models.QuizSession, error) {
+	var sessions []models.QuizSession
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: quizKey(userID)},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query quiz sessions from DynamoDB")
+			return nil, fmt.Errorf("failed to query quiz sessions: %w", err)
+		}
+
+		var page []models.QuizSession
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			r.logger.WithError(err).Error("Failed to unmarshal quiz sessions")
+			return nil, fmt.Errorf("failed to unmarshal quiz sessions: %w", err)
+		}
+		sessions = append(sessions, page...)
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return sessions, nil
+}
+
+// PK = userId#mastery，SK = 正規化後的單字，記錄測驗中連續答對的次數
+func masteryKey(userID string) string {
+	return userID + "#mastery"
+}
+
+// RecordWordResult 記錄單字的作答結果：答對時連續答對次數加一，答錯時歸零
+func (r *quizRepository) RecordWordResult(userID, word string, correct bool) error {
+	updateExpression := "SET correctStreak = :zero, updatedAt = :updatedAt"
+	values := map[string]types.AttributeValue{
+		":zero":      &types.AttributeValueMemberN{Value: "0"},
+		":updatedAt": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+	}
+	if correct {
+		updateExpression = "SET correctStreak = if_not_exists(correctStreak, :zero) + :one, updatedAt = :updatedAt"
+		values[":one"] = &types.AttributeValueMemberN{Value: "1"}
+	}
+
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: masteryKey(userID)},
+			"sk": &types.AttributeValueMemberS{Value: utils.NormalizeHistoryWord(word)},
+		},
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to record word quiz result to DynamoDB")
+		return fmt.Errorf("failed to record word quiz result: %w", err)
+	}
+
+	return nil
+}
+
+// GetMasteredWords 回傳連續答對達 utils.MasteryCorrectStreak 次的單字（正規化後）
+func (r *quizRepository) GetMasteredWords(userID string) (map[string]bool, error) {
+	words := map[string]bool{}
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			FilterExpression:       aws.String("correctStreak >= :mastered"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":       &types.AttributeValueMemberS{Value: masteryKey(userID)},
+				":mastered": &types.AttributeValueMemberN{Value: strconv.Itoa(utils.MasteryCorrectStreak)},
+			},
+			ProjectionExpression: aws.String("sk"),
+			ExclusiveStartKey:    lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query mastered words from DynamoDB")
+			return nil, fmt.Errorf("failed to query mastered words: %w", err)
+		}
+
+		for _, item := range result.Items {
+			if sk, ok := item["sk"].(*types.AttributeValueMemberS); ok {
+				words[sk.Value] = true
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return words, nil
+}
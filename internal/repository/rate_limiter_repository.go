@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type rateLimiterRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewRateLimiterRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.RateLimiterRepository {
+	return &rateLimiterRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#ratelimit，SK = 功能名稱，每個功能一個 token bucket，閒置後由 TTL 刪除
+func rateLimitKey(userID string) string {
+	return userID + "#ratelimit"
+}
+
+// GetBucket 取得用戶某功能的 token bucket，不存在時回傳 nil
+func (r *rateLimiterRepository) GetBucket(userID, feature string) (*models.RateLimitBucket, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: rateLimitKey(userID)},
+			"sk": &types.AttributeValueMemberS{Value: feature},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get rate limit bucket from DynamoDB")
+		return nil, fmt.Errorf("failed to get rate limit bucket: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var bucket models.RateLimitBucket
+	if err := attributevalue.UnmarshalMap(result.Item, &bucket); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal rate limit bucket")
+		return nil, fmt.Errorf("failed to unmarshal rate limit bucket: %w", err)
+	}
+	return &bucket, nil
+}
+
+// SaveBucket 只在 bucket 仍是讀取時的版本（bucket.Version - 1，新的 bucket 版本為 1）時寫入，
+// 回傳 false 表示同時有其他請求更新了 bucket，呼叫端應重新讀取後再試
+func (r *rateLimiterRepository) SaveBucket(userID, feature string, bucket models.RateLimitBucket, expiresAt time.Time) (bool, error) {
+	item, err := attributevalue.MarshalMap(bucket)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal rate limit bucket")
+		return false, fmt.Errorf("failed to marshal rate limit bucket: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: rateLimitKey(userID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: feature}
+	item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	}
+	if bucket.Version > 1 {
+		input.ConditionExpression = aws.String("version = :previous")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":previous": &types.AttributeValueMemberN{Value: strconv.FormatInt(bucket.Version-1, 10)},
+		}
+	}
+
+	_, err = r.dynamodb.PutItem(context.Background(), input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save rate limit bucket to DynamoDB")
+		return false, fmt.Errorf("failed to save rate limit bucket: %w", err)
+	}
+	return true, nil
+}
@@ -33,11 +33,14 @@ func (r *reminderRepository) GetUserVocabulariesByDate(date string) ([]models.Us
 		TableName:              aws.String(r.tableName),
 		IndexName:              aws.String("DateIndex"), // GSI 名稱
 		KeyConditionExpression: aws.String("#date = :dateVal"),
+		// 同一張表的推播紀錄等項目也有 date 欄位，只取單字紀錄（PK = userId#vocabulary）
+		FilterExpression: aws.String("contains(pk, :vocabularyKey)"),
 		ExpressionAttributeNames: map[string]string{
 			"#date": "date",
 		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":dateVal": &types.AttributeValueMemberS{Value: date},
+			":dateVal":       &types.AttributeValueMemberS{Value: date},
+			":vocabularyKey": &types.AttributeValueMemberS{Value: "#vocabulary"},
 		},
 	})
 
@@ -61,6 +64,11 @@ func (r *reminderRepository) GetUserVocabulariesByDate(date string) ([]models.Us
 			userVoca.UserID = attr.Value
 		}
 
+		// Extract `groupId`（群組的單字庫）
+		if attr, ok := item["groupId"].(*types.AttributeValueMemberS); ok {
+			userVoca.GroupID = attr.Value
+		}
+
 		// Extract `date` (from SK or date field)
 		if attr, ok := item["date"].(*types.AttributeValueMemberS); ok {
 			userVoca.Date = attr.Value
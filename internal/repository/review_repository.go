@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type reviewRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewReviewRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.ReviewRepository {
+	return &reviewRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#review，SK = 日期（YYYY-MM-DD），每晚的互動回顧與每個單字的回答
+func reviewSessionKey(userID string) string {
+	return userID + "#review"
+}
+
+// PK = userId#srs，SK = 正規化後的單字，記錄間隔複習的狀態
+func reviewRecordKey(userID string) string {
+	return userID + "#srs"
+}
+
+// SaveReviewSession 寫入整個互動回顧，每次回答後覆寫
+func (r *reviewRepository) SaveReviewSession(session models.ReviewSession) error {
+	item, err := attributevalue.MarshalMap(session)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal review session")
+		return fmt.Errorf("failed to marshal review session: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: reviewSessionKey(session.UserID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: session.Date}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save review session to DynamoDB")
+		return fmt.Errorf("failed to save review session: %w", err)
+	}
+
+	return nil
+}
+
+// GetReviewSession 取得指定日期的互動回顧，不存在時回傳 nil
+func (r *reviewRepository) GetReviewSession(userID, date string) (*models.ReviewSession, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: reviewSessionKey(userID)},
+			"sk": &types.AttributeValueMemberS{Value: date},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get review session from DynamoDB")
+		return nil, fmt.Errorf("failed to get review session: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var session models.ReviewSession
+	if err := attributevalue.UnmarshalMap(result.Item, &session); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal review session")
+		return nil, fmt.Errorf("failed to unmarshal review session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// GetReviewRecord 取得單字的間隔複習狀態，從未回顧過時回傳 nil
+func (r *reviewRepository) GetReviewRecord(userID, word string) (*models.ReviewRecord, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: reviewRecordKey(userID)},
+			"sk": &types.AttributeValueMemberS{Value: utils.NormalizeHistoryWord(word)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get review record from DynamoDB")
+		return nil, fmt.Errorf("failed to get review record: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var record models.ReviewRecord
+	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal review record")
+		return nil, fmt.Errorf("failed to unmarshal review record: %w", err)
+	}
+
+	return &record, nil
+}
+
+// GetReviewRecords 回傳用戶所有單字的間隔複習狀態，key 為正規化後的單字
+func (r *reviewRepository) GetReviewRecords(userID string) (map[string]models.ReviewRecord, error) {
+	records := map[string]models.ReviewRecord{}
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: reviewRecordKey(userID)},
+			},
+			ExclusiveStartKey: lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query review records from DynamoDB")
+			return nil, fmt.Errorf("failed to query review records: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var record models.ReviewRecord
+			if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal review record, skipping")
+				continue
+			}
+			records[record.Word] = record
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return records, nil
+}
+
+// SaveReviewRecord 覆寫單字的間隔複習狀態，record.Word 應為正規化後的單字
+func (r *reviewRepository) SaveReviewRecord(userID string, record models.ReviewRecord) error {
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal review record")
+		return fmt.Errorf("failed to marshal review record: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: reviewRecordKey(userID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: record.Word}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save review record to DynamoDB")
+		return fmt.Errorf("failed to save review record: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type scheduleAuditRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewScheduleAuditRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.ScheduleAuditRepository {
+	return &scheduleAuditRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+func (r *scheduleAuditRepository) RecordScheduleOperation(entry models.ScheduleAuditEntry) error {
+	if entry.Timestamp == "" {
+		entry.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal schedule audit entry")
+		return fmt.Errorf("failed to marshal schedule audit entry: %w", err)
+	}
+
+	// PK = userId, SK = timestamp#operation（同一時間的多個操作不會互相覆蓋）
+	item["pk"] = &types.AttributeValueMemberS{Value: entry.UserID}
+	item["sk"] = &types.AttributeValueMemberS{Value: entry.Timestamp + "#" + entry.Operation}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save schedule audit entry to DynamoDB")
+		return fmt.Errorf("failed to save schedule audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *scheduleAuditRepository) GetScheduleAuditLog(userID string, limit int) ([]models.ScheduleAuditEntry, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: userID},
+		},
+		ScanIndexForward: aws.Bool(false), // 最新的操作在前
+	}
+	if limit > 0 {
+		input.Limit = aws.Int32(int32(limit))
+	}
+
+	result, err := r.dynamodb.Query(context.Background(), input)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query schedule audit log from DynamoDB")
+		return nil, fmt.Errorf("failed to query schedule audit log: %w", err)
+	}
+
+	entries := []models.ScheduleAuditEntry{}
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &entries); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal schedule audit log")
+		return nil, fmt.Errorf("failed to unmarshal schedule audit log: %w", err)
+	}
+
+	return entries, nil
+}
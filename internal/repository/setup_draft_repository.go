@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type setupDraftRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewSetupDraftRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.SetupDraftRepository {
+	return &setupDraftRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#setupDraft，SK 固定為 "draft"
+func setupDraftKey(userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: userID + "#setupDraft"},
+		"sk": &types.AttributeValueMemberS{Value: "draft"},
+	}
+}
+
+// GetSetupDraft 讀取用戶進行中的推播設定，沒有時回傳 nil（TTL 刪除有延遲，是否過期由呼叫端判斷）
+func (r *setupDraftRepository) GetSetupDraft(userID string) (*models.SetupDraft, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       setupDraftKey(userID),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get setup draft from DynamoDB")
+		return nil, fmt.Errorf("failed to get setup draft: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var draft models.SetupDraft
+	if err := attributevalue.UnmarshalMap(result.Item, &draft); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal setup draft")
+		return nil, fmt.Errorf("failed to unmarshal setup draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// UpdateSetupDraft 只寫入 draft 中有值的欄位，每個步驟各自更新自己的選擇，不需要先讀取
+func (r *setupDraftRepository) UpdateSetupDraft(draft models.SetupDraft) error {
+	sets := []string{"userId = :userId", "expiresAt = :expiresAt"}
+	values := map[string]types.AttributeValue{
+		":userId":    &types.AttributeValueMemberS{Value: draft.UserID},
+		":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(draft.ExpiresAt, 10)},
+	}
+	if draft.Course != "" {
+		sets = append(sets, "course = :course")
+		values[":course"] = &types.AttributeValueMemberS{Value: draft.Course}
+	}
+	if draft.DailyWords != 0 {
+		sets = append(sets, "dailyWords = :dailyWords")
+		values[":dailyWords"] = &types.AttributeValueMemberN{Value: strconv.Itoa(draft.DailyWords)}
+	}
+	if draft.PushTime != "" {
+		sets = append(sets, "pushTime = :pushTime")
+		values[":pushTime"] = &types.AttributeValueMemberS{Value: draft.PushTime}
+	}
+
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(r.tableName),
+		Key:                       setupDraftKey(draft.UserID),
+		UpdateExpression:          aws.String("SET " + strings.Join(sets, ", ")),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to update setup draft in DynamoDB")
+		return fmt.Errorf("failed to update setup draft: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSetupDraft 推播設定完成後刪除暫存的選擇
+func (r *setupDraftRepository) DeleteSetupDraft(userID string) error {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       setupDraftKey(userID),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete setup draft from DynamoDB")
+		return fmt.Errorf("failed to delete setup draft: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// 待恭喜的里程碑全部放在同一個 PK 下，reminder Lambda 一次 Query 即可取得
+const pendingStreakMilestonesKey = "streakMilestone#pending"
+
+type streakRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewStreakRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.StreakRepository {
+	return &streakRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#streak，SK 固定為 "streak"
+func streakKey(userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: userID + "#streak"},
+		"sk": &types.AttributeValueMemberS{Value: "streak"},
+	}
+}
+
+// GetStreak 取得用戶的連續學習紀錄，從未學習過時回傳 nil
+func (r *streakRepository) GetStreak(userID string) (*models.Streak, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       streakKey(userID),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get streak from DynamoDB")
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var streak models.Streak
+	if err := attributevalue.UnmarshalMap(result.Item, &streak); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal streak")
+		return nil, fmt.Errorf("failed to unmarshal streak: %w", err)
+	}
+	return &streak, nil
+}
+
+func (r *streakRepository) SaveStreak(streak models.Streak) error {
+	streak.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	item, err := attributevalue.MarshalMap(streak)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal streak")
+		return fmt.Errorf("failed to marshal streak: %w", err)
+	}
+	for k, v := range streakKey(streak.UserID) {
+		item[k] = v
+	}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save streak to DynamoDB")
+		return fmt.Errorf("failed to save streak: %w", err)
+	}
+
+	return nil
+}
+
+// AddPendingMilestone 登記待恭喜的里程碑；同一用戶只保留最新一筆
+func (r *streakRepository) AddPendingMilestone(milestone models.StreakMilestone) error {
+	item, err := attributevalue.MarshalMap(milestone)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal streak milestone")
+		return fmt.Errorf("failed to marshal streak milestone: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: pendingStreakMilestonesKey}
+	item["sk"] = &types.AttributeValueMemberS{Value: milestone.UserID}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save streak milestone to DynamoDB")
+		return fmt.Errorf("failed to save streak milestone: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingMilestones 列出所有尚未推播恭喜訊息的里程碑
+func (r *streakRepository) GetPendingMilestones() ([]models.StreakMilestone, error) {
+	milestones := []models.StreakMilestone{}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: pendingStreakMilestonesKey},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query streak milestones from DynamoDB")
+			return nil, fmt.Errorf("failed to query streak milestones: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var milestone models.StreakMilestone
+			if err := attributevalue.UnmarshalMap(item, &milestone); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal streak milestone")
+				continue
+			}
+			milestones = append(milestones, milestone)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return milestones, nil
+}
+
+// DeletePendingMilestone 推播恭喜訊息後移除登記
+func (r *streakRepository) DeletePendingMilestone(userID string) error {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: pendingStreakMilestonesKey},
+			"sk": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete streak milestone from DynamoDB")
+		return fmt.Errorf("failed to delete streak milestone: %w", err)
+	}
+
+	return nil
+}
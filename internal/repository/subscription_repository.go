@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// 所有試用放在同一個 PK 下（SK = userId#feature），reminder Lambda 一次 Query 即可找出要到期的試用
+const trialsKey = "subscription#trial"
+
+type subscriptionRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewSubscriptionRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.SubscriptionRepository {
+	return &subscriptionRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+func trialKey(userID string, feature models.PremiumFeature) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: trialsKey},
+		"sk": &types.AttributeValueMemberS{Value: userID + "#" + string(feature)},
+	}
+}
+
+// StartTrial 建立試用，回傳是否建立成功；用戶試用過同一個功能時（不論是否已到期）回傳 false
+func (r *subscriptionRepository) StartTrial(trial models.Trial) (bool, error) {
+	item, err := attributevalue.MarshalMap(trial)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal trial")
+		return false, fmt.Errorf("failed to marshal trial: %w", err)
+	}
+	for name, value := range trialKey(trial.UserID, trial.Feature) {
+		item[name] = value
+	}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to save trial to DynamoDB")
+		return false, fmt.Errorf("failed to save trial: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetTrial 取得用戶對某個功能的試用，沒有試用過時回傳 nil
+func (r *subscriptionRepository) GetTrial(userID string, feature models.PremiumFeature) (*models.Trial, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       trialKey(userID, feature),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get trial from DynamoDB")
+		return nil, fmt.Errorf("failed to get trial: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var trial models.Trial
+	if err := attributevalue.UnmarshalMap(result.Item, &trial); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal trial")
+		return nil, fmt.Errorf("failed to unmarshal trial: %w", err)
+	}
+	return &trial, nil
+}
+
+// GetActiveTrials 列出所有尚未完成到期處理的試用
+func (r *subscriptionRepository) GetActiveTrials() ([]models.Trial, error) {
+	trials := []models.Trial{}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			FilterExpression:       aws.String("#status = :active"),
+			// status 是 DynamoDB 的保留字
+			ExpressionAttributeNames: map[string]string{"#status": "status"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":     &types.AttributeValueMemberS{Value: trialsKey},
+				":active": &types.AttributeValueMemberS{Value: string(models.TrialActive)},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query trials from DynamoDB")
+			return nil, fmt.Errorf("failed to query trials: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var trial models.Trial
+			if err := attributevalue.UnmarshalMap(item, &trial); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal trial")
+				continue
+			}
+			trials = append(trials, trial)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return trials, nil
+}
+
+// MarkTrialEndingNotified 記錄已推播即將到期的提醒，避免每天重複提醒
+func (r *subscriptionRepository) MarkTrialEndingNotified(userID string, feature models.PremiumFeature) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:        aws.String(r.tableName),
+		Key:              trialKey(userID, feature),
+		UpdateExpression: aws.String("SET endingNotified = :notified"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":notified": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to mark trial ending notified in DynamoDB")
+		return fmt.Errorf("failed to mark trial ending notified: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireTrial 只在試用仍是 active 時標為到期，回傳是否有更新；
+// 重複執行的排程只有第一次會成功，避免重複降級與通知
+func (r *subscriptionRepository) ExpireTrial(userID string, feature models.PremiumFeature, expiredAt string) (bool, error) {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                aws.String(r.tableName),
+		Key:                      trialKey(userID, feature),
+		UpdateExpression:         aws.String("SET #status = :expired, expiredAt = :expiredAt"),
+		ConditionExpression:      aws.String("#status = :active"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":active":    &types.AttributeValueMemberS{Value: string(models.TrialActive)},
+			":expired":   &types.AttributeValueMemberS{Value: string(models.TrialExpired)},
+			":expiredAt": &types.AttributeValueMemberS{Value: expiredAt},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to expire trial in DynamoDB")
+		return false, fmt.Errorf("failed to expire trial: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+// 同一個用戶同時只會有一張未處理的 ticket，全部放在同一個 PK 下方便 ops 列出
+const openSupportTicketsKey = "supportTicket#open"
+
+type supportTicketRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewSupportTicketRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.SupportTicketRepository {
+	return &supportTicketRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#failure，SK = occurredAt，方便查詢某段時間內的失敗次數
+func userFailureKey(userID string) string {
+	return userID + "#failure"
+}
+
+func (r *supportTicketRepository) RecordUserFailure(failure models.UserFailure) error {
+	if failure.OccurredAt == "" {
+		failure.OccurredAt = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	item, err := attributevalue.MarshalMap(failure)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal user failure")
+		return fmt.Errorf("failed to marshal user failure: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: userFailureKey(failure.UserID)}
+	item["sk"] = &types.AttributeValueMemberS{Value: failure.OccurredAt}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save user failure to DynamoDB")
+		return fmt.Errorf("failed to save user failure: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserFailuresSince 取得用戶在 since 之後的失敗紀錄，依發生時間排序
+func (r *supportTicketRepository) GetUserFailuresSince(userID string, since time.Time) ([]models.UserFailure, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk AND sk >= :since"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: userFailureKey(userID)},
+			":since": &types.AttributeValueMemberS{Value: since.UTC().Format(time.RFC3339Nano)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query user failures from DynamoDB")
+		return nil, fmt.Errorf("failed to query user failures: %w", err)
+	}
+
+	failures := []models.UserFailure{}
+	for _, item := range result.Items {
+		var failure models.UserFailure
+		if err := attributevalue.UnmarshalMap(item, &failure); err != nil {
+			r.logger.WithError(err).Warn("Failed to unmarshal user failure")
+			continue
+		}
+		failures = append(failures, failure)
+	}
+
+	return failures, nil
+}
+
+// OpenSupportTicket 開立 ticket；用戶已有未處理的 ticket 時不會覆寫，回傳是否有開立
+func (r *supportTicketRepository) OpenSupportTicket(ticket models.SupportTicket) (bool, error) {
+	item, err := attributevalue.MarshalMap(ticket)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal support ticket")
+		return false, fmt.Errorf("failed to marshal support ticket: %w", err)
+	}
+	item["pk"] = &types.AttributeValueMemberS{Value: openSupportTicketsKey}
+	item["sk"] = &types.AttributeValueMemberS{Value: ticket.UserID}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to save support ticket to DynamoDB")
+		return false, fmt.Errorf("failed to save support ticket: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetOpenSupportTickets 列出所有未處理的 ticket
+func (r *supportTicketRepository) GetOpenSupportTickets() ([]models.SupportTicket, error) {
+	tickets := []models.SupportTicket{}
+
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: openSupportTicketsKey},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query support tickets from DynamoDB")
+			return nil, fmt.Errorf("failed to query support tickets: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var ticket models.SupportTicket
+			if err := attributevalue.UnmarshalMap(item, &ticket); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal support ticket")
+				continue
+			}
+			tickets = append(tickets, ticket)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return tickets, nil
+}
+
+// ResolveSupportTicket 結案並移除 ticket，回傳 ticket 是否存在
+func (r *supportTicketRepository) ResolveSupportTicket(userID string) (bool, error) {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: openSupportTicketsKey},
+			"sk": &types.AttributeValueMemberS{Value: userID},
+		},
+		ConditionExpression: aws.String("attribute_exists(pk)"),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to delete support ticket from DynamoDB")
+		return false, fmt.Errorf("failed to delete support ticket: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type translationCacheRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewTranslationCacheRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.TranslationCacheRepository {
+	return &translationCacheRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = translationCache#<utils.TranslationCacheText 的 key>，SK = variant，同一個輸入的所有 variant 可以一次清除
+func translationCachePK(text string) string {
+	return "translationCache#" + text
+}
+
+func translationCacheKey(text, variant string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: translationCachePK(text)},
+		"sk": &types.AttributeValueMemberS{Value: variant},
+	}
+}
+
+// GetCachedTranslation 讀取快取的翻譯，不存在時回傳 nil（TTL 刪除有延遲，是否過期由呼叫端判斷）
+func (r *translationCacheRepository) GetCachedTranslation(text, variant string) (*models.TranslationCacheEntry, error) {
+	result, err := r.dynamodb.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       translationCacheKey(text, variant),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to get cached translation from DynamoDB")
+		return nil, fmt.Errorf("failed to get cached translation: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var entry models.TranslationCacheEntry
+	if err := attributevalue.UnmarshalMap(result.Item, &entry); err != nil {
+		r.logger.WithError(err).Error("Failed to unmarshal cached translation")
+		return nil, fmt.Errorf("failed to unmarshal cached translation: %w", err)
+	}
+	return &entry, nil
+}
+
+// PutCachedTranslation 寫入（或覆蓋）快取的翻譯
+func (r *translationCacheRepository) PutCachedTranslation(entry models.TranslationCacheEntry) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal cached translation")
+		return fmt.Errorf("failed to marshal cached translation: %w", err)
+	}
+	for k, v := range translationCacheKey(entry.Text, entry.Variant) {
+		item[k] = v
+	}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save cached translation to DynamoDB")
+		return fmt.Errorf("failed to save cached translation: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCachedTranslations 清除輸入文字所有 variant 的快取，例如翻譯被用戶回報時
+func (r *translationCacheRepository) DeleteCachedTranslations(text string) error {
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: translationCachePK(text)},
+			},
+			ProjectionExpression: aws.String("pk, sk"),
+			ExclusiveStartKey:    startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query cached translations from DynamoDB")
+			return fmt.Errorf("failed to query cached translations: %w", err)
+		}
+
+		for _, item := range result.Items {
+			_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+				TableName: aws.String(r.tableName),
+				Key:       map[string]types.AttributeValue{"pk": item["pk"], "sk": item["sk"]},
+			})
+			if err != nil {
+				r.logger.WithError(err).Error("Failed to delete cached translation from DynamoDB")
+				return fmt.Errorf("failed to delete cached translation: %w", err)
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type usageRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewUsageRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.UsageRepository {
+	return &usageRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#usage，SK = <feature>#<date>，每個功能每天一筆計數，過期後由 TTL 刪除
+func usageKey(userID string) string {
+	return userID + "#usage"
+}
+
+// IncrementDailyUsage 將用戶某功能在 date（用戶時區的日期）的使用次數加一，回傳累加後的次數
+func (r *usageRepository) IncrementDailyUsage(userID, feature, date string, expiresAt time.Time) (int, error) {
+	result, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: usageKey(userID)},
+			"sk": &types.AttributeValueMemberS{Value: feature + "#" + date},
+		},
+		UpdateExpression: aws.String("ADD #count :one SET expiresAt = :expiresAt"),
+		// count 是 DynamoDB 的保留字
+		ExpressionAttributeNames: map[string]string{"#count": "count"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":       &types.AttributeValueMemberN{Value: "1"},
+			":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to increment daily usage in DynamoDB")
+		return 0, fmt.Errorf("failed to increment daily usage: %w", err)
+	}
+
+	count := 0
+	if attr, ok := result.Attributes["count"].(*types.AttributeValueMemberN); ok {
+		count, _ = strconv.Atoi(attr.Value)
+	}
+	return count, nil
+}
+
+// token 用量以日期為 PK（usage#tokens#<date>，SK = <userId>#<feature>），報表一次 Query 即可取得當天所有用戶
+func tokenUsageKey(date string) string {
+	return "usage#tokens#" + date
+}
+
+// AddTokenUsage 將一次 OpenAI 呼叫的 token 數與估算費用累加到用戶當天該功能的用量
+func (r *usageRepository) AddTokenUsage(usage models.TokenUsage, expiresAt time.Time) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: tokenUsageKey(usage.Date)},
+			"sk": &types.AttributeValueMemberS{Value: usage.UserID + "#" + usage.Feature},
+		},
+		UpdateExpression: aws.String("ADD requests :requests, promptTokens :prompt, completionTokens :completion, costUsd :cost " +
+			"SET #date = :date, userId = :userId, feature = :feature, expiresAt = :expiresAt"),
+		// date 是 DynamoDB 的保留字
+		ExpressionAttributeNames: map[string]string{"#date": "date"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":requests":   &types.AttributeValueMemberN{Value: strconv.Itoa(usage.Requests)},
+			":prompt":     &types.AttributeValueMemberN{Value: strconv.Itoa(usage.PromptTokens)},
+			":completion": &types.AttributeValueMemberN{Value: strconv.Itoa(usage.CompletionTokens)},
+			":cost":       &types.AttributeValueMemberN{Value: strconv.FormatFloat(usage.CostUSD, 'f', -1, 64)},
+			":date":       &types.AttributeValueMemberS{Value: usage.Date},
+			":userId":     &types.AttributeValueMemberS{Value: usage.UserID},
+			":feature":    &types.AttributeValueMemberS{Value: usage.Feature},
+			":expiresAt":  &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to add token usage in DynamoDB")
+		return fmt.Errorf("failed to add token usage: %w", err)
+	}
+	return nil
+}
+
+// GetTokenUsage 取得 date（UTC 日期）所有用戶各功能的 token 用量
+func (r *usageRepository) GetTokenUsage(date string) ([]models.TokenUsage, error) {
+	var usages []models.TokenUsage
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: tokenUsageKey(date)},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query token usage from DynamoDB")
+			return nil, fmt.Errorf("failed to query token usage: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var usage models.TokenUsage
+			if err := attributevalue.UnmarshalMap(item, &usage); err != nil {
+				r.logger.WithError(err).Warn("Failed to unmarshal token usage")
+				continue
+			}
+			usages = append(usages, usage)
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return usages, nil
+}
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"language-assistant/internal/models"
 	"language-assistant/internal/utils"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -31,34 +34,54 @@ func NewUserConfigRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, t
 func (r *userConfigRepository) SaveUserConfig(userID, displayName, course string, level int, dailyWords int, pushTime, timezone string) error {
 	timestamp := time.Now().UTC().Format(time.RFC3339)
 
-	item := map[string]types.AttributeValue{
-		"userId":    &types.AttributeValueMemberS{Value: userID},
-		"updatedAt": &types.AttributeValueMemberS{Value: timestamp},
+	// 只在有值時才設定欄位，沒有值的欄位則移除
+	// 使用 UpdateItem 而非 PutItem，避免覆蓋掉其他功能寫入的欄位（例如 scheduleName）
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"displayName", displayName},
+		{"course", course},
+		{"level", formatNonZero(level)},
+		{"dailyWords", formatNonZero(dailyWords)},
+		{"pushTime", pushTime},
+		{"timezone", timezone},
 	}
 
-	// 只在有值時才設定欄位
-	if displayName != "" {
-		item["displayName"] = &types.AttributeValueMemberS{Value: displayName}
+	setClauses := []string{"#updatedAt = :updatedAt"}
+	var removeClauses []string
+	names := map[string]string{"#updatedAt": "updatedAt"}
+	values := map[string]types.AttributeValue{
+		":updatedAt": &types.AttributeValueMemberS{Value: timestamp},
 	}
-	if course != "" {
-		item["course"] = &types.AttributeValueMemberS{Value: course}
-	}
-	if level != 0 {
-		item["level"] = &types.AttributeValueMemberS{Value: fmt.Sprintf("%d", level)}
-	}
-	if dailyWords != 0 {
-		item["dailyWords"] = &types.AttributeValueMemberS{Value: fmt.Sprintf("%d", dailyWords)}
+	for _, field := range fields {
+		names["#"+field.name] = field.name
+		if field.value == "" {
+			removeClauses = append(removeClauses, "#"+field.name)
+			continue
+		}
+		setClauses = append(setClauses, fmt.Sprintf("#%s = :%s", field.name, field.name))
+		values[":"+field.name] = &types.AttributeValueMemberS{Value: field.value}
 	}
-	if pushTime != "" {
-		item["pushTime"] = &types.AttributeValueMemberS{Value: pushTime}
+
+	// 選擇課程代表要開始每日單字推播，不再是只用翻譯的用戶
+	if course != "" {
+		removeClauses = append(removeClauses, "translationOnly")
 	}
-	if timezone != "" {
-		item["timezone"] = &types.AttributeValueMemberS{Value: timezone}
+
+	updateExpression := "SET " + strings.Join(setClauses, ", ")
+	if len(removeClauses) > 0 {
+		updateExpression += " REMOVE " + strings.Join(removeClauses, ", ")
 	}
 
-	_, err := r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
 		TableName: aws.String(r.tableName),
-		Item:      item,
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
 	})
 
 	if err != nil {
@@ -96,112 +119,1033 @@ func (r *userConfigRepository) GetUserConfig(userID string) (*models.UserConfig,
 		return nil, nil
 	}
 
-	var userConfig models.UserConfig
+	userConfig := parseUserConfig(result.Item)
 	userConfig.UserID = userID
 
-	// Extract displayName
-	if attr, ok := result.Item["displayName"].(*types.AttributeValueMemberS); ok {
-		userConfig.DisplayName = attr.Value
+	return userConfig, nil
+}
+
+// SetScheduleName 記錄用戶的排程名稱，供排程名稱反查用戶使用；空字串表示用戶已沒有個人排程（fan-out 模式）
+func (r *userConfigRepository) SetScheduleName(userID, scheduleName string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		// 有個人排程的用戶不再由 dispatcher 推播
+		UpdateExpression: aws.String("SET scheduleName = :scheduleName REMOVE fanoutPush"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":scheduleName": &types.AttributeValueMemberS{Value: scheduleName},
+		},
+	}
+	if scheduleName == "" {
+		// GSI 的 key 不能是空字串，移除屬性讓用戶離開 ScheduleNameIndex
+		input.UpdateExpression = aws.String("REMOVE scheduleName")
+		input.ExpressionAttributeValues = nil
 	}
+	_, err := r.dynamodb.UpdateItem(context.Background(), input)
 
-	// Extract course
-	if attr, ok := result.Item["course"].(*types.AttributeValueMemberS); ok {
-		userConfig.Course = attr.Value
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save schedule name to DynamoDB")
+		return fmt.Errorf("failed to save schedule name: %w", err)
 	}
 
-	// Extract level
-	if attr, ok := result.Item["level"].(*types.AttributeValueMemberS); ok {
-		level, err := strconv.Atoi(attr.Value)
-		if err == nil {
-			userConfig.Level = level
-		}
+	return nil
+}
+
+// SetFanoutPush 在個人排程刪除後標記用戶改由 language-dispatcher 推播，並移除 scheduleName。
+// dispatcher 只處理有標記的用戶：scheduleName 欄位上線前建立排程的用戶沒有 scheduleName，但個人排程仍存在
+func (r *userConfigRepository) SetFanoutPush(userID string) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET fanoutPush = :fanoutPush REMOVE scheduleName"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":fanoutPush": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save fan-out push to DynamoDB")
+		return fmt.Errorf("failed to save fan-out push: %w", err)
 	}
 
-	// Extract dailyWords
-	if attr, ok := result.Item["dailyWords"].(*types.AttributeValueMemberS); ok {
-		dailyWords, err := strconv.Atoi(attr.Value)
-		if err == nil {
-			userConfig.DailyWords = dailyWords
-		}
-	} else {
-		userConfig.DailyWords = 10 // 預設值
+	return nil
+}
+
+// GetUserConfigByScheduleName 透過排程名稱反查用戶設定
+func (r *userConfigRepository) GetUserConfigByScheduleName(scheduleName string) (*models.UserConfig, error) {
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		IndexName:              aws.String("ScheduleNameIndex"), // GSI 名稱
+		KeyConditionExpression: aws.String("scheduleName = :scheduleName"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":scheduleName": &types.AttributeValueMemberS{Value: scheduleName},
+		},
+		Limit: aws.Int32(1),
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query user by schedule name from DynamoDB")
+		return nil, fmt.Errorf("failed to query user by schedule name: %w", err)
 	}
 
-	// Extract pushTime
-	if attr, ok := result.Item["pushTime"].(*types.AttributeValueMemberS); ok {
-		userConfig.PushTime = attr.Value
-	} else {
-		userConfig.PushTime = "08:00" // 預設值
+	if len(result.Items) == 0 {
+		return nil, nil
 	}
 
-	// Extract timezone
-	if attr, ok := result.Item["timezone"].(*types.AttributeValueMemberS); ok {
-		userConfig.Timezone = attr.Value
-	} else {
-		userConfig.Timezone = "Asia/Taipei" // 預設值
+	return parseUserConfig(result.Items[0]), nil
+}
+
+// SetProfileSlug 開啟或關閉公開個人頁面，profileSlug 為空字串時移除欄位（同時從 GSI 移除）
+func (r *userConfigRepository) SetProfileSlug(userID, profileSlug string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("REMOVE profileSlug"),
+	}
+	if profileSlug != "" {
+		input.UpdateExpression = aws.String("SET profileSlug = :profileSlug")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":profileSlug": &types.AttributeValueMemberS{Value: profileSlug},
+		}
 	}
 
-	// Extract updatedAt
-	if attr, ok := result.Item["updatedAt"].(*types.AttributeValueMemberS); ok {
-		userConfig.UpdatedAt = attr.Value
+	if _, err := r.dynamodb.UpdateItem(context.Background(), input); err != nil {
+		r.logger.WithError(err).Error("Failed to save profile slug to DynamoDB")
+		return fmt.Errorf("failed to save profile slug: %w", err)
 	}
 
-	return &userConfig, nil
+	return nil
 }
 
-func (r *userConfigRepository) GetUsersByCourse(course string) ([]models.UserConfig, error) {
+// GetUserConfigByProfileSlug 透過公開頁面代碼反查用戶設定
+func (r *userConfigRepository) GetUserConfigByProfileSlug(profileSlug string) (*models.UserConfig, error) {
 	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
-		IndexName:              aws.String("CourseIndex"), // GSI 名稱
-		KeyConditionExpression: aws.String("course = :course"),
+		IndexName:              aws.String("ProfileSlugIndex"), // GSI 名稱
+		KeyConditionExpression: aws.String("profileSlug = :profileSlug"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":course": &types.AttributeValueMemberS{Value: course},
+			":profileSlug": &types.AttributeValueMemberS{Value: profileSlug},
 		},
+		Limit: aws.Int32(1),
 	})
 
 	if err != nil {
-		r.logger.WithError(err).Error("Failed to query users by course from DynamoDB")
-		return nil, fmt.Errorf("failed to query users by course: %w", err)
+		r.logger.WithError(err).Error("Failed to query user by profile slug from DynamoDB")
+		return nil, fmt.Errorf("failed to query user by profile slug: %w", err)
 	}
 
-	if result.Items == nil {
-		return []models.UserConfig{}, nil
+	if len(result.Items) == 0 {
+		return nil, nil
 	}
 
-	var userConfigs []models.UserConfig
-	for _, item := range result.Items {
-		var userConfig models.UserConfig
+	return parseUserConfig(result.Items[0]), nil
+}
 
-		// Extract userId
-		if attr, ok := item["userId"].(*types.AttributeValueMemberS); ok {
-			userConfig.UserID = attr.Value
-		}
+// SetCardFormat 設定每日單字的呈現方式（文字或圖片字卡）
+func (r *userConfigRepository) SetCardFormat(userID string, cardFormat models.CardFormat) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET cardFormat = :cardFormat"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cardFormat": &types.AttributeValueMemberS{Value: string(cardFormat)},
+		},
+	})
 
-		// Extract course
-		if attr, ok := item["course"].(*types.AttributeValueMemberS); ok {
-			userConfig.Course = attr.Value
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save card format to DynamoDB")
+		return fmt.Errorf("failed to save card format: %w", err)
+	}
+
+	return nil
+}
+
+// SetPronunciationAudio 設定每日單字推播是否附上發音音檔
+func (r *userConfigRepository) SetPronunciationAudio(userID string, enabled bool) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET pronunciationAudio = :pronunciationAudio"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pronunciationAudio": &types.AttributeValueMemberBOOL{Value: enabled},
+		},
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save pronunciation audio setting to DynamoDB")
+		return fmt.Errorf("failed to save pronunciation audio setting: %w", err)
+	}
+
+	return nil
+}
+
+// SetGrammarMode 設定輸入完整英文句子時是否改為文法修正
+func (r *userConfigRepository) SetGrammarMode(userID string, enabled bool) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET grammarMode = :grammarMode"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":grammarMode": &types.AttributeValueMemberBOOL{Value: enabled},
+		},
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save grammar mode setting to DynamoDB")
+		return fmt.Errorf("failed to save grammar mode setting: %w", err)
+	}
+
+	return nil
+}
+
+// SetInteractiveReview 設定每晚回顧是否改為逐字回答的互動回顧
+func (r *userConfigRepository) SetInteractiveReview(userID string, enabled bool) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET interactiveReview = :interactiveReview"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":interactiveReview": &types.AttributeValueMemberBOOL{Value: enabled},
+		},
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save interactive review setting to DynamoDB")
+		return fmt.Errorf("failed to save interactive review setting: %w", err)
+	}
+
+	return nil
+}
+
+// SetTranslationOnly 設定用戶是否只使用翻譯功能
+func (r *userConfigRepository) SetTranslationOnly(userID string, enabled bool) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET translationOnly = :translationOnly"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":translationOnly": &types.AttributeValueMemberBOOL{Value: enabled},
+		},
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save translation only setting to DynamoDB")
+		return fmt.Errorf("failed to save translation only setting: %w", err)
+	}
+
+	return nil
+}
+
+// SetModel 設定翻譯與單字生成使用的 OpenAI 模型，model 為空字串時移除欄位（恢復預設模型）
+func (r *userConfigRepository) SetModel(userID, model string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("REMOVE model"),
+	}
+	if model != "" {
+		input.UpdateExpression = aws.String("SET model = :model")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":model": &types.AttributeValueMemberS{Value: model},
 		}
+	}
 
-		// Extract level
-		if attr, ok := item["level"].(*types.AttributeValueMemberS); ok {
-			level, err := strconv.Atoi(attr.Value)
-			if err == nil {
-				userConfig.Level = level
-			}
+	_, err := r.dynamodb.UpdateItem(context.Background(), input)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save model to DynamoDB")
+		return fmt.Errorf("failed to save model: %w", err)
+	}
+
+	return nil
+}
+
+// SetLineDestination 記錄用戶加入的官方帳號，destination 為空字串時移除欄位（使用預設 channel）
+func (r *userConfigRepository) SetLineDestination(userID, destination string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("REMOVE lineDestination"),
+	}
+	if destination != "" {
+		input.UpdateExpression = aws.String("SET lineDestination = :lineDestination")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":lineDestination": &types.AttributeValueMemberS{Value: destination},
 		}
+	}
 
-		// Extract updatedAt
-		if attr, ok := item["updatedAt"].(*types.AttributeValueMemberS); ok {
-			userConfig.UpdatedAt = attr.Value
+	_, err := r.dynamodb.UpdateItem(context.Background(), input)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save line destination to DynamoDB")
+		return fmt.Errorf("failed to save line destination: %w", err)
+	}
+
+	return nil
+}
+
+// SetReviewSource 設定每晚回顧與測驗使用的單字來源，source 為空字串時移除欄位（使用所有單字）
+func (r *userConfigRepository) SetReviewSource(userID, source string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("REMOVE reviewSource"),
+	}
+	if source != "" {
+		input.UpdateExpression = aws.String("SET reviewSource = :reviewSource")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":reviewSource": &types.AttributeValueMemberS{Value: source},
 		}
+	}
 
-		userConfigs = append(userConfigs, userConfig)
+	_, err := r.dynamodb.UpdateItem(context.Background(), input)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save review source to DynamoDB")
+		return fmt.Errorf("failed to save review source: %w", err)
 	}
 
-	r.logger.WithFields(logrus.Fields{
-		"course": course,
-		"count":  len(userConfigs),
-	}).Info("Successfully retrieved users by course")
+	return nil
+}
 
-	return userConfigs, nil
+// SetDifficultyOffset 設定每日單字難度的調整，0 表示依分數決定
+func (r *userConfigRepository) SetDifficultyOffset(userID string, offset int) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET difficultyOffset = :difficultyOffset"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":difficultyOffset": &types.AttributeValueMemberN{Value: strconv.Itoa(offset)},
+		},
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save difficulty offset to DynamoDB")
+		return fmt.Errorf("failed to save difficulty offset: %w", err)
+	}
+
+	return nil
+}
+
+// SetLowerBandWords 設定程度提升後較低級距舊單字的處理方式
+func (r *userConfigRepository) SetLowerBandWords(userID, policy string) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET lowerBandWords = :lowerBandWords"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":lowerBandWords": &types.AttributeValueMemberS{Value: policy},
+		},
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save lower band words policy to DynamoDB")
+		return fmt.Errorf("failed to save lower band words policy: %w", err)
+	}
+
+	return nil
+}
+
+// DeactivateUser 用戶取消追蹤時標記為停用，並移除已刪除的排程名稱；用戶沒有設定紀錄時回傳 utils.ErrUserNotFound
+func (r *userConfigRepository) DeactivateUser(userID string, at time.Time) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:    aws.String("SET deactivatedAt = :deactivatedAt REMOVE scheduleName"),
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":deactivatedAt": &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return utils.ErrUserNotFound
+		}
+		r.logger.WithError(err).Error("Failed to deactivate user in DynamoDB")
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	return nil
+}
+
+// RecordBlockedPush 累加連續因用戶封鎖而失敗的推播次數，回傳累加後的次數
+func (r *userConfigRepository) RecordBlockedPush(userID string) (int, error) {
+	result, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("ADD blockedPushes :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to record blocked push in DynamoDB")
+		return 0, fmt.Errorf("failed to record blocked push: %w", err)
+	}
+
+	count := 0
+	if attr, ok := result.Attributes["blockedPushes"].(*types.AttributeValueMemberN); ok {
+		count, _ = strconv.Atoi(attr.Value)
+	}
+	return count, nil
+}
+
+// ResetBlockedPushes 推播成功後清除封鎖失敗次數
+func (r *userConfigRepository) ResetBlockedPushes(userID string) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("REMOVE blockedPushes"),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to reset blocked pushes in DynamoDB")
+		return fmt.Errorf("failed to reset blocked pushes: %w", err)
+	}
+
+	return nil
+}
+
+// ReactivateUser 用戶重新追蹤時清除停用標記與封鎖失敗次數
+func (r *userConfigRepository) ReactivateUser(userID string) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("REMOVE deactivatedAt, blockedPushes"),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to reactivate user in DynamoDB")
+		return fmt.Errorf("failed to reactivate user: %w", err)
+	}
+
+	return nil
+}
+
+// SetWebhookCapture 標記用戶在 until 之前擷取 webhook payload 供重播除錯，until 為零值時取消標記
+func (r *userConfigRepository) SetWebhookCapture(userID string, until time.Time) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:    aws.String("REMOVE webhookCaptureUntil"),
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+	}
+	if !until.IsZero() {
+		input.UpdateExpression = aws.String("SET webhookCaptureUntil = :until")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":until": &types.AttributeValueMemberS{Value: until.UTC().Format(time.RFC3339)},
+		}
+	}
+
+	if _, err := r.dynamodb.UpdateItem(context.Background(), input); err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return utils.ErrUserNotFound
+		}
+		r.logger.WithError(err).Error("Failed to save webhook capture flag to DynamoDB")
+		return fmt.Errorf("failed to save webhook capture flag: %w", err)
+	}
+
+	return nil
+}
+
+// RequestBeta 將用戶加入 Beta 測試審核佇列；已核准的用戶不會被改回 pending，回傳是否有寫入
+func (r *userConfigRepository) RequestBeta(userID string) (bool, error) {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:    aws.String("SET betaStatus = :pending, betaRequestedAt = :now"),
+		ConditionExpression: aws.String("attribute_not_exists(betaStatus) OR betaStatus = :rejected"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pending":  &types.AttributeValueMemberS{Value: string(models.BetaStatusPending)},
+			":rejected": &types.AttributeValueMemberS{Value: string(models.BetaStatusRejected)},
+			":now":      &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to save beta request to DynamoDB")
+		return false, fmt.Errorf("failed to save beta request: %w", err)
+	}
+
+	return true, nil
+}
+
+// SetBetaStatus 由管理者核准或拒絕 Beta 測試申請
+func (r *userConfigRepository) SetBetaStatus(userID string, status models.BetaStatus) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:    aws.String("SET betaStatus = :status"),
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: string(status)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return utils.ErrUserNotFound
+		}
+		r.logger.WithError(err).Error("Failed to save beta status to DynamoDB")
+		return fmt.Errorf("failed to save beta status: %w", err)
+	}
+
+	return nil
+}
+
+// SetPlan 由管理者（或付款完成後）設定用戶的方案
+func (r *userConfigRepository) SetPlan(userID string, plan models.Plan) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:    aws.String("SET #plan = :plan"),
+		ConditionExpression: aws.String("attribute_exists(userId)"),
+		// plan 是 DynamoDB 的保留字
+		ExpressionAttributeNames: map[string]string{"#plan": "plan"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":plan": &types.AttributeValueMemberS{Value: string(plan)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return utils.ErrUserNotFound
+		}
+		r.logger.WithError(err).Error("Failed to save plan to DynamoDB")
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsersByBetaStatus 依申請狀態列出用戶（透過 BetaStatusIndex），申請時間較早的在前
+func (r *userConfigRepository) GetUsersByBetaStatus(status models.BetaStatus) ([]models.UserConfig, error) {
+	userConfigs := []models.UserConfig{}
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			IndexName:              aws.String("BetaStatusIndex"), // GSI 名稱
+			KeyConditionExpression: aws.String("betaStatus = :status"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":status": &types.AttributeValueMemberS{Value: string(status)},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query users by beta status from DynamoDB")
+			return nil, fmt.Errorf("failed to query users by beta status: %w", err)
+		}
+
+		for _, item := range result.Items {
+			userConfigs = append(userConfigs, *parseUserConfig(item))
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+	sort.Slice(userConfigs, func(i, j int) bool { return userConfigs[i].BetaRequestedAt < userConfigs[j].BetaRequestedAt })
+
+	return userConfigs, nil
+}
+
+// AcceptTerms 記錄用戶同意的服務條款版本與同意時間
+func (r *userConfigRepository) AcceptTerms(userID, version string, at time.Time) error {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET termsVersion = :version, termsAcceptedAt = :acceptedAt"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version":    &types.AttributeValueMemberS{Value: version},
+			":acceptedAt": &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save terms acceptance to DynamoDB")
+		return fmt.Errorf("failed to save terms acceptance: %w", err)
+	}
+
+	return nil
+}
+
+// MarkTermsPrompted 記錄已對用戶提示過 version 的服務條款；已經提示過（包含同時處理的其他事件）時回傳 false，
+// 讓每個版本只提示一次
+func (r *userConfigRepository) MarkTermsPrompted(userID, version string) (bool, error) {
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:    aws.String("SET termsPrompted = :version"),
+		ConditionExpression: aws.String("attribute_exists(userId) AND (attribute_not_exists(termsPrompted) OR termsPrompted <> :version)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberS{Value: version},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to mark terms prompted in DynamoDB")
+		return false, fmt.Errorf("failed to mark terms prompted: %w", err)
+	}
+
+	return true, nil
+}
+
+// consentAttributes 各同意項目在用戶資料表中的屬性名稱
+var consentAttributes = map[models.ConsentType]string{
+	models.ConsentAnalytics: "consentAnalytics",
+	models.ConsentMarketing: "consentMarketing",
+	models.ConsentAIData:    "consentAiData",
+}
+
+// SetConsents 更新用戶的隱私設定，只修改 consents 中列出的項目，並記錄選擇的時間
+func (r *userConfigRepository) SetConsents(userID string, consents map[models.ConsentType]bool, at time.Time) error {
+	setClauses := []string{"consentUpdatedAt = :consentUpdatedAt"}
+	values := map[string]types.AttributeValue{
+		":consentUpdatedAt": &types.AttributeValueMemberS{Value: at.UTC().Format(time.RFC3339)},
+	}
+	for _, consent := range models.ConsentTypes {
+		granted, ok := consents[consent]
+		if !ok {
+			continue
+		}
+		attribute := consentAttributes[consent]
+		setClauses = append(setClauses, fmt.Sprintf("%s = :%s", attribute, attribute))
+		values[":"+attribute] = &types.AttributeValueMemberBOOL{Value: granted}
+	}
+
+	_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(setClauses, ", ")),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to save consents to DynamoDB")
+		return fmt.Errorf("failed to save consents: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsersWithConsent 列出同意某個項目的用戶。沒有對應的 GSI，以 Scan 加上篩選條件讀取整張表，
+// 只用於管理後台發送公告等低頻操作
+func (r *userConfigRepository) GetUsersWithConsent(consent models.ConsentType) ([]models.UserConfig, error) {
+	attribute, ok := consentAttributes[consent]
+	if !ok {
+		return nil, fmt.Errorf("unknown consent type %q", consent)
+	}
+
+	userConfigs := []models.UserConfig{}
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Scan(context.Background(), &dynamodb.ScanInput{
+			TableName:        aws.String(r.tableName),
+			FilterExpression: aws.String(attribute + " = :granted"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":granted": &types.AttributeValueMemberBOOL{Value: true},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to scan users by consent from DynamoDB")
+			return nil, fmt.Errorf("failed to scan users by consent: %w", err)
+		}
+
+		for _, item := range result.Items {
+			userConfigs = append(userConfigs, *parseUserConfig(item))
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return userConfigs, nil
+}
+
+// GetUsersByPushTime 列出推播時間為 pushTime（"HH:MM"）的用戶（透過 PushTimeIndex），供 fan-out 模式的 dispatcher 使用
+func (r *userConfigRepository) GetUsersByPushTime(pushTime string) ([]models.UserConfig, error) {
+	userConfigs := []models.UserConfig{}
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			IndexName:              aws.String("PushTimeIndex"), // GSI 名稱
+			KeyConditionExpression: aws.String("pushTime = :pushTime"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pushTime": &types.AttributeValueMemberS{Value: pushTime},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query users by push time from DynamoDB")
+			return nil, fmt.Errorf("failed to query users by push time: %w", err)
+		}
+
+		for _, item := range result.Items {
+			userConfigs = append(userConfigs, *parseUserConfig(item))
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return userConfigs, nil
+}
+
+// TouchLastActive 更新用戶最後互動時間，第一次互動時同時寫入 firstActiveAt，回傳 firstActiveAt
+func (r *userConfigRepository) TouchLastActive(userID string, at time.Time) (string, error) {
+	now := at.UTC().Format(time.RFC3339)
+
+	result, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET lastActiveAt = :now, firstActiveAt = if_not_exists(firstActiveAt, :now)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberS{Value: now},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to update last active time in DynamoDB")
+		return "", fmt.Errorf("failed to update last active time: %w", err)
+	}
+
+	if attr, ok := result.Attributes["firstActiveAt"].(*types.AttributeValueMemberS); ok {
+		return attr.Value, nil
+	}
+	return now, nil
+}
+
+func (r *userConfigRepository) GetUsersByCourse(course string) ([]models.UserConfig, error) {
+	userConfigs := []models.UserConfig{}
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			IndexName:              aws.String("CourseIndex"), // GSI 名稱
+			KeyConditionExpression: aws.String("course = :course"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":course": &types.AttributeValueMemberS{Value: course},
+			},
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query users by course from DynamoDB")
+			return nil, fmt.Errorf("failed to query users by course: %w", err)
+		}
+
+		userConfigs = append(userConfigs, parseCourseUsers(result.Items)...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"course": course,
+		"count":  len(userConfigs),
+	}).Info("Successfully retrieved users by course")
+
+	return userConfigs, nil
+}
+
+// parseCourseUsers 取出 CourseIndex 查詢結果中依課程列出用戶所需的欄位
+func parseCourseUsers(items []map[string]types.AttributeValue) []models.UserConfig {
+	var userConfigs []models.UserConfig
+	for _, item := range items {
+		var userConfig models.UserConfig
+
+		// Extract userId
+		if attr, ok := item["userId"].(*types.AttributeValueMemberS); ok {
+			userConfig.UserID = attr.Value
+		}
+
+		// Extract course
+		if attr, ok := item["course"].(*types.AttributeValueMemberS); ok {
+			userConfig.Course = attr.Value
+		}
+
+		// Extract level
+		if attr, ok := item["level"].(*types.AttributeValueMemberS); ok {
+			level, err := strconv.Atoi(attr.Value)
+			if err == nil {
+				userConfig.Level = level
+			}
+		}
+
+		// Extract updatedAt
+		if attr, ok := item["updatedAt"].(*types.AttributeValueMemberS); ok {
+			userConfig.UpdatedAt = attr.Value
+		}
+
+		// Extract deactivatedAt
+		if attr, ok := item["deactivatedAt"].(*types.AttributeValueMemberS); ok {
+			userConfig.DeactivatedAt = attr.Value
+		}
+
+		// Extract consents（課程公告只發給同意接收公告的用戶）
+		parseConsents(item, &userConfig)
+
+		userConfigs = append(userConfigs, userConfig)
+	}
+	return userConfigs
+}
+
+// parseConsents 取出隱私設定的各個同意項目與選擇時間
+func parseConsents(item map[string]types.AttributeValue, userConfig *models.UserConfig) {
+	if attr, ok := item[consentAttributes[models.ConsentAnalytics]].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.ConsentAnalytics = attr.Value
+	}
+	if attr, ok := item[consentAttributes[models.ConsentMarketing]].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.ConsentMarketing = attr.Value
+	}
+	if attr, ok := item[consentAttributes[models.ConsentAIData]].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.ConsentAIData = attr.Value
+	}
+	if attr, ok := item["consentUpdatedAt"].(*types.AttributeValueMemberS); ok {
+		userConfig.ConsentUpdatedAt = attr.Value
+	}
+}
+
+// parseUserConfig 將 DynamoDB item 轉換為 UserConfig，缺少的推播設定會補上預設值
+func parseUserConfig(item map[string]types.AttributeValue) *models.UserConfig {
+	var userConfig models.UserConfig
+
+	// Extract userId
+	if attr, ok := item["userId"].(*types.AttributeValueMemberS); ok {
+		userConfig.UserID = attr.Value
+	}
+
+	// Extract displayName
+	if attr, ok := item["displayName"].(*types.AttributeValueMemberS); ok {
+		userConfig.DisplayName = attr.Value
+	}
+
+	// Extract course
+	if attr, ok := item["course"].(*types.AttributeValueMemberS); ok {
+		userConfig.Course = attr.Value
+	}
+
+	// Extract level
+	if attr, ok := item["level"].(*types.AttributeValueMemberS); ok {
+		level, err := strconv.Atoi(attr.Value)
+		if err == nil {
+			userConfig.Level = level
+		}
+	}
+
+	// Extract dailyWords
+	if attr, ok := item["dailyWords"].(*types.AttributeValueMemberS); ok {
+		dailyWords, err := strconv.Atoi(attr.Value)
+		if err == nil {
+			userConfig.DailyWords = dailyWords
+		}
+	} else {
+		userConfig.DailyWords = 10 // 預設值
+		userConfig.MissingPushSettings = true
+	}
+
+	// Extract pushTime
+	if attr, ok := item["pushTime"].(*types.AttributeValueMemberS); ok {
+		userConfig.PushTime = attr.Value
+	} else {
+		userConfig.PushTime = "08:00" // 預設值
+		userConfig.MissingPushSettings = true
+	}
+
+	// Extract timezone
+	if attr, ok := item["timezone"].(*types.AttributeValueMemberS); ok {
+		userConfig.Timezone = attr.Value
+	} else {
+		userConfig.Timezone = models.DefaultTimezone // 預設值
+	}
+
+	// Extract scheduleName
+	if attr, ok := item["scheduleName"].(*types.AttributeValueMemberS); ok {
+		userConfig.ScheduleName = attr.Value
+	}
+
+	// Extract fanoutPush
+	if attr, ok := item["fanoutPush"].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.FanoutPush = attr.Value
+	}
+
+	// Extract profileSlug
+	if attr, ok := item["profileSlug"].(*types.AttributeValueMemberS); ok {
+		userConfig.ProfileSlug = attr.Value
+	}
+
+	// Extract cardFormat
+	if attr, ok := item["cardFormat"].(*types.AttributeValueMemberS); ok {
+		userConfig.CardFormat = models.CardFormat(attr.Value)
+	}
+
+	// Extract lowerBandWords
+	if attr, ok := item["lowerBandWords"].(*types.AttributeValueMemberS); ok {
+		userConfig.LowerBandWords = attr.Value
+	}
+
+	// Extract reviewSource
+	if attr, ok := item["reviewSource"].(*types.AttributeValueMemberS); ok {
+		userConfig.ReviewSource = attr.Value
+	}
+
+	// Extract pronunciationAudio
+	if attr, ok := item["pronunciationAudio"].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.PronunciationAudio = attr.Value
+	}
+
+	// Extract grammarMode
+	if attr, ok := item["grammarMode"].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.GrammarMode = attr.Value
+	}
+
+	// Extract interactiveReview
+	if attr, ok := item["interactiveReview"].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.InteractiveReview = attr.Value
+	}
+
+	// Extract translationOnly
+	if attr, ok := item["translationOnly"].(*types.AttributeValueMemberBOOL); ok {
+		userConfig.TranslationOnly = attr.Value
+	}
+
+	// Extract model
+	if attr, ok := item["model"].(*types.AttributeValueMemberS); ok {
+		userConfig.Model = attr.Value
+	}
+
+	// Extract difficultyOffset
+	if attr, ok := item["difficultyOffset"].(*types.AttributeValueMemberN); ok {
+		userConfig.DifficultyOffset, _ = strconv.Atoi(attr.Value)
+	}
+
+	// Extract betaStatus / betaRequestedAt
+	if attr, ok := item["betaStatus"].(*types.AttributeValueMemberS); ok {
+		userConfig.BetaStatus = models.BetaStatus(attr.Value)
+	}
+	if attr, ok := item["betaRequestedAt"].(*types.AttributeValueMemberS); ok {
+		userConfig.BetaRequestedAt = attr.Value
+	}
+
+	// Extract plan
+	if attr, ok := item["plan"].(*types.AttributeValueMemberS); ok {
+		userConfig.Plan = models.Plan(attr.Value)
+	}
+
+	// Extract deactivatedAt
+	if attr, ok := item["deactivatedAt"].(*types.AttributeValueMemberS); ok {
+		userConfig.DeactivatedAt = attr.Value
+	}
+
+	// Extract blockedPushes
+	if attr, ok := item["blockedPushes"].(*types.AttributeValueMemberN); ok {
+		userConfig.BlockedPushes, _ = strconv.Atoi(attr.Value)
+	}
+
+	// Extract webhookCaptureUntil
+	if attr, ok := item["webhookCaptureUntil"].(*types.AttributeValueMemberS); ok {
+		userConfig.WebhookCaptureUntil = attr.Value
+	}
+
+	// Extract consents
+	parseConsents(item, &userConfig)
+
+	// Extract termsVersion / termsAcceptedAt / termsPrompted
+	if attr, ok := item["termsVersion"].(*types.AttributeValueMemberS); ok {
+		userConfig.TermsVersion = attr.Value
+	}
+	if attr, ok := item["termsAcceptedAt"].(*types.AttributeValueMemberS); ok {
+		userConfig.TermsAcceptedAt = attr.Value
+	}
+	if attr, ok := item["termsPrompted"].(*types.AttributeValueMemberS); ok {
+		userConfig.TermsPrompted = attr.Value
+	}
+
+	// Extract lineDestination
+	if attr, ok := item["lineDestination"].(*types.AttributeValueMemberS); ok {
+		userConfig.LineDestination = attr.Value
+	}
+
+	// Extract firstActiveAt / lastActiveAt
+	if attr, ok := item["firstActiveAt"].(*types.AttributeValueMemberS); ok {
+		userConfig.FirstActiveAt = attr.Value
+	}
+	if attr, ok := item["lastActiveAt"].(*types.AttributeValueMemberS); ok {
+		userConfig.LastActiveAt = attr.Value
+	}
+
+	// Extract updatedAt
+	if attr, ok := item["updatedAt"].(*types.AttributeValueMemberS); ok {
+		userConfig.UpdatedAt = attr.Value
+	}
+
+	return &userConfig
+}
+
+func formatNonZero(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
 }
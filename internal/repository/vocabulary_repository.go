@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"language-assistant/internal/models"
 	"language-assistant/internal/utils"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -29,7 +31,32 @@ func NewVocabularyRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, t
 	}
 }
 
-func (r *vocabularyRepository) SaveWord(word, partOfSpeech, translation, sentence, userID string) error {
+// SaveWord 將單字加入用戶當天的單字紀錄，並將當天紀錄的 TTL 設為 expiresAt；
+// expiresAt 為零值時不設定 TTL，紀錄永久保存（例如不受保存期限限制的付費用戶）
+func (r *vocabularyRepository) SaveWord(word, partOfSpeech, translation, sentence, source, userID string, expiresAt time.Time) error {
+	return r.appendWord(userID, "", models.WordRecord{
+		Word:         word,
+		PartOfSpeech: partOfSpeech,
+		Translation:  translation,
+		Sentence:     sentence,
+		Source:       source,
+	}, expiresAt)
+}
+
+// SaveGroupWord 將群組成員查詢的單字存到群組的單字庫（PK = groupId#vocabulary），並記錄是哪位成員查的
+func (r *vocabularyRepository) SaveGroupWord(groupID, userID, word, partOfSpeech, translation, sentence string, expiresAt time.Time) error {
+	return r.appendWord(groupID, groupID, models.WordRecord{
+		Word:         word,
+		PartOfSpeech: partOfSpeech,
+		Translation:  translation,
+		Sentence:     sentence,
+		Source:       models.WordSourceTranslation,
+		AddedBy:      userID,
+	}, expiresAt)
+}
+
+// appendWord 將單字加入 userID 今天的單字紀錄；groupID 不為空字串時標記為群組的單字庫，每晚改推播群組回顧
+func (r *vocabularyRepository) appendWord(userID, groupID string, record models.WordRecord, expiresAt time.Time) error {
 	now := time.Now().UTC()
 	today := now.Format("2006-01-02")
 	timestamp := now.Format(time.RFC3339)
@@ -78,13 +105,8 @@ func (r *vocabularyRepository) SaveWord(word, partOfSpeech, translation, sentenc
 	}
 
 	// add new word to user vocabulary no matter it's already in the list or not
-	userVoca.Words = append(userVoca.Words, models.WordRecord{
-		Word:         word,
-		PartOfSpeech: partOfSpeech,
-		Translation:  translation,
-		Sentence:     sentence,
-		Timestamp:    timestamp,
-	})
+	record.Timestamp = timestamp
+	userVoca.Words = append(userVoca.Words, record)
 	userVoca.UpdatedAt = timestamp
 
 	// save user vocabulary to dynamodb
@@ -93,16 +115,24 @@ func (r *vocabularyRepository) SaveWord(word, partOfSpeech, translation, sentenc
 		return errors.New("failed to marshal words")
 	}
 
+	item := map[string]types.AttributeValue{
+		"pk":        &types.AttributeValueMemberS{Value: pk},
+		"sk":        &types.AttributeValueMemberS{Value: userVoca.Date},
+		"userId":    &types.AttributeValueMemberS{Value: userVoca.UserID},
+		"date":      &types.AttributeValueMemberS{Value: userVoca.Date},
+		"words":     &types.AttributeValueMemberS{Value: string(wordsJSON)},
+		"updatedAt": &types.AttributeValueMemberS{Value: userVoca.UpdatedAt},
+	}
+	if !expiresAt.IsZero() {
+		item["expiresAt"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)}
+	}
+	if groupID != "" {
+		item["groupId"] = &types.AttributeValueMemberS{Value: groupID}
+	}
+
 	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
 		TableName: aws.String(r.tableName),
-		Item: map[string]types.AttributeValue{
-			"pk":        &types.AttributeValueMemberS{Value: pk},
-			"sk":        &types.AttributeValueMemberS{Value: userVoca.Date},
-			"userId":    &types.AttributeValueMemberS{Value: userVoca.UserID},
-			"date":      &types.AttributeValueMemberS{Value: userVoca.Date},
-			"words":     &types.AttributeValueMemberS{Value: string(wordsJSON)},
-			"updatedAt": &types.AttributeValueMemberS{Value: userVoca.UpdatedAt},
-		},
+		Item:      item,
 	})
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to save user vocabulary to DynamoDB")
@@ -154,28 +184,60 @@ func (r *vocabularyRepository) GetUserVocabularyByDate(userID, date string) (*mo
 	return &userVoca, nil
 }
 
+// GetAllUserVocabularies 取得用戶所有的單字紀錄，最新的日期在前；逐頁讀取，不受單次 Query 1MB 的上限影響
 func (r *vocabularyRepository) GetAllUserVocabularies(userID string) ([]models.UserVocabulary, error) {
+	userVocabularies := []models.UserVocabulary{}
+	startDate := ""
+	for {
+		page, nextDate, err := r.GetUserVocabulariesPage(userID, 0, startDate)
+		if err != nil {
+			return nil, err
+		}
+		userVocabularies = append(userVocabularies, page...)
+		if nextDate == "" {
+			break
+		}
+		startDate = nextDate
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"userId": userID,
+		"count":  len(userVocabularies),
+	}).Info("Successfully retrieved user vocabularies")
+
+	return userVocabularies, nil
+}
+
+// GetUserVocabulariesPage 分頁取得單字紀錄，最新的日期在前，每頁最多 limit 天（0 表示不限制，只受 1MB 上限影響）。
+// startDate 為上一頁回傳的 nextDate，第一頁傳空字串；nextDate 為空字串表示沒有下一頁
+func (r *vocabularyRepository) GetUserVocabulariesPage(userID string, limit int, startDate string) ([]models.UserVocabulary, string, error) {
 	pk := fmt.Sprintf("%s#vocabulary", userID)
-	
-	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+
+	input := &dynamodb.QueryInput{
 		TableName:              aws.String(r.tableName),
 		KeyConditionExpression: aws.String("pk = :pk"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":pk": &types.AttributeValueMemberS{Value: pk},
 		},
 		ScanIndexForward: aws.Bool(false), // 最新的日期在前
-	})
+	}
+	if limit > 0 {
+		input.Limit = aws.Int32(int32(limit))
+	}
+	if startDate != "" {
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: pk},
+			"sk": &types.AttributeValueMemberS{Value: startDate},
+		}
+	}
 
+	result, err := r.dynamodb.Query(context.Background(), input)
 	if err != nil {
 		r.logger.WithError(err).Error("Failed to query user vocabularies from DynamoDB")
-		return nil, fmt.Errorf("failed to query user vocabularies: %w", err)
-	}
-
-	if result.Items == nil {
-		return []models.UserVocabulary{}, nil
+		return nil, "", fmt.Errorf("failed to query user vocabularies: %w", err)
 	}
 
-	var userVocabularies []models.UserVocabulary
+	userVocabularies := []models.UserVocabulary{}
 	for _, item := range result.Items {
 		var userVoca models.UserVocabulary
 		userVoca.UserID = userID
@@ -203,10 +265,211 @@ func (r *vocabularyRepository) GetAllUserVocabularies(userID string) ([]models.U
 		userVocabularies = append(userVocabularies, userVoca)
 	}
 
-	r.logger.WithFields(logrus.Fields{
-		"userId": userID,
-		"count":  len(userVocabularies),
-	}).Info("Successfully retrieved user vocabularies")
+	// 下一頁的游標只需要日期，pk 由 userID 組出
+	var nextDate string
+	if attr, ok := result.LastEvaluatedKey["sk"].(*types.AttributeValueMemberS); ok {
+		nextDate = attr.Value
+	}
+
+	return userVocabularies, nextDate, nil
+}
+
+// GetUserVocabulariesBetween 取得指定日期區間（含頭尾）的單字紀錄，日期由舊到新
+func (r *vocabularyRepository) GetUserVocabulariesBetween(userID, fromDate, toDate string) ([]models.UserVocabulary, error) {
+	pk := fmt.Sprintf("%s#vocabulary", userID)
+
+	result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(r.tableName),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: pk},
+			":from": &types.AttributeValueMemberS{Value: fromDate},
+			":to":   &types.AttributeValueMemberS{Value: toDate},
+		},
+		ScanIndexForward: aws.Bool(true),
+	})
+
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query user vocabularies by date range from DynamoDB")
+		return nil, fmt.Errorf("failed to query user vocabularies by date range: %w", err)
+	}
+
+	var userVocabularies []models.UserVocabulary
+	for _, item := range result.Items {
+		userVoca := models.UserVocabulary{UserID: userID}
+
+		if attr, ok := item["sk"].(*types.AttributeValueMemberS); ok {
+			userVoca.Date = attr.Value
+		}
+
+		if attr, ok := item["updatedAt"].(*types.AttributeValueMemberS); ok {
+			userVoca.UpdatedAt = attr.Value
+		}
+
+		if attr, ok := item["words"].(*types.AttributeValueMemberS); ok {
+			if err := json.Unmarshal([]byte(attr.Value), &userVoca.Words); err != nil {
+				r.logger.WithError(err).Error("Failed to unmarshal words field")
+				continue
+			}
+		}
+
+		userVocabularies = append(userVocabularies, userVoca)
+	}
 
 	return userVocabularies, nil
-}
\ No newline at end of file
+}
+
+// SetVocabularyExpiry 為用戶所有單字紀錄設定 TTL（expiresAt，epoch 秒），expiresAt 為零值時取消 TTL。
+// 回傳更新的筆數
+func (r *vocabularyRepository) SetVocabularyExpiry(userID string, expiresAt time.Time) (int, error) {
+	pk := fmt.Sprintf("%s#vocabulary", userID)
+
+	updateExpression := aws.String("REMOVE expiresAt")
+	var values map[string]types.AttributeValue
+	if !expiresAt.IsZero() {
+		updateExpression = aws.String("SET expiresAt = :expiresAt")
+		values = map[string]types.AttributeValue{
+			":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		}
+	}
+
+	updated := 0
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: pk},
+			},
+			ProjectionExpression: aws.String("pk, sk"),
+			ExclusiveStartKey:    startKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query user vocabularies from DynamoDB")
+			return updated, fmt.Errorf("failed to query user vocabularies: %w", err)
+		}
+
+		for _, item := range result.Items {
+			_, err := r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+				TableName:                 aws.String(r.tableName),
+				Key:                       map[string]types.AttributeValue{"pk": item["pk"], "sk": item["sk"]},
+				UpdateExpression:          updateExpression,
+				ExpressionAttributeValues: values,
+			})
+			if err != nil {
+				r.logger.WithError(err).Error("Failed to set vocabulary expiry in DynamoDB")
+				return updated, fmt.Errorf("failed to set vocabulary expiry: %w", err)
+			}
+			updated++
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return updated, nil
+}
+
+// GetWordOccurrences 找出用戶每次儲存某個單字的紀錄（不分大小寫，日期由舊到新）。
+// 單字以 JSON 存在每日的 item 中，無法建 GSI 直接查詢單字；每位用戶一天只有一個 item，
+// 因此直接讀取用戶的 partition 並比對，只投影需要的欄位
+func (r *vocabularyRepository) GetWordOccurrences(userID, word string) ([]models.WordOccurrence, error) {
+	target := strings.ToLower(strings.TrimSpace(word))
+	occurrences := []models.WordOccurrence{}
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#vocabulary", userID)},
+			},
+			ProjectionExpression: aws.String("sk, words"),
+			ExclusiveStartKey:    lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query user vocabularies for word search")
+			return nil, fmt.Errorf("failed to query user vocabularies: %w", err)
+		}
+
+		for _, item := range result.Items {
+			date, _ := item["sk"].(*types.AttributeValueMemberS)
+			wordsAttr, ok := item["words"].(*types.AttributeValueMemberS)
+			if date == nil || !ok {
+				continue
+			}
+
+			var records []models.WordRecord
+			if err := json.Unmarshal([]byte(wordsAttr.Value), &records); err != nil {
+				r.logger.WithError(err).WithField("date", date.Value).Warn("Failed to unmarshal words field")
+				continue
+			}
+			for _, record := range records {
+				if strings.ToLower(strings.TrimSpace(record.Word)) == target {
+					occurrences = append(occurrences, models.WordOccurrence{Date: date.Value, WordRecord: record})
+				}
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return occurrences, nil
+}
+
+// UpdateWordSentence 將用戶某天單字紀錄中的單字（不分大小寫，同一天有多筆時為最新一筆）例句換成 sentence，
+// 找不到該天紀錄或單字時回傳 false。以 updatedAt 做條件寫入，避免覆蓋同時加入的單字
+func (r *vocabularyRepository) UpdateWordSentence(userID, date, word, sentence string) (bool, error) {
+	userVoca, err := r.GetUserVocabularyByDate(userID, date)
+	if err != nil {
+		return false, err
+	}
+	if userVoca == nil {
+		return false, nil
+	}
+
+	target := strings.ToLower(strings.TrimSpace(word))
+	index := -1
+	for i, record := range userVoca.Words {
+		if strings.ToLower(strings.TrimSpace(record.Word)) == target {
+			index = i
+		}
+	}
+	if index < 0 {
+		return false, nil
+	}
+	userVoca.Words[index].Sentence = sentence
+
+	wordsJSON, err := json.Marshal(userVoca.Words)
+	if err != nil {
+		return false, errors.New("failed to marshal words")
+	}
+
+	_, err = r.dynamodb.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: fmt.Sprintf("%s#vocabulary", userID)},
+			"sk": &types.AttributeValueMemberS{Value: date},
+		},
+		UpdateExpression:    aws.String("SET words = :words, updatedAt = :updatedAt"),
+		ConditionExpression: aws.String("updatedAt = :previous"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":words":     &types.AttributeValueMemberS{Value: string(wordsJSON)},
+			":updatedAt": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339)},
+			":previous":  &types.AttributeValueMemberS{Value: userVoca.UpdatedAt},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to update word sentence in DynamoDB")
+		return false, fmt.Errorf("failed to update word sentence: %w", err)
+	}
+
+	return true, nil
+}
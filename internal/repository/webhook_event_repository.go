@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+type webhookEventRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewWebhookEventRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.WebhookEventRepository {
+	return &webhookEventRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = webhookEvent#<webhookEventId>，SK 固定為 "event"
+func webhookEventKey(eventID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "webhookEvent#" + eventID},
+		"sk": &types.AttributeValueMemberS{Value: "event"},
+	}
+}
+
+// ClaimWebhookEvent 以條件寫入登記事件，回傳 false 表示此事件先前已處理過（LINE 重送）
+func (r *webhookEventRepository) ClaimWebhookEvent(event models.WebhookEvent) (bool, error) {
+	item, err := attributevalue.MarshalMap(event)
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to marshal webhook event")
+		return false, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+	for k, v := range webhookEventKey(event.EventID) {
+		item[k] = v
+	}
+
+	_, err = r.dynamodb.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:           aws.String(r.tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		r.logger.WithError(err).Error("Failed to save webhook event to DynamoDB")
+		return false, fmt.Errorf("failed to save webhook event: %w", err)
+	}
+
+	return true, nil
+}
+
+// ReleaseWebhookEvent 刪除登記，讓處理失敗的事件在 LINE 重送時可以重新處理
+func (r *webhookEventRepository) ReleaseWebhookEvent(eventID string) error {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key:       webhookEventKey(eventID),
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete webhook event from DynamoDB")
+		return fmt.Errorf("failed to delete webhook event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxBatchWriteItems DynamoDB BatchWriteItem 單次上限
+	maxBatchWriteItems = 25
+	// maxBatchWriteAttempts 重送 UnprocessedItems 的次數上限
+	maxBatchWriteAttempts = 5
+)
+
+type wordHistoryRepository struct {
+	logger    *logrus.Entry
+	dynamodb  utils.DynamoDbAPI
+	tableName string
+}
+
+func NewWordHistoryRepository(logger *logrus.Entry, dynamodb utils.DynamoDbAPI, tableName string) utils.WordHistoryRepository {
+	return &wordHistoryRepository{
+		logger:    logger,
+		dynamodb:  dynamodb,
+		tableName: tableName,
+	}
+}
+
+// PK = userId#course，SK = 正規化後的單字，每個推播過的單字一個 item
+func wordHistoryKey(userID, course string) string {
+	return userID + "#" + course
+}
+
+// RecordPushedWords 以 BatchWriteItem 寫入推播過的單字與當時的程度級距，重複寫入同一個單字只會更新 pushedAt 與級距
+func (r *wordHistoryRepository) RecordPushedWords(userID, course, band string, words []string, pushedAt time.Time) error {
+	var requests []types.WriteRequest
+	for _, word := range words {
+		key := utils.NormalizeHistoryWord(word)
+		if key == "" {
+			continue
+		}
+		item, err := attributevalue.MarshalMap(models.WordHistoryEntry{
+			UserID:   userID,
+			Course:   course,
+			Word:     key,
+			Band:     band,
+			PushedAt: pushedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to marshal word history entry")
+			return fmt.Errorf("failed to marshal word history entry: %w", err)
+		}
+		item["pk"] = &types.AttributeValueMemberS{Value: wordHistoryKey(userID, course)}
+		item["sk"] = &types.AttributeValueMemberS{Value: key}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := r.batchWrite(requests[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchWrite 寫入一批 item，被節流而未處理的 item 以指數退避重送
+func (r *wordHistoryRepository) batchWrite(requests []types.WriteRequest) error {
+	pending := map[string][]types.WriteRequest{r.tableName: requests}
+	for attempt := 1; len(pending[r.tableName]) > 0; attempt++ {
+		if attempt > maxBatchWriteAttempts {
+			r.logger.WithField("unprocessed", len(pending[r.tableName])).Error("Failed to write all word history entries")
+			return fmt.Errorf("failed to write word history: %d items unprocessed", len(pending[r.tableName]))
+		}
+		if attempt > 1 {
+			time.Sleep(time.Duration(1<<(attempt-2)) * 50 * time.Millisecond)
+		}
+
+		output, err := r.dynamodb.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{
+			RequestItems: pending,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to batch write word history to DynamoDB")
+			return fmt.Errorf("failed to write word history: %w", err)
+		}
+		pending = output.UnprocessedItems
+	}
+
+	return nil
+}
+
+// GetPushedWords 讀取推播過的單字（正規化後），超過 limit 個時停止讀取並回傳 complete = false；limit <= 0 表示全部讀取。
+// minBand 不為空時只回傳在該級距以上推播的單字，沒有記錄級距的單字一律回傳
+func (r *wordHistoryRepository) GetPushedWords(userID, course, minBand string, limit int) (map[string]bool, bool, error) {
+	words := map[string]bool{}
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: wordHistoryKey(userID, course)},
+			},
+			ProjectionExpression: aws.String("sk, band"),
+			ExclusiveStartKey:    lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query word history from DynamoDB")
+			return nil, false, fmt.Errorf("failed to query word history: %w", err)
+		}
+
+		for _, item := range result.Items {
+			sk, ok := item["sk"].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			if band, ok := item["band"].(*types.AttributeValueMemberS); ok && minBand != "" && utils.CompareLevelBands(band.Value, minBand) < 0 {
+				continue
+			}
+			words[sk.Value] = true
+		}
+		if limit > 0 && len(words) > limit {
+			return words, false, nil
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	return words, true, nil
+}
+
+// ClearPushedWords 刪除用戶某課程的所有單字歷史，回傳刪除的單字數
+func (r *wordHistoryRepository) ClearPushedWords(userID, course string) (int, error) {
+	var requests []types.WriteRequest
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		result, err := r.dynamodb.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tableName),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: wordHistoryKey(userID, course)},
+			},
+			ProjectionExpression: aws.String("pk, sk"),
+			ExclusiveStartKey:    lastEvaluatedKey,
+		})
+		if err != nil {
+			r.logger.WithError(err).Error("Failed to query word history for deletion")
+			return 0, fmt.Errorf("failed to query word history: %w", err)
+		}
+
+		for _, item := range result.Items {
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: item}})
+		}
+
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
+	}
+
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := r.batchWrite(requests[start:end]); err != nil {
+			return start, err
+		}
+	}
+
+	return len(requests), nil
+}
+
+// RemovePushedWord 刪除單一單字的歷史，之後的推播可能再次挑到這個單字
+func (r *wordHistoryRepository) RemovePushedWord(userID, course, word string) error {
+	_, err := r.dynamodb.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: wordHistoryKey(userID, course)},
+			"sk": &types.AttributeValueMemberS{Value: utils.NormalizeHistoryWord(word)},
+		},
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to delete word history entry")
+		return fmt.Errorf("failed to delete word history entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package richmenu 定義官方帳號的圖文選單（說明、設定推播、個人設定、測驗、今日單字），
+// 並提供建立選單、上傳圖片與綁定用戶的操作。選單 ID 依 stage 存放在 SSM，
+// language-handler 部署時以 ${ssm:...} 讀入 RICH_MENU_ID，在用戶加入好友時綁定
+package richmenu
+
+import (
+	"fmt"
+	"language-assistant/internal/messages"
+	"net/url"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+// 圖文選單圖片的尺寸（LINE 的大型選單規格），上方三個按鈕、下方兩個按鈕
+const (
+	Width  = 2500
+	Height = 1686
+)
+
+// Name 建立選單時使用的名稱，只會出現在 LINE Official Account Manager
+const Name = "language-assistant"
+
+// ParameterName 回傳存放 stage 圖文選單 ID 的 SSM 參數名稱
+func ParameterName(stage string) string {
+	return fmt.Sprintf("/language-assistant/%s/rich-menu-id", stage)
+}
+
+// Menu 回傳圖文選單的定義，按鈕位置需與上傳的圖片對齊
+func Menu() linebot.RichMenu {
+	rowHeight := Height / 2
+	topWidth := Width / 3
+	bottomWidth := Width / 2
+
+	return linebot.RichMenu{
+		Size:        linebot.RichMenuSize{Width: Width, Height: Height},
+		Selected:    true,
+		Name:        Name,
+		ChatBarText: messages.Text(messages.RichMenuChatBar),
+		Areas: []linebot.AreaDetail{
+			area(0, 0, topWidth, rowHeight, messageAction(messages.RichMenuHelpLabel, "/說明")),
+			area(topWidth, 0, topWidth, rowHeight, pushSettingsAction()),
+			area(topWidth*2, 0, Width-topWidth*2, rowHeight, messageAction(messages.RichMenuSettingsLabel, "/個人設定")),
+			area(0, rowHeight, bottomWidth, Height-rowHeight, messageAction(messages.RichMenuQuizLabel, "/測驗")),
+			area(bottomWidth, rowHeight, Width-bottomWidth, Height-rowHeight, messageAction(messages.RichMenuTodayWordsLabel, "/今日單字")),
+		},
+	}
+}
+
+func area(x, y, width, height int, action linebot.RichMenuAction) linebot.AreaDetail {
+	return linebot.AreaDetail{
+		Bounds: linebot.RichMenuBounds{X: x, Y: y, Width: width, Height: height},
+		Action: action,
+	}
+}
+
+// messageAction 點選後以用戶身分送出指令，與手動輸入指令的效果相同
+func messageAction(label messages.Key, command string) linebot.RichMenuAction {
+	return linebot.RichMenuAction{
+		Type:  linebot.RichMenuActionTypeMessage,
+		Label: messages.Text(label),
+		Text:  command,
+	}
+}
+
+// pushSettingsAction 與推播設定流程的按鈕相同，以 postback 開始課程選擇
+func pushSettingsAction() linebot.RichMenuAction {
+	values := url.Values{}
+	values.Set("action", messages.PostbackPushSettings)
+	values.Set("step", messages.PushSettingsStepStart)
+	label := messages.Text(messages.RichMenuPushSettingsLabel)
+	return linebot.RichMenuAction{
+		Type:        linebot.RichMenuActionTypePostback,
+		Label:       label,
+		Data:        values.Encode(),
+		DisplayText: label,
+	}
+}
+
+// Provision 建立圖文選單並上傳圖片（PNG 或 JPEG，尺寸需為 Width x Height），回傳新的選單 ID
+func Provision(client *linebot.Client, imagePath string) (string, error) {
+	created, err := client.CreateRichMenu(Menu()).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to create rich menu: %w", err)
+	}
+
+	if _, err := client.UploadRichMenuImage(created.RichMenuID, imagePath).Do(); err != nil {
+		// 沒有圖片的選單無法使用，刪除以免留下孤兒選單
+		if _, deleteErr := client.DeleteRichMenu(created.RichMenuID).Do(); deleteErr != nil {
+			return "", fmt.Errorf("failed to upload rich menu image: %w (and failed to delete rich menu %s: %v)", err, created.RichMenuID, deleteErr)
+		}
+		return "", fmt.Errorf("failed to upload rich menu image: %w", err)
+	}
+
+	return created.RichMenuID, nil
+}
@@ -0,0 +1,31 @@
+package richmenu
+
+import "testing"
+
+func TestMenuAreasFitWithoutOverlap(t *testing.T) {
+	menu := Menu()
+	if len(menu.Areas) != 5 {
+		t.Fatalf("Expected 5 buttons, got %d", len(menu.Areas))
+	}
+
+	covered := 0
+	for i, a := range menu.Areas {
+		b := a.Bounds
+		if b.X < 0 || b.Y < 0 || b.X+b.Width > Width || b.Y+b.Height > Height {
+			t.Errorf("Area %d out of bounds: %+v", i, b)
+		}
+		if a.Action.Label == "" {
+			t.Errorf("Area %d has no label", i)
+		}
+		for j, other := range menu.Areas[:i] {
+			o := other.Bounds
+			if b.X < o.X+o.Width && o.X < b.X+b.Width && b.Y < o.Y+o.Height && o.Y < b.Y+b.Height {
+				t.Errorf("Area %d overlaps area %d", i, j)
+			}
+		}
+		covered += b.Width * b.Height
+	}
+	if covered != Width*Height {
+		t.Errorf("Expected areas to cover the whole image, covered %d of %d", covered, Width*Height)
+	}
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"language-assistant/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AnalyticsAnonymizeEnv 設為 "true" 時，寫入分析資料（互動、每週活躍、事件串流）的 userId 一律改為雜湊值
+	AnalyticsAnonymizeEnv = "ANALYTICS_ANONYMIZE"
+	// AnalyticsSaltEnv 產生雜湊 salt 的密鑰，開啟匿名模式時必須設定
+	AnalyticsSaltEnv = "ANALYTICS_SALT"
+	// AnalyticsSaltRotationEnv 每隔幾天換一次 salt；同一期間內同一用戶的雜湊值相同，可以串起漏斗與留存，跨期間則無法連結
+	AnalyticsSaltRotationEnv = "ANALYTICS_SALT_ROTATION_DAYS"
+
+	defaultSaltRotationDays = 30
+)
+
+// Anonymizer replaces user IDs in analytics sinks with keyed hashes. The salt of each rotation period is derived
+// from the secret, so no salt needs to be stored and IDs from different periods cannot be linked. A nil
+// Anonymizer (anonymized mode off) returns IDs unchanged. Safe for concurrent use
+type Anonymizer struct {
+	secret   []byte
+	rotation time.Duration
+}
+
+func NewAnonymizer(secret string, rotationDays int) *Anonymizer {
+	return &Anonymizer{
+		secret:   []byte(secret),
+		rotation: time.Duration(rotationDays) * 24 * time.Hour,
+	}
+}
+
+// LoadAnonymizer reads ANALYTICS_ANONYMIZE, ANALYTICS_SALT and ANALYTICS_SALT_ROTATION_DAYS (default 30);
+// returns nil when anonymized mode is off
+func LoadAnonymizer(getenv func(string) string) (*Anonymizer, error) {
+	enabled := strings.ToLower(strings.TrimSpace(getenv(AnalyticsAnonymizeEnv)))
+	switch enabled {
+	case "", "false":
+		return nil, nil
+	case "true":
+	default:
+		return nil, fmt.Errorf("%s must be true or false, got %q", AnalyticsAnonymizeEnv, enabled)
+	}
+
+	secret := getenv(AnalyticsSaltEnv)
+	if secret == "" {
+		return nil, fmt.Errorf("%s is required when %s is true", AnalyticsSaltEnv, AnalyticsAnonymizeEnv)
+	}
+	rotationDays := defaultSaltRotationDays
+	if value := strings.TrimSpace(getenv(AnalyticsSaltRotationEnv)); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("%s must be a positive integer, got %q", AnalyticsSaltRotationEnv, value)
+		}
+		rotationDays = days
+	}
+	return NewAnonymizer(secret, rotationDays), nil
+}
+
+// RotationDays 每隔幾天換一次 salt，匿名模式關閉時為 0
+func (a *Anonymizer) RotationDays() int {
+	if a == nil {
+		return 0
+	}
+	return int(a.rotation / (24 * time.Hour))
+}
+
+// UserID 回傳 userID 在 at 所屬期間的雜湊值，格式與 PseudonymizeID 相同（開頭字母加 32 個 hex 字元）；
+// 匿名模式關閉時原樣回傳
+func (a *Anonymizer) UserID(userID string, at time.Time) string {
+	if a == nil || userID == "" {
+		return userID
+	}
+	period := at.UTC().Unix() / int64(a.rotation/time.Second)
+	salt := hmac.New(sha256.New, a.secret)
+	fmt.Fprintf(salt, "analytics-salt#%d", period)
+
+	mac := hmac.New(sha256.New, salt.Sum(nil))
+	mac.Write([]byte(userID))
+	return userID[:1] + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+type anonymizedInteractionRepository struct {
+	InteractionRepository
+	anonymizer *Anonymizer
+	now        func() time.Time
+}
+
+// AnonymizeInteractions 記錄互動時以雜湊值取代 userId；anonymizer 為 nil 時直接回傳 repo
+func AnonymizeInteractions(repo InteractionRepository, anonymizer *Anonymizer) InteractionRepository {
+	if anonymizer == nil {
+		return repo
+	}
+	return &anonymizedInteractionRepository{InteractionRepository: repo, anonymizer: anonymizer, now: time.Now}
+}
+
+func (r *anonymizedInteractionRepository) RecordInteraction(interaction models.Interaction) error {
+	interaction.UserID = r.anonymizer.UserID(interaction.UserID, r.now())
+	return r.InteractionRepository.RecordInteraction(interaction)
+}
+
+type anonymizedAnalyticsRepository struct {
+	AnalyticsRepository
+	anonymizer *Anonymizer
+}
+
+// AnonymizeAnalytics 記錄每週活躍時以雜湊值取代 userId；anonymizer 為 nil 時直接回傳 repo
+func AnonymizeAnalytics(repo AnalyticsRepository, anonymizer *Anonymizer) AnalyticsRepository {
+	if anonymizer == nil {
+		return repo
+	}
+	return &anonymizedAnalyticsRepository{AnalyticsRepository: repo, anonymizer: anonymizer}
+}
+
+// RecordWeeklyActivity 以該週的開始時間決定 salt，同一週重複記錄的用戶仍會對到同一筆
+func (r *anonymizedAnalyticsRepository) RecordWeeklyActivity(activity models.WeeklyActivity) error {
+	week, err := time.Parse("2006-01-02", activity.Week)
+	if err != nil {
+		return fmt.Errorf("invalid activity week %q: %w", activity.Week, err)
+	}
+	activity.UserID = r.anonymizer.UserID(activity.UserID, week)
+	return r.AnalyticsRepository.RecordWeeklyActivity(activity)
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+type recordingInteractions struct {
+	InteractionRepository
+	recorded []models.Interaction
+}
+
+func (r *recordingInteractions) RecordInteraction(interaction models.Interaction) error {
+	r.recorded = append(r.recorded, interaction)
+	return nil
+}
+
+func TestLoadAnonymizer(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+
+	if anonymizer, err := LoadAnonymizer(env(nil)); err != nil || anonymizer != nil {
+		t.Errorf("Expected anonymized mode to be off by default, got %v (err %v)", anonymizer, err)
+	}
+	if _, err := LoadAnonymizer(env(map[string]string{AnalyticsAnonymizeEnv: "true"})); err == nil {
+		t.Error("Expected an error without a salt")
+	}
+	if _, err := LoadAnonymizer(env(map[string]string{AnalyticsAnonymizeEnv: "true", AnalyticsSaltEnv: "s", AnalyticsSaltRotationEnv: "0"})); err == nil {
+		t.Error("Expected an error for a zero rotation")
+	}
+	anonymizer, err := LoadAnonymizer(env(map[string]string{AnalyticsAnonymizeEnv: "true", AnalyticsSaltEnv: "s"}))
+	if err != nil || anonymizer.RotationDays() != 30 {
+		t.Errorf("Expected the default 30-day rotation, got %v (err %v)", anonymizer, err)
+	}
+}
+
+func TestAnonymizerUserID(t *testing.T) {
+	anonymizer := NewAnonymizer("secret", 7)
+	userID := "U4af4980629a0b0c0d0e0f0a0b0c0d0e0"
+	// 1970-01-01 起算，2026-10-15 與 2026-10-20 落在同一個 7 天期間，2026-10-23 是下一個期間
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+
+	first := anonymizer.UserID(userID, day(15))
+	if first == userID || len(first) != 33 || first[0] != 'U' {
+		t.Fatalf("Expected a pseudonym shaped like a LINE ID, got %q", first)
+	}
+	if again := anonymizer.UserID(userID, day(20)); again != first {
+		t.Errorf("Expected the same hash within a rotation period, got %q and %q", first, again)
+	}
+	if rotated := anonymizer.UserID(userID, day(23)); rotated == first {
+		t.Error("Expected the hash to change after the salt rotates")
+	}
+	if other := NewAnonymizer("other", 7).UserID(userID, day(15)); other == first {
+		t.Error("Expected a different secret to produce a different hash")
+	}
+
+	var disabled *Anonymizer
+	if disabled.UserID(userID, day(15)) != userID {
+		t.Error("Expected a nil anonymizer to keep the user ID")
+	}
+}
+
+func TestAnonymizeInteractions(t *testing.T) {
+	repo := &recordingInteractions{}
+	anonymizer := NewAnonymizer("secret", 30)
+	interactions := AnonymizeInteractions(repo, anonymizer)
+	if err := interactions.RecordInteraction(models.Interaction{UserID: "U1", Action: models.InteractionFollow}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(repo.recorded) != 1 || repo.recorded[0].UserID != anonymizer.UserID("U1", time.Now()) {
+		t.Errorf("Expected the interaction to be recorded with the hashed user ID, got %+v", repo.recorded)
+	}
+	if AnonymizeInteractions(repo, nil) != InteractionRepository(repo) {
+		t.Error("Expected the repository to be returned as is when anonymized mode is off")
+	}
+}
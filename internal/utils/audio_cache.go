@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const audioCachePrefix = "audio/"
+
+// AudioSynthesizer produces MP3 speech audio for a word; called only on cache misses
+type AudioSynthesizer func(text, voice string) ([]byte, error)
+
+// AudioCache stores synthesized word audio through the MediaService, keyed by normalized word and voice,
+// so common words are synthesized once and shared across users. Holds no mutable state; safe for concurrent use
+type AudioCache struct {
+	media *MediaService
+}
+
+func NewAudioCache(media *MediaService) *AudioCache {
+	return &AudioCache{media: media}
+}
+
+// NormalizeAudioText lower-cases the word, trims surrounding punctuation and collapses whitespace
+// so "Happy", " happy " and "happy!" share one cache entry
+func NormalizeAudioText(text string) string {
+	text = strings.TrimFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// AudioCacheKey returns the object key for a word and voice
+func AudioCacheKey(text, voice string) string {
+	sum := sha256.Sum256([]byte(NormalizeAudioText(text)))
+	return fmt.Sprintf("%s%s/%s.mp3", audioCachePrefix, strings.ToLower(voice), hex.EncodeToString(sum[:]))
+}
+
+// GetOrSynthesize returns a presigned URL for the word's audio, synthesizing and storing it on a cache miss.
+// The bool reports whether the audio came from the cache.
+func (c *AudioCache) GetOrSynthesize(text, voice string, synthesize AudioSynthesizer) (string, bool, error) {
+	key := AudioCacheKey(text, voice)
+
+	exists, err := c.media.Exists(key)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		url, err := c.media.URL(MediaAudio, key)
+		return url, true, err
+	}
+
+	audio, err := synthesize(NormalizeAudioText(text), voice)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to synthesize audio: %w", err)
+	}
+	url, err := c.media.Publish(MediaAudio, key, audio)
+	return url, false, err
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+type memoryStorage struct {
+	objects map[string][]byte
+}
+
+func (m *memoryStorage) PutObject(key string, body []byte, contentType string) error {
+	m.objects[key] = body
+	return nil
+}
+
+func (m *memoryStorage) PresignGetObject(key string, expiry time.Duration) (string, error) {
+	return "https://example.com/" + key, nil
+}
+
+func (m *memoryStorage) ObjectExists(key string) (bool, error) {
+	_, ok := m.objects[key]
+	return ok, nil
+}
+
+func TestAudioCacheKey(t *testing.T) {
+	if AudioCacheKey("Happy", "alloy") != AudioCacheKey(" happy! ", "ALLOY") {
+		t.Error("Expected normalized words to share a cache key")
+	}
+	if AudioCacheKey("happy", "alloy") == AudioCacheKey("happy", "nova") {
+		t.Error("Expected different voices to use different cache keys")
+	}
+}
+
+func TestAudioCacheGetOrSynthesize(t *testing.T) {
+	cache := NewAudioCache(NewMediaService(&memoryStorage{objects: map[string][]byte{}}, nil))
+	calls := 0
+	synthesize := func(text, voice string) ([]byte, error) {
+		calls++
+		return []byte("audio:" + text), nil
+	}
+
+	_, hit, err := cache.GetOrSynthesize("Happy", "alloy", synthesize)
+	if err != nil || hit {
+		t.Fatalf("Expected miss without error, got hit=%v err=%v", hit, err)
+	}
+	_, hit, err = cache.GetOrSynthesize("happy", "alloy", synthesize)
+	if err != nil || !hit {
+		t.Fatalf("Expected hit without error, got hit=%v err=%v", hit, err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 synthesis call, got %d", calls)
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+)
+
+// LambdaInvoker defines the Lambda operations needed to trigger other functions; *lambda.Client implements it
+type LambdaInvoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// SchedulerAPI defines the EventBridge Scheduler operations used to manage per-user push schedules;
+// *scheduler.Client implements it
+type SchedulerAPI interface {
+	CreateSchedule(ctx context.Context, params *scheduler.CreateScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.CreateScheduleOutput, error)
+	GetSchedule(ctx context.Context, params *scheduler.GetScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.GetScheduleOutput, error)
+	UpdateSchedule(ctx context.Context, params *scheduler.UpdateScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.UpdateScheduleOutput, error)
+	DeleteSchedule(ctx context.Context, params *scheduler.DeleteScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.DeleteScheduleOutput, error)
+}
+
+var (
+	_ LambdaInvoker = (*lambda.Client)(nil)
+	_ SchedulerAPI  = (*scheduler.Client)(nil)
+	_ LambdaInvoker = (*MockLambdaInvoker)(nil)
+	_ SchedulerAPI  = (*MockScheduler)(nil)
+)
+
+// FirehoseAPI defines the Kinesis Data Firehose operation used to stream analytics events; *firehose.Client
+// implements it
+type FirehoseAPI interface {
+	PutRecord(ctx context.Context, params *firehose.PutRecordInput, optFns ...func(*firehose.Options)) (*firehose.PutRecordOutput, error)
+}
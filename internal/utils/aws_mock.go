@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+)
+
+// MockLambdaInvoker records every Invoke call instead of calling Lambda; Err, when set, is returned from every call
+type MockLambdaInvoker struct {
+	mu     sync.Mutex
+	Inputs []*lambda.InvokeInput
+	Err    error
+}
+
+func (m *MockLambdaInvoker) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Inputs = append(m.Inputs, params)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &lambda.InvokeOutput{StatusCode: 202}, nil
+}
+
+// Invocations returns the recorded calls with the given invocation type ("Event", "DryRun", ...)
+func (m *MockLambdaInvoker) Invocations(invocationType string) []*lambda.InvokeInput {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*lambda.InvokeInput
+	for _, input := range m.Inputs {
+		if string(input.InvocationType) == invocationType {
+			result = append(result, input)
+		}
+	}
+	return result
+}
+
+// MockSchedule is a schedule stored by MockScheduler
+type MockSchedule struct {
+	Expression string
+	State      types.ScheduleState
+	Target     *types.Target
+}
+
+// MockScheduler keeps schedules in memory and behaves like EventBridge Scheduler for missing or duplicate names
+// (ResourceNotFoundException / ConflictException). Errors maps an operation name (e.g. "UpdateSchedule") to an
+// error returned instead of performing it; Calls lists the operations in order
+type MockScheduler struct {
+	mu        sync.Mutex
+	Schedules map[string]*MockSchedule // key: group/name
+	Errors    map[string]error
+	Calls     []string
+}
+
+// NewMockScheduler returns an empty MockScheduler
+func NewMockScheduler() *MockScheduler {
+	return &MockScheduler{Schedules: map[string]*MockSchedule{}, Errors: map[string]error{}}
+}
+
+// Schedule returns the stored schedule in ScheduleGroupName, or nil
+func (m *MockScheduler) Schedule(name string) *MockSchedule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Schedules[mockScheduleKey(aws.String(ScheduleGroupName), aws.String(name))]
+}
+
+func mockScheduleKey(group, name *string) string {
+	if g := aws.ToString(group); g != "" {
+		return g + "/" + aws.ToString(name)
+	}
+	return ScheduleGroupName + "/" + aws.ToString(name)
+}
+
+// call records the operation and returns its injected error; the caller must hold m.mu
+func (m *MockScheduler) call(operation string) error {
+	m.Calls = append(m.Calls, operation)
+	return m.Errors[operation]
+}
+
+func (m *MockScheduler) CreateSchedule(ctx context.Context, params *scheduler.CreateScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.CreateScheduleOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call("CreateSchedule"); err != nil {
+		return nil, err
+	}
+	key := mockScheduleKey(params.GroupName, params.Name)
+	if _, exists := m.Schedules[key]; exists {
+		return nil, &types.ConflictException{Message: aws.String(fmt.Sprintf("schedule %s already exists", key))}
+	}
+	m.Schedules[key] = &MockSchedule{Expression: aws.ToString(params.ScheduleExpression), State: params.State, Target: params.Target}
+	return &scheduler.CreateScheduleOutput{ScheduleArn: aws.String("arn:aws:scheduler:::schedule/" + key)}, nil
+}
+
+func (m *MockScheduler) GetSchedule(ctx context.Context, params *scheduler.GetScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.GetScheduleOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call("GetSchedule"); err != nil {
+		return nil, err
+	}
+	schedule, ok := m.Schedules[mockScheduleKey(params.GroupName, params.Name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("schedule not found")}
+	}
+	return &scheduler.GetScheduleOutput{
+		Name:               params.Name,
+		GroupName:          aws.String(ScheduleGroupName),
+		ScheduleExpression: aws.String(schedule.Expression),
+		State:              schedule.State,
+		Target:             schedule.Target,
+		FlexibleTimeWindow: &types.FlexibleTimeWindow{Mode: types.FlexibleTimeWindowModeOff},
+	}, nil
+}
+
+func (m *MockScheduler) UpdateSchedule(ctx context.Context, params *scheduler.UpdateScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.UpdateScheduleOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call("UpdateSchedule"); err != nil {
+		return nil, err
+	}
+	schedule, ok := m.Schedules[mockScheduleKey(params.GroupName, params.Name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("schedule not found")}
+	}
+	schedule.Expression = aws.ToString(params.ScheduleExpression)
+	schedule.State = params.State
+	schedule.Target = params.Target
+	return &scheduler.UpdateScheduleOutput{}, nil
+}
+
+func (m *MockScheduler) DeleteSchedule(ctx context.Context, params *scheduler.DeleteScheduleInput, optFns ...func(*scheduler.Options)) (*scheduler.DeleteScheduleOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.call("DeleteSchedule"); err != nil {
+		return nil, err
+	}
+	key := mockScheduleKey(params.GroupName, params.Name)
+	if _, ok := m.Schedules[key]; !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("schedule not found")}
+	}
+	delete(m.Schedules, key)
+	return &scheduler.DeleteScheduleOutput{}, nil
+}
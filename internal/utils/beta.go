@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"sort"
+	"strings"
+)
+
+// Modules that can be put behind the beta allowlist
+const (
+	BetaFeatureQuiz            = "quiz"
+	BetaFeatureWritingFeedback = "writing_feedback"
+)
+
+// BetaGate decides whether a module is available to a user.
+// Modules listed in BETA_FEATURES only activate for approved beta testers; all others are open to everyone.
+// Read-only after LoadBetaGate; safe for concurrent use
+type BetaGate struct {
+	gated map[string]bool
+}
+
+// LoadBetaGate reads a comma separated module list from BETA_FEATURES
+func LoadBetaGate(getenv func(string) string) *BetaGate {
+	gate := &BetaGate{gated: map[string]bool{}}
+	for _, feature := range strings.Split(getenv("BETA_FEATURES"), ",") {
+		if feature = strings.TrimSpace(feature); feature != "" {
+			gate.gated[feature] = true
+		}
+	}
+	return gate
+}
+
+// IsGated reports whether the module is in allowlist mode
+func (g *BetaGate) IsGated(feature string) bool {
+	return g.gated[feature]
+}
+
+// Gated 回傳目前只開放給測試用戶的模組，依名稱排序
+func (g *BetaGate) Gated() []string {
+	features := make([]string, 0, len(g.gated))
+	for feature := range g.gated {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// Allows 回傳用戶是否可使用該模組；userConfig 為 nil 時視為非測試者
+func (g *BetaGate) Allows(feature string, userConfig *models.UserConfig) bool {
+	if !g.IsGated(feature) {
+		return true
+	}
+	return userConfig != nil && userConfig.BetaStatus == models.BetaStatusApproved
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestBetaGate(t *testing.T) {
+	gate := LoadBetaGate(func(key string) string {
+		if key == "BETA_FEATURES" {
+			return " quiz, ,writing_feedback"
+		}
+		return ""
+	})
+
+	approved := &models.UserConfig{BetaStatus: models.BetaStatusApproved}
+	pending := &models.UserConfig{BetaStatus: models.BetaStatusPending}
+
+	if !gate.Allows(BetaFeatureQuiz, approved) {
+		t.Errorf("Approved beta tester should be allowed")
+	}
+	if gate.Allows(BetaFeatureQuiz, pending) || gate.Allows(BetaFeatureWritingFeedback, nil) {
+		t.Errorf("Gated module should not be available to non beta testers")
+	}
+	if !gate.Allows("translation", nil) {
+		t.Errorf("Modules not listed in BETA_FEATURES should be open to everyone")
+	}
+
+	if LoadBetaGate(func(string) string { return "" }).IsGated(BetaFeatureQuiz) {
+		t.Errorf("Nothing should be gated when BETA_FEATURES is empty")
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+)
+
+// BloomRebuildResult summarizes where the words of a rebuilt bloom filter came from
+type BloomRebuildResult struct {
+	UserID       string `json:"userId"`
+	Course       string `json:"course"`
+	Words        int    `json:"words"`        // 加入新 filter 的不重複單字數
+	HistoryWords int    `json:"historyWords"` // 來自單字歷史
+	PushLogWords int    `json:"pushLogWords"` // 來自推播紀錄（含單字歷史建立前的推播）
+}
+
+// BloomFilterRebuilder reconstructs a user's bloom filter from the word history and push logs,
+// for filters that were corrupted or need a different size.
+// Safe for concurrent use, but concurrent rebuilds of the same user race on the final save
+type BloomFilterRebuilder struct {
+	bloomFilterRepo BloomFilterRepository
+	wordHistoryRepo WordHistoryRepository
+	pushLogRepo     PushLogRepository
+}
+
+func NewBloomFilterRebuilder(bloomFilterRepo BloomFilterRepository, wordHistoryRepo WordHistoryRepository, pushLogRepo PushLogRepository) *BloomFilterRebuilder {
+	return &BloomFilterRebuilder{
+		bloomFilterRepo: bloomFilterRepo,
+		wordHistoryRepo: wordHistoryRepo,
+		pushLogRepo:     pushLogRepo,
+	}
+}
+
+// Rebuild 以全新的 filter 取代既有的 filter，只納入成功送達且屬於該課程的推播
+func (b *BloomFilterRebuilder) Rebuild(userID, course string) (*BloomRebuildResult, error) {
+	history, _, err := b.wordHistoryRepo.GetPushedWords(userID, course, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load word history: %w", err)
+	}
+	logs, err := b.pushLogRepo.GetAllPushLogs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load push logs: %w", err)
+	}
+
+	// bloom filter 以原始單字比對，推播紀錄的原始大小寫與單字歷史的小寫版本都加入
+	words := map[string]bool{}
+	distinct := map[string]bool{}
+	result := &BloomRebuildResult{UserID: userID, Course: course}
+	for word := range history {
+		words[word] = true
+		distinct[word] = true
+		result.HistoryWords++
+	}
+	for _, log := range logs {
+		if log.Course != course || log.Status != models.PushStatusDelivered {
+			continue
+		}
+		for _, word := range log.Words {
+			result.PushLogWords++
+			words[word] = true
+			words[NormalizeHistoryWord(word)] = true
+			distinct[NormalizeHistoryWord(word)] = true
+		}
+	}
+
+	filter := models.NewCountingBloomFilter(userID)
+	for word := range words {
+		filter.Add(word)
+	}
+	if err := b.bloomFilterRepo.SaveBloomFilter(filter, course); err != nil {
+		return nil, fmt.Errorf("failed to save rebuilt bloom filter: %w", err)
+	}
+
+	result.Words = len(distinct)
+	return result, nil
+}
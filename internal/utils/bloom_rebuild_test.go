@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+type stubWordHistory map[string]bool
+
+func (s stubWordHistory) RecordPushedWords(userID, course, band string, words []string, pushedAt time.Time) error {
+	return nil
+}
+
+func (s stubWordHistory) GetPushedWords(userID, course, minBand string, limit int) (map[string]bool, bool, error) {
+	return s, true, nil
+}
+
+func (s stubWordHistory) RemovePushedWord(userID, course, word string) error {
+	return nil
+}
+
+func (s stubWordHistory) ClearPushedWords(userID, course string) (int, error) {
+	return len(s), nil
+}
+
+type stubPushLogs struct {
+	PushLogRepository
+	logs []models.PushLog
+}
+
+func (s stubPushLogs) GetAllPushLogs(userID string) ([]models.PushLog, error) {
+	return s.logs, nil
+}
+
+type capturingBloomFilters struct {
+	BloomFilterRepository
+	saved *models.BloomFilter
+}
+
+func (c *capturingBloomFilters) SaveBloomFilter(filter *models.BloomFilter, course string) error {
+	c.saved = filter
+	return nil
+}
+
+func TestBloomFilterRebuild(t *testing.T) {
+	bloom := &capturingBloomFilters{}
+	pushLogs := stubPushLogs{logs: []models.PushLog{
+		{Course: "toeic", Status: models.PushStatusDelivered, Words: []string{"Resilient", "abundant"}},
+		{Course: "toeic", Status: models.PushStatusFailed, Words: []string{"undelivered"}},
+		{Course: "ielts", Status: models.PushStatusDelivered, Words: []string{"otherCourse"}},
+	}}
+	rebuilder := NewBloomFilterRebuilder(bloom, stubWordHistory{"resilient": true, "meticulous": true}, pushLogs)
+
+	result, err := rebuilder.Rebuild("U1", "toeic")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Words != 3 || result.HistoryWords != 2 || result.PushLogWords != 2 {
+		t.Errorf("Unexpected rebuild result: %+v", result)
+	}
+
+	for _, word := range []string{"Resilient", "resilient", "abundant", "meticulous"} {
+		if !bloom.saved.Contains(word) {
+			t.Errorf("Expected rebuilt filter to contain %q", word)
+		}
+	}
+	if bloom.saved.Contains("undelivered") || bloom.saved.Contains("otherCourse") {
+		t.Errorf("Failed pushes and other courses should not be in the filter")
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// PickCardFormat 決定本次推播使用的字卡格式。
+// 用戶自行選擇過格式時以用戶設定為準；尚未選擇的用戶每次推播隨機分派，作為 A/B 測試樣本
+func PickCardFormat(preferred models.CardFormat, imageAvailable bool, rnd *rand.Rand) (format models.CardFormat, experiment bool) {
+	if preferred != "" {
+		return preferred, false
+	}
+	if !imageAvailable {
+		// 無法產生圖片時不納入實驗，避免樣本只有單一格式
+		return models.CardFormatText, false
+	}
+	if rnd.Intn(2) == 0 {
+		return models.CardFormatText, true
+	}
+	return models.CardFormatImage, true
+}
+
+// WordAckPostbackData 產生「記住了」按鈕的 postback data，experiment 為 true 時會計入 A/B 指標。
+// 帶上送出時間，點擊時可計算用戶的反應時間
+func WordAckPostbackData(format models.CardFormat, sentAt time.Time, experiment bool) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackWordAck)
+	values.Set("format", string(format))
+	values.Set("date", sentAt.UTC().Format("2006-01-02"))
+	values.Set("sent", strconv.FormatInt(sentAt.Unix(), 10))
+	if experiment {
+		values.Set("exp", "1")
+	}
+	return values.Encode()
+}
+
+// CardFormatSummary 彙總整段期間某格式的推播與互動次數
+type CardFormatSummary struct {
+	Format         string  `json:"format"`
+	Pushes         int     `json:"pushes"`
+	Engagements    int     `json:"engagements"`
+	EngagementRate float64 `json:"engagementRate"`
+}
+
+// SummarizeCardFormatMetrics 將每日指標依格式加總，依格式名稱排序
+func SummarizeCardFormatMetrics(daily []models.CardFormatMetrics) []CardFormatSummary {
+	totals := map[string]*models.CardFormatMetrics{}
+	for _, m := range daily {
+		total, ok := totals[m.Format]
+		if !ok {
+			total = &models.CardFormatMetrics{Format: m.Format}
+			totals[m.Format] = total
+		}
+		total.Pushes += m.Pushes
+		total.Engagements += m.Engagements
+	}
+
+	summaries := make([]CardFormatSummary, 0, len(totals))
+	for _, total := range totals {
+		summaries = append(summaries, CardFormatSummary{
+			Format:         total.Format,
+			Pushes:         total.Pushes,
+			Engagements:    total.Engagements,
+			EngagementRate: total.EngagementRate(),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Format < summaries[j].Format })
+	return summaries
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPickCardFormat(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	if format, experiment := PickCardFormat(models.CardFormatImage, true, rnd); format != models.CardFormatImage || experiment {
+		t.Errorf("Explicit preference should win, got %q (experiment=%v)", format, experiment)
+	}
+	if format, experiment := PickCardFormat("", false, rnd); format != models.CardFormatText || experiment {
+		t.Errorf("Without image support should fall back to text outside the experiment, got %q (experiment=%v)", format, experiment)
+	}
+
+	seen := map[models.CardFormat]int{}
+	for i := 0; i < 200; i++ {
+		format, experiment := PickCardFormat("", true, rnd)
+		if !experiment {
+			t.Fatalf("Users without a preference should be in the experiment")
+		}
+		seen[format]++
+	}
+	if seen[models.CardFormatText] == 0 || seen[models.CardFormatImage] == 0 {
+		t.Errorf("Expected both formats to be assigned, got %v", seen)
+	}
+}
+
+func TestWordAckPostbackData(t *testing.T) {
+	sentAt := time.Date(2025, 1, 2, 23, 30, 0, 0, time.UTC)
+	values, err := url.ParseQuery(WordAckPostbackData(models.CardFormatImage, sentAt, true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if values.Get("action") != models.PostbackWordAck || values.Get("format") != "image" || values.Get("date") != "2025-01-02" || values.Get("exp") != "1" || values.Get("sent") != "1735860600" {
+		t.Errorf("Unexpected postback data: %v", values)
+	}
+}
+
+func TestSummarizeCardFormatMetrics(t *testing.T) {
+	summaries := SummarizeCardFormatMetrics([]models.CardFormatMetrics{
+		{Date: "2025-01-01", Format: "text", Pushes: 10, Engagements: 2},
+		{Date: "2025-01-01", Format: "image", Pushes: 8, Engagements: 4},
+		{Date: "2025-01-02", Format: "text", Pushes: 10, Engagements: 3},
+	})
+
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 formats, got %d", len(summaries))
+	}
+	if summaries[0].Format != "image" || summaries[0].EngagementRate != 0.5 {
+		t.Errorf("Unexpected image summary: %+v", summaries[0])
+	}
+	if summaries[1].Format != "text" || summaries[1].Pushes != 20 || summaries[1].EngagementRate != 0.25 {
+		t.Errorf("Unexpected text summary: %+v", summaries[1])
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import "strings"
+
+// commandAlias maps an English command to the Chinese command the router handles
+type commandAlias struct {
+	command       string
+	translateArgs bool // 參數是開啟/關閉等固定選項時，一併將英文選項轉成中文
+}
+
+// commandAliases 讓不熟悉中文的學習者也能使用英文指令
+var commandAliases = map[string]commandAlias{
+	"/help":          {command: "/說明"},
+	"/setup":         {command: "/設定推播"},
+	"/settings":      {command: "/個人設定"},
+	"/history":       {command: "/推播紀錄"},
+	"/stats":         {command: "/統計"},
+	"/vocab-history": {command: "/歷史"},
+	"/login":         {command: "/登入網頁"},
+	"/quiz":          {command: "/測驗"},
+	"/beta":          {command: "/加入測試"},
+	"/support":       {command: "/支持"},
+	"/trial":         {command: "/試用"},
+	"/translate":     {command: "/翻譯"},
+	"/ranking":       {command: "/排行榜"},
+	"/repush":        {command: "/重發"},
+	"/search":        {command: "/查詢"},
+	"/export":        {command: "/匯出"},
+	"/decks":         {command: "/清單"},
+	"/new-deck":      {command: "/建立清單"},
+	"/reset-words":   {command: "/重置單字紀錄"},
+	"/lower-band":    {command: "/低階單字", translateArgs: true},
+	"/review-source": {command: "/複習來源", translateArgs: true},
+	"/profile":       {command: "/公開檔案", translateArgs: true},
+	"/card-format":   {command: "/字卡格式", translateArgs: true},
+	"/pronunciation": {command: "/發音", translateArgs: true},
+	"/model":         {command: "/模型", translateArgs: true},
+	"/grammar":       {command: "/文法模式", translateArgs: true},
+	"/review-mode":   {command: "/互動複習", translateArgs: true},
+	"/difficulty":    {command: "/難度調整", translateArgs: true},
+	"/privacy":       {command: "/隱私設定"},
+}
+
+var argumentAliases = map[string]string{
+	"on":      "開啟",
+	"off":     "關閉",
+	"image":   "圖片",
+	"text":    "文字",
+	"review":  "複習",
+	"skip":    "略過",
+	"default": "預設",
+	"all":     "全部",
+	"mine":    "我查的",
+	"up":      "提高",
+	"down":    "降低",
+}
+
+// NormalizeCommand 將英文指令別名轉成中文指令，例如 "/grammar on" → "/文法模式 開啟"；
+// 不是英文別名的文字原樣回傳，指令名稱不分大小寫
+func NormalizeCommand(text string) string {
+	if !strings.HasPrefix(text, "/") {
+		return text
+	}
+
+	name, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	alias, ok := commandAliases[strings.ToLower(name)]
+	if !ok {
+		return text
+	}
+
+	fields := strings.Fields(args)
+	if alias.translateArgs {
+		for i, field := range fields {
+			if translated, ok := argumentAliases[strings.ToLower(field)]; ok {
+				fields[i] = translated
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return alias.command
+	}
+	return alias.command + " " + strings.Join(fields, " ")
+}
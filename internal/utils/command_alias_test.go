@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestNormalizeCommand(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/help", "/說明"},
+		{"/Settings", "/個人設定"},
+		{"/stats", "/統計"},
+		{"/grammar ON", "/文法模式 開啟"},
+		{"/card-format image", "/字卡格式 圖片"},
+		{"/model gpt-4o", "/模型 gpt-4o"},
+		// 查詢的參數是單字，不轉換
+		{"/search default", "/查詢 default"},
+		{"/說明", "/說明"},
+		{"/unknown", "/unknown"},
+		{"help", "help"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeCommand(tt.input); got != tt.expected {
+			t.Errorf("NormalizeCommand(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
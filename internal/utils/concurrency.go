@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// lockedSource serializes access to a rand.Source so one *rand.Rand can be shared across goroutines
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// NewConcurrentRand returns a *rand.Rand that is safe for concurrent use (except Read, which handlers don't use).
+// rand.New(rand.NewSource(...)) is not, so handler-level random sources must come from here
+func NewConcurrentRand(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
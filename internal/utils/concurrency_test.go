@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 這些測試以多個 goroutine 共用同一個實例，需搭配 go test -race 才能發現 data race
+
+func TestConcurrentRandSharedAcrossGoroutines(t *testing.T) {
+	rnd := NewConcurrentRand(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				rnd.Intn(10)
+				rnd.Perm(4)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPromptRolloutCache(t *testing.T) {
+	cache := NewPromptRolloutCache(time.Hour)
+	var loads atomic.Int32
+	load := func() (*models.PromptRollout, error) {
+		loads.Add(1)
+		return &models.PromptRollout{RolloutID: "r1"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := cache.Get(load); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 同時 miss 時可能重複載入，但快取生效後不應每次都讀取
+	if n := loads.Load(); n == 0 || n > 8 {
+		t.Errorf("Expected between 1 and 8 loads, got %d", n)
+	}
+
+	cache.Clear()
+	rollout, _ := cache.Get(load)
+	if rollout != nil {
+		t.Errorf("Expected cleared cache to return nil until ttl passes, got %+v", rollout)
+	}
+}
+
+func TestPromptRolloutCacheKeepsPreviousOnError(t *testing.T) {
+	cache := NewPromptRolloutCache(0)
+	cache.Get(func() (*models.PromptRollout, error) { return &models.PromptRollout{RolloutID: "r1"}, nil })
+
+	time.Sleep(time.Millisecond)
+	rollout, err := cache.Get(func() (*models.PromptRollout, error) { return nil, ErrUserNotFound })
+	if err == nil || rollout == nil || rollout.RolloutID != "r1" {
+		t.Errorf("Expected previous rollout with error, got %+v, %v", rollout, err)
+	}
+}
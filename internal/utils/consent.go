@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ConsentCacheTTL 各 Lambda 重新讀取用戶隱私設定的間隔；用戶在同一個 handler 修改設定時會立即清除快取，
+// 其他 Lambda 最多這麼久後生效
+const ConsentCacheTTL = time.Minute
+
+// maxCachedConsents 快取超過這個數量時先清掉過期的項目，避免長時間保持 warm 的 Lambda 無限累積
+const maxCachedConsents = 1000
+
+type cachedConsent struct {
+	userConfig models.UserConfig
+	loadedAt   time.Time
+}
+
+// ConsentChecker answers whether a user agreed to a data use (models.ConsentType) before analytics, broadcast or
+// AI data paths act on their data. Users who never answered the consent prompt, and users whose settings cannot
+// be loaded, are treated as not consenting. A nil checker allows everything. Safe for concurrent use
+type ConsentChecker struct {
+	logger *logrus.Entry
+	repo   UserConfigRepository
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedConsent
+}
+
+func NewConsentChecker(logger *logrus.Entry, repo UserConfigRepository, ttl time.Duration) *ConsentChecker {
+	return &ConsentChecker{
+		logger: logger,
+		repo:   repo,
+		ttl:    ttl,
+		now:    time.Now,
+		cache:  map[string]cachedConsent{},
+	}
+}
+
+// Allowed 回傳用戶是否同意該項目
+func (c *ConsentChecker) Allowed(userID string, consent models.ConsentType) bool {
+	if c == nil {
+		return true
+	}
+	userConfig, err := c.userConfig(userID)
+	if err != nil {
+		c.logger.WithError(err).WithFields(logrus.Fields{"userId": userID, "consent": consent}).Warn("Failed to load consents, treating as not consented")
+		return false
+	}
+	return userConfig.HasConsent(consent)
+}
+
+// Forget 清除用戶的快取，修改隱私設定後呼叫讓新設定立即生效
+func (c *ConsentChecker) Forget(userID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.cache, userID)
+}
+
+func (c *ConsentChecker) userConfig(userID string) (models.UserConfig, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	cached, ok := c.cache[userID]
+	c.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) <= c.ttl {
+		return cached.userConfig, nil
+	}
+
+	userConfig, err := c.repo.GetUserConfig(userID)
+	if err != nil {
+		return models.UserConfig{}, fmt.Errorf("failed to get user config: %w", err)
+	}
+	// 沒有用戶資料（例如群組中未加好友的成員）視為尚未同意
+	var loaded models.UserConfig
+	if userConfig != nil {
+		loaded = *userConfig
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= maxCachedConsents {
+		for id, entry := range c.cache {
+			if now.Sub(entry.loadedAt) > c.ttl {
+				delete(c.cache, id)
+			}
+		}
+	}
+	c.cache[userID] = cachedConsent{userConfig: loaded, loadedAt: now}
+	return loaded, nil
+}
+
+type consentedInteractionRepository struct {
+	InteractionRepository
+	consents *ConsentChecker
+}
+
+// ConsentedInteractions 只記錄同意統計分析的用戶的互動；consents 為 nil 時直接回傳 repo
+func ConsentedInteractions(repo InteractionRepository, consents *ConsentChecker) InteractionRepository {
+	if consents == nil {
+		return repo
+	}
+	return &consentedInteractionRepository{InteractionRepository: repo, consents: consents}
+}
+
+func (r *consentedInteractionRepository) RecordInteraction(interaction models.Interaction) error {
+	if !r.consents.Allowed(interaction.UserID, models.ConsentAnalytics) {
+		return nil
+	}
+	return r.InteractionRepository.RecordInteraction(interaction)
+}
+
+type consentedAnalyticsRepository struct {
+	AnalyticsRepository
+	consents *ConsentChecker
+}
+
+// ConsentedAnalytics 只記錄同意統計分析的用戶的每週活躍；consents 為 nil 時直接回傳 repo
+func ConsentedAnalytics(repo AnalyticsRepository, consents *ConsentChecker) AnalyticsRepository {
+	if consents == nil {
+		return repo
+	}
+	return &consentedAnalyticsRepository{AnalyticsRepository: repo, consents: consents}
+}
+
+func (r *consentedAnalyticsRepository) RecordWeeklyActivity(activity models.WeeklyActivity) error {
+	if !r.consents.Allowed(activity.UserID, models.ConsentAnalytics) {
+		return nil
+	}
+	return r.AnalyticsRepository.RecordWeeklyActivity(activity)
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"errors"
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type memoryConsents struct {
+	UserConfigRepository
+	configs map[string]*models.UserConfig
+	err     error
+	loads   int
+}
+
+func (m *memoryConsents) GetUserConfig(userID string) (*models.UserConfig, error) {
+	m.loads++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.configs[userID], nil
+}
+
+func TestConsentChecker(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo := &memoryConsents{configs: map[string]*models.UserConfig{
+		"U1": {UserID: "U1", ConsentAnalytics: true, ConsentUpdatedAt: "2026-09-30T00:00:00Z"},
+		"U2": {UserID: "U2"},
+	}}
+	checker := NewConsentChecker(logrus.NewEntry(logrus.New()), repo, time.Minute)
+	checker.now = func() time.Time { return now }
+
+	if !checker.Allowed("U1", models.ConsentAnalytics) || checker.Allowed("U1", models.ConsentMarketing) {
+		t.Errorf("Expected U1 to consent to analytics only")
+	}
+	if checker.Allowed("U2", models.ConsentAnalytics) || checker.Allowed("U3", models.ConsentAnalytics) {
+		t.Errorf("Expected users who never answered, or without a config, not to consent")
+	}
+	if repo.loads != 3 {
+		t.Errorf("Expected one load per user within the ttl, got %d", repo.loads)
+	}
+
+	// 修改設定後清除快取，新設定立即生效
+	repo.configs["U1"] = &models.UserConfig{UserID: "U1", ConsentUpdatedAt: "2026-10-01T00:00:00Z"}
+	checker.Forget("U1")
+	if checker.Allowed("U1", models.ConsentAnalytics) {
+		t.Errorf("Expected the withdrawn consent after Forget")
+	}
+
+	repo.err = errors.New("throttled")
+	now = now.Add(2 * time.Minute)
+	if checker.Allowed("U2", models.ConsentAnalytics) {
+		t.Errorf("Expected no consent when the settings cannot be loaded")
+	}
+
+	var disabled *ConsentChecker
+	if !disabled.Allowed("U2", models.ConsentAIData) {
+		t.Errorf("Expected a nil checker to allow everything")
+	}
+}
+
+func TestConsentedInteractions(t *testing.T) {
+	repo := &recordingInteractions{}
+	consents := NewConsentChecker(logrus.NewEntry(logrus.New()), &memoryConsents{configs: map[string]*models.UserConfig{
+		"U1": {UserID: "U1", ConsentAnalytics: true},
+		"U2": {UserID: "U2", ConsentMarketing: true, ConsentAIData: true},
+	}}, time.Minute)
+	interactions := ConsentedInteractions(repo, consents)
+
+	for _, userID := range []string{"U1", "U2"} {
+		if err := interactions.RecordInteraction(models.Interaction{UserID: userID, Feature: models.FeatureOnboarding}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(repo.recorded) != 1 || repo.recorded[0].UserID != "U1" {
+		t.Errorf("Expected only the consenting user to be recorded, got %+v", repo.recorded)
+	}
+
+	if ConsentedInteractions(repo, nil) != InteractionRepository(repo) {
+		t.Errorf("Expected the repository to be returned unchanged without a checker")
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentReportSuppressEnv 設為 "true" 時，被回報的單字在審核完成前不會出現在每日單字中
+const ContentReportSuppressEnv = "CONTENT_REPORT_SUPPRESS"
+
+// LoadContentReportSuppress reports whether CONTENT_REPORT_SUPPRESS is enabled
+func LoadContentReportSuppress(getenv func(string) string) (bool, error) {
+	value := strings.ToLower(strings.TrimSpace(getenv(ContentReportSuppressEnv)))
+	switch value {
+	case "", "false", "0":
+		return false, nil
+	case "true", "1":
+		return true, nil
+	}
+	return false, fmt.Errorf("%s must be true or false, got %q", ContentReportSuppressEnv, value)
+}
+
+// contentReportReasonReserve 選擇原因時會在 postback data 後加上 reason，產生按鈕時先保留這段長度
+var contentReportReasonReserve = len("&reason=") + len(models.ContentReportInappropriate)
+
+// ContentReportPostbackData 產生「回報內容問題」按鈕的 postback data，帶上字卡內容讓回報保存用戶當下看到的版本；
+// 翻譯的 cacheKey 是用戶查詢原文的快取 key（TranslationCacheText），回報時據此清除快取，不在快取中或每日單字傳空字串。
+// 超過 LINE 的長度上限時捨棄例句，仍然太長時回傳空字串，不顯示按鈕
+func ContentReportPostbackData(kind, word, partOfSpeech, meaning, example, cacheKey string) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackContentReport)
+	values.Set("kind", kind)
+	values.Set("word", word)
+	if cacheKey != "" {
+		values.Set("cacheKey", cacheKey)
+	}
+	values.Set("pos", partOfSpeech)
+	values.Set("meaning", meaning)
+	values.Set("example", example)
+	if data := values.Encode(); len(data)+contentReportReasonReserve <= maxPostbackDataLength {
+		return data
+	}
+	values.Del("example")
+	if data := values.Encode(); len(data)+contentReportReasonReserve <= maxPostbackDataLength {
+		return data
+	}
+	return ""
+}
+
+// NewContentReport 由選擇原因後的 postback 建立回報；userConfig 可為 nil（例如群組中未加好友的成員）
+func NewContentReport(userID string, values url.Values, userConfig *models.UserConfig, suppress bool, now time.Time) (models.ContentReport, error) {
+	reason, err := models.ParseContentReportReason(values.Get("reason"))
+	if err != nil {
+		return models.ContentReport{}, err
+	}
+	kind := values.Get("kind")
+	if kind != models.ContentKindTranslation && kind != models.ContentKindDailyWord {
+		return models.ContentReport{}, fmt.Errorf("unknown content kind %q", kind)
+	}
+	word := strings.TrimSpace(values.Get("word"))
+	if word == "" {
+		return models.ContentReport{}, fmt.Errorf("content report without word")
+	}
+
+	lines := []string{word}
+	if pos := values.Get("pos"); pos != "" {
+		lines[0] += " (" + pos + ")"
+	}
+	for _, field := range []string{"meaning", "example"} {
+		if value := values.Get(field); value != "" {
+			lines = append(lines, value)
+		}
+	}
+
+	report := models.ContentReport{
+		// 時間在前讓佇列依回報時間排序，userId 避免同一瞬間的回報互相覆寫
+		ReportID:   strconv.FormatInt(now.UnixNano(), 10) + "-" + userID,
+		UserID:     userID,
+		Kind:       kind,
+		Word:       word,
+		CacheKey:   values.Get("cacheKey"),
+		Content:    strings.Join(lines, "\n"),
+		Reason:     reason,
+		Suppressed: suppress,
+		ReportedAt: now.UTC().Format(time.RFC3339),
+	}
+	if userConfig != nil {
+		report.Course = userConfig.Course
+		report.Level = userConfig.Level
+		report.Model = userConfig.Model
+	}
+	return report, nil
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContentReportPostbackData(t *testing.T) {
+	data := ContentReportPostbackData(models.ContentKindDailyWord, "itinerary", "n.", "行程", "Here is our itinerary.", "")
+	values, err := url.ParseQuery(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if values.Get("action") != models.PostbackContentReport || values.Get("kind") != models.ContentKindDailyWord || values.Get("example") != "Here is our itinerary." {
+		t.Errorf("Unexpected postback data: %v", values)
+	}
+
+	// 選擇原因時還要加上 reason，產生的 data 必須預留這段長度
+	long := ContentReportPostbackData(models.ContentKindTranslation, "itinerary", "n.", "行程", strings.Repeat("a long example ", 20), "Itinerary")
+	values, _ = url.ParseQuery(long)
+	if values.Get("example") != "" {
+		t.Errorf("Expected the long example to be dropped")
+	}
+	values.Set("reason", string(models.ContentReportInappropriate))
+	if len(values.Encode()) > maxPostbackDataLength {
+		t.Errorf("Expected room for the reason, got %d bytes", len(values.Encode()))
+	}
+
+	if data := ContentReportPostbackData(models.ContentKindTranslation, strings.Repeat("字", 100), "n.", "意思", "", ""); data != "" {
+		t.Errorf("Expected no button for an oversized card, got %q", data)
+	}
+}
+
+func TestNewContentReport(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	values, _ := url.ParseQuery(ContentReportPostbackData(models.ContentKindTranslation, "itinerary", "n.", "行程", "Here is our itinerary.", "Itinerary?"))
+
+	if _, err := NewContentReport("U1", values, nil, false, now); err == nil {
+		t.Errorf("Expected an error without a reason")
+	}
+
+	values.Set("reason", string(models.ContentReportIncorrect))
+	report, err := NewContentReport("U1", values, &models.UserConfig{Course: "toeic", Level: 600, Model: "gpt-4o"}, true, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 快取 key 是用戶查詢的原文，不是翻譯出的單字
+	if report.CacheKey != "Itinerary?" {
+		t.Errorf("Expected the cache key of the original input, got %q", report.CacheKey)
+	}
+	if report.Word != "itinerary" || report.Content != "itinerary (n.)\n行程\nHere is our itinerary." || report.Reason != models.ContentReportIncorrect {
+		t.Errorf("Unexpected report content %+v", report)
+	}
+	if report.Course != "toeic" || report.Model != "gpt-4o" || !report.Suppressed || !strings.HasSuffix(report.ReportID, "-U1") {
+		t.Errorf("Unexpected report context %+v", report)
+	}
+
+	values.Set("kind", "article")
+	if _, err := NewContentReport("U1", values, nil, false, now); err == nil {
+		t.Errorf("Expected an error for an unknown kind")
+	}
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField 是 cron 表達式中的一個欄位，any 表示 "*" 或 "?"
+type cronField struct {
+	any    bool
+	values map[int]bool
+}
+
+func (f cronField) match(value int) bool {
+	return f.any || f.values[value]
+}
+
+// cronFieldSpec 描述欄位的合法範圍與可用的名稱（月份、星期）
+type cronFieldSpec struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMinuteSpec  = cronFieldSpec{name: "minute", min: 0, max: 59}
+	cronHourSpec    = cronFieldSpec{name: "hour", min: 0, max: 23}
+	cronDaySpec     = cronFieldSpec{name: "day-of-month", min: 1, max: 31}
+	cronMonthSpec   = cronFieldSpec{name: "month", min: 1, max: 12, names: map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}}
+	cronWeekdaySpec = cronFieldSpec{name: "day-of-week", min: 1, max: 7, names: map[string]int{"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7}}
+	cronYearSpec    = cronFieldSpec{name: "year", min: 1970, max: 2199}
+)
+
+// CronSchedule is a parsed EventBridge cron expression evaluated in UTC
+type CronSchedule struct {
+	minute, hour, day, month, weekday, year cronField
+}
+
+// ParseCronExpression 解析 EventBridge 的 "cron(分 時 日 月 星期 年)"，支援 *、?、數字、名稱、逗號清單、範圍與 "/" 間隔；
+// L、W、# 等特殊字元不支援
+func ParseCronExpression(expression string) (*CronSchedule, error) {
+	inner := strings.TrimSpace(expression)
+	if !strings.HasPrefix(inner, "cron(") || !strings.HasSuffix(inner, ")") {
+		return nil, fmt.Errorf("invalid cron expression %q: must be cron(...)", expression)
+	}
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(inner, "cron("), ")"))
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 6 fields, got %d", expression, len(fields))
+	}
+	if (fields[2] == "?") == (fields[4] == "?") {
+		return nil, fmt.Errorf("invalid cron expression %q: exactly one of day-of-month and day-of-week must be ?", expression)
+	}
+
+	specs := []cronFieldSpec{cronMinuteSpec, cronHourSpec, cronDaySpec, cronMonthSpec, cronWeekdaySpec, cronYearSpec}
+	parsed := make([]cronField, len(fields))
+	for i, field := range fields {
+		f, err := parseCronField(field, specs[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+		}
+		parsed[i] = f
+	}
+
+	return &CronSchedule{
+		minute:  parsed[0],
+		hour:    parsed[1],
+		day:     parsed[2],
+		month:   parsed[3],
+		weekday: parsed[4],
+		year:    parsed[5],
+	}, nil
+}
+
+func parseCronField(field string, spec cronFieldSpec) (cronField, error) {
+	if field == "*" || field == "?" {
+		if field == "?" && spec.name != cronDaySpec.name && spec.name != cronWeekdaySpec.name {
+			return cronField{}, fmt.Errorf("? is only allowed in day fields, got %s %q", spec.name, field)
+		}
+		return cronField{any: true}, nil
+	}
+
+	values := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if base, stepText, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return cronField{}, fmt.Errorf("invalid %s step %q", spec.name, part)
+			}
+			rangePart, step = base, n
+		}
+
+		start, end := spec.min, spec.max
+		if rangePart != "*" {
+			low, high, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = spec.value(low); err != nil {
+				return cronField{}, err
+			}
+			end = start
+			if isRange {
+				if end, err = spec.value(high); err != nil {
+					return cronField{}, err
+				}
+			} else if step > 1 {
+				// "5/15" 表示從 5 開始每 15 一次
+				end = spec.max
+			}
+		}
+		if start > end {
+			return cronField{}, fmt.Errorf("invalid %s range %q", spec.name, part)
+		}
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return cronField{values: values}, nil
+}
+
+func (spec cronFieldSpec) value(text string) (int, error) {
+	if v, ok := spec.names[strings.ToUpper(text)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("invalid %s value %q", spec.name, text)
+	}
+	return v, nil
+}
+
+// Next 回傳 after 之後（不含）第一個符合的時間（UTC，精確到分鐘）；五年內都沒有符合時回傳 false
+func (c *CronSchedule) Next(after time.Time) (time.Time, bool) {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !c.year.match(t.Year()):
+			t = time.Date(t.Year()+1, 1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.month.match(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.day.match(t.Day()) || !c.weekday.match(int(t.Weekday())+1):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !c.hour.match(t.Hour()):
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !c.minute.match(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
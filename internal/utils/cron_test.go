@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	after := time.Date(2025, 6, 4, 10, 30, 0, 0, time.UTC) // 星期三
+
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"cron(0 0 * * ? *)", time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)},
+		{"cron(45 10 * * ? *)", time.Date(2025, 6, 4, 10, 45, 0, 0, time.UTC)},
+		{"cron(0 * * * ? *)", time.Date(2025, 6, 4, 11, 0, 0, 0, time.UTC)},
+		{"cron(30 0 ? * MON *)", time.Date(2025, 6, 9, 0, 30, 0, 0, time.UTC)},
+		{"cron(0/20 9-11 * * ? *)", time.Date(2025, 6, 4, 10, 40, 0, 0, time.UTC)},
+		{"cron(0 12 1 JAN,JUL ? 2026)", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+		// 剛好在觸發時間時回傳下一次
+		{"cron(30 10 * * ? *)", time.Date(2025, 6, 5, 10, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCronExpression(tt.expression)
+		if err != nil {
+			t.Fatalf("ParseCronExpression(%q) error: %v", tt.expression, err)
+		}
+		got, ok := schedule.Next(after)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%s Next = %v (%v), want %v", tt.expression, got, ok, tt.want)
+		}
+	}
+
+	schedule, _ := ParseCronExpression("cron(0 0 1 1 ? 2020)")
+	if _, ok := schedule.Next(after); ok {
+		t.Error("expected no upcoming time for a past year")
+	}
+}
+
+func TestParseCronExpressionInvalid(t *testing.T) {
+	for _, expression := range []string{
+		"rate(1 hour)",
+		"cron(0 0 * * *)",
+		"cron(0 0 * * * *)",
+		"cron(0 0 ? * ? *)",
+		"cron(60 0 * * ? *)",
+		"cron(0 ? * * ? *)",
+		"cron(0 5-3 * * ? *)",
+		"cron(0/0 0 * * ? *)",
+	} {
+		if _, err := ParseCronExpression(expression); err == nil {
+			t.Errorf("ParseCronExpression(%q) expected an error", expression)
+		}
+	}
+}
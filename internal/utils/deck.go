@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"errors"
+	"language-assistant/internal/models"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxDecks 每位用戶最多的清單數，翻譯結果下方最多只能放 13 個 quick reply 按鈕
+	MaxDecks = 10
+	// MaxDeckNameLength 清單名稱的字數上限，讓「加入「名稱」」仍在 quick reply 標籤的 20 字以內
+	MaxDeckNameLength = 12
+)
+
+// ErrInvalidDeckName is returned when a deck name is empty, too long or looks like a command
+var ErrInvalidDeckName = errors.New("invalid deck name")
+
+// ValidateDeckName 去除前後空白並檢查清單名稱：不可為空、不可超過 MaxDeckNameLength 字，也不可以 "/" 開頭以免與指令混淆
+func ValidateDeckName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.HasPrefix(name, "/") || utf8.RuneCountInString(name) > MaxDeckNameLength {
+		return "", ErrInvalidDeckName
+	}
+	return name, nil
+}
+
+// DeckAddPostbackData 產生把這次翻譯的單字加入清單的 postback data。單字依 date（UTC，與單字庫相同）
+// 從單字庫取回完整內容，因此只帶單字本身；超過 postback 長度上限的單字不會帶上
+func DeckAddPostbackData(deck, date string, words []string) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackDeckAdd)
+	values.Set("deck", deck)
+	values.Set("date", date)
+	data := values.Encode()
+	for _, word := range words {
+		values.Add("w", word)
+		encoded := values.Encode()
+		if len(encoded) > maxPostbackDataLength {
+			break
+		}
+		data = encoded
+	}
+	return data
+}
+
+// AddWordsToDeck 將 words 中指定的單字從單字庫紀錄加入清單，清單中已有的單字不會重複加入，回傳加入的數量。
+// 同一個單字有多筆紀錄時使用最新的一筆
+func AddWordsToDeck(deck *models.Deck, records []models.WordRecord, words []string, now time.Time) int {
+	existing := map[string]bool{}
+	for _, w := range deck.Words {
+		existing[NormalizeHistoryWord(w.Word)] = true
+	}
+
+	latest := map[string]models.WordRecord{}
+	for _, record := range records {
+		latest[NormalizeHistoryWord(record.Word)] = record
+	}
+
+	added := 0
+	for _, word := range words {
+		key := NormalizeHistoryWord(word)
+		record, ok := latest[key]
+		if !ok || existing[key] {
+			continue
+		}
+		deck.Words = append(deck.Words, models.DeckWord{
+			Word:         record.Word,
+			PartOfSpeech: record.PartOfSpeech,
+			Translation:  record.Translation,
+			Sentence:     record.Sentence,
+			AddedAt:      now.UTC().Format(time.RFC3339),
+		})
+		existing[key] = true
+		added++
+	}
+	return added
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateDeckName(t *testing.T) {
+	if name, err := ValidateDeckName("  旅遊英文 "); err != nil || name != "旅遊英文" {
+		t.Errorf("Expected trimmed name, got %q (err %v)", name, err)
+	}
+	for _, name := range []string{"", "   ", "/測驗", strings.Repeat("字", MaxDeckNameLength+1)} {
+		if _, err := ValidateDeckName(name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestDeckAddPostbackData(t *testing.T) {
+	values, err := url.ParseQuery(DeckAddPostbackData("旅遊英文", "2026-10-18", []string{"boarding pass", "luggage"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if values.Get("action") != models.PostbackDeckAdd || values.Get("deck") != "旅遊英文" || values.Get("date") != "2026-10-18" || strings.Join(values["w"], ",") != "boarding pass,luggage" {
+		t.Errorf("Unexpected postback data: %v", values)
+	}
+
+	words := make([]string, 50)
+	for i := range words {
+		words[i] = "extraordinarily"
+	}
+	if data := DeckAddPostbackData(strings.Repeat("字", MaxDeckNameLength), "2026-10-18", words); len(data) > maxPostbackDataLength {
+		t.Errorf("Expected postback data within %d chars, got %d", maxPostbackDataLength, len(data))
+	}
+}
+
+func TestAddWordsToDeck(t *testing.T) {
+	deck := &models.Deck{Words: []models.DeckWord{{Word: "Luggage"}}}
+	records := []models.WordRecord{
+		{Word: "passport", Translation: "舊翻譯"},
+		{Word: "luggage", Translation: "行李"},
+		{Word: "passport", Translation: "護照"},
+	}
+
+	added := AddWordsToDeck(deck, records, []string{"passport", "luggage", "visa"}, time.Now())
+	if added != 1 || len(deck.Words) != 2 {
+		t.Fatalf("Expected only passport to be added, got %d (%+v)", added, deck.Words)
+	}
+	if deck.Words[1].Translation != "護照" {
+		t.Errorf("Expected the latest record to be used, got %q", deck.Words[1].Translation)
+	}
+}
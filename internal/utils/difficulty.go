@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+)
+
+// DifficultyBand groups CEFR levels into the three colours shown on word cards
+type DifficultyBand int
+
+const (
+	DifficultyUnknown DifficultyBand = iota
+	DifficultyEasy                   // A1, A2
+	DifficultyMedium                 // B1, B2
+	DifficultyHard                   // C1, C2
+)
+
+var cefrBands = map[string]DifficultyBand{
+	"A1": DifficultyEasy,
+	"A2": DifficultyEasy,
+	"B1": DifficultyMedium,
+	"B2": DifficultyMedium,
+	"C1": DifficultyHard,
+	"C2": DifficultyHard,
+}
+
+// cefrLevels CEFR 等級由易到難
+var cefrLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// difficultyTolerance 每日單字與目標難度最多相差的等級數，對應 prompt 中「可以混雜再難一個階級」
+const difficultyTolerance = 1
+
+var difficultyEmoji = map[DifficultyBand]string{
+	DifficultyEasy:   "🟢",
+	DifficultyMedium: "🟡",
+	DifficultyHard:   "🔴",
+}
+
+// NormalizeCEFR 將模型回傳的難度（例如 "b2"、"CEFR B2"）轉成標準 CEFR 等級，無法辨識時回傳空字串
+func NormalizeCEFR(level string) string {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	level = strings.TrimSpace(strings.TrimPrefix(level, "CEFR"))
+	if _, ok := cefrBands[level]; ok {
+		return level
+	}
+	return ""
+}
+
+// DifficultyOf returns the colour band for a CEFR level
+func DifficultyOf(cefr string) DifficultyBand {
+	return cefrBands[NormalizeCEFR(cefr)]
+}
+
+// TargetCEFR 回傳每日單字的目標 CEFR 等級：課程分數對應的級距（LevelBand）加上用戶的難度調整（UserConfig.DifficultyOffset），
+// 超出 A1～C2 時取邊界；分數尚未設定時回傳空字串
+func TargetCEFR(course string, level, offset int) string {
+	band := LevelBand(course, level)
+	if band == "" {
+		return ""
+	}
+	index := min(max(slices.Index(cefrLevels, band)+offset, 0), len(cefrLevels)-1)
+	return cefrLevels[index]
+}
+
+// WithinDifficulty 回傳單字難度與目標相差是否不超過 difficultyTolerance；任一方無法辨識時不過濾
+func WithinDifficulty(difficulty, target string) bool {
+	got, want := slices.Index(cefrLevels, NormalizeCEFR(difficulty)), slices.Index(cefrLevels, NormalizeCEFR(target))
+	if got < 0 || want < 0 {
+		return true
+	}
+	return max(got-want, want-got) <= difficultyTolerance
+}
+
+// SplitByDifficulty 將單字依是否符合目標難度分成兩組，保留原本的順序
+func SplitByDifficulty(words []Word, target string) (inBand, outOfBand []Word) {
+	for _, word := range words {
+		if WithinDifficulty(word.Difficulty, target) {
+			inBand = append(inBand, word)
+		} else {
+			outOfBand = append(outOfBand, word)
+		}
+	}
+	return inBand, outOfBand
+}
+
+// Emoji 回傳難度對應的燈號，未知難度回傳空字串
+func (b DifficultyBand) Emoji() string {
+	return difficultyEmoji[b]
+}
+
+// FormatExamTags 將考試標籤格式化為 "#TOEIC #IELTS"，並去除重複與空白
+func FormatExamTags(tags []string) string {
+	seen := make(map[string]bool, len(tags))
+	var formatted []string
+	for _, tag := range tags {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		key := strings.ToUpper(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		formatted = append(formatted, "#"+tag)
+	}
+	return strings.Join(formatted, " ")
+}
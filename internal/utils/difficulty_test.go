@@ -0,0 +1,69 @@
+package utils
+
+import "testing"
+
+func TestDifficultyOf(t *testing.T) {
+	tests := []struct {
+		level    string
+		expected DifficultyBand
+		emoji    string
+	}{
+		{"A2", DifficultyEasy, "🟢"},
+		{" b1 ", DifficultyMedium, "🟡"},
+		{"CEFR C1", DifficultyHard, "🔴"},
+		{"advanced", DifficultyUnknown, ""},
+		{"", DifficultyUnknown, ""},
+	}
+
+	for _, tt := range tests {
+		band := DifficultyOf(tt.level)
+		if band != tt.expected {
+			t.Errorf("DifficultyOf(%q) = %v, expected %v", tt.level, band, tt.expected)
+		}
+		if band.Emoji() != tt.emoji {
+			t.Errorf("DifficultyOf(%q).Emoji() = %q, expected %q", tt.level, band.Emoji(), tt.emoji)
+		}
+	}
+}
+
+func TestFormatExamTags(t *testing.T) {
+	got := FormatExamTags([]string{"TOEIC", " #toeic", "IELTS Writing", ""})
+	expected := "#TOEIC #IELTS Writing"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestTargetCEFR(t *testing.T) {
+	tests := []struct {
+		course string
+		level  int
+		offset int
+		want   string
+	}{
+		{"toeic", 600, 0, "B1"},
+		{"toeic", 600, 1, "B2"},
+		{"toeic", 950, 2, "C2"},
+		{"toeic", 300, -2, "A1"},
+		{"toeic", 0, 1, ""},
+	}
+	for _, tt := range tests {
+		if got := TargetCEFR(tt.course, tt.level, tt.offset); got != tt.want {
+			t.Errorf("TargetCEFR(%q, %d, %d) = %q, want %q", tt.course, tt.level, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestSplitByDifficulty(t *testing.T) {
+	words := []Word{{Word: "office", Difficulty: "A1"}, {Word: "client", Difficulty: "A2"}, {Word: "revenue", Difficulty: "B2"}, {Word: "paradigm", Difficulty: "C2"}, {Word: "budget"}}
+	inBand, outOfBand := SplitByDifficulty(words, "B1")
+	if len(inBand) != 3 || inBand[0].Word != "client" || inBand[1].Word != "revenue" || inBand[2].Word != "budget" {
+		t.Errorf("Expected client, revenue and the unrated budget within B1, got %v", inBand)
+	}
+	if len(outOfBand) != 2 || outOfBand[0].Word != "office" || outOfBand[1].Word != "paradigm" {
+		t.Errorf("Expected office and paradigm outside B1, got %v", outOfBand)
+	}
+	if inBand, _ := SplitByDifficulty(words, ""); len(inBand) != len(words) {
+		t.Errorf("Expected no filtering without a target, got %v", inBand)
+	}
+}
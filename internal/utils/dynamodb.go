@@ -2,24 +2,42 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"language-assistant/internal/models"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
 
+// ErrUserNotFound is returned when updating a user that has no config record
+var ErrUserNotFound = errors.New("user not found")
+
 // DynamoDbAPI defines the DynamoDB operations needed by our application
 type DynamoDbAPI interface {
 	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
 	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
 	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
 }
 
+// Repository implementations (NewXRepository) only hold the logger, client and table name,
+// so they are safe for concurrent use; consistency between concurrent writers relies on DynamoDB conditions
+
 // VocabularyRepository defines vocabulary-related database operations
 type VocabularyRepository interface {
-	SaveWord(word, partOfSpeech, translation, sentence, userID string) error
+	SaveWord(word, partOfSpeech, translation, sentence, source, userID string, expiresAt time.Time) error
+	SaveGroupWord(groupID, userID, word, partOfSpeech, translation, sentence string, expiresAt time.Time) error
 	GetUserVocabularyByDate(userID, date string) (*models.UserVocabulary, error)
 	GetAllUserVocabularies(userID string) ([]models.UserVocabulary, error)
+	GetUserVocabulariesPage(userID string, limit int, startDate string) ([]models.UserVocabulary, string, error)
+	GetUserVocabulariesBetween(userID, fromDate, toDate string) ([]models.UserVocabulary, error)
+	SetVocabularyExpiry(userID string, expiresAt time.Time) (int, error)
+	GetWordOccurrences(userID, word string) ([]models.WordOccurrence, error)
+	UpdateWordSentence(userID, date, word, sentence string) (bool, error)
 }
 
 // ReminderRepository defines reminder-related database operations
@@ -32,6 +50,36 @@ type UserConfigRepository interface {
 	SaveUserConfig(userID, displayName, course string, level int, dailyWords int, pushTime, timezone string) error
 	GetUserConfig(userID string) (*models.UserConfig, error)
 	GetUsersByCourse(course string) ([]models.UserConfig, error)
+	GetUsersByPushTime(pushTime string) ([]models.UserConfig, error)
+	SetScheduleName(userID, scheduleName string) error
+	SetFanoutPush(userID string) error
+	GetUserConfigByScheduleName(scheduleName string) (*models.UserConfig, error)
+	SetProfileSlug(userID, profileSlug string) error
+	GetUserConfigByProfileSlug(profileSlug string) (*models.UserConfig, error)
+	SetCardFormat(userID string, cardFormat models.CardFormat) error
+	SetLowerBandWords(userID, policy string) error
+	SetReviewSource(userID, source string) error
+	SetPronunciationAudio(userID string, enabled bool) error
+	SetGrammarMode(userID string, enabled bool) error
+	SetInteractiveReview(userID string, enabled bool) error
+	SetTranslationOnly(userID string, enabled bool) error
+	SetModel(userID, model string) error
+	SetLineDestination(userID, destination string) error
+	SetDifficultyOffset(userID string, offset int) error
+	TouchLastActive(userID string, at time.Time) (string, error)
+	RequestBeta(userID string) (bool, error)
+	SetBetaStatus(userID string, status models.BetaStatus) error
+	SetPlan(userID string, plan models.Plan) error
+	GetUsersByBetaStatus(status models.BetaStatus) ([]models.UserConfig, error)
+	SetWebhookCapture(userID string, until time.Time) error
+	DeactivateUser(userID string, at time.Time) error
+	ReactivateUser(userID string) error
+	RecordBlockedPush(userID string) (int, error)
+	ResetBlockedPushes(userID string) error
+	SetConsents(userID string, consents map[models.ConsentType]bool, at time.Time) error
+	GetUsersWithConsent(consent models.ConsentType) ([]models.UserConfig, error)
+	AcceptTerms(userID, version string, at time.Time) error
+	MarkTermsPrompted(userID, version string) (bool, error)
 }
 
 // BloomFilterRepository defines Bloom Filter related database operations
@@ -41,4 +89,197 @@ type BloomFilterRepository interface {
 	AddWordToBloomFilter(userID, word, course string) error
 	FilterWords(userID, course string, words []Word) ([]Word, error)
 	AddWordsToBloomFilter(userID, course string, words []Word) error
-}
\ No newline at end of file
+	RemoveWordFromBloomFilter(userID, word, course string) (bool, error)
+	ClearBloomFilter(userID, course string) error
+}
+// ScheduleAuditRepository defines audit log operations for EventBridge schedules
+type ScheduleAuditRepository interface {
+	RecordScheduleOperation(entry models.ScheduleAuditEntry) error
+	GetScheduleAuditLog(userID string, limit int) ([]models.ScheduleAuditEntry, error)
+}
+
+// PushLogRepository defines daily push log database operations
+type PushLogRepository interface {
+	SavePushLog(log models.PushLog) error
+	GetRecentPushLogs(userID string, days int) ([]models.PushLog, error)
+	GetPushLogsByDate(userID, date string) ([]models.PushLog, error)
+	GetAllPushLogs(userID string) ([]models.PushLog, error)
+	ClaimScheduledPush(userID, date string, expiresAt int64) (bool, error)
+	ReleaseScheduledPush(userID, date string) error
+}
+
+// PairingRepository defines web dashboard pairing code and session operations
+type PairingRepository interface {
+	SavePairingCode(code models.PairingCode) error
+	ConsumePairingCode(code string) (*models.PairingCode, error)
+	SaveWebSession(session models.WebSession) error
+	GetWebSession(tokenHash string) (*models.WebSession, error)
+}
+
+// PromptRolloutRepository defines staged prompt rollout configuration and metrics operations
+type PromptRolloutRepository interface {
+	GetPromptRollout(prompt string) (*models.PromptRollout, error)
+	SavePromptRollout(rollout models.PromptRollout) error
+	EndPromptRollout(prompt, rolloutID, status, reason string) (bool, error)
+	RecordPromptOutcome(prompt, rolloutID, version string, parseFailed bool) error
+	GetPromptMetrics(prompt, rolloutID string) (map[string]models.PromptMetrics, error)
+}
+
+// CardFormatExperimentRepository defines text vs image card A/B metrics operations
+type CardFormatExperimentRepository interface {
+	RecordCardFormatPush(date string, format models.CardFormat) error
+	RecordCardFormatEngagement(date string, format models.CardFormat) error
+	GetCardFormatMetrics(fromDate, toDate string) ([]models.CardFormatMetrics, error)
+}
+
+// AnalyticsRepository defines weekly activity and retention report operations
+type AnalyticsRepository interface {
+	RecordWeeklyActivity(activity models.WeeklyActivity) error
+	GetWeeklyActivity(week string) ([]models.WeeklyActivity, error)
+	SaveCohortRetention(retention models.CohortRetention) error
+	GetCohortRetention(limit int) ([]models.CohortRetention, error)
+}
+
+// InteractionRepository defines postback / quick reply interaction event operations
+type InteractionRepository interface {
+	RecordInteraction(interaction models.Interaction) error
+	GetInteractionsByDate(date string) ([]models.Interaction, error)
+}
+
+// SupportTicketRepository defines per-user failure tracking and support ticket operations
+type SupportTicketRepository interface {
+	RecordUserFailure(failure models.UserFailure) error
+	GetUserFailuresSince(userID string, since time.Time) ([]models.UserFailure, error)
+	OpenSupportTicket(ticket models.SupportTicket) (bool, error)
+	GetOpenSupportTickets() ([]models.SupportTicket, error)
+	ResolveSupportTicket(userID string) (bool, error)
+}
+
+// ContentReportRepository defines the moderation queue for reported AI-generated content, and the words
+// suppressed from daily pushes until their report is reviewed
+type ContentReportRepository interface {
+	OpenContentReport(report models.ContentReport) error
+	GetOpenContentReports() ([]models.ContentReport, error)
+	ResolveContentReport(reportID string) (*models.ContentReport, error)
+	SuppressWord(word, reportID string) error
+	UnsuppressWord(word, reportID string) (bool, error)
+	GetSuppressedWords() (map[string]bool, error)
+}
+
+// SetupDraftRepository defines the in-progress push settings choices kept between setup steps
+type SetupDraftRepository interface {
+	GetSetupDraft(userID string) (*models.SetupDraft, error)
+	UpdateSetupDraft(draft models.SetupDraft) error
+	DeleteSetupDraft(userID string) error
+}
+
+// TranslationCacheRepository defines the shared cache of translations for short inputs
+type TranslationCacheRepository interface {
+	GetCachedTranslation(text, variant string) (*models.TranslationCacheEntry, error)
+	PutCachedTranslation(entry models.TranslationCacheEntry) error
+	DeleteCachedTranslations(text string) error
+}
+
+// UsageRepository defines per-user daily usage counters used by the quota limiter, and the daily OpenAI token
+// usage aggregates used by the cost report
+type UsageRepository interface {
+	IncrementDailyUsage(userID, feature, date string, expiresAt time.Time) (int, error)
+	AddTokenUsage(usage models.TokenUsage, expiresAt time.Time) error
+	GetTokenUsage(date string) ([]models.TokenUsage, error)
+}
+
+// RateLimiterRepository defines the per-user token buckets used by the rate limiter; SaveBucket is a conditional
+// write on the bucket version so concurrent requests cannot both spend the same token
+type RateLimiterRepository interface {
+	GetBucket(userID, feature string) (*models.RateLimitBucket, error)
+	SaveBucket(userID, feature string, bucket models.RateLimitBucket, expiresAt time.Time) (bool, error)
+}
+
+// PaymentRepository defines storage for payments and the receipts of confirmed payments
+type PaymentRepository interface {
+	SavePayment(payment models.Payment) error
+	GetPayment(orderID string) (*models.Payment, error)
+	UpdatePaymentStatus(orderID string, from, to models.PaymentStatus, updatedAt string) (bool, error)
+	SavePaymentReceipt(receipt models.PaymentReceipt) error
+	GetPaymentReceipts(userID string) ([]models.PaymentReceipt, error)
+}
+
+// KillSwitchRepository defines storage for operator-controlled kill switches
+type KillSwitchRepository interface {
+	GetKillSwitches() (map[models.KillSwitch]models.KillSwitchState, error)
+	SaveKillSwitch(state models.KillSwitchState) error
+}
+
+// SubscriptionRepository defines storage for time-boxed trials of premium features
+type SubscriptionRepository interface {
+	StartTrial(trial models.Trial) (bool, error)
+	GetTrial(userID string, feature models.PremiumFeature) (*models.Trial, error)
+	GetActiveTrials() ([]models.Trial, error)
+	MarkTrialEndingNotified(userID string, feature models.PremiumFeature) error
+	ExpireTrial(userID string, feature models.PremiumFeature, expiredAt string) (bool, error)
+}
+
+// ParseFailureRepository defines storage for sampled OpenAI responses that failed to parse
+type ParseFailureRepository interface {
+	SaveParseFailure(failure models.ParseFailure) error
+	GetRecentParseFailures(feature string, limit int) ([]models.ParseFailure, error)
+}
+
+// QuizRepository defines quiz session database operations
+type QuizRepository interface {
+	SaveQuizSession(session models.QuizSession) error
+	GetQuizSession(userID, sessionID string) (*models.QuizSession, error)
+	GetQuizSessions(userID string) ([]models.QuizSession, error)
+	RecordWordResult(userID, word string, correct bool) error
+	GetMasteredWords(userID string) (map[string]bool, error)
+}
+
+// DeckRepository defines user word list (deck) database operations
+type DeckRepository interface {
+	CreateDeck(userID, name string) (bool, error)
+	GetDeck(userID, name string) (*models.Deck, error)
+	GetDecks(userID string) ([]models.Deck, error)
+	SaveDeck(deck models.Deck) error
+}
+
+// ReviewRepository defines interactive nightly review and spaced-repetition database operations
+type ReviewRepository interface {
+	SaveReviewSession(session models.ReviewSession) error
+	GetReviewSession(userID, date string) (*models.ReviewSession, error)
+	GetReviewRecord(userID, word string) (*models.ReviewRecord, error)
+	GetReviewRecords(userID string) (map[string]models.ReviewRecord, error)
+	SaveReviewRecord(userID string, record models.ReviewRecord) error
+}
+
+// WordHistoryRepository defines exact pushed-word history database operations
+type WordHistoryRepository interface {
+	RecordPushedWords(userID, course, band string, words []string, pushedAt time.Time) error
+	GetPushedWords(userID, course, minBand string, limit int) (map[string]bool, bool, error)
+	ClearPushedWords(userID, course string) (int, error)
+	RemovePushedWord(userID, course, word string) error
+}
+
+// WebhookEventRepository defines LINE webhook event dedup database operations
+type WebhookEventRepository interface {
+	ClaimWebhookEvent(event models.WebhookEvent) (bool, error)
+	ReleaseWebhookEvent(eventID string) error
+}
+
+// StreakRepository defines consecutive-day learning streak database operations
+type StreakRepository interface {
+	GetStreak(userID string) (*models.Streak, error)
+	SaveStreak(streak models.Streak) error
+	AddPendingMilestone(milestone models.StreakMilestone) error
+	GetPendingMilestones() ([]models.StreakMilestone, error)
+	DeletePendingMilestone(userID string) error
+}
+
+// MigrationCheckpointPrefix 是 migration 進度在 vocabulary table 的 pk 前綴，掃描該 table 的 migration 會略過這些 item
+const MigrationCheckpointPrefix = "migration#"
+
+// MigrationCheckpointRepository defines migration progress database operations
+type MigrationCheckpointRepository interface {
+	GetMigrationCheckpoint(migrationID string) (*models.MigrationCheckpoint, error)
+	SaveMigrationCheckpoint(checkpoint models.MigrationCheckpoint) error
+	DeleteMigrationCheckpoint(migrationID string) error
+}
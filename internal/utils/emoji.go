@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+// 回覆貼圖時使用 LINE 官方的免費貼圖（Brown & Cony 的揮手貼圖），用戶不需要擁有這組貼圖也能看到
+const (
+	ReplyStickerPackageID = "446"
+	ReplyStickerID        = "1988"
+)
+
+// StripLineEmojis 移除文字中的 LINE emoji；LINE 以替代文字（例如 "(love)"）表示 emoji，
+// emojis 的位置與長度以 UTF-16 code unit 計算
+func StripLineEmojis(text string, emojis []*linebot.Emoji) string {
+	if len(emojis) == 0 {
+		return text
+	}
+	units := utf16.Encode([]rune(text))
+	removed := make([]bool, len(units))
+	for _, emoji := range emojis {
+		for i := emoji.Index; i < emoji.Index+emoji.Length && i < len(units); i++ {
+			if i >= 0 {
+				removed[i] = true
+			}
+		}
+	}
+
+	kept := make([]uint16, 0, len(units))
+	for i, unit := range units {
+		if !removed[i] {
+			kept = append(kept, unit)
+		}
+	}
+	return string(utf16.Decode(kept))
+}
+
+// IsEmojiOnly reports whether a text message has nothing to translate: it contains at least one emoji (Unicode or
+// LINE emoji) and otherwise only whitespace, punctuation and the joiners and modifiers emoji sequences use
+func IsEmojiOnly(text string, emojis []*linebot.Emoji) bool {
+	hasEmoji := len(emojis) > 0
+	for _, r := range StripLineEmojis(text, emojis) {
+		switch {
+		case unicode.Is(unicode.So, r):
+			hasEmoji = true
+		case unicode.IsSpace(r), unicode.IsPunct(r),
+			r == '\u200d', // zero width joiner
+			unicode.Is(unicode.Variation_Selector, r),
+			unicode.Is(unicode.Sk, r), // 膚色修飾（U+1F3FB～U+1F3FF）
+			unicode.Is(unicode.Me, r): // keycap（U+20E3）
+		default:
+			return false
+		}
+	}
+	return hasEmoji
+}
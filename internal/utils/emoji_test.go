@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+func TestIsEmojiOnly(t *testing.T) {
+	tests := []struct {
+		text   string
+		emojis []*linebot.Emoji
+		want   bool
+	}{
+		{"😂", nil, true},
+		{"👍🏻 !!", nil, true},
+		{"👨‍👩‍👧 ❤️", nil, true},
+		{"(love)(love)", []*linebot.Emoji{{Index: 0, Length: 6}, {Index: 6, Length: 6}}, true},
+		{"好 (love)", []*linebot.Emoji{{Index: 2, Length: 6}}, false},
+		{"apple 🍎", nil, false},
+		{"謝謝😊", nil, false},
+		{"...", nil, false},
+		{"", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsEmojiOnly(tt.text, tt.emojis); got != tt.want {
+			t.Errorf("IsEmojiOnly(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStripLineEmojis(t *testing.T) {
+	// emoji 的位置以 UTF-16 計算，前面的 😀 佔兩個 code unit
+	got := StripLineEmojis("😀(moon) hi", []*linebot.Emoji{{Index: 2, Length: 6}})
+	if got != "😀 hi" {
+		t.Errorf("Expected the LINE emoji to be removed, got %q", got)
+	}
+}
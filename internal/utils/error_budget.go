@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultErrorBudgetFailures = 3
+	defaultErrorBudgetWindow   = 24 * time.Hour
+)
+
+// ErrorBudget is the number of failures a single user may hit within Window before a support ticket is opened
+type ErrorBudget struct {
+	MaxFailures int
+	Window      time.Duration
+}
+
+// LoadErrorBudget reads ERROR_BUDGET_MAX_FAILURES and ERROR_BUDGET_WINDOW (Go duration, e.g. "12h"),
+// falling back to 3 failures per 24 hours when unset
+func LoadErrorBudget(getenv func(string) string) (ErrorBudget, error) {
+	budget := ErrorBudget{MaxFailures: defaultErrorBudgetFailures, Window: defaultErrorBudgetWindow}
+
+	if value := getenv("ERROR_BUDGET_MAX_FAILURES"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return ErrorBudget{}, fmt.Errorf("invalid ERROR_BUDGET_MAX_FAILURES %q: must be a positive integer", value)
+		}
+		budget.MaxFailures = parsed
+	}
+
+	if value := getenv("ERROR_BUDGET_WINDOW"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return ErrorBudget{}, fmt.Errorf("invalid ERROR_BUDGET_WINDOW %q: must be a positive duration", value)
+		}
+		budget.Window = parsed
+	}
+
+	return budget, nil
+}
+
+// Exhausted reports whether the failures inside the window have used up the budget
+func (b ErrorBudget) Exhausted(failures []models.UserFailure, now time.Time) bool {
+	since := now.Add(-b.Window)
+	count := 0
+	for _, failure := range failures {
+		occurredAt, err := time.Parse(time.RFC3339Nano, failure.OccurredAt)
+		if err != nil || occurredAt.Before(since) {
+			continue
+		}
+		count++
+	}
+	return count >= b.MaxFailures
+}
+
+// CorrelationIDs 回傳失敗紀錄中不重複的 correlation ID（依原本順序），開 ticket 時附上方便對照 log
+func CorrelationIDs(failures []models.UserFailure) []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, failure := range failures {
+		if failure.CorrelationID == "" || seen[failure.CorrelationID] {
+			continue
+		}
+		seen[failure.CorrelationID] = true
+		ids = append(ids, failure.CorrelationID)
+	}
+	return ids
+}
+
+// FailureReporter records user-facing failures and opens a support ticket once a user exhausts the error budget,
+// so repeated failures reach ops instead of the user silently churning. Safe for concurrent use
+type FailureReporter struct {
+	logger *logrus.Entry
+	repo   SupportTicketRepository
+	budget ErrorBudget
+}
+
+func NewFailureReporter(logger *logrus.Entry, repo SupportTicketRepository, budget ErrorBudget) *FailureReporter {
+	return &FailureReporter{
+		logger: logger,
+		repo:   repo,
+		budget: budget,
+	}
+}
+
+// Report 記錄一次失敗並檢查 error budget；寫入失敗只記 log，不影響原本的錯誤處理流程
+func (f *FailureReporter) Report(userID, source, correlationID string, failureErr error) {
+	if userID == "" || failureErr == nil {
+		return
+	}
+	now := time.Now().UTC()
+
+	failure := models.UserFailure{
+		UserID:        userID,
+		Source:        source,
+		CorrelationID: correlationID,
+		Error:         failureErr.Error(),
+		OccurredAt:    now.Format(time.RFC3339Nano),
+	}
+	if err := f.repo.RecordUserFailure(failure); err != nil {
+		f.logger.WithError(err).WithField("userId", userID).Warn("Failed to record user failure")
+		return
+	}
+
+	failures, err := f.repo.GetUserFailuresSince(userID, now.Add(-f.budget.Window))
+	if err != nil {
+		f.logger.WithError(err).WithField("userId", userID).Warn("Failed to get user failures")
+		return
+	}
+	if !f.budget.Exhausted(failures, now) {
+		return
+	}
+
+	ticket := models.SupportTicket{
+		UserID:         userID,
+		CorrelationIDs: CorrelationIDs(failures),
+		Failures:       failures,
+		OpenedAt:       now.Format(time.RFC3339),
+	}
+	opened, err := f.repo.OpenSupportTicket(ticket)
+	if err != nil {
+		f.logger.WithError(err).WithField("userId", userID).Warn("Failed to open support ticket")
+		return
+	}
+	if !opened {
+		// 已有未處理的 ticket，不重複通知
+		return
+	}
+
+	f.logger.WithFields(logrus.Fields{
+		"alert":          "support_ticket",
+		"userId":         userID,
+		"failures":       len(failures),
+		"correlationIds": ticket.CorrelationIDs,
+	}).Warn("User exhausted error budget, opened support ticket")
+}
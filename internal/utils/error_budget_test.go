@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestErrorBudgetExhausted(t *testing.T) {
+	budget := ErrorBudget{MaxFailures: 3, Window: 24 * time.Hour}
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	failureAt := func(ago time.Duration, correlationID string) models.UserFailure {
+		return models.UserFailure{CorrelationID: correlationID, OccurredAt: now.Add(-ago).Format(time.RFC3339Nano)}
+	}
+
+	failures := []models.UserFailure{
+		failureAt(30*time.Hour, "req-old"), // 超出 window
+		failureAt(3*time.Hour, "req-1"),
+		failureAt(time.Hour, "req-2"),
+	}
+	if budget.Exhausted(failures, now) {
+		t.Errorf("Failures outside the window should not count toward the budget")
+	}
+
+	failures = append(failures, failureAt(time.Minute, "req-2"))
+	if !budget.Exhausted(failures, now) {
+		t.Errorf("Expected budget to be exhausted after 3 failures within the window")
+	}
+
+	if got, want := CorrelationIDs(failures), []string{"req-old", "req-1", "req-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CorrelationIDs() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadErrorBudget(t *testing.T) {
+	budget, err := LoadErrorBudget(func(string) string { return "" })
+	if err != nil || budget.MaxFailures != 3 || budget.Window != 24*time.Hour {
+		t.Errorf("Expected defaults, got %+v (err %v)", budget, err)
+	}
+
+	if _, err := LoadErrorBudget(func(key string) string {
+		if key == "ERROR_BUDGET_MAX_FAILURES" {
+			return "0"
+		}
+		return ""
+	}); err == nil {
+		t.Errorf("Expected error for non-positive ERROR_BUDGET_MAX_FAILURES")
+	}
+}
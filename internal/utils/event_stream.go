@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"language-assistant/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/firehose/types"
+	"github.com/sirupsen/logrus"
+)
+
+// 串流到資料倉儲的事件類型；Firehose 依 eventType 與 eventTime 的 UTC 日期分區寫入 S3
+const (
+	StreamEventInteraction = "interaction"
+	StreamEventPush        = "push"
+)
+
+// streamEnvelope 是每個事件共用的欄位，與事件本身的欄位攤平在同一層 JSON，方便 Athena 直接查詢
+type streamEnvelope struct {
+	EventType string `json:"eventType"`
+	EventTime string `json:"eventTime"` // RFC3339（UTC），分區日期取前 10 個字元
+}
+
+type interactionStreamEvent struct {
+	streamEnvelope
+	models.Interaction
+}
+
+type pushStreamEvent struct {
+	streamEnvelope
+	models.PushLog
+}
+
+// EventStream sends interaction and push events to a Kinesis Data Firehose delivery stream, which batches them
+// into partitioned JSON on S3 for Athena/QuickSight. Streaming is best-effort: failures are logged and never
+// affect the caller. A nil stream (EVENT_STREAM_NAME unset) drops every event. User IDs are hashed when anonymizer
+// is set (anonymized mode). Safe for concurrent use
+type EventStream struct {
+	logger     *logrus.Entry
+	client     FirehoseAPI
+	streamName string
+	anonymizer *Anonymizer
+	now        func() time.Time
+}
+
+func NewEventStream(logger *logrus.Entry, client FirehoseAPI, streamName string, anonymizer *Anonymizer) *EventStream {
+	return &EventStream{
+		logger:     logger,
+		client:     client,
+		streamName: streamName,
+		anonymizer: anonymizer,
+		now:        time.Now,
+	}
+}
+
+// PublishInteraction 串流一次按鈕點擊
+func (s *EventStream) PublishInteraction(interaction models.Interaction) {
+	if s == nil {
+		return
+	}
+	envelope := s.envelope(StreamEventInteraction)
+	interaction.UserID = s.anonymizer.UserID(interaction.UserID, s.now())
+	if interaction.OccurredAt == "" {
+		interaction.OccurredAt = envelope.EventTime
+	}
+	s.publish(interactionStreamEvent{streamEnvelope: envelope, Interaction: interaction})
+}
+
+// PublishPush 串流一次每日單字推播的結果；推播的訊息內容不寫入資料倉儲
+func (s *EventStream) PublishPush(pushLog models.PushLog) {
+	if s == nil {
+		return
+	}
+	pushLog.Message = ""
+	pushLog.UserID = s.anonymizer.UserID(pushLog.UserID, s.now())
+	s.publish(pushStreamEvent{streamEnvelope: s.envelope(StreamEventPush), PushLog: pushLog})
+}
+
+func (s *EventStream) envelope(eventType string) streamEnvelope {
+	return streamEnvelope{EventType: eventType, EventTime: s.now().UTC().Format(time.RFC3339)}
+}
+
+func (s *EventStream) publish(event interface{}) {
+	if err := s.put(event); err != nil {
+		s.logger.WithError(err).Warn("Failed to stream analytics event")
+	}
+}
+
+func (s *EventStream) put(event interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal analytics event: %w", err)
+	}
+	// 每筆事件一行，S3 上的檔案即為 JSON Lines
+	_, err = s.client.PutRecord(context.Background(), &firehose.PutRecordInput{
+		DeliveryStreamName: aws.String(s.streamName),
+		Record:             &types.Record{Data: append(data, '\n')},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put analytics event: %w", err)
+	}
+	return nil
+}
+
+type streamingInteractionRepository struct {
+	InteractionRepository
+	stream *EventStream
+}
+
+// StreamInteractions 記錄互動時一併串流到資料倉儲；stream 為 nil 時直接回傳 repo
+func StreamInteractions(repo InteractionRepository, stream *EventStream) InteractionRepository {
+	if stream == nil {
+		return repo
+	}
+	return &streamingInteractionRepository{InteractionRepository: repo, stream: stream}
+}
+
+func (r *streamingInteractionRepository) RecordInteraction(interaction models.Interaction) error {
+	r.stream.PublishInteraction(interaction)
+	return r.InteractionRepository.RecordInteraction(interaction)
+}
+
+type streamingPushLogRepository struct {
+	PushLogRepository
+	stream   *EventStream
+	consents *ConsentChecker
+}
+
+// StreamPushLogs 保存推播紀錄時一併串流到資料倉儲；stream 為 nil 時直接回傳 repo。
+// 推播紀錄本身仍會保存（/推播紀錄 需要），只有同意統計分析的用戶會串流，consents 為 nil 時不檢查
+func StreamPushLogs(repo PushLogRepository, stream *EventStream, consents *ConsentChecker) PushLogRepository {
+	if stream == nil {
+		return repo
+	}
+	return &streamingPushLogRepository{PushLogRepository: repo, stream: stream, consents: consents}
+}
+
+func (r *streamingPushLogRepository) SavePushLog(pushLog models.PushLog) error {
+	if r.consents.Allowed(pushLog.UserID, models.ConsentAnalytics) {
+		r.stream.PublishPush(pushLog)
+	}
+	return r.PushLogRepository.SavePushLog(pushLog)
+}
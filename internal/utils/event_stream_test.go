@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/sirupsen/logrus"
+)
+
+type recordingFirehose struct {
+	records [][]byte
+	err     error
+}
+
+func (f *recordingFirehose) PutRecord(ctx context.Context, params *firehose.PutRecordInput, optFns ...func(*firehose.Options)) (*firehose.PutRecordOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.records = append(f.records, params.Record.Data)
+	return &firehose.PutRecordOutput{}, nil
+}
+
+type memoryPushLogs struct {
+	PushLogRepository
+	saved []models.PushLog
+}
+
+func (m *memoryPushLogs) SavePushLog(log models.PushLog) error {
+	m.saved = append(m.saved, log)
+	return nil
+}
+
+func TestEventStream(t *testing.T) {
+	client := &recordingFirehose{}
+	stream := NewEventStream(logrus.NewEntry(logrus.New()), client, "events", nil)
+	stream.now = func() time.Time { return time.Date(2026, 10, 18, 1, 2, 3, 0, time.FixedZone("UTC+8", 8*3600)) }
+
+	repo := &memoryPushLogs{}
+	pushLogs := StreamPushLogs(repo, stream, nil)
+	if err := pushLogs.SavePushLog(models.PushLog{UserID: "U1", Date: "2026-10-18", Words: []string{"apple"}, Message: "apple 蘋果", Status: models.PushStatusDelivered}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	stream.PublishInteraction(models.Interaction{UserID: "U1", Feature: models.FeatureQuiz, Action: models.InteractionQuizAnswer})
+
+	if len(repo.saved) != 1 || repo.saved[0].Message == "" {
+		t.Errorf("Expected the push log to be saved unchanged, got %+v", repo.saved)
+	}
+	if len(client.records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(client.records))
+	}
+
+	// 每筆事件一行，事件欄位與 eventType、eventTime 攤平在同一層，推播訊息內容不送出
+	var push map[string]interface{}
+	if data := client.records[0]; data[len(data)-1] != '\n' || json.Unmarshal(data, &push) != nil {
+		t.Fatalf("Expected a newline-terminated JSON record, got %q", data)
+	}
+	if push["eventType"] != StreamEventPush || push["eventTime"] != "2026-10-17T17:02:03Z" || push["userId"] != "U1" || push["message"] != nil {
+		t.Errorf("Unexpected push record %v", push)
+	}
+	var interaction map[string]interface{}
+	if err := json.Unmarshal(client.records[1], &interaction); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if interaction["eventType"] != StreamEventInteraction || interaction["action"] != models.InteractionQuizAnswer || interaction["occurredAt"] != "2026-10-17T17:02:03Z" {
+		t.Errorf("Unexpected interaction record %v", interaction)
+	}
+
+	// 串流失敗不影響保存推播紀錄
+	client.err = errors.New("throttled")
+	if err := pushLogs.SavePushLog(models.PushLog{UserID: "U2"}); err != nil || len(repo.saved) != 2 {
+		t.Errorf("Expected the push log to be saved despite the stream failure, got %v", err)
+	}
+
+	if StreamPushLogs(repo, nil, nil) != PushLogRepository(repo) {
+		t.Error("Expected the repository to be returned as is without a stream")
+	}
+	var disabled *EventStream
+	disabled.PublishPush(models.PushLog{UserID: "U1"})
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CheckExamplePair applies cheap heuristics to catch en/zh example pairs that don't match the word.
+// It returns false with a reason when the pair should be regenerated.
+func CheckExamplePair(word string, example Example) (bool, string) {
+	en := strings.TrimSpace(example.En)
+	zh := strings.TrimSpace(example.Zh)
+	if en == "" || zh == "" {
+		return false, "missing example sentence"
+	}
+	if !containsRune(en, isASCIILetter) {
+		return false, "english example has no english text"
+	}
+	if !containsRune(zh, isHan) {
+		return false, "chinese example has no chinese text"
+	}
+
+	word = strings.TrimSpace(word)
+	switch {
+	case containsRune(word, isHan):
+		// 中文輸入：中文例句應包含原詞
+		if !strings.Contains(zh, word) {
+			return false, "chinese example does not contain the word"
+		}
+	case word != "":
+		// 英文單字：英文例句應包含單字（以字根比對，容許 -ed / -ing / -s 等變化）
+		if !strings.Contains(strings.ToLower(en), englishStem(word)) {
+			return false, "english example does not contain the word"
+		}
+	}
+	return true, ""
+}
+
+// englishStem returns a lower-cased prefix of the word (or of the first word in a phrase)
+// that survives common inflections
+func englishStem(word string) string {
+	fields := strings.Fields(strings.ToLower(word))
+	if len(fields) == 0 {
+		return ""
+	}
+	stem := fields[0]
+	if len(stem) > 5 {
+		stem = stem[:len(stem)-2]
+	} else if len(stem) > 3 {
+		stem = stem[:len(stem)-1]
+	}
+	return stem
+}
+
+func containsRune(s string, f func(rune) bool) bool {
+	return strings.IndexFunc(s, f) >= 0
+}
+
+func isASCIILetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
+
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// ValidateWordExamples regenerates example pairs that fail CheckExamplePair, in place.
+// Words whose example cannot be regenerated keep the original example; the errors are returned for logging.
+func ValidateWordExamples(client OpenaiAPI, words []Word) (int, []error) {
+	regenerated := 0
+	var errs []error
+	for i := range words {
+		fixed, err := ensureExample(client, words[i].Word, words[i].PartOfSpeech, words[i].Meaning, words[i].Difficulty, &words[i].Example)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if fixed {
+			regenerated++
+		}
+	}
+	return regenerated, errs
+}
+
+// ValidateTranslationExamples is ValidateWordExamples for translation results
+func ValidateTranslationExamples(client OpenaiAPI, translations []Translation) (int, []error) {
+	regenerated := 0
+	var errs []error
+	for i := range translations {
+		fixed, err := ensureExample(client, translations[i].Word, translations[i].PartOfSpeech, translations[i].Meaning, "", &translations[i].Example)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if fixed {
+			regenerated++
+		}
+	}
+	return regenerated, errs
+}
+
+func ensureExample(client OpenaiAPI, word, partOfSpeech, meaning, level string, example *Example) (bool, error) {
+	// 沒有例句的結果（例如整句翻譯）不需要補上例句
+	if example.En == "" && example.Zh == "" {
+		return false, nil
+	}
+	if ok, _ := CheckExamplePair(word, *example); ok {
+		return false, nil
+	}
+
+	regenerated, err := client.RegenerateExample(word, partOfSpeech, meaning, level)
+	if err != nil {
+		return false, err
+	}
+	// 重新生成的例句仍不合格時保留原本的例句，避免越改越糟
+	if ok, _ := CheckExamplePair(word, regenerated); !ok {
+		return false, nil
+	}
+
+	*example = regenerated
+	return true, nil
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestCheckExamplePair(t *testing.T) {
+	tests := []struct {
+		name     string
+		word     string
+		example  Example
+		expected bool
+	}{
+		{"Valid english word", "postpone", Example{En: "They postponed the meeting.", Zh: "他們延後了會議。"}, true},
+		{"Inflected short word", "happy", Example{En: "She smiled happily.", Zh: "她開心地笑了。"}, true},
+		{"Valid chinese word", "開心", Example{En: "I am very happy today.", Zh: "我今天很開心。"}, true},
+		{"Missing zh", "book", Example{En: "I read a book."}, false},
+		{"Swapped languages", "book", Example{En: "我讀了一本書。", Zh: "I read a book."}, false},
+		{"English example without word", "postpone", Example{En: "The weather is nice.", Zh: "天氣很好。"}, false},
+		{"Chinese example without word", "開心", Example{En: "I am sad.", Zh: "我很難過。"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, reason := CheckExamplePair(tt.word, tt.example); got != tt.expected {
+				t.Errorf("CheckExamplePair(%q) = %v (%s), expected %v", tt.word, got, reason, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+// FaultKind is a dependency failure that can be simulated for resilience testing
+type FaultKind string
+
+const (
+	FaultOpenAITimeout    FaultKind = "openai_timeout"
+	FaultDynamoDBThrottle FaultKind = "dynamodb_throttle"
+	FaultLine429          FaultKind = "line_429"
+)
+
+// 正式環境不允許注入錯誤，避免誤設定影響真實用戶
+const productionStage = "prod"
+
+// FaultInjector decides, per call, whether a simulated failure should be returned instead of calling the dependency.
+// Rates are fixed after LoadFaultInjector and rolls use the global rand source, so it is safe for concurrent use
+type FaultInjector struct {
+	rates map[FaultKind]float64
+	roll  func() float64
+}
+
+// LoadFaultInjector reads FAULT_INJECTION, a comma separated list of kind=rate pairs
+// (e.g. "openai_timeout=0.2,line_429=1"). Returns nil when unset, and an error when set on the prod STAGE
+func LoadFaultInjector(getenv func(string) string) (*FaultInjector, error) {
+	value := strings.TrimSpace(getenv("FAULT_INJECTION"))
+	if value == "" {
+		return nil, nil
+	}
+	if getenv("STAGE") == productionStage {
+		return nil, fmt.Errorf("FAULT_INJECTION must not be set on the %s stage", productionStage)
+	}
+
+	injector := &FaultInjector{rates: map[FaultKind]float64{}, roll: rand.Float64}
+	for _, pair := range strings.Split(value, ",") {
+		kind, rateStr, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid FAULT_INJECTION entry %q: expected kind=rate", pair)
+		}
+
+		switch FaultKind(kind) {
+		case FaultOpenAITimeout, FaultDynamoDBThrottle, FaultLine429:
+		default:
+			return nil, fmt.Errorf("invalid FAULT_INJECTION entry %q: unknown fault %q", pair, kind)
+		}
+
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid FAULT_INJECTION entry %q: rate must be between 0 and 1", pair)
+		}
+		injector.rates[FaultKind(kind)] = rate
+	}
+
+	return injector, nil
+}
+
+// ShouldFail 依設定的機率決定這次呼叫是否注入錯誤；injector 為 nil 時永遠回傳 false
+func (f *FaultInjector) ShouldFail(kind FaultKind) bool {
+	if f == nil {
+		return false
+	}
+	rate := f.rates[kind]
+	return rate > 0 && f.roll() < rate
+}
+
+// WithOpenAIFaults wraps an OpenAI client so calls can fail as if the request timed out
+func WithOpenAIFaults(api OpenaiAPI, injector *FaultInjector) OpenaiAPI {
+	if injector == nil {
+		return api
+	}
+	return &faultyOpenAI{OpenaiAPI: api, injector: injector}
+}
+
+type faultyOpenAI struct {
+	OpenaiAPI
+	injector *FaultInjector
+}
+
+func (f *faultyOpenAI) fault() error {
+	if f.injector.ShouldFail(FaultOpenAITimeout) {
+		return fmt.Errorf("fault injected: openai request: %w", context.DeadlineExceeded)
+	}
+	return nil
+}
+
+func (f *faultyOpenAI) Translate(inputMsg string, opts TranslateOptions) (TranslationResponse, error) {
+	if err := f.fault(); err != nil {
+		return TranslationResponse{}, err
+	}
+	return f.OpenaiAPI.Translate(inputMsg, opts)
+}
+
+func (f *faultyOpenAI) GenerateWord(opts GenerateWordOptions) (WordGenerationResponse, error) {
+	if err := f.fault(); err != nil {
+		return WordGenerationResponse{}, err
+	}
+	return f.OpenaiAPI.GenerateWord(opts)
+}
+
+func (f *faultyOpenAI) SummarizeArticle(inputMsg string, keyWordCount int, sampleFailures bool) (ArticleSummaryResponse, error) {
+	if err := f.fault(); err != nil {
+		return ArticleSummaryResponse{}, err
+	}
+	return f.OpenaiAPI.SummarizeArticle(inputMsg, keyWordCount, sampleFailures)
+}
+
+func (f *faultyOpenAI) RegenerateExample(word, partOfSpeech, meaning, level string) (Example, error) {
+	if err := f.fault(); err != nil {
+		return Example{}, err
+	}
+	return f.OpenaiAPI.RegenerateExample(word, partOfSpeech, meaning, level)
+}
+
+func (f *faultyOpenAI) CorrectGrammar(sentence, model string, sampleFailures bool) (GrammarCorrectionResponse, error) {
+	if err := f.fault(); err != nil {
+		return GrammarCorrectionResponse{}, err
+	}
+	return f.OpenaiAPI.CorrectGrammar(sentence, model, sampleFailures)
+}
+
+// WithDynamoDBFaults wraps a DynamoDB client so calls can fail with ProvisionedThroughputExceededException
+func WithDynamoDBFaults(api DynamoDbAPI, injector *FaultInjector) DynamoDbAPI {
+	if injector == nil {
+		return api
+	}
+	return &faultyDynamoDB{DynamoDbAPI: api, injector: injector}
+}
+
+type faultyDynamoDB struct {
+	DynamoDbAPI
+	injector *FaultInjector
+}
+
+func (f *faultyDynamoDB) fault() error {
+	if f.injector.ShouldFail(FaultDynamoDBThrottle) {
+		return &types.ProvisionedThroughputExceededException{Message: aws.String("fault injected: throughput exceeded")}
+	}
+	return nil
+}
+
+func (f *faultyDynamoDB) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.DynamoDbAPI.Query(ctx, params, optFns...)
+}
+
+func (f *faultyDynamoDB) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.DynamoDbAPI.Scan(ctx, params, optFns...)
+}
+
+func (f *faultyDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.DynamoDbAPI.GetItem(ctx, params, optFns...)
+}
+
+func (f *faultyDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.DynamoDbAPI.PutItem(ctx, params, optFns...)
+}
+
+func (f *faultyDynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.DynamoDbAPI.UpdateItem(ctx, params, optFns...)
+}
+
+func (f *faultyDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.DynamoDbAPI.DeleteItem(ctx, params, optFns...)
+}
+
+func (f *faultyDynamoDB) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.DynamoDbAPI.BatchWriteItem(ctx, params, optFns...)
+}
+
+// WithLinebotFaults wraps a LINE client so reply / push calls can fail with 429 Too Many Requests
+func WithLinebotFaults(api LinebotAPI, injector *FaultInjector) LinebotAPI {
+	if injector == nil {
+		return api
+	}
+	return &faultyLinebot{LinebotAPI: api, injector: injector}
+}
+
+type faultyLinebot struct {
+	LinebotAPI
+	injector *FaultInjector
+}
+
+func (f *faultyLinebot) fault() error {
+	if f.injector.ShouldFail(FaultLine429) {
+		return &linebot.APIError{
+			Code:     http.StatusTooManyRequests,
+			Response: &linebot.ErrorResponse{Message: "fault injected: too many requests"},
+		}
+	}
+	return nil
+}
+
+func (f *faultyLinebot) ReplyMessage(replyToken string, message string) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.ReplyMessage(replyToken, message)
+}
+
+func (f *faultyLinebot) ReplyMessageWithMultiple(replyToken string, messages ...linebot.SendingMessage) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.ReplyMessageWithMultiple(replyToken, messages...)
+}
+
+func (f *faultyLinebot) PushMessage(userID string, message string) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.PushMessage(userID, message)
+}
+
+func (f *faultyLinebot) PushMessages(userID string, messages ...linebot.SendingMessage) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.PushMessages(userID, messages...)
+}
+
+func (f *faultyLinebot) PushFlexMessage(userID, altText string, contents linebot.FlexContainer, quickReplies *linebot.QuickReplyItems) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.PushFlexMessage(userID, altText, contents, quickReplies)
+}
+
+func (f *faultyLinebot) PushAudioMessage(userID, audioURL string, duration time.Duration) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.PushAudioMessage(userID, audioURL, duration)
+}
+
+func (f *faultyLinebot) GetProfile(userID string) (*linebot.UserProfileResponse, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.LinebotAPI.GetProfile(userID)
+}
+
+func (f *faultyLinebot) GetGroupMemberProfile(groupID, userID string) (*linebot.UserProfileResponse, error) {
+	if err := f.fault(); err != nil {
+		return nil, err
+	}
+	return f.LinebotAPI.GetGroupMemberProfile(groupID, userID)
+}
+
+func (f *faultyLinebot) LinkUserRichMenu(userID, richMenuID string) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.LinkUserRichMenu(userID, richMenuID)
+}
+
+func (f *faultyLinebot) Multicast(userIDs []string, messages ...linebot.SendingMessage) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.Multicast(userIDs, messages...)
+}
+
+func (f *faultyLinebot) Broadcast(messages ...linebot.SendingMessage) error {
+	if err := f.fault(); err != nil {
+		return err
+	}
+	return f.LinebotAPI.Broadcast(messages...)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+func envOf(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestLoadFaultInjector(t *testing.T) {
+	injector, err := LoadFaultInjector(envOf(nil))
+	if err != nil || injector != nil {
+		t.Fatalf("Expected no injector when FAULT_INJECTION is unset, got %v (err %v)", injector, err)
+	}
+	if injector.ShouldFail(FaultLine429) {
+		t.Errorf("nil injector should never fail")
+	}
+
+	invalid := []string{"line_429", "line_429=2", "disk_full=0.5"}
+	for _, value := range invalid {
+		if _, err := LoadFaultInjector(envOf(map[string]string{"FAULT_INJECTION": value})); err == nil {
+			t.Errorf("Expected error for FAULT_INJECTION=%q", value)
+		}
+	}
+
+	if _, err := LoadFaultInjector(envOf(map[string]string{"FAULT_INJECTION": "line_429=1", "STAGE": "prod"})); err == nil {
+		t.Errorf("Expected fault injection to be rejected on prod")
+	}
+}
+
+func TestFaultWrappers(t *testing.T) {
+	injector, err := LoadFaultInjector(envOf(map[string]string{
+		"FAULT_INJECTION": "openai_timeout=1, dynamodb_throttle=1, line_429=1",
+		"STAGE":           "staging",
+	}))
+	if err != nil {
+		t.Fatalf("LoadFaultInjector() error = %v", err)
+	}
+
+	// 注入錯誤時不應呼叫到底層的 client，所以傳入 nil
+	if _, err := WithOpenAIFaults(nil, injector).Translate("hello", TranslateOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected OpenAI timeout, got %v", err)
+	}
+
+	var throttled *types.ProvisionedThroughputExceededException
+	if _, err := WithDynamoDBFaults(nil, injector).GetItem(context.Background(), &dynamodb.GetItemInput{}); !errors.As(err, &throttled) {
+		t.Errorf("Expected DynamoDB throttling, got %v", err)
+	}
+
+	var apiErr *linebot.APIError
+	if err := WithLinebotFaults(nil, injector).PushMessage("U1", "hi"); !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected LINE 429, got %v", err)
+	}
+}
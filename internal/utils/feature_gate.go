@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"slices"
+	"time"
+)
+
+// TrialEndingNotice 試用剩下這麼多時間時推播即將到期的提醒（reminder 每天執行一次）
+const TrialEndingNotice = 2 * 24 * time.Hour
+
+// 各付費功能的試用長度，付費功能的試用只在這裡設定
+var trialDurations = map[models.PremiumFeature]time.Duration{
+	models.PremiumFeatureAudio: 14 * 24 * time.Hour,
+}
+
+// 各方案包含的付費功能
+var planFeatures = map[models.Plan][]models.PremiumFeature{
+	models.PlanSupporter: models.PremiumFeatures,
+}
+
+// TrialDays 付費功能的試用天數
+func TrialDays(feature models.PremiumFeature) int {
+	return int(trialDurations[feature] / (24 * time.Hour))
+}
+
+// FeatureGate decides whether a user may use a premium feature: plans that include the feature always may, other
+// users only during an unexpired trial. It also starts trials and downgrades users when a trial runs out.
+// Safe for concurrent use
+type FeatureGate struct {
+	repo           SubscriptionRepository
+	userConfigRepo UserConfigRepository
+	now            func() time.Time
+}
+
+func NewFeatureGate(repo SubscriptionRepository, userConfigRepo UserConfigRepository) *FeatureGate {
+	return &FeatureGate{
+		repo:           repo,
+		userConfigRepo: userConfigRepo,
+		now:            time.Now,
+	}
+}
+
+// Allowed 回傳用戶目前是否可以使用 feature；方案包含該功能時不讀取試用紀錄
+func (g *FeatureGate) Allowed(userConfig models.UserConfig, feature models.PremiumFeature) (bool, error) {
+	if slices.Contains(planFeatures[userConfig.EffectivePlan()], feature) {
+		return true, nil
+	}
+	trial, err := g.repo.GetTrial(userConfig.UserID, feature)
+	if err != nil {
+		return false, fmt.Errorf("failed to get trial: %w", err)
+	}
+	return trial != nil && g.inTrial(*trial), nil
+}
+
+// StartTrial 開始 feature 的試用，回傳試用與這次是否新開始；用戶試用過時回傳原本的試用與 false
+func (g *FeatureGate) StartTrial(userID string, feature models.PremiumFeature) (*models.Trial, bool, error) {
+	duration, ok := trialDurations[feature]
+	if !ok {
+		return nil, false, fmt.Errorf("feature %s has no trial", feature)
+	}
+
+	now := g.now().UTC()
+	trial := models.Trial{
+		UserID:    userID,
+		Feature:   feature,
+		Status:    models.TrialActive,
+		StartedAt: now.Format(time.RFC3339),
+		ExpiresAt: now.Add(duration).Format(time.RFC3339),
+	}
+	started, err := g.repo.StartTrial(trial)
+	if err != nil {
+		return nil, false, err
+	}
+	if started {
+		return &trial, true, nil
+	}
+
+	existing, err := g.repo.GetTrial(userID, feature)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get trial: %w", err)
+	}
+	return existing, false, nil
+}
+
+// DueTrials 列出已過期待降級的試用，以及即將到期、還沒提醒過的試用
+func (g *FeatureGate) DueTrials() (expired, ending []models.Trial, err error) {
+	trials, err := g.repo.GetActiveTrials()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := g.now()
+	for _, trial := range trials {
+		expiresAt, err := time.Parse(time.RFC3339, trial.ExpiresAt)
+		if err != nil {
+			// 到期時間無法解析的試用視為已到期，避免永遠無法結束
+			expired = append(expired, trial)
+			continue
+		}
+		switch {
+		case !now.Before(expiresAt):
+			expired = append(expired, trial)
+		case !trial.EndingNotified && expiresAt.Sub(now) <= TrialEndingNotice:
+			ending = append(ending, trial)
+		}
+	}
+	return expired, ending, nil
+}
+
+// MarkEndingNotified 記錄已提醒試用即將到期
+func (g *FeatureGate) MarkEndingNotified(trial models.Trial) error {
+	return g.repo.MarkTrialEndingNotified(trial.UserID, trial.Feature)
+}
+
+// ExpireTrial 將試用標為到期並關閉用戶在試用期間開啟的功能，回傳這次是否完成了到期處理（需要通知用戶）。
+// 試用期間升級成包含該功能的方案時只標為到期，不關閉功能
+func (g *FeatureGate) ExpireTrial(trial models.Trial) (bool, error) {
+	expired, err := g.repo.ExpireTrial(trial.UserID, trial.Feature, g.now().UTC().Format(time.RFC3339))
+	if err != nil || !expired {
+		return false, err
+	}
+
+	userConfig, err := g.userConfigRepo.GetUserConfig(trial.UserID)
+	if err != nil {
+		return true, fmt.Errorf("failed to get user config: %w", err)
+	}
+	if userConfig == nil || slices.Contains(planFeatures[userConfig.EffectivePlan()], trial.Feature) {
+		return true, nil
+	}
+
+	switch trial.Feature {
+	case models.PremiumFeatureAudio:
+		if userConfig.PronunciationAudio {
+			if err := g.userConfigRepo.SetPronunciationAudio(trial.UserID, false); err != nil {
+				return true, fmt.Errorf("failed to turn off pronunciation audio: %w", err)
+			}
+		}
+	}
+	return true, nil
+}
+
+// TrialExpiryDate 試用最後可使用的日期（用戶時區，YYYY-MM-DD）
+func TrialExpiryDate(trial models.Trial, timezone string) string {
+	expiresAt, err := time.Parse(time.RFC3339, trial.ExpiresAt)
+	if err != nil {
+		return trial.ExpiresAt
+	}
+	return StreakDate(expiresAt, timezone)
+}
+
+func (g *FeatureGate) inTrial(trial models.Trial) bool {
+	expiresAt, err := time.Parse(time.RFC3339, trial.ExpiresAt)
+	return err == nil && trial.Status == models.TrialActive && g.now().Before(expiresAt)
+}
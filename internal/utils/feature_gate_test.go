@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+type memorySubscriptions struct {
+	trials map[string]models.Trial
+}
+
+func (m *memorySubscriptions) StartTrial(trial models.Trial) (bool, error) {
+	key := trial.UserID + "#" + string(trial.Feature)
+	if _, ok := m.trials[key]; ok {
+		return false, nil
+	}
+	m.trials[key] = trial
+	return true, nil
+}
+
+func (m *memorySubscriptions) GetTrial(userID string, feature models.PremiumFeature) (*models.Trial, error) {
+	trial, ok := m.trials[userID+"#"+string(feature)]
+	if !ok {
+		return nil, nil
+	}
+	return &trial, nil
+}
+
+func (m *memorySubscriptions) GetActiveTrials() ([]models.Trial, error) {
+	var trials []models.Trial
+	for _, trial := range m.trials {
+		if trial.Status == models.TrialActive {
+			trials = append(trials, trial)
+		}
+	}
+	return trials, nil
+}
+
+func (m *memorySubscriptions) MarkTrialEndingNotified(userID string, feature models.PremiumFeature) error {
+	trial := m.trials[userID+"#"+string(feature)]
+	trial.EndingNotified = true
+	m.trials[userID+"#"+string(feature)] = trial
+	return nil
+}
+
+func (m *memorySubscriptions) ExpireTrial(userID string, feature models.PremiumFeature, expiredAt string) (bool, error) {
+	trial := m.trials[userID+"#"+string(feature)]
+	if trial.Status != models.TrialActive {
+		return false, nil
+	}
+	trial.Status, trial.ExpiredAt = models.TrialExpired, expiredAt
+	m.trials[userID+"#"+string(feature)] = trial
+	return true, nil
+}
+
+type audioSettings struct {
+	UserConfigRepository
+	configs map[string]*models.UserConfig
+}
+
+func (a *audioSettings) GetUserConfig(userID string) (*models.UserConfig, error) {
+	return a.configs[userID], nil
+}
+
+func (a *audioSettings) SetPronunciationAudio(userID string, enabled bool) error {
+	a.configs[userID].PronunciationAudio = enabled
+	return nil
+}
+
+func TestFeatureGate(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	repo := &memorySubscriptions{trials: map[string]models.Trial{}}
+	settings := &audioSettings{configs: map[string]*models.UserConfig{
+		"U1": {UserID: "U1", PronunciationAudio: true},
+		"U2": {UserID: "U2", PronunciationAudio: true, Plan: models.PlanSupporter},
+	}}
+	gate := NewFeatureGate(repo, settings)
+	gate.now = func() time.Time { return now }
+
+	allowed := func(userID string) bool {
+		t.Helper()
+		ok, err := gate.Allowed(*settings.configs[userID], models.PremiumFeatureAudio)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return ok
+	}
+
+	if allowed("U1") || !allowed("U2") {
+		t.Fatalf("Expected only the supporter to have audio before any trial")
+	}
+
+	trial, started, err := gate.StartTrial("U1", models.PremiumFeatureAudio)
+	if err != nil || !started || trial.ExpiresAt != "2026-10-15T12:00:00Z" {
+		t.Fatalf("Expected a 14-day trial, got %+v, %v (err %v)", trial, started, err)
+	}
+	if _, started, _ := gate.StartTrial("U1", models.PremiumFeatureAudio); started {
+		t.Errorf("Expected each feature to be tried only once")
+	}
+	if !allowed("U1") {
+		t.Errorf("Expected audio during the trial")
+	}
+
+	now = start.Add(12*24*time.Hour + time.Hour)
+	expired, ending, err := gate.DueTrials()
+	if err != nil || len(expired) != 0 || len(ending) != 1 {
+		t.Fatalf("Expected one ending trial, got %v and %v (err %v)", expired, ending, err)
+	}
+	gate.MarkEndingNotified(ending[0])
+	if _, ending, _ := gate.DueTrials(); len(ending) != 0 {
+		t.Errorf("Expected the ending notice to be sent once, got %v", ending)
+	}
+
+	now = start.Add(14 * 24 * time.Hour)
+	if allowed("U1") {
+		t.Errorf("Expected no audio once the trial expires")
+	}
+	expired, _, _ = gate.DueTrials()
+	if len(expired) != 1 {
+		t.Fatalf("Expected one expired trial, got %v", expired)
+	}
+	if notify, err := gate.ExpireTrial(expired[0]); err != nil || !notify || settings.configs["U1"].PronunciationAudio {
+		t.Errorf("Expected the trial to expire and turn audio off, got %v (err %v), audio %v", notify, err, settings.configs["U1"].PronunciationAudio)
+	}
+	if notify, _ := gate.ExpireTrial(expired[0]); notify {
+		t.Errorf("Expected a repeated expiry to be a no-op")
+	}
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"sort"
+	"time"
+)
+
+// OnboardingFunnelSteps 依序為 follow → 選課程 → 輸入分數 → 完成推播設定
+var OnboardingFunnelSteps = []string{
+	models.InteractionFollow,
+	models.InteractionCourseSelected,
+	models.InteractionScoreSet,
+	models.InteractionPushConfigured,
+}
+
+// MissingSetupStep 回傳用戶尚未完成的第一個 onboarding 步驟（OnboardingFunnelSteps 中的值），設定完整時回傳空字串
+func MissingSetupStep(userConfig *models.UserConfig) string {
+	switch {
+	case userConfig.Course == "":
+		return models.InteractionCourseSelected
+	case userConfig.Level <= 0:
+		return models.InteractionScoreSet
+	case userConfig.MissingPushSettings || userConfig.DailyWords <= 0 || userConfig.PushTime == "":
+		return models.InteractionPushConfigured
+	}
+	return ""
+}
+
+// Cohort granularities
+const (
+	CohortDay  = "day"
+	CohortWeek = "week"
+)
+
+// FunnelStep is the number of users in a cohort that reached a step
+type FunnelStep struct {
+	Step           string  `json:"step"`
+	Users          int     `json:"users"`
+	Conversion     float64 `json:"conversion"`     // 相對於 follow 人數
+	StepConversion float64 `json:"stepConversion"` // 相對於上一步人數
+}
+
+// FunnelCohort groups users by the day or week (Monday) they followed the bot
+type FunnelCohort struct {
+	Cohort string       `json:"cohort"`
+	Steps  []FunnelStep `json:"steps"`
+}
+
+// ComputeOnboardingFunnel 計算每個 cohort 的 onboarding 漏斗轉換率。
+// 只計入 follow 時間落在 [from, to) 的用戶；每一步必須在上一步之後發生才算完成（嚴格漏斗）。
+// interactions 需包含 from 之後所有日期的紀錄，才能涵蓋 cohort 期間之後才完成的步驟
+func ComputeOnboardingFunnel(interactions []models.Interaction, from, to time.Time, granularity string) []FunnelCohort {
+	sorted := make([]models.Interaction, len(interactions))
+	copy(sorted, interactions)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].OccurredAt < sorted[j].OccurredAt })
+
+	// 每位用戶目前完成到第幾步，與所屬 cohort
+	progress := map[string]int{}
+	cohortOf := map[string]string{}
+	for _, interaction := range sorted {
+		occurredAt, err := time.Parse(time.RFC3339Nano, interaction.OccurredAt)
+		if err != nil {
+			continue
+		}
+
+		if interaction.Action == models.InteractionFollow {
+			// 以第一次 follow 為準（封鎖後重新加入不重新分 cohort）
+			if _, ok := cohortOf[interaction.UserID]; ok {
+				continue
+			}
+			if occurredAt.Before(from) || !occurredAt.Before(to) {
+				continue
+			}
+			cohortOf[interaction.UserID] = cohortLabel(occurredAt, granularity)
+			progress[interaction.UserID] = 1
+			continue
+		}
+
+		reached, ok := progress[interaction.UserID]
+		if !ok || reached >= len(OnboardingFunnelSteps) {
+			continue
+		}
+		if interaction.Action == OnboardingFunnelSteps[reached] {
+			progress[interaction.UserID] = reached + 1
+		}
+	}
+
+	counts := map[string][]int{}
+	for userID, cohort := range cohortOf {
+		if counts[cohort] == nil {
+			counts[cohort] = make([]int, len(OnboardingFunnelSteps))
+		}
+		for step := 0; step < progress[userID]; step++ {
+			counts[cohort][step]++
+		}
+	}
+
+	cohorts := make([]FunnelCohort, 0, len(counts))
+	for cohort, stepCounts := range counts {
+		steps := make([]FunnelStep, len(OnboardingFunnelSteps))
+		for i, name := range OnboardingFunnelSteps {
+			steps[i] = FunnelStep{
+				Step:       name,
+				Users:      stepCounts[i],
+				Conversion: ratio(stepCounts[i], stepCounts[0]),
+			}
+			if i == 0 {
+				steps[i].StepConversion = 1
+			} else {
+				steps[i].StepConversion = ratio(stepCounts[i], stepCounts[i-1])
+			}
+		}
+		cohorts = append(cohorts, FunnelCohort{Cohort: cohort, Steps: steps})
+	}
+	sort.Slice(cohorts, func(i, j int) bool { return cohorts[i].Cohort < cohorts[j].Cohort })
+
+	return cohorts
+}
+
+// cohortLabel 回傳日期（UTC），以週分組時為該週的星期一
+func cohortLabel(t time.Time, granularity string) string {
+	if granularity == CohortWeek {
+		return WeekStart(t).Format("2006-01-02")
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+func TestComputeOnboardingFunnel(t *testing.T) {
+	event := func(userID, action, occurredAt string) models.Interaction {
+		return models.Interaction{UserID: userID, Action: action, OccurredAt: occurredAt}
+	}
+
+	interactions := []models.Interaction{
+		// user1: 完成所有步驟（週三加入）
+		event("user1", models.InteractionFollow, "2025-01-08T10:00:00Z"),
+		event("user1", models.InteractionCourseSelected, "2025-01-08T10:01:00Z"),
+		event("user1", models.InteractionScoreSet, "2025-01-08T10:02:00Z"),
+		event("user1", models.InteractionPushConfigured, "2025-01-15T09:00:00Z"), // cohort 期間之後才完成仍計入
+		// user2: 選了課程就離開
+		event("user2", models.InteractionCourseSelected, "2025-01-06T08:01:00Z"),
+		event("user2", models.InteractionFollow, "2025-01-06T08:00:00Z"),
+		// user3: 跳過分數直接設定推播，嚴格漏斗不計入後續步驟
+		event("user3", models.InteractionFollow, "2025-01-12T23:00:00Z"),
+		event("user3", models.InteractionCourseSelected, "2025-01-12T23:01:00Z"),
+		event("user3", models.InteractionPushConfigured, "2025-01-12T23:02:00Z"),
+		// user4: 下一週加入
+		event("user4", models.InteractionFollow, "2025-01-13T00:00:00Z"),
+		// user5: 在查詢區間之前加入，不計入
+		event("user5", models.InteractionFollow, "2025-01-01T00:00:00Z"),
+		event("user5", models.InteractionCourseSelected, "2025-01-06T00:00:00Z"),
+	}
+
+	from := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	cohorts := ComputeOnboardingFunnel(interactions, from, to, CohortWeek)
+
+	if len(cohorts) != 2 {
+		t.Fatalf("Expected 2 weekly cohorts, got %d: %+v", len(cohorts), cohorts)
+	}
+
+	first := cohorts[0]
+	if first.Cohort != "2025-01-06" {
+		t.Errorf("Expected first cohort 2025-01-06, got %s", first.Cohort)
+	}
+	expectedUsers := []int{3, 3, 1, 1}
+	for i, step := range first.Steps {
+		if step.Users != expectedUsers[i] {
+			t.Errorf("Step %s: expected %d users, got %d", step.Step, expectedUsers[i], step.Users)
+		}
+	}
+	if first.Steps[2].StepConversion != 1.0/3 || first.Steps[3].Conversion != 1.0/3 {
+		t.Errorf("Unexpected conversion rates: %+v", first.Steps)
+	}
+
+	if cohorts[1].Cohort != "2025-01-13" || cohorts[1].Steps[0].Users != 1 || cohorts[1].Steps[1].Users != 0 {
+		t.Errorf("Unexpected second cohort: %+v", cohorts[1])
+	}
+}
+
+func TestMissingSetupStep(t *testing.T) {
+	tests := []struct {
+		config models.UserConfig
+		want   string
+	}{
+		{models.UserConfig{}, models.InteractionCourseSelected},
+		{models.UserConfig{Course: "toeic"}, models.InteractionScoreSet},
+		{models.UserConfig{Course: "toeic", Level: 750}, models.InteractionPushConfigured},
+		{models.UserConfig{Course: "toeic", Level: 750, DailyWords: 10}, models.InteractionPushConfigured},
+		{models.UserConfig{Course: "toeic", Level: 750, DailyWords: 10, PushTime: "08:00"}, ""},
+		// 讀取時補上預設值的推播設定不算完成
+		{models.UserConfig{Course: "toeic", Level: 750, DailyWords: 10, PushTime: "08:00", MissingPushSettings: true}, models.InteractionPushConfigured},
+	}
+
+	for _, tt := range tests {
+		if got := MissingSetupStep(&tt.config); got != tt.want {
+			t.Errorf("MissingSetupStep(%+v) = %q, expected %q", tt.config, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"language-assistant/internal/messages"
+	"language-assistant/internal/models"
+	"sort"
+)
+
+const (
+	// GroupRankingDays /排行榜 統計最近幾天（含今天）查詢的單字
+	GroupRankingDays = 7
+	// MaxGroupRankingEntries 排行榜最多列出的成員數
+	MaxGroupRankingEntries = 10
+)
+
+// GroupContributor 群組成員在群組單字庫中查詢的單字數
+type GroupContributor struct {
+	UserID string
+	Words  int
+}
+
+// RankGroupContributors 依查詢的單字數由多到少排列群組成員，數量相同時先達到該數量的成員在前；
+// 沒有記錄成員的單字（例如成員未同意提供 userId）不列入排名
+func RankGroupContributors(vocabularies []models.UserVocabulary) []GroupContributor {
+	counts := map[string]int{}
+	reachedAt := map[string]string{}
+	for _, vocabulary := range vocabularies {
+		for _, word := range vocabulary.Words {
+			if word.AddedBy == "" {
+				continue
+			}
+			counts[word.AddedBy]++
+			reachedAt[word.AddedBy] = max(reachedAt[word.AddedBy], word.Timestamp)
+		}
+	}
+
+	contributors := make([]GroupContributor, 0, len(counts))
+	for userID, words := range counts {
+		contributors = append(contributors, GroupContributor{UserID: userID, Words: words})
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		a, b := contributors[i], contributors[j]
+		if a.Words != b.Words {
+			return a.Words > b.Words
+		}
+		if reachedAt[a.UserID] != reachedAt[b.UserID] {
+			return reachedAt[a.UserID] < reachedAt[b.UserID]
+		}
+		return a.UserID < b.UserID
+	})
+	return contributors
+}
+
+// GroupMemberName 取得群組成員的顯示名稱；成員已離開群組或取得失敗時回傳通用的稱呼
+func GroupMemberName(client LinebotAPI, groupID, userID string) string {
+	profile, err := client.GetGroupMemberProfile(groupID, userID)
+	if err != nil || profile.DisplayName == "" {
+		return messages.Text(messages.GroupMemberFallback)
+	}
+	return profile.DisplayName
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestRankGroupContributors(t *testing.T) {
+	vocabularies := []models.UserVocabulary{
+		{Date: "2026-10-16", Words: []models.WordRecord{
+			{Word: "apple", AddedBy: "U2", Timestamp: "2026-10-16T01:00:00Z"},
+			{Word: "banana", AddedBy: "U1", Timestamp: "2026-10-16T02:00:00Z"},
+			{Word: "cherry", Timestamp: "2026-10-16T03:00:00Z"},
+		}},
+		{Date: "2026-10-17", Words: []models.WordRecord{
+			{Word: "durian", AddedBy: "U3", Timestamp: "2026-10-17T01:00:00Z"},
+			{Word: "elder", AddedBy: "U3", Timestamp: "2026-10-17T02:00:00Z"},
+			{Word: "fig", AddedBy: "U2", Timestamp: "2026-10-17T03:00:00Z"},
+		}},
+	}
+
+	got := RankGroupContributors(vocabularies)
+	// U3 與 U2 同樣是 2 個，U3 較早達到
+	want := []GroupContributor{{UserID: "U3", Words: 2}, {UserID: "U2", Words: 2}, {UserID: "U1", Words: 1}}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v at rank %d, got %v", want[i], i+1, got[i])
+		}
+	}
+}
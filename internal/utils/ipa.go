@@ -0,0 +1,14 @@
+package utils
+
+import "strings"
+
+// DisplayIPA 回傳以斜線包住的 IPA 音標，例如 "/ˈhæpi/"；模型有時已加上斜線或方括號，先去掉再統一格式。
+// 沒有音標（日文或中文輸入）時回傳空字串
+func DisplayIPA(ipa string) string {
+	ipa = strings.Trim(strings.TrimSpace(ipa), "/[] ")
+	if ipa == "" {
+		return ""
+	}
+	// 模型偶爾以撇號代替主重音符號 ˈ
+	return "/" + strings.ReplaceAll(ipa, "'", "ˈ") + "/"
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayIPA(t *testing.T) {
+	cases := map[string]string{
+		"ˈhæpi":          "/ˈhæpi/",
+		" /ˈhæpi/ ":      "/ˈhæpi/",
+		"[ˌʌndərˈstænd]": "/ˌʌndərˈstænd/",
+		"'hæpi":          "/ˈhæpi/",
+		"":               "",
+		"//":             "",
+	}
+	for ipa, expected := range cases {
+		if got := DisplayIPA(ipa); got != expected {
+			t.Errorf("DisplayIPA(%q) = %q, expected %q", ipa, got, expected)
+		}
+	}
+}
+
+func TestTranslationCardIPA(t *testing.T) {
+	card := Translation{Word: "happy", IPA: "ˈhæpi", PartOfSpeech: "adj.", Meaning: "快樂的"}.String()
+	if !strings.HasPrefix(card, "【happy】/ˈhæpi/ (adj.)") {
+		t.Errorf("Expected the IPA after the word, got %q", card)
+	}
+	if card := (Translation{Word: "開心", PartOfSpeech: "adj.", Meaning: "happy"}).String(); !strings.HasPrefix(card, "【開心】(adj.)") {
+		t.Errorf("Expected no pronunciation without IPA, got %q", card)
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"language-assistant/internal/courses"
+	"strings"
+	"unicode"
+)
+
+// ContainsKana reports whether text has hiragana or katakana; Chinese text never does, so it tells Japanese
+// input apart even when the rest is kanji
+func ContainsKana(text string) bool {
+	return strings.IndexFunc(text, func(r rune) bool {
+		return unicode.In(r, unicode.Hiragana, unicode.Katakana)
+	}) >= 0
+}
+
+// TranslationLanguage 決定翻譯使用的語言：輸入含假名，或日文課程的用戶輸入中文時使用中日翻譯，其他為中英翻譯
+func TranslationLanguage(text, course string) string {
+	if ContainsKana(text) {
+		return courses.LanguageJapanese
+	}
+	if c, ok := courses.Get(course); ok && c.Language == courses.LanguageJapanese && containsRune(text, isHan) {
+		return courses.LanguageJapanese
+	}
+	return courses.LanguageEnglish
+}
+
+// DisplayReading 回傳要顯示的假名讀音；單字本身就是假名時與讀音相同，不重複顯示
+func DisplayReading(word, kana string) string {
+	if strings.TrimSpace(kana) == strings.TrimSpace(word) {
+		return ""
+	}
+	return kana
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"language-assistant/internal/courses"
+	"strings"
+	"testing"
+)
+
+func TestTranslationLanguage(t *testing.T) {
+	tests := []struct {
+		text   string
+		course string
+		want   string
+	}{
+		{"happy", "toeic", courses.LanguageEnglish},
+		{"開心", "toeic", courses.LanguageEnglish},
+		{"嬉しい", "toeic", courses.LanguageJapanese}, // 含假名一律中日翻譯
+		{"コーヒー", "", courses.LanguageJapanese},
+		{"開心", "japanese", courses.LanguageJapanese},
+		{"happy", "japanese", courses.LanguageEnglish}, // 日文課程輸入英文仍是中英翻譯
+	}
+	for _, tt := range tests {
+		if got := TranslationLanguage(tt.text, tt.course); got != tt.want {
+			t.Errorf("TranslationLanguage(%q, %q) = %q, want %q", tt.text, tt.course, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayReading(t *testing.T) {
+	if got := DisplayReading("準備", "じゅんび"); got != "じゅんび" {
+		t.Errorf("Expected kanji word to show its reading, got %q", got)
+	}
+	if got := DisplayReading("たべる", "たべる"); got != "" {
+		t.Errorf("Expected kana-only word to hide the duplicate reading, got %q", got)
+	}
+}
+
+func TestFakeOpenAIClientJapanese(t *testing.T) {
+	client := NewFakeOpenAIClient()
+	resp, err := client.GenerateWord(GenerateWordOptions{Course: "japanese", WordCount: 3, Level: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, word := range resp.Words {
+		if word.Reading == "" || !ContainsKana(word.Example.En) || !strings.HasPrefix(word.ExamTags[0], "JLPT") {
+			t.Errorf("Expected a Japanese word with reading, got %+v", word)
+		}
+	}
+
+	translation, _ := client.Translate("約定", TranslateOptions{Language: courses.LanguageJapanese})
+	card := translation.Texts()[0]
+	if !strings.Contains(card, "【約束】［やくそく］") || !strings.Contains(card, "（あしたのやくそくをわすれないでね。）") {
+		t.Errorf("Expected the translation card to show readings, got %q", card)
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"language-assistant/internal/messages"
+	"language-assistant/internal/models"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// KillSwitchCacheTTL 各 Lambda 重新讀取 kill switch 的間隔，維運人員關閉子系統後最多這麼久即生效，不需要重新部署
+const KillSwitchCacheTTL = 30 * time.Second
+
+// 維護中訊息顯示的子系統名稱
+var killSwitchLabels = map[models.KillSwitch]messages.Key{
+	models.KillSwitchTTS:       messages.KillSwitchTTSLabel,
+	models.KillSwitchDailyPush: messages.KillSwitchDailyPushLabel,
+}
+
+// KillSwitchCache reads the kill switches at most once per ttl. When the switches cannot be loaded it keeps the last
+// known states, so a DynamoDB outage never turns a subsystem off by itself. Safe for concurrent use
+type KillSwitchCache struct {
+	logger *logrus.Entry
+	repo   KillSwitchRepository
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	states   map[models.KillSwitch]models.KillSwitchState
+	loadedAt time.Time
+}
+
+func NewKillSwitchCache(logger *logrus.Entry, repo KillSwitchRepository, ttl time.Duration) *KillSwitchCache {
+	return &KillSwitchCache{
+		logger: logger,
+		repo:   repo,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Disabled 回傳子系統目前是否被關閉
+func (c *KillSwitchCache) Disabled(sw models.KillSwitch) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.loadedAt.IsZero() || now.Sub(c.loadedAt) > c.ttl {
+		states, err := c.repo.GetKillSwitches()
+		if err != nil {
+			c.logger.WithError(err).Warn("Failed to load kill switches, keeping last known states")
+		} else {
+			c.states = states
+		}
+		// 讀取失敗時同樣等 ttl 後再重試，避免每個請求都打 DynamoDB
+		c.loadedAt = now
+	}
+
+	disabled := c.states[sw].Disabled
+	if disabled {
+		c.logger.WithFields(logrus.Fields{"killSwitch": sw, "reason": c.states[sw].Reason}).Info("Subsystem disabled by kill switch")
+	}
+	return disabled
+}
+
+// MaintenanceMessage 子系統被關閉時回覆用戶的「暫停維護中」訊息
+func MaintenanceMessage(sw models.KillSwitch) string {
+	return messages.Render(messages.FeatureMaintenance, messages.Data{"Feature": messages.Text(killSwitchLabels[sw])})
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"errors"
+	"language-assistant/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type memoryKillSwitches struct {
+	states map[models.KillSwitch]models.KillSwitchState
+	err    error
+	loads  int
+}
+
+func (m *memoryKillSwitches) GetKillSwitches() (map[models.KillSwitch]models.KillSwitchState, error) {
+	m.loads++
+	if m.err != nil {
+		return nil, m.err
+	}
+	states := map[models.KillSwitch]models.KillSwitchState{}
+	for sw, state := range m.states {
+		states[sw] = state
+	}
+	return states, nil
+}
+
+func (m *memoryKillSwitches) SaveKillSwitch(state models.KillSwitchState) error {
+	m.states[state.Switch] = state
+	return nil
+}
+
+func TestKillSwitchCache(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo := &memoryKillSwitches{states: map[models.KillSwitch]models.KillSwitchState{}}
+	cache := NewKillSwitchCache(logrus.NewEntry(logrus.New()), repo, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	if cache.Disabled(models.KillSwitchTTS) {
+		t.Fatalf("Expected switches without a stored state to be enabled")
+	}
+
+	repo.SaveKillSwitch(models.KillSwitchState{Switch: models.KillSwitchTTS, Disabled: true, Reason: "cost spike"})
+	if cache.Disabled(models.KillSwitchTTS) || repo.loads != 1 {
+		t.Errorf("Expected the cached state within the ttl, got %d loads", repo.loads)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if !cache.Disabled(models.KillSwitchTTS) || cache.Disabled(models.KillSwitchDailyPush) {
+		t.Errorf("Expected only TTS to be disabled after reloading")
+	}
+
+	// 讀取失敗時沿用上次的狀態，不會因 DynamoDB 故障而自行恢復或關閉
+	repo.err = errors.New("throttled")
+	now = now.Add(2 * time.Minute)
+	if !cache.Disabled(models.KillSwitchTTS) {
+		t.Errorf("Expected the last known state when loading fails")
+	}
+
+	if message := MaintenanceMessage(models.KillSwitchTTS); !strings.Contains(message, "單字發音暫停維護中") {
+		t.Errorf("Unexpected maintenance message %q", message)
+	}
+}
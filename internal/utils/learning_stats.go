@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"sort"
+	"time"
+)
+
+type badgeRule struct {
+	badge models.Badge
+	earn  func(stats models.LearningStats) bool
+}
+
+// 徽章依序顯示，條件只依賴統計數字，不讀取單字內容
+var badgeRules = []badgeRule{
+	{models.Badge{ID: "first_word", Emoji: "🌱", Name: "第一個單字"}, func(s models.LearningStats) bool { return s.TotalWords >= 1 }},
+	{models.Badge{ID: "words_100", Emoji: "📚", Name: "百字達人"}, func(s models.LearningStats) bool { return s.TotalWords >= 100 }},
+	{models.Badge{ID: "words_500", Emoji: "🏆", Name: "五百字大師"}, func(s models.LearningStats) bool { return s.TotalWords >= 500 }},
+	{models.Badge{ID: "streak_7", Emoji: "🔥", Name: "連續學習 7 天"}, func(s models.LearningStats) bool { return s.LongestStreak >= 7 }},
+	{models.Badge{ID: "streak_30", Emoji: "💎", Name: "連續學習 30 天"}, func(s models.LearningStats) bool { return s.LongestStreak >= 30 }},
+}
+
+// CalculateLearningStats computes totals, streaks and badges from the user's daily vocabulary records.
+// Dates are YYYY-MM-DD in UTC, matching how VocabularyRepository stores them.
+func CalculateLearningStats(vocabularies []models.UserVocabulary, now time.Time) models.LearningStats {
+	stats := models.LearningStats{WordsBySource: map[string]int{}, Badges: []models.Badge{}}
+
+	var days []time.Time
+	for _, vocabulary := range vocabularies {
+		if len(vocabulary.Words) == 0 {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", vocabulary.Date)
+		if err != nil {
+			continue
+		}
+		stats.TotalWords += len(vocabulary.Words)
+		for _, word := range vocabulary.Words {
+			stats.WordsBySource[word.WordSource()]++
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	stats.ActiveDays = len(days)
+
+	streak := 0
+	for i, day := range days {
+		if i > 0 && day.Sub(days[i-1]) == 24*time.Hour {
+			streak++
+		} else {
+			streak = 1
+		}
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+		}
+	}
+
+	// 最後一次學習是今天或昨天，連續紀錄才算還在進行中
+	if len(days) > 0 {
+		today, _ := time.Parse("2006-01-02", now.UTC().Format("2006-01-02"))
+		if today.Sub(days[len(days)-1]) <= 24*time.Hour {
+			stats.CurrentStreak = streak
+		}
+	}
+
+	for _, rule := range badgeRules {
+		if rule.earn(stats) {
+			stats.Badges = append(stats.Badges, rule.badge)
+		}
+	}
+
+	return stats
+}
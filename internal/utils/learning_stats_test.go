@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+func vocabularyOn(date string, count int) models.UserVocabulary {
+	return models.UserVocabulary{Date: date, Words: make([]models.WordRecord, count)}
+}
+
+func TestCalculateLearningStats(t *testing.T) {
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Current streak continues through yesterday", func(t *testing.T) {
+		stats := CalculateLearningStats([]models.UserVocabulary{
+			vocabularyOn("2025-05-01", 3),
+			vocabularyOn("2025-05-02", 2),
+			vocabularyOn("2025-05-03", 1),
+			vocabularyOn("2025-05-08", 4),
+			vocabularyOn("2025-05-09", 5),
+		}, now)
+
+		if stats.TotalWords != 15 || stats.ActiveDays != 5 {
+			t.Errorf("Expected 15 words over 5 days, got %d words over %d days", stats.TotalWords, stats.ActiveDays)
+		}
+		if stats.CurrentStreak != 2 || stats.LongestStreak != 3 {
+			t.Errorf("Expected current 2 / longest 3, got %d / %d", stats.CurrentStreak, stats.LongestStreak)
+		}
+		if len(stats.Badges) != 1 || stats.Badges[0].ID != "first_word" {
+			t.Errorf("Expected only first_word badge, got %+v", stats.Badges)
+		}
+	})
+
+	t.Run("Words counted by source", func(t *testing.T) {
+		stats := CalculateLearningStats([]models.UserVocabulary{{
+			Date: "2025-05-09",
+			Words: []models.WordRecord{
+				{Word: "resilient"},
+				{Word: "meticulous", Source: models.WordSourceTranslation},
+				{Word: "allocate", Source: models.WordSourceDailyPush},
+			},
+		}}, now)
+
+		if stats.WordsBySource[models.WordSourceTranslation] != 2 || stats.WordsBySource[models.WordSourceDailyPush] != 1 {
+			t.Errorf("Expected 2 translated and 1 daily push word, got %v", stats.WordsBySource)
+		}
+	})
+
+	t.Run("Streak broken and empty days ignored", func(t *testing.T) {
+		stats := CalculateLearningStats([]models.UserVocabulary{
+			vocabularyOn("2025-05-06", 1),
+			vocabularyOn("2025-05-07", 0),
+			vocabularyOn("2025-05-08", 0),
+		}, now)
+
+		if stats.CurrentStreak != 0 || stats.LongestStreak != 1 || stats.ActiveDays != 1 {
+			t.Errorf("Unexpected stats: %+v", stats)
+		}
+	})
+}
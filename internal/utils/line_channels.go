@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"sort"
+	"strings"
+)
+
+// LineChannelsEnv 同一個部署服務多個 LINE 官方帳號（例如多益與雅思各一個）時的 channel 設定，JSON 物件：
+// webhook 的 destination（官方帳號的 bot user ID）→ {"secret": "...", "token": "..."}。
+// 未列出的 destination 使用 CHANNEL_SECRET 與 CHANNEL_TOKEN
+const LineChannelsEnv = "LINE_CHANNELS"
+
+// LineChannel is the credential pair of one LINE official account
+type LineChannel struct {
+	Secret string `json:"secret"`
+	Token  string `json:"token"`
+}
+
+// LineChannels resolves the channel credentials for a webhook at request time, so one deployment can serve
+// several official accounts. Read-only after LoadLineChannels; safe for concurrent use
+type LineChannels struct {
+	Default       LineChannel
+	ByDestination map[string]LineChannel
+}
+
+// LoadLineChannels reads the default channel from CHANNEL_SECRET and CHANNEL_TOKEN, and the per-destination
+// channels from LINE_CHANNELS
+func LoadLineChannels(getenv func(string) string) (*LineChannels, error) {
+	channels := &LineChannels{
+		Default:       LineChannel{Secret: getenv("CHANNEL_SECRET"), Token: getenv("CHANNEL_TOKEN")},
+		ByDestination: map[string]LineChannel{},
+	}
+	if channels.Default.Secret == "" {
+		return nil, errors.New("CHANNEL_SECRET is not set")
+	}
+	if channels.Default.Token == "" {
+		return nil, errors.New("CHANNEL_TOKEN is not set")
+	}
+
+	value := strings.TrimSpace(getenv(LineChannelsEnv))
+	if value == "" {
+		return channels, nil
+	}
+	if err := json.Unmarshal([]byte(value), &channels.ByDestination); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object of destination to channel: %w", LineChannelsEnv, err)
+	}
+	for destination, channel := range channels.ByDestination {
+		if channel.Secret == "" || channel.Token == "" {
+			return nil, fmt.Errorf("%s: channel for destination %q needs both secret and token", LineChannelsEnv, destination)
+		}
+	}
+	return channels, nil
+}
+
+// Resolve 回傳 destination 對應的 channel；未設定的 destination 使用預設 channel（簽章不符時 webhook 仍會被拒絕）
+func (c *LineChannels) Resolve(destination string) LineChannel {
+	if channel, ok := c.ByDestination[destination]; ok {
+		return channel
+	}
+	return c.Default
+}
+
+// Destinations 依序列出有個別設定的 destination，用於啟動報告
+func (c *LineChannels) Destinations() []string {
+	destinations := make([]string, 0, len(c.ByDestination))
+	for destination := range c.ByDestination {
+		destinations = append(destinations, destination)
+	}
+	sort.Strings(destinations)
+	return destinations
+}
+
+// LineClients 各官方帳號的 LINE client。排程推播、付款通知與公告不經過 webhook，依用戶設定中記錄的
+// destination 選擇 client，訊息才會從用戶加入的官方帳號送出。Read-only after NewLineClients; safe for concurrent use
+type LineClients struct {
+	Default       LinebotAPI
+	ByDestination map[string]LinebotAPI
+}
+
+// NewLineClients creates one client per channel; wrap (e.g. fault injection) is applied to every client and may be nil
+func NewLineClients(channels *LineChannels, wrap func(LinebotAPI) LinebotAPI) (*LineClients, error) {
+	newClient := func(channel LineChannel) (LinebotAPI, error) {
+		client, err := NewLineBotClient(channel.Secret, channel.Token)
+		if err != nil || wrap == nil {
+			return client, err
+		}
+		return wrap(client), nil
+	}
+
+	defaultClient, err := newClient(channels.Default)
+	if err != nil {
+		return nil, err
+	}
+	clients := &LineClients{Default: defaultClient, ByDestination: map[string]LinebotAPI{}}
+	for destination, channel := range channels.ByDestination {
+		if clients.ByDestination[destination], err = newClient(channel); err != nil {
+			return nil, fmt.Errorf("channel for destination %q: %w", destination, err)
+		}
+	}
+	return clients, nil
+}
+
+// ClientFor 回傳用戶加入的官方帳號的 client；尚未記錄 destination 或 destination 沒有個別設定時使用預設 channel
+func (c *LineClients) ClientFor(userConfig *models.UserConfig) LinebotAPI {
+	if userConfig != nil {
+		if client, ok := c.ByDestination[userConfig.LineDestination]; ok {
+			return client
+		}
+	}
+	return c.Default
+}
+
+// WebhookDestination 取出 webhook body 的 destination，也就是收到事件的官方帳號；驗證簽章前就需要它來選擇 channel secret
+func WebhookDestination(body string) (string, error) {
+	var webhook struct {
+		Destination string `json:"destination"`
+	}
+	if err := json.Unmarshal([]byte(body), &webhook); err != nil {
+		return "", fmt.Errorf("failed to parse webhook destination: %w", err)
+	}
+	return webhook.Destination, nil
+}
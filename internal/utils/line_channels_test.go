@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestLoadLineChannels(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+	base := map[string]string{"CHANNEL_SECRET": "default-secret", "CHANNEL_TOKEN": "default-token"}
+
+	channels, err := LoadLineChannels(env(base))
+	if err != nil || channels.Resolve("Uany") != channels.Default || len(channels.Destinations()) != 0 {
+		t.Errorf("Expected only the default channel, got %+v (err %v)", channels, err)
+	}
+
+	base[LineChannelsEnv] = `{"Utoeic": {"secret": "toeic-secret", "token": "toeic-token"}}`
+	channels, err = LoadLineChannels(env(base))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if channel := channels.Resolve("Utoeic"); channel.Secret != "toeic-secret" || channel.Token != "toeic-token" {
+		t.Errorf("Expected the toeic channel, got %+v", channel)
+	}
+	if channel := channels.Resolve("Uunknown"); channel.Secret != "default-secret" {
+		t.Errorf("Expected unknown destinations to use the default channel, got %+v", channel)
+	}
+
+	base[LineChannelsEnv] = `{"Uielts": {"secret": "ielts-secret"}}`
+	if _, err := LoadLineChannels(env(base)); err == nil {
+		t.Error("Expected an error for a channel without a token")
+	}
+	if _, err := LoadLineChannels(env(map[string]string{"CHANNEL_TOKEN": "token"})); err == nil {
+		t.Error("Expected an error without the default secret")
+	}
+}
+
+func TestLineClientsClientFor(t *testing.T) {
+	channels := &LineChannels{
+		Default:       LineChannel{Secret: "default-secret", Token: "default-token"},
+		ByDestination: map[string]LineChannel{"Utoeic": {Secret: "toeic-secret", Token: "toeic-token"}},
+	}
+	clients, err := NewLineClients(channels, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	toeic := clients.ByDestination["Utoeic"]
+	if client := clients.ClientFor(&models.UserConfig{LineDestination: "Utoeic"}); client != toeic || client == clients.Default {
+		t.Error("Expected users of the toeic account to use its client")
+	}
+	for _, userConfig := range []*models.UserConfig{nil, {}, {LineDestination: "Uunknown"}} {
+		if client := clients.ClientFor(userConfig); client != clients.Default {
+			t.Errorf("Expected %+v to use the default client", userConfig)
+		}
+	}
+}
+
+func TestWebhookDestination(t *testing.T) {
+	destination, err := WebhookDestination(`{"destination": "Utoeic", "events": []}`)
+	if err != nil || destination != "Utoeic" {
+		t.Errorf("Expected Utoeic, got %q (err %v)", destination, err)
+	}
+	if _, err := WebhookDestination("not json"); err == nil {
+		t.Error("Expected an error for an invalid body")
+	}
+}
@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"language-assistant/internal/messages"
+	"language-assistant/internal/models"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/line/line-bot-sdk-go/v7/linebot"
 )
@@ -12,9 +17,65 @@ type LinebotAPI interface {
 	ReplyMessageWithMultiple(replyToken string, messages ...linebot.SendingMessage) error
 	ParseRequest(req *http.Request) ([]*linebot.Event, error)
 	PushMessage(userID string, message string) error
+	PushMessages(userID string, messages ...linebot.SendingMessage) error
+	PushFlexMessage(userID, altText string, contents linebot.FlexContainer, quickReplies *linebot.QuickReplyItems) error
+	PushAudioMessage(userID, audioURL string, duration time.Duration) error
 	GetProfile(userID string) (*linebot.UserProfileResponse, error)
+	GetGroupMemberProfile(groupID, userID string) (*linebot.UserProfileResponse, error)
+	LinkUserRichMenu(userID, richMenuID string) error
+	Multicast(userIDs []string, messages ...linebot.SendingMessage) error
+	Broadcast(messages ...linebot.SendingMessage) error
 }
 
+// PushInBatches 依 LINE 的單次上限（messages.MaxMessagesPerRequest）分批推播
+func PushInBatches(client LinebotAPI, userID string, sendingMessages []linebot.SendingMessage) error {
+	for start := 0; start < len(sendingMessages); start += messages.MaxMessagesPerRequest {
+		end := min(start+messages.MaxMessagesPerRequest, len(sendingMessages))
+		if err := client.PushMessages(userID, sendingMessages[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxMulticastRecipients LINE multicast 每次最多可指定的收件人數
+const MaxMulticastRecipients = 500
+
+// MulticastInBatches 依 MaxMulticastRecipients 分批 multicast，回傳已成功送出的收件人數；
+// 某一批失敗時停止，之後的批次不會送出
+func MulticastInBatches(client LinebotAPI, userIDs []string, sendingMessages []linebot.SendingMessage) (int, error) {
+	sent := 0
+	for start := 0; start < len(userIDs); start += MaxMulticastRecipients {
+		end := min(start+MaxMulticastRecipients, len(userIDs))
+		if err := client.Multicast(userIDs[start:end], sendingMessages...); err != nil {
+			return sent, err
+		}
+		sent = end
+	}
+	return sent, nil
+}
+
+// MaxBlockedPushes 連續幾次推播因用戶封鎖而失敗後，停用排程並將用戶標記為停用
+const MaxBlockedPushes = 3
+
+// IsUserUnreachable 判斷對單一用戶的查詢（GetProfile）是否回傳 404，代表用戶已封鎖官方帳號或已不是好友。
+// 403 等其他錯誤可能來自 channel 本身（權限被撤銷、token 設定錯誤），所有用戶都會失敗，不能當成用戶封鎖
+func IsUserUnreachable(err error) bool {
+	var apiErr *linebot.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
+
+// ConfirmUserUnreachable 推播失敗時以 GetProfile 確認是否為這位用戶無法觸及；推播的錯誤本身分不出用戶封鎖與 channel 的問題
+func ConfirmUserUnreachable(client LinebotAPI, userID string, pushErr error) bool {
+	var apiErr *linebot.APIError
+	if !errors.As(pushErr, &apiErr) {
+		return false
+	}
+	_, err := client.GetProfile(userID)
+	return IsUserUnreachable(err)
+}
+
+// LineBotClient wraps the LINE SDK client; safe for concurrent use
 type LineBotClient struct {
 	client *linebot.Client
 }
@@ -48,6 +109,221 @@ func (c *LineBotClient) PushMessage(userID string, message string) error {
 	return err
 }
 
+// PushMessages 一次推播多則訊息，LINE 限制每次最多 5 則
+func (c *LineBotClient) PushMessages(userID string, messages ...linebot.SendingMessage) error {
+	_, err := c.client.PushMessage(userID, messages...).Do()
+	return err
+}
+
+// PushFlexMessage 推播 Flex Message，quickReplies 為 nil 時不附加 Quick Reply
+func (c *LineBotClient) PushFlexMessage(userID, altText string, contents linebot.FlexContainer, quickReplies *linebot.QuickReplyItems) error {
+	message := linebot.NewFlexMessage(altText, contents)
+	if quickReplies != nil {
+		_, err := c.client.PushMessage(userID, message.WithQuickReplies(quickReplies)).Do()
+		return err
+	}
+	_, err := c.client.PushMessage(userID, message).Do()
+	return err
+}
+
+func (c *LineBotClient) PushAudioMessage(userID, audioURL string, duration time.Duration) error {
+	_, err := c.client.PushMessage(userID, linebot.NewAudioMessage(audioURL, int(duration.Milliseconds()))).Do()
+	return err
+}
+
 func (c *LineBotClient) GetProfile(userID string) (*linebot.UserProfileResponse, error) {
 	return c.client.GetProfile(userID).Do()
 }
+
+// GetGroupMemberProfile 取得群組成員的名稱，成員不需要加 bot 為好友
+func (c *LineBotClient) GetGroupMemberProfile(groupID, userID string) (*linebot.UserProfileResponse, error) {
+	return c.client.GetGroupMemberProfile(groupID, userID).Do()
+}
+
+// LinkUserRichMenu 將圖文選單綁定到用戶，取代預設選單
+func (c *LineBotClient) LinkUserRichMenu(userID, richMenuID string) error {
+	_, err := c.client.LinkUserRichMenu(userID, richMenuID).Do()
+	return err
+}
+
+// Multicast 推播給多位用戶，LINE 限制每次最多 MaxMulticastRecipients 人
+func (c *LineBotClient) Multicast(userIDs []string, messages ...linebot.SendingMessage) error {
+	_, err := c.client.Multicast(userIDs, messages...).Do()
+	return err
+}
+
+// Broadcast 推播給所有加入官方帳號好友的用戶
+func (c *LineBotClient) Broadcast(messages ...linebot.SendingMessage) error {
+	_, err := c.client.BroadcastMessage(messages...).Do()
+	return err
+}
+
+// MaxFlexCarouselBubbles LINE 的 carousel 最多只能放 12 個 bubble
+const MaxFlexCarouselBubbles = 12
+
+// BuildWordCarousels 將每日單字排成 Flex carousel，每個單字一個 bubble（單字、詞性、意思、例句與「加入複習」、「回報內容問題」按鈕）。
+// 超過 MaxFlexCarouselBubbles 個單字時分成多個 carousel
+func BuildWordCarousels(words []Word, reviewPostbackData func(Word) string) []*linebot.CarouselContainer {
+	var carousels []*linebot.CarouselContainer
+	for start := 0; start < len(words); start += MaxFlexCarouselBubbles {
+		end := start + MaxFlexCarouselBubbles
+		if end > len(words) {
+			end = len(words)
+		}
+
+		carousel := &linebot.CarouselContainer{Type: linebot.FlexContainerTypeCarousel}
+		for i, word := range words[start:end] {
+			carousel.Contents = append(carousel.Contents, wordBubble(word, start+i+1, len(words), reviewPostbackData(word)))
+		}
+		carousels = append(carousels, carousel)
+	}
+	return carousels
+}
+
+func wordBubble(word Word, index, total int, reviewPostbackData string) *linebot.BubbleContainer {
+	subtitle := fmt.Sprintf("%d/%d", index, total)
+	if word.PartOfSpeech != "" {
+		subtitle += " · " + word.PartOfSpeech
+	}
+	if cefr := NormalizeCEFR(word.Difficulty); cefr != "" {
+		subtitle += " · " + DifficultyOf(word.Difficulty).Emoji() + " " + cefr
+	}
+
+	contents := []linebot.FlexComponent{
+		&linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: word.Word, Size: linebot.FlexTextSizeTypeXl, Weight: linebot.FlexTextWeightTypeBold, Wrap: true},
+	}
+	if kana := DisplayReading(word.Word, word.Reading); kana != "" {
+		contents = append(contents, &linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: kana, Size: linebot.FlexTextSizeTypeSm, Wrap: true})
+	}
+	if ipa := DisplayIPA(word.IPA); ipa != "" {
+		contents = append(contents, &linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: ipa, Size: linebot.FlexTextSizeTypeSm, Wrap: true})
+	}
+	contents = append(contents,
+		&linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: subtitle, Size: linebot.FlexTextSizeTypeSm, Color: "#888888", Wrap: true},
+		&linebot.SeparatorComponent{Type: linebot.FlexComponentTypeSeparator, Margin: linebot.FlexComponentMarginTypeMd},
+		&linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: word.Meaning, Size: linebot.FlexTextSizeTypeMd, Margin: linebot.FlexComponentMarginTypeMd, Wrap: true},
+	)
+	// Flex 的 text 不可為空字串，沒有例句時省略
+	if word.Example.En != "" {
+		contents = append(contents, &linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: word.Example.En, Size: linebot.FlexTextSizeTypeSm, Style: linebot.FlexTextStyleTypeItalic, Margin: linebot.FlexComponentMarginTypeMd, Wrap: true})
+	}
+	if word.Example.Reading != "" {
+		contents = append(contents, &linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: word.Example.Reading, Size: linebot.FlexTextSizeTypeXs, Color: "#888888", Wrap: true})
+	}
+	if word.Example.Zh != "" {
+		contents = append(contents, &linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: word.Example.Zh, Size: linebot.FlexTextSizeTypeSm, Color: "#888888", Wrap: true})
+	}
+
+	label := messages.Text(messages.DailyPushReviewAddLabel)
+	footer := []linebot.FlexComponent{
+		&linebot.ButtonComponent{
+			Type:   linebot.FlexComponentTypeButton,
+			Style:  linebot.FlexButtonStyleTypePrimary,
+			Height: linebot.FlexButtonHeightTypeSm,
+			Action: linebot.NewPostbackAction(label, reviewPostbackData, "", label+" "+word.Word, "", ""),
+		},
+	}
+	if reportData := ContentReportPostbackData(models.ContentKindDailyWord, word.Word, word.PartOfSpeech, word.Meaning, word.Example.En, ""); reportData != "" {
+		reportLabel := messages.Text(messages.ContentReportLabel)
+		footer = append(footer, &linebot.ButtonComponent{
+			Type:   linebot.FlexComponentTypeButton,
+			Style:  linebot.FlexButtonStyleTypeLink,
+			Height: linebot.FlexButtonHeightTypeSm,
+			Action: linebot.NewPostbackAction(reportLabel, reportData, "", reportLabel+" "+word.Word, "", ""),
+		})
+	}
+	return &linebot.BubbleContainer{
+		Type: linebot.FlexContainerTypeBubble,
+		Size: linebot.FlexBubbleSizeTypeKilo,
+		Body: &linebot.BoxComponent{
+			Type:     linebot.FlexComponentTypeBox,
+			Layout:   linebot.FlexBoxLayoutTypeVertical,
+			Spacing:  linebot.FlexComponentSpacingTypeSm,
+			Contents: contents,
+		},
+		Footer: &linebot.BoxComponent{
+			Type:     linebot.FlexComponentTypeBox,
+			Layout:   linebot.FlexBoxLayoutTypeVertical,
+			Contents: footer,
+		},
+	}
+}
+
+// vocabularyHistoryBubbleWords /歷史 每天的卡片最多列出幾個單字，其餘只顯示數量
+const vocabularyHistoryBubbleWords = 5
+
+// BuildVocabularyHistoryCarousel 將單字庫紀錄排成 Flex carousel，每天一個 bubble（日期、單字數與前幾個單字）。
+// 超過 MaxFlexCarouselBubbles 天時只取前面的日期，呼叫端應以不超過上限的頁數分頁
+func BuildVocabularyHistoryCarousel(vocabularies []models.UserVocabulary) *linebot.CarouselContainer {
+	if len(vocabularies) > MaxFlexCarouselBubbles {
+		vocabularies = vocabularies[:MaxFlexCarouselBubbles]
+	}
+	carousel := &linebot.CarouselContainer{Type: linebot.FlexContainerTypeCarousel}
+	for _, vocabulary := range vocabularies {
+		carousel.Contents = append(carousel.Contents, vocabularyDayBubble(vocabulary))
+	}
+	return carousel
+}
+
+func vocabularyDayBubble(vocabulary models.UserVocabulary) *linebot.BubbleContainer {
+	contents := []linebot.FlexComponent{
+		&linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: vocabulary.Date, Size: linebot.FlexTextSizeTypeLg, Weight: linebot.FlexTextWeightTypeBold},
+		&linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: messages.Render(messages.VocabularyHistoryWordCount, messages.Data{"Count": len(vocabulary.Words)}), Size: linebot.FlexTextSizeTypeSm, Color: "#888888"},
+		&linebot.SeparatorComponent{Type: linebot.FlexComponentTypeSeparator, Margin: linebot.FlexComponentMarginTypeMd},
+	}
+	for i, word := range vocabulary.Words {
+		if i == vocabularyHistoryBubbleWords {
+			more := messages.Render(messages.VocabularyHistoryMore, messages.Data{"Count": len(vocabulary.Words) - i})
+			contents = append(contents, &linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: more, Size: linebot.FlexTextSizeTypeXs, Color: "#888888", Margin: linebot.FlexComponentMarginTypeMd})
+			break
+		}
+		line := word.Word
+		if word.Translation != "" {
+			line += "  " + word.Translation
+		}
+		contents = append(contents, &linebot.TextComponent{Type: linebot.FlexComponentTypeText, Text: line, Size: linebot.FlexTextSizeTypeSm, Margin: linebot.FlexComponentMarginTypeSm, Wrap: true})
+	}
+
+	return &linebot.BubbleContainer{
+		Type: linebot.FlexContainerTypeBubble,
+		Size: linebot.FlexBubbleSizeTypeKilo,
+		Body: &linebot.BoxComponent{
+			Type:     linebot.FlexComponentTypeBox,
+			Layout:   linebot.FlexBoxLayoutTypeVertical,
+			Spacing:  linebot.FlexComponentSpacingTypeSm,
+			Contents: contents,
+		},
+	}
+}
+
+// maxPostbackDataLength LINE postback data 的長度上限
+const maxPostbackDataLength = 300
+
+// ReviewAddPostbackData 產生「加入複習」按鈕的 postback data，
+// 帶上單字內容讓 handler 不必再查一次；超過 LINE 的長度上限時捨棄例句
+func ReviewAddPostbackData(word Word) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackReviewAdd)
+	values.Set("word", word.Word)
+	values.Set("pos", word.PartOfSpeech)
+	values.Set("meaning", word.Meaning)
+	values.Set("example", word.Example.En)
+	if data := values.Encode(); len(data) <= maxPostbackDataLength {
+		return data
+	}
+	values.Del("example")
+	return values.Encode()
+}
+
+// RegenerateExamplePostbackData 產生「換個例句」按鈕的 postback data。單字依 date（UTC，與單字庫相同）從單字庫
+// 取回詞性與中文意思，因此只帶單字本身；單字過長時回傳空字串，不顯示按鈕
+func RegenerateExamplePostbackData(word, date string) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackRegenerateExample)
+	values.Set("word", word)
+	values.Set("date", date)
+	if data := values.Encode(); len(data) <= maxPostbackDataLength {
+		return data
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+func TestBuildWordCarousels(t *testing.T) {
+	var words []Word
+	for i := 0; i < MaxFlexCarouselBubbles+1; i++ {
+		words = append(words, Word{Word: fmt.Sprintf("word%d", i), PartOfSpeech: "n.", Meaning: "意思", Difficulty: "B1"})
+	}
+	words[0].Example = Example{En: "An example.", Zh: "一個例句。"}
+
+	carousels := BuildWordCarousels(words, ReviewAddPostbackData)
+	if len(carousels) != 2 || len(carousels[0].Contents) != MaxFlexCarouselBubbles || len(carousels[1].Contents) != 1 {
+		t.Fatalf("Expected carousels to be split at %d bubbles, got %d carousels", MaxFlexCarouselBubbles, len(carousels))
+	}
+
+	withExample := carousels[0].Contents[0].Body.Contents
+	withoutExample := carousels[0].Contents[1].Body.Contents
+	if len(withExample) != len(withoutExample)+2 {
+		t.Errorf("Expected empty examples to be omitted, got %d and %d components", len(withExample), len(withoutExample))
+	}
+
+	button, ok := carousels[1].Contents[0].Footer.Contents[0].(*linebot.ButtonComponent)
+	if !ok {
+		t.Fatalf("Expected footer to contain the review button")
+	}
+	values, _ := url.ParseQuery(button.Action.(*linebot.PostbackAction).Data)
+	if values.Get("action") != models.PostbackReviewAdd || values.Get("word") != "word12" {
+		t.Errorf("Unexpected postback data: %v", values)
+	}
+}
+
+func TestBuildVocabularyHistoryCarousel(t *testing.T) {
+	var vocabularies []models.UserVocabulary
+	for i := 0; i < MaxFlexCarouselBubbles+1; i++ {
+		vocabularies = append(vocabularies, models.UserVocabulary{Date: fmt.Sprintf("2026-10-%02d", i+1)})
+	}
+	for i := 0; i < vocabularyHistoryBubbleWords+2; i++ {
+		vocabularies[0].Words = append(vocabularies[0].Words, models.WordRecord{Word: fmt.Sprintf("word%d", i), Translation: "意思"})
+	}
+
+	carousel := BuildVocabularyHistoryCarousel(vocabularies)
+	if len(carousel.Contents) != MaxFlexCarouselBubbles {
+		t.Fatalf("Expected at most %d bubbles, got %d", MaxFlexCarouselBubbles, len(carousel.Contents))
+	}
+
+	// 日期、單字數、分隔線、前幾個單字，其餘以一行「還有 N 個」帶過
+	components := carousel.Contents[0].Body.Contents
+	if len(components) != 3+vocabularyHistoryBubbleWords+1 {
+		t.Fatalf("Expected the word list to be truncated, got %d components", len(components))
+	}
+	if more := components[len(components)-1].(*linebot.TextComponent).Text; !strings.Contains(more, "2") {
+		t.Errorf("Expected the remaining word count, got %q", more)
+	}
+	if empty := carousel.Contents[1].Body.Contents; len(empty) != 3 {
+		t.Errorf("Expected a day without words to show only the header, got %d components", len(empty))
+	}
+}
+
+func TestReviewAddPostbackDataDropsLongExample(t *testing.T) {
+	word := Word{Word: "serendipity", PartOfSpeech: "n.", Meaning: "意外發現美好事物的運氣", Example: Example{En: strings.Repeat("long example ", 30)}}
+
+	data := ReviewAddPostbackData(word)
+	if len(data) > maxPostbackDataLength {
+		t.Fatalf("Expected postback data within %d chars, got %d", maxPostbackDataLength, len(data))
+	}
+	values, _ := url.ParseQuery(data)
+	if values.Get("word") != "serendipity" || values.Has("example") {
+		t.Errorf("Expected example to be dropped, got %v", values)
+	}
+}
+
+func TestRegenerateExamplePostbackData(t *testing.T) {
+	values, _ := url.ParseQuery(RegenerateExamplePostbackData("serendipity", "2024-03-01"))
+	if values.Get("action") != models.PostbackRegenerateExample || values.Get("word") != "serendipity" || values.Get("date") != "2024-03-01" {
+		t.Errorf("Unexpected postback data: %v", values)
+	}
+	if data := RegenerateExamplePostbackData(strings.Repeat("a", maxPostbackDataLength), "2024-03-01"); data != "" {
+		t.Errorf("Expected no postback data for an overlong word, got %d chars", len(data))
+	}
+}
+
+func TestIsUserUnreachable(t *testing.T) {
+	notFound := fmt.Errorf("failed to get profile: %w", &linebot.APIError{Code: http.StatusNotFound})
+	if !IsUserUnreachable(notFound) {
+		t.Error("Expected wrapped 404 to be treated as unreachable")
+	}
+	// channel 權限被撤銷或 token 錯誤時所有用戶都會失敗，不能當成用戶封鎖
+	if IsUserUnreachable(&linebot.APIError{Code: http.StatusForbidden}) {
+		t.Error("Expected 403 not to be treated as unreachable")
+	}
+	if IsUserUnreachable(&linebot.APIError{Code: http.StatusBadRequest, Response: &linebot.ErrorResponse{Message: "The user has blocked the bot"}}) {
+		t.Error("Expected the error message alone not to be treated as unreachable")
+	}
+	if IsUserUnreachable(&linebot.APIError{Code: http.StatusTooManyRequests}) {
+		t.Error("Expected 429 to be retryable, not unreachable")
+	}
+	if IsUserUnreachable(errors.New("network error")) {
+		t.Error("Expected non-API errors to be retryable")
+	}
+}
+
+// profileLookup 回傳指定的 GetProfile 錯誤並記錄呼叫次數
+type profileLookup struct {
+	LinebotAPI
+	err   error
+	calls int
+}
+
+func (p *profileLookup) GetProfile(userID string) (*linebot.UserProfileResponse, error) {
+	p.calls++
+	return &linebot.UserProfileResponse{UserID: userID}, p.err
+}
+
+func TestConfirmUserUnreachable(t *testing.T) {
+	pushErr := fmt.Errorf("failed to push message to user: %w", &linebot.APIError{Code: http.StatusForbidden})
+	if !ConfirmUserUnreachable(&profileLookup{err: &linebot.APIError{Code: http.StatusNotFound}}, "U1", pushErr) {
+		t.Error("Expected a 404 profile to confirm the user is unreachable")
+	}
+	if ConfirmUserUnreachable(&profileLookup{err: &linebot.APIError{Code: http.StatusForbidden}}, "U1", pushErr) {
+		t.Error("Expected a channel-wide 403 not to confirm the user is unreachable")
+	}
+	lookup := &profileLookup{}
+	if ConfirmUserUnreachable(lookup, "U1", errors.New("network error")) || lookup.calls != 0 {
+		t.Errorf("Expected non-API push errors to skip the profile check, got %d calls", lookup.calls)
+	}
+}
+
+// multicastRecorder 記錄每次 multicast 的收件人數，failAt 指定第幾次呼叫（從 1 起算）失敗
+type multicastRecorder struct {
+	LinebotAPI
+	batches []int
+	failAt  int
+}
+
+func (m *multicastRecorder) Multicast(userIDs []string, messages ...linebot.SendingMessage) error {
+	if len(m.batches)+1 == m.failAt {
+		return errors.New("multicast failed")
+	}
+	m.batches = append(m.batches, len(userIDs))
+	return nil
+}
+
+func TestMulticastInBatches(t *testing.T) {
+	userIDs := make([]string, 2*MaxMulticastRecipients+1)
+	for i := range userIDs {
+		userIDs[i] = fmt.Sprintf("U%d", i)
+	}
+	msgs := []linebot.SendingMessage{linebot.NewTextMessage("公告")}
+
+	t.Run("splits at the recipient limit", func(t *testing.T) {
+		client := &multicastRecorder{}
+		sent, err := MulticastInBatches(client, userIDs, msgs)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if sent != len(userIDs) {
+			t.Errorf("Expected %d recipients sent, got %d", len(userIDs), sent)
+		}
+		if len(client.batches) != 3 || client.batches[0] != MaxMulticastRecipients || client.batches[2] != 1 {
+			t.Errorf("Expected batches of %d, %d and 1, got %v", MaxMulticastRecipients, MaxMulticastRecipients, client.batches)
+		}
+	})
+
+	t.Run("stops at the first failed batch", func(t *testing.T) {
+		client := &multicastRecorder{failAt: 2}
+		sent, err := MulticastInBatches(client, userIDs, msgs)
+		if err == nil {
+			t.Fatal("Expected an error from the failed batch")
+		}
+		if sent != MaxMulticastRecipients || len(client.batches) != 1 {
+			t.Errorf("Expected only the first batch to be sent, got %d recipients in %d batches", sent, len(client.batches))
+		}
+	})
+
+	t.Run("no recipients", func(t *testing.T) {
+		client := &multicastRecorder{}
+		if sent, err := MulticastInBatches(client, nil, msgs); err != nil || sent != 0 || len(client.batches) != 0 {
+			t.Errorf("Expected nothing to be sent, got %d recipients, %d batches, err %v", sent, len(client.batches), err)
+		}
+	})
+}
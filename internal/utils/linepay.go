@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LinePaySandboxURL 未設定 LINEPAY_API_URL 時使用的測試環境
+	LinePaySandboxURL    = "https://sandbox-api-pay.line.me"
+	LinePayProductionURL = "https://api-pay.line.me"
+
+	// DefaultSupporterPrice 未設定 SUPPORTER_PRICE 時支持者方案的金額
+	DefaultSupporterPrice = 150
+	// DefaultSupporterCurrency 未設定 SUPPORTER_CURRENCY 時的幣別
+	DefaultSupporterCurrency = "TWD"
+
+	// LINE Pay 回傳 0000 表示成功，其他代碼都是失敗
+	linePaySuccessCode = "0000"
+	linePayTimeout     = 10 * time.Second
+)
+
+// LinePayConfig is the LINE Pay merchant setting and the supporter plan price
+type LinePayConfig struct {
+	ChannelID     string
+	ChannelSecret string
+	APIURL        string
+	CallbackURL   string // language-payment 的網址，付款完成或取消後 LINE Pay 會導回這裡
+	Price         int
+	Currency      string
+}
+
+// LoadLinePayConfig reads LINEPAY_CHANNEL_ID, LINEPAY_CHANNEL_SECRET, LINEPAY_API_URL, PAYMENT_CALLBACK_URL,
+// SUPPORTER_PRICE and SUPPORTER_CURRENCY. Returns nil when LINEPAY_CHANNEL_ID is unset, meaning payments are disabled
+func LoadLinePayConfig(getenv func(string) string) (*LinePayConfig, error) {
+	channelID := strings.TrimSpace(getenv("LINEPAY_CHANNEL_ID"))
+	if channelID == "" {
+		return nil, nil
+	}
+
+	config := &LinePayConfig{
+		ChannelID:     channelID,
+		ChannelSecret: getenv("LINEPAY_CHANNEL_SECRET"),
+		APIURL:        strings.TrimRight(getenv("LINEPAY_API_URL"), "/"),
+		CallbackURL:   strings.TrimRight(getenv("PAYMENT_CALLBACK_URL"), "/"),
+		Price:         DefaultSupporterPrice,
+		Currency:      strings.ToUpper(strings.TrimSpace(getenv("SUPPORTER_CURRENCY"))),
+	}
+	if config.ChannelSecret == "" {
+		return nil, errors.New("LINEPAY_CHANNEL_SECRET is not set")
+	}
+	if config.CallbackURL == "" {
+		return nil, errors.New("PAYMENT_CALLBACK_URL is not set")
+	}
+	if config.APIURL == "" {
+		config.APIURL = LinePaySandboxURL
+	}
+	if config.Currency == "" {
+		config.Currency = DefaultSupporterCurrency
+	}
+	if value := strings.TrimSpace(getenv("SUPPORTER_PRICE")); value != "" {
+		price, err := strconv.Atoi(value)
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("SUPPORTER_PRICE must be a positive integer, got %q", value)
+		}
+		config.Price = price
+	}
+	return config, nil
+}
+
+// LinePayAPI is the subset of the LINE Pay v3 online API the bot uses
+type LinePayAPI interface {
+	RequestPayment(request LinePayRequest) (*LinePayRequestResult, error)
+	ConfirmPayment(transactionID string, amount int, currency string) error
+}
+
+// LinePayRequest 建立一筆付款所需的資料
+type LinePayRequest struct {
+	OrderID     string
+	Amount      int
+	Currency    string
+	ProductName string
+	ConfirmURL  string // 付款後導回的網址，LINE Pay 會附上 transactionId 與 orderId
+	CancelURL   string
+}
+
+// LinePayRequestResult 付款建立後讓用戶前往付款的網址與 LINE Pay 的交易編號
+type LinePayRequestResult struct {
+	TransactionID string
+	PaymentURL    string
+}
+
+// LinePayError is a non-success returnCode from LINE Pay
+type LinePayError struct {
+	Code    string
+	Message string
+}
+
+func (e *LinePayError) Error() string {
+	return fmt.Sprintf("LINE Pay returned %s: %s", e.Code, e.Message)
+}
+
+type LinePayClient struct {
+	channelID     string
+	channelSecret string
+	baseURL       string
+	httpClient    *http.Client
+}
+
+func NewLinePayClient(config LinePayConfig) *LinePayClient {
+	return &LinePayClient{
+		channelID:     config.ChannelID,
+		channelSecret: config.ChannelSecret,
+		baseURL:       config.APIURL,
+		httpClient:    &http.Client{Timeout: linePayTimeout},
+	}
+}
+
+type linePayProduct struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Price    int    `json:"price"`
+}
+
+type linePayPackage struct {
+	ID       string           `json:"id"`
+	Amount   int              `json:"amount"`
+	Products []linePayProduct `json:"products"`
+}
+
+type linePayRequestBody struct {
+	Amount       int              `json:"amount"`
+	Currency     string           `json:"currency"`
+	OrderID      string           `json:"orderId"`
+	Packages     []linePayPackage `json:"packages"`
+	RedirectURLs struct {
+		ConfirmURL string `json:"confirmUrl"`
+		CancelURL  string `json:"cancelUrl"`
+	} `json:"redirectUrls"`
+}
+
+type linePayResponse struct {
+	ReturnCode    string          `json:"returnCode"`
+	ReturnMessage string          `json:"returnMessage"`
+	Info          json.RawMessage `json:"info"`
+}
+
+// RequestPayment 建立付款（POST /v3/payments/request），用戶需在回傳的網址完成付款
+func (c *LinePayClient) RequestPayment(request LinePayRequest) (*LinePayRequestResult, error) {
+	body := linePayRequestBody{
+		Amount:   request.Amount,
+		Currency: request.Currency,
+		OrderID:  request.OrderID,
+		Packages: []linePayPackage{{
+			ID:       request.OrderID,
+			Amount:   request.Amount,
+			Products: []linePayProduct{{Name: request.ProductName, Quantity: 1, Price: request.Amount}},
+		}},
+	}
+	body.RedirectURLs.ConfirmURL = request.ConfirmURL
+	body.RedirectURLs.CancelURL = request.CancelURL
+
+	info, err := c.post("/v3/payments/request", body)
+	if err != nil {
+		return nil, err
+	}
+
+	// transactionId 是 19 位數的整數，以 json.Number 接收避免轉成 float64 失去精度
+	var result struct {
+		TransactionID json.Number `json:"transactionId"`
+		PaymentURL    struct {
+			Web string `json:"web"`
+		} `json:"paymentUrl"`
+	}
+	if err := json.Unmarshal(info, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse LINE Pay request info: %w", err)
+	}
+	if result.TransactionID == "" || result.PaymentURL.Web == "" {
+		return nil, errors.New("LINE Pay response is missing transactionId or paymentUrl")
+	}
+	return &LinePayRequestResult{TransactionID: result.TransactionID.String(), PaymentURL: result.PaymentURL.Web}, nil
+}
+
+// ConfirmPayment 用戶在 LINE Pay 授權後確認付款（POST /v3/payments/{transactionId}/confirm），成功後才會實際扣款
+func (c *LinePayClient) ConfirmPayment(transactionID string, amount int, currency string) error {
+	body := map[string]interface{}{"amount": amount, "currency": currency}
+	_, err := c.post("/v3/payments/"+transactionID+"/confirm", body)
+	return err
+}
+
+// post 以 LINE Pay 的 HMAC 簽章送出請求，returnCode 不是 0000 時回傳 *LinePayError
+func (c *LinePayClient) post(uri string, payload interface{}) (json.RawMessage, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal LINE Pay request: %w", err)
+	}
+	nonce, err := linePayNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LINE Pay request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-LINE-ChannelId", c.channelID)
+	req.Header.Set("X-LINE-Authorization-Nonce", nonce)
+	req.Header.Set("X-LINE-Authorization", LinePaySignature(c.channelSecret, uri, string(body), nonce))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call LINE Pay %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LINE Pay response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LINE Pay %s returned HTTP %d", uri, resp.StatusCode)
+	}
+
+	var result linePayResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse LINE Pay response: %w", err)
+	}
+	if result.ReturnCode != linePaySuccessCode {
+		return nil, &LinePayError{Code: result.ReturnCode, Message: result.ReturnMessage}
+	}
+	return result.Info, nil
+}
+
+// LinePaySignature 計算 X-LINE-Authorization：以 channel secret 為 key，
+// 對 channelSecret + uri + body + nonce 做 HMAC-SHA256 後 base64 編碼
+func LinePaySignature(channelSecret, uri, body, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(channelSecret))
+	mac.Write([]byte(channelSecret + uri + body + nonce))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// linePayNonce 每個請求都要帶不重複的 nonce
+func linePayNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate LINE Pay nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
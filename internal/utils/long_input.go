@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 超過任一門檻即視為貼上的文章，改用摘要翻譯模式
+const (
+	longInputMinEnglishWords = 40
+	longInputMinCJKRunes     = 80
+	longInputMinRunes        = 300
+	ArticleKeyWordCount      = 5
+)
+
+// 文法模式下，至少這麼多個英文單字且以句號、問號或驚嘆號結尾才視為完整句子；更長的句子不需要標點
+const (
+	fullSentenceMinWords          = 4
+	fullSentenceUnpunctuatedWords = 6
+)
+
+// IsLongInput reports whether the text looks like a pasted article rather than a word or short phrase
+func IsLongInput(text string) bool {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) >= longInputMinRunes {
+		return true
+	}
+
+	cjk := 0
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			cjk++
+		}
+	}
+	if cjk >= longInputMinCJKRunes {
+		return true
+	}
+
+	englishWords := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return r < unicode.MaxASCII && unicode.IsLetter(r) }) >= 0 {
+			englishWords++
+		}
+	}
+	return englishWords >= longInputMinEnglishWords
+}
+
+// IsFullSentence reports whether the text looks like a complete English sentence rather than a word or phrase;
+// text containing Chinese is never a sentence to correct
+func IsFullSentence(text string) bool {
+	text = strings.TrimSpace(text)
+	if strings.IndexFunc(text, func(r rune) bool { return unicode.Is(unicode.Han, r) }) >= 0 {
+		return false
+	}
+
+	words := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return r < unicode.MaxASCII && unicode.IsLetter(r) }) >= 0 {
+			words++
+		}
+	}
+	if words >= fullSentenceUnpunctuatedWords {
+		return true
+	}
+	return words >= fullSentenceMinWords && strings.ContainsAny(text[len(text)-1:], ".?!")
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsLongInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"Single word", "happy", false},
+		{"Short sentence", "I am looking forward to the weekend.", false},
+		{"Chinese phrase", "杞人憂天", false},
+		{"English paragraph", strings.Repeat("The committee postponed the decision again. ", 8), true},
+		{"Chinese article", strings.Repeat("今天天氣很好，我們一起去公園散步。", 6), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLongInput(tt.input); got != tt.expected {
+				t.Errorf("IsLongInput(%q) = %v, expected %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsFullSentence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"happy", false},
+		{"look forward to", false},
+		{"She go to school.", true},
+		{"Where is the station?", true},
+		{"She go to school", false},
+		{"yesterday i go to the park with my friend", true},
+		{"I am 快樂 today.", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsFullSentence(tt.input); got != tt.expected {
+			t.Errorf("IsFullSentence(%q) = %v, expected %v", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// MediaKind groups media that share a URL expiry policy
+type MediaKind string
+
+const (
+	MediaExport MediaKind = "EXPORT" // PDF 學習單等匯出檔
+	MediaImage  MediaKind = "IMAGE"  // QR Code、分享卡片等圖片
+	MediaAudio  MediaKind = "AUDIO"  // 單字發音
+)
+
+var defaultMediaURLExpiry = map[MediaKind]time.Duration{
+	MediaExport: time.Hour,
+	MediaImage:  24 * time.Hour,
+	MediaAudio:  24 * time.Hour,
+}
+
+// 部分執行環境沒有 /etc/mime.types，常用格式直接對應
+var mediaContentTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+}
+
+// MediaService uploads generated media and hands out time-limited presigned URLs for it.
+// Configuration is read-only after NewMediaService; safe for concurrent use
+type MediaService struct {
+	storage ObjectStorageAPI
+	expiry  map[MediaKind]time.Duration
+}
+
+func NewMediaService(storage ObjectStorageAPI, expiry map[MediaKind]time.Duration) *MediaService {
+	return &MediaService{
+		storage: storage,
+		expiry:  expiry,
+	}
+}
+
+// LoadMediaURLExpiry reads MEDIA_URL_EXPIRY_<KIND> (Go duration, e.g. "30m") for every media kind,
+// falling back to the defaults when unset
+func LoadMediaURLExpiry(getenv func(string) string) (map[MediaKind]time.Duration, error) {
+	expiry := make(map[MediaKind]time.Duration, len(defaultMediaURLExpiry))
+	for kind, defaultExpiry := range defaultMediaURLExpiry {
+		expiry[kind] = defaultExpiry
+
+		name := "MEDIA_URL_EXPIRY_" + string(kind)
+		value := getenv(name)
+		if value == "" {
+			continue
+		}
+		// S3 presigned URL 最長 7 天
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 || duration > 7*24*time.Hour {
+			return nil, fmt.Errorf("%s must be a duration between 0 and 168h, got %q", name, value)
+		}
+		expiry[kind] = duration
+	}
+	return expiry, nil
+}
+
+// ContentTypeFor returns the content type for an object key based on its extension
+func ContentTypeFor(key string) string {
+	ext := strings.ToLower(path.Ext(key))
+	if contentType, ok := mediaContentTypes[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// Expiry returns how long URLs for the given kind stay valid
+func (s *MediaService) Expiry(kind MediaKind) time.Duration {
+	if expiry, ok := s.expiry[kind]; ok {
+		return expiry
+	}
+	return defaultMediaURLExpiry[kind]
+}
+
+// Publish uploads the media and returns a presigned URL valid for the kind's expiry
+func (s *MediaService) Publish(kind MediaKind, key string, body []byte) (string, error) {
+	if err := s.storage.PutObject(key, body, ContentTypeFor(key)); err != nil {
+		return "", err
+	}
+	return s.URL(kind, key)
+}
+
+// Store uploads an object without handing out a URL (e.g. captured webhook payloads read back by tooling)
+func (s *MediaService) Store(key string, body []byte) error {
+	return s.storage.PutObject(key, body, ContentTypeFor(key))
+}
+
+// URL returns a presigned URL for media that is already stored (e.g. cached audio)
+func (s *MediaService) URL(kind MediaKind, key string) (string, error) {
+	return s.storage.PresignGetObject(key, s.Expiry(kind))
+}
+
+// Exists reports whether media has already been stored under the key
+func (s *MediaService) Exists(key string) (bool, error) {
+	return s.storage.ObjectExists(key)
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContentTypeFor(t *testing.T) {
+	tests := map[string]string{
+		"exports/a/b.pdf":    "application/pdf",
+		"pairing/a/CODE.PNG": "image/png",
+		"audio/alloy/x.mp3":  "audio/mpeg",
+		"unknown/file":       "application/octet-stream",
+	}
+	for key, expected := range tests {
+		if got := ContentTypeFor(key); got != expected {
+			t.Errorf("ContentTypeFor(%q) = %q, expected %q", key, got, expected)
+		}
+	}
+}
+
+func TestLoadMediaURLExpiry(t *testing.T) {
+	expiry, err := LoadMediaURLExpiry(func(key string) string {
+		if key == "MEDIA_URL_EXPIRY_EXPORT" {
+			return "30m"
+		}
+		return ""
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expiry[MediaExport] != 30*time.Minute || expiry[MediaAudio] != 24*time.Hour {
+		t.Errorf("Unexpected expiry: %v", expiry)
+	}
+
+	if _, err := LoadMediaURLExpiry(func(string) string { return "200h" }); err == nil {
+		t.Error("Expected error for expiry beyond the presign limit")
+	}
+}
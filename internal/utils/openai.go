@@ -1,11 +1,16 @@
 package utils
 
 import (
-	"context"
-	_ "embed"
+	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"language-assistant/internal/courses"
+	"language-assistant/internal/messages"
+	"math/rand"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 	"gopkg.in/yaml.v2"
@@ -17,31 +22,137 @@ var translationParserYAML []byte
 //go:embed prompt/word_generator.yaml
 var wordGeneratorYAML []byte
 
+//go:embed prompt/article_summary.yaml
+var articleSummaryYAML []byte
+
+//go:embed prompt/example_regenerator.yaml
+var exampleRegeneratorYAML []byte
+
+//go:embed prompt/grammar_correction.yaml
+var grammarCorrectionYAML []byte
+
+//go:embed prompt/japanese_word_generator.yaml
+var japaneseWordGeneratorYAML []byte
+
+//go:embed prompt/japanese_translation.yaml
+var japaneseTranslationYAML []byte
+
+// 候選版本的翻譯 prompt 放在 prompt/translation_parser_<version>.yaml
+//
+//go:embed prompt
+var promptFS embed.FS
+
+// ErrResponseParse marks OpenAI responses that could not be parsed into the expected JSON
+var ErrResponseParse = errors.New("failed to parse OpenAI response")
+
 type ParserPrompt struct {
+	Version      string `yaml:"version"`
 	SystemPrompt string `yaml:"system_prompt"`
 }
 
+var translationPrompts, BaselineTranslationPromptVersion = mustLoadTranslationPrompts()
+
+// mustLoadTranslationPrompts 載入所有翻譯 prompt 版本，回傳版本對照表與 baseline 版本
+func mustLoadTranslationPrompts() (map[string]ParserPrompt, string) {
+	var baseline ParserPrompt
+	if err := yaml.Unmarshal(translationParserYAML, &baseline); err != nil {
+		panic(fmt.Errorf("error parsing prompt yaml: %w", err))
+	}
+	prompts := map[string]ParserPrompt{baseline.Version: baseline}
+
+	candidates, err := promptFS.ReadDir("prompt")
+	if err != nil {
+		panic(fmt.Errorf("error reading prompt directory: %w", err))
+	}
+	for _, entry := range candidates {
+		if !strings.HasPrefix(entry.Name(), "translation_parser_") {
+			continue
+		}
+		raw, err := promptFS.ReadFile(path.Join("prompt", entry.Name()))
+		if err != nil {
+			panic(fmt.Errorf("error reading prompt %s: %w", entry.Name(), err))
+		}
+		var prompt ParserPrompt
+		if err := yaml.Unmarshal(raw, &prompt); err != nil {
+			panic(fmt.Errorf("error parsing prompt %s: %w", entry.Name(), err))
+		}
+		if _, exists := prompts[prompt.Version]; exists || prompt.Version == "" {
+			panic(fmt.Errorf("prompt %s has a missing or duplicate version %q", entry.Name(), prompt.Version))
+		}
+		prompts[prompt.Version] = prompt
+	}
+
+	return prompts, baseline.Version
+}
+
+// HasTranslationPromptVersion reports whether the given translation prompt version is embedded
+func HasTranslationPromptVersion(version string) bool {
+	_, ok := translationPrompts[version]
+	return ok
+}
+
 type TranslationResponse struct {
-	Translations []Translation `json:"translations"`
+	Translations []Translation   `json:"translations"`
+	Usage        CompletionUsage `json:"-"` // 這次呼叫的 token 用量，不屬於模型回傳的 JSON
+}
+
+// CompletionUsage is the token usage OpenAI reported for one chat completion
+type CompletionUsage struct {
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+func completionUsage(req openai.ChatCompletionRequest, resp openai.ChatCompletionResponse) CompletionUsage {
+	return CompletionUsage{
+		Model:            req.Model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
+}
+
+// ArticleSummaryResponse is the summarized translation of a long input with a few key words to learn
+type ArticleSummaryResponse struct {
+	Summary       string        `json:"summary"`
+	KeyVocabulary []Translation `json:"keyVocabulary"`
+}
+
+// GrammarCorrectionResponse is the corrected version of a user's sentence with each fix explained in Chinese
+type GrammarCorrectionResponse struct {
+	Corrected   string              `json:"corrected"`
+	Translation string              `json:"translation"`
+	Corrections []GrammarCorrection `json:"corrections"` // 空的表示句子沒有錯誤
+}
+
+type GrammarCorrection struct {
+	Original    string `json:"original"`
+	Corrected   string `json:"corrected"`
+	Explanation string `json:"explanation"`
 }
 
 type WordGenerationResponse struct {
-	Words []Word `json:"words"`
+	Words []Word          `json:"words"`
+	Usage CompletionUsage `json:"-"` // 這次呼叫的 token 用量，不屬於模型回傳的 JSON
 }
 
 type Word struct {
 	Word         string   `json:"word"`
+	Reading      string   `json:"reading,omitempty"` // 假名讀音（furigana），只有日文單字有
+	IPA          string   `json:"ipa,omitempty"`     // IPA 音標（含重音符號 ˈ ˌ），只有英文單字有
 	PartOfSpeech string   `json:"partOfSpeech"`
 	Meaning      string   `json:"meaning"`
 	Example      Example  `json:"example"`
 	Synonyms     []string `json:"synonyms"`
 	Antonyms     []string `json:"antonyms"`
-	Difficulty   string   `json:"difficulty"`
+	Difficulty   string   `json:"difficulty"` // CEFR 等級 (A1~C2)
 	Category     string   `json:"category"`
+	ExamTags     []string `json:"examTags"` // 常出現的考試/題型，例如 "TOEIC Part 5"
 }
 
 type Translation struct {
 	Word         string   `json:"word"`
+	Reading      string   `json:"reading,omitempty"` // 假名讀音（furigana），只有日文單字有
+	IPA          string   `json:"ipa,omitempty"`     // IPA 音標（含重音符號 ˈ ˌ），只有英文單字有
 	PartOfSpeech string   `json:"partOfSpeech"`
 	Meaning      string   `json:"meaning"`
 	Example      Example  `json:"example"`
@@ -49,157 +160,352 @@ type Translation struct {
 	Antonyms     []string `json:"antonyms"`
 }
 
+// Example 是單字的例句；En 放學習語言的例句，日文課程也沿用這個欄位
 type Example struct {
-	En string `json:"en"`
-	Zh string `json:"zh"`
+	En      string `json:"en"`
+	Zh      string `json:"zh"`
+	Reading string `json:"reading,omitempty"` // 日文例句的假名讀音
+}
+
+// TranslateOptions selects the prompt and model for one translation; the zero value uses the baseline prompt
+// and the default model
+type TranslateOptions struct {
+	PromptVersion string // 翻譯 prompt 版本（prompt rollout 使用），空字串為 baseline
+	Model         string // 用戶選擇的模型（UserConfig.Model），空字串使用預設模型
+	Language      string // courses.LanguageJapanese 改用中日翻譯 prompt（不參與 rollout），其他為中英翻譯
+	// SampleFailures 用戶同意 AI 資料使用（models.ConsentAIData）時才保存解析失敗的輸出，輸出可能包含用戶輸入的內容
+	SampleFailures bool
+	// BypassCache 不讀寫 TranslationCache，例如 prompt rollout 實驗需要實際呼叫模型來比較結果
+	BypassCache bool
+}
+
+// GenerateWordOptions describes one word generation request
+type GenerateWordOptions struct {
+	Course    string // internal/courses 登記的課程 ID
+	WordCount int
+	Level     int
+	Model     string // 用戶選擇的模型（UserConfig.Model），空字串使用預設模型
+	Target    string // 目標 CEFR 等級（TargetCEFR），空字串時由模型依分數決定
 }
 
 type OpenaiAPI interface {
-	Translate(inputMsg string) (TranslationResponse, error)
-	GenerateWord(course string, wordCount int, level int) (WordGenerationResponse, error)
+	Translate(inputMsg string, opts TranslateOptions) (TranslationResponse, error)
+	GenerateWord(opts GenerateWordOptions) (WordGenerationResponse, error)
+	SummarizeArticle(inputMsg string, keyWordCount int, sampleFailures bool) (ArticleSummaryResponse, error)
+	RegenerateExample(word, partOfSpeech, meaning, level string) (Example, error)
+	CorrectGrammar(sentence, model string, sampleFailures bool) (GrammarCorrectionResponse, error)
 }
 
+// OpenaiClient calls the OpenAI chat API; params are read-only after NewOpenAIClient, so it is safe for concurrent use
 type OpenaiClient struct {
-	client *openai.Client
+	client        *openai.Client
+	params        map[OpenAIFeature]GenerationParams
+	retry         RetryPolicy
+	deadline      *InvocationDeadline   // nil 表示只以 retry.MaxAttempts 限制重試
+	parseFailures *ParseFailureRecorder // nil 表示不保存解析失敗的輸出
+	rnd           *rand.Rand
+	now           func() time.Time
+	sleep         func(time.Duration)
 }
 
-func NewOpenAIClient(apiKey string, baseUrl string) (OpenaiAPI, error) {
+// NewOpenAIClient creates the client; deadline is shared with the Lambda entry point so retries respect the
+// remaining invocation time, and may be nil. parseFailures samples responses that fail to parse, and may be nil
+func NewOpenAIClient(apiKey string, baseUrl string, params map[OpenAIFeature]GenerationParams, retry RetryPolicy, deadline *InvocationDeadline, parseFailures *ParseFailureRecorder) (OpenaiAPI, error) {
 	config := openai.DefaultConfig(apiKey)
 	config.BaseURL = baseUrl
 	client := openai.NewClientWithConfig(config)
 	return &OpenaiClient{
-		client: client,
+		client:        client,
+		params:        params,
+		retry:         retry,
+		deadline:      deadline,
+		parseFailures: parseFailures,
+		rnd:           NewConcurrentRand(time.Now().UnixNano()),
+		now:           time.Now,
+		sleep:         time.Sleep,
 	}, nil
 }
 
-func (c *OpenaiClient) Translate(inputMsg string) (TranslationResponse, error) {
+// Translate translates inputMsg; opts.PromptVersion is set by prompt rollouts and opts.Model by the user's preference
+func (c *OpenaiClient) Translate(inputMsg string, opts TranslateOptions) (TranslationResponse, error) {
 	var prompt ParserPrompt
-	err := yaml.Unmarshal(translationParserYAML, &prompt)
-	if err != nil {
-		return TranslationResponse{}, fmt.Errorf("error parsing prompt yaml: %w", err)
-	}
-
-	resp, err := c.client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: openai.GPT4oMini,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: prompt.SystemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: inputMsg,
-				},
+	if opts.Language == courses.LanguageJapanese {
+		if err := yaml.Unmarshal(japaneseTranslationYAML, &prompt); err != nil {
+			return TranslationResponse{}, fmt.Errorf("error parsing japanese translation prompt yaml: %w", err)
+		}
+	} else {
+		promptVersion := opts.PromptVersion
+		if promptVersion == "" {
+			promptVersion = BaselineTranslationPromptVersion
+		}
+		var ok bool
+		if prompt, ok = translationPrompts[promptVersion]; !ok {
+			return TranslationResponse{}, fmt.Errorf("unknown translation prompt version %q", promptVersion)
+		}
+	}
+
+	model, err := resolveModel(opts.Model, DefaultOpenAIModel)
+	if err != nil {
+		return TranslationResponse{}, err
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: prompt.SystemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: inputMsg,
 			},
-			Temperature: 1.0,
 		},
-	)
+		ResponseFormat: translationResponseFormat,
+	}
+	c.params[FeatureTranslation].apply(&req)
+
+	resp, err := c.createChatCompletion(req)
 	if err != nil {
 		return TranslationResponse{}, fmt.Errorf("OpenAI API error: %w", err)
 	}
 
-	content := resp.Choices[0].Message.Content
-
-	if !strings.Contains(content, "{") {
-		return TranslationResponse{
-			Translations: []Translation{
-				{
-					Word:    inputMsg,
-					Meaning: strings.Trim(strings.TrimSpace(content), "\""),
-				},
-			},
-		}, nil
-	}
 	var translationResponse TranslationResponse
-	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &translationResponse)
+	err = c.parseJSONResponse(req, resp.Choices[0].Message.Content, FeatureTranslation, opts.PromptVersion, c.failureRecorder(opts.SampleFailures), &translationResponse)
 	if err != nil {
 		return TranslationResponse{}, fmt.Errorf("error unmarshalling openai API response: %w", err)
 	}
+	translationResponse.Usage = completionUsage(req, resp)
 
 	return translationResponse, nil
 }
 
-func (c *OpenaiClient) GenerateWord(course string, wordCount int, level int) (WordGenerationResponse, error) {
+func (c *OpenaiClient) GenerateWord(opts GenerateWordOptions) (WordGenerationResponse, error) {
+	exam, ok := courses.Get(opts.Course)
+	if !ok {
+		return WordGenerationResponse{}, fmt.Errorf("unknown course %q", opts.Course)
+	}
+
+	promptYAML := wordGeneratorYAML
+	if exam.Language == courses.LanguageJapanese {
+		promptYAML = japaneseWordGeneratorYAML
+	}
 	var prompt ParserPrompt
-	err := yaml.Unmarshal(wordGeneratorYAML, &prompt)
+	err := yaml.Unmarshal(promptYAML, &prompt)
 	if err != nil {
 		return WordGenerationResponse{}, fmt.Errorf("error parsing word generator prompt yaml: %w", err)
 	}
 
+	model, err := resolveModel(opts.Model, openai.GPT5)
+	if err != nil {
+		return WordGenerationResponse{}, err
+	}
+	wordCount, level := opts.WordCount, opts.Level
+
 	// Replace template variables in the system prompt
-	systemPrompt := strings.ReplaceAll(prompt.SystemPrompt, "{{.Course}}", course)
+	systemPrompt := strings.ReplaceAll(prompt.SystemPrompt, "{{.Course}}", exam.ExamName)
 	systemPrompt = strings.ReplaceAll(systemPrompt, "{{.WordCount}}", fmt.Sprintf("%d", wordCount))
-	systemPrompt = strings.ReplaceAll(systemPrompt, "{{.Level}}", fmt.Sprintf("%d", level))
-
-	resp, err := c.client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: openai.GPT5,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: fmt.Sprintf("請生成 %d 個適合 %s 考試 %d 分程度的英文單字", wordCount, course, level),
-				},
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{{.Level}}", exam.FormatLevel(level))
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{{.ScoreRange}}", exam.ScoreRange())
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{{.CourseGuidance}}", exam.PromptGuidance)
+	difficulty := "依目標分數決定"
+	if opts.Target != "" {
+		difficulty = fmt.Sprintf("以 CEFR %s 為主，最多相差一個等級", opts.Target)
+	}
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{{.Difficulty}}", difficulty)
+
+	userPrompt := fmt.Sprintf("請生成 %d 個適合 %s 考試 %s 分程度的英文單字", wordCount, exam.ExamName, exam.FormatLevel(level))
+	if exam.Language == courses.LanguageJapanese {
+		userPrompt = fmt.Sprintf("請生成 %d 個適合 %s %s 程度的日文單字", wordCount, exam.ExamName, exam.FormatLevel(level))
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: userPrompt,
 			},
-			Temperature: 1.0,
 		},
-	)
+		ResponseFormat: wordGenerationResponseFormat,
+	}
+	c.params[FeatureWordGeneration].apply(&req)
+
+	resp, err := c.createChatCompletion(req)
 	if err != nil {
 		return WordGenerationResponse{}, fmt.Errorf("OpenAI API error: %w", err)
 	}
 
-	content := resp.Choices[0].Message.Content
-
 	var wordResponse WordGenerationResponse
-	err = json.Unmarshal([]byte(content), &wordResponse)
+	err = c.parseJSONResponse(req, resp.Choices[0].Message.Content, FeatureWordGeneration, "", c.parseFailures, &wordResponse)
 	if err != nil {
 		return WordGenerationResponse{}, fmt.Errorf("error unmarshalling word generation API response: %w", err)
 	}
+	wordResponse.Usage = completionUsage(req, resp)
 
 	return wordResponse, nil
 }
 
-func (t Translation) String() string {
-	var sb strings.Builder
+// SummarizeArticle returns a summary translation of a long input plus key vocabulary, instead of translating every word.
+// sampleFailures is the user's AI data consent, see TranslateOptions.SampleFailures
+func (c *OpenaiClient) SummarizeArticle(inputMsg string, keyWordCount int, sampleFailures bool) (ArticleSummaryResponse, error) {
+	var prompt ParserPrompt
+	err := yaml.Unmarshal(articleSummaryYAML, &prompt)
+	if err != nil {
+		return ArticleSummaryResponse{}, fmt.Errorf("error parsing article summary prompt yaml: %w", err)
+	}
+
+	systemPrompt := strings.ReplaceAll(prompt.SystemPrompt, "{{.KeyWordCount}}", fmt.Sprintf("%d", keyWordCount))
+
+	req := openai.ChatCompletionRequest{
+		Model: DefaultOpenAIModel,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: inputMsg,
+			},
+		},
+	}
+	c.params[FeatureArticleSummary].apply(&req)
+
+	resp, err := c.createChatCompletion(req)
+	if err != nil {
+		return ArticleSummaryResponse{}, fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	var summaryResponse ArticleSummaryResponse
+	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &summaryResponse)
+	if err != nil {
+		c.failureRecorder(sampleFailures).Record(FeatureArticleSummary, "", req.Model, resp.Choices[0].Message.Content, err)
+		return ArticleSummaryResponse{}, fmt.Errorf("error unmarshalling article summary API response: %w: %v", ErrResponseParse, err)
+	}
+
+	return summaryResponse, nil
+}
+
+// RegenerateExample writes a fresh, consistent en/zh example pair for a word using the cheaper model.
+// level is a CEFR band (e.g. "B1") the sentence should be written for, empty for no particular level
+func (c *OpenaiClient) RegenerateExample(word, partOfSpeech, meaning, level string) (Example, error) {
+	var prompt ParserPrompt
+	err := yaml.Unmarshal(exampleRegeneratorYAML, &prompt)
+	if err != nil {
+		return Example{}, fmt.Errorf("error parsing example regenerator prompt yaml: %w", err)
+	}
+
+	userMessage := fmt.Sprintf("單字：%s\n詞性：%s\n意思：%s", word, partOfSpeech, meaning)
+	if level != "" {
+		userMessage += fmt.Sprintf("\n程度：%s", level)
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model: DefaultOpenAIModel,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: prompt.SystemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: userMessage,
+			},
+		},
+	}
+	c.params[FeatureExampleRegeneration].apply(&req)
 
-	// 標題：單字和詞性
-	sb.WriteString(fmt.Sprintf("【%s】(%s)\n", t.Word, t.PartOfSpeech))
+	resp, err := c.createChatCompletion(req)
+	if err != nil {
+		return Example{}, fmt.Errorf("OpenAI API error: %w", err)
+	}
+
+	var example Example
+	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &example)
+	if err != nil {
+		c.parseFailures.Record(FeatureExampleRegeneration, "", req.Model, resp.Choices[0].Message.Content, err)
+		return Example{}, fmt.Errorf("error unmarshalling example regeneration API response: %w: %v", ErrResponseParse, err)
+	}
+
+	return example, nil
+}
+
+// CorrectGrammar checks a full English sentence and explains each correction, used instead of translation in grammar mode.
+// model is the user's preference (UserConfig.Model), empty for the default; sampleFailures as in TranslateOptions
+func (c *OpenaiClient) CorrectGrammar(sentence, model string, sampleFailures bool) (GrammarCorrectionResponse, error) {
+	var prompt ParserPrompt
+	err := yaml.Unmarshal(grammarCorrectionYAML, &prompt)
+	if err != nil {
+		return GrammarCorrectionResponse{}, fmt.Errorf("error parsing grammar correction prompt yaml: %w", err)
+	}
 
-	// 中文意思
-	sb.WriteString(fmt.Sprintf("意思：%s\n", t.Meaning))
+	model, err = resolveModel(model, DefaultOpenAIModel)
+	if err != nil {
+		return GrammarCorrectionResponse{}, err
+	}
 
-	// 例句
-	sb.WriteString("例句：\n")
-	sb.WriteString(fmt.Sprintf("  %s\n", t.Example.En))
-	sb.WriteString(fmt.Sprintf("  %s\n", t.Example.Zh))
+	req := openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: prompt.SystemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: sentence,
+			},
+		},
+	}
+	c.params[FeatureGrammarCorrection].apply(&req)
 
-	// 同義詞
-	if len(t.Synonyms) > 0 {
-		sb.WriteString(fmt.Sprintf("同義詞：%s\n", strings.Join(t.Synonyms, ", ")))
+	resp, err := c.createChatCompletion(req)
+	if err != nil {
+		return GrammarCorrectionResponse{}, fmt.Errorf("OpenAI API error: %w", err)
 	}
 
-	if len(t.Antonyms) > 0 {
-		sb.WriteString(fmt.Sprintf("反義詞：%s\n", strings.Join(t.Antonyms, ", ")))
+	var correction GrammarCorrectionResponse
+	err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &correction)
+	if err != nil {
+		c.failureRecorder(sampleFailures).Record(FeatureGrammarCorrection, "", req.Model, resp.Choices[0].Message.Content, err)
+		return GrammarCorrectionResponse{}, fmt.Errorf("error unmarshalling grammar correction API response: %w: %v", ErrResponseParse, err)
 	}
 
-	return sb.String()
+	return correction, nil
 }
 
-func (tr TranslationResponse) String() string {
-	var sb strings.Builder
+func (t Translation) String() string {
+	return messages.Render(messages.TranslationCard, messages.Data{
+		"Word":           t.Word,
+		"Reading":        DisplayReading(t.Word, t.Reading),
+		"IPA":            DisplayIPA(t.IPA),
+		"PartOfSpeech":   t.PartOfSpeech,
+		"Meaning":        t.Meaning,
+		"ExampleEn":      t.Example.En,
+		"ExampleZh":      t.Example.Zh,
+		"ExampleReading": t.Example.Reading,
+		"Synonyms":       strings.Join(t.Synonyms, ", "),
+		"Antonyms":       strings.Join(t.Antonyms, ", "),
+	})
+}
 
-	for i, trans := range tr.Translations {
-		if i > 0 {
-			sb.WriteString("\n-------------------\n")
-		}
-		sb.WriteString(trans.String())
+// translationSeparator 多個翻譯結果之間的分隔線
+const translationSeparator = "\n-------------------\n"
+
+// Texts formats every translation card, split into LINE-sized messages between cards
+func (tr TranslationResponse) Texts() []string {
+	cards := make([]string, 0, len(tr.Translations))
+	for _, trans := range tr.Translations {
+		cards = append(cards, trans.String())
 	}
+	return messages.Split(cards, translationSeparator)
+}
 
-	return sb.String()
+func (tr TranslationResponse) String() string {
+	return strings.Join(tr.Texts(), translationSeparator)
 }
@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"fmt"
+	"hash/fnv"
+	"language-assistant/internal/courses"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// FakeOpenAIEnv 設為 "true" 時以 FakeOpenaiClient 取代 OpenAI，整合測試與本機 Lambda 不需網路也不產生費用
+const FakeOpenAIEnv = "OPENAI_FAKE"
+
+// LoadFakeOpenAI reports whether OPENAI_FAKE is enabled; like fault injection it is rejected on the prod STAGE
+func LoadFakeOpenAI(getenv func(string) string) (bool, error) {
+	value := strings.ToLower(strings.TrimSpace(getenv(FakeOpenAIEnv)))
+	switch value {
+	case "", "false", "0":
+		return false, nil
+	case "true", "1":
+	default:
+		return false, fmt.Errorf("%s must be true or false, got %q", FakeOpenAIEnv, value)
+	}
+	if getenv("STAGE") == productionStage {
+		return false, fmt.Errorf("%s must not be set on the %s stage", FakeOpenAIEnv, productionStage)
+	}
+	return true, nil
+}
+
+// fakeWords 是 FakeOpenaiClient 輪流回傳的固定單字，依難度由低到高排列
+var fakeWords = []Word{
+	{Word: "schedule", IPA: "ˈskedʒuːl", PartOfSpeech: "n.", Meaning: "行程表、時間表", Example: Example{En: "Please check the schedule before the meeting.", Zh: "開會前請確認行程表。"}, Synonyms: []string{"timetable", "agenda"}, Difficulty: "A2"},
+	{Word: "deliver", IPA: "dɪˈlɪvər", PartOfSpeech: "v.", Meaning: "遞送、交付", Example: Example{En: "The package will be delivered tomorrow.", Zh: "包裹明天會送達。"}, Synonyms: []string{"bring", "supply"}, Difficulty: "A2"},
+	{Word: "available", IPA: "əˈveɪləbl", PartOfSpeech: "adj.", Meaning: "可用的、有空的", Example: Example{En: "Is the manager available this afternoon?", Zh: "經理今天下午有空嗎？"}, Synonyms: []string{"free", "accessible"}, Antonyms: []string{"unavailable"}, Difficulty: "A2"},
+	{Word: "budget", IPA: "ˈbʌdʒɪt", PartOfSpeech: "n.", Meaning: "預算", Example: Example{En: "We need to stay within the budget.", Zh: "我們必須控制在預算內。"}, Synonyms: []string{"funds", "allowance"}, Difficulty: "B1"},
+	{Word: "approve", IPA: "əˈpruːv", PartOfSpeech: "v.", Meaning: "批准、贊成", Example: Example{En: "The board approved the new plan.", Zh: "董事會批准了新計畫。"}, Synonyms: []string{"authorize", "accept"}, Antonyms: []string{"reject"}, Difficulty: "B1"},
+	{Word: "evidence", IPA: "ˈevɪdəns", PartOfSpeech: "n.", Meaning: "證據", Example: Example{En: "There is little evidence to support the claim.", Zh: "幾乎沒有證據支持這個說法。"}, Synonyms: []string{"proof"}, Difficulty: "B1"},
+	{Word: "reliable", IPA: "rɪˈlaɪəbl", PartOfSpeech: "adj.", Meaning: "可靠的", Example: Example{En: "She is a reliable colleague.", Zh: "她是一位可靠的同事。"}, Synonyms: []string{"dependable", "trustworthy"}, Antonyms: []string{"unreliable"}, Difficulty: "B1"},
+	{Word: "negotiate", IPA: "nɪˈɡoʊʃieɪt", PartOfSpeech: "v.", Meaning: "談判、協商", Example: Example{En: "They negotiated a better price with the supplier.", Zh: "他們和供應商談到了更好的價格。"}, Synonyms: []string{"bargain", "discuss"}, Difficulty: "B2"},
+	{Word: "substantial", IPA: "səbˈstænʃl", PartOfSpeech: "adj.", Meaning: "大量的、可觀的", Example: Example{En: "The company made a substantial profit last year.", Zh: "公司去年獲得可觀的利潤。"}, Synonyms: []string{"considerable", "significant"}, Antonyms: []string{"minor"}, Difficulty: "B2"},
+	{Word: "implement", IPA: "ˈɪmplɪment", PartOfSpeech: "v.", Meaning: "實施、執行", Example: Example{En: "The new policy will be implemented next month.", Zh: "新政策將在下個月實施。"}, Synonyms: []string{"carry out", "execute"}, Difficulty: "B2"},
+	{Word: "obstacle", IPA: "ˈɑːbstəkl", PartOfSpeech: "n.", Meaning: "障礙", Example: Example{En: "Cost is the main obstacle to the project.", Zh: "成本是這個專案的主要障礙。"}, Synonyms: []string{"barrier", "hurdle"}, Difficulty: "B2"},
+	{Word: "resilient", IPA: "rɪˈzɪliənt", PartOfSpeech: "adj.", Meaning: "有韌性的、能迅速恢復的", Example: Example{En: "Children are often more resilient than adults.", Zh: "孩子往往比大人更有韌性。"}, Synonyms: []string{"tough", "adaptable"}, Antonyms: []string{"fragile"}, Difficulty: "B2"},
+	{Word: "compliance", IPA: "kəmˈplaɪəns", PartOfSpeech: "n.", Meaning: "遵守、合規", Example: Example{En: "All staff must ensure compliance with safety rules.", Zh: "所有員工都必須遵守安全規定。"}, Synonyms: []string{"adherence", "conformity"}, Antonyms: []string{"violation"}, Difficulty: "C1"},
+	{Word: "mitigate", IPA: "ˈmɪtɪɡeɪt", PartOfSpeech: "v.", Meaning: "減輕、緩和", Example: Example{En: "Trees can mitigate the effects of pollution.", Zh: "樹木可以減輕污染的影響。"}, Synonyms: []string{"alleviate", "reduce"}, Antonyms: []string{"aggravate"}, Difficulty: "C1"},
+	{Word: "ambiguous", IPA: "æmˈbɪɡjuəs", PartOfSpeech: "adj.", Meaning: "模稜兩可的", Example: Example{En: "The instructions were ambiguous.", Zh: "這些指示模稜兩可。"}, Synonyms: []string{"unclear", "vague"}, Antonyms: []string{"clear"}, Difficulty: "C1"},
+	{Word: "ubiquitous", IPA: "juːˈbɪkwɪtəs", PartOfSpeech: "adj.", Meaning: "無所不在的", Example: Example{En: "Smartphones have become ubiquitous.", Zh: "智慧型手機已經無所不在。"}, Synonyms: []string{"omnipresent", "pervasive"}, Antonyms: []string{"rare"}, Difficulty: "C1"},
+}
+
+// fakeJapaneseWords 是日文課程（courses.LanguageJapanese）使用的固定單字，依 JLPT 級數由低到高排列
+var fakeJapaneseWords = []Word{
+	{Word: "食べる", Reading: "たべる", PartOfSpeech: "動詞", Meaning: "吃", Example: Example{En: "朝ご飯を食べましたか。", Reading: "あさごはんをたべましたか。", Zh: "你吃早餐了嗎？"}, Difficulty: "A1"},
+	{Word: "友達", Reading: "ともだち", PartOfSpeech: "名詞", Meaning: "朋友", Example: Example{En: "週末は友達と映画を見ます。", Reading: "しゅうまつはともだちとえいがをみます。", Zh: "週末和朋友去看電影。"}, Difficulty: "A1"},
+	{Word: "準備", Reading: "じゅんび", PartOfSpeech: "名・サ変", Meaning: "準備", Example: Example{En: "旅行の準備はもうできましたか。", Reading: "りょこうのじゅんびはもうできましたか。", Zh: "旅行的準備已經好了嗎？"}, Synonyms: []string{"用意", "支度"}, Difficulty: "A2"},
+	{Word: "約束", Reading: "やくそく", PartOfSpeech: "名・サ変", Meaning: "約定、承諾", Example: Example{En: "明日の約束を忘れないでね。", Reading: "あしたのやくそくをわすれないでね。", Zh: "別忘了明天的約定喔。"}, Difficulty: "A2"},
+	{Word: "影響", Reading: "えいきょう", PartOfSpeech: "名・サ変", Meaning: "影響", Example: Example{En: "天気は気分に影響する。", Reading: "てんきはきぶんにえいきょうする。", Zh: "天氣會影響心情。"}, Difficulty: "B1"},
+	{Word: "我慢", Reading: "がまん", PartOfSpeech: "名・サ変", Meaning: "忍耐", Example: Example{En: "痛みを我慢しないでください。", Reading: "いたみをがまんしないでください。", Zh: "請不要忍著痛。"}, Synonyms: []string{"辛抱"}, Difficulty: "B1"},
+	{Word: "傾向", Reading: "けいこう", PartOfSpeech: "名詞", Meaning: "傾向、趨勢", Example: Example{En: "若者は新聞を読まない傾向がある。", Reading: "わかものはしんぶんをよまないけいこうがある。", Zh: "年輕人有不看報紙的傾向。"}, Synonyms: []string{"風潮"}, Difficulty: "B2"},
+	{Word: "懸念", Reading: "けねん", PartOfSpeech: "名・サ変", Meaning: "擔憂、顧慮", Example: Example{En: "専門家は景気の悪化を懸念している。", Reading: "せんもんかはけいきのあっかをけねんしている。", Zh: "專家擔憂景氣惡化。"}, Synonyms: []string{"心配"}, Antonyms: []string{"安心"}, Difficulty: "C1"},
+}
+
+// FakeOpenaiClient is a deterministic, offline OpenaiAPI: word generation cycles through a fixed list and
+// translations are looked up from the same list (or echoed back), so the whole pipeline runs without network calls.
+// Safe for concurrent use; the call counter is guarded by mu
+type FakeOpenaiClient struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func NewFakeOpenAIClient() OpenaiAPI {
+	return &FakeOpenaiClient{}
+}
+
+func (c *FakeOpenaiClient) Translate(inputMsg string, opts TranslateOptions) (TranslationResponse, error) {
+	if opts.PromptVersion != "" && !HasTranslationPromptVersion(opts.PromptVersion) {
+		return TranslationResponse{}, fmt.Errorf("unknown translation prompt version %q", opts.PromptVersion)
+	}
+	if !ValidOpenAIModel(opts.Model) {
+		return TranslationResponse{}, fmt.Errorf("model %q is not allowed", opts.Model)
+	}
+	words := fakeWords
+	if opts.Language == courses.LanguageJapanese {
+		words = fakeJapaneseWords
+	}
+	usage := fakeUsage(opts.Model, DefaultOpenAIModel, inputMsg, 1)
+	return TranslationResponse{Translations: []Translation{fakeTranslation(words, strings.TrimSpace(inputMsg))}, Usage: usage}, nil
+}
+
+// GenerateWord 依課程與程度決定起點，之後每次呼叫接著回傳下一批單字；整份清單用完後加上輪數後綴，
+// 讓已推播單字的過濾不會永遠篩光所有單字
+func (c *FakeOpenaiClient) GenerateWord(opts GenerateWordOptions) (WordGenerationResponse, error) {
+	exam, ok := courses.Get(opts.Course)
+	if !ok {
+		return WordGenerationResponse{}, fmt.Errorf("unknown course %q", opts.Course)
+	}
+	if !ValidOpenAIModel(opts.Model) {
+		return WordGenerationResponse{}, fmt.Errorf("model %q is not allowed", opts.Model)
+	}
+	course, wordCount, level := opts.Course, opts.WordCount, opts.Level
+	list := fakeWords
+	if exam.Language == courses.LanguageJapanese {
+		list = fakeJapaneseWords
+	}
+
+	c.mu.Lock()
+	start := c.calls * wordCount
+	c.calls++
+	c.mu.Unlock()
+
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s:%d", course, level)
+	offset := int(hash.Sum32() % uint32(len(list)))
+
+	words := make([]Word, 0, wordCount)
+	for i := start; i < start+wordCount; i++ {
+		word := list[(offset+i)%len(list)]
+		if round := i / len(list); round > 0 {
+			word.Word = fmt.Sprintf("%s-%d", word.Word, round+1)
+		}
+		word.ExamTags = []string{exam.ExamName + " Reading"}
+		words = append(words, word)
+	}
+	return WordGenerationResponse{Words: words, Usage: fakeUsage(opts.Model, openai.GPT5, "", wordCount)}, nil
+}
+
+// fakeUsage 以輸入長度與回傳的單字數估算固定的 token 用量，讓用量記錄與費用報表在本機也有資料
+func fakeUsage(model, fallback, inputMsg string, words int) CompletionUsage {
+	if model == "" {
+		model = fallback
+	}
+	return CompletionUsage{Model: model, PromptTokens: 300 + utf8.RuneCountInString(inputMsg), CompletionTokens: 80 * words}
+}
+
+func (c *FakeOpenaiClient) SummarizeArticle(inputMsg string, keyWordCount int, sampleFailures bool) (ArticleSummaryResponse, error) {
+	summary := inputMsg
+	if utf8.RuneCountInString(summary) > 50 {
+		summary = string([]rune(summary)[:50]) + "…"
+	}
+
+	// 優先挑出文章中出現的清單單字，不足時以清單前幾個補齊
+	var keyWords []Translation
+	lower := strings.ToLower(inputMsg)
+	for _, word := range fakeWords {
+		if len(keyWords) < keyWordCount && strings.Contains(lower, word.Word) {
+			keyWords = append(keyWords, fakeTranslation(fakeWords, word.Word))
+		}
+	}
+	for _, word := range fakeWords {
+		if len(keyWords) >= keyWordCount {
+			break
+		}
+		if !strings.Contains(lower, word.Word) {
+			keyWords = append(keyWords, fakeTranslation(fakeWords, word.Word))
+		}
+	}
+
+	return ArticleSummaryResponse{Summary: "（測試摘要）" + summary, KeyVocabulary: keyWords}, nil
+}
+
+func (c *FakeOpenaiClient) RegenerateExample(word, partOfSpeech, meaning, level string) (Example, error) {
+	return Example{
+		En: fmt.Sprintf("This is a new example sentence using %q.", word),
+		Zh: fmt.Sprintf("這是使用「%s」（%s）的新例句。", word, meaning),
+	}, nil
+}
+
+// CorrectGrammar 只修正常見的 "i" 小寫與缺少句點，其他句子視為正確
+func (c *FakeOpenaiClient) CorrectGrammar(sentence, model string, sampleFailures bool) (GrammarCorrectionResponse, error) {
+	corrected := strings.TrimSpace(sentence)
+	var corrections []GrammarCorrection
+	if strings.HasPrefix(corrected, "i ") {
+		corrected = "I " + corrected[2:]
+		corrections = append(corrections, GrammarCorrection{Original: "i", Corrected: "I", Explanation: "（測試說明）第一人稱 I 必須大寫"})
+	}
+	if !strings.HasSuffix(corrected, ".") && !strings.HasSuffix(corrected, "?") && !strings.HasSuffix(corrected, "!") {
+		corrected += "."
+		corrections = append(corrections, GrammarCorrection{Original: sentence, Corrected: corrected, Explanation: "（測試說明）句子結尾需要標點符號"})
+	}
+	return GrammarCorrectionResponse{Corrected: corrected, Translation: "（測試翻譯）" + corrected, Corrections: corrections}, nil
+}
+
+// fakeTranslation 清單中有的單字（原文或中文意思）回傳完整資料，其他輸入原樣回傳並標示為測試翻譯
+func fakeTranslation(words []Word, input string) Translation {
+	for _, word := range words {
+		if strings.EqualFold(input, word.Word) || strings.Contains(word.Meaning, input) && input != "" {
+			return Translation{
+				Word:         word.Word,
+				Reading:      word.Reading,
+				IPA:          word.IPA,
+				PartOfSpeech: word.PartOfSpeech,
+				Meaning:      word.Meaning,
+				Example:      word.Example,
+				Synonyms:     word.Synonyms,
+				Antonyms:     word.Antonyms,
+			}
+		}
+	}
+	return Translation{
+		Word:    input,
+		Meaning: "（測試翻譯）" + input,
+		Example: Example{En: fmt.Sprintf("This is an example with %q.", input), Zh: fmt.Sprintf("這是包含「%s」的例句。", input)},
+	}
+}
+
+// FakeTTSClient returns placeholder bytes instead of calling a TTS service; used together with OPENAI_FAKE
+type FakeTTSClient struct{}
+
+func (FakeTTSClient) Synthesize(text, voice string) ([]byte, error) {
+	return []byte("fake-audio:" + voice + ":" + text), nil
+}
@@ -0,0 +1,68 @@
+package utils
+
+import "testing"
+
+func TestFakeOpenAIClientGenerateWord(t *testing.T) {
+	first, err := NewFakeOpenAIClient().GenerateWord(GenerateWordOptions{Course: "toeic", WordCount: 10, Level: 750})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	again, _ := NewFakeOpenAIClient().GenerateWord(GenerateWordOptions{Course: "toeic", WordCount: 10, Level: 750})
+	if len(first.Words) != 10 || first.Words[0].Word != again.Words[0].Word {
+		t.Fatalf("Expected deterministic output for a fresh client, got %v and %v", first.Words, again.Words)
+	}
+
+	// 同一個 client 連續呼叫不會重複單字，清單用完後加上輪數後綴
+	client := NewFakeOpenAIClient()
+	seen := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		resp, err := client.GenerateWord(GenerateWordOptions{Course: "ielts", WordCount: 10, Level: 65})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, word := range resp.Words {
+			if seen[word.Word] {
+				t.Fatalf("Word %q generated twice", word.Word)
+			}
+			seen[word.Word] = true
+		}
+	}
+
+	if _, err := client.GenerateWord(GenerateWordOptions{Course: "unknown", WordCount: 1, Level: 1}); err == nil {
+		t.Error("Expected unknown course to fail like the real client")
+	}
+}
+
+func TestFakeOpenAIClientTranslate(t *testing.T) {
+	client := NewFakeOpenAIClient()
+
+	resp, _ := client.Translate("預算", TranslateOptions{})
+	if resp.Translations[0].Word != "budget" {
+		t.Errorf("Expected 預算 to translate to budget, got %+v", resp.Translations[0])
+	}
+	resp, _ = client.Translate("serendipity", TranslateOptions{Model: "gpt-4o"})
+	if resp.Translations[0].Meaning != "（測試翻譯）serendipity" {
+		t.Errorf("Expected canned translation for unknown word, got %+v", resp.Translations[0])
+	}
+	if _, err := client.Translate("hello", TranslateOptions{PromptVersion: "no-such-version"}); err == nil {
+		t.Error("Expected unknown prompt version to fail")
+	}
+	if _, err := client.Translate("hello", TranslateOptions{Model: "gpt-3.5-turbo"}); err == nil {
+		t.Error("Expected a model outside the allow list to fail")
+	}
+}
+
+func TestLoadFakeOpenAI(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	if enabled, err := LoadFakeOpenAI(env(map[string]string{"OPENAI_FAKE": "true", "STAGE": "dev"})); err != nil || !enabled {
+		t.Errorf("Expected fake OpenAI enabled on dev, got %v, %v", enabled, err)
+	}
+	if _, err := LoadFakeOpenAI(env(map[string]string{"OPENAI_FAKE": "true", "STAGE": "prod"})); err == nil {
+		t.Error("Expected fake OpenAI to be rejected on prod")
+	}
+	if enabled, err := LoadFakeOpenAI(env(nil)); err != nil || enabled {
+		t.Errorf("Expected fake OpenAI disabled by default, got %v, %v", enabled, err)
+	}
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"slices"
+	"strconv"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// OpenAIFeature identifies a feature with its own generation parameters
+type OpenAIFeature string
+
+const (
+	FeatureTranslation         OpenAIFeature = "TRANSLATION"
+	FeatureWordGeneration      OpenAIFeature = "WORD_GENERATION"
+	FeatureArticleSummary      OpenAIFeature = "ARTICLE_SUMMARY"
+	FeatureExampleRegeneration OpenAIFeature = "EXAMPLE_REGENERATION"
+	FeatureGrammarCorrection   OpenAIFeature = "GRAMMAR_CORRECTION"
+)
+
+// DefaultOpenAIModel is used by every feature unless the user chooses another model with /模型
+const DefaultOpenAIModel = openai.GPT4oMini
+
+// AllowedOpenAIModels are the models a user may opt into with /模型; everything else is rejected
+var AllowedOpenAIModels = []string{models.ModelGPT4oMini, models.ModelGPT4o}
+
+// ValidOpenAIModel reports whether model may be stored as a user preference; empty means the feature default
+func ValidOpenAIModel(model string) bool {
+	return model == "" || slices.Contains(AllowedOpenAIModels, model)
+}
+
+// resolveModel returns the user's model, or fallback when they haven't chosen one
+func resolveModel(model, fallback string) (string, error) {
+	if !ValidOpenAIModel(model) {
+		return "", fmt.Errorf("model %q is not allowed", model)
+	}
+	if model == "" {
+		return fallback, nil
+	}
+	return model, nil
+}
+
+// GenerationParams controls sampling for one feature.
+// Zero values are omitted from the request (the SDK uses omitempty), so the API default applies;
+// for near-deterministic output use a small temperature such as 0.01 rather than 0.
+type GenerationParams struct {
+	Temperature float32
+	TopP        float32
+	MaxTokens   int
+}
+
+// 預設值維持原本的行為
+var defaultGenerationParams = map[OpenAIFeature]GenerationParams{
+	FeatureTranslation:         {Temperature: 1.0},
+	FeatureWordGeneration:      {Temperature: 1.0},
+	FeatureArticleSummary:      {Temperature: 1.0},
+	FeatureExampleRegeneration: {Temperature: 0.7},
+	FeatureGrammarCorrection:   {Temperature: 0.2},
+}
+
+// LoadGenerationParams reads OPENAI_<FEATURE>_TEMPERATURE, OPENAI_<FEATURE>_TOP_P and
+// OPENAI_<FEATURE>_MAX_TOKENS for every feature, falling back to the defaults when unset
+func LoadGenerationParams(getenv func(string) string) (map[OpenAIFeature]GenerationParams, error) {
+	params := make(map[OpenAIFeature]GenerationParams, len(defaultGenerationParams))
+	for feature, defaults := range defaultGenerationParams {
+		p := defaults
+		prefix := "OPENAI_" + string(feature) + "_"
+
+		if value := getenv(prefix + "TEMPERATURE"); value != "" {
+			temperature, err := strconv.ParseFloat(value, 32)
+			if err != nil || temperature < 0 || temperature > 2 {
+				return nil, fmt.Errorf("%sTEMPERATURE must be a number between 0 and 2, got %q", prefix, value)
+			}
+			p.Temperature = float32(temperature)
+		}
+
+		if value := getenv(prefix + "TOP_P"); value != "" {
+			topP, err := strconv.ParseFloat(value, 32)
+			if err != nil || topP < 0 || topP > 1 {
+				return nil, fmt.Errorf("%sTOP_P must be a number between 0 and 1, got %q", prefix, value)
+			}
+			p.TopP = float32(topP)
+		}
+
+		if value := getenv(prefix + "MAX_TOKENS"); value != "" {
+			maxTokens, err := strconv.Atoi(value)
+			if err != nil || maxTokens < 0 {
+				return nil, fmt.Errorf("%sMAX_TOKENS must be a non-negative integer, got %q", prefix, value)
+			}
+			p.MaxTokens = maxTokens
+		}
+
+		params[feature] = p
+	}
+	return params, nil
+}
+
+func (p GenerationParams) apply(req *openai.ChatCompletionRequest) {
+	req.Temperature = p.Temperature
+	req.TopP = p.TopP
+	req.MaxCompletionTokens = p.MaxTokens
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	defaultOpenAIRetryMaxAttempts = 3
+	defaultOpenAIRetryBaseDelay   = 500 * time.Millisecond
+	defaultOpenAIRetryMaxDelay    = 4 * time.Second
+
+	// 重試前至少要保留的 Lambda 剩餘時間，留給最後一次請求與回覆用戶
+	openAIRetryDeadlineMargin = 5 * time.Second
+)
+
+// RateLimitError is returned when OpenAI keeps answering 429 or 5xx after all retries (or the Lambda time budget)
+// are used up; handlers reply with messages.OpenAIRateLimited instead of treating it as a failure
+type RateLimitError struct {
+	StatusCode int
+	Attempts   int
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("OpenAI unavailable (status %d) after %d attempts: %v", e.StatusCode, e.Attempts, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// IsRateLimited reports whether err comes from OpenAI throttling or outages that outlasted the retries
+func IsRateLimited(err error) bool {
+	var rateLimitErr *RateLimitError
+	return errors.As(err, &rateLimitErr)
+}
+
+// RetryPolicy controls how transient OpenAI errors are retried; delays grow exponentially from BaseDelay up to
+// MaxDelay with full jitter
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// LoadOpenAIRetryPolicy reads OPENAI_RETRY_MAX_ATTEMPTS, OPENAI_RETRY_BASE_DELAY and OPENAI_RETRY_MAX_DELAY
+// (Go duration, e.g. "500ms"), falling back to 3 attempts with 500ms~4s delays when unset
+func LoadOpenAIRetryPolicy(getenv func(string) string) (RetryPolicy, error) {
+	policy := RetryPolicy{
+		MaxAttempts: defaultOpenAIRetryMaxAttempts,
+		BaseDelay:   defaultOpenAIRetryBaseDelay,
+		MaxDelay:    defaultOpenAIRetryMaxDelay,
+	}
+
+	if value := getenv("OPENAI_RETRY_MAX_ATTEMPTS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return RetryPolicy{}, fmt.Errorf("invalid OPENAI_RETRY_MAX_ATTEMPTS %q: must be a positive integer", value)
+		}
+		policy.MaxAttempts = parsed
+	}
+
+	if value := getenv("OPENAI_RETRY_BASE_DELAY"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return RetryPolicy{}, fmt.Errorf("invalid OPENAI_RETRY_BASE_DELAY %q: must be a positive duration", value)
+		}
+		policy.BaseDelay = parsed
+	}
+
+	if value := getenv("OPENAI_RETRY_MAX_DELAY"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < policy.BaseDelay {
+			return RetryPolicy{}, fmt.Errorf("invalid OPENAI_RETRY_MAX_DELAY %q: must be a duration no shorter than the base delay", value)
+		}
+		policy.MaxDelay = parsed
+	}
+
+	return policy, nil
+}
+
+// backoff returns the full-jitter delay before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int, rnd *rand.Rand) time.Duration {
+	ceiling := p.MaxDelay
+	if shift := retry - 1; shift < 30 {
+		if exp := p.BaseDelay << shift; exp > 0 && exp < ceiling {
+			ceiling = exp
+		}
+	}
+	return time.Duration(rnd.Int63n(int64(ceiling) + 1))
+}
+
+// InvocationDeadline holds the deadline of the current Lambda invocation so OpenAI retries stop before the
+// function times out. The Lambda entry point calls Set with its context; a zero value means no deadline
+type InvocationDeadline struct {
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+// Set records ctx's deadline, clearing it when ctx has none
+func (d *InvocationDeadline) Set(ctx context.Context) {
+	deadline, _ := ctx.Deadline()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deadline = deadline
+}
+
+// remaining returns the time left before the deadline, or false when no deadline is set
+func (d *InvocationDeadline) remaining(now time.Time) (time.Duration, bool) {
+	if d == nil {
+		return 0, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.deadline.IsZero() {
+		return 0, false
+	}
+	return d.deadline.Sub(now), true
+}
+
+// retryableStatus returns the HTTP status of a transient OpenAI error (429 or 5xx), or 0 when err should not be retried
+func retryableStatus(err error) int {
+	status := 0
+	var apiErr *openai.APIError
+	var requestErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &requestErr):
+		status = requestErr.HTTPStatusCode
+	}
+	if status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+		return status
+	}
+	return 0
+}
+
+// createChatCompletion calls the chat API, retrying 429/5xx responses within the retry policy and the
+// remaining Lambda time; a transient error that outlasts them is returned as *RateLimitError
+func (c *OpenaiClient) createChatCompletion(req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := c.client.CreateChatCompletion(context.Background(), req)
+		if err == nil {
+			return resp, nil
+		}
+
+		status := retryableStatus(err)
+		if status == 0 {
+			return resp, err
+		}
+		if attempt >= c.retry.MaxAttempts {
+			return resp, &RateLimitError{StatusCode: status, Attempts: attempt, Err: err}
+		}
+
+		delay := c.retry.backoff(attempt, c.rnd)
+		if remaining, ok := c.deadline.remaining(c.now()); ok && remaining-delay < openAIRetryDeadlineMargin {
+			return resp, &RateLimitError{StatusCode: status, Attempts: attempt, Err: err}
+		}
+		c.sleep(delay)
+	}
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryTestClient 回傳連到 httptest server 的 client，前 failures 次請求回覆 status
+func newRetryTestClient(t *testing.T, failures int32, status int, policy RetryPolicy, deadline *InvocationDeadline) (*OpenaiClient, *atomic.Int32, *[]time.Duration) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":{"message":"slow down","type":"rate_limit"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"en\":\"Hi.\",\"zh\":\"嗨。\"}"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	api, _ := NewOpenAIClient("test-key", server.URL, defaultGenerationParams, policy, deadline, nil)
+	client := api.(*OpenaiClient)
+	var sleeps []time.Duration
+	client.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	return client, &calls, &sleeps
+}
+
+func TestOpenAIRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		client, calls, sleeps := newRetryTestClient(t, 2, http.StatusTooManyRequests, policy, nil)
+		example, err := client.RegenerateExample("hi", "int.", "嗨", "")
+		if err != nil || example.En != "Hi." {
+			t.Fatalf("Expected success after retries, got %+v (err %v)", example, err)
+		}
+		if calls.Load() != 3 || len(*sleeps) != 2 {
+			t.Errorf("Expected 3 calls and 2 backoffs, got %d calls and %v", calls.Load(), *sleeps)
+		}
+		for i, d := range *sleeps {
+			if ceiling := policy.BaseDelay << i; d < 0 || d > ceiling {
+				t.Errorf("Backoff %d = %v, want within [0, %v]", i+1, d, ceiling)
+			}
+		}
+	})
+
+	t.Run("gives up with RateLimitError", func(t *testing.T) {
+		client, calls, _ := newRetryTestClient(t, 10, http.StatusServiceUnavailable, policy, nil)
+		_, err := client.RegenerateExample("hi", "int.", "嗨", "")
+		var rateLimitErr *RateLimitError
+		if !errors.As(err, &rateLimitErr) || rateLimitErr.StatusCode != http.StatusServiceUnavailable || rateLimitErr.Attempts != 3 {
+			t.Fatalf("Expected RateLimitError after 3 attempts, got %v", err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("Expected 3 calls, got %d", calls.Load())
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		client, calls, _ := newRetryTestClient(t, 10, http.StatusBadRequest, policy, nil)
+		_, err := client.RegenerateExample("hi", "int.", "嗨", "")
+		if err == nil || IsRateLimited(err) || calls.Load() != 1 {
+			t.Errorf("Expected a single non rate limit failure, got %v after %d calls", err, calls.Load())
+		}
+	})
+
+	t.Run("stops near the Lambda deadline", func(t *testing.T) {
+		deadline := &InvocationDeadline{}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		deadline.Set(ctx)
+
+		client, calls, _ := newRetryTestClient(t, 10, http.StatusTooManyRequests, policy, deadline)
+		_, err := client.RegenerateExample("hi", "int.", "嗨", "")
+		if !IsRateLimited(err) || calls.Load() != 1 {
+			t.Errorf("Expected to give up after 1 call with little time left, got %v after %d calls", err, calls.Load())
+		}
+	})
+}
+
+func TestLoadOpenAIRetryPolicy(t *testing.T) {
+	policy, err := LoadOpenAIRetryPolicy(func(string) string { return "" })
+	if err != nil || policy.MaxAttempts != 3 || policy.BaseDelay != 500*time.Millisecond || policy.MaxDelay != 4*time.Second {
+		t.Errorf("Expected defaults, got %+v (err %v)", policy, err)
+	}
+
+	env := map[string]string{"OPENAI_RETRY_MAX_ATTEMPTS": "5", "OPENAI_RETRY_BASE_DELAY": "200ms", "OPENAI_RETRY_MAX_DELAY": "2s"}
+	policy, err = LoadOpenAIRetryPolicy(func(key string) string { return env[key] })
+	if err != nil || policy.MaxAttempts != 5 || policy.BaseDelay != 200*time.Millisecond || policy.MaxDelay != 2*time.Second {
+		t.Errorf("Expected overrides, got %+v (err %v)", policy, err)
+	}
+
+	for key, value := range map[string]string{"OPENAI_RETRY_MAX_ATTEMPTS": "0", "OPENAI_RETRY_BASE_DELAY": "soon", "OPENAI_RETRY_MAX_DELAY": "1ms"} {
+		if _, err := LoadOpenAIRetryPolicy(func(k string) string {
+			if k == key {
+				return value
+			}
+			return ""
+		}); err == nil {
+			t.Errorf("Expected %s=%q to be rejected", key, value)
+		}
+	}
+}
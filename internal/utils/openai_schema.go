@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+// jsonRepairInstruction 回應無法解析時附在對話後面重試一次的指示
+const jsonRepairInstruction = "上一個回覆不是有效的 JSON。請只回傳符合指定 JSON schema 的 JSON 物件，不要加入任何說明文字或 code block。"
+
+// 翻譯與單字生成要求 OpenAI 以 structured output 回傳符合 struct 的 JSON
+var (
+	translationResponseFormat    = mustResponseFormat("translation_response", TranslationResponse{})
+	wordGenerationResponseFormat = mustResponseFormat("word_generation_response", WordGenerationResponse{})
+)
+
+// mustResponseFormat 以 Go struct 產生 json_schema response format，struct 無法轉成 schema 屬於程式錯誤
+func mustResponseFormat(name string, v any) *openai.ChatCompletionResponseFormat {
+	schema, err := jsonschema.GenerateSchemaForType(v)
+	if err != nil {
+		panic(fmt.Sprintf("failed to generate %s schema: %v", name, err))
+	}
+	requireAllProperties(schema)
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:   name,
+			Schema: schema,
+			Strict: true,
+		},
+	}
+}
+
+// requireAllProperties strict 模式要求每個欄位都列在 required，omitempty 的欄位（例如日文才有的 reading）
+// 也標為必填，模型以空字串或空陣列填入
+func requireAllProperties(d *jsonschema.Definition) {
+	if d == nil {
+		return
+	}
+	if len(d.Properties) > 0 {
+		d.Required = d.Required[:0]
+		for name, property := range d.Properties {
+			requireAllProperties(&property)
+			d.Properties[name] = property
+			d.Required = append(d.Required, name)
+		}
+		sort.Strings(d.Required)
+	}
+	requireAllProperties(d.Items)
+	for name, def := range d.Defs {
+		requireAllProperties(&def)
+		d.Defs[name] = def
+	}
+}
+
+// unmarshalModelJSON 解析模型輸出的 JSON；模型在 JSON 前後加了說明文字或 code block 時，
+// 改取第一個 { 到最後一個 } 之間的內容再試，回傳的是原本的解析錯誤
+func unmarshalModelJSON(content string, out any) error {
+	err := json.Unmarshal([]byte(content), out)
+	if err == nil {
+		return nil
+	}
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end <= start || json.Unmarshal([]byte(content[start:end+1]), out) != nil {
+		return err
+	}
+	return nil
+}
+
+// failureRecorder 回傳保存解析失敗輸出的 recorder；輸出含有用戶輸入的功能在用戶未同意 AI 資料使用時回傳 nil（不保存）
+func (c *OpenaiClient) failureRecorder(consented bool) *ParseFailureRecorder {
+	if !consented {
+		return nil
+	}
+	return c.parseFailures
+}
+
+// parseJSONResponse 將 req 的回應 content 解析成 out。無法解析時以 failures 保存失敗的輸出（nil 不保存），並附上該輸出與
+// 更嚴格的指示重試一次；仍無法解析時回傳 ErrResponseParse
+func (c *OpenaiClient) parseJSONResponse(req openai.ChatCompletionRequest, content string, feature OpenAIFeature, promptVersion string, failures *ParseFailureRecorder, out any) error {
+	err := unmarshalModelJSON(content, out)
+	if err == nil {
+		return nil
+	}
+	failures.Record(feature, promptVersion, req.Model, content, err)
+
+	repair := req
+	repair.Messages = append(slices.Clone(req.Messages),
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: jsonRepairInstruction},
+	)
+	resp, repairErr := c.createChatCompletion(repair)
+	if repairErr != nil {
+		return fmt.Errorf("%w: %v; repair request failed: %w", ErrResponseParse, err, repairErr)
+	}
+	repaired := resp.Choices[0].Message.Content
+	if err := unmarshalModelJSON(repaired, out); err != nil {
+		failures.Record(feature, promptVersion, repair.Model, repaired, err)
+		return fmt.Errorf("%w: %v", ErrResponseParse, err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newScriptedOpenAIClient 回傳依序回覆 contents 的 client，並記錄每次請求的 body
+func newScriptedOpenAIClient(t *testing.T, contents ...string) (*OpenaiClient, *[]map[string]any) {
+	t.Helper()
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, body)
+
+		content := contents[min(len(requests), len(contents))-1]
+		reply, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(reply)
+	}))
+	t.Cleanup(server.Close)
+
+	api, _ := NewOpenAIClient("test-key", server.URL, defaultGenerationParams, RetryPolicy{MaxAttempts: 1}, nil, nil)
+	return api.(*OpenaiClient), &requests
+}
+
+func TestTranslateStructuredOutput(t *testing.T) {
+	valid := `{"translations":[{"word":"book","reading":"","partOfSpeech":"n.","meaning":"書","example":{"en":"A book.","zh":"一本書。","reading":""},"synonyms":[],"antonyms":[]}]}`
+
+	t.Run("requests the json schema", func(t *testing.T) {
+		client, requests := newScriptedOpenAIClient(t, valid)
+		resp, err := client.Translate("book", TranslateOptions{})
+		if err != nil || len(resp.Translations) != 1 || resp.Translations[0].Meaning != "書" {
+			t.Fatalf("Unexpected translation %+v (err %v)", resp, err)
+		}
+		format, _ := (*requests)[0]["response_format"].(map[string]any)
+		if format["type"] != "json_schema" {
+			t.Errorf("Expected a json_schema response format, got %v", format)
+		}
+	})
+
+	t.Run("extracts json wrapped in prose", func(t *testing.T) {
+		client, requests := newScriptedOpenAIClient(t, "Here is the translation:\n```json\n"+valid+"\n```")
+		if _, err := client.Translate("book", TranslateOptions{}); err != nil || len(*requests) != 1 {
+			t.Errorf("Expected the wrapped JSON to parse without a repair request, got %v after %d requests", err, len(*requests))
+		}
+	})
+
+	t.Run("repairs once", func(t *testing.T) {
+		client, requests := newScriptedOpenAIClient(t, "Sorry, I can't format that.", valid)
+		resp, err := client.Translate("book", TranslateOptions{})
+		if err != nil || len(resp.Translations) != 1 {
+			t.Fatalf("Expected the repair request to succeed, got %+v (err %v)", resp, err)
+		}
+		if len(*requests) != 2 {
+			t.Fatalf("Expected 2 requests, got %d", len(*requests))
+		}
+		repairMessages, _ := (*requests)[1]["messages"].([]any)
+		last, _ := repairMessages[len(repairMessages)-1].(map[string]any)
+		if len(repairMessages) != 4 || !strings.Contains(last["content"].(string), "JSON") {
+			t.Errorf("Expected the repair request to append the bad output and a JSON instruction, got %v", repairMessages)
+		}
+	})
+
+	t.Run("gives up after the repair", func(t *testing.T) {
+		client, requests := newScriptedOpenAIClient(t, "not json", "still not json")
+		_, err := client.Translate("book", TranslateOptions{})
+		if !errors.Is(err, ErrResponseParse) || len(*requests) != 2 {
+			t.Errorf("Expected ErrResponseParse after 2 requests, got %v after %d", err, len(*requests))
+		}
+	})
+}
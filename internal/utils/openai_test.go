@@ -104,3 +104,58 @@ func TestTranslationResponseParsing(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadGenerationParams(t *testing.T) {
+	t.Run("Defaults when unset", func(t *testing.T) {
+		params, err := LoadGenerationParams(func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if params[FeatureTranslation].Temperature != 1.0 || params[FeatureWordGeneration].Temperature != 1.0 {
+			t.Errorf("Expected default temperature 1.0, got %+v", params)
+		}
+	})
+
+	t.Run("Per-feature overrides", func(t *testing.T) {
+		env := map[string]string{
+			"OPENAI_TRANSLATION_TEMPERATURE": "0.2",
+			"OPENAI_TRANSLATION_MAX_TOKENS":  "800",
+			"OPENAI_TRANSLATION_TOP_P":       "0.9",
+		}
+		params, err := LoadGenerationParams(func(key string) string { return env[key] })
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		translation := params[FeatureTranslation]
+		if translation.Temperature != 0.2 || translation.MaxTokens != 800 || translation.TopP != 0.9 {
+			t.Errorf("Unexpected translation params: %+v", translation)
+		}
+		if params[FeatureWordGeneration].Temperature != 1.0 {
+			t.Errorf("Word generation should keep its default, got %+v", params[FeatureWordGeneration])
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		_, err := LoadGenerationParams(func(key string) string {
+			if key == "OPENAI_WORD_GENERATION_TEMPERATURE" {
+				return "3"
+			}
+			return ""
+		})
+		if err == nil {
+			t.Error("Expected error for out-of-range temperature")
+		}
+	})
+}
+
+func TestResolveModel(t *testing.T) {
+	if model, err := resolveModel("", "gpt-5"); err != nil || model != "gpt-5" {
+		t.Errorf("Expected empty preference to use the feature default, got %q, %v", model, err)
+	}
+	if model, err := resolveModel("gpt-4o", "gpt-4o-mini"); err != nil || model != "gpt-4o" {
+		t.Errorf("Expected user preference to win, got %q, %v", model, err)
+	}
+	if _, err := resolveModel("o1-pro", "gpt-4o-mini"); err == nil {
+		t.Error("Expected a model outside the allow list to be rejected")
+	}
+}
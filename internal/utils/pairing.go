@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 排除容易混淆的字元 (0/O, 1/I/L)
+const pairingCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+const (
+	PairingCodeLength  = 8
+	sessionTokenLength = 32
+)
+
+// GeneratePairingCode returns a random code that is easy to type on another device
+func GeneratePairingCode() (string, error) {
+	max := big.NewInt(int64(len(pairingCodeAlphabet)))
+	code := make([]byte, PairingCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate pairing code: %w", err)
+		}
+		code[i] = pairingCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// NormalizePairingCode upper-cases the code and strips separators users may type
+func NormalizePairingCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// GenerateSessionToken returns an opaque URL-safe session token
+func GenerateSessionToken() (string, error) {
+	buf := make([]byte, sessionTokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashSessionToken returns the value stored in DynamoDB for a session token
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+const profileSlugLength = 9
+
+// GenerateProfileSlug returns a random, unguessable identifier for a public profile URL
+func GenerateProfileSlug() (string, error) {
+	buf := make([]byte, profileSlugLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate profile slug: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePairingCode(t *testing.T) {
+	code, err := GeneratePairingCode()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(code) != PairingCodeLength {
+		t.Errorf("Expected length %d, got %d (%s)", PairingCodeLength, len(code), code)
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(pairingCodeAlphabet, r) {
+			t.Errorf("Unexpected character %q in code %s", r, code)
+		}
+	}
+}
+
+func TestNormalizePairingCode(t *testing.T) {
+	if got := NormalizePairingCode(" abcd-ef 23 "); got != "ABCDEF23" {
+		t.Errorf("Expected ABCDEF23, got %s", got)
+	}
+}
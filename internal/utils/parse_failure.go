@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ParseFailureSampleRateEnv 解析失敗時保存模型原始輸出的比例（0～1），"0" 表示不保存
+	ParseFailureSampleRateEnv = "OPENAI_PARSE_FAILURE_SAMPLE_RATE"
+	// DefaultParseFailureSampleRate 未設定 ParseFailureSampleRateEnv 時的保存比例
+	DefaultParseFailureSampleRate = 0.2
+
+	// 保存的解析失敗只用來修 prompt，到期由 TTL 刪除
+	parseFailureRetention = 30 * 24 * time.Hour
+	// minPhoneDigits 電話號碼至少的位數，避免把日期（2024-03-01 為 8 碼）當成電話
+	minPhoneDigits = 9
+	// maxParseFailureOutputBytes 保存的輸出上限，遠低於 DynamoDB 400KB 的 item 上限
+	maxParseFailureOutputBytes = 8 * 1024
+)
+
+var (
+	lineIDPattern = regexp.MustCompile(`\b[UCR][0-9a-f]{32}\b`)
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// 電話號碼候選：數字間可包含 +、空白、-、括號，實際遮蔽前再檢查位數
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-()]{6,}\d`)
+)
+
+// LoadParseFailureSampleRate reads ParseFailureSampleRateEnv, falling back to DefaultParseFailureSampleRate when unset
+func LoadParseFailureSampleRate(getenv func(string) string) (float64, error) {
+	value := strings.TrimSpace(getenv(ParseFailureSampleRateEnv))
+	if value == "" {
+		return DefaultParseFailureSampleRate, nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("%s must be a number between 0 and 1, got %q", ParseFailureSampleRateEnv, value)
+	}
+	return rate, nil
+}
+
+// SanitizeModelOutput removes personal data the model may have echoed from user input before the output is stored:
+// LINE IDs are pseudonymized, emails and phone numbers are masked, and the result is truncated to
+// maxParseFailureOutputBytes. Reports whether the output was truncated
+func SanitizeModelOutput(output string) (string, bool) {
+	output = lineIDPattern.ReplaceAllStringFunc(output, PseudonymizeID)
+	output = emailPattern.ReplaceAllString(output, "[email]")
+	output = phonePattern.ReplaceAllStringFunc(output, func(match string) string {
+		digits := 0
+		for _, r := range match {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits < minPhoneDigits {
+			return match
+		}
+		return "[phone]"
+	})
+
+	if len(output) <= maxParseFailureOutputBytes {
+		return output, false
+	}
+	cut := maxParseFailureOutputBytes
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	return output[:cut], true
+}
+
+// ParseFailureRecorder samples OpenAI responses that fail to parse and stores them sanitized, so prompt fixes can
+// start from real failures. A nil recorder records nothing. Safe for concurrent use
+type ParseFailureRecorder struct {
+	logger     *logrus.Entry
+	repo       ParseFailureRepository
+	sampleRate float64
+	rnd        *rand.Rand
+	now        func() time.Time
+}
+
+func NewParseFailureRecorder(logger *logrus.Entry, repo ParseFailureRepository, sampleRate float64) *ParseFailureRecorder {
+	return &ParseFailureRecorder{
+		logger:     logger,
+		repo:       repo,
+		sampleRate: sampleRate,
+		rnd:        NewConcurrentRand(time.Now().UnixNano()),
+		now:        time.Now,
+	}
+}
+
+// Record 依抽樣比例保存一次解析失敗；寫入失敗只記 log，不影響原本的錯誤處理流程
+func (r *ParseFailureRecorder) Record(feature OpenAIFeature, promptVersion, model, output string, parseErr error) {
+	if r == nil || parseErr == nil || r.sampleRate <= 0 || r.rnd.Float64() >= r.sampleRate {
+		return
+	}
+	now := r.now().UTC()
+
+	sanitized, truncated := SanitizeModelOutput(output)
+	failure := models.ParseFailure{
+		Feature:       string(feature),
+		PromptVersion: promptVersion,
+		Model:         model,
+		Output:        sanitized,
+		Truncated:     truncated,
+		Error:         parseErr.Error(),
+		OccurredAt:    now.Format(time.RFC3339Nano),
+		ExpiresAt:     now.Add(parseFailureRetention).Unix(),
+	}
+	if err := r.repo.SaveParseFailure(failure); err != nil {
+		r.logger.WithError(err).WithField("feature", feature).Warn("Failed to record parse failure")
+	}
+}
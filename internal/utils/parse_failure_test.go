@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"errors"
+	"language-assistant/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type savedParseFailures struct {
+	ParseFailureRepository
+	saved []models.ParseFailure
+}
+
+func (s *savedParseFailures) SaveParseFailure(failure models.ParseFailure) error {
+	s.saved = append(s.saved, failure)
+	return nil
+}
+
+func TestSanitizeModelOutput(t *testing.T) {
+	output := `{"word": "contact", "date": "2024-03-01", "example": "Email amy.chen@example.com or call +886 912-345-678", "user": "U0123456789abcdef0123456789abcdef"`
+	sanitized, truncated := SanitizeModelOutput(output)
+	if truncated {
+		t.Error("Short output should not be truncated")
+	}
+	for _, leaked := range []string{"amy.chen@example.com", "912-345-678", "U0123456789abcdef0123456789abcdef"} {
+		if strings.Contains(sanitized, leaked) {
+			t.Errorf("Expected %q to be removed, got %s", leaked, sanitized)
+		}
+	}
+	if !strings.Contains(sanitized, `"word": "contact", "date": "2024-03-01"`) || !strings.Contains(sanitized, "[email]") || !strings.Contains(sanitized, "[phone]") {
+		t.Errorf("Unexpected sanitized output: %s", sanitized)
+	}
+
+	long, truncated := SanitizeModelOutput(strings.Repeat("單字", maxParseFailureOutputBytes))
+	if !truncated || len(long) > maxParseFailureOutputBytes || !strings.HasSuffix(long, "字") {
+		t.Errorf("Expected output truncated on a rune boundary within %d bytes, got %d bytes", maxParseFailureOutputBytes, len(long))
+	}
+}
+
+func TestParseFailureRecorderSampling(t *testing.T) {
+	parseErr := errors.New("unexpected end of JSON input")
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	newRecorder := func(rate float64) (*ParseFailureRecorder, *savedParseFailures) {
+		repo := &savedParseFailures{}
+		recorder := NewParseFailureRecorder(logrus.NewEntry(logrus.New()), repo, rate)
+		recorder.now = func() time.Time { return now }
+		return recorder, repo
+	}
+
+	recorder, repo := newRecorder(0)
+	recorder.Record(FeatureTranslation, "v2", "gpt-4o-mini", `{"translations": [`, parseErr)
+	if len(repo.saved) != 0 {
+		t.Fatalf("Sample rate 0 should record nothing, got %d", len(repo.saved))
+	}
+
+	recorder, repo = newRecorder(1)
+	recorder.Record(FeatureTranslation, "v2", "gpt-4o-mini", `{"translations": [`, parseErr)
+	recorder.Record(FeatureTranslation, "v2", "gpt-4o-mini", `{}`, nil)
+	if len(repo.saved) != 1 {
+		t.Fatalf("Expected one recorded failure, got %d", len(repo.saved))
+	}
+	failure := repo.saved[0]
+	if failure.Feature != "TRANSLATION" || failure.PromptVersion != "v2" || failure.Output != `{"translations": [` || failure.Error != parseErr.Error() {
+		t.Errorf("Unexpected failure: %+v", failure)
+	}
+	if failure.ExpiresAt != now.Add(parseFailureRetention).Unix() {
+		t.Errorf("Expected expiry after %s, got %d", parseFailureRetention, failure.ExpiresAt)
+	}
+
+	var disabled *ParseFailureRecorder
+	disabled.Record(FeatureTranslation, "", "gpt-4o-mini", "{", parseErr)
+}
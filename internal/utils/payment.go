@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PaymentProviderLinePay 付款紀錄上的金流商名稱
+	PaymentProviderLinePay = "linepay"
+
+	// LinePayConfirmPath、LinePayCancelPath 是 language-payment 接收 LINE Pay 導回的路徑
+	LinePayConfirmPath = "/payments/linepay/confirm"
+	LinePayCancelPath  = "/payments/linepay/cancel"
+
+	supporterProductName = "語言小幫手 支持者方案"
+)
+
+// ErrPaymentNotFound 付款不存在，或導回時帶的交易編號與付款不符
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// PaymentService starts supporter payments through LINE Pay and, once LINE Pay redirects back, confirms them, flips
+// the user's plan and keeps a receipt. Safe for concurrent use
+type PaymentService struct {
+	logger         *logrus.Entry
+	linePay        LinePayAPI
+	repo           PaymentRepository
+	userConfigRepo UserConfigRepository
+	config         LinePayConfig
+	now            func() time.Time
+}
+
+func NewPaymentService(logger *logrus.Entry, linePay LinePayAPI, repo PaymentRepository, userConfigRepo UserConfigRepository, config LinePayConfig) *PaymentService {
+	return &PaymentService{
+		logger:         logger,
+		linePay:        linePay,
+		repo:           repo,
+		userConfigRepo: userConfigRepo,
+		config:         config,
+		now:            time.Now,
+	}
+}
+
+// Price 支持者方案的金額與幣別
+func (s *PaymentService) Price() (int, string) {
+	return s.config.Price, s.config.Currency
+}
+
+// StartSupporterPayment 向 LINE Pay 建立一筆支持者方案的付款並保存為 pending，回傳讓用戶付款的網址
+func (s *PaymentService) StartSupporterPayment(userID string) (string, error) {
+	now := s.now().UTC()
+	orderID, err := newOrderID(now)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := s.linePay.RequestPayment(LinePayRequest{
+		OrderID:     orderID,
+		Amount:      s.config.Price,
+		Currency:    s.config.Currency,
+		ProductName: supporterProductName,
+		ConfirmURL:  s.config.CallbackURL + LinePayConfirmPath,
+		// LINE Pay 只在確認網址附上 orderId，取消網址要自己帶
+		CancelURL: s.config.CallbackURL + LinePayCancelPath + "?orderId=" + url.QueryEscape(orderID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to request LINE Pay payment: %w", err)
+	}
+
+	// 付款紀錄存好才回傳網址，確認時才找得到這筆付款
+	if err := s.repo.SavePayment(models.Payment{
+		OrderID:       orderID,
+		UserID:        userID,
+		Plan:          models.PlanSupporter,
+		Amount:        s.config.Price,
+		Currency:      s.config.Currency,
+		Provider:      PaymentProviderLinePay,
+		TransactionID: result.TransactionID,
+		Status:        models.PaymentPending,
+		CreatedAt:     now.Format(time.RFC3339),
+	}); err != nil {
+		return "", err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"orderId":       orderID,
+		"transactionId": result.TransactionID,
+	}).Info("Started supporter payment")
+	return result.PaymentURL, nil
+}
+
+// ConfirmPayment 在用戶從 LINE Pay 導回時確認付款並切換方案，回傳付款與這次呼叫是否完成了確認。
+// 已確認過的付款不會再次扣款，只重新套用方案，所以重新整理確認頁面是安全的
+func (s *PaymentService) ConfirmPayment(orderID, transactionID string) (*models.Payment, bool, error) {
+	payment, err := s.repo.GetPayment(orderID)
+	if err != nil {
+		return nil, false, err
+	}
+	if payment == nil || payment.TransactionID != transactionID {
+		return nil, false, ErrPaymentNotFound
+	}
+
+	switch payment.Status {
+	case models.PaymentConfirmed:
+		return payment, false, s.applyPlan(payment)
+	case models.PaymentPending:
+	default:
+		return payment, false, fmt.Errorf("payment %s is %s", orderID, payment.Status)
+	}
+
+	now := s.now().UTC().Format(time.RFC3339)
+	if err := s.linePay.ConfirmPayment(transactionID, payment.Amount, payment.Currency); err != nil {
+		if _, updateErr := s.repo.UpdatePaymentStatus(orderID, models.PaymentPending, models.PaymentFailed, now); updateErr != nil {
+			s.logger.WithError(updateErr).WithField("orderId", orderID).Error("Failed to mark payment failed")
+		}
+		return payment, false, fmt.Errorf("failed to confirm LINE Pay payment: %w", err)
+	}
+
+	// LINE Pay 已扣款就以扣款結果為準：同一筆付款被重複確認時，另一次請求可能先把它標成失敗
+	confirmed, err := s.repo.UpdatePaymentStatus(orderID, models.PaymentPending, models.PaymentConfirmed, now)
+	if err == nil && !confirmed {
+		confirmed, err = s.repo.UpdatePaymentStatus(orderID, models.PaymentFailed, models.PaymentConfirmed, now)
+	}
+	if err != nil {
+		return payment, false, err
+	}
+	payment.Status = models.PaymentConfirmed
+	payment.UpdatedAt = now
+	if !confirmed {
+		// 另一個請求已經完成確認與收據
+		return payment, false, s.applyPlan(payment)
+	}
+
+	receipt := models.PaymentReceipt{
+		UserID:        payment.UserID,
+		OrderID:       payment.OrderID,
+		TransactionID: payment.TransactionID,
+		Provider:      payment.Provider,
+		Plan:          payment.Plan,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		ConfirmedAt:   now,
+	}
+	logger := s.logger.WithFields(logrus.Fields{
+		"orderId":       receipt.OrderID,
+		"transactionId": receipt.TransactionID,
+		"plan":          receipt.Plan,
+		"amount":        receipt.Amount,
+		"currency":      receipt.Currency,
+	})
+	// 收據寫入失敗不影響升級，log 中仍保有完整的收據內容可供補登
+	if err := s.repo.SavePaymentReceipt(receipt); err != nil {
+		logger.WithError(err).Error("Failed to save payment receipt")
+	}
+	logger.Info("Payment confirmed")
+
+	return payment, true, s.applyPlan(payment)
+}
+
+// CancelPayment 用戶在 LINE Pay 取消付款時將 pending 的付款標為取消
+func (s *PaymentService) CancelPayment(orderID string) error {
+	payment, err := s.repo.GetPayment(orderID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return ErrPaymentNotFound
+	}
+	_, err = s.repo.UpdatePaymentStatus(orderID, models.PaymentPending, models.PaymentCancelled, s.now().UTC().Format(time.RFC3339))
+	return err
+}
+
+func (s *PaymentService) applyPlan(payment *models.Payment) error {
+	if err := s.userConfigRepo.SetPlan(payment.UserID, payment.Plan); err != nil {
+		s.logger.WithError(err).WithField("orderId", payment.OrderID).Error("Failed to apply plan for confirmed payment")
+		return fmt.Errorf("failed to apply plan: %w", err)
+	}
+	return nil
+}
+
+// newOrderID 產生不重複的訂單編號，以時間開頭方便對帳時排序
+func newOrderID(now time.Time) (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate order ID: %w", err)
+	}
+	return now.Format("20060102150405") + "-" + hex.EncodeToString(b), nil
+}
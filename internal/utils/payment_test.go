@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"language-assistant/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type memoryPayments struct {
+	payments map[string]models.Payment
+	receipts []models.PaymentReceipt
+}
+
+func (m *memoryPayments) SavePayment(payment models.Payment) error {
+	m.payments[payment.OrderID] = payment
+	return nil
+}
+
+func (m *memoryPayments) GetPayment(orderID string) (*models.Payment, error) {
+	payment, ok := m.payments[orderID]
+	if !ok {
+		return nil, nil
+	}
+	return &payment, nil
+}
+
+func (m *memoryPayments) UpdatePaymentStatus(orderID string, from, to models.PaymentStatus, updatedAt string) (bool, error) {
+	payment := m.payments[orderID]
+	if payment.Status != from {
+		return false, nil
+	}
+	payment.Status, payment.UpdatedAt = to, updatedAt
+	m.payments[orderID] = payment
+	return true, nil
+}
+
+func (m *memoryPayments) SavePaymentReceipt(receipt models.PaymentReceipt) error {
+	m.receipts = append(m.receipts, receipt)
+	return nil
+}
+
+func (m *memoryPayments) GetPaymentReceipts(userID string) ([]models.PaymentReceipt, error) {
+	return m.receipts, nil
+}
+
+type recordingPlans struct {
+	UserConfigRepository
+	plans map[string]models.Plan
+}
+
+func (r *recordingPlans) SetPlan(userID string, plan models.Plan) error {
+	r.plans[userID] = plan
+	return nil
+}
+
+// newTestLinePay 回傳連到假 LINE Pay 的 client，確認請求回覆 confirmCode，並記錄每個請求的路徑
+func newTestLinePay(t *testing.T, confirmCode string) (LinePayConfig, *[]string) {
+	t.Helper()
+	var paths []string
+	config := LinePayConfig{ChannelID: "1234", ChannelSecret: "secret", CallbackURL: "https://example.com", Price: 150, Currency: "TWD"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		paths = append(paths, r.URL.Path)
+		want := LinePaySignature(config.ChannelSecret, r.URL.Path, string(body), r.Header.Get("X-LINE-Authorization-Nonce"))
+		if r.Header.Get("X-LINE-Authorization") != want || r.Header.Get("X-LINE-ChannelId") != config.ChannelID {
+			t.Errorf("Unexpected LINE Pay auth headers for %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/confirm") {
+			w.Write([]byte(`{"returnCode":"` + confirmCode + `","returnMessage":"result"}`))
+			return
+		}
+		w.Write([]byte(`{"returnCode":"0000","returnMessage":"Success.","info":{"paymentUrl":{"web":"https://pay.example/web"},"transactionId":2019049910005496810}}`))
+	}))
+	t.Cleanup(server.Close)
+	config.APIURL = server.URL
+	return config, &paths
+}
+
+func TestPaymentService(t *testing.T) {
+	newService := func(confirmCode string) (*PaymentService, *memoryPayments, *recordingPlans, *[]string) {
+		config, paths := newTestLinePay(t, confirmCode)
+		repo := &memoryPayments{payments: map[string]models.Payment{}}
+		plans := &recordingPlans{plans: map[string]models.Plan{}}
+		return NewPaymentService(logrus.NewEntry(logrus.New()), NewLinePayClient(config), repo, plans, config), repo, plans, paths
+	}
+	startPayment := func(t *testing.T, service *PaymentService, repo *memoryPayments) models.Payment {
+		t.Helper()
+		paymentURL, err := service.StartSupporterPayment("U1")
+		if err != nil || paymentURL != "https://pay.example/web" || len(repo.payments) != 1 {
+			t.Fatalf("Unexpected payment start %q (err %v), %d payments", paymentURL, err, len(repo.payments))
+		}
+		for _, payment := range repo.payments {
+			return payment
+		}
+		return models.Payment{}
+	}
+
+	t.Run("confirms once and flips the plan", func(t *testing.T) {
+		service, repo, plans, paths := newService("0000")
+		payment := startPayment(t, service, repo)
+		if payment.Status != models.PaymentPending || payment.TransactionID != "2019049910005496810" {
+			t.Fatalf("Expected a pending payment with the exact transaction ID, got %+v", payment)
+		}
+
+		if _, _, err := service.ConfirmPayment(payment.OrderID, "other"); !errors.Is(err, ErrPaymentNotFound) {
+			t.Errorf("Expected ErrPaymentNotFound for a mismatched transaction, got %v", err)
+		}
+
+		confirmedPayment, confirmed, err := service.ConfirmPayment(payment.OrderID, payment.TransactionID)
+		if err != nil || !confirmed || confirmedPayment.Status != models.PaymentConfirmed {
+			t.Fatalf("Expected the payment to be confirmed, got %+v, %v (err %v)", confirmedPayment, confirmed, err)
+		}
+		if plans.plans["U1"] != models.PlanSupporter || len(repo.receipts) != 1 || repo.receipts[0].Amount != 150 {
+			t.Errorf("Expected the supporter plan and one receipt, got %v and %+v", plans.plans, repo.receipts)
+		}
+
+		// 重新整理確認頁面不會再次向 LINE Pay 確認或產生收據
+		if _, confirmed, err := service.ConfirmPayment(payment.OrderID, payment.TransactionID); err != nil || confirmed {
+			t.Errorf("Expected a repeated confirmation to be a no-op, got %v (err %v)", confirmed, err)
+		}
+		if len(*paths) != 2 || len(repo.receipts) != 1 {
+			t.Errorf("Expected one request and one confirm call, got %v and %d receipts", *paths, len(repo.receipts))
+		}
+	})
+
+	t.Run("marks rejected confirmations failed", func(t *testing.T) {
+		service, repo, plans, _ := newService("1150")
+		payment := startPayment(t, service, repo)
+
+		_, _, err := service.ConfirmPayment(payment.OrderID, payment.TransactionID)
+		var linePayErr *LinePayError
+		if !errors.As(err, &linePayErr) || linePayErr.Code != "1150" {
+			t.Fatalf("Expected a LINE Pay error, got %v", err)
+		}
+		if repo.payments[payment.OrderID].Status != models.PaymentFailed || len(plans.plans) != 0 {
+			t.Errorf("Expected a failed payment and no plan change, got %+v and %v", repo.payments[payment.OrderID], plans.plans)
+		}
+	})
+
+	t.Run("cancels pending payments", func(t *testing.T) {
+		service, repo, _, _ := newService("0000")
+		payment := startPayment(t, service, repo)
+		if err := service.CancelPayment(payment.OrderID); err != nil || repo.payments[payment.OrderID].Status != models.PaymentCancelled {
+			t.Errorf("Expected the payment to be cancelled, got %+v (err %v)", repo.payments[payment.OrderID], err)
+		}
+		if err := service.CancelPayment("missing"); !errors.Is(err, ErrPaymentNotFound) {
+			t.Errorf("Expected ErrPaymentNotFound, got %v", err)
+		}
+	})
+}
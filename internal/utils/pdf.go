@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page layout in PDF points
+const (
+	pdfPageWidth    = 595.0
+	pdfPageHeight   = 842.0
+	pdfMargin       = 50.0
+	pdfUsableWidth  = pdfPageWidth - 2*pdfMargin
+	pdfLineSpacing  = 1.45
+	pdfFullWidthEm  = 1.0
+	pdfHalfWidthEm  = 0.5
+	pdfReplacement  = '?'
+	pdfFontResource = "F1"
+)
+
+// pdfLine is a single positioned line of text
+type pdfLine struct {
+	text     string
+	fontSize float64
+	x        float64
+	y        float64
+}
+
+// PDFDocument is a minimal text-only PDF writer.
+// It uses the standard (non-embedded) Adobe-CNS1 font MSung-Light so Traditional Chinese renders
+// without shipping a font file; characters outside the BMP (e.g. emoji) are replaced.
+// Not safe for concurrent use: build each document on a single goroutine.
+type PDFDocument struct {
+	pages [][]pdfLine
+	y     float64
+}
+
+func NewPDFDocument() *PDFDocument {
+	doc := &PDFDocument{}
+	doc.newPage()
+	return doc
+}
+
+func (d *PDFDocument) newPage() {
+	d.pages = append(d.pages, []pdfLine{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// AddParagraph wraps text to the page width and appends it, starting new pages as needed
+func (d *PDFDocument) AddParagraph(text string, fontSize, indent float64) {
+	for _, line := range WrapPDFText(text, fontSize, pdfUsableWidth-indent) {
+		d.addLine(line, fontSize, indent)
+	}
+}
+
+// AddSpacing adds vertical whitespace equal to one line of the given font size
+func (d *PDFDocument) AddSpacing(fontSize float64) {
+	d.y -= fontSize * pdfLineSpacing
+}
+
+// EnsureSpace starts a new page if fewer than the given points remain,
+// so a word card is not split across pages
+func (d *PDFDocument) EnsureSpace(points float64) {
+	if d.y-points < pdfMargin {
+		d.newPage()
+	}
+}
+
+func (d *PDFDocument) addLine(text string, fontSize, indent float64) {
+	height := fontSize * pdfLineSpacing
+	if d.y-height < pdfMargin {
+		d.newPage()
+	}
+	d.y -= height
+
+	page := len(d.pages) - 1
+	d.pages[page] = append(d.pages[page], pdfLine{text: text, fontSize: fontSize, x: pdfMargin + indent, y: d.y})
+}
+
+// Bytes serializes the document
+func (d *PDFDocument) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Object layout: 1 catalog, 2 pages, 3 font, 4 CID font, 5 font descriptor,
+	// then a (page, content) pair per page
+	pageCount := len(d.pages)
+	kids := make([]string, pageCount)
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+i*2)
+	}
+
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pageCount))
+	writeObject("<< /Type /Font /Subtype /Type0 /BaseFont /MSung-Light /Encoding /UniCNS-UCS2-H /DescendantFonts [4 0 R] >>")
+	writeObject("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /MSung-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (CNS1) /Supplement 0 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	writeObject("<< /Type /FontDescriptor /FontName /MSung-Light /Flags 6 /FontBBox [-160 -249 1015 888] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	for i, lines := range d.pages {
+		var content bytes.Buffer
+		for _, line := range lines {
+			if line.text == "" {
+				continue
+			}
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.2f %.2f Td <%s> Tj ET\n",
+				pdfFontResource, line.fontSize, line.x, line.y, encodePDFText(line.text))
+		}
+
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /%s 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pdfFontResource, 7+i*2))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	return buf.Bytes()
+}
+
+// encodePDFText encodes text as UTF-16BE hex for the UCS2 CMap
+func encodePDFText(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if r > 0xFFFF {
+			r = pdfReplacement
+		}
+		fmt.Fprintf(&sb, "%04X", r)
+	}
+	return sb.String()
+}
+
+// pdfRuneWidth approximates glyph width in em: ASCII is half width, everything else full width
+func pdfRuneWidth(r rune) float64 {
+	if r < 0x80 {
+		return pdfHalfWidthEm
+	}
+	return pdfFullWidthEm
+}
+
+// WrapPDFText splits text into lines that fit the given width, preferring to break at spaces
+func WrapPDFText(text string, fontSize, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines = append(lines, wrapPDFParagraph(paragraph, fontSize, maxWidth)...)
+	}
+	return lines
+}
+
+func wrapPDFParagraph(paragraph string, fontSize, maxWidth float64) []string {
+	runes := []rune(paragraph)
+	if len(runes) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	start, lastSpace := 0, -1
+	width := 0.0
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == ' ' {
+			lastSpace = i
+		}
+		width += pdfRuneWidth(runes[i]) * fontSize
+		if width <= maxWidth || i == start {
+			continue
+		}
+
+		// 超過寬度：優先在空白處斷行，否則在目前字元前斷行（中文）
+		breakAt := i
+		if lastSpace > start {
+			breakAt = lastSpace
+		}
+		lines = append(lines, strings.TrimRight(string(runes[start:breakAt]), " "))
+		if breakAt == lastSpace {
+			breakAt++
+		}
+		start, lastSpace = breakAt, -1
+		width = 0
+		for j := start; j <= i; j++ {
+			if runes[j] == ' ' {
+				lastSpace = j
+			}
+			width += pdfRuneWidth(runes[j]) * fontSize
+		}
+	}
+	lines = append(lines, string(runes[start:]))
+	return lines
+}
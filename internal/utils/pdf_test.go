@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestWrapPDFText(t *testing.T) {
+	t.Run("Breaks English at spaces", func(t *testing.T) {
+		lines := WrapPDFText("the quick brown fox", 10, 50) // 10 half-width characters per line
+		expected := []string{"the quick", "brown fox"}
+		if len(lines) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, lines)
+		}
+		for i := range expected {
+			if lines[i] != expected[i] {
+				t.Errorf("Line %d: expected %q, got %q", i, expected[i], lines[i])
+			}
+		}
+	})
+
+	t.Run("Breaks Chinese anywhere", func(t *testing.T) {
+		lines := WrapPDFText("我昨天買了一本新書", 10, 40) // 4 full-width characters per line
+		expected := []string{"我昨天買", "了一本新", "書"}
+		if len(lines) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, lines)
+		}
+		for i := range expected {
+			if lines[i] != expected[i] {
+				t.Errorf("Line %d: expected %q, got %q", i, expected[i], lines[i])
+			}
+		}
+	})
+}
+
+func TestBuildStudySheetPDF(t *testing.T) {
+	words := make([]models.WordRecord, 40)
+	for i := range words {
+		words[i] = models.WordRecord{Word: "book", PartOfSpeech: "n.", Translation: "書本", Sentence: "I bought a new book yesterday."}
+	}
+
+	pdf := BuildStudySheetPDF("單字學習單", []models.UserVocabulary{{Date: "2025-05-01", Words: words}})
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) {
+		t.Error("Expected PDF header")
+	}
+	if !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Error("Expected PDF trailer")
+	}
+	if bytes.Contains(pdf, []byte("/Count 1 ")) {
+		t.Error("Expected 40 word cards to span multiple pages")
+	}
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"language-assistant/internal/models"
+	"sync"
+	"time"
+)
+
+// PromptRolloutThresholds controls when a candidate prompt is rolled back automatically
+type PromptRolloutThresholds struct {
+	MinSamples              int     // 候選版本至少要有這麼多次請求才判斷
+	MaxParseFailureIncrease float64 // 解析失敗率最多可比 baseline 高出多少（絕對值）
+}
+
+var DefaultPromptRolloutThresholds = PromptRolloutThresholds{
+	MinSamples:              50,
+	MaxParseFailureIncrease: 0.05,
+}
+
+// InPromptRollout deterministically buckets a user so they see the same prompt version for the whole rollout
+func InPromptRollout(userID, rolloutID string, percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	if percent >= 100 {
+		return true
+	}
+	sum := sha256.Sum256([]byte(rolloutID + "#" + userID))
+	return binary.BigEndian.Uint32(sum[:4])%100 < uint32(percent)
+}
+
+// EvaluatePromptRollout reports whether the candidate should be rolled back and why
+func EvaluatePromptRollout(baseline, candidate models.PromptMetrics, thresholds PromptRolloutThresholds) (bool, string) {
+	if candidate.Requests < thresholds.MinSamples {
+		return false, ""
+	}
+
+	increase := candidate.ParseFailureRate() - baseline.ParseFailureRate()
+	if increase > thresholds.MaxParseFailureIncrease {
+		return true, fmt.Sprintf("parse failure rate %.1f%% exceeds baseline %.1f%% by more than %.1f%%",
+			candidate.ParseFailureRate()*100, baseline.ParseFailureRate()*100, thresholds.MaxParseFailureIncrease*100)
+	}
+	return false, ""
+}
+
+// PromptRolloutCache keeps the last loaded rollout for ttl so handlers don't read DynamoDB on every message.
+// Safe for concurrent use; the loader runs outside the lock, so concurrent misses may load twice
+type PromptRolloutCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	rollout  *models.PromptRollout
+	loadedAt time.Time
+}
+
+func NewPromptRolloutCache(ttl time.Duration) *PromptRolloutCache {
+	return &PromptRolloutCache{ttl: ttl}
+}
+
+// Get returns the cached rollout, reloading it once ttl has passed.
+// A failed load keeps the previous value and returns the error so the caller can log it
+func (c *PromptRolloutCache) Get(load func() (*models.PromptRollout, error)) (*models.PromptRollout, error) {
+	c.mu.Lock()
+	rollout, fresh := c.rollout, time.Since(c.loadedAt) <= c.ttl
+	c.mu.Unlock()
+	if fresh {
+		return rollout, nil
+	}
+
+	loaded, err := load()
+	if err != nil {
+		return rollout, err
+	}
+
+	c.mu.Lock()
+	c.rollout = loaded
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+	return loaded, nil
+}
+
+// Clear drops the cached rollout until ttl passes, e.g. right after this instance rolled it back
+func (c *PromptRolloutCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rollout = nil
+	c.loadedAt = time.Now()
+}
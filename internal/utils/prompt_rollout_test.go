@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestInPromptRollout(t *testing.T) {
+	inRollout := 0
+	for i := 0; i < 1000; i++ {
+		userID := fmt.Sprintf("U%d", i)
+		if InPromptRollout(userID, "2025-05-01T00:00:00Z", 10) {
+			inRollout++
+		}
+		if InPromptRollout(userID, "r", 10) != InPromptRollout(userID, "r", 10) {
+			t.Fatalf("Bucketing is not deterministic for %s", userID)
+		}
+	}
+	if inRollout < 50 || inRollout > 150 {
+		t.Errorf("Expected roughly 10%% of users in rollout, got %d/1000", inRollout)
+	}
+}
+
+func TestEvaluatePromptRollout(t *testing.T) {
+	baseline := models.PromptMetrics{Version: "v1", Requests: 500, ParseFailures: 10}
+
+	t.Run("Not enough samples", func(t *testing.T) {
+		rollback, _ := EvaluatePromptRollout(baseline, models.PromptMetrics{Requests: 10, ParseFailures: 10}, DefaultPromptRolloutThresholds)
+		if rollback {
+			t.Error("Expected no rollback before MinSamples")
+		}
+	})
+
+	t.Run("Within threshold", func(t *testing.T) {
+		rollback, _ := EvaluatePromptRollout(baseline, models.PromptMetrics{Requests: 100, ParseFailures: 5}, DefaultPromptRolloutThresholds)
+		if rollback {
+			t.Error("Expected no rollback within threshold")
+		}
+	})
+
+	t.Run("Exceeds threshold", func(t *testing.T) {
+		rollback, reason := EvaluatePromptRollout(baseline, models.PromptMetrics{Requests: 100, ParseFailures: 20}, DefaultPromptRolloutThresholds)
+		if !rollback || reason == "" {
+			t.Errorf("Expected rollback with reason, got %v %q", rollback, reason)
+		}
+	})
+}
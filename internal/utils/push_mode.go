@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"strings"
+	"time"
+)
+
+// PushModeEnv 選擇每日推播的觸發方式，language-handler 與 language-dispatcher 必須設定相同的值
+const PushModeEnv = "PUSH_MODE"
+
+// Push modes
+const (
+	PushModeSchedule = "schedule" // 每位用戶一個 EventBridge 排程直接 invoke language-vocabulary（預設）
+	PushModeFanout   = "fanout"   // 每小時一個排程 invoke language-dispatcher，依 pushTime 查出用戶後送入 SQS
+)
+
+// LoadPushMode reads PUSH_MODE; empty means PushModeSchedule
+func LoadPushMode(getenv func(string) string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(getenv(PushModeEnv))); mode {
+	case "", PushModeSchedule:
+		return PushModeSchedule, nil
+	case PushModeFanout:
+		return PushModeFanout, nil
+	default:
+		return "", fmt.Errorf("%s must be %s or %s, got %q", PushModeEnv, PushModeSchedule, PushModeFanout, mode)
+	}
+}
+
+// ScheduleGroupName 所有 EventBridge Scheduler 排程所在的群組
+const ScheduleGroupName = "default"
+
+// FanoutTimezone fan-out 模式的 dispatcher 只依此時區查詢推播時間，其他時區的用戶仍使用個人排程
+const FanoutTimezone = models.DefaultTimezone
+
+// FanoutPushTime 回傳 now 在用戶時區的整點 "HH:00"，dispatcher 以此查詢 PushTimeIndex。
+// fan-out 模式以小時為單位，推播時間不是整點的用戶不會被查到（設定選項目前都是整點）
+func FanoutPushTime(now time.Time, timezone string) (string, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+	return now.In(loc).Format("15") + ":00", nil
+}
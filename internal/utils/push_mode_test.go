@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadPushMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: PushModeSchedule},
+		{value: "schedule", want: PushModeSchedule},
+		{value: " FANOUT ", want: PushModeFanout},
+		{value: "sqs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := LoadPushMode(func(string) string { return tt.value })
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("LoadPushMode(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("LoadPushMode(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFanoutPushTime(t *testing.T) {
+	// 23:05 UTC 是台北隔天 07:05
+	now := time.Date(2025, 6, 1, 23, 5, 0, 0, time.UTC)
+	got, err := FanoutPushTime(now, "Asia/Taipei")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "07:00" {
+		t.Errorf("FanoutPushTime = %q, want 07:00", got)
+	}
+
+	if _, err := FanoutPushTime(now, "Mars/Olympus"); err == nil {
+		t.Error("expected an error for an unknown timezone")
+	}
+}
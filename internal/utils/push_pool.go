@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// ReminderConcurrencyEnv 每晚回顧同時推播的用戶數
+	ReminderConcurrencyEnv = "REMINDER_CONCURRENCY"
+	// DefaultReminderConcurrency 未設定時的同時推播數，遠低於 LINE push API 每秒 2,000 次的上限，也不會對 DynamoDB 造成尖峰
+	DefaultReminderConcurrency = 10
+	maxReminderConcurrency     = 100
+)
+
+// maxSummaryFailures 摘要 log 最多列出幾個失敗的推播對象，完整的錯誤已在各自的 log 中
+const maxSummaryFailures = 20
+
+// LoadReminderConcurrency reads REMINDER_CONCURRENCY, defaulting to DefaultReminderConcurrency
+func LoadReminderConcurrency(getenv func(string) string) (int, error) {
+	value := strings.TrimSpace(getenv(ReminderConcurrencyEnv))
+	if value == "" {
+		return DefaultReminderConcurrency, nil
+	}
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 || concurrency > maxReminderConcurrency {
+		return 0, fmt.Errorf("%s must be an integer between 1 and %d, got %q", ReminderConcurrencyEnv, maxReminderConcurrency, value)
+	}
+	return concurrency, nil
+}
+
+// PushSummary aggregates a concurrent push job: how many targets were pushed or skipped, and the error of each
+// target that failed. Safe for concurrent use
+type PushSummary struct {
+	mu       sync.Mutex
+	Sent     int
+	Skipped  int
+	Failures map[string]error // 推播對象（userId 或 groupId）→ 錯誤
+}
+
+func (s *PushSummary) record(target string, sent bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err != nil:
+		s.Failures[target] = err
+	case sent:
+		s.Sent++
+	default:
+		s.Skipped++
+	}
+}
+
+// Fields returns the summary as log fields, listing at most maxSummaryFailures failed targets
+func (s *PushSummary) Fields() logrus.Fields {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failed := make([]string, 0, len(s.Failures))
+	for target := range s.Failures {
+		failed = append(failed, target)
+	}
+	sort.Strings(failed)
+	if len(failed) > maxSummaryFailures {
+		failed = failed[:maxSummaryFailures]
+	}
+	return logrus.Fields{
+		"sent":          s.Sent,
+		"skipped":       s.Skipped,
+		"failed":        len(s.Failures),
+		"failedTargets": failed,
+	}
+}
+
+// PushConcurrently 以最多 concurrency 個 goroutine 對每個 item 執行 push，一個對象失敗不影響其他對象。
+// push 回傳 false 且沒有錯誤表示略過（例如沒有要回顧的單字）；target 回傳 item 的推播對象，用來彙整錯誤
+func PushConcurrently[T any](items []T, concurrency int, target func(T) string, push func(T) (bool, error)) *PushSummary {
+	summary := &PushSummary{Failures: map[string]error{}}
+
+	g := new(errgroup.Group)
+	g.SetLimit(concurrency)
+	for _, item := range items {
+		g.Go(func() error {
+			sent, err := push(item)
+			summary.record(target(item), sent, err)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return summary
+}
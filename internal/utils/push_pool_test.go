@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadReminderConcurrency(t *testing.T) {
+	env := func(value string) func(string) string {
+		return func(string) string { return value }
+	}
+
+	if concurrency, err := LoadReminderConcurrency(env("")); err != nil || concurrency != DefaultReminderConcurrency {
+		t.Errorf("Expected the default concurrency, got %d (err %v)", concurrency, err)
+	}
+	if concurrency, err := LoadReminderConcurrency(env(" 25 ")); err != nil || concurrency != 25 {
+		t.Errorf("Expected 25, got %d (err %v)", concurrency, err)
+	}
+	for _, value := range []string{"0", "-1", "1000", "ten"} {
+		if _, err := LoadReminderConcurrency(env(value)); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestPushConcurrently(t *testing.T) {
+	users := []string{"U1", "U2", "U3", "U4", "U5", "U6"}
+
+	var running, peak int32
+	summary := PushConcurrently(users, 2, func(userID string) string { return userID }, func(userID string) (bool, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		switch userID {
+		case "U2":
+			return false, errors.New("line 500")
+		case "U5":
+			return false, nil
+		}
+		return true, nil
+	})
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent pushes, got %d", peak)
+	}
+	if summary.Sent != 4 || summary.Skipped != 1 || len(summary.Failures) != 1 || summary.Failures["U2"] == nil {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if fields := summary.Fields(); fields["failed"] != 1 || len(fields["failedTargets"].([]string)) != 1 {
+		t.Errorf("Unexpected summary fields %v", fields)
+	}
+}
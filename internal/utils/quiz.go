@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"errors"
+	"language-assistant/internal/models"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	QuizQuestionCount = 10 // 每次測驗的題數
+	QuizOptionCount   = 4  // 每題的選項數
+
+	// MasteryCorrectStreak 測驗中連續答對幾次視為已熟練，已熟練的單字不再出現在每晚回顧；答錯時重新計算
+	MasteryCorrectStreak = 2
+)
+
+// QuizOptionLabels 選項代號，依序對應 QuizQuestion.Options
+var QuizOptionLabels = []string{"A", "B", "C", "D"}
+
+// ErrNotEnoughQuizWords is returned when the user has saved too few distinct words to build distractors
+var ErrNotEnoughQuizWords = errors.New("not enough saved words for a quiz")
+
+// BuildQuizQuestions 從用戶查過的單字產生選擇題，題型隨機為「單字→中文」或「中文→單字」。
+// 每個單字最多出一題，單字不足 QuizQuestionCount 個時題數會跟著減少
+func BuildQuizQuestions(vocabularies []models.UserVocabulary, rnd *rand.Rand) ([]models.QuizQuestion, error) {
+	// 同一個單字可能在不同天查過，以單字（不分大小寫）去重
+	seen := map[string]bool{}
+	var words []models.WordRecord
+	for _, vocabulary := range vocabularies {
+		for _, record := range vocabulary.Words {
+			key := strings.ToLower(strings.TrimSpace(record.Word))
+			if key == "" || strings.TrimSpace(record.Translation) == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			words = append(words, record)
+		}
+	}
+	if len(words) < QuizOptionCount {
+		return nil, ErrNotEnoughQuizWords
+	}
+
+	rnd.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+
+	count := QuizQuestionCount
+	if len(words) < count {
+		count = len(words)
+	}
+
+	questions := make([]models.QuizQuestion, 0, count)
+	for i := 0; i < count; i++ {
+		direction := models.QuizWordToMeaning
+		if rnd.Intn(2) == 1 {
+			direction = models.QuizMeaningToWord
+		}
+
+		options, answer, ok := quizOptions(words, i, direction, rnd)
+		if !ok {
+			continue
+		}
+		questions = append(questions, models.QuizQuestion{
+			Direction:    direction,
+			Word:         words[i].Word,
+			PartOfSpeech: words[i].PartOfSpeech,
+			Translation:  words[i].Translation,
+			Options:      options,
+			Answer:       answer,
+			Choice:       -1,
+		})
+	}
+	if len(questions) == 0 {
+		return nil, ErrNotEnoughQuizWords
+	}
+
+	return questions, nil
+}
+
+// quizOptions 以其他單字作為干擾選項，選項文字不重複；湊不滿 QuizOptionCount 個時回傳 false
+func quizOptions(words []models.WordRecord, target int, direction string, rnd *rand.Rand) ([]string, int, bool) {
+	optionText := func(record models.WordRecord) string {
+		if direction == models.QuizWordToMeaning {
+			return record.Translation
+		}
+		return record.Word
+	}
+
+	correct := optionText(words[target])
+	options := []string{correct}
+	used := map[string]bool{strings.ToLower(correct): true}
+	for _, i := range rnd.Perm(len(words)) {
+		if len(options) == QuizOptionCount {
+			break
+		}
+		text := optionText(words[i])
+		if i == target || used[strings.ToLower(text)] {
+			continue
+		}
+		used[strings.ToLower(text)] = true
+		options = append(options, text)
+	}
+	if len(options) < QuizOptionCount {
+		return nil, 0, false
+	}
+
+	rnd.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	for i, option := range options {
+		if option == correct {
+			return options, i, true
+		}
+	}
+	return nil, 0, false
+}
+
+// AnswerQuiz 記錄目前題目的作答並前進到下一題，回傳是否答對。
+// 題號與目前題目不符（重複點擊或點到舊題目的按鈕）時回傳錯誤
+func AnswerQuiz(session *models.QuizSession, question, choice int) (bool, error) {
+	if session.Status != models.QuizStatusActive {
+		return false, errors.New("quiz is not active")
+	}
+	if question != session.Current || question >= len(session.Questions) {
+		return false, errors.New("question already answered")
+	}
+	if choice < 0 || choice >= len(session.Questions[question].Options) {
+		return false, errors.New("invalid choice")
+	}
+
+	q := &session.Questions[question]
+	q.Choice = choice
+	correct := choice == q.Answer
+	if correct {
+		session.Correct++
+	}
+	session.Current++
+	if session.Current == len(session.Questions) {
+		session.Status = models.QuizStatusCompleted
+	}
+	return correct, nil
+}
+
+// QuizAnswerPostbackData 產生測驗選項按鈕的 postback data
+func QuizAnswerPostbackData(sessionID string, question, choice int) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackQuizAnswer)
+	values.Set("session", sessionID)
+	values.Set("q", strconv.Itoa(question))
+	values.Set("a", strconv.Itoa(choice))
+	return values.Encode()
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"math/rand"
+	"testing"
+)
+
+func TestBuildQuizQuestions(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	few := []models.UserVocabulary{{Words: []models.WordRecord{
+		{Word: "apple", Translation: "蘋果"},
+		{Word: "Apple", Translation: "蘋果"}, // 重複的單字只算一次
+		{Word: "banana", Translation: "香蕉"},
+		{Word: "cherry", Translation: "櫻桃"},
+	}}}
+	if _, err := BuildQuizQuestions(few, rnd); err != ErrNotEnoughQuizWords {
+		t.Errorf("Expected ErrNotEnoughQuizWords, got %v", err)
+	}
+
+	var records []models.WordRecord
+	for _, w := range []struct{ word, meaning string }{
+		{"abandon", "放棄"}, {"benefit", "好處"}, {"candid", "坦率的"}, {"diligent", "勤奮的"},
+		{"eager", "渴望的"}, {"fragile", "易碎的"}, {"genuine", "真正的"}, {"hazard", "危險"},
+		{"impose", "強加"}, {"justify", "證明合理"}, {"keen", "熱衷的"}, {"lavish", "奢華的"},
+	} {
+		records = append(records, models.WordRecord{Word: w.word, Translation: w.meaning})
+	}
+	questions, err := BuildQuizQuestions([]models.UserVocabulary{{Words: records}}, rnd)
+	if err != nil {
+		t.Fatalf("BuildQuizQuestions() error = %v", err)
+	}
+	if len(questions) != QuizQuestionCount {
+		t.Fatalf("Expected %d questions, got %d", QuizQuestionCount, len(questions))
+	}
+
+	for _, q := range questions {
+		if len(q.Options) != QuizOptionCount || q.Choice != -1 {
+			t.Errorf("Unexpected question %+v", q)
+		}
+		want := q.Translation
+		if q.Direction == models.QuizMeaningToWord {
+			want = q.Word
+		}
+		if q.Options[q.Answer] != want {
+			t.Errorf("Answer %q does not match %q for %s question", q.Options[q.Answer], want, q.Direction)
+		}
+	}
+}
+
+func TestAnswerQuiz(t *testing.T) {
+	session := &models.QuizSession{
+		Status: models.QuizStatusActive,
+		Questions: []models.QuizQuestion{
+			{Options: []string{"a", "b", "c", "d"}, Answer: 1, Choice: -1},
+			{Options: []string{"a", "b", "c", "d"}, Answer: 2, Choice: -1},
+		},
+	}
+
+	if correct, err := AnswerQuiz(session, 0, 1); err != nil || !correct {
+		t.Errorf("Expected correct answer, got %v (err %v)", correct, err)
+	}
+	if _, err := AnswerQuiz(session, 0, 1); err == nil {
+		t.Errorf("Expected error when answering the same question twice")
+	}
+	if correct, err := AnswerQuiz(session, 1, 0); err != nil || correct {
+		t.Errorf("Expected wrong answer, got %v (err %v)", correct, err)
+	}
+	if session.Status != models.QuizStatusCompleted || session.Correct != 1 {
+		t.Errorf("Expected completed session with 1 correct answer, got %+v", session)
+	}
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageFeatureTranslation 翻譯類請求（查詢翻譯、長文摘要、文法修正）共用的每日額度
+const UsageFeatureTranslation = "translation"
+
+// 每日計數保留到隔天以後再由 TTL 刪除，避免時區差異讓當天的計數提早消失
+const usageCounterRetention = 48 * time.Hour
+
+// PlanQuota is what a plan may use per day; 0 means unlimited
+type PlanQuota struct {
+	DailyTranslations int
+}
+
+// 各方案的預設額度，方案的限制只在這裡設定
+var defaultPlanQuotas = map[models.Plan]PlanQuota{
+	models.PlanFree:      {DailyTranslations: 30},
+	models.PlanSupporter: {}, // 不限次數
+}
+
+// LoadPlanQuotas reads PLAN_<PLAN>_DAILY_TRANSLATIONS (e.g. PLAN_FREE_DAILY_TRANSLATIONS, "0" for unlimited) for
+// every plan, falling back to the defaults when unset
+func LoadPlanQuotas(getenv func(string) string) (map[models.Plan]PlanQuota, error) {
+	quotas := make(map[models.Plan]PlanQuota, len(defaultPlanQuotas))
+	for plan, defaults := range defaultPlanQuotas {
+		quota := defaults
+		name := "PLAN_" + strings.ToUpper(string(plan)) + "_DAILY_TRANSLATIONS"
+		if value := getenv(name); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+			}
+			quota.DailyTranslations = limit
+		}
+		quotas[plan] = quota
+	}
+	return quotas, nil
+}
+
+// QuotaDecision is the outcome of one quota check
+type QuotaDecision struct {
+	Allowed   bool
+	Plan      models.Plan
+	Used      int // 包含這次在內今天已使用的次數，不限次數的方案不計數時為 0
+	Limit     int // 0 表示不限次數
+	Remaining int
+}
+
+// UsageLimiter enforces the per-plan daily quotas. Usage is counted per day in the user's timezone so the quota
+// resets at the user's midnight. Safe for concurrent use
+type UsageLimiter struct {
+	repo   UsageRepository
+	quotas map[models.Plan]PlanQuota
+	now    func() time.Time
+}
+
+func NewUsageLimiter(repo UsageRepository, quotas map[models.Plan]PlanQuota) *UsageLimiter {
+	return &UsageLimiter{
+		repo:   repo,
+		quotas: quotas,
+		now:    time.Now,
+	}
+}
+
+// AllowTranslation 計入一次翻譯類請求並回傳是否還在額度內；不限次數的方案不寫入計數。
+// 未知的方案以免費方案的額度計算
+func (l *UsageLimiter) AllowTranslation(userConfig models.UserConfig) (QuotaDecision, error) {
+	plan := userConfig.EffectivePlan()
+	quota, ok := l.quotas[plan]
+	if !ok {
+		quota = l.quotas[models.PlanFree]
+	}
+	decision := QuotaDecision{Allowed: true, Plan: plan, Limit: quota.DailyTranslations}
+	if quota.DailyTranslations == 0 {
+		return decision, nil
+	}
+
+	now := l.now()
+	used, err := l.repo.IncrementDailyUsage(userConfig.UserID, UsageFeatureTranslation, StreakDate(now, userConfig.Timezone), now.Add(usageCounterRetention))
+	if err != nil {
+		return decision, fmt.Errorf("failed to count translation usage: %w", err)
+	}
+	decision.Used = used
+	decision.Allowed = used <= quota.DailyTranslations
+	decision.Remaining = max(quota.DailyTranslations-used, 0)
+	return decision, nil
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+// countingUsage 記錄每個 key 的計數與最後一次寫入的日期
+type countingUsage struct {
+	UsageRepository
+	counts map[string]int
+	date   string
+}
+
+func (c *countingUsage) IncrementDailyUsage(userID, feature, date string, expiresAt time.Time) (int, error) {
+	c.date = date
+	key := userID + "#" + feature + "#" + date
+	c.counts[key]++
+	return c.counts[key], nil
+}
+
+func TestLoadPlanQuotas(t *testing.T) {
+	quotas, err := LoadPlanQuotas(func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if quotas[models.PlanFree].DailyTranslations != 30 || quotas[models.PlanSupporter].DailyTranslations != 0 {
+		t.Errorf("Unexpected default quotas: %+v", quotas)
+	}
+
+	quotas, err = LoadPlanQuotas(func(name string) string {
+		if name == "PLAN_FREE_DAILY_TRANSLATIONS" {
+			return "5"
+		}
+		return ""
+	})
+	if err != nil || quotas[models.PlanFree].DailyTranslations != 5 {
+		t.Errorf("Expected free plan override to 5, got %+v (%v)", quotas, err)
+	}
+
+	if _, err := LoadPlanQuotas(func(string) string { return "-1" }); err == nil {
+		t.Error("Expected an error for a negative quota")
+	}
+}
+
+func TestUsageLimiterAllowTranslation(t *testing.T) {
+	usage := &countingUsage{counts: map[string]int{}}
+	limiter := NewUsageLimiter(usage, map[models.Plan]PlanQuota{
+		models.PlanFree:      {DailyTranslations: 2},
+		models.PlanSupporter: {},
+	})
+	// 台北時間 1 日早上 7 點，UTC 還是前一天
+	limiter.now = func() time.Time { return time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC) }
+
+	free := models.UserConfig{UserID: "U1", Timezone: "Asia/Taipei"}
+	for i, wantAllowed := range []bool{true, true, false} {
+		decision, err := limiter.AllowTranslation(free)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if decision.Allowed != wantAllowed || decision.Plan != models.PlanFree || decision.Limit != 2 {
+			t.Errorf("Request %d: unexpected decision %+v", i+1, decision)
+		}
+	}
+	if usage.date != "2024-03-01" {
+		t.Errorf("Expected usage counted on the user's date 2024-03-01, got %s", usage.date)
+	}
+
+	supporter := models.UserConfig{UserID: "U2", Plan: models.PlanSupporter}
+	for i := 0; i < 5; i++ {
+		if decision, _ := limiter.AllowTranslation(supporter); !decision.Allowed {
+			t.Fatalf("Supporter plan should be unlimited, got %+v", decision)
+		}
+	}
+	if len(usage.counts) != 1 {
+		t.Errorf("Unlimited plans should not be counted, got %v", usage.counts)
+	}
+}
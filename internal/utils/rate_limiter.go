@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TranslationRateLimitEnv 每位用戶每小時最多幾次翻譯類請求（翻譯、長文摘要、文法修正），"0" 表示不限制
+	TranslationRateLimitEnv = "TRANSLATION_RATE_LIMIT_PER_HOUR"
+
+	defaultTranslationRateLimit = 60
+
+	// 同時有其他請求更新 bucket 時重新讀取再試的次數
+	rateLimitMaxAttempts = 3
+)
+
+// LoadTranslationRateLimit reads TRANSLATION_RATE_LIMIT_PER_HOUR (default 60, "0" disables the limit)
+func LoadTranslationRateLimit(getenv func(string) string) (int, error) {
+	value := strings.TrimSpace(getenv(TranslationRateLimitEnv))
+	if value == "" {
+		return defaultTranslationRateLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", TranslationRateLimitEnv, value)
+	}
+	return limit, nil
+}
+
+// RateLimitDecision is the outcome of one rate limit check
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int           // 每小時的上限，0 表示不限制
+	RetryAfter time.Duration // 被限制時，累積到下一個 token 需要的時間
+}
+
+// RateLimiter caps how often a user may call a feature with a token bucket per user in DynamoDB: the bucket holds
+// up to limit tokens and refills limit tokens per hour, so short bursts are allowed but the hourly rate is capped.
+// Buckets are updated with conditional writes, so concurrent requests from the same user cannot spend the same
+// token. Unlike UsageLimiter it applies to every plan, to stop abuse rather than to meter usage. Safe for concurrent use
+type RateLimiter struct {
+	repo  RateLimiterRepository
+	limit int
+	now   func() time.Time
+}
+
+func NewRateLimiter(repo RateLimiterRepository, limitPerHour int) *RateLimiter {
+	return &RateLimiter{
+		repo:  repo,
+		limit: limitPerHour,
+		now:   time.Now,
+	}
+}
+
+// AllowTranslation 從用戶翻譯類請求的 bucket 取出一個 token，回傳是否允許這次請求
+func (l *RateLimiter) AllowTranslation(userID string) (RateLimitDecision, error) {
+	return l.allow(userID, UsageFeatureTranslation)
+}
+
+func (l *RateLimiter) allow(userID, feature string) (RateLimitDecision, error) {
+	decision := RateLimitDecision{Allowed: true, Limit: l.limit}
+	if l.limit == 0 {
+		return decision, nil
+	}
+
+	capacity := float64(l.limit)
+	refillPerMs := capacity / float64(time.Hour.Milliseconds())
+	for attempt := 1; attempt <= rateLimitMaxAttempts; attempt++ {
+		bucket, err := l.repo.GetBucket(userID, feature)
+		if err != nil {
+			return decision, fmt.Errorf("failed to load rate limit bucket: %w", err)
+		}
+
+		now := l.now().UnixMilli()
+		next := models.RateLimitBucket{Tokens: capacity, UpdatedAt: now, Version: 1}
+		if bucket != nil {
+			elapsed := max(now-bucket.UpdatedAt, 0)
+			next.Tokens = math.Min(capacity, bucket.Tokens+float64(elapsed)*refillPerMs)
+			next.UpdatedAt = max(now, bucket.UpdatedAt)
+			next.Version = bucket.Version + 1
+		}
+
+		if next.Tokens < 1 {
+			decision.Allowed = false
+			decision.RetryAfter = time.Duration(math.Ceil((1-next.Tokens)/refillPerMs)) * time.Millisecond
+			return decision, nil
+		}
+		next.Tokens--
+
+		// 閒置一小時後 bucket 已補滿，之後由 TTL 刪除
+		saved, err := l.repo.SaveBucket(userID, feature, next, time.UnixMilli(next.UpdatedAt).Add(2*time.Hour))
+		if err != nil {
+			return decision, fmt.Errorf("failed to save rate limit bucket: %w", err)
+		}
+		if saved {
+			return decision, nil
+		}
+	}
+	return decision, fmt.Errorf("rate limit bucket for %s kept changing after %d attempts", feature, rateLimitMaxAttempts)
+}
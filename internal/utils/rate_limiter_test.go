@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+// memoryBuckets 以 version 模擬 DynamoDB 的條件寫入；conflicts 次數內的寫入會被當成被其他請求搶先
+type memoryBuckets struct {
+	buckets   map[string]models.RateLimitBucket
+	conflicts int
+}
+
+func (m *memoryBuckets) GetBucket(userID, feature string) (*models.RateLimitBucket, error) {
+	bucket, ok := m.buckets[userID+"#"+feature]
+	if !ok {
+		return nil, nil
+	}
+	return &bucket, nil
+}
+
+func (m *memoryBuckets) SaveBucket(userID, feature string, bucket models.RateLimitBucket, expiresAt time.Time) (bool, error) {
+	if m.conflicts > 0 {
+		m.conflicts--
+		return false, nil
+	}
+	if m.buckets[userID+"#"+feature].Version != bucket.Version-1 {
+		return false, nil
+	}
+	m.buckets[userID+"#"+feature] = bucket
+	return true, nil
+}
+
+func TestLoadTranslationRateLimit(t *testing.T) {
+	if limit, err := LoadTranslationRateLimit(func(string) string { return "" }); err != nil || limit != 60 {
+		t.Errorf("Expected the default of 60, got %d (%v)", limit, err)
+	}
+	if _, err := LoadTranslationRateLimit(func(string) string { return "-1" }); err == nil {
+		t.Error("Expected an error for a negative limit")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	repo := &memoryBuckets{buckets: map[string]models.RateLimitBucket{}}
+	limiter := NewRateLimiter(repo, 3)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	// bucket 一開始是滿的，可以連續使用 3 次
+	for i := 1; i <= 3; i++ {
+		if decision, err := limiter.AllowTranslation("U1"); err != nil || !decision.Allowed {
+			t.Fatalf("Expected request %d to be allowed, got %+v (err %v)", i, decision, err)
+		}
+	}
+	decision, err := limiter.AllowTranslation("U1")
+	if err != nil || decision.Allowed || decision.RetryAfter != 20*time.Minute {
+		t.Fatalf("Expected the 4th request to wait 20 minutes for the next token, got %+v (err %v)", decision, err)
+	}
+	if decision, _ := limiter.AllowTranslation("U2"); !decision.Allowed {
+		t.Error("Expected other users to have their own bucket")
+	}
+
+	// 每 20 分鐘補充一個 token
+	now = now.Add(20 * time.Minute)
+	if decision, _ := limiter.AllowTranslation("U1"); !decision.Allowed {
+		t.Errorf("Expected a refilled token after 20 minutes, got %+v", decision)
+	}
+	if decision, _ := limiter.AllowTranslation("U1"); decision.Allowed {
+		t.Error("Expected the refilled token to be spent")
+	}
+
+	// 同時有其他請求寫入時重新讀取再試，一直衝突則回傳錯誤
+	now = now.Add(time.Hour)
+	repo.conflicts = 2
+	if decision, err := limiter.AllowTranslation("U1"); err != nil || !decision.Allowed {
+		t.Errorf("Expected the request to succeed after retrying, got %+v (err %v)", decision, err)
+	}
+	repo.conflicts = rateLimitMaxAttempts
+	if _, err := limiter.AllowTranslation("U1"); err == nil {
+		t.Error("Expected an error when every write conflicts")
+	}
+
+	if decision, _ := NewRateLimiter(repo, 0).AllowTranslation("U1"); !decision.Allowed {
+		t.Error("Expected a zero limit to disable rate limiting")
+	}
+}
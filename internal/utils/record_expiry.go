@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RecordRetentionEnv 每日紀錄的保存天數，"0" 表示不過期
+	RecordRetentionEnv = "RECORD_RETENTION_DAYS"
+	// DefaultRecordRetentionDays 未設定 RecordRetentionEnv 時的保存天數
+	DefaultRecordRetentionDays = 180
+)
+
+// RecordExpiry decides the DynamoDB TTL (expiresAt) written with per-day user records: vocabulary days, quiz
+// sessions and review sessions. Records expire Retention after their last write; the zero value never expires
+type RecordExpiry struct {
+	Retention time.Duration
+}
+
+// LoadRecordExpiry reads RecordRetentionEnv (whole days), falling back to DefaultRecordRetentionDays when unset
+func LoadRecordExpiry(getenv func(string) string) (RecordExpiry, error) {
+	value := strings.TrimSpace(getenv(RecordRetentionEnv))
+	if value == "" {
+		return RecordExpiry{Retention: DefaultRecordRetentionDays * 24 * time.Hour}, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return RecordExpiry{}, fmt.Errorf("%s must be a non-negative number of days, got %q", RecordRetentionEnv, value)
+	}
+	return RecordExpiry{Retention: time.Duration(days) * 24 * time.Hour}, nil
+}
+
+// ExpiresAt 回傳在 writtenAt 寫入的紀錄何時過期；不過期時回傳零值，repository 收到零值時不設定 TTL
+func (e RecordExpiry) ExpiresAt(writtenAt time.Time) time.Time {
+	if e.Retention <= 0 {
+		return time.Time{}
+	}
+	return writtenAt.Add(e.Retention)
+}
+
+// ExpiresAtUnix 同 ExpiresAt，回傳 TTL 屬性使用的 epoch 秒；不過期時回傳 0（搭配 omitempty 不寫入屬性）
+func (e RecordExpiry) ExpiresAtUnix(writtenAt time.Time) int64 {
+	expiresAt := e.ExpiresAt(writtenAt)
+	if expiresAt.IsZero() {
+		return 0
+	}
+	return expiresAt.Unix()
+}
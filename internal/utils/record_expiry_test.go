@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadRecordExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"Default", "", DefaultRecordRetentionDays * 24 * time.Hour, false},
+		{"Custom days", "30", 30 * 24 * time.Hour, false},
+		{"Disabled", "0", 0, false},
+		{"Negative", "-1", 0, true},
+		{"Not a number", "180d", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiry, err := LoadRecordExpiry(func(string) string { return tt.value })
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if expiry.Retention != tt.want {
+				t.Errorf("Expected retention %v, got %v", tt.want, expiry.Retention)
+			}
+		})
+	}
+}
+
+func TestRecordExpiryExpiresAt(t *testing.T) {
+	writtenAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	expiry := RecordExpiry{Retention: 180 * 24 * time.Hour}
+	if got := expiry.ExpiresAt(writtenAt); !got.Equal(time.Date(2024, 8, 28, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected expiry 180 days after the write, got %v", got)
+	}
+	if got := expiry.ExpiresAtUnix(writtenAt); got != writtenAt.Add(expiry.Retention).Unix() {
+		t.Errorf("Expected epoch seconds of the expiry, got %d", got)
+	}
+
+	var never RecordExpiry
+	if !never.ExpiresAt(writtenAt).IsZero() || never.ExpiresAtUnix(writtenAt) != 0 {
+		t.Errorf("Expected the zero RecordExpiry to never expire")
+	}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import "language-assistant/internal/models"
+
+// PushedWordsOn 回傳推播紀錄中成功送達的單字（正規化後）
+func PushedWordsOn(logs []models.PushLog) map[string]bool {
+	words := map[string]bool{}
+	for _, log := range logs {
+		if log.Status != models.PushStatusDelivered {
+			continue
+		}
+		for _, word := range log.Words {
+			words[NormalizeHistoryWord(word)] = true
+		}
+	}
+	return words
+}
+
+// ReminderWords 挑出每晚回顧要複習的單字：略過 excluded 中的單字（當天已推播或已熟練），
+// 同一天重複查詢的單字只保留第一筆
+func ReminderWords(words []models.WordRecord, excluded map[string]bool) []models.WordRecord {
+	picked := map[string]bool{}
+	var result []models.WordRecord
+	for _, word := range words {
+		key := NormalizeHistoryWord(word.Word)
+		if key == "" || excluded[key] || picked[key] {
+			continue
+		}
+		picked[key] = true
+		result = append(result, word)
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestReminderWords(t *testing.T) {
+	excluded := PushedWordsOn([]models.PushLog{
+		{Status: models.PushStatusDelivered, Words: []string{"Resilient"}},
+		{Status: models.PushStatusFailed, Words: []string{"allocate"}},
+	})
+	excluded["meticulous"] = true // 已熟練
+
+	words := ReminderWords([]models.WordRecord{
+		{Word: "resilient"},
+		{Word: "meticulous"},
+		{Word: "allocate"},
+		{Word: "Allocate"},
+		{Word: "diligent"},
+	}, excluded)
+
+	if len(words) != 2 || words[0].Word != "allocate" || words[1].Word != "diligent" {
+		t.Errorf("Expected [allocate diligent], got %+v", words)
+	}
+}
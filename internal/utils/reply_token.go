@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"sync"
+	"time"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ReplyTokenValidity LINE reply token 的有效時間，從收到 webhook 起算
+	ReplyTokenValidity = time.Minute
+	// 保留給 reply API 本身來回的時間，剩餘時間不足時直接改用 push
+	replyTokenSafetyMargin = 5 * time.Second
+)
+
+type trackedReplyToken struct {
+	to         string
+	receivedAt time.Time
+}
+
+// ReplyTokenGuard wraps a LinebotAPI and sends replies whose token has (nearly) expired as push messages to the
+// event source instead. Slow OpenAI calls can push a reply past ReplyTokenValidity; trying the token anyway wastes
+// an API call, logs a confusing "Failed to reply" and leaves the user without an answer. Tokens that were never
+// tracked are replied as usual. Safe for concurrent use
+type ReplyTokenGuard struct {
+	LinebotAPI
+	logger *logrus.Entry
+	now    func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]trackedReplyToken
+}
+
+func NewReplyTokenGuard(logger *logrus.Entry, client LinebotAPI) *ReplyTokenGuard {
+	return &ReplyTokenGuard{
+		LinebotAPI: client,
+		logger:     logger,
+		now:        time.Now,
+		tokens:     map[string]trackedReplyToken{},
+	}
+}
+
+// Track records when the webhook carrying replyToken was received and where to push once it expires
+// (ReplyTarget of the event source)
+func (g *ReplyTokenGuard) Track(replyToken, to string, receivedAt time.Time) {
+	if replyToken == "" || to == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.tokens[replyToken] = trackedReplyToken{to: to, receivedAt: receivedAt}
+}
+
+// Forget drops a token once its event is handled
+func (g *ReplyTokenGuard) Forget(replyToken string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.tokens, replyToken)
+}
+
+// expiredTarget 回傳 token 已過期（或即將過期）時改推播的對象
+func (g *ReplyTokenGuard) expiredTarget(replyToken string) (string, bool) {
+	g.mu.Lock()
+	tracked, ok := g.tokens[replyToken]
+	g.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	elapsed := g.now().Sub(tracked.receivedAt)
+	if elapsed < ReplyTokenValidity-replyTokenSafetyMargin {
+		return "", false
+	}
+	g.logger.WithField("elapsed", elapsed.Round(time.Millisecond).String()).Info("Reply token expired, pushing instead")
+	return tracked.to, true
+}
+
+func (g *ReplyTokenGuard) ReplyMessage(replyToken string, message string) error {
+	if to, expired := g.expiredTarget(replyToken); expired {
+		return g.PushMessage(to, message)
+	}
+	return g.LinebotAPI.ReplyMessage(replyToken, message)
+}
+
+func (g *ReplyTokenGuard) ReplyMessageWithMultiple(replyToken string, messages ...linebot.SendingMessage) error {
+	if to, expired := g.expiredTarget(replyToken); expired {
+		return g.PushMessages(to, messages...)
+	}
+	return g.LinebotAPI.ReplyMessageWithMultiple(replyToken, messages...)
+}
+
+// ReplyTarget 回傳可推播給事件來源的 ID：群組與聊天室推播到群組／聊天室，其他推播給用戶
+func ReplyTarget(source *linebot.EventSource) string {
+	if source == nil {
+		return ""
+	}
+	switch {
+	case source.GroupID != "":
+		return source.GroupID
+	case source.RoomID != "":
+		return source.RoomID
+	default:
+		return source.UserID
+	}
+}
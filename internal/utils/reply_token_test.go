@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+	"github.com/sirupsen/logrus"
+)
+
+// recordingLinebot 記錄 reply 與 push 的對象，其他方法未實作
+type recordingLinebot struct {
+	LinebotAPI
+	replies []string
+	pushes  []string
+}
+
+func (r *recordingLinebot) ReplyMessage(replyToken string, message string) error {
+	r.replies = append(r.replies, replyToken)
+	return nil
+}
+
+func (r *recordingLinebot) ReplyMessageWithMultiple(replyToken string, messages ...linebot.SendingMessage) error {
+	r.replies = append(r.replies, replyToken)
+	return nil
+}
+
+func (r *recordingLinebot) PushMessage(to string, message string) error {
+	r.pushes = append(r.pushes, to)
+	return nil
+}
+
+func (r *recordingLinebot) PushMessages(to string, messages ...linebot.SendingMessage) error {
+	r.pushes = append(r.pushes, to)
+	return nil
+}
+
+func TestReplyTokenGuard(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	client := &recordingLinebot{}
+	guard := NewReplyTokenGuard(logrus.NewEntry(logger), client)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+
+	guard.Track("fresh", "U1", now.Add(-10*time.Second))
+	guard.Track("stale", "C1", now.Add(-57*time.Second))
+
+	guard.ReplyMessage("fresh", "hi")
+	guard.ReplyMessageWithMultiple("stale", linebot.NewTextMessage("hi"))
+	guard.ReplyMessage("untracked", "hi")
+	guard.Forget("stale")
+	guard.ReplyMessage("stale", "hi")
+
+	if want := []string{"fresh", "untracked", "stale"}; len(client.replies) != 3 || client.replies[0] != want[0] || client.replies[1] != want[1] || client.replies[2] != want[2] {
+		t.Errorf("replies = %v, want %v", client.replies, want)
+	}
+	if len(client.pushes) != 1 || client.pushes[0] != "C1" {
+		t.Errorf("Expected the expired reply to be pushed to C1, got %v", client.pushes)
+	}
+}
+
+func TestReplyTarget(t *testing.T) {
+	tests := map[string]*linebot.EventSource{
+		"U1": {Type: linebot.EventSourceTypeUser, UserID: "U1"},
+		"C1": {Type: linebot.EventSourceTypeGroup, UserID: "U1", GroupID: "C1"},
+		"R1": {Type: linebot.EventSourceTypeRoom, UserID: "U1", RoomID: "R1"},
+		"":   nil,
+	}
+	for want, source := range tests {
+		if got := ReplyTarget(source); got != want {
+			t.Errorf("ReplyTarget(%+v) = %q, want %q", source, got, want)
+		}
+	}
+}
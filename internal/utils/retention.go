@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"time"
+)
+
+// WeekStart returns Monday 00:00 UTC of the week containing t
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC().Truncate(24 * time.Hour)
+	offset := (int(t.Weekday()) + 6) % 7 // Monday = 0
+	return t.AddDate(0, 0, -offset)
+}
+
+// ComputeCohortRetention 計算 cohort 週加入的用戶在之後每一週仍活躍的人數與比例。
+// activityByWeek[n] 為第 cohort+n 週的活躍紀錄，index 0 必須是 cohort 當週
+func ComputeCohortRetention(cohort string, activityByWeek [][]models.WeeklyActivity) models.CohortRetention {
+	retention := models.CohortRetention{
+		Cohort:   cohort,
+		Retained: make([]int, len(activityByWeek)),
+		Rates:    make([]float64, len(activityByWeek)),
+	}
+
+	for n, activities := range activityByWeek {
+		for _, activity := range activities {
+			if activity.CohortWeek == cohort {
+				retention.Retained[n]++
+			}
+		}
+	}
+
+	if len(activityByWeek) > 0 {
+		retention.Size = retention.Retained[0]
+	}
+	for n := range retention.Retained {
+		retention.Rates[n] = ratio(retention.Retained[n], retention.Size)
+	}
+
+	return retention
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		input    time.Time
+		expected string
+	}{
+		{time.Date(2025, 1, 8, 15, 0, 0, 0, time.UTC), "2025-01-06"},                        // Wednesday
+		{time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), "2025-01-06"},                         // Monday
+		{time.Date(2025, 1, 12, 23, 59, 0, 0, time.UTC), "2025-01-06"},                      // Sunday
+		{time.Date(2025, 1, 13, 7, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)), "2025-01-06"}, // 台灣週一早上仍屬 UTC 上一週
+	}
+
+	for _, tt := range tests {
+		if got := WeekStart(tt.input).Format("2006-01-02"); got != tt.expected {
+			t.Errorf("WeekStart(%v) = %s, expected %s", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestComputeCohortRetention(t *testing.T) {
+	activity := func(week, userID, cohort string) models.WeeklyActivity {
+		return models.WeeklyActivity{Week: week, UserID: userID, CohortWeek: cohort}
+	}
+
+	retention := ComputeCohortRetention("2025-01-06", [][]models.WeeklyActivity{
+		{
+			activity("2025-01-06", "user1", "2025-01-06"),
+			activity("2025-01-06", "user2", "2025-01-06"),
+			activity("2025-01-06", "user3", "2025-01-06"),
+			activity("2025-01-06", "user4", "2024-12-30"), // 其他 cohort 不計入
+		},
+		{
+			activity("2025-01-13", "user1", "2025-01-06"),
+			activity("2025-01-13", "user2", "2025-01-06"),
+		},
+		{},
+	})
+
+	if retention.Size != 3 {
+		t.Errorf("Expected cohort size 3, got %d", retention.Size)
+	}
+	expected := []int{3, 2, 0}
+	for n, count := range expected {
+		if retention.Retained[n] != count {
+			t.Errorf("Week +%d: expected %d retained, got %d", n, count, retention.Retained[n])
+		}
+	}
+	if retention.Rates[1] != 2.0/3 {
+		t.Errorf("Expected week +1 rate 2/3, got %f", retention.Rates[1])
+	}
+}
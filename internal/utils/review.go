@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"language-assistant/internal/messages"
+	"language-assistant/internal/models"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+)
+
+// MaxInteractiveReviewWords 互動回顧最多的單字數，超過的單字留到之後依複習優先順序再出現
+const MaxInteractiveReviewWords = 10
+
+// ReviewIntervals 各 Leitner box 距離下次複習的天數
+var ReviewIntervals = []int{1, 2, 4, 7, 15, 30}
+
+// NextReviewRecord applies a review response to the word's spaced-repetition state; record may be nil for a
+// word that has never been reviewed. today is YYYY-MM-DD in the user's timezone
+func NextReviewRecord(record *models.ReviewRecord, word, response, today string, now time.Time) models.ReviewRecord {
+	next := models.ReviewRecord{Word: NormalizeHistoryWord(word)}
+	if record != nil {
+		next = *record
+	}
+
+	if response == models.ReviewRemembered {
+		next.Remembered++
+		next.Box = min(next.Box+1, len(ReviewIntervals)-1)
+	} else {
+		next.Forgotten++
+		next.Box = 0
+	}
+
+	next.DueDate = today
+	if day, err := time.Parse("2006-01-02", today); err == nil {
+		next.DueDate = day.AddDate(0, 0, ReviewIntervals[next.Box]).Format("2006-01-02")
+	}
+	next.LastReviewedAt = now.UTC().Format(time.RFC3339)
+	return next
+}
+
+// PrioritizeReviewWords orders words for review: words due on or before today come first (lower boxes, i.e.
+// recently forgotten, before higher ones), then words never reviewed, then words not yet due. The order is
+// otherwise stable
+func PrioritizeReviewWords(words []models.WordRecord, records map[string]models.ReviewRecord, today string) []models.WordRecord {
+	rank := func(word models.WordRecord) (int, int) {
+		record, ok := records[NormalizeHistoryWord(word.Word)]
+		switch {
+		case !ok:
+			return 1, 0
+		case record.DueDate <= today:
+			return 0, record.Box
+		default:
+			return 2, record.Box
+		}
+	}
+
+	result := slices.Clone(words)
+	slices.SortStableFunc(result, func(a, b models.WordRecord) int {
+		aGroup, aBox := rank(a)
+		bGroup, bBox := rank(b)
+		if aGroup != bGroup {
+			return aGroup - bGroup
+		}
+		return aBox - bBox
+	})
+	return result
+}
+
+// NewReviewSession builds the interactive review for the given words, keeping at most MaxInteractiveReviewWords
+func NewReviewSession(userID, date string, words []models.WordRecord) models.ReviewSession {
+	items := make([]models.ReviewItem, 0, min(len(words), MaxInteractiveReviewWords))
+	for _, word := range words[:min(len(words), MaxInteractiveReviewWords)] {
+		items = append(items, models.ReviewItem{
+			Word:         word.Word,
+			PartOfSpeech: word.PartOfSpeech,
+			Translation:  word.Translation,
+			Sentence:     word.Sentence,
+		})
+	}
+	return models.ReviewSession{UserID: userID, Date: date, Items: items}
+}
+
+// AnswerReview records the response to the current word and advances the session; pressing a button of a
+// word that was already answered returns an error
+func AnswerReview(session *models.ReviewSession, index int, response string) error {
+	if session.Completed() {
+		return errors.New("review is already completed")
+	}
+	if index != session.Current {
+		return errors.New("word already answered")
+	}
+	if response != models.ReviewRemembered && response != models.ReviewForgotten {
+		return errors.New("invalid review response")
+	}
+
+	session.Items[index].Response = response
+	if response == models.ReviewRemembered {
+		session.Remembered++
+	}
+	session.Current++
+	return nil
+}
+
+// ReviewCardMessage 組出互動回顧目前單字的訊息，下方附上「我記得 / 忘記了」按鈕
+func ReviewCardMessage(session *models.ReviewSession) linebot.SendingMessage {
+	item := session.Items[session.Current]
+	text := messages.Render(messages.InteractiveReviewCard, messages.Data{
+		"Number":       session.Current + 1,
+		"Total":        len(session.Items),
+		"Word":         item.Word,
+		"PartOfSpeech": item.PartOfSpeech,
+	})
+	return linebot.NewTextMessage(text).WithQuickReplies(messages.ReviewResponseReplies(
+		ReviewResponsePostbackData(session.Date, session.Current, models.ReviewRemembered),
+		ReviewResponsePostbackData(session.Date, session.Current, models.ReviewForgotten),
+	))
+}
+
+// ReviewResponsePostbackData 產生互動回顧「我記得 / 忘記了」按鈕的 postback data
+func ReviewResponsePostbackData(date string, index int, response string) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackReviewResponse)
+	values.Set("date", date)
+	values.Set("i", strconv.Itoa(index))
+	values.Set("r", response)
+	return values.Encode()
+}
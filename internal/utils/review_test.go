@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+)
+
+func TestNextReviewRecord(t *testing.T) {
+	now := time.Date(2024, 5, 10, 13, 0, 0, 0, time.UTC)
+
+	first := NextReviewRecord(nil, "Abandon", models.ReviewRemembered, "2024-05-10", now)
+	if first.Word != "abandon" || first.Box != 1 || first.DueDate != "2024-05-12" || first.Remembered != 1 {
+		t.Errorf("Unexpected record after remembering a new word: %+v", first)
+	}
+
+	forgotten := NextReviewRecord(&first, "abandon", models.ReviewForgotten, "2024-05-12", now)
+	if forgotten.Box != 0 || forgotten.DueDate != "2024-05-13" || forgotten.Remembered != 1 || forgotten.Forgotten != 1 {
+		t.Errorf("Forgetting should reset the box: %+v", forgotten)
+	}
+
+	top := models.ReviewRecord{Word: "abandon", Box: len(ReviewIntervals) - 1}
+	if next := NextReviewRecord(&top, "abandon", models.ReviewRemembered, "2024-05-10", now); next.Box != len(ReviewIntervals)-1 {
+		t.Errorf("Box should stay at the last interval, got %d", next.Box)
+	}
+}
+
+func TestPrioritizeReviewWords(t *testing.T) {
+	words := []models.WordRecord{{Word: "new"}, {Word: "later"}, {Word: "easy"}, {Word: "forgot"}}
+	records := map[string]models.ReviewRecord{
+		"later":  {Word: "later", Box: 0, DueDate: "2024-05-11"},
+		"easy":   {Word: "easy", Box: 3, DueDate: "2024-05-01"},
+		"forgot": {Word: "forgot", Box: 0, DueDate: "2024-05-10"},
+	}
+
+	got := PrioritizeReviewWords(words, records, "2024-05-10")
+	want := []string{"forgot", "easy", "new", "later"}
+	for i, word := range got {
+		if word.Word != want[i] {
+			t.Fatalf("PrioritizeReviewWords() order = %v, want %v", got, want)
+		}
+	}
+	if words[0].Word != "new" {
+		t.Errorf("Input slice should not be reordered")
+	}
+}
+
+func TestAnswerReview(t *testing.T) {
+	words := make([]models.WordRecord, MaxInteractiveReviewWords+2)
+	for i := range words {
+		words[i] = models.WordRecord{Word: string(rune('a' + i))}
+	}
+	session := NewReviewSession("user-1", "2024-05-10", words)
+	if len(session.Items) != MaxInteractiveReviewWords {
+		t.Fatalf("Expected %d items, got %d", MaxInteractiveReviewWords, len(session.Items))
+	}
+
+	if err := AnswerReview(&session, 1, models.ReviewRemembered); err == nil {
+		t.Errorf("Expected an error when answering a later word")
+	}
+	if err := AnswerReview(&session, 0, "maybe"); err == nil {
+		t.Errorf("Expected an error for an unknown response")
+	}
+	for i := range session.Items {
+		response := models.ReviewForgotten
+		if i%2 == 0 {
+			response = models.ReviewRemembered
+		}
+		if err := AnswerReview(&session, i, response); err != nil {
+			t.Fatalf("AnswerReview(%d) error = %v", i, err)
+		}
+	}
+	if !session.Completed() || session.Remembered != MaxInteractiveReviewWords/2 {
+		t.Errorf("Unexpected session after answering every word: %+v", session)
+	}
+	if err := AnswerReview(&session, 0, models.ReviewRemembered); err == nil {
+		t.Errorf("Expected an error once the review is completed")
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ObjectStorageAPI defines the object storage operations needed for generated media
+type ObjectStorageAPI interface {
+	PutObject(key string, body []byte, contentType string) error
+	PresignGetObject(key string, expiry time.Duration) (string, error)
+	ObjectExists(key string) (bool, error)
+}
+
+// S3Client stores objects in a single bucket; safe for concurrent use
+type S3Client struct {
+	client    *s3.Client
+	presigner *s3.PresignClient
+	bucket    string
+}
+
+func NewS3Client(cfg aws.Config, bucket string) ObjectStorageAPI {
+	client := s3.NewFromConfig(cfg)
+	return &S3Client{
+		client:    client,
+		presigner: s3.NewPresignClient(client),
+		bucket:    bucket,
+	}
+}
+
+func (c *S3Client) PutObject(key string, body []byte, contentType string) error {
+	_, err := c.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c *S3Client) PresignGetObject(key string, expiry time.Duration) (string, error) {
+	req, err := c.presigner.PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign object %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+func (c *S3Client) ObjectExists(key string) (bool, error) {
+	_, err := c.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to head object %s: %w", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	// ScheduleNamePrefix is shared by every per-user daily push schedule
+	ScheduleNamePrefix = "daily-vocab-"
+	// maxScheduleNameLength is EventBridge Scheduler's limit on schedule names
+	maxScheduleNameLength = 64
+	// scheduleNameHashLength keeps 128 bits of the user ID hash
+	scheduleNameHashLength = 32
+)
+
+var validScheduleName = regexp.MustCompile(`^[0-9a-zA-Z\-_.]+$`)
+
+// ScheduleName returns the deterministic EventBridge schedule name for a user.
+// The raw user ID is hashed so unusual IDs can never exceed the length limit or contain invalid characters.
+func ScheduleName(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return ScheduleNamePrefix + hex.EncodeToString(sum[:])[:scheduleNameHashLength]
+}
+
+// LegacyScheduleName returns the schedule name used before names were hashed,
+// or an empty string if the user ID could never have produced a valid name
+func LegacyScheduleName(userID string) string {
+	name := ScheduleNamePrefix + userID
+	if !IsValidScheduleName(name) {
+		return ""
+	}
+	return name
+}
+
+// IsValidScheduleName checks a name against EventBridge Scheduler's naming rules
+func IsValidScheduleName(name string) bool {
+	return len(name) > 0 && len(name) <= maxScheduleNameLength && validScheduleName.MatchString(name)
+}
+
+// DailyCronExpression converts a local push time ("HH:MM") into the UTC cron expression EventBridge Scheduler expects.
+// The offset is taken at now, so daylight saving changes shift the push by an hour until the schedule is recreated
+func DailyCronExpression(pushTime, timezone string, now time.Time) (string, error) {
+	t, err := time.Parse("15:04", pushTime)
+	if err != nil {
+		return "", fmt.Errorf("invalid time format: %s", pushTime)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", fmt.Errorf("invalid timezone: %s", timezone)
+	}
+
+	local := now.In(loc)
+	utcTime := time.Date(local.Year(), local.Month(), local.Day(), t.Hour(), t.Minute(), 0, 0, loc).UTC()
+
+	// cron 格式: 分 時 日 月 星期 年
+	return fmt.Sprintf("cron(%d %d * * ? *)", utcTime.Minute(), utcTime.Hour()), nil
+}
+
+// NextPushTime 以排程使用的 cron 表達式計算 now 之後的下一次每日推播時間，回傳用戶時區的時間
+func NextPushTime(pushTime, timezone string, now time.Time) (time.Time, error) {
+	expression, err := DailyCronExpression(pushTime, timezone, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	schedule, err := ParseCronExpression(expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next, ok := schedule.Next(now)
+	if !ok {
+		return time.Time{}, fmt.Errorf("no upcoming time for %s", expression)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone: %s", timezone)
+	}
+	return next.In(loc), nil
+}
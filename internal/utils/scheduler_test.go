@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleName(t *testing.T) {
+	userIDs := []string{
+		"U4af4980629c2b0f1a1f5e3c3c2a3b4c5",
+		"",
+		"user with spaces/and:symbols",
+		strings.Repeat("x", 200),
+		"使用者",
+	}
+
+	for _, userID := range userIDs {
+		name := ScheduleName(userID)
+		if !IsValidScheduleName(name) {
+			t.Errorf("ScheduleName(%q) = %q is not a valid schedule name", userID, name)
+		}
+		if name != ScheduleName(userID) {
+			t.Errorf("ScheduleName(%q) is not deterministic", userID)
+		}
+	}
+
+	if ScheduleName("a") == ScheduleName("b") {
+		t.Error("Expected different user IDs to produce different schedule names")
+	}
+}
+
+func TestLegacyScheduleName(t *testing.T) {
+	if got := LegacyScheduleName("U4af4980629c2b0f1a1f5e3c3c2a3b4c5"); got != "daily-vocab-U4af4980629c2b0f1a1f5e3c3c2a3b4c5" {
+		t.Errorf("Unexpected legacy name %q", got)
+	}
+	if got := LegacyScheduleName("bad id"); got != "" {
+		t.Errorf("Expected empty legacy name for invalid ID, got %q", got)
+	}
+	if got := LegacyScheduleName(strings.Repeat("x", 60)); got != "" {
+		t.Errorf("Expected empty legacy name for overlong ID, got %q", got)
+	}
+}
+
+func TestDailyCronExpression(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	expr, err := DailyCronExpression("08:30", "Asia/Taipei", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expr != "cron(30 0 * * ? *)" {
+		t.Errorf("Expected 08:30 Taipei to be 00:30 UTC, got %s", expr)
+	}
+
+	if _, err := DailyCronExpression("8am", "Asia/Taipei", now); err == nil {
+		t.Errorf("Expected invalid push time to fail")
+	}
+	if _, err := DailyCronExpression("08:30", "Mars/Olympus", now); err == nil {
+		t.Errorf("Expected invalid timezone to fail")
+	}
+}
+
+func TestNextPushTime(t *testing.T) {
+	// 台北 2025-03-01 18:00
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	next, err := NextPushTime("08:00", "Asia/Taipei", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := next.Format("2006-01-02 15:04 MST"); got != "2025-03-02 08:00 CST" {
+		t.Errorf("Expected tomorrow 08:00 Taipei, got %s", got)
+	}
+
+	next, err = NextPushTime("19:00", "Asia/Taipei", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := next.Format("2006-01-02 15:04"); got != "2025-03-01 19:00" {
+		t.Errorf("Expected today 19:00 Taipei, got %s", got)
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ShutdownFlushTimeout 是收到 SIGTERM 後 flush 的時間上限；Lambda 只給內部 extension 約 500ms 的 shutdown 階段
+const ShutdownFlushTimeout = 400 * time.Millisecond
+
+type flusher struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Shutdown tracks work that outlives a request: background goroutines started with Go and flush hooks registered
+// with OnFlush. Lambda freezes the container as soon as the handler returns and may shut it down without another
+// invocation, so anything still running or buffered at that point is lost. Call Flush before returning from each
+// invocation and pass OnSIGTERM to lambda.WithEnableSIGTERM for the final shutdown. Safe for concurrent use
+type Shutdown struct {
+	logger   *logrus.Entry
+	tasks    sync.WaitGroup
+	mu       sync.Mutex
+	flushers []flusher
+}
+
+func NewShutdown(logger *logrus.Entry) *Shutdown {
+	return &Shutdown{logger: logger}
+}
+
+// Go runs fn in a goroutine that Flush waits for
+func (s *Shutdown) Go(fn func()) {
+	s.tasks.Add(1)
+	go func() {
+		defer s.tasks.Done()
+		fn()
+	}()
+}
+
+// OnFlush registers a hook that writes out buffered state (e.g. metrics or outbox records); hooks run in
+// registration order after background goroutines finish
+func (s *Shutdown) OnFlush(name string, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushers = append(s.flushers, flusher{name: name, fn: fn})
+}
+
+// Flush waits for background goroutines and then runs the flush hooks, giving up once ctx is done. Failures are
+// only logged: by this point the response is decided and there is no one to return an error to
+func (s *Shutdown) Flush(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.tasks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.WithError(ctx.Err()).Warn("Gave up waiting for background work before flush")
+		return
+	}
+
+	s.mu.Lock()
+	flushers := append([]flusher(nil), s.flushers...)
+	s.mu.Unlock()
+
+	for _, f := range flushers {
+		if err := ctx.Err(); err != nil {
+			s.logger.WithError(err).WithField("flusher", f.name).Warn("Skipped flush, out of time")
+			continue
+		}
+		if err := f.fn(ctx); err != nil {
+			s.logger.WithError(err).WithField("flusher", f.name).Warn("Failed to flush")
+		}
+	}
+}
+
+// OnSIGTERM flushes within ShutdownFlushTimeout; pass it to lambda.WithEnableSIGTERM
+func (s *Shutdown) OnSIGTERM() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownFlushTimeout)
+	defer cancel()
+	s.logger.Info("Received SIGTERM, flushing before shutdown")
+	s.Flush(ctx)
+}
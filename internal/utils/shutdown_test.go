@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestShutdown() *Shutdown {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewShutdown(logrus.NewEntry(logger))
+}
+
+func TestShutdownFlush(t *testing.T) {
+	t.Run("waits for background work before flushing", func(t *testing.T) {
+		s := newTestShutdown()
+		var mu sync.Mutex
+		var order []string
+		record := func(step string) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, step)
+		}
+
+		release := make(chan struct{})
+		s.Go(func() {
+			<-release
+			record("task")
+		})
+		s.OnFlush("metrics", func(context.Context) error { record("metrics"); return errors.New("unavailable") })
+		s.OnFlush("outbox", func(context.Context) error { record("outbox"); return nil })
+
+		time.AfterFunc(10*time.Millisecond, func() { close(release) })
+		s.Flush(context.Background())
+
+		if want := []string{"task", "metrics", "outbox"}; !reflect.DeepEqual(order, want) {
+			t.Errorf("Flush order = %v, want %v (a failed hook must not stop later ones)", order, want)
+		}
+	})
+
+	t.Run("gives up when the context ends", func(t *testing.T) {
+		s := newTestShutdown()
+		block := make(chan struct{})
+		defer close(block)
+		s.Go(func() { <-block })
+		flushed := false
+		s.OnFlush("metrics", func(context.Context) error { flushed = true; return nil })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		s.Flush(ctx)
+		if time.Since(start) > time.Second || flushed {
+			t.Errorf("Expected Flush to return at the deadline without running hooks, took %v (flushed %v)", time.Since(start), flushed)
+		}
+	})
+}
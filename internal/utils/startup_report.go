@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"runtime"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StartupReport is the configuration self-check a Lambda logs once on cold start, so operators can tell from a
+// single log entry which features, models, tables and prompt versions a deployed version runs with.
+// Only whether a secret is set is ever recorded, never its value
+type StartupReport struct {
+	service  string
+	getenv   func(string) string
+	features map[string]bool
+	tables   map[string]string
+	settings map[string]interface{}
+}
+
+// NewStartupReport starts a report for service; getenv supplies the Lambda runtime variables
+// (function name, version, region). Call it once from main on cold start, after the configuration is loaded,
+// so the report reflects the settings this version actually runs with
+func NewStartupReport(service string, getenv func(string) string) *StartupReport {
+	return &StartupReport{
+		service:  service,
+		getenv:   getenv,
+		features: map[string]bool{},
+		tables:   map[string]string{},
+		settings: map[string]interface{}{},
+	}
+}
+
+// Feature records whether an optional feature is enabled
+func (r *StartupReport) Feature(name string, enabled bool) *StartupReport {
+	r.features[name] = enabled
+	return r
+}
+
+// Table records the table name a role (e.g. "vocabulary") is bound to
+func (r *StartupReport) Table(role, tableName string) *StartupReport {
+	r.tables[role] = tableName
+	return r
+}
+
+// Tables records the tables whose schemas were validated at startup, keyed by table name
+func (r *StartupReport) Tables(schemas ...TableSchema) *StartupReport {
+	for _, schema := range schemas {
+		r.tables[schema.TableName] = "schema ok"
+	}
+	return r
+}
+
+// Secret records whether a secret is configured without its value
+func (r *StartupReport) Secret(name, value string) *StartupReport {
+	r.settings[name] = value != ""
+	return r
+}
+
+// Setting records any other non-secret configuration value
+func (r *StartupReport) Setting(name string, value interface{}) *StartupReport {
+	r.settings[name] = value
+	return r
+}
+
+// OpenAI records the models, prompt versions, generation parameters and retry policy used for OpenAI calls
+func (r *StartupReport) OpenAI(params map[OpenAIFeature]GenerationParams, retry RetryPolicy, fake bool) *StartupReport {
+	versions := make([]string, 0, len(translationPrompts))
+	for version := range translationPrompts {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	generation := map[string]GenerationParams{}
+	for feature, p := range params {
+		generation[string(feature)] = p
+	}
+
+	r.settings["openai"] = map[string]interface{}{
+		"fake":                      fake,
+		"defaultModel":              DefaultOpenAIModel,
+		"allowedModels":             AllowedOpenAIModels,
+		"translationPromptBaseline": BaselineTranslationPromptVersion,
+		"translationPromptVersions": versions,
+		"generationParams":          generation,
+		"retry": map[string]interface{}{
+			"maxAttempts": retry.MaxAttempts,
+			"baseDelay":   retry.BaseDelay.String(),
+			"maxDelay":    retry.MaxDelay.String(),
+		},
+	}
+	return r
+}
+
+// ErrorBudget records how many failures open a support ticket
+func (r *StartupReport) ErrorBudget(budget ErrorBudget) *StartupReport {
+	r.settings["errorBudget"] = map[string]interface{}{
+		"maxFailures": budget.MaxFailures,
+		"window":      budget.Window.String(),
+	}
+	return r
+}
+
+// Fields returns the report as log fields
+func (r *StartupReport) Fields() logrus.Fields {
+	return logrus.Fields{
+		"service":   r.service,
+		"function":  r.getenv("AWS_LAMBDA_FUNCTION_NAME"),
+		"version":   r.getenv("AWS_LAMBDA_FUNCTION_VERSION"),
+		"region":    r.getenv("AWS_REGION"),
+		"goVersion": runtime.Version(),
+		"features":  r.features,
+		"tables":    r.tables,
+		"settings":  r.settings,
+	}
+}
+
+// Log writes the report as a single structured entry
+func (r *StartupReport) Log(logger *logrus.Entry) {
+	logger.WithField("startup", r.Fields()).Info("Startup self-check")
+}
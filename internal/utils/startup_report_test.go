@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStartupReport(t *testing.T) {
+	env := map[string]string{"AWS_LAMBDA_FUNCTION_NAME": "language-handler", "AWS_LAMBDA_FUNCTION_VERSION": "42"}
+	report := NewStartupReport("language-handler", func(key string) string { return env[key] }).
+		Tables(VocabularyTableSchema("vocabulary")).
+		OpenAI(defaultGenerationParams, RetryPolicy{MaxAttempts: 3}, false).
+		Feature("richMenu", true).
+		Secret("openaiApiKey", "sk-secret").
+		Setting("pushMode", PushModeSchedule)
+
+	fields := report.Fields()
+	if fields["version"] != "42" || fields["function"] != "language-handler" {
+		t.Errorf("Expected Lambda version fields, got %v", fields)
+	}
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("Expected report to be JSON encodable: %v", err)
+	}
+	out := string(raw)
+	if strings.Contains(out, "sk-secret") {
+		t.Errorf("Secret value leaked into the report: %s", out)
+	}
+	for _, want := range []string{`"openaiApiKey":true`, `"richMenu":true`, BaselineTranslationPromptVersion, DefaultOpenAIModel, `"vocabulary":"schema ok"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %s, got %s", want, out)
+		}
+	}
+}
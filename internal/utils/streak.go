@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"time"
+)
+
+// StreakDate returns today's date (YYYY-MM-DD) in the user's timezone, falling back to UTC
+func StreakDate(now time.Time, timezone string) string {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return now.In(loc).Format("2006-01-02")
+}
+
+// AdvanceStreak records activity on date. It returns the updated streak, whether anything changed
+// (false when the user was already active that day) and the milestone newly reached, 0 if none
+func AdvanceStreak(streak models.Streak, date string) (models.Streak, bool, int) {
+	if streak.LastActiveDate == date {
+		return streak, false, 0
+	}
+
+	if streak.LastActiveDate != "" && isNextDay(streak.LastActiveDate, date) {
+		streak.Current++
+	} else {
+		// 第一次學習或中斷後重新開始
+		streak.Current = 1
+		streak.Milestone = 0
+	}
+	streak.LastActiveDate = date
+	if streak.Current > streak.Longest {
+		streak.Longest = streak.Current
+	}
+
+	reached := 0
+	for _, milestone := range models.StreakMilestones {
+		if streak.Current >= milestone && milestone > streak.Milestone {
+			reached = milestone
+		}
+	}
+	if reached > 0 {
+		streak.Milestone = reached
+	}
+	return streak, true, reached
+}
+
+// CurrentStreak returns the streak as of today: it still counts if the user was last active today or yesterday
+func CurrentStreak(streak *models.Streak, today string) int {
+	if streak == nil {
+		return 0
+	}
+	if streak.LastActiveDate == today || isNextDay(streak.LastActiveDate, today) {
+		return streak.Current
+	}
+	return 0
+}
+
+func isNextDay(prev, next string) bool {
+	prevDay, err := time.Parse("2006-01-02", prev)
+	if err != nil {
+		return false
+	}
+	return prevDay.AddDate(0, 0, 1).Format("2006-01-02") == next
+}
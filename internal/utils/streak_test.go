@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestAdvanceStreak(t *testing.T) {
+	var streak models.Streak
+	var changed bool
+	var reached int
+
+	streak, changed, _ = AdvanceStreak(streak, "2024-03-01")
+	if !changed || streak.Current != 1 || streak.Longest != 1 {
+		t.Fatalf("Expected first activity to start a streak, got %+v", streak)
+	}
+	if _, changed, _ = AdvanceStreak(streak, "2024-03-01"); changed {
+		t.Error("Expected a second activity on the same day to be a no-op")
+	}
+
+	for _, date := range []string{"2024-03-02", "2024-03-03", "2024-03-04", "2024-03-05", "2024-03-06", "2024-03-07"} {
+		streak, _, reached = AdvanceStreak(streak, date)
+	}
+	if streak.Current != 7 || reached != 7 || streak.Milestone != 7 {
+		t.Fatalf("Expected the 7 day milestone on day 7, got %+v (reached %d)", streak, reached)
+	}
+	if streak, _, reached = AdvanceStreak(streak, "2024-03-08"); reached != 0 {
+		t.Errorf("Expected no milestone on day 8, got %d", reached)
+	}
+
+	// 跨月也算連續，中斷後重新計算但保留最長紀錄與重新達成里程碑的機會
+	streak, _, _ = AdvanceStreak(streak, "2024-03-10")
+	if streak.Current != 1 || streak.Longest != 8 || streak.Milestone != 0 {
+		t.Errorf("Expected a gap to reset the streak, got %+v", streak)
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	streak := &models.Streak{Current: 5, LastActiveDate: "2024-02-28"}
+	if got := CurrentStreak(streak, "2024-02-29"); got != 5 {
+		t.Errorf("Expected streak to still count the day after, got %d", got)
+	}
+	if got := CurrentStreak(streak, "2024-03-01"); got != 0 {
+		t.Errorf("Expected a broken streak to count as 0, got %d", got)
+	}
+	if got := CurrentStreak(nil, "2024-03-01"); got != 0 {
+		t.Errorf("Expected no streak record to count as 0, got %d", got)
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+)
+
+const (
+	studySheetTitleSize = 18.0
+	studySheetDateSize  = 13.0
+	studySheetWordSize  = 12.0
+	studySheetBodySize  = 10.5
+	studySheetIndent    = 16.0
+	// 每張字卡約佔 5 行，避免跨頁
+	studySheetCardHeight = 5 * studySheetWordSize * pdfLineSpacing
+)
+
+// BuildStudySheetPDF renders saved vocabulary into a printable study sheet:
+// word, meaning, example sentence and a blank line for notes
+func BuildStudySheetPDF(title string, vocabularies []models.UserVocabulary) []byte {
+	doc := NewPDFDocument()
+	doc.AddParagraph(title, studySheetTitleSize, 0)
+	doc.AddSpacing(studySheetBodySize)
+
+	for _, vocabulary := range vocabularies {
+		if len(vocabulary.Words) == 0 {
+			continue
+		}
+
+		doc.EnsureSpace(studySheetDateSize*pdfLineSpacing + studySheetCardHeight)
+		doc.AddParagraph(vocabulary.Date, studySheetDateSize, 0)
+
+		for i, word := range vocabulary.Words {
+			doc.EnsureSpace(studySheetCardHeight)
+			doc.AddParagraph(fmt.Sprintf("%d. %s (%s)", i+1, word.Word, word.PartOfSpeech), studySheetWordSize, 0)
+			doc.AddParagraph("意思："+word.Translation, studySheetBodySize, studySheetIndent)
+			if word.Sentence != "" {
+				doc.AddParagraph("例句："+word.Sentence, studySheetBodySize, studySheetIndent)
+			}
+			doc.AddParagraph("筆記：________________________________________________", studySheetBodySize, studySheetIndent)
+			doc.AddSpacing(studySheetBodySize)
+		}
+	}
+
+	return doc.Bytes()
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// KeySchema is the partition / sort key pair of a table or index; Range is empty for hash-only keys
+type KeySchema struct {
+	Hash  string `json:"hash"`
+	Range string `json:"range,omitempty"`
+}
+
+// TableSchema is the key layout the repositories rely on for a table
+type TableSchema struct {
+	TableName string               `json:"tableName"`
+	Key       KeySchema            `json:"key"`
+	Indexes   map[string]KeySchema `json:"indexes,omitempty"` // GSI 名稱 → key
+}
+
+// VocabularyTableSchema 單字、推播紀錄、實驗指標等共用的 table，DateIndex 供每日回顧查詢
+func VocabularyTableSchema(tableName string) TableSchema {
+	return TableSchema{
+		TableName: tableName,
+		Key:       KeySchema{Hash: "pk", Range: "sk"},
+		Indexes: map[string]KeySchema{
+			"DateIndex": {Hash: "date"},
+		},
+	}
+}
+
+// UserTableSchema 用戶設定 table 與各個反查用的 GSI
+func UserTableSchema(tableName string) TableSchema {
+	return TableSchema{
+		TableName: tableName,
+		Key:       KeySchema{Hash: "userId"},
+		Indexes: map[string]KeySchema{
+			"CourseIndex":       {Hash: "course"},
+			"ScheduleNameIndex": {Hash: "scheduleName"},
+			"ProfileSlugIndex":  {Hash: "profileSlug"},
+			"BetaStatusIndex":   {Hash: "betaStatus"},
+			"PushTimeIndex":     {Hash: "pushTime"},
+		},
+	}
+}
+
+// ScheduleAuditTableSchema 排程稽核紀錄 table
+func ScheduleAuditTableSchema(tableName string) TableSchema {
+	return TableSchema{TableName: tableName, Key: KeySchema{Hash: "pk", Range: "sk"}}
+}
+
+// PairingTableSchema 網頁登入配對碼 table
+func PairingTableSchema(tableName string) TableSchema {
+	return TableSchema{TableName: tableName, Key: KeySchema{Hash: "pk"}}
+}
+
+// AnalyticsTableSchema 留存分析 table
+func AnalyticsTableSchema(tableName string) TableSchema {
+	return TableSchema{TableName: tableName, Key: KeySchema{Hash: "pk", Range: "sk"}}
+}
+
+// CompareTableSchema 比對實際的 table 描述與預期的 schema，回傳所有不符之處（依 GSI 名稱排序）
+func CompareTableSchema(schema TableSchema, table *types.TableDescription) []string {
+	var problems []string
+	if actual := keySchemaOf(table.KeySchema); actual != schema.Key {
+		problems = append(problems, fmt.Sprintf("primary key is %s, expected %s", actual, schema.Key))
+	}
+
+	actualIndexes := map[string]KeySchema{}
+	for _, index := range table.GlobalSecondaryIndexes {
+		actualIndexes[aws.ToString(index.IndexName)] = keySchemaOf(index.KeySchema)
+	}
+
+	names := make([]string, 0, len(schema.Indexes))
+	for name := range schema.Indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		actual, ok := actualIndexes[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing GSI %s", name))
+			continue
+		}
+		if expected := schema.Indexes[name]; actual != expected {
+			problems = append(problems, fmt.Sprintf("GSI %s key is %s, expected %s", name, actual, expected))
+		}
+	}
+
+	return problems
+}
+
+// ValidateTableSchemas 逐一 DescribeTable 並比對 schema，任一 table 不存在或不符時回傳列出所有問題的錯誤，
+// 讓部署設定錯誤在啟動時就明確失敗，而不是在執行 Query 時才出現難以追查的錯誤
+func ValidateTableSchemas(api DynamoDbAPI, schemas ...TableSchema) error {
+	var errs []error
+	for _, schema := range schemas {
+		output, err := api.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{
+			TableName: aws.String(schema.TableName),
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("table %s: failed to describe: %w", schema.TableName, err))
+			continue
+		}
+
+		if problems := CompareTableSchema(schema, output.Table); len(problems) > 0 {
+			errs = append(errs, fmt.Errorf("table %s: %s", schema.TableName, strings.Join(problems, "; ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func keySchemaOf(elements []types.KeySchemaElement) KeySchema {
+	var key KeySchema
+	for _, element := range elements {
+		switch element.KeyType {
+		case types.KeyTypeHash:
+			key.Hash = aws.ToString(element.AttributeName)
+		case types.KeyTypeRange:
+			key.Range = aws.ToString(element.AttributeName)
+		}
+	}
+	return key
+}
+
+func (k KeySchema) String() string {
+	if k.Range == "" {
+		return fmt.Sprintf("(%s)", k.Hash)
+	}
+	return fmt.Sprintf("(%s, %s)", k.Hash, k.Range)
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestCompareTableSchema(t *testing.T) {
+	key := func(hash, rng string) []types.KeySchemaElement {
+		elements := []types.KeySchemaElement{{AttributeName: aws.String(hash), KeyType: types.KeyTypeHash}}
+		if rng != "" {
+			elements = append(elements, types.KeySchemaElement{AttributeName: aws.String(rng), KeyType: types.KeyTypeRange})
+		}
+		return elements
+	}
+
+	table := &types.TableDescription{
+		KeySchema: key("userId", ""),
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{
+			{IndexName: aws.String("CourseIndex"), KeySchema: key("course", "")},
+			{IndexName: aws.String("ScheduleNameIndex"), KeySchema: key("scheduleName", "")},
+			{IndexName: aws.String("ProfileSlugIndex"), KeySchema: key("slug", "")},
+			{IndexName: aws.String("PushTimeIndex"), KeySchema: key("pushTime", "")},
+		},
+	}
+
+	got := CompareTableSchema(UserTableSchema("users"), table)
+	want := []string{
+		"missing GSI BetaStatusIndex",
+		"GSI ProfileSlugIndex key is (slug), expected (profileSlug)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareTableSchema() = %q, want %q", got, want)
+	}
+
+	vocabulary := &types.TableDescription{
+		KeySchema: key("pk", "sk"),
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndexDescription{
+			{IndexName: aws.String("DateIndex"), KeySchema: key("date", "")},
+		},
+	}
+	if problems := CompareTableSchema(VocabularyTableSchema("vocabulary"), vocabulary); len(problems) != 0 {
+		t.Errorf("Expected matching schema, got %q", problems)
+	}
+	if problems := CompareTableSchema(PairingTableSchema("pairing"), vocabulary); len(problems) != 1 {
+		t.Errorf("Expected primary key mismatch, got %q", problems)
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/models"
+	"net/url"
+	"strings"
+)
+
+const (
+	// TermsVersionEnv 目前的服務條款版本（例如 "2026-10"），更新條款時修改；未設定時不要求用戶同意
+	TermsVersionEnv = "TERMS_VERSION"
+	// TermsURLEnv 服務條款全文的網址，設定 TermsVersionEnv 時必填
+	TermsURLEnv = "TERMS_URL"
+)
+
+// premiumCommands 需要先同意目前服務條款才能使用的付費功能指令（含方案付款與試用）
+var premiumCommands = []string{"/支持", "/試用", "/發音"}
+
+// Terms is the current terms-of-service version users must acknowledge before premium features are served.
+// A nil Terms (TERMS_VERSION unset) accepts every user
+type Terms struct {
+	Version string
+	URL     string
+}
+
+// LoadTerms reads TERMS_VERSION and TERMS_URL; it returns nil when no version is configured
+func LoadTerms(getenv func(string) string) (*Terms, error) {
+	version := strings.TrimSpace(getenv(TermsVersionEnv))
+	if version == "" {
+		return nil, nil
+	}
+	link := strings.TrimSpace(getenv(TermsURLEnv))
+	if parsed, err := url.Parse(link); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("%s must be an https URL when %s is set, got %q", TermsURLEnv, TermsVersionEnv, link)
+	}
+	return &Terms{Version: version, URL: link}, nil
+}
+
+// Accepted 回傳用戶是否已同意目前版本的服務條款
+func (t *Terms) Accepted(userConfig models.UserConfig) bool {
+	return t == nil || userConfig.TermsVersion == t.Version
+}
+
+// NeedsPrompt 回傳是否還沒有對用戶提示過目前版本的服務條款（每個版本只主動提示一次）
+func (t *Terms) NeedsPrompt(userConfig models.UserConfig) bool {
+	return !t.Accepted(userConfig) && userConfig.TermsPrompted != t.Version
+}
+
+// IsPremiumCommand 回傳文字是否為付費功能的指令（已經過 NormalizeCommand），例如 "/發音 開啟"
+func IsPremiumCommand(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, command := range premiumCommands {
+		if strings.HasPrefix(text, command) {
+			return true
+		}
+	}
+	return false
+}
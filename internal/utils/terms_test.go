@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestLoadTerms(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+
+	if terms, err := LoadTerms(env(nil)); err != nil || terms != nil {
+		t.Errorf("Expected no terms by default, got %v (err %v)", terms, err)
+	}
+	if _, err := LoadTerms(env(map[string]string{TermsVersionEnv: "2026-10"})); err == nil {
+		t.Error("Expected an error without a terms URL")
+	}
+	if _, err := LoadTerms(env(map[string]string{TermsVersionEnv: "2026-10", TermsURLEnv: "http://example.com/terms"})); err == nil {
+		t.Error("Expected an error for a non-https terms URL")
+	}
+	terms, err := LoadTerms(env(map[string]string{TermsVersionEnv: " 2026-10 ", TermsURLEnv: "https://example.com/terms"}))
+	if err != nil || terms.Version != "2026-10" || terms.URL != "https://example.com/terms" {
+		t.Errorf("Unexpected terms %+v (err %v)", terms, err)
+	}
+}
+
+func TestTermsAcceptance(t *testing.T) {
+	terms := &Terms{Version: "2026-10", URL: "https://example.com/terms"}
+
+	outdated := models.UserConfig{TermsVersion: "2026-01"}
+	if terms.Accepted(outdated) || !terms.NeedsPrompt(outdated) {
+		t.Errorf("Expected a user on the previous version to be prompted")
+	}
+	outdated.TermsPrompted = "2026-10"
+	if terms.NeedsPrompt(outdated) {
+		t.Errorf("Expected each version to be prompted only once")
+	}
+	if accepted := (models.UserConfig{TermsVersion: "2026-10"}); !terms.Accepted(accepted) || terms.NeedsPrompt(accepted) {
+		t.Errorf("Expected a user on the current version not to be prompted")
+	}
+
+	var disabled *Terms
+	if !disabled.Accepted(models.UserConfig{}) || disabled.NeedsPrompt(models.UserConfig{}) {
+		t.Errorf("Expected nil terms to accept every user")
+	}
+}
+
+func TestIsPremiumCommand(t *testing.T) {
+	cases := map[string]bool{
+		"/支持":      true,
+		"/試用":      true,
+		"/發音 開啟":   true,
+		"/文法模式 開啟": false,
+		"apple":    false,
+	}
+	for text, expected := range cases {
+		if got := IsPremiumCommand(text); got != expected {
+			t.Errorf("IsPremiumCommand(%q) = %v, expected %v", text, got, expected)
+		}
+	}
+}
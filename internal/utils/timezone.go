@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // Lambda 的執行環境不一定有系統時區資料，讓用戶輸入的任何 IANA 時區都能載入
+)
+
+// ValidateTimezone checks a user-entered IANA timezone name (e.g. "Europe/London") and returns its canonical
+// form. "Local" is rejected because it depends on the Lambda environment rather than the user
+func ValidateTimezone(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "Local") {
+		return "", fmt.Errorf("invalid timezone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc.String(), nil
+}
+
+// timezonePattern IANA 時區名稱的形式 "Area/City"，Area 以大寫開頭，避免把 "and/or" 之類的輸入當成時區
+var timezonePattern = regexp.MustCompile(`^[A-Z][A-Za-z]+(/[A-Za-z0-9_+-]+)+$`)
+
+// LooksLikeTimezone reports whether free text during the timezone step is an attempt at an IANA name
+// rather than something to translate
+func LooksLikeTimezone(text string) bool {
+	return timezonePattern.MatchString(strings.TrimSpace(text))
+}
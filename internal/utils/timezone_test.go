@@ -0,0 +1,34 @@
+package utils
+
+import "testing"
+
+func TestValidateTimezone(t *testing.T) {
+	for _, name := range []string{"Asia/Taipei", " Europe/London ", "America/Argentina/Buenos_Aires", "UTC"} {
+		if _, err := ValidateTimezone(name); err != nil {
+			t.Errorf("ValidateTimezone(%q) unexpected error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Local", "Asia/Atlantis", "taipei"} {
+		if _, err := ValidateTimezone(name); err == nil {
+			t.Errorf("ValidateTimezone(%q) expected an error", name)
+		}
+	}
+}
+
+func TestLooksLikeTimezone(t *testing.T) {
+	tests := map[string]bool{
+		"Europe/Paris":                   true,
+		"America/Argentina/Buenos_Aires": true,
+		"Etc/GMT+8":                      true,
+		"Asia/Atlantis":                  true, // 形式正確，交給 ValidateTimezone 判斷
+		"and/or":                         false,
+		"/設定推播":                          false,
+		"input/output devices":           false,
+		"book":                           false,
+	}
+	for text, want := range tests {
+		if got := LooksLikeTimezone(text); got != want {
+			t.Errorf("LooksLikeTimezone(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+)
+
+// token 用量保留 90 天供費用報表查詢，之後由 TTL 刪除
+const tokenUsageRetention = 90 * 24 * time.Hour
+
+// ModelPrice is the OpenAI list price of a model in USD per 1M tokens
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// 各模型的單價，OpenAI 調整價格或新增可選模型時一併更新
+var modelPrices = map[string]ModelPrice{
+	openai.GPT4oMini: {Prompt: 0.15, Completion: 0.60},
+	openai.GPT4o:     {Prompt: 2.50, Completion: 10.00},
+	openai.GPT5:      {Prompt: 1.25, Completion: 10.00},
+}
+
+// EstimateCost 依模型單價估算一次呼叫的費用（USD）；不在價目表中的模型回傳 false
+func EstimateCost(usage CompletionUsage) (float64, bool) {
+	price, ok := modelPrices[usage.Model]
+	if !ok {
+		return 0, false
+	}
+	cost := (float64(usage.PromptTokens)*price.Prompt + float64(usage.CompletionTokens)*price.Completion) / 1_000_000
+	return roundCost(cost), true
+}
+
+// roundCost 取到百萬分之一美元，避免浮點數累加出現一長串小數
+func roundCost(cost float64) float64 {
+	return math.Round(cost*1_000_000) / 1_000_000
+}
+
+// TokenUsageRecorder adds the token usage of each OpenAI call to the user's daily aggregate. Recording is
+// best-effort: failures are logged and never affect the reply. Safe for concurrent use
+type TokenUsageRecorder struct {
+	logger *logrus.Entry
+	repo   UsageRepository
+	now    func() time.Time
+}
+
+func NewTokenUsageRecorder(logger *logrus.Entry, repo UsageRepository) *TokenUsageRecorder {
+	return &TokenUsageRecorder{
+		logger: logger,
+		repo:   repo,
+		now:    time.Now,
+	}
+}
+
+// Record 將一次呼叫的 token 用量累加到 userID 當天（UTC）該功能的用量；回應沒有用量資訊時不寫入
+func (r *TokenUsageRecorder) Record(userID string, feature OpenAIFeature, usage CompletionUsage) {
+	if usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		return
+	}
+	logger := r.logger.WithFields(logrus.Fields{"feature": feature, "model": usage.Model})
+	cost, ok := EstimateCost(usage)
+	if !ok {
+		logger.Warn("No price for model, recording token usage without cost")
+	}
+
+	now := r.now().UTC()
+	err := r.repo.AddTokenUsage(models.TokenUsage{
+		Date:             now.Format("2006-01-02"),
+		UserID:           userID,
+		Feature:          string(feature),
+		Requests:         1,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		CostUSD:          cost,
+	}, now.Add(tokenUsageRetention))
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record token usage")
+	}
+}
+
+// UsageCost is the summed token usage and cost of one feature or one user over the report period
+type UsageCost struct {
+	Key              string  `json:"key"` // 功能名稱或 userId
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	CostUSD          float64 `json:"costUsd"`
+}
+
+func (c *UsageCost) add(usage models.TokenUsage) {
+	c.Requests += usage.Requests
+	c.PromptTokens += usage.PromptTokens
+	c.CompletionTokens += usage.CompletionTokens
+	c.CostUSD = roundCost(c.CostUSD + usage.CostUSD)
+}
+
+// TokenUsageReport summarizes OpenAI cost by feature and lists the most expensive users over a date range
+type TokenUsageReport struct {
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Total    UsageCost   `json:"total"`
+	Features []UsageCost `json:"features"` // 依費用由高到低
+	TopUsers []UsageCost `json:"topUsers"` // 費用最高的 topUsers 位用戶
+}
+
+// SummarizeTokenUsage 彙整 from～to 的每日用量：各功能的總費用，以及費用最高的 topUsers 位用戶
+func SummarizeTokenUsage(from, to string, usages []models.TokenUsage, topUsers int) TokenUsageReport {
+	report := TokenUsageReport{From: from, To: to, Total: UsageCost{Key: "total"}}
+	features := map[string]*UsageCost{}
+	users := map[string]*UsageCost{}
+	for _, usage := range usages {
+		report.Total.add(usage)
+		if features[usage.Feature] == nil {
+			features[usage.Feature] = &UsageCost{Key: usage.Feature}
+		}
+		features[usage.Feature].add(usage)
+		if users[usage.UserID] == nil {
+			users[usage.UserID] = &UsageCost{Key: usage.UserID}
+		}
+		users[usage.UserID].add(usage)
+	}
+
+	report.Features = sortedByCost(features)
+	report.TopUsers = sortedByCost(users)
+	if len(report.TopUsers) > topUsers {
+		report.TopUsers = report.TopUsers[:topUsers]
+	}
+	return report
+}
+
+func sortedByCost(costs map[string]*UsageCost) []UsageCost {
+	sorted := make([]UsageCost, 0, len(costs))
+	for _, cost := range costs {
+		sorted = append(sorted, *cost)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CostUSD != sorted[j].CostUSD {
+			return sorted[i].CostUSD > sorted[j].CostUSD
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
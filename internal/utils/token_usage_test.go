@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+)
+
+type recordingTokenUsage struct {
+	UsageRepository
+	usages []models.TokenUsage
+}
+
+func (r *recordingTokenUsage) AddTokenUsage(usage models.TokenUsage, expiresAt time.Time) error {
+	r.usages = append(r.usages, usage)
+	return nil
+}
+
+func TestEstimateCost(t *testing.T) {
+	cost, ok := EstimateCost(CompletionUsage{Model: openai.GPT4oMini, PromptTokens: 1_000_000, CompletionTokens: 500_000})
+	if !ok || cost != 0.45 {
+		t.Errorf("Expected $0.45 for gpt-4o-mini, got %v (%v)", cost, ok)
+	}
+	if _, ok := EstimateCost(CompletionUsage{Model: "unknown", PromptTokens: 10}); ok {
+		t.Error("Expected no price for an unknown model")
+	}
+}
+
+func TestTokenUsageRecorder(t *testing.T) {
+	repo := &recordingTokenUsage{}
+	recorder := NewTokenUsageRecorder(logrus.NewEntry(logrus.New()), repo)
+	recorder.now = func() time.Time { return time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("UTC+8", 8*3600)) }
+
+	recorder.Record("U1", FeatureTranslation, CompletionUsage{})
+	recorder.Record("U1", FeatureTranslation, CompletionUsage{Model: openai.GPT4o, PromptTokens: 400, CompletionTokens: 100})
+	if len(repo.usages) != 1 {
+		t.Fatalf("Expected only the call with token usage to be recorded, got %+v", repo.usages)
+	}
+	want := models.TokenUsage{Date: "2026-10-18", UserID: "U1", Feature: "TRANSLATION", Requests: 1, PromptTokens: 400, CompletionTokens: 100, CostUSD: 0.002}
+	if repo.usages[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, repo.usages[0])
+	}
+}
+
+func TestSummarizeTokenUsage(t *testing.T) {
+	usages := []models.TokenUsage{
+		{Date: "2026-10-17", UserID: "U1", Feature: "TRANSLATION", Requests: 10, PromptTokens: 4000, CompletionTokens: 1000, CostUSD: 0.0012},
+		{Date: "2026-10-17", UserID: "U2", Feature: "WORD_GENERATION", Requests: 1, PromptTokens: 800, CompletionTokens: 3000, CostUSD: 0.031},
+		{Date: "2026-10-18", UserID: "U1", Feature: "TRANSLATION", Requests: 5, PromptTokens: 2000, CompletionTokens: 500, CostUSD: 0.0006},
+		{Date: "2026-10-18", UserID: "U3", Feature: "TRANSLATION", Requests: 1, PromptTokens: 100, CompletionTokens: 20, CostUSD: 0.0001},
+	}
+
+	report := SummarizeTokenUsage("2026-10-17", "2026-10-18", usages, 2)
+	if report.Total.Requests != 17 || report.Total.CostUSD != 0.0329 {
+		t.Errorf("Unexpected total %+v", report.Total)
+	}
+	if len(report.Features) != 2 || report.Features[0].Key != "WORD_GENERATION" || report.Features[1].CostUSD != 0.0019 {
+		t.Errorf("Expected features sorted by cost, got %+v", report.Features)
+	}
+	if len(report.TopUsers) != 2 || report.TopUsers[0].Key != "U2" || report.TopUsers[1].Key != "U1" || report.TopUsers[1].Requests != 15 {
+		t.Errorf("Expected the two most expensive users, got %+v", report.TopUsers)
+	}
+}
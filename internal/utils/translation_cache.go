@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"language-assistant/internal/courses"
+	"language-assistant/internal/models"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TranslationCacheTTLEnv 翻譯快取的保存時數，"0" 表示不使用快取
+	TranslationCacheTTLEnv = "TRANSLATION_CACHE_TTL_HOURS"
+	// DefaultTranslationCacheTTLHours 未設定 TranslationCacheTTLEnv 時的保存時數
+	DefaultTranslationCacheTTLHours = 7 * 24
+)
+
+// 只快取常見單字或短片語；句子幾乎不會重複，快取只會多一次讀寫
+const (
+	maxCachedTranslationWords = 3
+	maxCachedTranslationRunes = 40
+)
+
+// translationCacheSchemaVersion Translation 欄位變動時（例如加入 IPA）遞增，舊格式的快取不再命中，直接重新翻譯
+const translationCacheSchemaVersion = "v2"
+
+// LoadTranslationCacheTTL reads TranslationCacheTTLEnv (whole hours), falling back to DefaultTranslationCacheTTLHours when unset
+func LoadTranslationCacheTTL(getenv func(string) string) (time.Duration, error) {
+	value := strings.TrimSpace(getenv(TranslationCacheTTLEnv))
+	if value == "" {
+		return DefaultTranslationCacheTTLHours * time.Hour, nil
+	}
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative number of hours, got %q", TranslationCacheTTLEnv, value)
+	}
+	return time.Duration(hours) * time.Hour, nil
+}
+
+// TranslationCache shares translations of short inputs across users, keyed by the whitespace-trimmed input and the
+// language, model and prompt version, so common words ("book", "apple") skip the OpenAI call. Cache errors
+// fall back to the client. A nil cache always calls the client. Holds no mutable state; safe for concurrent use
+type TranslationCache struct {
+	logger *logrus.Entry
+	repo   TranslationCacheRepository
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewTranslationCache(logger *logrus.Entry, repo TranslationCacheRepository, ttl time.Duration) *TranslationCache {
+	return &TranslationCache{
+		logger: logger,
+		repo:   repo,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// TranslationCacheText 回傳輸入文字的快取 key，不適合快取時回傳空字串。只整理空白，保留大小寫與標點：
+// "US"/"us"、"May"/"may"、"what?"/"what" 的翻譯不同，不能共用快取
+func TranslationCacheText(text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	if len(strings.Fields(normalized)) > maxCachedTranslationWords || utf8.RuneCountInString(normalized) > maxCachedTranslationRunes {
+		return ""
+	}
+	return normalized
+}
+
+// translationCacheVariant 不同語言、模型、prompt 或快取格式版本的翻譯結果不同，各自快取
+func translationCacheVariant(opts TranslateOptions) string {
+	language := opts.Language
+	if language == "" {
+		language = courses.LanguageEnglish
+	}
+	model := opts.Model
+	if model == "" {
+		model = "default"
+	}
+	promptVersion := opts.PromptVersion
+	if promptVersion == "" {
+		promptVersion = BaselineTranslationPromptVersion
+	}
+	return language + "#" + model + "#" + promptVersion + "#" + translationCacheSchemaVersion
+}
+
+// Translate returns the cached translation of inputMsg, calling client.Translate and caching the result on a miss.
+// opts.BypassCache and inputs too long to cache always call the client. The bool reports a cache hit; hits carry
+// no token usage
+func (c *TranslationCache) Translate(client OpenaiAPI, inputMsg string, opts TranslateOptions) (TranslationResponse, bool, error) {
+	text := TranslationCacheText(inputMsg)
+	if c == nil || opts.BypassCache || text == "" {
+		response, err := client.Translate(inputMsg, opts)
+		return response, false, err
+	}
+	variant := translationCacheVariant(opts)
+	logger := c.logger.WithFields(logrus.Fields{"text": text, "variant": variant})
+
+	now := c.now()
+	entry, err := c.repo.GetCachedTranslation(text, variant)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to read translation cache, calling OpenAI")
+	} else if entry != nil && entry.ExpiresAt > now.Unix() {
+		var response TranslationResponse
+		if err := json.Unmarshal([]byte(entry.Translations), &response.Translations); err == nil && len(response.Translations) > 0 {
+			return response, true, nil
+		}
+		logger.WithError(err).Warn("Ignoring unreadable cached translation")
+	}
+
+	response, err := client.Translate(inputMsg, opts)
+	if err != nil || len(response.Translations) == 0 {
+		return response, false, err
+	}
+	translations, err := json.Marshal(response.Translations)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to marshal translation for cache")
+		return response, false, nil
+	}
+	err = c.repo.PutCachedTranslation(models.TranslationCacheEntry{
+		Text:         text,
+		Variant:      variant,
+		Translations: string(translations),
+		CachedAt:     now.UTC().Format(time.RFC3339),
+		ExpiresAt:    now.Add(c.ttl).Unix(),
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to save translation to cache")
+	}
+	return response, false, nil
+}
+
+// Forget 清除單字所有語言、模型與 prompt 版本的快取，例如翻譯被用戶回報時，下次查詢重新翻譯
+func (c *TranslationCache) Forget(text string) error {
+	if c == nil {
+		return nil
+	}
+	if text = TranslationCacheText(text); text == "" {
+		return nil
+	}
+	return c.repo.DeleteCachedTranslations(text)
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"errors"
+	"language-assistant/internal/models"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type memoryTranslationCache struct {
+	entries map[string]models.TranslationCacheEntry
+	err     error
+}
+
+func (m *memoryTranslationCache) GetCachedTranslation(text, variant string) (*models.TranslationCacheEntry, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	entry, ok := m.entries[text+"|"+variant]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (m *memoryTranslationCache) PutCachedTranslation(entry models.TranslationCacheEntry) error {
+	m.entries[entry.Text+"|"+entry.Variant] = entry
+	return nil
+}
+
+func (m *memoryTranslationCache) DeleteCachedTranslations(text string) error {
+	for key, entry := range m.entries {
+		if entry.Text == text {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+type countingTranslator struct {
+	OpenaiAPI
+	calls int
+}
+
+func (c *countingTranslator) Translate(inputMsg string, opts TranslateOptions) (TranslationResponse, error) {
+	c.calls++
+	return TranslationResponse{
+		Translations: []Translation{{Word: inputMsg, Meaning: "書"}},
+		Usage:        CompletionUsage{Model: "gpt-4o", PromptTokens: 10, CompletionTokens: 20},
+	}, nil
+}
+
+func TestTranslationCache(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	repo := &memoryTranslationCache{entries: map[string]models.TranslationCacheEntry{}}
+	cache := NewTranslationCache(logrus.NewEntry(logrus.New()), repo, time.Hour)
+	cache.now = func() time.Time { return now }
+	client := &countingTranslator{}
+
+	if _, hit, err := cache.Translate(client, "book", TranslateOptions{}); err != nil || hit {
+		t.Fatalf("Expected a miss, got hit=%v err=%v", hit, err)
+	}
+	response, hit, err := cache.Translate(client, " book\n", TranslateOptions{})
+	if err != nil || !hit || response.Translations[0].Meaning != "書" || response.Usage.PromptTokens != 0 {
+		t.Fatalf("Expected a hit without token usage, got %+v hit=%v err=%v", response, hit, err)
+	}
+	if client.calls != 1 {
+		t.Errorf("Expected 1 OpenAI call, got %d", client.calls)
+	}
+
+	// 不同模型、bypass 與長句都直接呼叫 OpenAI
+	cache.Translate(client, "book", TranslateOptions{Model: "gpt-5"})
+	cache.Translate(client, "book", TranslateOptions{BypassCache: true})
+	cache.Translate(client, "I would like to book a table", TranslateOptions{})
+	if client.calls != 4 {
+		t.Errorf("Expected 4 OpenAI calls, got %d", client.calls)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, hit, _ := cache.Translate(client, "book", TranslateOptions{}); hit {
+		t.Error("Expected an expired entry to be refreshed")
+	}
+
+	if err := cache.Forget(" book "); err != nil || len(repo.entries) != 0 {
+		t.Errorf("Expected every variant to be forgotten, got %d entries (err %v)", len(repo.entries), err)
+	}
+
+	repo.err = errors.New("throttled")
+	if _, hit, err := cache.Translate(client, "apple", TranslateOptions{}); err != nil || hit {
+		t.Errorf("Expected cache errors to fall back to OpenAI, got hit=%v err=%v", hit, err)
+	}
+
+	var disabled *TranslationCache
+	if _, hit, err := disabled.Translate(client, "book", TranslateOptions{}); err != nil || hit {
+		t.Errorf("Expected a nil cache to call OpenAI, got hit=%v err=%v", hit, err)
+	}
+}
+
+func TestLoadTranslationCacheTTL(t *testing.T) {
+	cases := map[string]time.Duration{"": DefaultTranslationCacheTTLHours * time.Hour, "0": 0, "12": 12 * time.Hour}
+	for value, expected := range cases {
+		ttl, err := LoadTranslationCacheTTL(func(string) string { return value })
+		if err != nil || ttl != expected {
+			t.Errorf("LoadTranslationCacheTTL(%q) = %v (err %v), expected %v", value, ttl, err, expected)
+		}
+	}
+	if _, err := LoadTranslationCacheTTL(func(string) string { return "-1" }); err == nil {
+		t.Error("Expected an error for a negative ttl")
+	}
+}
+
+func TestTranslationCacheTextKeepsCaseAndPunctuation(t *testing.T) {
+	for _, pair := range [][2]string{{"US", "us"}, {"May", "may"}, {"what?", "what"}, {"Polish", "polish"}} {
+		if TranslationCacheText(pair[0]) == TranslationCacheText(pair[1]) {
+			t.Errorf("Expected %q and %q to use different cache keys", pair[0], pair[1])
+		}
+	}
+	if key := TranslationCacheText("  ice   cream "); key != "ice cream" {
+		t.Errorf("Expected whitespace to be collapsed, got %q", key)
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultTTSVoice 未設定 TTS_VOICE 時使用的發音聲線
+const DefaultTTSVoice = "alloy"
+
+// TTSAPI synthesizes spoken audio (MP3) for a piece of text
+type TTSAPI interface {
+	Synthesize(text, voice string) ([]byte, error)
+}
+
+// OpenAITTSClient uses the OpenAI audio/speech endpoint; safe for concurrent use
+type OpenAITTSClient struct {
+	client *openai.Client
+}
+
+func NewOpenAITTSClient(apiKey string, baseUrl string) TTSAPI {
+	config := openai.DefaultConfig(apiKey)
+	config.BaseURL = baseUrl
+	return &OpenAITTSClient{client: openai.NewClientWithConfig(config)}
+}
+
+func (c *OpenAITTSClient) Synthesize(text, voice string) ([]byte, error) {
+	resp, err := c.client.CreateSpeech(context.Background(), openai.CreateSpeechRequest{
+		Model:          openai.TTSModel1,
+		Input:          text,
+		Voice:          openai.SpeechVoice(voice),
+		ResponseFormat: openai.SpeechResponseFormatMp3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI speech API error: %w", err)
+	}
+	defer resp.Close()
+
+	audio, err := io.ReadAll(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read speech audio: %w", err)
+	}
+	return audio, nil
+}
+
+// EstimateSpeechDuration LINE 語音訊息必須附上長度；快取命中時沒有音檔內容，
+// 因此依字數估算單字發音的長度（約 0.6 秒加上每個字母 80 毫秒，最長 5 秒）
+func EstimateSpeechDuration(text string) time.Duration {
+	duration := 600*time.Millisecond + time.Duration(utf8.RuneCountInString(NormalizeAudioText(text)))*80*time.Millisecond
+	if duration > 5*time.Second {
+		return 5 * time.Second
+	}
+	return duration
+}
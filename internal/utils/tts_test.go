@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateSpeechDuration(t *testing.T) {
+	if got := EstimateSpeechDuration("Cat!"); got != 840*time.Millisecond {
+		t.Errorf("Expected 840ms for a three-letter word, got %v", got)
+	}
+	if got := EstimateSpeechDuration("pneumonoultramicroscopicsilicovolcanoconiosis and a few more words"); got != 5*time.Second {
+		t.Errorf("Expected duration to be capped at 5s, got %v", got)
+	}
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"fmt"
+	"language-assistant/internal/courses"
+	"time"
+)
+
+// VocabularyBackfillResult summarizes the words a backfill added to a user's dedup store
+type VocabularyBackfillResult struct {
+	UserID          string `json:"userId"`
+	Course          string `json:"course"`
+	VocabularyWords int    `json:"vocabularyWords"` // 單字庫中屬於該課程語言的不重複單字數
+	HistoryAdded    int    `json:"historyAdded"`    // 新寫入單字歷史的單字數（已在歷史中的不覆寫）
+	BloomAdded      int    `json:"bloomAdded"`      // 新加入 bloom filter 的單字數
+}
+
+// VocabularyBackfiller seeds the pushed-word dedup store (word history and bloom filter) with the words a user
+// already has in their vocabulary, so words learned through translation before the dedup store existed aren't
+// pushed again. Safe for concurrent use, but concurrent backfills of the same user race on the bloom filter save
+type VocabularyBackfiller struct {
+	vocabularyRepo  VocabularyRepository
+	wordHistoryRepo WordHistoryRepository
+	bloomFilterRepo BloomFilterRepository
+}
+
+func NewVocabularyBackfiller(vocabularyRepo VocabularyRepository, wordHistoryRepo WordHistoryRepository, bloomFilterRepo BloomFilterRepository) *VocabularyBackfiller {
+	return &VocabularyBackfiller{
+		vocabularyRepo:  vocabularyRepo,
+		wordHistoryRepo: wordHistoryRepo,
+		bloomFilterRepo: bloomFilterRepo,
+	}
+}
+
+// Backfill 將單字庫中屬於該課程語言的單字加入單字歷史與 bloom filter。
+// 單字歷史以 band 記錄（通常是用戶目前的級距），推播時間為加入單字庫的日期；已在歷史中的單字保留原本的紀錄。
+// 可重複執行，dryRun 時只計算會新增的單字數
+func (b *VocabularyBackfiller) Backfill(userID, course, band string, dryRun bool) (*VocabularyBackfillResult, error) {
+	vocabularies, err := b.vocabularyRepo.GetAllUserVocabularies(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vocabulary: %w", err)
+	}
+	history, _, err := b.wordHistoryRepo.GetPushedWords(userID, course, "", 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load word history: %w", err)
+	}
+	filter, err := b.bloomFilterRepo.GetBloomFilter(userID, course)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bloom filter: %w", err)
+	}
+
+	result := &VocabularyBackfillResult{UserID: userID, Course: course}
+	seen := map[string]bool{}
+	newHistory := map[string][]string{} // 加入單字庫的日期 → 尚未在歷史中的單字
+	for _, vocabulary := range vocabularies {
+		for _, record := range vocabulary.Words {
+			key := NormalizeHistoryWord(record.Word)
+			if key == "" || seen[key] || !matchesCourseLanguage(record.Word, course) {
+				continue
+			}
+			seen[key] = true
+			result.VocabularyWords++
+
+			if !history[key] {
+				newHistory[vocabulary.Date] = append(newHistory[vocabulary.Date], record.Word)
+				result.HistoryAdded++
+			}
+			// bloom filter 以原始單字比對，與重建時相同，原始大小寫與小寫版本都加入
+			added := false
+			for _, word := range []string{record.Word, key} {
+				if !filter.Contains(word) {
+					filter.Add(word)
+					added = true
+				}
+			}
+			if added {
+				result.BloomAdded++
+			}
+		}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	for date, words := range newHistory {
+		learnedAt, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			learnedAt = time.Now()
+		}
+		if err := b.wordHistoryRepo.RecordPushedWords(userID, course, band, words, learnedAt); err != nil {
+			return nil, fmt.Errorf("failed to record word history: %w", err)
+		}
+	}
+	if result.BloomAdded > 0 {
+		if err := b.bloomFilterRepo.SaveBloomFilter(filter, course); err != nil {
+			return nil, fmt.Errorf("failed to save bloom filter: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// matchesCourseLanguage 單字庫不分課程，只回填與課程同語言的單字：日文課程為含假名或漢字的單字，其他課程為不含的單字
+func matchesCourseLanguage(word, course string) bool {
+	japanese := ContainsKana(word) || containsRune(word, isHan)
+	if c, ok := courses.Get(course); ok && c.Language == courses.LanguageJapanese {
+		return japanese
+	}
+	return !japanese
+}
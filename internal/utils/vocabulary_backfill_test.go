@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"sort"
+	"testing"
+	"time"
+)
+
+type stubVocabulary struct {
+	VocabularyRepository
+	vocabularies []models.UserVocabulary
+}
+
+func (s stubVocabulary) GetAllUserVocabularies(userID string) ([]models.UserVocabulary, error) {
+	return s.vocabularies, nil
+}
+
+// recordingWordHistory 回傳既有的歷史，並記錄每次寫入的單字與時間
+type recordingWordHistory struct {
+	stubWordHistory
+	recorded map[string][]string // pushedAt 日期 → 單字
+	band     string
+}
+
+func (r *recordingWordHistory) RecordPushedWords(userID, course, band string, words []string, pushedAt time.Time) error {
+	r.recorded[pushedAt.Format("2006-01-02")] = append(r.recorded[pushedAt.Format("2006-01-02")], words...)
+	r.band = band
+	return nil
+}
+
+type storedBloomFilters struct {
+	BloomFilterRepository
+	filter *models.BloomFilter
+	saves  int
+}
+
+func (s *storedBloomFilters) GetBloomFilter(userID, course string) (*models.BloomFilter, error) {
+	return s.filter, nil
+}
+
+func (s *storedBloomFilters) SaveBloomFilter(filter *models.BloomFilter, course string) error {
+	s.filter = filter
+	s.saves++
+	return nil
+}
+
+func TestVocabularyBackfill(t *testing.T) {
+	vocabulary := stubVocabulary{vocabularies: []models.UserVocabulary{
+		{Date: "2024-03-01", Words: []models.WordRecord{{Word: "Resilient"}, {Word: "abundant"}, {Word: "食べる"}}},
+		{Date: "2024-03-02", Words: []models.WordRecord{{Word: "resilient"}, {Word: "meticulous"}}},
+	}}
+	newBackfiller := func() (*VocabularyBackfiller, *recordingWordHistory, *storedBloomFilters) {
+		existing := models.NewCountingBloomFilter("U1")
+		existing.Add("meticulous")
+		history := &recordingWordHistory{stubWordHistory: stubWordHistory{"meticulous": true}, recorded: map[string][]string{}}
+		bloom := &storedBloomFilters{filter: existing}
+		return NewVocabularyBackfiller(vocabulary, history, bloom), history, bloom
+	}
+
+	backfiller, history, bloom := newBackfiller()
+	dryRun, err := backfiller.Backfill("U1", "toeic", "B1", true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history.recorded) != 0 || bloom.saves != 0 {
+		t.Fatalf("Dry run should not write, got %d history dates and %d saves", len(history.recorded), bloom.saves)
+	}
+
+	backfiller, history, bloom = newBackfiller()
+	result, err := backfiller.Backfill("U1", "toeic", "B1", false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *result != *dryRun {
+		t.Errorf("Expected dry run to report %+v, got %+v", result, dryRun)
+	}
+	if result.VocabularyWords != 3 || result.HistoryAdded != 2 || result.BloomAdded != 2 {
+		t.Errorf("Unexpected backfill result: %+v", result)
+	}
+
+	words := history.recorded["2024-03-01"]
+	sort.Strings(words)
+	if len(history.recorded) != 1 || len(words) != 2 || words[0] != "Resilient" || words[1] != "abundant" || history.band != "B1" {
+		t.Errorf("Expected Resilient and abundant recorded on 2024-03-01 at B1, got %v at %q", history.recorded, history.band)
+	}
+	for _, word := range []string{"Resilient", "resilient", "abundant", "meticulous"} {
+		if !bloom.filter.Contains(word) {
+			t.Errorf("Expected backfilled filter to contain %q", word)
+		}
+	}
+	if bloom.filter.Contains("食べる") {
+		t.Errorf("Japanese words should not be backfilled into an English course")
+	}
+}
+
+func TestMatchesCourseLanguage(t *testing.T) {
+	tests := []struct {
+		word   string
+		course string
+		want   bool
+	}{
+		{"resilient", "toeic", true},
+		{"食べる", "toeic", false},
+		{"食べる", "japanese", true},
+		{"勉強", "japanese", true},
+		{"resilient", "japanese", false},
+	}
+	for _, tt := range tests {
+		if got := matchesCourseLanguage(tt.word, tt.course); got != tt.want {
+			t.Errorf("matchesCourseLanguage(%q, %q) = %v, want %v", tt.word, tt.course, got, tt.want)
+		}
+	}
+}
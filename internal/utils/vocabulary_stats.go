@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TopPartsOfSpeechCount /統計 顯示的常見詞性數量
+const TopPartsOfSpeechCount = 3
+
+// partOfSpeechAliases 將 OpenAI 回傳的不同寫法統一成縮寫，避免同一詞性被分開計算
+var partOfSpeechAliases = map[string]string{
+	"n": "n.", "noun": "n.",
+	"v": "v.", "verb": "v.",
+	"adj": "adj.", "adjective": "adj.",
+	"adv": "adv.", "adverb": "adv.",
+	"prep": "prep.", "preposition": "prep.",
+	"conj": "conj.", "conjunction": "conj.",
+	"pron": "pron.", "pronoun": "pron.",
+}
+
+func normalizePartOfSpeech(partOfSpeech string) string {
+	key := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(partOfSpeech)), ".")
+	if alias, ok := partOfSpeechAliases[key]; ok {
+		return alias
+	}
+	return strings.TrimSpace(partOfSpeech)
+}
+
+// CalculateVocabularyStats aggregates the user's saved words and answered quiz questions. Dates are YYYY-MM-DD in
+// UTC like VocabularyRepository stores them, so weeks and months are UTC too
+func CalculateVocabularyStats(vocabularies []models.UserVocabulary, sessions []models.QuizSession, now time.Time) models.VocabularyStats {
+	var stats models.VocabularyStats
+
+	today, _ := time.Parse("2006-01-02", now.UTC().Format("2006-01-02"))
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var first time.Time
+	partsOfSpeech := map[string]int{}
+	for _, vocabulary := range vocabularies {
+		if len(vocabulary.Words) == 0 {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", vocabulary.Date)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+
+		stats.TotalWords += len(vocabulary.Words)
+		if !day.Before(weekStart) {
+			stats.WeekWords += len(vocabulary.Words)
+		}
+		if !day.Before(monthStart) {
+			stats.MonthWords += len(vocabulary.Words)
+		}
+		for _, word := range vocabulary.Words {
+			if partOfSpeech := normalizePartOfSpeech(word.PartOfSpeech); partOfSpeech != "" {
+				partsOfSpeech[partOfSpeech]++
+			}
+		}
+	}
+
+	if !first.IsZero() {
+		days := int(today.Sub(first).Hours()/24) + 1
+		if days < 1 {
+			days = 1
+		}
+		stats.AveragePerDay = float64(stats.TotalWords) / float64(days)
+	}
+
+	for partOfSpeech, count := range partsOfSpeech {
+		stats.TopPartsOfSpeech = append(stats.TopPartsOfSpeech, models.PartOfSpeechCount{PartOfSpeech: partOfSpeech, Count: count})
+	}
+	sort.Slice(stats.TopPartsOfSpeech, func(i, j int) bool {
+		a, b := stats.TopPartsOfSpeech[i], stats.TopPartsOfSpeech[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.PartOfSpeech < b.PartOfSpeech
+	})
+	if len(stats.TopPartsOfSpeech) > TopPartsOfSpeechCount {
+		stats.TopPartsOfSpeech = stats.TopPartsOfSpeech[:TopPartsOfSpeechCount]
+	}
+
+	// 進行中的測驗也計入已作答的題目
+	for _, session := range sessions {
+		for _, question := range session.Questions {
+			if !question.Answered() {
+				continue
+			}
+			stats.QuizAnswered++
+			if question.Choice == question.Answer {
+				stats.QuizCorrect++
+			}
+		}
+	}
+
+	return stats
+}
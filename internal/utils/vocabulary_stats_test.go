@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"language-assistant/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalculateVocabularyStats(t *testing.T) {
+	// 2026-10-15 是星期四，本週從 10-12 開始
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	vocabularies := []models.UserVocabulary{
+		{Date: "2026-09-26", Words: []models.WordRecord{{Word: "budget", PartOfSpeech: "n."}, {Word: "approve", PartOfSpeech: "verb"}}},
+		{Date: "2026-10-02", Words: []models.WordRecord{{Word: "deliver", PartOfSpeech: "v."}}},
+		{Date: "2026-10-12", Words: []models.WordRecord{{Word: "evidence", PartOfSpeech: "noun"}, {Word: "reliable", PartOfSpeech: "adj."}}},
+		{Date: "2026-10-15", Words: []models.WordRecord{{Word: "obstacle", PartOfSpeech: "N"}, {Word: "ubiquitous", PartOfSpeech: "adj"}, {Word: "開心"}}},
+	}
+	sessions := []models.QuizSession{
+		{Status: models.QuizStatusCompleted, Questions: []models.QuizQuestion{{Answer: 1, Choice: 1}, {Answer: 0, Choice: 2}, {Answer: 3, Choice: 3}}},
+		{Status: models.QuizStatusActive, Questions: []models.QuizQuestion{{Answer: 2, Choice: 2}, {Answer: 1, Choice: -1}}},
+	}
+
+	stats := CalculateVocabularyStats(vocabularies, sessions, now)
+	if stats.TotalWords != 8 || stats.WeekWords != 5 || stats.MonthWords != 6 {
+		t.Errorf("Unexpected totals %+v", stats)
+	}
+	// 09-26 到 10-15 共 20 天
+	if stats.AveragePerDay != 0.4 {
+		t.Errorf("AveragePerDay = %v, want 0.4", stats.AveragePerDay)
+	}
+	want := []models.PartOfSpeechCount{{PartOfSpeech: "n.", Count: 3}, {PartOfSpeech: "adj.", Count: 2}, {PartOfSpeech: "v.", Count: 2}}
+	if !reflect.DeepEqual(stats.TopPartsOfSpeech, want) {
+		t.Errorf("TopPartsOfSpeech = %+v, want %+v", stats.TopPartsOfSpeech, want)
+	}
+	if stats.QuizAnswered != 4 || stats.QuizCorrect != 3 || stats.QuizAccuracy() != 75 {
+		t.Errorf("Unexpected quiz stats %d/%d (%d%%)", stats.QuizCorrect, stats.QuizAnswered, stats.QuizAccuracy())
+	}
+
+	if empty := CalculateVocabularyStats(nil, nil, now); empty.TotalWords != 0 || empty.AveragePerDay != 0 || empty.QuizAccuracy() != 0 {
+		t.Errorf("Expected empty stats, got %+v", empty)
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WebhookCapturePrefix 擷取的 webhook payload 存放位置（S3 lifecycle 會自動清除）
+const WebhookCapturePrefix = "webhooks/"
+
+// PseudonymizeID replaces a LINE user / group / room ID with a stable pseudonym of the same shape
+// (one letter + 32 hex chars), so captured payloads stay linkable without exposing the real ID
+func PseudonymizeID(id string) string {
+	if id == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(id))
+	return id[:1] + hex.EncodeToString(sum[:16])
+}
+
+// WebhookCaptureKey 回傳擷取檔的 S3 key，依用戶分資料夾、以時間排序，replay 時可直接依檔名順序重播
+func WebhookCaptureKey(userID string, at time.Time) string {
+	return fmt.Sprintf("%s%s/%s.json", WebhookCapturePrefix, PseudonymizeID(userID), at.UTC().Format("20060102T150405.000000000Z"))
+}
+
+// SanitizeWebhookBody keeps only the events whose source user passes keep, drops reply tokens
+// and pseudonymizes source IDs. Message text and postback data are kept so the conversation can be replayed.
+// Returns nil when no events are kept
+func SanitizeWebhookBody(body []byte, keep func(userID string) bool) ([]byte, error) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook body: %w", err)
+	}
+
+	rawEvents, _ := payload["events"].([]interface{})
+	kept := []interface{}{}
+	for _, rawEvent := range rawEvents {
+		event, ok := rawEvent.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		source, _ := event["source"].(map[string]interface{})
+		userID, _ := source["userId"].(string)
+		if userID == "" || !keep(userID) {
+			continue
+		}
+
+		// reply token 只能使用一次，留著也無法重播，且可能被拿來冒用回覆
+		delete(event, "replyToken")
+		for _, field := range []string{"userId", "groupId", "roomId"} {
+			if id, ok := source[field].(string); ok {
+				source[field] = PseudonymizeID(id)
+			}
+		}
+		kept = append(kept, event)
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+
+	payload["events"] = kept
+	delete(payload, "destination") // bot 的 user ID，重播到其他環境時不需要
+	return json.Marshal(payload)
+}
+
+// SignWebhookBody 以 channel secret 計算 X-Line-Signature，重播時讓 handler 的簽章驗證通過
+func SignWebhookBody(channelSecret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(channelSecret))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
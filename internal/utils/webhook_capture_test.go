@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeWebhookBody(t *testing.T) {
+	body := []byte(`{"destination":"Ubot","events":[
+		{"type":"message","replyToken":"token-1","source":{"type":"user","userId":"Uflagged"},"message":{"type":"text","text":"/測驗"}},
+		{"type":"message","replyToken":"token-2","source":{"type":"user","userId":"Uother"},"message":{"type":"text","text":"hello"}}
+	]}`)
+
+	sanitized, err := SanitizeWebhookBody(body, func(userID string) bool { return userID == "Uflagged" })
+	if err != nil {
+		t.Fatalf("SanitizeWebhookBody() error = %v", err)
+	}
+
+	got := string(sanitized)
+	for _, leaked := range []string{"Uflagged", "Uother", "token-1", "Ubot", "hello"} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("Sanitized body should not contain %q: %s", leaked, got)
+		}
+	}
+
+	var payload struct {
+		Events []struct {
+			Source  map[string]string `json:"source"`
+			Message map[string]string `json:"message"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(sanitized, &payload); err != nil {
+		t.Fatalf("Failed to parse sanitized body: %v", err)
+	}
+	if len(payload.Events) != 1 || payload.Events[0].Message["text"] != "/測驗" {
+		t.Fatalf("Expected only the flagged user's event, got %s", got)
+	}
+	if userID := payload.Events[0].Source["userId"]; userID != PseudonymizeID("Uflagged") || len(userID) != 33 {
+		t.Errorf("Expected pseudonymized user ID, got %q", userID)
+	}
+
+	if none, err := SanitizeWebhookBody(body, func(string) bool { return false }); err != nil || none != nil {
+		t.Errorf("Expected nil when no events are kept, got %s (err %v)", none, err)
+	}
+}
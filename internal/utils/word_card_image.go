@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// LINE 圖片訊息建議使用正方形，1040px 在手機上清晰且檔案不大
+const (
+	wordCardSize    = 1040
+	wordCardPadding = 80
+)
+
+var (
+	wordCardBackground = color.RGBA{0xF5, 0xF7, 0xFA, 0xFF}
+	wordCardAccent     = color.RGBA{0x3B, 0x5B, 0xDB, 0xFF}
+	wordCardText       = color.RGBA{0x22, 0x22, 0x22, 0xFF}
+	wordCardSubtle     = color.RGBA{0x77, 0x77, 0x77, 0xFF}
+
+	// 與文字版的 🟢/🟡/🔴 燈號一致
+	wordCardDifficultyColors = map[DifficultyBand]color.RGBA{
+		DifficultyEasy:   {0x2F, 0x9E, 0x44, 0xFF},
+		DifficultyMedium: {0xF0, 0xB4, 0x00, 0xFF},
+		DifficultyHard:   {0xE0, 0x31, 0x31, 0xFF},
+	}
+)
+
+// WordCardRenderer draws a daily word as a PNG card (word large, meaning, example).
+// The font must cover Traditional Chinese and kana (for the Japanese course), e.g. Noto Sans TC shipped in a Lambda layer.
+// Safe for concurrent use: font faces keep glyph buffers, so Render calls are serialized.
+type WordCardRenderer struct {
+	mu          sync.Mutex
+	wordFace    font.Face
+	headingFace font.Face
+	bodyFace    font.Face
+}
+
+// LoadWordCardRenderer reads an OpenType/TrueType font (or the first font of a collection) from disk
+func LoadWordCardRenderer(fontPath string) (*WordCardRenderer, error) {
+	data, err := os.ReadFile(fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read card font: %w", err)
+	}
+	return NewWordCardRenderer(data)
+}
+
+func NewWordCardRenderer(fontData []byte) (*WordCardRenderer, error) {
+	parsed, err := opentype.Parse(fontData)
+	if err != nil {
+		collection, collectionErr := opentype.ParseCollection(fontData)
+		if collectionErr != nil {
+			return nil, fmt.Errorf("failed to parse card font: %w", err)
+		}
+		if parsed, err = collection.Font(0); err != nil {
+			return nil, fmt.Errorf("failed to load font from collection: %w", err)
+		}
+	}
+
+	newFace := func(size float64) (font.Face, error) {
+		return opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	}
+
+	renderer := &WordCardRenderer{}
+	if renderer.wordFace, err = newFace(96); err != nil {
+		return nil, err
+	}
+	if renderer.headingFace, err = newFace(48); err != nil {
+		return nil, err
+	}
+	if renderer.bodyFace, err = newFace(40); err != nil {
+		return nil, err
+	}
+	return renderer, nil
+}
+
+// Render draws the word card and returns it PNG-encoded
+func (r *WordCardRenderer) Render(word Word, index, total int) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	img := image.NewRGBA(image.Rect(0, 0, wordCardSize, wordCardSize))
+	draw.Draw(img, img.Bounds(), &image.Uniform{wordCardBackground}, image.Point{}, draw.Src)
+	// 頂部色條依難度上色，未知難度使用主色
+	accent, ok := wordCardDifficultyColors[DifficultyOf(word.Difficulty)]
+	if !ok {
+		accent = wordCardAccent
+	}
+	draw.Draw(img, image.Rect(0, 0, wordCardSize, 16), &image.Uniform{accent}, image.Point{}, draw.Src)
+
+	maxWidth := wordCardSize - 2*wordCardPadding
+	y := wordCardPadding + 40
+
+	progress := fmt.Sprintf("%d / %d", index, total)
+	if cefr := NormalizeCEFR(word.Difficulty); cefr != "" {
+		progress += "  ·  " + cefr
+	}
+	y = r.drawLines(img, r.bodyFace, accent, progress, maxWidth, y)
+	y += 40
+	y = r.drawLines(img, r.wordFace, wordCardAccent, word.Word, maxWidth, y+40)
+	if kana := DisplayReading(word.Word, word.Reading); kana != "" {
+		y = r.drawLines(img, r.bodyFace, wordCardText, kana, maxWidth, y+10)
+	}
+	if word.PartOfSpeech != "" {
+		y = r.drawLines(img, r.bodyFace, wordCardSubtle, word.PartOfSpeech, maxWidth, y+10)
+	}
+	y += 40
+	y = r.drawLines(img, r.headingFace, wordCardText, word.Meaning, maxWidth, y)
+	y += 60
+	y = r.drawLines(img, r.bodyFace, wordCardText, word.Example.En, maxWidth, y)
+	y = r.drawLines(img, r.bodyFace, wordCardSubtle, word.Example.Zh, maxWidth, y+10)
+	r.drawLines(img, r.bodyFace, wordCardAccent, FormatExamTags(word.ExamTags), maxWidth, y+30)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode word card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLines wraps text to maxWidth and draws it starting at baseline y, returning the next baseline
+func (r *WordCardRenderer) drawLines(img draw.Image, face font.Face, c color.Color, text string, maxWidth, y int) int {
+	if strings.TrimSpace(text) == "" {
+		return y
+	}
+
+	lineHeight := face.Metrics().Height.Ceil() * 5 / 4
+	drawer := &font.Drawer{Dst: img, Src: &image.Uniform{c}, Face: face}
+	for i, line := range wrapByWidth(text, func(s string) int { return drawer.MeasureString(s).Ceil() }, maxWidth) {
+		if i > 0 {
+			y += lineHeight
+		}
+		// 超出卡片範圍的內容直接省略
+		if y > wordCardSize-wordCardPadding/2 {
+			break
+		}
+		drawer.Dot = fixed.P(wordCardPadding, y)
+		drawer.DrawString(line)
+	}
+	return y + lineHeight
+}
+
+// wrapByWidth breaks text into lines no wider than maxWidth, preferring spaces and
+// falling back to per-character breaks for CJK text
+func wrapByWidth(text string, measure func(string) int, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		runes := []rune(paragraph)
+		start := 0
+		for start < len(runes) {
+			end := len(runes)
+			for end > start+1 && measure(string(runes[start:end])) > maxWidth {
+				end--
+			}
+			if end < len(runes) {
+				if space := strings.LastIndex(string(runes[start:end]), " "); space > 0 {
+					end = start + len([]rune(string(runes[start:end])[:space]))
+				}
+			}
+			lines = append(lines, strings.TrimSpace(string(runes[start:end])))
+			start = end
+			for start < len(runes) && runes[start] == ' ' {
+				start++
+			}
+		}
+	}
+	return lines
+}
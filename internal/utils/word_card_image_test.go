@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestWordCardRenderer(t *testing.T) {
+	renderer, err := NewWordCardRenderer(goregular.TTF)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	card, err := renderer.Render(Word{
+		Word:         "postpone",
+		PartOfSpeech: "v.",
+		Meaning:      "to delay an event until a later time",
+		Example:      Example{En: strings.Repeat("They postponed the meeting again. ", 5), Zh: "They delayed it."},
+		Difficulty:   "B2",
+		ExamTags:     []string{"TOEIC Part 5"},
+	}, 1, 10)
+	if err != nil {
+		t.Fatalf("Unexpected render error: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(card))
+	if err != nil {
+		t.Fatalf("Rendered card is not a valid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != wordCardSize || img.Bounds().Dy() != wordCardSize {
+		t.Errorf("Unexpected card size %v", img.Bounds())
+	}
+}
+
+func TestWordCardRendererConcurrentRender(t *testing.T) {
+	renderer, err := NewWordCardRenderer(goregular.TTF)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 字型 face 內部有 glyph buffer，同一個 renderer 被多個推播共用時不可產生 data race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := renderer.Render(Word{Word: "postpone", Meaning: "to delay"}, i+1, 4); err != nil {
+				t.Errorf("Unexpected render error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestWrapByWidth(t *testing.T) {
+	// 每個字元寬度 1
+	measure := func(s string) int { return len([]rune(s)) }
+
+	got := wrapByWidth("the quick brown fox", measure, 10)
+	expected := []string{"the quick", "brown fox"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	got = wrapByWidth("今天天氣很好我們去散步", measure, 4)
+	if len(got) != 3 || got[0] != "今天天氣" {
+		t.Errorf("Unexpected CJK wrap: %v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"language-assistant/internal/courses"
+	"language-assistant/internal/models"
+	"net/url"
+	"strings"
+)
+
+// 程度級距（由低到高），以 CEFR 表示；跨考試共用，字串順序即為高低順序。
+// 各考試進入 B1、B2、C1 的分數門檻設定在 courses.yaml 的 band_thresholds
+var levelBands = []string{"A2", "B1", "B2", "C1"}
+
+// MaxExactWordHistory 精確比對時最多載入的歷史單字數，超過時改用 bloom filter 以免每次推播都讀取大量 item
+const MaxExactWordHistory = 5000
+
+// NormalizeHistoryWord 單字歷史以小寫比對，避免 "Resilient" 與 "resilient" 被視為不同單字
+func NormalizeHistoryWord(word string) string {
+	return strings.ToLower(strings.TrimSpace(word))
+}
+
+// FilterUnseenWords 移除已推播過的單字，以及同一批內重複的單字
+func FilterUnseenWords(words []Word, seen map[string]bool) []Word {
+	picked := map[string]bool{}
+	var result []Word
+	for _, word := range words {
+		key := NormalizeHistoryWord(word.Word)
+		if key == "" || seen[key] || picked[key] {
+			continue
+		}
+		picked[key] = true
+		result = append(result, word)
+	}
+	return result
+}
+
+// LevelBand 依考試分數回傳程度級距，分數尚未設定或課程未知時回傳空字串
+func LevelBand(course string, level int) string {
+	c, ok := courses.Get(course)
+	if !ok || level <= 0 {
+		return ""
+	}
+
+	band := 0
+	for _, threshold := range c.BandThresholds {
+		if level >= threshold {
+			band++
+		}
+	}
+	return levelBands[band]
+}
+
+// CompareLevelBands 比較兩個級距的高低，a 較低時回傳負數
+func CompareLevelBands(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// ResetWordHistoryPostbackData 清除單字推播紀錄確認按鈕的 postback data，confirm 為 false 時代表取消
+func ResetWordHistoryPostbackData(course string, confirm bool) string {
+	values := url.Values{}
+	values.Set("action", models.PostbackResetWordHistory)
+	values.Set("course", course)
+	if confirm {
+		values.Set("confirm", "1")
+	}
+	return values.Encode()
+}
@@ -0,0 +1,39 @@
+package utils
+
+import "testing"
+
+func TestFilterUnseenWords(t *testing.T) {
+	words := []Word{{Word: "Resilient"}, {Word: "abundant"}, {Word: "Abundant "}, {Word: "meticulous"}, {Word: " "}}
+	seen := map[string]bool{"resilient": true}
+
+	result := FilterUnseenWords(words, seen)
+	if len(result) != 2 || result[0].Word != "abundant" || result[1].Word != "meticulous" {
+		t.Errorf("Expected seen, duplicate and blank words to be removed, got %v", result)
+	}
+}
+
+func TestLevelBand(t *testing.T) {
+	tests := []struct {
+		course string
+		level  int
+		want   string
+	}{
+		{"toeic", 0, ""},
+		{"toeic", 450, "A2"},
+		{"toeic", 600, "B1"},
+		{"toeic", 850, "B2"},
+		{"toeic", 990, "C1"},
+		{"ielts", 55, "B2"},
+		{"ielts", 35, "A2"},
+		{"unknown", 600, ""},
+	}
+	for _, tt := range tests {
+		if got := LevelBand(tt.course, tt.level); got != tt.want {
+			t.Errorf("LevelBand(%q, %d) = %q, want %q", tt.course, tt.level, got, tt.want)
+		}
+	}
+
+	if CompareLevelBands("B1", "B2") >= 0 || CompareLevelBands("C1", "A2") <= 0 {
+		t.Errorf("Expected bands to compare from low to high")
+	}
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"language-assistant/internal/models"
+)
+
+// ErrInvalidWordPushPayload is returned when a word push payload fails validation
+var ErrInvalidWordPushPayload = errors.New("invalid word push payload")
+
+// WordPushOptions are the optional per-invocation overrides of a word push payload
+type WordPushOptions struct {
+	DryRun    bool
+	WordCount int // 0 表示使用用戶設定的每日單字數
+}
+
+// NewWordPushPayload 產生目前版本的推播 payload；排程與直接 invoke 都必須透過這裡產生，避免兩邊格式分歧
+func NewWordPushPayload(userID, source string) ([]byte, error) {
+	return NewWordPushPayloadWithOptions(userID, source, WordPushOptions{})
+}
+
+// NewWordPushPayloadWithOptions 同 NewWordPushPayload，並帶上 dry run 或單字數覆寫
+func NewWordPushPayloadWithOptions(userID, source string, opts WordPushOptions) ([]byte, error) {
+	return json.Marshal(models.WordPushPayload{
+		Version:   models.WordPushPayloadVersion,
+		UserID:    userID,
+		Source:    source,
+		DryRun:    opts.DryRun,
+		WordCount: opts.WordCount,
+	})
+}
+
+// ParseWordPushPayload 解析並驗證推播 payload。
+// 接受沒有 version 的舊 payload（已建立的排程仍帶著舊格式），未知欄位會被忽略；
+// 比目前支援的版本還新的 payload 會被拒絕，代表 language-vocabulary 需要先部署
+func ParseWordPushPayload(raw []byte) (models.WordPushPayload, error) {
+	var payload models.WordPushPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return models.WordPushPayload{}, fmt.Errorf("%w: %v", ErrInvalidWordPushPayload, err)
+	}
+
+	if payload.Version < 0 || payload.Version > models.WordPushPayloadVersion {
+		return models.WordPushPayload{}, fmt.Errorf("%w: unsupported version %d (supported up to %d)", ErrInvalidWordPushPayload, payload.Version, models.WordPushPayloadVersion)
+	}
+	if payload.UserID == "" {
+		return models.WordPushPayload{}, fmt.Errorf("%w: userId is required", ErrInvalidWordPushPayload)
+	}
+	if payload.WordCount < 0 || payload.WordCount > models.MaxWordPushWordCount {
+		return models.WordPushPayload{}, fmt.Errorf("%w: wordCount must be between 1 and %d", ErrInvalidWordPushPayload, models.MaxWordPushWordCount)
+	}
+	if payload.Version == 0 {
+		payload.Version = 1
+	}
+	if payload.Source == "" {
+		// 舊 payload 只會來自排程或設定完成後的立即推播，無法區分時記為排程
+		payload.Source = models.WordPushSourceSchedule
+	}
+	return payload, nil
+}
+
+// Word push event kinds, i.e. how language-vocabulary was invoked
+const (
+	WordPushEventDirect      = "direct"      // EventBridge Scheduler 的 target input 或直接 Lambda invoke，整個 event 就是 payload
+	WordPushEventEventBridge = "eventbridge" // EventBridge 規則送來的事件，payload 在 detail
+	WordPushEventSQS         = "sqs"         // fan-out 模式的 SQS 批次，每則訊息的 body 是一個 payload
+)
+
+// WordPushEvent is a language-vocabulary invocation normalized to a list of raw payloads, so scheduler events,
+// direct invokes and SQS batches are handled the same way. Payloads stay raw: each is validated on its own by
+// ParseWordPushPayload, so one bad SQS message doesn't fail the rest of the batch
+type WordPushEvent struct {
+	Kind    string
+	Records []WordPushRecord
+}
+
+// WordPushRecord is one payload of a WordPushEvent; MessageID is only set for SQS records
+type WordPushRecord struct {
+	MessageID string
+	Payload   json.RawMessage
+}
+
+// wordPushEnvelope 只取出判斷 event 種類需要的欄位；推播 payload 本身沒有這些欄位
+type wordPushEnvelope struct {
+	Records []struct {
+		MessageID   string `json:"messageId"`
+		EventSource string `json:"eventSource"`
+		Body        string `json:"body"`
+	} `json:"Records"`
+	DetailType string          `json:"detail-type"`
+	Detail     json.RawMessage `json:"detail"`
+}
+
+// ParseWordPushEvent 判斷 event 種類並取出其中的推播 payload。
+// 無法辨識的 event 一律視為直接 invoke，格式錯誤交給 ParseWordPushPayload 回報
+func ParseWordPushEvent(raw json.RawMessage) (WordPushEvent, error) {
+	var envelope wordPushEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return WordPushEvent{Kind: WordPushEventDirect, Records: []WordPushRecord{{Payload: raw}}}, nil
+	}
+
+	switch {
+	case len(envelope.Records) > 0:
+		records := make([]WordPushRecord, 0, len(envelope.Records))
+		for _, record := range envelope.Records {
+			if record.EventSource != "aws:sqs" {
+				return WordPushEvent{}, fmt.Errorf("%w: unsupported event source %q", ErrInvalidWordPushPayload, record.EventSource)
+			}
+			records = append(records, WordPushRecord{MessageID: record.MessageID, Payload: json.RawMessage(record.Body)})
+		}
+		return WordPushEvent{Kind: WordPushEventSQS, Records: records}, nil
+	case envelope.DetailType != "" && len(envelope.Detail) > 0:
+		return WordPushEvent{Kind: WordPushEventEventBridge, Records: []WordPushRecord{{Payload: envelope.Detail}}}, nil
+	default:
+		return WordPushEvent{Kind: WordPushEventDirect, Records: []WordPushRecord{{Payload: raw}}}, nil
+	}
+}
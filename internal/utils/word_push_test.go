@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"language-assistant/internal/models"
+	"testing"
+)
+
+func TestParseWordPushPayload(t *testing.T) {
+	current, err := NewWordPushPayload("U1234", models.WordPushSourceSettings)
+	if err != nil {
+		t.Fatalf("Unexpected marshal error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    models.WordPushPayload
+		wantErr bool
+	}{
+		{"Current version", string(current), models.WordPushPayload{Version: 1, UserID: "U1234", Source: models.WordPushSourceSettings}, false},
+		{"Legacy payload without version", `{"userId":"U1234"}`, models.WordPushPayload{Version: 1, UserID: "U1234", Source: models.WordPushSourceSchedule}, false},
+		{"Unknown fields are ignored", `{"version":1,"userId":"U1234","source":"schedule","priority":"high"}`, models.WordPushPayload{Version: 1, UserID: "U1234", Source: models.WordPushSourceSchedule}, false},
+		{"Dry run with word count", `{"version":1,"userId":"U1234","source":"adminctl","dryRun":true,"wordCount":3}`, models.WordPushPayload{Version: 1, UserID: "U1234", Source: models.WordPushSourceAdminctl, DryRun: true, WordCount: 3}, false},
+		{"Word count over limit", `{"version":1,"userId":"U1234","wordCount":21}`, models.WordPushPayload{}, true},
+		{"Negative word count", `{"version":1,"userId":"U1234","wordCount":-1}`, models.WordPushPayload{}, true},
+		{"Newer version rejected", `{"version":2,"userId":"U1234"}`, models.WordPushPayload{}, true},
+		{"Missing userId", `{"version":1}`, models.WordPushPayload{}, true},
+		{"Wrong type", `{"version":"1","userId":"U1234"}`, models.WordPushPayload{}, true},
+		{"Not an object", `["U1234"]`, models.WordPushPayload{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWordPushPayload([]byte(tt.raw))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWordPushPayload) {
+					t.Fatalf("Expected ErrInvalidWordPushPayload, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseWordPushEvent(t *testing.T) {
+	payload := `{"version":1,"userId":"U1234"}`
+
+	tests := []struct {
+		name        string
+		raw         string
+		wantKind    string
+		wantRecords []WordPushRecord
+		wantErr     bool
+	}{
+		{"Direct invoke", payload, WordPushEventDirect, []WordPushRecord{{Payload: json.RawMessage(payload)}}, false},
+		{"EventBridge event", `{"detail-type":"Word Push","source":"language-assistant","detail":` + payload + `}`, WordPushEventEventBridge, []WordPushRecord{{Payload: json.RawMessage(payload)}}, false},
+		{"SQS batch", `{"Records":[{"messageId":"m1","eventSource":"aws:sqs","body":"{\"userId\":\"U1\"}"},{"messageId":"m2","eventSource":"aws:sqs","body":"{\"userId\":\"U2\"}"}]}`,
+			WordPushEventSQS, []WordPushRecord{{MessageID: "m1", Payload: json.RawMessage(`{"userId":"U1"}`)}, {MessageID: "m2", Payload: json.RawMessage(`{"userId":"U2"}`)}}, false},
+		{"Unsupported record source", `{"Records":[{"eventSource":"aws:sns"}]}`, "", nil, true},
+		{"Not an object falls back to direct", `["U1234"]`, WordPushEventDirect, []WordPushRecord{{Payload: json.RawMessage(`["U1234"]`)}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWordPushEvent(json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWordPushPayload) {
+					t.Fatalf("Expected ErrInvalidWordPushPayload, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.Kind != tt.wantKind || len(got.Records) != len(tt.wantRecords) {
+				t.Fatalf("Expected %s event with %d records, got %s with %d", tt.wantKind, len(tt.wantRecords), got.Kind, len(got.Records))
+			}
+			for i, want := range tt.wantRecords {
+				if got.Records[i].MessageID != want.MessageID || string(got.Records[i].Payload) != string(want.Payload) {
+					t.Errorf("Record %d: expected %s %s, got %s %s", i, want.MessageID, want.Payload, got.Records[i].MessageID, got.Records[i].Payload)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,844 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"language-assistant/internal/courses"
+	"language-assistant/internal/messages"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAuditLimit     = 50
+	defaultExperimentDays = 14
+	maxExperimentDays     = 90
+	defaultFunnelDays     = 28
+	maxFunnelDays         = 90
+	defaultRetentionLimit = 12
+	defaultCaptureHours   = 24
+	maxCaptureHours       = 7 * 24
+)
+
+// Handler serves the admin API. Holds no mutable state; safe for concurrent use
+type Handler struct {
+	logger            *logrus.Entry
+	envVars           *EnvVars
+	dynamodbClient    utils.DynamoDbAPI
+	tableSchemas      []utils.TableSchema
+	scheduleAuditRepo utils.ScheduleAuditRepository
+	promptRolloutRepo utils.PromptRolloutRepository
+	experimentRepo    utils.CardFormatExperimentRepository
+	interactionRepo   utils.InteractionRepository
+	analyticsRepo     utils.AnalyticsRepository
+	userConfigRepo    utils.UserConfigRepository
+	supportTicketRepo utils.SupportTicketRepository
+	contentReportRepo utils.ContentReportRepository
+	killSwitchRepo    utils.KillSwitchRepository
+	bloomRebuilder    *utils.BloomFilterRebuilder
+	lineClients       *utils.LineClients // 公告由每位用戶加入的官方帳號發送
+}
+
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, dynamodbClient utils.DynamoDbAPI, tableSchemas []utils.TableSchema, scheduleAuditRepo utils.ScheduleAuditRepository, promptRolloutRepo utils.PromptRolloutRepository, experimentRepo utils.CardFormatExperimentRepository, interactionRepo utils.InteractionRepository, analyticsRepo utils.AnalyticsRepository, userConfigRepo utils.UserConfigRepository, supportTicketRepo utils.SupportTicketRepository, contentReportRepo utils.ContentReportRepository, killSwitchRepo utils.KillSwitchRepository, bloomRebuilder *utils.BloomFilterRebuilder, lineClients *utils.LineClients) (*Handler, error) {
+	return &Handler{
+		logger:            logger,
+		envVars:           envVars,
+		dynamodbClient:    dynamodbClient,
+		tableSchemas:      tableSchemas,
+		scheduleAuditRepo: scheduleAuditRepo,
+		promptRolloutRepo: promptRolloutRepo,
+		experimentRepo:    experimentRepo,
+		interactionRepo:   interactionRepo,
+		analyticsRepo:     analyticsRepo,
+		userConfigRepo:    userConfigRepo,
+		supportTicketRepo: supportTicketRepo,
+		contentReportRepo: contentReportRepo,
+		killSwitchRepo:    killSwitchRepo,
+		bloomRebuilder:    bloomRebuilder,
+		lineClients:       lineClients,
+	}, nil
+}
+
+type routeKey struct {
+	method   string
+	resource string
+}
+
+// EventHandler 處理 admin API 請求，依照 method + resource 分派到對應的 handler
+func (h *Handler) EventHandler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	h.logger.WithFields(logrus.Fields{
+		"method":   request.HTTPMethod,
+		"resource": request.Resource,
+	}).Info("Admin API request")
+
+	routes := map[routeKey]func(events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error){
+		{http.MethodGet, "/admin/users/{userId}/schedule-audit"}:        h.handleGetScheduleAudit,
+		{http.MethodGet, "/admin/messages"}:                             h.handleListMessageTemplates,
+		{http.MethodPost, "/admin/messages/render"}:                     h.handleRenderMessage,
+		{http.MethodGet, "/admin/prompts/{prompt}/rollout"}:             h.handleGetPromptRollout,
+		{http.MethodPut, "/admin/prompts/{prompt}/rollout"}:             h.handleStartPromptRollout,
+		{http.MethodDelete, "/admin/prompts/{prompt}/rollout"}:          h.handleStopPromptRollout,
+		{http.MethodGet, "/admin/experiments/card-format"}:              h.handleGetCardFormatExperiment,
+		{http.MethodGet, "/admin/analytics/onboarding-funnel"}:          h.handleGetOnboardingFunnel,
+		{http.MethodGet, "/admin/analytics/retention"}:                  h.handleGetRetention,
+		{http.MethodGet, "/admin/beta/requests"}:                        h.handleListBetaRequests,
+		{http.MethodPut, "/admin/beta/users/{userId}"}:                  h.handleSetBetaStatus,
+		{http.MethodPut, "/admin/users/{userId}/plan"}:                  h.handleSetPlan,
+		{http.MethodGet, "/admin/health/tables"}:                        h.handleValidateTables,
+		{http.MethodGet, "/admin/support-tickets"}:                      h.handleListSupportTickets,
+		{http.MethodPut, "/admin/users/{userId}/webhook-capture"}:       h.handleStartWebhookCapture,
+		{http.MethodDelete, "/admin/users/{userId}/webhook-capture"}:    h.handleStopWebhookCapture,
+		{http.MethodDelete, "/admin/support-tickets/{userId}"}:          h.handleResolveSupportTicket,
+		{http.MethodGet, "/admin/content-reports"}:                      h.handleListContentReports,
+		{http.MethodDelete, "/admin/content-reports/{reportId}"}:        h.handleResolveContentReport,
+		{http.MethodPost, "/admin/users/{userId}/bloom-filter/rebuild"}: h.handleRebuildBloomFilter,
+		{http.MethodPost, "/admin/broadcasts"}:                          h.handleBroadcast,
+		{http.MethodGet, "/admin/kill-switches"}:                        h.handleListKillSwitches,
+		{http.MethodPut, "/admin/kill-switches/{switch}"}:               h.handleSetKillSwitch,
+	}
+
+	route, ok := routes[routeKey{request.HTTPMethod, request.Resource}]
+	if !ok {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "route not found"})
+	}
+	return route(request)
+}
+
+// handleGetScheduleAudit 回傳用戶的排程操作稽核紀錄（最新的在前）
+func (h *Handler) handleGetScheduleAudit(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.PathParameters["userId"]
+	if userID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	}
+
+	limit := defaultAuditLimit
+	if limitStr := request.QueryStringParameters["limit"]; limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		}
+		limit = parsed
+	}
+
+	entries, err := h.scheduleAuditRepo.GetScheduleAuditLog(userID, limit)
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to get schedule audit log")
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get schedule audit log"})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"userId":  userID,
+		"entries": entries,
+	})
+}
+
+// handleListMessageTemplates 列出訊息目錄中所有可預覽的模板名稱
+func (h *Handler) handleListMessageTemplates(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"templates": messages.Keys(),
+	})
+}
+
+type renderMessageRequest struct {
+	Template string        `json:"template"`
+	Data     messages.Data `json:"data"`
+}
+
+// handleRenderMessage 以範例資料渲染模板，回傳實際會送給 LINE 的訊息 JSON（含 Quick Reply / Template），
+// 用來在不推播給真實用戶的情況下驗證文案與版面
+func (h *Handler) handleRenderMessage(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var body renderMessageRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil || body.Template == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "template is required"})
+	}
+
+	key := messages.Key(body.Template)
+	if !messages.Has(key) {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "unknown template: " + body.Template})
+	}
+
+	// linebot 的訊息型別都有實作 MarshalJSON，輸出即為 Messaging API 的 payload
+	payloads := []json.RawMessage{}
+	for _, message := range messages.Build(key, body.Data) {
+		payload, err := json.Marshal(message)
+		if err != nil {
+			h.logger.WithError(err).WithField("template", body.Template).Error("Failed to marshal LINE message")
+			return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to render message"})
+		}
+		payloads = append(payloads, payload)
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"template": body.Template,
+		"messages": payloads,
+	})
+}
+
+// handleGetPromptRollout 回傳目前的 rollout 設定與各版本指標
+func (h *Handler) handleGetPromptRollout(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	prompt := request.PathParameters["prompt"]
+	if prompt != models.PromptTranslation {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "unknown prompt: " + prompt})
+	}
+
+	rollout, err := h.promptRolloutRepo.GetPromptRollout(prompt)
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get prompt rollout"})
+	}
+
+	response := map[string]interface{}{
+		"prompt":          prompt,
+		"baselineVersion": utils.BaselineTranslationPromptVersion,
+		"rollout":         rollout,
+	}
+	if rollout != nil {
+		metrics, err := h.promptRolloutRepo.GetPromptMetrics(prompt, rollout.RolloutID)
+		if err != nil {
+			return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get prompt metrics"})
+		}
+		response["metrics"] = metrics
+	}
+
+	return jsonResponse(http.StatusOK, response)
+}
+
+type startPromptRolloutRequest struct {
+	CandidateVersion string `json:"candidateVersion"`
+	Percent          int    `json:"percent"`
+}
+
+// handleStartPromptRollout 開始新的 rollout（會取代目前的設定並重新計算指標）
+func (h *Handler) handleStartPromptRollout(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	prompt := request.PathParameters["prompt"]
+	if prompt != models.PromptTranslation {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "unknown prompt: " + prompt})
+	}
+
+	var body startPromptRolloutRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if body.CandidateVersion == utils.BaselineTranslationPromptVersion || !utils.HasTranslationPromptVersion(body.CandidateVersion) {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "candidateVersion must be an embedded, non-baseline prompt version"})
+	}
+	if body.Percent < 1 || body.Percent > 100 {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "percent must be between 1 and 100"})
+	}
+
+	rollout := models.PromptRollout{
+		Prompt:           prompt,
+		RolloutID:        time.Now().UTC().Format(time.RFC3339),
+		BaselineVersion:  utils.BaselineTranslationPromptVersion,
+		CandidateVersion: body.CandidateVersion,
+		Percent:          body.Percent,
+		Status:           models.PromptRolloutActive,
+	}
+	if err := h.promptRolloutRepo.SavePromptRollout(rollout); err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to save prompt rollout"})
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"prompt":           prompt,
+		"candidateVersion": rollout.CandidateVersion,
+		"percent":          rollout.Percent,
+	}).Info("Started prompt rollout")
+
+	return jsonResponse(http.StatusOK, rollout)
+}
+
+// handleStopPromptRollout 手動停止 rollout，所有流量回到 baseline
+func (h *Handler) handleStopPromptRollout(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	prompt := request.PathParameters["prompt"]
+	if prompt != models.PromptTranslation {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "unknown prompt: " + prompt})
+	}
+
+	rollout, err := h.promptRolloutRepo.GetPromptRollout(prompt)
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get prompt rollout"})
+	}
+	if rollout == nil || rollout.Status != models.PromptRolloutActive {
+		return jsonResponse(http.StatusConflict, map[string]string{"error": "no active rollout"})
+	}
+
+	if _, err := h.promptRolloutRepo.EndPromptRollout(prompt, rollout.RolloutID, models.PromptRolloutStopped, "stopped by admin"); err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to stop prompt rollout"})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]string{"status": models.PromptRolloutStopped})
+}
+
+// handleGetCardFormatExperiment 回傳最近 N 天文字與圖片字卡的推播數、互動數與互動率
+func (h *Handler) handleGetCardFormatExperiment(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	days := defaultExperimentDays
+	if daysStr := request.QueryStringParameters["days"]; daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed <= 0 || parsed > maxExperimentDays {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 90"})
+		}
+		days = parsed
+	}
+
+	now := time.Now().UTC()
+	fromDate := now.AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	toDate := now.Format("2006-01-02")
+
+	daily, err := h.experimentRepo.GetCardFormatMetrics(fromDate, toDate)
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get card format metrics"})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"from":    fromDate,
+		"to":      toDate,
+		"formats": utils.SummarizeCardFormatMetrics(daily),
+		"daily":   daily,
+	})
+}
+
+// handleGetOnboardingFunnel 依 follow 日期分 cohort，計算 follow → 課程 → 分數 → 推播設定 的轉換率。
+// 參數 from / to 為 cohort 的日期區間（含頭尾，UTC），cohort 可為 day 或 week（預設）
+func (h *Handler) handleGetOnboardingFunnel(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	to := today
+	if toStr := request.QueryStringParameters["to"]; toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "to must be YYYY-MM-DD"})
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(defaultFunnelDays - 1))
+	if fromStr := request.QueryStringParameters["from"]; fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "from must be YYYY-MM-DD"})
+		}
+		from = parsed
+	}
+
+	// 需要讀到今天為止，才能涵蓋 cohort 之後才完成的步驟
+	if from.After(to) || to.After(today) || today.Sub(from) >= maxFunnelDays*24*time.Hour {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "from must not be after to, to must not be in the future, and from must be within the last 90 days"})
+	}
+
+	granularity := request.QueryStringParameters["cohort"]
+	if granularity == "" {
+		granularity = utils.CohortWeek
+	}
+	if granularity != utils.CohortDay && granularity != utils.CohortWeek {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "cohort must be day or week"})
+	}
+
+	var interactions []models.Interaction
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		daily, err := h.interactionRepo.GetInteractionsByDate(day.Format("2006-01-02"))
+		if err != nil {
+			return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get interactions"})
+		}
+		interactions = append(interactions, daily...)
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+		"cohort":  granularity,
+		"steps":   utils.OnboardingFunnelSteps,
+		"cohorts": utils.ComputeOnboardingFunnel(interactions, from, to.AddDate(0, 0, 1), granularity),
+	})
+}
+
+// handleGetRetention 回傳每週排程計算好的 cohort 留存報表（最新的 cohort 在前）
+func (h *Handler) handleGetRetention(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	limit := defaultRetentionLimit
+	if limitStr := request.QueryStringParameters["limit"]; limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		}
+		limit = parsed
+	}
+
+	cohorts, err := h.analyticsRepo.GetCohortRetention(limit)
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get cohort retention"})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"cohorts": cohorts,
+	})
+}
+
+type betaUser struct {
+	UserID          string            `json:"userId"`
+	BetaStatus      models.BetaStatus `json:"betaStatus"`
+	BetaRequestedAt string            `json:"betaRequestedAt"`
+}
+
+// handleListBetaRequests 列出 Beta 測試申請（預設為待審核），申請較早的在前
+func (h *Handler) handleListBetaRequests(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	status := models.BetaStatusPending
+	if value := request.QueryStringParameters["status"]; value != "" {
+		parsed, err := models.ParseBetaStatus(value)
+		if err != nil {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "status must be pending, approved or rejected"})
+		}
+		status = parsed
+	}
+
+	userConfigs, err := h.userConfigRepo.GetUsersByBetaStatus(status)
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get beta requests"})
+	}
+
+	users := []betaUser{}
+	for _, userConfig := range userConfigs {
+		users = append(users, betaUser{
+			UserID:          userConfig.UserID,
+			BetaStatus:      userConfig.BetaStatus,
+			BetaRequestedAt: userConfig.BetaRequestedAt,
+		})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"status": status,
+		"users":  users,
+	})
+}
+
+type setBetaStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// handleSetBetaStatus 核准或拒絕用戶的 Beta 測試申請
+func (h *Handler) handleSetBetaStatus(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.PathParameters["userId"]
+	if userID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	}
+
+	var body setBetaStatusRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	status, err := models.ParseBetaStatus(body.Status)
+	if err != nil || status == models.BetaStatusPending {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "status must be approved or rejected"})
+	}
+
+	if err := h.userConfigRepo.SetBetaStatus(userID, status); err != nil {
+		if errors.Is(err, utils.ErrUserNotFound) {
+			return jsonResponse(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to set beta status"})
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"userID": userID,
+		"status": status,
+	}).Info("Updated beta status")
+
+	return jsonResponse(http.StatusOK, betaUser{UserID: userID, BetaStatus: status})
+}
+
+type setPlanRequest struct {
+	Plan string `json:"plan"`
+}
+
+// handleSetPlan 設定用戶的方案，決定每日翻譯額度
+func (h *Handler) handleSetPlan(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.PathParameters["userId"]
+	if userID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	}
+
+	var body setPlanRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	plan, err := models.ParsePlan(body.Plan)
+	if err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.userConfigRepo.SetPlan(userID, plan); err != nil {
+		if errors.Is(err, utils.ErrUserNotFound) {
+			return jsonResponse(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to set plan"})
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"userID": userID,
+		"plan":   plan,
+	}).Info("Updated plan")
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{"userId": userID, "plan": plan})
+}
+
+// handleListKillSwitches 列出所有子系統的 kill switch 狀態，沒有設定過的子系統視為開啟
+func (h *Handler) handleListKillSwitches(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	states, err := h.killSwitchRepo.GetKillSwitches()
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get kill switches"})
+	}
+
+	result := make([]models.KillSwitchState, 0, len(models.KillSwitches))
+	for _, sw := range models.KillSwitches {
+		state, ok := states[sw]
+		if !ok {
+			state = models.KillSwitchState{Switch: sw}
+		}
+		result = append(result, state)
+	}
+	return jsonResponse(http.StatusOK, result)
+}
+
+type setKillSwitchRequest struct {
+	Disabled bool   `json:"disabled"`
+	Reason   string `json:"reason"`
+}
+
+// handleSetKillSwitch 關閉或恢復子系統，各 Lambda 在 utils.KillSwitchCacheTTL 內生效
+func (h *Handler) handleSetKillSwitch(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	sw, err := models.ParseKillSwitch(request.PathParameters["switch"])
+	if err != nil {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	var body setKillSwitchRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	if body.Disabled && strings.TrimSpace(body.Reason) == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "reason is required when disabling"})
+	}
+
+	state := models.KillSwitchState{
+		Switch:    sw,
+		Disabled:  body.Disabled,
+		Reason:    strings.TrimSpace(body.Reason),
+		UpdatedBy: "admin-api",
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := h.killSwitchRepo.SaveKillSwitch(state); err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to save kill switch"})
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"killSwitch": sw,
+		"disabled":   state.Disabled,
+		"reason":     state.Reason,
+	}).Warn("Kill switch updated")
+
+	return jsonResponse(http.StatusOK, state)
+}
+
+// handleListSupportTickets 列出因 error budget 用完而自動開立、尚未處理的 support ticket
+func (h *Handler) handleListSupportTickets(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	tickets, err := h.supportTicketRepo.GetOpenSupportTickets()
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get support tickets"})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"tickets": tickets,
+	})
+}
+
+// handleResolveSupportTicket 處理完畢後結案，之後再次用完 error budget 會重新開立
+func (h *Handler) handleResolveSupportTicket(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.PathParameters["userId"]
+	if userID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	}
+
+	resolved, err := h.supportTicketRepo.ResolveSupportTicket(userID)
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to resolve support ticket"})
+	}
+	if !resolved {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "no open support ticket"})
+	}
+
+	h.logger.WithField("userID", userID).Info("Resolved support ticket")
+
+	return jsonResponse(http.StatusOK, map[string]string{"userId": userID, "status": "resolved"})
+}
+
+// handleListContentReports 列出用戶回報、尚未審核的 AI 產生內容
+func (h *Handler) handleListContentReports(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	reports, err := h.contentReportRepo.GetOpenContentReports()
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get content reports"})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"reports": reports,
+	})
+}
+
+// handleResolveContentReport 審核完畢後結案並恢復使用被暫停的單字；確認內容有問題時帶上 keepSuppressed=true，
+// 單字會持續排除在每日單字之外
+func (h *Handler) handleResolveContentReport(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	reportID := request.PathParameters["reportId"]
+	if reportID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "reportId is required"})
+	}
+	keepSuppressed := request.QueryStringParameters["keepSuppressed"] == "true"
+
+	report, err := h.contentReportRepo.ResolveContentReport(reportID)
+	if err != nil {
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to resolve content report"})
+	}
+	if report == nil {
+		return jsonResponse(http.StatusNotFound, map[string]string{"error": "no open content report"})
+	}
+
+	unsuppressed := false
+	if report.Suppressed && !keepSuppressed {
+		unsuppressed, err = h.contentReportRepo.UnsuppressWord(utils.NormalizeHistoryWord(report.Word), report.ReportID)
+		if err != nil {
+			return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "content report resolved but failed to restore the word"})
+		}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"reportId":     reportID,
+		"word":         report.Word,
+		"unsuppressed": unsuppressed,
+	}).Info("Resolved content report")
+
+	return jsonResponse(http.StatusOK, map[string]interface{}{"reportId": reportID, "status": "resolved", "unsuppressed": unsuppressed})
+}
+
+type startWebhookCaptureRequest struct {
+	Hours int `json:"hours"`
+}
+
+// handleStartWebhookCapture 標記用戶，在接下來 N 小時內擷取其 webhook payload（去識別化）供重播除錯
+func (h *Handler) handleStartWebhookCapture(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.PathParameters["userId"]
+	if userID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	}
+
+	body := startWebhookCaptureRequest{Hours: defaultCaptureHours}
+	if request.Body != "" {
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		}
+	}
+	if body.Hours < 1 || body.Hours > maxCaptureHours {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "hours must be between 1 and 168"})
+	}
+
+	until := time.Now().UTC().Add(time.Duration(body.Hours) * time.Hour)
+	if err := h.userConfigRepo.SetWebhookCapture(userID, until); err != nil {
+		if errors.Is(err, utils.ErrUserNotFound) {
+			return jsonResponse(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to start webhook capture"})
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"userID": userID,
+		"until":  until.Format(time.RFC3339),
+	}).Info("Started webhook capture")
+
+	return jsonResponse(http.StatusOK, map[string]string{
+		"userId": userID,
+		"until":  until.Format(time.RFC3339),
+		"prefix": utils.WebhookCapturePrefix + utils.PseudonymizeID(userID) + "/",
+	})
+}
+
+// handleRebuildBloomFilter 以單字歷史與推播紀錄重建用戶的 bloom filter，course 未指定時使用用戶目前的課程
+func (h *Handler) handleRebuildBloomFilter(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.PathParameters["userId"]
+	if userID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	}
+
+	course := request.QueryStringParameters["course"]
+	if course == "" {
+		userConfig, err := h.userConfigRepo.GetUserConfig(userID)
+		if err != nil {
+			return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get user config"})
+		}
+		if userConfig == nil || userConfig.Course == "" {
+			return jsonResponse(http.StatusNotFound, map[string]string{"error": "user has no course, specify ?course="})
+		}
+		course = userConfig.Course
+	}
+
+	result, err := h.bloomRebuilder.Rebuild(userID, course)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{"userID": userID, "course": course}).Error("Failed to rebuild bloom filter")
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to rebuild bloom filter"})
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"userID": userID,
+		"course": course,
+		"words":  result.Words,
+	}).Info("Rebuilt bloom filter")
+
+	return jsonResponse(http.StatusOK, result)
+}
+
+// handleStopWebhookCapture 取消擷取標記，已擷取的 payload 會由 S3 lifecycle 清除
+func (h *Handler) handleStopWebhookCapture(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID := request.PathParameters["userId"]
+	if userID == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "userId is required"})
+	}
+
+	if err := h.userConfigRepo.SetWebhookCapture(userID, time.Time{}); err != nil {
+		if errors.Is(err, utils.ErrUserNotFound) {
+			return jsonResponse(http.StatusNotFound, map[string]string{"error": "user not found"})
+		}
+		return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to stop webhook capture"})
+	}
+
+	return jsonResponse(http.StatusOK, map[string]string{"userId": userID, "status": "stopped"})
+}
+
+type tableCheck struct {
+	TableName string `json:"tableName"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+// handleValidateTables 比對各 DynamoDB table 的 key 與 GSI 是否符合 repository 的預期
+func (h *Handler) handleValidateTables(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	allOK := true
+	checks := make([]tableCheck, 0, len(h.tableSchemas))
+	for _, schema := range h.tableSchemas {
+		check := tableCheck{TableName: schema.TableName, OK: true}
+		if err := utils.ValidateTableSchemas(h.dynamodbClient, schema); err != nil {
+			check.OK = false
+			check.Error = err.Error()
+			allOK = false
+		}
+		checks = append(checks, check)
+	}
+
+	statusCode := http.StatusOK
+	if !allOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+	return jsonResponse(statusCode, map[string]interface{}{
+		"ok":     allOK,
+		"tables": checks,
+	})
+}
+
+type broadcastRequest struct {
+	Message string `json:"message"`
+	Course  string `json:"course"`
+}
+
+// handleBroadcast 發送公告：以 multicast 分批發給同意接收公告（models.ConsentMarketing）且仍在使用的用戶，
+// 指定 course 時只發給該課程的用戶。不使用 LINE broadcast，因為它會發給所有好友而無法排除未同意的用戶
+func (h *Handler) handleBroadcast(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var body broadcastRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+	}
+	body.Message = strings.TrimSpace(body.Message)
+	if body.Message == "" {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "message is required"})
+	}
+	if messages.Length(body.Message) > messages.MaxTextLength {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": "message must be at most " + strconv.Itoa(messages.MaxTextLength) + " characters"})
+	}
+	sendingMessages := []linebot.SendingMessage{linebot.NewTextMessage(body.Message)}
+
+	audience := body.Course
+	var users []models.UserConfig
+	var err error
+	if body.Course == "" {
+		audience = "all"
+		users, err = h.userConfigRepo.GetUsersWithConsent(models.ConsentMarketing)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to get users with marketing consent")
+			return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get users with marketing consent"})
+		}
+	} else {
+		if _, ok := courses.Get(body.Course); !ok {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "unknown course: " + body.Course})
+		}
+		users, err = h.userConfigRepo.GetUsersByCourse(body.Course)
+		if err != nil {
+			h.logger.WithError(err).WithField("course", body.Course).Error("Failed to get users by course")
+			return jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get users by course"})
+		}
+	}
+	// multicast 只能發給同一個官方帳號的好友，依用戶加入的帳號分組發送
+	recipients := 0
+	userIDsByClient := map[utils.LinebotAPI][]string{}
+	for i, user := range users {
+		// 已封鎖的用戶收不到訊息，送出只會浪費 multicast 額度
+		if user.DeactivatedAt == "" && user.HasConsent(models.ConsentMarketing) {
+			client := h.lineClients.ClientFor(&users[i])
+			userIDsByClient[client] = append(userIDsByClient[client], user.UserID)
+			recipients++
+		}
+	}
+
+	sent := 0
+	for client, userIDs := range userIDsByClient {
+		var clientSent int
+		clientSent, err = utils.MulticastInBatches(client, userIDs, sendingMessages)
+		sent += clientSent
+		if err != nil {
+			break
+		}
+	}
+	logger := h.logger.WithFields(logrus.Fields{
+		"audience":   audience,
+		"recipients": recipients,
+		"sent":       sent,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to multicast announcement")
+		return jsonResponse(http.StatusBadGateway, map[string]interface{}{
+			"error":      "failed to multicast announcement",
+			"recipients": recipients,
+			"sent":       sent,
+		})
+	}
+	logger.Info("Multicast announcement to consenting users")
+	return jsonResponse(http.StatusOK, map[string]interface{}{
+		"audience":   audience,
+		"recipients": recipients,
+		"sent":       sent,
+	})
+}
+
+func jsonResponse(statusCode int, body interface{}) (events.APIGatewayProxyResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "Internal Server Error",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(payload),
+	}, nil
+}
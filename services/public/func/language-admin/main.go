@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SEVERITY    = "severity"
+	MESSAGE     = "message"
+	TIMESTAMP   = "timestamp"
+	COMPONENT   = "component"
+	SERVICENAME = "language-admin"
+)
+
+type EnvVars struct {
+	auditTableName      string
+	vocabularyTableName string
+	analyticsTableName  string
+	userTableName       string
+	pairingTableName    string
+	lineChannels        *utils.LineChannels // 各官方帳號的 channel，公告由用戶加入的帳號發送
+}
+
+func getEnvironmentVariables() (envVars *EnvVars, err error) {
+	auditTableName := os.Getenv("AUDIT_TABLE_NAME")
+	if auditTableName == "" {
+		return nil, errors.New("AUDIT_TABLE_NAME is not set")
+	}
+
+	vocabularyTableName := os.Getenv("VOCABULARY_TABLE_NAME")
+	if vocabularyTableName == "" {
+		return nil, errors.New("VOCABULARY_TABLE_NAME is not set")
+	}
+
+	analyticsTableName := os.Getenv("ANALYTICS_TABLE_NAME")
+	if analyticsTableName == "" {
+		return nil, errors.New("ANALYTICS_TABLE_NAME is not set")
+	}
+
+	userTableName := os.Getenv("USER_TABLE_NAME")
+	if userTableName == "" {
+		return nil, errors.New("USER_TABLE_NAME is not set")
+	}
+
+	pairingTableName := os.Getenv("PAIRING_TABLE_NAME")
+	if pairingTableName == "" {
+		return nil, errors.New("PAIRING_TABLE_NAME is not set")
+	}
+
+	lineChannels, err := utils.LoadLineChannels(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EnvVars{
+		auditTableName:      auditTableName,
+		vocabularyTableName: vocabularyTableName,
+		analyticsTableName:  analyticsTableName,
+		userTableName:       userTableName,
+		pairingTableName:    pairingTableName,
+		lineChannels:        lineChannels,
+	}, nil
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  TIMESTAMP,
+			logrus.FieldKeyLevel: SEVERITY,
+			logrus.FieldKeyMsg:   MESSAGE,
+		},
+	})
+	logger := logrus.WithField(COMPONENT, SERVICENAME)
+
+	envVars, err := getEnvironmentVariables()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get environment variables")
+		panic(err)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		panic(err)
+	}
+	dynamodbClient := dynamodb.NewFromConfig(cfg)
+
+	scheduleAuditRepo := repository.NewScheduleAuditRepository(logger, dynamodbClient, envVars.auditTableName)
+
+	promptRolloutRepo := repository.NewPromptRolloutRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	experimentRepo := repository.NewCardFormatExperimentRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	interactionRepo := repository.NewInteractionRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	analyticsRepo := repository.NewAnalyticsRepository(logger, dynamodbClient, envVars.analyticsTableName)
+	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
+	supportTicketRepo := repository.NewSupportTicketRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	contentReportRepo := repository.NewContentReportRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	killSwitchRepo := repository.NewKillSwitchRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	bloomRebuilder := utils.NewBloomFilterRebuilder(
+		repository.NewBloomFilterRepository(logger, dynamodbClient, envVars.vocabularyTableName),
+		repository.NewWordHistoryRepository(logger, dynamodbClient, envVars.vocabularyTableName),
+		repository.NewPushLogRepository(logger, dynamodbClient, envVars.vocabularyTableName),
+	)
+
+	lineClients, err := utils.NewLineClients(envVars.lineChannels, nil)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create LINE Bot client")
+		panic(err)
+	}
+
+	tableSchemas := []utils.TableSchema{
+		utils.VocabularyTableSchema(envVars.vocabularyTableName),
+		utils.UserTableSchema(envVars.userTableName),
+		utils.ScheduleAuditTableSchema(envVars.auditTableName),
+		utils.PairingTableSchema(envVars.pairingTableName),
+		utils.AnalyticsTableSchema(envVars.analyticsTableName),
+	}
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Table("vocabulary", envVars.vocabularyTableName).
+		Table("user", envVars.userTableName).
+		Table("audit", envVars.auditTableName).
+		Table("pairing", envVars.pairingTableName).
+		Table("analytics", envVars.analyticsTableName).
+		Setting("scheduleGroup", utils.ScheduleGroupName).
+		Setting("lineChannels", envVars.lineChannels.Destinations()).
+		Log(logger)
+
+	handler, err := NewHandler(logger, envVars, dynamodbClient, tableSchemas, scheduleAuditRepo, promptRolloutRepo, experimentRepo, interactionRepo, analyticsRepo, userConfigRepo, supportTicketRepo, contentReportRepo, killSwitchRepo, bloomRebuilder, lineClients)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create handler")
+		panic(err)
+	}
+
+	lambda.Start(handler.EventHandler)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/sirupsen/logrus"
+)
+
+// 每次重新計算最近幾個 cohort 的留存（較早的 cohort 數字已不會再變動）
+const retentionCohortWeeks = 8
+
+// Handler aggregates analytics events. Holds no mutable state; safe for concurrent use
+type Handler struct {
+	logger        *logrus.Entry
+	envVars       *EnvVars
+	analyticsRepo utils.AnalyticsRepository
+}
+
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, analyticsRepo utils.AnalyticsRepository) (*Handler, error) {
+	return &Handler{
+		logger:        logger,
+		envVars:       envVars,
+		analyticsRepo: analyticsRepo,
+	}, nil
+}
+
+// EventHandler 每週執行一次，計算已結束的週的 cohort 留存並寫入 analytics table
+func (h *Handler) EventHandler(ctx context.Context, event events.CloudWatchEvent) error {
+	h.logger.WithFields(logrus.Fields{
+		"source":     event.Source,
+		"detailType": event.DetailType,
+		"eventTime":  event.Time,
+	}).Info("Weekly retention job triggered")
+
+	// 只計算到上週為止，本週尚未結束
+	currentWeek := utils.WeekStart(time.Now())
+	lastCompleteWeek := currentWeek.AddDate(0, 0, -7)
+	computedAt := time.Now().UTC().Format(time.RFC3339)
+
+	activityByWeek := map[string][]models.WeeklyActivity{}
+	loadWeek := func(week time.Time) ([]models.WeeklyActivity, error) {
+		key := week.Format("2006-01-02")
+		if activities, ok := activityByWeek[key]; ok {
+			return activities, nil
+		}
+		activities, err := h.analyticsRepo.GetWeeklyActivity(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load activity for week %s: %w", key, err)
+		}
+		activityByWeek[key] = activities
+		return activities, nil
+	}
+
+	var report []models.CohortRetention
+	for i := retentionCohortWeeks; i >= 1; i-- {
+		cohort := currentWeek.AddDate(0, 0, -7*i)
+
+		var weeks [][]models.WeeklyActivity
+		for week := cohort; !week.After(lastCompleteWeek); week = week.AddDate(0, 0, 7) {
+			activities, err := loadWeek(week)
+			if err != nil {
+				h.logger.WithError(err).Error("Failed to load weekly activity")
+				return err
+			}
+			weeks = append(weeks, activities)
+		}
+
+		retention := utils.ComputeCohortRetention(cohort.Format("2006-01-02"), weeks)
+		retention.ComputedAt = computedAt
+		if err := h.analyticsRepo.SaveCohortRetention(retention); err != nil {
+			h.logger.WithError(err).WithField("cohort", retention.Cohort).Error("Failed to save cohort retention")
+			return err
+		}
+		report = append(report, retention)
+	}
+
+	// 沒有獨立的 ops digest 管道，以結構化 log 輸出摘要，供 log 訂閱 / 告警轉發
+	h.logger.WithFields(logrus.Fields{
+		"digest":  "weekly_retention",
+		"cohorts": formatRetentionDigest(report),
+	}).Info("Weekly cohort retention computed")
+
+	return nil
+}
+
+// formatRetentionDigest 將留存報表整理成易讀的摘要，例如 "2025-01-06 (n=12): 100% 58% 42%"
+func formatRetentionDigest(report []models.CohortRetention) []string {
+	lines := make([]string, 0, len(report))
+	for _, retention := range report {
+		line := fmt.Sprintf("%s (n=%d):", retention.Cohort, retention.Size)
+		for _, rate := range retention.Rates {
+			line += fmt.Sprintf(" %.0f%%", rate*100)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SEVERITY    = "severity"
+	MESSAGE     = "message"
+	TIMESTAMP   = "timestamp"
+	COMPONENT   = "component"
+	SERVICENAME = "language-analytics"
+)
+
+type EnvVars struct {
+	analyticsTableName string
+}
+
+func getEnvironmentVariables() (envVars *EnvVars, err error) {
+	analyticsTableName := os.Getenv("ANALYTICS_TABLE_NAME")
+	if analyticsTableName == "" {
+		return nil, errors.New("ANALYTICS_TABLE_NAME is not set")
+	}
+
+	return &EnvVars{
+		analyticsTableName: analyticsTableName,
+	}, nil
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  TIMESTAMP,
+			logrus.FieldKeyLevel: SEVERITY,
+			logrus.FieldKeyMsg:   MESSAGE,
+		},
+	})
+	logger := logrus.WithField(COMPONENT, SERVICENAME)
+
+	envVars, err := getEnvironmentVariables()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get environment variables")
+		panic(err)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		panic(err)
+	}
+	dynamodbClient := dynamodb.NewFromConfig(cfg)
+
+	analyticsRepo := repository.NewAnalyticsRepository(logger, dynamodbClient, envVars.analyticsTableName)
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Table("analytics", envVars.analyticsTableName).
+		Log(logger)
+
+	handler, err := NewHandler(logger, envVars, analyticsRepo)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create handler")
+		panic(err)
+	}
+
+	lambda.Start(handler.EventHandler)
+}
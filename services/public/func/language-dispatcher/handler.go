@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/sirupsen/logrus"
+)
+
+// SQS SendMessageBatch 一次最多 10 則
+const maxBatchEntries = 10
+
+// Handler enqueues the hourly word pushes. Holds no mutable state; safe for concurrent use
+type Handler struct {
+	logger         *logrus.Entry
+	envVars        *EnvVars
+	userConfigRepo utils.UserConfigRepository
+	sqsClient      *sqs.Client
+}
+
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, userConfigRepo utils.UserConfigRepository, sqsClient *sqs.Client) (*Handler, error) {
+	return &Handler{
+		logger:         logger,
+		envVars:        envVars,
+		userConfigRepo: userConfigRepo,
+		sqsClient:      sqsClient,
+	}, nil
+}
+
+// EventHandler 每小時由排程觸發，查出 pushTime 為本小時的用戶，將每位用戶的推播 payload 送入 SQS 交給 language-vocabulary
+func (h *Handler) EventHandler(ctx context.Context, event events.CloudWatchEvent) error {
+	if h.envVars.pushMode != utils.PushModeFanout {
+		h.logger.WithField("pushMode", h.envVars.pushMode).Info("Fan-out push disabled, skipping dispatch")
+		return nil
+	}
+
+	// 以排程的預定時間為準，Lambda 延遲啟動時不會跨到下一個小時
+	now := event.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	pushTime, err := utils.FanoutPushTime(now, utils.FanoutTimezone)
+	if err != nil {
+		return err
+	}
+
+	userConfigs, err := h.userConfigRepo.GetUsersByPushTime(pushTime)
+	if err != nil {
+		return err
+	}
+
+	var userIDs []string
+	for _, userConfig := range userConfigs {
+		switch {
+		case userConfig.DeactivatedAt != "", userConfig.TranslationOnly:
+			continue
+		case !userConfig.FanoutPush:
+			// 尚未切換到 fan-out 的用戶仍由個人排程推播；scheduleName 欄位上線前建立排程的用戶沒有 scheduleName，
+			// 只有個人排程已刪除（handler 標記 fanoutPush）的用戶才由 dispatcher 推播
+			continue
+		case userConfig.Timezone != utils.FanoutTimezone:
+			h.logger.WithFields(logrus.Fields{"userID": userConfig.UserID, "timezone": userConfig.Timezone}).Warn("Skipping user with unsupported timezone")
+			continue
+		}
+		userIDs = append(userIDs, userConfig.UserID)
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"pushTime":  pushTime,
+		"matched":   len(userConfigs),
+		"enqueuing": len(userIDs),
+	}).Info("Dispatching word pushes")
+
+	return h.enqueueWordPushes(ctx, userIDs)
+}
+
+// enqueueWordPushes 以 SendMessageBatch 送出推播 payload，部分失敗時繼續送其他批次。
+// 失敗不回傳錯誤：Lambda 非同步重試會重送整個小時，已送出的用戶會收到重複推播，改以 alert 通知
+func (h *Handler) enqueueWordPushes(ctx context.Context, userIDs []string) error {
+	failed := 0
+	for start := 0; start < len(userIDs); start += maxBatchEntries {
+		end := min(start+maxBatchEntries, len(userIDs))
+
+		entries := make([]sqstypes.SendMessageBatchRequestEntry, 0, end-start)
+		for i, userID := range userIDs[start:end] {
+			payload, err := utils.NewWordPushPayload(userID, models.WordPushSourceDispatcher)
+			if err != nil {
+				return fmt.Errorf("failed to marshal payload: %w", err)
+			}
+			entries = append(entries, sqstypes.SendMessageBatchRequestEntry{
+				Id:          aws.String(strconv.Itoa(i)),
+				MessageBody: aws.String(string(payload)),
+			})
+		}
+
+		output, err := h.sqsClient.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(h.envVars.pushQueueURL),
+			Entries:  entries,
+		})
+		if err != nil {
+			h.logger.WithError(err).WithField("batchSize", len(entries)).Error("Failed to enqueue word pushes")
+			failed += len(entries)
+			continue
+		}
+		for _, entry := range output.Failed {
+			index, _ := strconv.Atoi(aws.ToString(entry.Id))
+			h.logger.WithFields(logrus.Fields{
+				"userID": userIDs[start+index],
+				"code":   aws.ToString(entry.Code),
+			}).Error("Failed to enqueue word push: ", aws.ToString(entry.Message))
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		h.logger.WithFields(logrus.Fields{
+			"alert":  "push_dispatch_failure",
+			"failed": failed,
+			"total":  len(userIDs),
+		}).Error("Some word pushes were not enqueued")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SEVERITY    = "severity"
+	MESSAGE     = "message"
+	TIMESTAMP   = "timestamp"
+	COMPONENT   = "component"
+	SERVICENAME = "language-dispatcher"
+)
+
+type EnvVars struct {
+	userTableName string
+	pushQueueURL  string
+	pushMode      string
+	faultInjector *utils.FaultInjector // nil 表示不注入錯誤
+}
+
+func getEnvironmentVariables() (envVars *EnvVars, err error) {
+	userTableName := os.Getenv("USER_TABLE_NAME")
+	if userTableName == "" {
+		return nil, errors.New("USER_TABLE_NAME is not set")
+	}
+
+	pushQueueURL := os.Getenv("PUSH_QUEUE_URL")
+	if pushQueueURL == "" {
+		return nil, errors.New("PUSH_QUEUE_URL is not set")
+	}
+
+	pushMode, err := utils.LoadPushMode(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	faultInjector, err := utils.LoadFaultInjector(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EnvVars{
+		userTableName: userTableName,
+		pushQueueURL:  pushQueueURL,
+		pushMode:      pushMode,
+		faultInjector: faultInjector,
+	}, nil
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  TIMESTAMP,
+			logrus.FieldKeyLevel: SEVERITY,
+			logrus.FieldKeyMsg:   MESSAGE,
+		},
+	})
+	logger := logrus.WithField(COMPONENT, SERVICENAME)
+
+	envVars, err := getEnvironmentVariables()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get environment variables")
+		panic(err)
+	}
+	if envVars.faultInjector != nil {
+		logger.WithField("faultInjection", os.Getenv("FAULT_INJECTION")).Warn("Fault injection enabled")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		panic(err)
+	}
+	dynamodbClient := utils.WithDynamoDBFaults(dynamodb.NewFromConfig(cfg), envVars.faultInjector)
+
+	schemas := []utils.TableSchema{
+		utils.UserTableSchema(envVars.userTableName),
+	}
+	if err := utils.ValidateTableSchemas(dynamodbClient, schemas...); err != nil {
+		logger.WithError(err).Error("DynamoDB table schema mismatch")
+		panic(err)
+	}
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Tables(schemas...).
+		Feature("faultInjection", envVars.faultInjector != nil).
+		Setting("pushMode", envVars.pushMode).
+		Setting("fanoutTimezone", utils.FanoutTimezone).
+		Setting("pushQueueURL", envVars.pushQueueURL).
+		Log(logger)
+
+	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
+
+	handler, err := NewHandler(logger, envVars, userConfigRepo, sqs.NewFromConfig(cfg))
+	if err != nil {
+		logger.WithError(err).Error("Failed to create handler")
+		panic(err)
+	}
+
+	lambda.Start(handler.EventHandler)
+}
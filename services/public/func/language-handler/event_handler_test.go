@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"language-assistant/internal/messages"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/line/line-bot-sdk-go/v7/linebot"
+	"github.com/sirupsen/logrus"
+)
+
+// recordingLinebot 解析未簽章的 webhook body，並依 reply token 記錄回覆；可同時被多個事件使用
+type recordingLinebot struct {
+	utils.LinebotAPI
+	mu      sync.Mutex
+	replies map[string]int
+}
+
+func (b *recordingLinebot) ParseRequest(req *http.Request) ([]*linebot.Event, error) {
+	var body struct {
+		Events []*linebot.Event `json:"events"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Events, nil
+}
+
+func (b *recordingLinebot) ReplyMessage(replyToken string, message string) error {
+	return b.ReplyMessageWithMultiple(replyToken, linebot.NewTextMessage(message))
+}
+
+func (b *recordingLinebot) ReplyMessageWithMultiple(replyToken string, sendingMessages ...linebot.SendingMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.replies[replyToken]++
+	return nil
+}
+
+// memoryUsers 只實作推播設定流程用到的方法
+type memoryUsers struct {
+	utils.UserConfigRepository
+	mu      sync.Mutex
+	configs map[string]*models.UserConfig
+}
+
+func (r *memoryUsers) GetUserConfig(userID string) (*models.UserConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	config, ok := r.configs[userID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *config
+	return &copied, nil
+}
+
+func (r *memoryUsers) SaveUserConfig(userID, displayName, course string, level int, dailyWords int, pushTime, timezone string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs[userID] = &models.UserConfig{UserID: userID, DisplayName: displayName, Course: course, Level: level, DailyWords: dailyWords, PushTime: pushTime, Timezone: timezone}
+	return nil
+}
+
+func (r *memoryUsers) SetScheduleName(userID, scheduleName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs[userID].ScheduleName = scheduleName
+	return nil
+}
+
+func (r *memoryUsers) SetLineDestination(userID, destination string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configs[userID].LineDestination = destination
+	return nil
+}
+
+func (r *memoryUsers) TouchLastActive(userID string, at time.Time) (string, error) {
+	return at.Format(time.RFC3339), nil
+}
+
+type memorySetupDrafts struct {
+	mu     sync.Mutex
+	drafts map[string]models.SetupDraft
+}
+
+func (r *memorySetupDrafts) GetSetupDraft(userID string) (*models.SetupDraft, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	draft, ok := r.drafts[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &draft, nil
+}
+
+func (r *memorySetupDrafts) UpdateSetupDraft(update models.SetupDraft) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	draft := r.drafts[update.UserID]
+	draft.UserID, draft.ExpiresAt = update.UserID, update.ExpiresAt
+	if update.Course != "" {
+		draft.Course = update.Course
+	}
+	if update.DailyWords != 0 {
+		draft.DailyWords = update.DailyWords
+	}
+	if update.PushTime != "" {
+		draft.PushTime = update.PushTime
+	}
+	r.drafts[update.UserID] = draft
+	return nil
+}
+
+func (r *memorySetupDrafts) DeleteSetupDraft(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.drafts, userID)
+	return nil
+}
+
+type discardInteractions struct{ utils.InteractionRepository }
+
+func (discardInteractions) RecordInteraction(models.Interaction) error { return nil }
+
+type discardAnalytics struct{ utils.AnalyticsRepository }
+
+func (discardAnalytics) RecordWeeklyActivity(models.WeeklyActivity) error { return nil }
+
+type discardScheduleAudit struct{ utils.ScheduleAuditRepository }
+
+func (discardScheduleAudit) RecordScheduleOperation(models.ScheduleAuditEntry) error { return nil }
+
+type eventTestHandler struct {
+	*Handler
+	linebot *recordingLinebot
+	users   *memoryUsers
+	drafts  *memorySetupDrafts
+	lambda  *utils.MockLambdaInvoker
+}
+
+func newEventTestHandler(userIDs ...string) *eventTestHandler {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	entry := logrus.NewEntry(logger)
+
+	h := &eventTestHandler{
+		linebot: &recordingLinebot{replies: map[string]int{}},
+		users:   &memoryUsers{configs: map[string]*models.UserConfig{}},
+		drafts:  &memorySetupDrafts{drafts: map[string]models.SetupDraft{}},
+		lambda:  &utils.MockLambdaInvoker{},
+	}
+	for _, userID := range userIDs {
+		h.users.configs[userID] = &models.UserConfig{UserID: userID, Course: "toeic", Level: 600}
+	}
+	replyGuard := utils.NewReplyTokenGuard(entry, h.linebot)
+	h.Handler = &Handler{
+		logger: entry,
+		envVars: &EnvVars{
+			vocabularyFunctionArn: "arn:aws:lambda:ap-northeast-1:123456789012:function:language-vocabulary",
+			schedulerRoleArn:      "arn:aws:iam::123456789012:role/scheduler",
+			pushMode:              utils.PushModeSchedule,
+		},
+		linebotClient:     replyGuard,
+		replyGuard:        replyGuard,
+		lineDestination:   "Ubot",
+		userConfigRepo:    h.users,
+		setupDraftRepo:    h.drafts,
+		interactionRepo:   discardInteractions{},
+		analyticsRepo:     discardAnalytics{},
+		scheduleAuditRepo: discardScheduleAudit{},
+		lambdaClient:      h.lambda,
+		schedulerClient:   utils.NewMockScheduler(),
+		shutdown:          utils.NewShutdown(entry),
+	}
+	return h
+}
+
+// postbackEvent 回傳 webhook body 中的一個 postback 事件，reply token 為 userID/step
+func postbackEvent(userID, step, data string) map[string]any {
+	return map[string]any{
+		"type":       "postback",
+		"mode":       "active",
+		"timestamp":  time.Now().UnixMilli(),
+		"replyToken": userID + "/" + step,
+		"source":     map[string]string{"type": "user", "userId": userID},
+		"postback":   map[string]string{"data": data},
+	}
+}
+
+// setupBatch 回傳每位用戶完整的自訂推播設定流程，不同用戶的事件交錯排列，模擬同一次 webhook 送來多位用戶的事件
+func setupBatch(userIDs []string) (events.APIGatewayProxyRequest, map[string]string) {
+	pushTimes := map[string]string{}
+	steps := make([][]map[string]any, len(userIDs))
+	for i, userID := range userIDs {
+		pushTimes[userID] = fmt.Sprintf("%02d:00", 6+i)
+		steps[i] = []map[string]any{
+			postbackEvent(userID, "custom", "action="+messages.PostbackPushSettings+"&step="+messages.PushSettingsStepCustom),
+			postbackEvent(userID, "words", fmt.Sprintf("action=%s&count=%d", messages.PostbackDailyWords, messages.DailyWordsOptions[i%len(messages.DailyWordsOptions)])),
+			postbackEvent(userID, "time", "action="+messages.PostbackPushTime+"&time="+pushTimes[userID]),
+			postbackEvent(userID, "timezone", "action="+messages.PostbackTimezone+"&tz=Asia/Tokyo"),
+		}
+	}
+
+	var batch []map[string]any
+	for step := 0; step < 4; step++ {
+		for i := range userIDs {
+			batch = append(batch, steps[i][step])
+		}
+	}
+	body, _ := json.Marshal(map[string]any{"destination": "Ubot", "events": batch})
+	return events.APIGatewayProxyRequest{Body: string(body)}, pushTimes
+}
+
+// 以 go test -race 執行時，驗證同一批次中多位用戶的推播設定平行處理時互不干擾
+func TestEventHandlerConcurrentSetup(t *testing.T) {
+	userIDs := []string{"U1", "U2", "U3", "U4", "U5", "U6", "U7", "U8"}
+	h := newEventTestHandler(userIDs...)
+	request, pushTimes := setupBatch(userIDs)
+
+	response, err := h.EventHandler(request)
+	h.shutdown.Flush(context.Background())
+	if err != nil || response.StatusCode != 200 {
+		t.Fatalf("Expected 200, got %d (err %v)", response.StatusCode, err)
+	}
+
+	for i, userID := range userIDs {
+		config, _ := h.users.GetUserConfig(userID)
+		dailyWords := messages.DailyWordsOptions[i%len(messages.DailyWordsOptions)]
+		if config.PushTime != pushTimes[userID] || config.DailyWords != dailyWords || config.Timezone != "Asia/Tokyo" || config.Course != "toeic" {
+			t.Errorf("Expected %s to save %s with %d words, got %+v", userID, pushTimes[userID], dailyWords, config)
+		}
+		if config.ScheduleName != utils.ScheduleName(userID) {
+			t.Errorf("Expected a schedule for %s, got %q", userID, config.ScheduleName)
+		}
+		if config.LineDestination != "Ubot" {
+			t.Errorf("Expected %s to be pushed from the account they set up on, got %q", userID, config.LineDestination)
+		}
+	}
+	if len(h.drafts.drafts) != 0 {
+		t.Errorf("Expected every setup draft to be deleted, got %+v", h.drafts.drafts)
+	}
+	if len(h.linebot.replies) != 4*len(userIDs) {
+		t.Errorf("Expected one reply per event, got %d", len(h.linebot.replies))
+	}
+}
+
+func TestGroupEventsBySource(t *testing.T) {
+	event := func(id string, source linebot.EventSource) *linebot.Event {
+		return &linebot.Event{WebhookEventID: id, Source: &source}
+	}
+	batch := []*linebot.Event{
+		event("u1-a", linebot.EventSource{Type: linebot.EventSourceTypeUser, UserID: "U1"}),
+		event("g1-a", linebot.EventSource{Type: linebot.EventSourceTypeGroup, GroupID: "C1", UserID: "U1"}),
+		event("u2-a", linebot.EventSource{Type: linebot.EventSourceTypeUser, UserID: "U2"}),
+		event("u1-b", linebot.EventSource{Type: linebot.EventSourceTypeUser, UserID: "U1"}),
+		event("r1-a", linebot.EventSource{Type: linebot.EventSourceTypeRoom, RoomID: "R1", UserID: "U2"}),
+		event("g1-b", linebot.EventSource{Type: linebot.EventSourceTypeGroup, GroupID: "C1", UserID: "U3"}),
+		event("u1-c", linebot.EventSource{Type: linebot.EventSourceTypeUser, UserID: "U1"}),
+	}
+
+	var got [][]string
+	for _, group := range groupEventsBySource(batch) {
+		var ids []string
+		for _, e := range group {
+			ids = append(ids, e.WebhookEventID)
+		}
+		got = append(got, ids)
+	}
+
+	// 一對一聊天、群組與聊天室各自分組（同一位用戶在群組中的訊息不與一對一聊天合併），組內保持原本的順序
+	expected := [][]string{{"u1-a", "u1-b", "u1-c"}, {"g1-a", "g1-b"}, {"u2-a"}, {"r1-a"}}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -4,10 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"language-assistant/internal/courses"
+	"language-assistant/internal/messages"
 	"language-assistant/internal/models"
 	"language-assistant/internal/utils"
+	"math"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,33 +26,124 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
 	"github.com/line/line-bot-sdk-go/v7/linebot"
 	"github.com/sirupsen/logrus"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/sync/errgroup"
 )
 
+// rollout 設定快取時間，避免每則訊息都讀取 DynamoDB
+const promptRolloutCacheTTL = time.Minute
+
+// lastActiveAt 最多每小時寫入一次，避免每則訊息都寫 DynamoDB
+const activityWriteInterval = time.Hour
+
+// 取消追蹤後單字紀錄保留 30 天，期間內重新加入可恢復
+const unfollowVocabularyRetention = 30 * 24 * time.Hour
+
+// 一次 webhook 最多同時處理幾個來源的事件，避免同時發出過多 OpenAI 請求
+const maxConcurrentEventSources = 4
+
+// 已處理的 webhook event ID 保留 7 天，遠超過 LINE 重送的期間
+const webhookEventRetention = 7 * 24 * time.Hour
+
+// 推播設定流程中途離開時，暫存的選擇保留 1 天
+const setupDraftRetention = 24 * time.Hour
+
+// Handler processes LINE webhook events. Safe for concurrent use: dependencies are read-only after NewHandler,
+// rnd is a locked source and the rollout cache has its own lock; per-event state stays on the stack, and state
+// that spans several webhooks (the push settings draft) is stored in DynamoDB
 type Handler struct {
-	logger          *logrus.Entry
-	envVars         *EnvVars
-	linebotClient   utils.LinebotAPI
-	openaiClient    utils.OpenaiAPI
-	vocabularyRepo  utils.VocabularyRepository
-	userConfigRepo  utils.UserConfigRepository
-	lambdaClient    *lambda.Client
-	schedulerClient *scheduler.Client
+	logger            *logrus.Entry
+	envVars           *EnvVars
+	linebotClient     utils.LinebotAPI // replyGuard 包裝後的 client
+	replyGuard        *utils.ReplyTokenGuard
+	lineDestination   string // 這個 handler 服務的官方帳號（webhook 的 destination），預設 channel 為空字串
+	openaiClient      utils.OpenaiAPI
+	vocabularyRepo    utils.VocabularyRepository
+	userConfigRepo    utils.UserConfigRepository
+	scheduleAuditRepo utils.ScheduleAuditRepository
+	pushLogRepo       utils.PushLogRepository
+	media             *utils.MediaService
+	pairingRepo       utils.PairingRepository
+	promptRolloutRepo utils.PromptRolloutRepository
+	experimentRepo    utils.CardFormatExperimentRepository
+	interactionRepo   utils.InteractionRepository
+	analyticsRepo     utils.AnalyticsRepository
+	quizRepo          utils.QuizRepository
+	reviewRepo        utils.ReviewRepository
+	deckRepo          utils.DeckRepository
+	wordHistoryRepo   utils.WordHistoryRepository
+	bloomFilterRepo   utils.BloomFilterRepository
+	webhookEventRepo  utils.WebhookEventRepository
+	streakRepo        utils.StreakRepository
+	contentReportRepo utils.ContentReportRepository
+	setupDraftRepo    utils.SetupDraftRepository
+	failureReporter   *utils.FailureReporter
+	usageLimiter      *utils.UsageLimiter
+	rateLimiter       *utils.RateLimiter
+	translationCache  *utils.TranslationCache // nil 表示不使用翻譯快取
+	tokenUsage        *utils.TokenUsageRecorder
+	payments          *utils.PaymentService // nil 表示未開放線上付款
+	featureGate       *utils.FeatureGate
+	killSwitches      *utils.KillSwitchCache
+	consents          *utils.ConsentChecker
+	lambdaClient      utils.LambdaInvoker
+	schedulerClient   utils.SchedulerAPI
+	shutdown          *utils.Shutdown // 追蹤回覆後仍在跑的背景工作，main 在每次 invocation 結束前 Flush
+	rnd               *rand.Rand      // utils.NewConcurrentRand，可跨 goroutine 共用
+
+	translationRollout *utils.PromptRolloutCache
 }
 
-func NewHandler(logger *logrus.Entry, envVars *EnvVars, linebotClient utils.LinebotAPI, openaiClient utils.OpenaiAPI, vocabularyRepo utils.VocabularyRepository, userConfigRepo utils.UserConfigRepository, lambdaClient *lambda.Client, schedulerClient *scheduler.Client) (*Handler, error) {
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, linebotClient utils.LinebotAPI, lineDestination string, openaiClient utils.OpenaiAPI, vocabularyRepo utils.VocabularyRepository, userConfigRepo utils.UserConfigRepository, scheduleAuditRepo utils.ScheduleAuditRepository, pushLogRepo utils.PushLogRepository, media *utils.MediaService, pairingRepo utils.PairingRepository, promptRolloutRepo utils.PromptRolloutRepository, experimentRepo utils.CardFormatExperimentRepository, interactionRepo utils.InteractionRepository, analyticsRepo utils.AnalyticsRepository, quizRepo utils.QuizRepository, reviewRepo utils.ReviewRepository, deckRepo utils.DeckRepository, wordHistoryRepo utils.WordHistoryRepository, bloomFilterRepo utils.BloomFilterRepository, webhookEventRepo utils.WebhookEventRepository, streakRepo utils.StreakRepository, contentReportRepo utils.ContentReportRepository, setupDraftRepo utils.SetupDraftRepository, failureReporter *utils.FailureReporter, usageLimiter *utils.UsageLimiter, rateLimiter *utils.RateLimiter, translationCache *utils.TranslationCache, tokenUsage *utils.TokenUsageRecorder, payments *utils.PaymentService, featureGate *utils.FeatureGate, killSwitches *utils.KillSwitchCache, consents *utils.ConsentChecker, lambdaClient utils.LambdaInvoker, schedulerClient utils.SchedulerAPI, shutdown *utils.Shutdown) (*Handler, error) {
+	// reply token 快過期時改用 push，handler 內的回覆一律經過 replyGuard
+	replyGuard := utils.NewReplyTokenGuard(logger, linebotClient)
 	return &Handler{
-		logger:          logger,
-		envVars:         envVars,
-		linebotClient:   linebotClient,
-		openaiClient:    openaiClient,
-		vocabularyRepo:  vocabularyRepo,
-		userConfigRepo:  userConfigRepo,
-		lambdaClient:    lambdaClient,
-		schedulerClient: schedulerClient,
+		logger:            logger,
+		envVars:           envVars,
+		linebotClient:     replyGuard,
+		replyGuard:        replyGuard,
+		lineDestination:   lineDestination,
+		openaiClient:      openaiClient,
+		vocabularyRepo:    vocabularyRepo,
+		userConfigRepo:    userConfigRepo,
+		scheduleAuditRepo: scheduleAuditRepo,
+		pushLogRepo:       pushLogRepo,
+		media:             media,
+		pairingRepo:       pairingRepo,
+		promptRolloutRepo: promptRolloutRepo,
+		experimentRepo:    experimentRepo,
+		interactionRepo:   interactionRepo,
+		analyticsRepo:     analyticsRepo,
+		quizRepo:          quizRepo,
+		reviewRepo:        reviewRepo,
+		deckRepo:          deckRepo,
+		wordHistoryRepo:   wordHistoryRepo,
+		bloomFilterRepo:   bloomFilterRepo,
+		webhookEventRepo:  webhookEventRepo,
+		streakRepo:        streakRepo,
+		contentReportRepo: contentReportRepo,
+		setupDraftRepo:    setupDraftRepo,
+		failureReporter:   failureReporter,
+		usageLimiter:      usageLimiter,
+		rateLimiter:       rateLimiter,
+		translationCache:  translationCache,
+		tokenUsage:        tokenUsage,
+		payments:          payments,
+		featureGate:       featureGate,
+		killSwitches:      killSwitches,
+		consents:          consents,
+		lambdaClient:      lambdaClient,
+		schedulerClient:   schedulerClient,
+		shutdown:          shutdown,
+		rnd:               utils.NewConcurrentRand(time.Now().UnixNano()),
+
+		translationRollout: utils.NewPromptRolloutCache(promptRolloutCacheTTL),
 	}, nil
 }
 
 func (h *Handler) EventHandler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// reply token 的有效時間從收到 webhook 起算
+	receivedAt := time.Now()
 	messageEvents, err := h.RequestParser(request)
 	if err != nil {
 		h.logger.Error("Failed to parse request: ", err)
@@ -54,100 +153,375 @@ func (h *Handler) EventHandler(request events.APIGatewayProxyRequest) (events.AP
 		}, nil
 	}
 
-	// Process each message event
-	for _, event := range messageEvents {
-		h.logger.WithFields(logrus.Fields{
-			"event_type": event.Type,
-			"user_id":    event.Source.UserID,
-			"room_id":    event.Source.RoomID,
-			"group_id":   event.Source.GroupID,
-		}).Info("event handling")
-
-		if event.Type == linebot.EventTypeFollow {
-			h.handleUserFollow(event.ReplyToken, event.Source.UserID)
-			continue
-		}
+	h.captureWebhook(request.Body, messageEvents)
+
+	// 同一個來源（用戶、群組或聊天室）的事件依序處理，不同來源的事件平行處理
+	g := new(errgroup.Group)
+	g.SetLimit(maxConcurrentEventSources)
+	for _, sourceEvents := range groupEventsBySource(messageEvents) {
+		g.Go(func() error {
+			for _, event := range sourceEvents {
+				h.replyGuard.Track(event.ReplyToken, utils.ReplyTarget(event.Source), receivedAt)
+				err := h.handleEvent(event, request.RequestContext.RequestID)
+				h.replyGuard.Forget(event.ReplyToken)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return events.APIGatewayProxyResponse{
+			Body:       err.Error(),
+			StatusCode: 500,
+		}, nil
+	}
 
-		if event.Type == linebot.EventTypeMessage {
-			switch message := event.Message.(type) {
-			case *linebot.TextMessage:
-				h.logger.WithField("text", message.Text).Info("Received text message")
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       "OK",
+	}, nil
+}
 
-				// 檢查用戶是否已有設定
-				userConfig, err := h.userConfigRepo.GetUserConfig(event.Source.UserID)
-				if err != nil {
-					h.logger.WithError(err).Error("Failed to get user config")
+// handleEvent 處理單一事件；回傳錯誤時整個 webhook 回應 500 讓 LINE 重送
+func (h *Handler) handleEvent(event *linebot.Event, requestID string) error {
+	h.logger.WithFields(logrus.Fields{
+		"event_type": event.Type,
+		"user_id":    event.Source.UserID,
+		"room_id":    event.Source.RoomID,
+		"group_id":   event.Source.GroupID,
+	}).Info("event handling")
+
+	// LINE 重送的事件已處理過，略過以免重複儲存單字與回覆
+	if !h.claimWebhookEvent(event) {
+		return nil
+	}
+
+	if event.Type == linebot.EventTypeFollow {
+		h.handleUserFollow(event.ReplyToken, event.Source.UserID)
+		h.recordActivity(event.Source.UserID, nil)
+		return nil
+	}
+
+	if event.Type == linebot.EventTypeUnfollow {
+		h.handleUserUnfollow(event.Source.UserID)
+		return nil
+	}
+
+	// bot 被加入群組時說明群組中可以使用的指令
+	if event.Type == linebot.EventTypeJoin && event.Source.GroupID != "" {
+		h.sendGroupGreeting(event.ReplyToken)
+		return nil
+	}
+
+	if event.Type == linebot.EventTypePostback {
+		h.handlePostback(event.ReplyToken, event.Source.UserID, event.Postback.Data)
+		h.recordActivity(event.Source.UserID, nil)
+		return nil
+	}
+
+	if event.Type == linebot.EventTypeMessage {
+		switch message := event.Message.(type) {
+		case *linebot.TextMessage:
+			h.logger.WithField("text", message.Text).Info("Received text message")
+			// 英文指令別名（例如 /help）轉成對應的中文指令
+			text := utils.NormalizeCommand(message.Text)
+
+			// 群組中只回應群組指令，查過的單字存進群組的共用單字庫
+			if event.Source.GroupID != "" {
+				return h.handleGroupMessage(event, text, requestID)
+			}
+
+			// 檢查用戶是否已有設定
+			userConfig, err := h.userConfigRepo.GetUserConfig(event.Source.UserID)
+			if err != nil {
+				h.logger.WithError(err).Error("Failed to get user config")
+			}
+			h.recordActivity(event.Source.UserID, userConfig)
+
+			// 服務條款更新後，同意前不提供付費功能
+			if !h.requireTerms(event.ReplyToken, event.Source.UserID, text, userConfig) {
+				return nil
+			}
+
+			// 只有 emoji 的訊息沒有可翻譯的內容，不送去 OpenAI 也不計入翻譯額度
+			if utils.IsEmojiOnly(text, message.Emojis) {
+				h.replyNonText(event.ReplyToken, messages.EmojiOnlyReceived)
+				return nil
+			}
+
+			// 推播設定選擇時區的步驟中，用戶可以直接輸入 IANA 時區名稱
+			if utils.LooksLikeTimezone(text) && h.setupDraft(event.Source.UserID).PushTime != "" {
+				h.handleTimezoneInput(event.ReplyToken, event.Source.UserID, text, userConfig)
+				return nil
+			}
+
+			// 舊版課程選單的按鈕送出的是文字訊息，聊天紀錄中的舊選單仍可能被點擊
+			if course, ok := courses.FromInterestText(text); ok {
+				h.handleCourseInterest(event.ReplyToken, userConfig.DisplayName, event.Source.UserID, course)
+				return nil
+			}
+
+			switch text {
+			case "/說明":
+				h.sendGreetingMessage(event.ReplyToken, false)
+				return nil
+			case "/設定推播":
+				h.handlePushSettingsStart(event.ReplyToken)
+				return nil
+			case "/設定推播詳細":
+				h.handlePushSettings(event.ReplyToken, event.Source.UserID, userConfig)
+				return nil
+			case "/使用預設設定":
+				h.handleSkipPushSettings(event.ReplyToken, event.Source.UserID, userConfig)
+				return nil
+			case "/個人設定":
+				h.handleShowUserSettings(event.ReplyToken, event.Source.UserID)
+				return nil
+			case "/推播紀錄":
+				h.handleShowPushHistory(event.ReplyToken, event.Source.UserID, userConfig)
+				return nil
+			case "/統計":
+				h.handleVocabularyStats(event.ReplyToken, event.Source.UserID)
+				return nil
+			case "/歷史":
+				h.handleVocabularyHistory(event.ReplyToken, event.Source.UserID, "")
+				return nil
+			case "/隱私設定":
+				h.handlePrivacySettings(event.ReplyToken, userConfig)
+				return nil
+			case "/登入網頁":
+				h.handleWebLogin(event.ReplyToken, event.Source.UserID)
+				return nil
+			case "/測驗":
+				h.handleQuizStart(event.ReplyToken, event.Source.UserID, userConfig, "")
+				return nil
+			case "/今日單字":
+				h.handleTodayWords(event.ReplyToken, event.Source.UserID, userConfig)
+				return nil
+			case "/加入測試":
+				h.handleJoinBeta(event.ReplyToken, event.Source.UserID, userConfig)
+				return nil
+			case "/支持":
+				h.handleSupporterPayment(event.ReplyToken, event.Source.UserID, userConfig)
+				return nil
+			case "/試用":
+				h.handleTrial(event.ReplyToken, event.Source.UserID, userConfig)
+				return nil
+			default:
+				// 帶參數的指令
+				if strings.HasPrefix(text, "/重發") {
+					h.handleRepush(event.ReplyToken, event.Source.UserID, strings.TrimSpace(strings.TrimPrefix(text, "/重發")))
+					return nil
 				}
 
-				switch message.Text {
-				case "/說明":
-					h.sendGreetingMessage(event.ReplyToken)
-					continue
-				case "我對多益有興趣":
-					h.handleCourseInterest(event.ReplyToken, userConfig.DisplayName, event.Source.UserID, "toeic")
-					continue
-				case "我對雅思有興趣":
-					h.handleCourseInterest(event.ReplyToken, userConfig.DisplayName, event.Source.UserID, "ielts")
-					continue
-				case "/設定推播":
-					h.handlePushSettingsStart(event.ReplyToken)
-					continue
-				case "/設定推播詳細":
-					h.handlePushSettings(event.ReplyToken, event.Source.UserID, userConfig)
-					continue
-				case "/使用預設設定":
-					h.handleSkipPushSettings(event.ReplyToken, event.Source.UserID, userConfig)
-					continue
-				case "/個人設定":
-					h.handleShowUserSettings(event.ReplyToken, event.Source.UserID)
-					continue
-				default:
-					// 檢查是否是無效的 "/" 命令
-					if strings.HasPrefix(message.Text, "/") {
-						h.linebotClient.ReplyMessage(event.ReplyToken, "❌ 目前無此設定\n\n可使用的指令：\n• /說明 - 查看使用說明\n• /設定推播 - 設定推播選項\n• /個人設定 - 查看個人設定")
-						continue
-					}
+				if strings.HasPrefix(text, "/查詢") {
+					h.handleWordSearch(event.ReplyToken, event.Source.UserID, strings.TrimSpace(strings.TrimPrefix(text, "/查詢")))
+					return nil
+				}
 
-					// 檢查是否是推播設定相關的回應
-					if h.handlePushSettingsResponse(event.ReplyToken, event.Source.UserID, message.Text, userConfig) {
-						continue
-					}
-					// 檢查是否是數字（可能是分數輸入）
-					if h.handleScoreInput(event.ReplyToken, userConfig.DisplayName, event.Source.UserID, message.Text) {
-						continue
-					}
+				if strings.HasPrefix(text, "/重置單字庫") {
+					h.handleResetWordBank(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/重置單字庫")))
+					return nil
+				}
 
-					// 原本的翻譯邏輯
-					translationResponse, err := h.openaiClient.Translate(message.Text)
-					if err != nil {
-						h.logger.WithError(err).Error("Failed to translate valid text")
-						return events.APIGatewayProxyResponse{
-							Body:       err.Error(),
-							StatusCode: 500,
-						}, nil
-					}
-					h.logger.Info("Translation response: ", translationResponse)
+				if strings.HasPrefix(text, "/重置單字紀錄") {
+					h.handleResetWordHistory(event.ReplyToken, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/重置單字紀錄")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/複習來源") {
+					h.handleReviewSource(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/複習來源")))
+					return nil
+				}
 
-					for _, translation := range translationResponse.Translations {
-						if err := h.vocabularyRepo.SaveWord(translation.Word, translation.PartOfSpeech, translation.Meaning, translation.Example.En, event.Source.UserID); err != nil {
-							h.logger.Error("Failed to save word: ", err)
-							continue
-						}
+				if strings.HasPrefix(text, "/難度調整") {
+					h.handleDifficulty(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/難度調整")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/低階單字") {
+					h.handleLowerBandWords(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/低階單字")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/公開檔案") {
+					h.handlePublicProfile(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/公開檔案")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/字卡格式") {
+					h.handleCardFormat(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/字卡格式")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/發音") {
+					h.handlePronunciation(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/發音")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/模型") {
+					h.handleModel(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/模型")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/文法模式") {
+					h.handleGrammarMode(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/文法模式")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/互動複習") {
+					h.handleInteractiveReview(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/互動複習")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/測驗") {
+					h.handleQuizStart(event.ReplyToken, event.Source.UserID, userConfig, strings.TrimSpace(strings.TrimPrefix(text, "/測驗")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/建立清單") {
+					h.handleCreateDeck(event.ReplyToken, event.Source.UserID, strings.TrimPrefix(text, "/建立清單"))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/清單") {
+					h.handleDecks(event.ReplyToken, event.Source.UserID, strings.TrimSpace(strings.TrimPrefix(text, "/清單")))
+					return nil
+				}
+
+				if strings.HasPrefix(text, "/匯出") {
+					h.handleExportStudySheet(event.ReplyToken, event.Source.UserID, strings.Fields(strings.TrimPrefix(text, "/匯出")))
+					return nil
+				}
+
+				// 檢查是否是無效的 "/" 命令
+				if strings.HasPrefix(text, "/") {
+					h.linebotClient.ReplyMessage(event.ReplyToken, messages.Text(messages.UnknownCommand))
+					return nil
+				}
+
+				// 檢查是否是數字（可能是分數輸入）
+				if h.handleScoreInput(event.ReplyToken, userConfig.DisplayName, event.Source.UserID, text) {
+					return nil
+				}
+
+				// 以下的翻譯、摘要與文法修正都計入方案的每日翻譯額度
+				if !h.allowTranslation(event.ReplyToken, event.Source.UserID, userConfig) {
+					return nil
+				}
+
+				// 貼上長文時改用摘要翻譯，只挑出關鍵單字
+				if utils.IsLongInput(text) {
+					h.handleArticleSummary(event.ReplyToken, event.Source.UserID, text, requestID)
+					return nil
+				}
+
+				// 開啟文法模式的用戶輸入完整句子時改為修正文法
+				if userConfig != nil && userConfig.GrammarMode && utils.IsFullSentence(text) {
+					h.handleGrammarCorrection(event.ReplyToken, event.Source.UserID, text, requestID, userConfig)
+					return nil
+				}
+
+				// 原本的翻譯邏輯（依 prompt rollout 決定使用的 prompt 版本；中日翻譯不參與 rollout）
+				// 常見單字使用翻譯快取；參與 rollout 的翻譯不使用快取，讓實驗比較的是模型實際的輸出
+				language := utils.TranslationLanguage(text, userConfig.Course)
+				var rollout *models.PromptRollout
+				var promptVersion string
+				if language != courses.LanguageJapanese {
+					rollout, promptVersion = h.selectTranslationPrompt(event.Source.UserID)
+				}
+				translationResponse, cached, err := h.translationCache.Translate(h.openaiClient, text, utils.TranslateOptions{PromptVersion: promptVersion, Model: userConfig.Model, Language: language, SampleFailures: userConfig.HasConsent(models.ConsentAIData), BypassCache: rollout != nil})
+				h.recordTranslationOutcome(rollout, promptVersion, err)
+				h.tokenUsage.Record(event.Source.UserID, utils.FeatureTranslation, translationResponse.Usage)
+				if utils.IsRateLimited(err) {
+					// 重試後仍被限流，請用戶稍後再試，不讓 LINE 重送以免加重負載
+					h.logger.WithError(err).Warn("OpenAI rate limited translation")
+					h.linebotClient.ReplyMessage(event.ReplyToken, messages.Text(messages.OpenAIRateLimited))
+					return nil
+				}
+				if err != nil {
+					h.logger.WithError(err).Error("Failed to translate valid text")
+					h.failureReporter.Report(event.Source.UserID, models.FailureTranslation, requestID, err)
+					// 回傳 500 讓 LINE 重送，因此取消登記讓重送的事件可以重新處理
+					h.releaseWebhookEvent(event.WebhookEventID)
+					return err
+				}
+				h.logger.WithField("cached", cached).Info("Translation response: ", translationResponse)
+
+				// 檢查例句的中英文是否對應，不一致的重新生成後再儲存與回覆（檢查規則只適用英文例句）
+				if language == courses.LanguageEnglish {
+					regenerated, errs := utils.ValidateTranslationExamples(h.openaiClient, translationResponse.Translations)
+					for _, err := range errs {
+						h.logger.WithError(err).Warn("Failed to regenerate example sentence")
+					}
+					if regenerated > 0 {
+						h.logger.Infof("Regenerated %d mismatched example sentences", regenerated)
 					}
-					// Reply with the same message
-					if err := h.linebotClient.ReplyMessage(event.ReplyToken, translationResponse.String()); err != nil {
-						h.logger.Error("Failed to reply message: ", err)
+				}
+
+				for _, translation := range translationResponse.Translations {
+					if err := h.vocabularyRepo.SaveWord(translation.Word, translation.PartOfSpeech, translation.Meaning, translation.Example.En, models.WordSourceTranslation, event.Source.UserID, h.recordExpiresAt()); err != nil {
+						h.logger.Error("Failed to save word: ", err)
 						continue
 					}
 				}
+				h.recordStreak(event.Source.UserID, userConfig)
+				// Reply with the same message
+				replies := messages.TextMessages(translationResponse.Texts())
+				h.attachTranslationReplies(event.Source.UserID, language, text, translationResponse.Translations, replies)
+				// 翻譯結果很多時超過單次回覆上限，其餘改用推播（按鈕附在最後一則）
+				if err := h.replyAndPushRest(event.ReplyToken, event.Source.UserID, replies); err != nil {
+					h.logger.Error("Failed to reply message: ", err)
+					return nil
+				}
+			}
+		case *linebot.StickerMessage:
+			// 群組中的貼圖不回應，避免打擾群組聊天
+			if event.Source.GroupID != "" || event.Source.RoomID != "" {
+				return nil
 			}
+			h.logger.WithFields(logrus.Fields{"packageId": message.PackageID, "stickerId": message.StickerID}).Info("Received sticker message")
+			h.recordActivity(event.Source.UserID, nil)
+			h.replyNonText(event.ReplyToken, messages.StickerReceived)
 		}
 	}
 
-	return events.APIGatewayProxyResponse{
-		StatusCode: 200,
-		Body:       "OK",
-	}, nil
+	return nil
+}
+
+// replyNonText 以簡短的提示與貼圖回覆貼圖或只有 emoji 的訊息，引導用戶輸入要翻譯的文字
+func (h *Handler) replyNonText(replyToken string, key messages.Key) {
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken,
+		linebot.NewTextMessage(messages.Text(key)),
+		linebot.NewStickerMessage(utils.ReplyStickerPackageID, utils.ReplyStickerID),
+	); err != nil {
+		h.logger.WithError(err).Warn("Failed to reply to non-text message")
+	}
+}
+
+// groupEventsBySource 依來源分組並保留各組內的事件順序，組的順序為來源第一次出現的順序
+func groupEventsBySource(messageEvents []*linebot.Event) [][]*linebot.Event {
+	var groups [][]*linebot.Event
+	index := map[string]int{}
+	for _, event := range messageEvents {
+		key := event.Source.UserID
+		if event.Source.GroupID != "" {
+			key = "group#" + event.Source.GroupID
+		} else if event.Source.RoomID != "" {
+			key = "room#" + event.Source.RoomID
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], event)
+	}
+	return groups
 }
 
 func (h *Handler) RequestParser(request events.APIGatewayProxyRequest) ([]*linebot.Event, error) {
@@ -183,15 +557,267 @@ func (h *Handler) RequestParser(request events.APIGatewayProxyRequest) ([]*lineb
 	return messageEvents, nil
 }
 
+// captureWebhook 將被標記用戶的 webhook payload 去識別化後存到 S3，供 cmd/webhook-replay 重播除錯。
+// 擷取失敗不影響訊息處理
+func (h *Handler) captureWebhook(body string, messageEvents []*linebot.Event) {
+	now := time.Now().UTC()
+
+	checked := map[string]bool{}
+	for _, event := range messageEvents {
+		userID := event.Source.UserID
+		if userID == "" || checked[userID] {
+			continue
+		}
+		checked[userID] = true
+
+		userConfig, err := h.userConfigRepo.GetUserConfig(userID)
+		if err != nil || userConfig == nil || userConfig.WebhookCaptureUntil == "" {
+			continue
+		}
+		until, err := time.Parse(time.RFC3339, userConfig.WebhookCaptureUntil)
+		if err != nil || now.After(until) {
+			continue
+		}
+
+		sanitized, err := utils.SanitizeWebhookBody([]byte(body), func(id string) bool { return id == userID })
+		if err != nil || sanitized == nil {
+			continue
+		}
+		if err := h.media.Store(utils.WebhookCaptureKey(userID, now), sanitized); err != nil {
+			h.logger.WithError(err).WithField("userId", userID).Warn("Failed to capture webhook payload")
+		}
+	}
+}
+
+// handlePostback 處理按鈕的 postback，data 為 URL query string，例如 action=word_ack&format=image&date=2025-01-02
+func (h *Handler) handlePostback(replyToken, userID, data string) {
+	values, err := url.ParseQuery(data)
+	if err != nil {
+		h.logger.WithError(err).WithField("data", data).Warn("Failed to parse postback data")
+		return
+	}
+
+	switch values.Get("action") {
+	case models.PostbackWordAck:
+		// 只有隨機分派的推播才計入 A/B 指標，避免自選格式的用戶影響比較結果
+		if values.Get("exp") == "1" {
+			if format, err := models.ParseCardFormat(values.Get("format")); err != nil {
+				h.logger.WithError(err).Warn("Ignoring word ack with unknown card format")
+			} else if err := h.experimentRepo.RecordCardFormatEngagement(values.Get("date"), format); err != nil {
+				h.logger.WithError(err).Warn("Failed to record card format engagement")
+			}
+		}
+
+		var latency time.Duration
+		if sent, err := strconv.ParseInt(values.Get("sent"), 10, 64); err == nil {
+			latency = time.Since(time.Unix(sent, 0))
+		}
+		h.recordInteraction(userID, models.FeatureDailyPush, models.InteractionWordAck, values.Get("format"), latency)
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DailyPushAckReply))
+	case models.PostbackQuizAnswer:
+		h.handleQuizAnswer(replyToken, userID, values)
+	case models.PostbackReviewResponse:
+		h.handleReviewResponse(replyToken, userID, values)
+	case models.PostbackReviewAdd:
+		h.handleReviewAdd(replyToken, userID, values)
+	case models.PostbackDeckAdd:
+		h.handleDeckAdd(replyToken, userID, values)
+	case models.PostbackRegenerateExample:
+		h.handleRegenerateExample(replyToken, userID, values)
+	case models.PostbackResetWordHistory:
+		h.handleResetWordHistoryConfirmed(replyToken, userID, values)
+	case models.PostbackContentReport:
+		h.handleContentReport(replyToken, userID, values)
+	case models.PostbackVocabularyHistory:
+		before := values.Get("before")
+		if _, err := time.Parse("2006-01-02", before); err != nil {
+			h.logger.WithField("before", before).Warn("Ignoring vocabulary history page with invalid date")
+			return
+		}
+		h.handleVocabularyHistory(replyToken, userID, before)
+	case messages.PostbackCourseInterest, messages.PostbackPushSettings, messages.PostbackDailyWords, messages.PostbackPushTime, messages.PostbackTimezone:
+		h.handleSetupPostback(replyToken, userID, values)
+	case messages.PostbackTranslationOnly:
+		h.handleTranslationOnly(replyToken, userID)
+	case messages.PostbackConsent:
+		h.handleConsentPostback(replyToken, userID, values)
+	case messages.PostbackTermsAccept:
+		h.handleTermsAccept(replyToken, userID, values.Get("version"))
+	default:
+		h.logger.WithField("data", data).Warn("Unknown postback action")
+	}
+}
+
+// handleSetupPostback 處理課程選單與推播設定流程的按鈕，用 postback 傳遞選項避免用戶輸入的文字被誤判為設定
+func (h *Handler) handleSetupPostback(replyToken, userID string, values url.Values) {
+	userConfig, err := h.userConfigRepo.GetUserConfig(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get user config")
+	}
+
+	switch values.Get("action") {
+	case messages.PostbackCourseInterest:
+		course, ok := courses.Get(values.Get("course"))
+		if !ok {
+			h.logger.WithField("course", values.Get("course")).Warn("Unknown course in postback")
+			return
+		}
+		var displayName string
+		if userConfig != nil {
+			displayName = userConfig.DisplayName
+		}
+		h.handleCourseInterest(replyToken, displayName, userID, course)
+	case messages.PostbackPushSettings:
+		switch values.Get("step") {
+		case messages.PushSettingsStepStart:
+			h.handlePushSettingsStart(replyToken)
+		case messages.PushSettingsStepCustom:
+			h.handlePushSettings(replyToken, userID, userConfig)
+		case messages.PushSettingsStepDefault:
+			h.handleSkipPushSettings(replyToken, userID, userConfig)
+		default:
+			h.logger.WithField("step", values.Get("step")).Warn("Unknown push settings step in postback")
+		}
+	case messages.PostbackDailyWords:
+		dailyWords, err := strconv.Atoi(values.Get("count"))
+		if err != nil || !slices.Contains(messages.DailyWordsOptions, dailyWords) {
+			h.logger.WithField("count", values.Get("count")).Warn("Unknown daily words value in postback")
+			return
+		}
+		h.handleDailyWordsSelection(replyToken, userID, dailyWords)
+	case messages.PostbackPushTime:
+		pushTime := values.Get("time")
+		if _, err := time.Parse("15:04", pushTime); err != nil {
+			h.logger.WithField("time", pushTime).Warn("Invalid push time in postback")
+			return
+		}
+		h.handlePushTimeSelection(replyToken, userID, pushTime)
+	case messages.PostbackTimezone:
+		timezone, err := utils.ValidateTimezone(values.Get("tz"))
+		if err != nil {
+			h.logger.WithError(err).Warn("Invalid timezone in postback")
+			return
+		}
+		h.handleTimezoneSelection(replyToken, userID, timezone, userConfig)
+	}
+}
+
+// handleTranslationOnly 用戶選擇只用翻譯功能：停止每日推播與設定提醒，之後選擇課程（/設定推播）時會自動恢復
+func (h *Handler) handleTranslationOnly(replyToken, userID string) {
+	if err := h.userConfigRepo.SetTranslationOnly(userID, true); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to set translation only")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.TranslationOnlyFailed))
+		return
+	}
+	h.recordInteraction(userID, models.FeatureOnboarding, models.InteractionTranslationOnly, "", 0)
+
+	// 設定到一半的用戶可能已有排程
+	if err := h.deleteExistingSchedule(userID, "translation only selected"); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to delete schedule for translation-only user")
+	}
+
+	if err := h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.TranslationOnlyDone)); err != nil {
+		h.logger.Error("Failed to send translation only confirmation: ", err)
+	}
+}
+
+// handleReviewAdd 將每日推播卡片上的單字存入用戶的單字庫，之後的回顧與測驗都會用到
+func (h *Handler) handleReviewAdd(replyToken, userID string, values url.Values) {
+	word := values.Get("word")
+	if word == "" {
+		h.logger.WithField("userId", userID).Warn("Review add postback without word")
+		return
+	}
+
+	if err := h.vocabularyRepo.SaveWord(word, values.Get("pos"), values.Get("meaning"), values.Get("example"), models.WordSourceDailyPush, userID, h.recordExpiresAt()); err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Error("Failed to save word from daily push")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DailyPushReviewAddFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DailyPushReviewAdded, messages.Data{"Word": word}))
+}
+
+// recordActivity 更新用戶的 lastActiveAt，並在每週第一次活躍時寫入週活躍紀錄供留存分析使用。
+// userConfig 為 nil 時無法得知上次活躍時間，一律寫入
+func (h *Handler) recordActivity(userID string, userConfig *models.UserConfig) {
+	now := time.Now().UTC()
+
+	var lastActiveAt time.Time
+	if userConfig != nil && userConfig.LastActiveAt != "" {
+		lastActiveAt, _ = time.Parse(time.RFC3339, userConfig.LastActiveAt)
+	}
+	if now.Sub(lastActiveAt) < activityWriteInterval {
+		return
+	}
+
+	firstActiveAt, err := h.userConfigRepo.TouchLastActive(userID, now)
+	if err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to update last active time")
+		return
+	}
+
+	// 本週已經記錄過
+	week := utils.WeekStart(now)
+	if !lastActiveAt.IsZero() && utils.WeekStart(lastActiveAt).Equal(week) {
+		return
+	}
+
+	cohort := week
+	if first, err := time.Parse(time.RFC3339, firstActiveAt); err == nil {
+		cohort = utils.WeekStart(first)
+	}
+	activity := models.WeeklyActivity{
+		Week:       week.Format("2006-01-02"),
+		UserID:     userID,
+		CohortWeek: cohort.Format("2006-01-02"),
+	}
+	if err := h.analyticsRepo.RecordWeeklyActivity(activity); err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to record weekly activity")
+	}
+}
+
+// recordInteraction 記錄用戶的按鈕點擊，寫入失敗不影響主流程。latency 無法得知時傳 0
+func (h *Handler) recordInteraction(userID, feature, action, variant string, latency time.Duration) {
+	interaction := models.Interaction{
+		UserID:    userID,
+		Feature:   feature,
+		Action:    action,
+		Variant:   variant,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if err := h.interactionRepo.RecordInteraction(interaction); err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"userId":  userID,
+			"feature": feature,
+			"action":  action,
+		}).Warn("Failed to record interaction")
+	}
+}
+
 func (h *Handler) handleUserFollow(replyToken, userID string) {
 	h.logger.WithField("userID", userID).Info("User followed the bot")
+	h.recordInteraction(userID, models.FeatureOnboarding, models.InteractionFollow, "", 0)
+	h.linkRichMenu(userID)
+
+	// 還沒選擇過隱私設定的用戶（新用戶，以及隱私設定上線前就加入的回鍋用戶）在歡迎訊息後詢問
+	askConsent := true
+	if userConfig, err := h.userConfigRepo.GetUserConfig(userID); err == nil && userConfig != nil && userConfig.ConsentUpdatedAt != "" {
+		askConsent = false
+	}
+
+	if h.reactivateUser(userID) {
+		// 回鍋的用戶保留原本的設定，不重新走 onboarding
+		h.recordLineDestination(userID)
+		h.sendGreetingMessage(replyToken, askConsent)
+		return
+	}
 
 	// 獲取用戶資料
 	profile, err := h.linebotClient.GetProfile(userID)
 	if err != nil {
 		h.logger.WithError(err).WithField("userID", userID).Error("Failed to get user profile")
 		// 即使獲取資料失敗，仍然發送歡迎訊息
-		h.sendGreetingMessage(replyToken)
+		h.sendGreetingMessage(replyToken, askConsent)
 		return
 	}
 
@@ -213,65 +839,121 @@ func (h *Handler) handleUserFollow(replyToken, userID string) {
 			"userID":      userID,
 			"displayName": displayName,
 		}).Info("Successfully created initial user record")
+		h.recordLineDestination(userID)
 	}
 
 	// 發送歡迎訊息
-	h.sendGreetingMessage(replyToken)
+	h.sendGreetingMessage(replyToken, askConsent)
+}
+
+// linkRichMenu 綁定圖文選單（見 internal/richmenu），失敗時用戶仍可輸入指令，只記 log
+func (h *Handler) linkRichMenu(userID string) {
+	if h.envVars.richMenuID == "" {
+		return
+	}
+	if err := h.linebotClient.LinkUserRichMenu(userID, h.envVars.richMenuID); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to link rich menu")
+	}
 }
 
-func (h *Handler) sendGreetingMessage(replyToken string) {
-	message := `👋 嗨！我是你的語言小幫手！
+// handleUserUnfollow 用戶取消追蹤（封鎖）時停用帳號、刪除每日推播排程，並讓單字紀錄在保留期後由 TTL 清除
+func (h *Handler) handleUserUnfollow(userID string) {
+	h.logger.WithField("userID", userID).Info("User unfollowed the bot")
+
+	if err := h.deleteExistingSchedule(userID, "user unfollowed"); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to delete schedule for unfollowed user")
+	}
+
+	now := time.Now().UTC()
+	if err := h.userConfigRepo.DeactivateUser(userID, now); err != nil && !errors.Is(err, utils.ErrUserNotFound) {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to deactivate unfollowed user")
+	}
+
+	updated, err := h.vocabularyRepo.SetVocabularyExpiry(userID, now.Add(unfollowVocabularyRetention))
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to mark vocabularies for expiration")
+		return
+	}
+	h.logger.WithFields(logrus.Fields{
+		"userID":       userID,
+		"vocabularies": updated,
+	}).Info("Cleaned up unfollowed user")
+}
 
-我可以幫你翻譯英文和中文，不論是英翻中還是中翻英，通通都沒問題 ✅  
-而且我會在每天晚上幫你整理你今天問過的單字，協助你定期複習 🧠✨
+// recordExpiresAt 回傳現在寫入的單字紀錄與 session 何時由 TTL 刪除，零值表示永久保存
+func (h *Handler) recordExpiresAt() time.Time {
+	return h.envVars.recordExpiry.ExpiresAt(time.Now())
+}
 
-如果你有興趣，也可以點選我們的字卡連結，我們目前支援「多益」與「雅思」的每日單字推播 📚📩
-不過目前暫時沒有興趣也沒關係，你可以隨時輸入「/設定推播」來開始設定。
-也可以輸入「/個人設定」來查看你的設定紀錄唷！
+// reactivateUser 曾取消追蹤（或因封鎖被停用）的用戶重新加入時清除停用標記、將單字紀錄的 TTL 恢復為一般保存期限，
+// 設定完整時重建每日排程。回傳 true 表示用戶原本的設定已恢復
+func (h *Handler) reactivateUser(userID string) bool {
+	userConfig, err := h.userConfigRepo.GetUserConfig(userID)
+	if err != nil || userConfig == nil || userConfig.DeactivatedAt == "" {
+		return false
+	}
 
-如有任何疑問，歡迎隨時輸入「/說明」來再次查看這份說明 📎`
+	if err := h.userConfigRepo.ReactivateUser(userID); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to reactivate user")
+	}
+	if _, err := h.vocabularyRepo.SetVocabularyExpiry(userID, h.recordExpiresAt()); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to restore vocabularies")
+	}
 
-	textMessage := linebot.NewTextMessage(message)
+	if utils.MissingSetupStep(userConfig) != "" || userConfig.TranslationOnly {
+		return false
+	}
+	// scheduleWordPush 會先刪除停用中的舊排程再建立新的
+	if err := h.scheduleWordPush(userID, userConfig.PushTime, userConfig.Timezone, "user re-followed"); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to restore schedule for re-followed user")
+		return false
+	}
+	return true
+}
 
-	// 使用共用的 CarouselTemplate
-	template := h.createCourseSelectionCarousel()
-	templateMessage := linebot.NewTemplateMessage("字卡訂閱", template)
-	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, textMessage, templateMessage); err != nil {
+// sendGreetingMessage 回覆說明文字與課程選單，askConsent 時再附上隱私設定的詢問
+func (h *Handler) sendGreetingMessage(replyToken string, askConsent bool) {
+	// 說明文字 + 課程選擇 CarouselTemplate
+	greeting := messages.Build(messages.Greeting, messages.Data{"Courses": courses.Names()})
+	if askConsent {
+		greeting = append(greeting, messages.Build(messages.ConsentPrompt, nil)...)
+	}
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, greeting...); err != nil {
 		h.logger.Error("Failed to send carousel template: ", err)
 	}
 }
 
-func (h *Handler) handleCourseInterest(replyToken, userName, userID, course string) {
+func (h *Handler) handleCourseInterest(replyToken, userName, userID string, course courses.Course) {
 	// 先儲存課程選擇（level 暫時設為 0，等待用戶輸入，使用預設的推播設定）
-	if err := h.userConfigRepo.SaveUserConfig(userID, userName, course, 0, 0, "", ""); err != nil {
+	if err := h.userConfigRepo.SaveUserConfig(userID, userName, course.ID, 0, 0, "", ""); err != nil {
 		h.logger.WithError(err).Error("Failed to save user config")
-		h.linebotClient.ReplyMessage(replyToken, "抱歉，設定過程發生錯誤，請稍後再試。")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupFailed))
 		return
 	}
+	h.recordInteraction(userID, models.FeatureOnboarding, models.InteractionCourseSelected, course.ID, 0)
 
-	// 根據課程類型回覆不同訊息
-	var message string
-	if course == "toeic" {
-		message = `太棒了！我已為你設定多益課程 📘
-
-請告訴我你目前的多益分數（0-990分）：
-如果不確定的話可以先隨機輸入一個大概的分數，之後如果難易度不符合可以再調整。
-
-請直接輸入數字即可（例如：750）`
-	} else {
-		message = `太棒了！我已為你設定雅思課程 📗
-
-請告訴我你目前的雅思分數（0-9分）：
-如果不確定的話可以先隨機輸入一個大概的分數，之後如果難易度不符合可以再調整。
-
-請直接輸入數字即可（例如：6.5）`
+	message := linebot.NewTextMessage(messages.Render(messages.CourseInterest, courseData(course)))
+	if course.NamedLevels() {
+		message.WithQuickReplies(messages.LevelReplies(course.Levels))
 	}
 
-	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, message); err != nil {
 		h.logger.Error("Failed to reply course interest: ", err)
 	}
 }
 
+// courseData 課程選擇與分數設定訊息共用的模板變數
+func courseData(course courses.Course) messages.Data {
+	return messages.Data{
+		"CourseName":   course.Name,
+		"Emoji":        course.Emoji,
+		"ScoreRange":   course.ScoreRange(),
+		"ScoreExample": course.ScoreExample,
+		"Decimal":      course.DecimalScore(),
+		"NamedLevels":  course.NamedLevels(),
+	}
+}
+
 func (h *Handler) handleScoreInput(replyToken, userName, userID, text string) bool {
 	// 檢查用戶是否有等待分數輸入的設定
 	userConfig, err := h.userConfigRepo.GetUserConfig(userID)
@@ -285,58 +967,35 @@ func (h *Handler) handleScoreInput(replyToken, userName, userID, text string) bo
 		return false
 	}
 
-	// 嘗試解析分數
-	var score int
-	var floatScore float64
-
-	if userConfig.Course == "ielts" {
-		// 雅思支援小數點輸入
-		if _, err := fmt.Sscanf(text, "%f", &floatScore); err != nil {
-			// 不是數字，不處理
-			return false
-		}
-		// 轉換為整數存儲 (6.5 -> 65)
-		score = int(floatScore * 10)
-	} else {
-		// 多益只接受整數
-		if _, err := fmt.Sscanf(text, "%d", &score); err != nil {
-			// 不是數字，不處理
-			return false
-		}
+	course, ok := courses.Get(userConfig.Course)
+	if !ok {
+		return false
 	}
 
-	// 驗證分數範圍
-	var isValid bool
-	var message string
-
-	if userConfig.Course == "toeic" {
-		isValid = score >= 0 && score <= 990
-		if isValid {
-			message = fmt.Sprintf("✅ 已設定你的多益分數為 %d 分！", score)
-		} else {
-			message = "多益分數應該在 0-990 分之間，請重新輸入。"
-		}
-	} else { // ielts
-		isValid = score >= 0 && score <= 90 // 0.0 到 9.0 分，轉換後是 0 到 90
-		if isValid {
-			realScore := float64(score) / 10.0
-			message = fmt.Sprintf("✅ 已設定你的雅思分數為 %.1f 分！", realScore)
-		} else {
-			message = "雅思分數應該在 0-9 分之間（例如：6.5），請重新輸入。"
-		}
+	// 嘗試解析分數（小數分數以 ×score_scale 的整數存儲，例如雅思 6.5 -> 65）
+	score, ok := course.ParseScore(text)
+	if !ok {
+		// 不是數字，不處理
+		return false
 	}
 
-	if !isValid {
-		h.linebotClient.ReplyMessage(replyToken, message)
+	// 驗證分數範圍
+	if !course.ValidLevel(score) {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ScoreInvalid, courseData(course)))
 		return true // 雖然分數無效，但確實是分數輸入嘗試
 	}
 
+	data := courseData(course)
+	data["Score"] = course.FormatLevel(score)
+	message := messages.Render(messages.ScoreSet, data)
+
 	// 更新用戶設定
 	if err := h.userConfigRepo.SaveUserConfig(userID, userName, userConfig.Course, score, 0, "", ""); err != nil {
 		h.logger.WithError(err).Error("Failed to update user config with score")
-		h.linebotClient.ReplyMessage(replyToken, "抱歉，分數設定過程發生錯誤，請稍後再試。")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ScoreSaveFailed))
 		return true
 	}
+	h.recordInteraction(userID, models.FeatureOnboarding, models.InteractionScoreSet, userConfig.Course, 0)
 
 	// 發送成功訊息，並詢問是否要設定推播選項
 	h.sendPushSettingsPrompt(replyToken, message)
@@ -345,19 +1004,10 @@ func (h *Handler) handleScoreInput(replyToken, userName, userID, text string) bo
 }
 
 func (h *Handler) sendPushSettingsPrompt(replyToken, scoreMessage string) {
-	message := scoreMessage + "\n\n📱 要設定每日單字推播嗎？\n\n🔧 預設設定：每天10個單字，早上8:00推播\n❗ 如使用預設設定可直接跳過，並於明天開始推播~"
-
-	textMessage := linebot.NewTextMessage(message)
-
 	// 使用 Quick Reply 按鈕
-	quickReply := linebot.NewQuickReplyItems(
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("設定推播", "/設定推播詳細")),
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("使用預設設定", "/使用預設設定")),
-	)
-
-	textMessageWithQuickReply := textMessage.WithQuickReplies(quickReply)
+	replies := messages.Build(messages.PushSettingsPrompt, messages.Data{"ScoreMessage": scoreMessage})
 
-	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, textMessageWithQuickReply); err != nil {
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, replies...); err != nil {
 		h.logger.Error("Failed to send push settings prompt: ", err)
 	}
 }
@@ -366,496 +1016,1807 @@ func (h *Handler) handleShowUserSettings(replyToken, userID string) {
 	userConfig, err := h.userConfigRepo.GetUserConfig(userID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get user config")
-		h.linebotClient.ReplyMessage(replyToken, "抱歉，無法取得您的設定資料，請稍後再試。")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SettingsLoadFailed))
 		return
 	}
 
 	if userConfig == nil {
-		h.linebotClient.ReplyMessage(replyToken, "📝 您尚未完成設定\n\n請先：\n1. 選擇課程（多益/雅思）\n2. 設定您的程度分數\n3. 設定推播選項\n\n💡 輸入「/說明」查看完整使用說明")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SettingsNotFound))
 		return
 	}
 
-	// 格式化用戶設定資訊
-	var message strings.Builder
-	message.WriteString("⚙️ 個人設定資訊\n\n")
-
-	// 顯示名稱
-	if userConfig.DisplayName != "" {
-		message.WriteString(fmt.Sprintf("👤 用戶名稱：%s\n", userConfig.DisplayName))
-	}
-
 	// 課程資訊
-	if userConfig.Course != "" {
-		var courseName, levelInfo string
-		if userConfig.Course == "toeic" {
-			courseName = "多益 (TOEIC)"
-			if userConfig.Level > 0 {
-				levelInfo = fmt.Sprintf("%d 分", userConfig.Level)
-			}
-		} else if userConfig.Course == "ielts" {
-			courseName = "雅思 (IELTS)"
-			if userConfig.Level > 0 {
-				realScore := float64(userConfig.Level) / 10.0
-				levelInfo = fmt.Sprintf("%.1f 分", realScore)
+	var courseName, levelInfo string
+	if course, ok := courses.Get(userConfig.Course); ok {
+		courseName = fmt.Sprintf("%s (%s)", course.Name, course.ExamName)
+		if userConfig.Level > 0 {
+			levelInfo = course.FormatLevel(userConfig.Level)
+			if !course.NamedLevels() {
+				levelInfo += " 分"
 			}
 		}
-		message.WriteString(fmt.Sprintf("📚 課程：%s\n", courseName))
-
-		if levelInfo != "" {
-			message.WriteString(fmt.Sprintf("📊 程度：%s\n", levelInfo))
-		} else {
-			message.WriteString("📊 程度：尚未設定\n")
-		}
-	} else {
-		message.WriteString("📚 課程：尚未選擇\n")
-		message.WriteString("📊 程度：尚未設定\n")
 	}
 
-	// 推播設定
-	if userConfig.DailyWords > 0 {
-		message.WriteString(fmt.Sprintf("📱 每日推播：%d 個單字\n", userConfig.DailyWords))
-	} else {
-		message.WriteString("📱 每日推播：尚未設定\n")
-	}
+	// 設定完成度檢查
+	complete := userConfig.Course != "" && userConfig.Level > 0 && userConfig.DailyWords > 0 && userConfig.PushTime != ""
 
-	if userConfig.PushTime != "" {
-		message.WriteString(fmt.Sprintf("⏰ 推播時間：%s\n", userConfig.PushTime))
-	} else {
-		message.WriteString("⏰ 推播時間：尚未設定\n")
+	streak, err := h.streakRepo.GetStreak(userID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to get streak")
 	}
-
-	if userConfig.Timezone != "" {
-		message.WriteString(fmt.Sprintf("🌏 時區：%s\n", userConfig.Timezone))
+	longestStreak := 0
+	if streak != nil {
+		longestStreak = streak.Longest
 	}
 
-	// 設定完成度檢查
-	message.WriteString("\n")
-	if userConfig.Course != "" && userConfig.Level > 0 && userConfig.DailyWords > 0 && userConfig.PushTime != "" {
-		message.WriteString("✅ 設定已完成！\n\n💡 可使用「/設定推播」重新調整推播設定")
-	} else {
-		message.WriteString("⚠️ 設定尚未完整\n\n💡 使用「/設定推播」完成剩餘設定")
+	// 停用中的用戶沒有排程，不顯示下次推播
+	nextPush := ""
+	if complete && userConfig.DeactivatedAt == "" && !userConfig.TranslationOnly {
+		nextPush = h.nextPushText(userConfig.PushTime, userConfig.Timezone)
 	}
 
-	if err := h.linebotClient.ReplyMessage(replyToken, message.String()); err != nil {
+	message := messages.Render(messages.UserSettings, messages.Data{
+		"DisplayName":     userConfig.DisplayName,
+		"CourseName":      courseName,
+		"LevelInfo":       levelInfo,
+		"DailyWords":      userConfig.DailyWords,
+		"PushTime":        userConfig.PushTime,
+		"NextPush":        nextPush,
+		"Timezone":        userConfig.Timezone,
+		"Complete":        complete,
+		"TranslationOnly": userConfig.TranslationOnly,
+		"Streak":          utils.CurrentStreak(streak, utils.StreakDate(time.Now(), userConfig.Timezone)),
+		"LongestStreak":   longestStreak,
+	})
+
+	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
 		h.logger.Error("Failed to send user settings: ", err)
 	}
 }
 
-func (h *Handler) handlePushSettings(replyToken, userID string, userConfig *models.UserConfig) {
-	if userConfig != nil && userConfig.Course != "" {
-		// 用戶已有課程設定，直接進入單字量選擇
-		var courseName string
-		if userConfig.Course == "toeic" {
-			courseName = "多益"
-		} else {
-			courseName = "雅思"
-		}
-
-		message := fmt.Sprintf("📱 設定 %s 推播詳細選項\n\n請選擇每天要收到幾個單字：", courseName)
-
-		textMessage := linebot.NewTextMessage(message)
-
-		// 單字量選擇的 Quick Reply
-		quickReply := linebot.NewQuickReplyItems(
-			linebot.NewQuickReplyButton("", linebot.NewMessageAction("5個單字", "單字量:5")),
-			linebot.NewQuickReplyButton("", linebot.NewMessageAction("10個單字", "單字量:10")),
-			linebot.NewQuickReplyButton("", linebot.NewMessageAction("15個單字", "單字量:15")),
-			linebot.NewQuickReplyButton("", linebot.NewMessageAction("20個單字", "單字量:20")),
-		)
+// recordStreak 在翻譯或完成測驗後延續連續學習天數，達到里程碑時登記給 reminder Lambda 推播恭喜訊息。
+// 寫入失敗只記 log，不影響回覆
+func (h *Handler) recordStreak(userID string, userConfig *models.UserConfig) {
+	timezone := ""
+	if userConfig != nil {
+		timezone = userConfig.Timezone
+	} else if config, err := h.userConfigRepo.GetUserConfig(userID); err == nil && config != nil {
+		timezone = config.Timezone
+	}
 
-		textMessageWithQuickReply := textMessage.WithQuickReplies(quickReply)
+	streak, err := h.streakRepo.GetStreak(userID)
+	if err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to get streak")
+		return
+	}
+	if streak == nil {
+		streak = &models.Streak{UserID: userID}
+	}
 
-		// 暫存用戶已有的課程
-		h.tempStoreCourse(userID, userConfig.Course)
+	updated, changed, milestone := utils.AdvanceStreak(*streak, utils.StreakDate(time.Now(), timezone))
+	if !changed {
+		return
+	}
+	if err := h.streakRepo.SaveStreak(updated); err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to save streak")
+		return
+	}
 
-		if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, textMessageWithQuickReply); err != nil {
-			h.logger.Error("Failed to send daily words selection: ", err)
+	if milestone > 0 {
+		pending := models.StreakMilestone{UserID: userID, Days: milestone, ReachedAt: time.Now().UTC().Format(time.RFC3339)}
+		if err := h.streakRepo.AddPendingMilestone(pending); err != nil {
+			h.logger.WithError(err).WithField("userId", userID).Warn("Failed to save streak milestone")
 		}
-	} else {
-		// 用戶沒有課程設定，顯示課程選擇
-		h.handlePushSettingsStart(replyToken)
 	}
 }
 
-func (h *Handler) handleSkipPushSettings(replyToken, userID string, userConfig *models.UserConfig) {
-	if userConfig == nil {
-		h.linebotClient.ReplyMessage(replyToken, "請先設定課程和分數。")
+const pushHistoryDays = 7
+
+// handleVocabularyStats 回覆單字庫的累積、本週與本月單字數、常見詞性與測驗正確率
+func (h *Handler) handleVocabularyStats(replyToken, userID string) {
+	vocabularies, err := h.vocabularyRepo.GetAllUserVocabularies(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get vocabularies for stats")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.VocabularyStatsLoadFailed))
+		return
+	}
+	sessions, err := h.quizRepo.GetQuizSessions(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get quiz sessions for stats")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.VocabularyStatsLoadFailed))
 		return
 	}
 
-	// 使用預設設定：10個單字，早上8:00推播
-	userConfig.DailyWords = 10          // 預設每日單字數量
-	userConfig.PushTime = "08:00"       // 預設推播時間
-	userConfig.Timezone = "Asia/Taipei" // 預設時區
-
-	// 使用預設設定：10個單字，早上8:00推播
-	if err := h.userConfigRepo.SaveUserConfig(userID, userConfig.DisplayName, userConfig.Course, userConfig.Level, userConfig.DailyWords, userConfig.PushTime, userConfig.Timezone); err != nil {
-		h.logger.WithError(err).Error("Failed to save default push settings")
-		h.linebotClient.ReplyMessage(replyToken, "抱歉，設定過程發生錯誤，請稍後再試。")
+	stats := utils.CalculateVocabularyStats(vocabularies, sessions, time.Now())
+	if stats.TotalWords == 0 {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.VocabularyStatsEmpty))
 		return
 	}
 
-	var courseName string
-	if userConfig.Course == "toeic" {
-		courseName = "多益"
-	} else {
-		courseName = "雅思"
+	partsOfSpeech := make([]string, 0, len(stats.TopPartsOfSpeech))
+	for _, count := range stats.TopPartsOfSpeech {
+		partsOfSpeech = append(partsOfSpeech, fmt.Sprintf("%s %d", count.PartOfSpeech, count.Count))
+	}
+	message := messages.Render(messages.VocabularyStats, messages.Data{
+		"TotalWords":       stats.TotalWords,
+		"WeekWords":        stats.WeekWords,
+		"MonthWords":       stats.MonthWords,
+		"AveragePerDay":    strconv.FormatFloat(stats.AveragePerDay, 'f', 1, 64),
+		"TopPartsOfSpeech": strings.Join(partsOfSpeech, "、"),
+		"QuizAnswered":     stats.QuizAnswered,
+		"QuizCorrect":      stats.QuizCorrect,
+		"QuizAccuracy":     stats.QuizAccuracy(),
+	})
+	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
+		h.logger.Error("Failed to send vocabulary stats: ", err)
 	}
+}
 
-	message := fmt.Sprintf("🎉 已使用預設推播設定！\n\n📱 你的推播設定：\n• 課程：%s\n• 每天 10 個單字\n• 推播時間：08:00\n\n🚀 馬上為您推播 %s 單字，下一次會於明天 08:00 推播！\n\n現在你可以開始使用翻譯功能！", courseName, courseName)
+// vocabularyHistoryPageSize /歷史 每頁顯示的天數，不可超過 carousel 的 bubble 上限
+const vocabularyHistoryPageSize = 10
 
-	// 設定推播排程並立即推播
-	if err := h.setupUserPushSchedule(userID, userConfig.PushTime, userConfig.Timezone); err != nil {
-		errorMessage := "⚠️ 排程建立失敗，請稍後重新設定或聯絡客服。"
-		if replyErr := h.linebotClient.ReplyMessage(replyToken, errorMessage); replyErr != nil {
-			h.logger.Error("Failed to send error message: ", replyErr)
+// handleVocabularyHistory 以 Flex carousel 回覆單字庫最近的日期，每天一張卡片；before 為空字串時從最新的日期開始，
+// 還有更早的紀錄時附上「下一頁」按鈕，以 postback 帶上這一頁最後一天的日期
+func (h *Handler) handleVocabularyHistory(replyToken, userID, before string) {
+	vocabularies, nextDate, err := h.vocabularyRepo.GetUserVocabulariesPage(userID, vocabularyHistoryPageSize, before)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get vocabulary history")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.VocabularyHistoryLoadFailed))
+		return
+	}
+	if len(vocabularies) == 0 {
+		// 上一頁剛好讀到最後一天時 DynamoDB 仍會回傳游標，下一頁才會是空的
+		key := messages.VocabularyStatsEmpty
+		if before != "" {
+			key = messages.VocabularyHistoryEnd
 		}
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(key))
 		return
 	}
 
-	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
-		h.logger.Error("Failed to send default settings confirmation: ", err)
+	message := linebot.NewFlexMessage(messages.Text(messages.VocabularyHistoryAlt), utils.BuildVocabularyHistoryCarousel(vocabularies))
+	if nextDate != "" {
+		values := url.Values{}
+		values.Set("action", models.PostbackVocabularyHistory)
+		values.Set("before", nextDate)
+		message.WithQuickReplies(messages.VocabularyHistoryNextReplies(values.Encode()))
+	}
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, message); err != nil {
+		h.logger.Error("Failed to send vocabulary history: ", err)
 	}
 }
 
-func (h *Handler) handlePushSettingsResponse(replyToken, userID, text string, userConfig *models.UserConfig) bool {
-	h.logger.WithField("text", text).Info("Checking push settings response")
+// handleShowPushHistory 顯示最近 7 天的推播紀錄，讓用戶自行確認推播狀況
+func (h *Handler) handleShowPushHistory(replyToken, userID string, userConfig *models.UserConfig) {
+	logs, err := h.pushLogRepo.GetRecentPushLogs(userID, pushHistoryDays)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get push logs")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.PushHistoryLoadFailed))
+		return
+	}
 
-	// 檢查是否是推播設定的課程選擇
-	if strings.HasPrefix(text, "推播設定:") {
-		h.logger.Info("Matched 推播設定 prefix")
-		courseStr := strings.TrimPrefix(text, "推播設定:")
-		h.logger.WithField("course", courseStr).Info("Extracted course")
+	if len(logs) == 0 {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.PushHistoryEmpty, messages.Data{"Days": pushHistoryDays}))
+		return
+	}
 
-		if courseStr == "toeic" || courseStr == "ielts" {
-			h.handlePushSettingsCourseSelected(replyToken, userID, courseStr)
-			return true
+	loc := time.UTC
+	if userConfig != nil {
+		if userLoc, err := time.LoadLocation(userConfig.Timezone); err == nil {
+			loc = userLoc
 		}
-		return false
 	}
 
-	// 檢查是否是單字量設定
-	if strings.HasPrefix(text, "單字量:") {
-		h.logger.Info("Matched 單字量 prefix")
-		dailyWordsStr := strings.TrimPrefix(text, "單字量:")
-		h.logger.WithField("dailyWordsStr", dailyWordsStr).Info("Extracted daily words string")
-
-		dailyWords := 0
-		switch dailyWordsStr {
-		case "5":
-			dailyWords = 5
-		case "10":
-			dailyWords = 10
-		case "15":
-			dailyWords = 15
-		case "20":
-			dailyWords = 20
-		default:
-			h.logger.WithField("dailyWordsStr", dailyWordsStr).Warn("Unknown daily words value")
-			return false
+	entries := make([]messages.Data, 0, len(logs))
+	for _, log := range logs {
+		pushTime := ""
+		if pushedAt, err := time.Parse(time.RFC3339, log.PushedAt); err == nil {
+			pushTime = pushedAt.In(loc).Format("15:04")
 		}
-
-		h.logger.WithField("dailyWords", dailyWords).Info("Processing daily words selection")
-		h.handleDailyWordsSelection(replyToken, userID, dailyWords)
-		return true
+		entries = append(entries, messages.Data{
+			"Date":      log.Date,
+			"Time":      pushTime,
+			"WordCount": log.WordCount,
+			"Delivered": log.Status == models.PushStatusDelivered,
+		})
 	}
 
-	// 檢查是否是推播時間設定
-	if strings.HasPrefix(text, "時間:") {
-		h.logger.Info("Matched 時間 prefix")
-		pushTime := strings.TrimPrefix(text, "時間:")
-		h.logger.WithField("pushTime", pushTime).Info("Extracted push time")
-		h.handlePushTimeSelection(replyToken, userID, pushTime, userConfig)
-		return true
+	message := messages.Render(messages.PushHistory, messages.Data{
+		"Days": pushHistoryDays,
+		"Logs": entries,
+	})
+	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
+		h.logger.Error("Failed to send push history: ", err)
 	}
-
-	h.logger.Info("No push settings pattern matched")
-	return false
 }
 
-func (h *Handler) handleDailyWordsSelection(replyToken, userID string, dailyWords int) {
-	message := fmt.Sprintf("✅ 已設定每天推播 %d 個單字\n\n請選擇推播時間：", dailyWords)
+// handleTodayWords 重新發送今天（用戶時區）推播的單字，給圖文選單的「今日單字」使用
+func (h *Handler) handleTodayWords(replyToken, userID string, userConfig *models.UserConfig) {
+	timezone := ""
+	if userConfig != nil {
+		timezone = userConfig.Timezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	h.handleRepush(replyToken, userID, time.Now().In(loc).Format("2006-01-02"))
+}
 
-	textMessage := linebot.NewTextMessage(message)
+// handleRepush 重新發送指定日期的推播內容（例如用戶清除了聊天紀錄或當天推播失敗）
+func (h *Handler) handleRepush(replyToken, userID, date string) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.RepushUsage))
+		return
+	}
 
-	// 推播時間選擇的 Quick Reply
-	quickReply := linebot.NewQuickReplyItems(
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("早上 8:00", "時間:08:00")),
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("中午 12:00", "時間:12:00")),
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("晚上 7:00", "時間:19:00")),
-	)
+	logs, err := h.pushLogRepo.GetPushLogsByDate(userID, date)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get push logs by date")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.PushHistoryLoadFailed))
+		return
+	}
 
-	textMessageWithQuickReply := textMessage.WithQuickReplies(quickReply)
+	// 取最新一筆有內容的推播（即使當時推播失敗，內容也已產生）
+	var content string
+	for _, log := range logs {
+		if log.Message != "" {
+			content = log.Message
+			break
+		}
+	}
 
-	// 暫存用戶選擇的單字量
-	h.tempStoreDailyWords(userID, dailyWords)
+	if content == "" {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.RepushNotFound, messages.Data{"Date": date}))
+		return
+	}
 
-	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, textMessageWithQuickReply); err != nil {
-		h.logger.Error("Failed to send push time selection: ", err)
+	// 推播內容以空行分隔各單字，超過單則訊息上限時在單字之間分成多則
+	header := messages.Render(messages.RepushHeader, messages.Data{"Date": date})
+	replies := messages.TextMessages(append([]string{header}, messages.Split(strings.Split(content, "\n\n"), "\n\n")...))
+	if err := h.replyAndPushRest(replyToken, userID, replies); err != nil {
+		h.logger.Error("Failed to re-send push content: ", err)
 	}
 }
 
-func (h *Handler) handlePushTimeSelection(replyToken, userID, pushTime string, userConfig *models.UserConfig) {
-	// 獲取臨時存儲的單字量和課程
-	dailyWords := h.getTempDailyWords(userID)
-	if dailyWords == 0 {
-		dailyWords = 10 // 預設值
+// handleResetWordHistory 確認後清除某課程的單字推播紀錄，未指定課程時使用用戶目前的課程
+func (h *Handler) handleResetWordHistory(replyToken string, userConfig *models.UserConfig, arg string) {
+	if userConfig == nil || userConfig.Course == "" {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
 	}
 
-	tempCourse := h.getTempCourse(userID)
-
-	// 確定最終的課程和等級
-	var finalCourse string
-	var finalLevel int
-	var displayName string
-
-	if tempCourse != "" {
-		// 從推播設定流程來的
-		finalCourse = tempCourse
-		finalLevel = 0 // 預設 level
-		if userConfig != nil {
-			finalLevel = userConfig.Level
-			displayName = userConfig.DisplayName
-		}
-		h.logger.Info("Handling push settings flow")
-	} else {
-		// 從分數設定後的推播設定來的，需要重新獲取用戶設定
-		var err error
-		userConfig, err = h.userConfigRepo.GetUserConfig(userID)
-		if err != nil {
-			h.logger.WithError(err).Error("Failed to get user config")
-			h.linebotClient.ReplyMessage(replyToken, "抱歉，設定過程發生錯誤，請稍後再試。")
-			return
-		}
-
-		if userConfig == nil {
-			h.linebotClient.ReplyMessage(replyToken, "請先設定課程和分數。")
-			return
-		}
+	course := userConfig.Course
+	if c, ok := courses.Lookup(arg); ok {
+		course = c.ID
+	}
 
-		finalCourse = userConfig.Course
-		finalLevel = userConfig.Level
-		displayName = userConfig.DisplayName
-		h.logger.Info("Handling score input flow")
+	courseName := courses.DisplayName(course)
+	confirm := messages.WordHistoryResetTemplate(courseName, utils.ResetWordHistoryPostbackData(course, true), utils.ResetWordHistoryPostbackData(course, false))
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, confirm); err != nil {
+		h.logger.WithError(err).Error("Failed to send word history reset confirmation")
 	}
+}
 
-	// 統一更新用戶設定
-	if err := h.userConfigRepo.SaveUserConfig(userID, displayName, finalCourse, finalLevel, dailyWords, pushTime, "Asia/Taipei"); err != nil {
-		h.logger.WithError(err).Error("Failed to update user config with push settings")
-		h.linebotClient.ReplyMessage(replyToken, "抱歉，設定過程發生錯誤，請稍後再試。")
+// handleResetWordHistoryConfirmed 清除單字歷史並以空的 bloom filter 取代，之後的推播會重新挑選單字
+func (h *Handler) handleResetWordHistoryConfirmed(replyToken, userID string, values url.Values) {
+	course := values.Get("course")
+	if values.Get("confirm") != "1" || course == "" {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WordHistoryResetCancelled))
 		return
 	}
 
-	// 清理臨時存儲
-	h.clearTempDailyWords(userID)
-	if tempCourse != "" {
-		h.clearTempCourse(userID)
+	count, err := h.wordHistoryRepo.ClearPushedWords(userID, course)
+	if err == nil {
+		err = h.bloomFilterRepo.ClearBloomFilter(userID, course)
+	}
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{"userId": userID, "course": course}).Error("Failed to reset word history")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WordHistoryResetFailed))
+		return
 	}
 
-	// 統一的成功訊息處理
-	var courseName string
-	if finalCourse == "toeic" {
-		courseName = "多益"
-	} else {
-		courseName = "雅思"
+	h.logger.WithFields(logrus.Fields{"userId": userID, "course": course, "words": count}).Info("Reset word history")
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.WordHistoryResetDone, messages.Data{"CourseName": courses.DisplayName(course), "Count": count}))
+}
+
+// handleResetWordBank 讓推播過的單字可以再次出現：參數為單字時只移除該單字，為課程或未指定時清除整個課程的 bloom filter
+func (h *Handler) handleResetWordBank(replyToken, userID string, userConfig *models.UserConfig, arg string) {
+	if userConfig == nil || userConfig.Course == "" {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
 	}
 
-	message := fmt.Sprintf("🎉 推播設定完成！\n\n📱 你的推播設定：\n• 課程：%s\n• 每天 %d 個單字\n• 推播時間：%s\n\n🚀 馬上為您推播 %s 單字，下一次會於明天 %s 推播！\n\n現在你可以開始使用翻譯功能！", courseName, dailyWords, pushTime, courseName, pushTime)
+	course := userConfig.Course
+	c, isCourse := courses.Lookup(arg)
+	if isCourse {
+		course = c.ID
+	}
+	fields := logrus.Fields{"userId": userID, "course": course}
 
-	// 設定推播排程並立即推播
-	if err := h.setupUserPushSchedule(userID, pushTime, "Asia/Taipei"); err != nil {
-		errorMessage := "⚠️ 排程建立失敗，請稍後重新設定或聯絡客服。"
-		if replyErr := h.linebotClient.ReplyMessage(replyToken, errorMessage); replyErr != nil {
-			h.logger.Error("Failed to send error message: ", replyErr)
+	if arg == "" || isCourse {
+		if err := h.bloomFilterRepo.ClearBloomFilter(userID, course); err != nil {
+			h.logger.WithError(err).WithFields(fields).Error("Failed to clear bloom filter")
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WordBankResetFailed))
+			return
 		}
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.WordBankCleared, messages.Data{"CourseName": courses.DisplayName(course)}))
 		return
 	}
 
-	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
-		h.logger.Error("Failed to send push settings confirmation: ", err)
+	// 單字歷史較少時推播以歷史精確比對，因此兩邊都要移除
+	removed, err := h.bloomFilterRepo.RemoveWordFromBloomFilter(userID, arg, course)
+	if err == nil {
+		err = h.wordHistoryRepo.RemovePushedWord(userID, course, arg)
+	}
+	if err != nil {
+		h.logger.WithError(err).WithFields(fields).Error("Failed to remove word from word bank")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WordBankResetFailed))
+		return
 	}
-}
-
-// 臨時存儲機制（簡單實現，生產環境可能需要 Redis 或其他方案）
-var tempDailyWordsStorage = make(map[string]int)
-var tempCourseStorage = make(map[string]string)
 
-func (h *Handler) tempStoreDailyWords(userID string, dailyWords int) {
-	tempDailyWordsStorage[userID] = dailyWords
+	h.logger.WithFields(fields).WithFields(logrus.Fields{"word": arg, "bloomRemoved": removed}).Info("Removed word from word bank")
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.WordBankWordRemoved, messages.Data{"Word": arg, "CourseName": courses.DisplayName(course)}))
 }
 
-func (h *Handler) getTempDailyWords(userID string) int {
-	return tempDailyWordsStorage[userID]
-}
+// handleLowerBandWords 設定分數提升到新級距後，較低級距推播過的單字要再次出現當作複習或直接略過
+func (h *Handler) handleLowerBandWords(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
 
-func (h *Handler) clearTempDailyWords(userID string) {
-	delete(tempDailyWordsStorage, userID)
-}
+	var policy string
+	switch action {
+	case "複習":
+		policy = models.LowerBandWordsReview
+	case "略過":
+		policy = models.LowerBandWordsSkip
+	default:
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.LowerBandWordsStatus, messages.Data{"Policy": userConfig.LowerBandWords}))
+		return
+	}
 
-func (h *Handler) tempStoreCourse(userID string, course string) {
-	tempCourseStorage[userID] = course
+	if err := h.userConfigRepo.SetLowerBandWords(userID, policy); err != nil {
+		h.logger.WithError(err).Error("Failed to save lower band words policy")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.LowerBandWordsFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.LowerBandWordsUpdated, messages.Data{"Policy": policy}))
 }
 
-func (h *Handler) getTempCourse(userID string) string {
-	return tempCourseStorage[userID]
-}
+// handleDifficulty 將每日單字的難度提高或降低一個 CEFR 等級（最多 ±models.MaxDifficultyOffset），或恢復依分數決定
+func (h *Handler) handleDifficulty(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	if userConfig == nil || utils.TargetCEFR(userConfig.Course, userConfig.Level, 0) == "" {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+
+	course, level, offset := userConfig.Course, userConfig.Level, userConfig.DifficultyOffset
+	var next int
+	switch action {
+	case "提高", "降低":
+		step := 1
+		if action == "降低" {
+			step = -1
+		}
+		next = offset + step
+		// 已經是 A1 或 C2 時再調整也不會改變目標等級
+		target := utils.TargetCEFR(course, level, offset)
+		if next > models.MaxDifficultyOffset || next < -models.MaxDifficultyOffset || utils.TargetCEFR(course, level, next) == target {
+			h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DifficultyLimit, messages.Data{"Up": step > 0, "Target": target}))
+			return
+		}
+	case "預設":
+		next = 0
+	default:
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DifficultyStatus, messages.Data{
+			"Target": utils.TargetCEFR(course, level, offset),
+			"Offset": offset,
+			"Steps":  -offset,
+		}))
+		return
+	}
 
-func (h *Handler) clearTempCourse(userID string) {
-	delete(tempCourseStorage, userID)
+	if err := h.userConfigRepo.SetDifficultyOffset(userID, next); err != nil {
+		h.logger.WithError(err).Error("Failed to save difficulty offset")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DifficultyFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DifficultyUpdated, messages.Data{"Target": utils.TargetCEFR(course, level, next)}))
 }
 
-func (h *Handler) handlePushSettingsCourseSelected(replyToken, userID, course string) {
-	var courseName string
-	if course == "toeic" {
-		courseName = "多益"
-	} else {
-		courseName = "雅思"
+// handleReviewSource 設定每晚回顧與測驗是否只使用用戶自己查的單字
+func (h *Handler) handleReviewSource(replyToken, userID string, userConfig *models.UserConfig, arg string) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
 	}
 
-	message := fmt.Sprintf("✅ 已選擇 %s 字卡\n\n📱 設定每日推播\n\n請選擇每天要收到幾個單字：", courseName)
+	var source string
+	switch arg {
+	case "我查的":
+		source = models.WordSourceTranslation
+	case "全部":
+		source = ""
+	default:
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ReviewSourceStatus, messages.Data{"Source": userConfig.ReviewSource}))
+		return
+	}
 
-	textMessage := linebot.NewTextMessage(message)
+	if err := h.userConfigRepo.SetReviewSource(userID, source); err != nil {
+		h.logger.WithError(err).Error("Failed to save review source")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ReviewSourceFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ReviewSourceUpdated, messages.Data{"Source": source}))
+}
 
-	// 單字量選擇的 Quick Reply
-	quickReply := linebot.NewQuickReplyItems(
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("5個單字", "單字量:5")),
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("10個單字", "單字量:10")),
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("15個單字", "單字量:15")),
-		linebot.NewQuickReplyButton("", linebot.NewMessageAction("20個單字", "單字量:20")),
-	)
+// wordSearchMaxResults 單字查詢最多列出的紀錄數（最新的優先），避免超過 LINE 單則訊息長度上限
+const wordSearchMaxResults = 10
 
-	textMessageWithQuickReply := textMessage.WithQuickReplies(quickReply)
+// handleWordSearch 列出單字在用戶單字庫中每次被儲存的日期、意思與例句
+func (h *Handler) handleWordSearch(replyToken, userID, word string) {
+	if word == "" {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WordSearchUsage))
+		return
+	}
 
-	// 暫存用戶選擇的課程
-	h.tempStoreCourse(userID, course)
+	occurrences, err := h.vocabularyRepo.GetWordOccurrences(userID, word)
+	if err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Error("Failed to search word occurrences")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WordSearchFailed))
+		return
+	}
+	if len(occurrences) == 0 {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.WordSearchNotFound, messages.Data{"Word": word}))
+		return
+	}
 
-	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, textMessageWithQuickReply); err != nil {
-		h.logger.Error("Failed to send daily words selection for push settings: ", err)
+	more := 0
+	shown := occurrences
+	if len(shown) > wordSearchMaxResults {
+		more = len(shown) - wordSearchMaxResults
+		shown = shown[more:]
 	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.WordSearchResult, messages.Data{
+		"Word":        word,
+		"Count":       len(occurrences),
+		"Occurrences": shown,
+		"More":        more,
+	}))
 }
 
-// 創建課程選擇的 CarouselTemplate
-func (h *Handler) createCourseSelectionCarousel() *linebot.CarouselTemplate {
-	var toeicAction, ieltsAction linebot.TemplateAction
+const exportMaxDays = 31
 
-	toeicAction = linebot.NewMessageAction("有興趣", "我對多益有興趣")
-	ieltsAction = linebot.NewMessageAction("有興趣", "我對雅思有興趣")
-
-	var toeicDesc, ieltsDesc string
-	toeicDesc = "每天一字，幫助你準備 TOEIC！"
-	ieltsDesc = "提升你的 IELTS 單字力！"
+// handleExportStudySheet 將指定日期區間的單字產生為可列印的 PDF 學習單，並回覆下載連結
+func (h *Handler) handleExportStudySheet(replyToken, userID string, args []string) {
+	if len(args) != 2 {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ExportUsage, messages.Data{"MaxDays": exportMaxDays}))
+		return
+	}
 
-	return linebot.NewCarouselTemplate(
-		linebot.NewCarouselColumn(
-			"", // 不使用圖片
-			"📘 多益",
-			toeicDesc,
-			toeicAction,
-		),
-		linebot.NewCarouselColumn(
-			"",
-			"📗 雅思",
-			ieltsDesc,
-			ieltsAction,
-		),
-	)
-}
+	from, errFrom := time.Parse("2006-01-02", args[0])
+	to, errTo := time.Parse("2006-01-02", args[1])
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ExportUsage, messages.Data{"MaxDays": exportMaxDays}))
+		return
+	}
+	if to.Sub(from) >= exportMaxDays*24*time.Hour {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ExportRangeTooLong, messages.Data{"MaxDays": exportMaxDays}))
+		return
+	}
 
-func (h *Handler) handlePushSettingsStart(replyToken string) {
-	message := `📱 設定每日單字推播
+	fromDate, toDate := args[0], args[1]
+	vocabularies, err := h.vocabularyRepo.GetUserVocabulariesBetween(userID, fromDate, toDate)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get vocabularies for export")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ExportFailed))
+		return
+	}
 
-請選擇你想要的字卡類型：`
+	wordCount := 0
+	for _, vocabulary := range vocabularies {
+		wordCount += len(vocabulary.Words)
+	}
+	if wordCount == 0 {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ExportEmpty, messages.Data{"From": fromDate, "To": toDate}))
+		return
+	}
 
-	textMessage := linebot.NewTextMessage(message)
+	title := messages.Render(messages.ExportTitle, messages.Data{"From": fromDate, "To": toDate})
+	pdf := utils.BuildStudySheetPDF(title, vocabularies)
 
-	// 使用共用的 CarouselTemplate
-	template := h.createCourseSelectionCarousel()
-	templateMessage := linebot.NewTemplateMessage("字卡類型選擇", template)
+	// 以雜湊後的排程名稱作為路徑，避免 userID 出現在 URL 中
+	key := fmt.Sprintf("exports/%s/%s_%s-%d.pdf", utils.ScheduleName(userID), fromDate, toDate, time.Now().Unix())
+	url, err := h.media.Publish(utils.MediaExport, key, pdf)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to publish study sheet")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ExportFailed))
+		return
+	}
 
-	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, textMessage, templateMessage); err != nil {
-		h.logger.Error("Failed to send push settings course selection: ", err)
+	h.logger.WithFields(logrus.Fields{
+		"userID":    userID,
+		"from":      fromDate,
+		"to":        toDate,
+		"wordCount": wordCount,
+	}).Info("Exported study sheet")
+
+	message := messages.Render(messages.ExportReady, messages.Data{
+		"From":          fromDate,
+		"To":            toDate,
+		"Count":         wordCount,
+		"ExpiryMinutes": int(h.media.Expiry(utils.MediaExport).Minutes()),
+		"URL":           url,
+	})
+	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
+		h.logger.Error("Failed to send study sheet link: ", err)
 	}
 }
 
-// triggerImmediateWordPush 立即invoke language-vocabulary lambda推播一次單字給用戶
-func (h *Handler) triggerImmediateWordPush(userID string) {
-	h.logger.WithField("userID", userID).Info("Triggering immediate word push")
+const (
+	pairingCodeValidity = 5 * time.Minute
+	pairingQRCodeSize   = 512
+)
 
-	// 構造 lambda invoke 請求
-	requestPayload := map[string]string{
-		"userId": userID,
+// handleWebLogin 產生一次性的配對代碼與 QR Code，讓用戶在網頁版登入並綁定 LINE 帳號
+func (h *Handler) handleWebLogin(replyToken, userID string) {
+	code, err := utils.GeneratePairingCode()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate pairing code")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WebLoginFailed))
+		return
 	}
 
-	payloadBytes, err := json.Marshal(requestPayload)
+	now := time.Now()
+	if err := h.pairingRepo.SavePairingCode(models.PairingCode{
+		Code:      code,
+		UserID:    userID,
+		CreatedAt: now.UTC().Format(time.RFC3339),
+		ExpiresAt: now.Add(pairingCodeValidity).Unix(),
+	}); err != nil {
+		h.logger.WithError(err).Error("Failed to save pairing code")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.WebLoginFailed))
+		return
+	}
+
+	loginURL := fmt.Sprintf("%s/pair?code=%s", strings.TrimRight(h.envVars.dashboardURL, "/"), code)
+	text := linebot.NewTextMessage(messages.Render(messages.WebLoginCode, messages.Data{
+		"Code":          code,
+		"ExpiryMinutes": int(pairingCodeValidity.Minutes()),
+		"URL":           loginURL,
+	}))
+
+	// QR Code 產生失敗時仍回覆文字代碼，用戶可手動輸入
+	qrURL, err := h.uploadPairingQRCode(userID, code, loginURL)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to marshal lambda invoke payload")
+		h.logger.WithError(err).Warn("Failed to create pairing QR code, replying with code only")
+		if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, text); err != nil {
+			h.logger.Error("Failed to send pairing code: ", err)
+		}
 		return
 	}
 
-	// Invoke language-vocabulary lambda
-	input := &lambda.InvokeInput{
-		FunctionName:   aws.String("language-vocabulary"), // Lambda function name
-		InvocationType: "Event",                           // 異步調用，不等待回應
-		Payload:        payloadBytes,
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, text, linebot.NewImageMessage(qrURL, qrURL)); err != nil {
+		h.logger.Error("Failed to send pairing code: ", err)
 	}
+}
 
-	ctx := context.Background()
-	_, err = h.lambdaClient.Invoke(ctx, input)
+func (h *Handler) uploadPairingQRCode(userID, code, loginURL string) (string, error) {
+	png, err := qrcode.Encode(loginURL, qrcode.Medium, pairingQRCodeSize)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to invoke language-vocabulary lambda")
-		return
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
 	}
 
-	h.logger.WithField("userID", userID).Info("Successfully triggered immediate word push")
+	key := fmt.Sprintf("pairing/%s/%s.png", utils.ScheduleName(userID), code)
+	return h.media.Publish(utils.MediaImage, key, png)
 }
 
-// deleteExistingSchedule 刪除現有的用戶排程（如果存在）
-func (h *Handler) deleteExistingSchedule(userID string) error {
-	scheduleName := fmt.Sprintf("daily-vocab-%s", userID)
+// handlePublicProfile 查看、開啟或關閉公開個人頁面
+func (h *Handler) handlePublicProfile(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	currentSlug := ""
+	if userConfig != nil {
+		currentSlug = userConfig.ProfileSlug
+	}
 
-	h.logger.WithFields(logrus.Fields{
-		"userID":       userID,
+	switch action {
+	case "開啟":
+		// 已開啟時沿用原本的網址，避免已分享的連結失效
+		slug := currentSlug
+		if slug == "" {
+			var err error
+			slug, err = utils.GenerateProfileSlug()
+			if err != nil {
+				h.logger.WithError(err).Error("Failed to generate profile slug")
+				h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.PublicProfileFailed))
+				return
+			}
+			if err := h.userConfigRepo.SetProfileSlug(userID, slug); err != nil {
+				h.logger.WithError(err).Error("Failed to enable public profile")
+				h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.PublicProfileFailed))
+				return
+			}
+		}
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.PublicProfileEnabled, messages.Data{"URL": h.publicProfileURL(slug)}))
+	case "關閉":
+		if err := h.userConfigRepo.SetProfileSlug(userID, ""); err != nil {
+			h.logger.WithError(err).Error("Failed to disable public profile")
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.PublicProfileFailed))
+			return
+		}
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.PublicProfileDisabled))
+	default:
+		url := ""
+		if currentSlug != "" {
+			url = h.publicProfileURL(currentSlug)
+		}
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.PublicProfileStatus, messages.Data{"URL": url}))
+	}
+}
+
+// handleJoinBeta 申請加入 Beta 測試，排入佇列等待管理者核准
+func (h *Handler) handleJoinBeta(replyToken, userID string, userConfig *models.UserConfig) {
+	if userConfig != nil && userConfig.BetaStatus == models.BetaStatusApproved {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.BetaJoinApproved))
+		return
+	}
+	if userConfig != nil && userConfig.BetaStatus == models.BetaStatusPending {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.BetaJoinPending))
+		return
+	}
+
+	queued, err := h.userConfigRepo.RequestBeta(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to request beta access")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.BetaJoinFailed))
+		return
+	}
+	if !queued {
+		// 讀取設定後狀態已被改變（例如剛被核准）
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.BetaJoinPending))
+		return
+	}
+
+	// 通知管理者有新的申請待審核
+	h.logger.WithFields(logrus.Fields{
+		"alert":  "beta_request",
+		"userId": userID,
+	}).Info("New beta access request")
+	h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.BetaJoinRequested))
+}
+
+// requireBeta 檢查模組是否開放給此用戶，未開放時回覆申請說明並回傳 false
+func (h *Handler) requireBeta(replyToken, feature string, userConfig *models.UserConfig) bool {
+	if h.envVars.betaGate.Allows(feature, userConfig) {
+		return true
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.BetaFeatureLocked))
+	return false
+}
+
+// handleQuizStart 從用戶查過的單字產生一組選擇題，送出第一題
+func (h *Handler) handleQuizStart(replyToken, userID string, userConfig *models.UserConfig, deckName string) {
+	if !h.requireBeta(replyToken, utils.BetaFeatureQuiz, userConfig) {
+		return
+	}
+
+	var vocabularies []models.UserVocabulary
+	if deckName != "" {
+		// 指定清單時只用清單中的單字出題，不套用複習來源設定
+		deck, err := h.deckRepo.GetDeck(userID, deckName)
+		if err != nil {
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.QuizFailed))
+			return
+		}
+		if deck == nil {
+			h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckNotFound, messages.Data{"Name": deckName}))
+			return
+		}
+		vocabularies = []models.UserVocabulary{{UserID: userID, Words: deck.WordRecords()}}
+	} else {
+		all, err := h.vocabularyRepo.GetAllUserVocabularies(userID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to get vocabularies for quiz")
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.QuizFailed))
+			return
+		}
+		vocabularies = all
+		if userConfig != nil {
+			vocabularies = models.FilterVocabulariesBySource(vocabularies, userConfig.ReviewSource)
+		}
+	}
+
+	questions, err := utils.BuildQuizQuestions(vocabularies, h.rnd)
+	if err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.QuizNotEnoughWords, messages.Data{"MinWords": utils.QuizOptionCount}))
+		return
+	}
+
+	session := models.QuizSession{
+		UserID:    userID,
+		SessionID: time.Now().UTC().Format(time.RFC3339Nano),
+		Status:    models.QuizStatusActive,
+		Questions: questions,
+		ExpiresAt: h.envVars.recordExpiry.ExpiresAtUnix(time.Now()),
+	}
+	if err := h.quizRepo.SaveQuizSession(session); err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.QuizFailed))
+		return
+	}
+
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, quizQuestionMessage(&session)); err != nil {
+		h.logger.WithError(err).Error("Failed to send quiz question")
+	}
+}
+
+// handleQuizAnswer 記錄作答，回覆對錯後送出下一題；最後一題作答完回報分數
+func (h *Handler) handleQuizAnswer(replyToken, userID string, values url.Values) {
+	question, qErr := strconv.Atoi(values.Get("q"))
+	choice, aErr := strconv.Atoi(values.Get("a"))
+	if qErr != nil || aErr != nil {
+		h.logger.WithField("data", values.Encode()).Warn("Invalid quiz answer postback")
+		return
+	}
+
+	session, err := h.quizRepo.GetQuizSession(userID, values.Get("session"))
+	if err != nil || session == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.QuizFailed))
+		return
+	}
+
+	correct, err := utils.AnswerQuiz(session, question, choice)
+	if err != nil {
+		// 重複點擊或點到舊題目的按鈕
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.QuizStale))
+		return
+	}
+	if err := h.quizRepo.SaveQuizSession(*session); err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.QuizFailed))
+		return
+	}
+
+	answered := session.Questions[question]
+	variant := "wrong"
+	feedback := messages.Render(messages.QuizWrong, messages.Data{
+		"Label":  utils.QuizOptionLabels[answered.Answer],
+		"Answer": answered.Options[answered.Answer],
+	})
+	if correct {
+		variant = "correct"
+		feedback = messages.Text(messages.QuizCorrect)
+	}
+	h.recordInteraction(userID, models.FeatureQuiz, models.InteractionQuizAnswer, variant, 0)
+	if err := h.quizRepo.RecordWordResult(userID, answered.Word, correct); err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to record word mastery")
+	}
+
+	next := quizQuestionMessage(session)
+	if session.Status == models.QuizStatusCompleted {
+		h.recordStreak(userID, nil)
+		next = linebot.NewTextMessage(messages.Render(messages.QuizFinished, messages.Data{
+			"Correct": session.Correct,
+			"Total":   len(session.Questions),
+		}))
+	}
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, linebot.NewTextMessage(feedback), next); err != nil {
+		h.logger.WithError(err).Error("Failed to reply quiz answer")
+	}
+}
+
+// quizQuestionMessage 組出目前題目的訊息，選項以 A-D 的 Quick Reply 作答
+func quizQuestionMessage(session *models.QuizSession) linebot.SendingMessage {
+	question := session.Questions[session.Current]
+
+	type option struct {
+		Label string
+		Text  string
+	}
+	options := make([]option, 0, len(question.Options))
+	labels := make([]string, 0, len(question.Options))
+	postbackData := make([]string, 0, len(question.Options))
+	for i, text := range question.Options {
+		options = append(options, option{Label: utils.QuizOptionLabels[i], Text: text})
+		labels = append(labels, utils.QuizOptionLabels[i])
+		postbackData = append(postbackData, utils.QuizAnswerPostbackData(session.SessionID, session.Current, i))
+	}
+
+	key := messages.QuizQuestionWordToMeaning
+	if question.Direction == models.QuizMeaningToWord {
+		key = messages.QuizQuestionMeaningToWord
+	}
+	text := messages.Render(key, messages.Data{
+		"Number":       session.Current + 1,
+		"Total":        len(session.Questions),
+		"Word":         question.Word,
+		"PartOfSpeech": question.PartOfSpeech,
+		"Translation":  question.Translation,
+		"Options":      options,
+	})
+
+	return linebot.NewTextMessage(text).WithQuickReplies(messages.QuizOptionReplies(labels, postbackData))
+}
+
+// handleCreateDeck 建立新的單字清單，例如「/建立清單 旅遊英文」
+func (h *Handler) handleCreateDeck(replyToken, userID, arg string) {
+	name, err := utils.ValidateDeckName(arg)
+	if err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckNameInvalid, messages.Data{"MaxLength": utils.MaxDeckNameLength}))
+		return
+	}
+
+	decks, err := h.deckRepo.GetDecks(userID)
+	if err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckFailed))
+		return
+	}
+	if len(decks) >= utils.MaxDecks {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckLimit, messages.Data{"MaxDecks": utils.MaxDecks}))
+		return
+	}
+
+	created, err := h.deckRepo.CreateDeck(userID, name)
+	if err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckFailed))
+		return
+	}
+	if !created {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckExists, messages.Data{"Name": name}))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckCreated, messages.Data{"Name": name}))
+}
+
+// handleDecks 沒有參數時列出所有清單，指定清單名稱時回顧清單中的單字
+func (h *Handler) handleDecks(replyToken, userID, name string) {
+	if name == "" {
+		decks, err := h.deckRepo.GetDecks(userID)
+		if err != nil {
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckFailed))
+			return
+		}
+		if len(decks) == 0 {
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckListEmpty))
+			return
+		}
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckList, messages.Data{"Decks": decks}))
+		return
+	}
+
+	deck, err := h.deckRepo.GetDeck(userID, name)
+	if err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckFailed))
+		return
+	}
+	if deck == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckNotFound, messages.Data{"Name": name}))
+		return
+	}
+
+	// 單字很多的清單超過單次回覆上限，其餘改用推播
+	if err := h.replyAndPushRest(replyToken, userID, messages.TextMessages(models.FormatDeck(*deck))); err != nil {
+		h.logger.WithError(err).Error("Failed to send deck words")
+	}
+}
+
+// replyAndPushRest 回覆前 messages.MaxMessagesPerRequest 則訊息，超過單次回覆上限的部分改用推播
+func (h *Handler) replyAndPushRest(replyToken, userID string, replies []linebot.SendingMessage) error {
+	batch := min(len(replies), messages.MaxMessagesPerRequest)
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, replies[:batch]...); err != nil {
+		return fmt.Errorf("failed to reply: %w", err)
+	}
+	if err := utils.PushInBatches(h.linebotClient, userID, replies[batch:]); err != nil {
+		return fmt.Errorf("failed to push remaining messages: %w", err)
+	}
+	return nil
+}
+
+// maxQuickReplyButtons LINE 一則訊息最多可帶的 quick reply 按鈕數
+const maxQuickReplyButtons = 13
+
+// attachTranslationReplies 在翻譯結果的最後一則訊息加上按鈕：英文單字的「換個例句」（例句檢查與重新生成只支援英文）、
+// 每個單字的「回報問題」，以及用戶有建立清單時的「加入「清單」」。超過 LINE 的按鈕上限時保留前面的按鈕。
+// input 是用戶查詢的原文，回報時清除它的翻譯快取
+func (h *Handler) attachTranslationReplies(userID, language, input string, translations []utils.Translation, replies []linebot.SendingMessage) {
+	if len(translations) == 0 || len(replies) == 0 {
+		return
+	}
+	last, ok := replies[len(replies)-1].(*linebot.TextMessage)
+	if !ok {
+		return
+	}
+
+	words := make([]string, 0, len(translations))
+	for _, translation := range translations {
+		words = append(words, translation.Word)
+	}
+	// 與 SaveWord 相同以 UTC 日期存入單字庫
+	date := time.Now().UTC().Format("2006-01-02")
+
+	var buttons []*linebot.QuickReplyButton
+	if language == courses.LanguageEnglish {
+		var regenerateWords, regenerateData []string
+		for _, word := range words {
+			if data := utils.RegenerateExamplePostbackData(word, date); data != "" {
+				regenerateWords = append(regenerateWords, word)
+				regenerateData = append(regenerateData, data)
+			}
+		}
+		buttons = append(buttons, messages.RegenerateExampleButtons(regenerateWords, regenerateData)...)
+	}
+
+	var reportWords, reportData []string
+	cacheKey := utils.TranslationCacheText(input)
+	for _, translation := range translations {
+		if data := utils.ContentReportPostbackData(models.ContentKindTranslation, translation.Word, translation.PartOfSpeech, translation.Meaning, translation.Example.En, cacheKey); data != "" {
+			reportWords = append(reportWords, translation.Word)
+			reportData = append(reportData, data)
+		}
+	}
+	buttons = append(buttons, messages.ContentReportButtons(reportWords, reportData)...)
+
+	if decks, err := h.deckRepo.GetDecks(userID); err == nil && len(decks) > 0 {
+		names := make([]string, 0, len(decks))
+		data := make([]string, 0, len(decks))
+		for _, deck := range decks {
+			names = append(names, deck.Name)
+			data = append(data, utils.DeckAddPostbackData(deck.Name, date, words))
+		}
+		buttons = append(buttons, messages.DeckAddReplies(names, data).Items...)
+	}
+
+	if len(buttons) == 0 {
+		return
+	}
+	if len(buttons) > maxQuickReplyButtons {
+		buttons = buttons[:maxQuickReplyButtons]
+	}
+	replies[len(replies)-1] = last.WithQuickReplies(linebot.NewQuickReplyItems(buttons...))
+}
+
+// handleRegenerateExample 依用戶目前的程度重新生成單字庫中單字的例句，更新單字庫後回覆新例句
+func (h *Handler) handleRegenerateExample(replyToken, userID string, values url.Values) {
+	word := values.Get("word")
+	date := values.Get("date")
+	logger := h.logger.WithFields(logrus.Fields{"word": word, "date": date})
+
+	vocabulary, err := h.vocabularyRepo.GetUserVocabularyByDate(userID, date)
+	if err != nil || vocabulary == nil {
+		logger.WithError(err).Warn("Failed to load vocabulary for example regeneration")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ExampleRegenerateFailed))
+		return
+	}
+	var record *models.WordRecord
+	for i := range vocabulary.Words {
+		if strings.EqualFold(strings.TrimSpace(vocabulary.Words[i].Word), strings.TrimSpace(word)) {
+			record = &vocabulary.Words[i]
+		}
+	}
+	if record == nil {
+		logger.Warn("Word not found for example regeneration")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ExampleRegenerateFailed))
+		return
+	}
+
+	// 依課程分數換算的級距（例如 B1）生成例句；尚未設定程度時不指定
+	var level string
+	if userConfig, err := h.userConfigRepo.GetUserConfig(userID); err == nil && userConfig != nil {
+		level = utils.LevelBand(userConfig.Course, userConfig.Level)
+	}
+
+	example, err := h.openaiClient.RegenerateExample(record.Word, record.PartOfSpeech, record.Translation, level)
+	if err != nil {
+		logger.WithError(err).Error("Failed to regenerate example")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ExampleRegenerateFailed))
+		return
+	}
+	if _, err := h.vocabularyRepo.UpdateWordSentence(userID, date, record.Word, example.En); err != nil {
+		// 更新失敗（例如同時加入了新單字）時仍回覆新例句，單字庫保留原本的例句
+		logger.WithError(err).Warn("Failed to save regenerated example")
+	}
+
+	text := linebot.NewTextMessage(messages.Render(messages.ExampleRegenerated, messages.Data{"Word": record.Word, "En": example.En, "Zh": example.Zh}))
+	var reply linebot.SendingMessage = text
+	if data := utils.RegenerateExamplePostbackData(word, date); data != "" {
+		reply = text.WithQuickReplies(linebot.NewQuickReplyItems(messages.RegenerateExampleButtons([]string{record.Word}, []string{data})...))
+	}
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, reply); err != nil {
+		logger.WithError(err).Error("Failed to reply regenerated example")
+	}
+}
+
+// contentReportReasonLabels 回報原因按鈕的文字，依 models.ContentReportReasons 的順序顯示
+var contentReportReasonLabels = map[models.ContentReportReason]messages.Key{
+	models.ContentReportIncorrect:     messages.ContentReportIncorrectLabel,
+	models.ContentReportInappropriate: messages.ContentReportInappropriateLabel,
+	models.ContentReportOther:         messages.ContentReportOtherLabel,
+}
+
+// handleContentReport 處理 AI 產生內容的「回報內容問題」按鈕：第一次點擊時請用戶選擇原因（按鈕帶著同樣的字卡內容），
+// 選擇原因後放入審核佇列；開啟 CONTENT_REPORT_SUPPRESS 時，審核前每日單字不再使用這個單字
+func (h *Handler) handleContentReport(replyToken, userID string, values url.Values) {
+	word := values.Get("word")
+	if values.Get("reason") == "" {
+		labels := make([]string, 0, len(models.ContentReportReasons))
+		postbackData := make([]string, 0, len(models.ContentReportReasons))
+		for _, reason := range models.ContentReportReasons {
+			values.Set("reason", string(reason))
+			labels = append(labels, messages.Text(contentReportReasonLabels[reason]))
+			postbackData = append(postbackData, values.Encode())
+		}
+		prompt := linebot.NewTextMessage(messages.Render(messages.ContentReportReasonPrompt, messages.Data{"Word": word})).
+			WithQuickReplies(messages.ContentReportReasonReplies(labels, postbackData))
+		if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, prompt); err != nil {
+			h.logger.WithError(err).Error("Failed to reply content report reasons")
+		}
+		return
+	}
+
+	userConfig, err := h.userConfigRepo.GetUserConfig(userID)
+	if err != nil {
+		// 用戶設定只用來記錄回報當下的課程與模型，讀取失敗仍然收下回報
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to get user config for content report")
+	}
+	report, err := utils.NewContentReport(userID, values, userConfig, h.envVars.contentReportSuppress, time.Now())
+	if err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Ignoring invalid content report")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ContentReportFailed))
+		return
+	}
+	logger := h.logger.WithFields(logrus.Fields{"reportId": report.ReportID, "kind": report.Kind, "word": report.Word, "reason": report.Reason})
+
+	if err := h.contentReportRepo.OpenContentReport(report); err != nil {
+		logger.WithError(err).Error("Failed to save content report")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ContentReportFailed))
+		return
+	}
+	if report.Suppressed {
+		if err := h.contentReportRepo.SuppressWord(utils.NormalizeHistoryWord(report.Word), report.ReportID); err != nil {
+			// 回報已進入佇列，暫停失敗只影響審核前是否可能再次推播
+			logger.WithError(err).Warn("Failed to suppress reported word")
+		}
+	}
+	// 被回報的翻譯不再從快取回覆，下次查詢重新翻譯；快取以用戶查詢的原文為 key，不是翻譯出的單字
+	if report.Kind == models.ContentKindTranslation && report.CacheKey != "" {
+		if err := h.translationCache.Forget(report.CacheKey); err != nil {
+			logger.WithError(err).Warn("Failed to forget cached translation")
+		}
+	}
+	logger.Info("Content reported")
+
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ContentReportReceived, messages.Data{"Word": report.Word, "Suppressed": report.Suppressed}))
+}
+
+// handleDeckAdd 將翻譯結果的單字從單字庫加入選擇的清單
+func (h *Handler) handleDeckAdd(replyToken, userID string, values url.Values) {
+	name := values.Get("deck")
+	deck, err := h.deckRepo.GetDeck(userID, name)
+	if err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckAddFailed))
+		return
+	}
+	if deck == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckNotFound, messages.Data{"Name": name}))
+		return
+	}
+
+	vocabulary, err := h.vocabularyRepo.GetUserVocabularyByDate(userID, values.Get("date"))
+	if err != nil || vocabulary == nil {
+		h.logger.WithError(err).WithField("date", values.Get("date")).Warn("Failed to load vocabulary for deck add")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckAddFailed))
+		return
+	}
+
+	added := utils.AddWordsToDeck(deck, vocabulary.Words, values["w"], time.Now())
+	if added > 0 {
+		if err := h.deckRepo.SaveDeck(*deck); err != nil {
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.DeckAddFailed))
+			return
+		}
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.DeckAdded, messages.Data{"Name": deck.Name, "Added": added}))
+}
+
+// handleCardFormat 查看或切換每日單字的呈現方式（文字／圖片字卡）
+func (h *Handler) handleCardFormat(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+
+	var format models.CardFormat
+	switch action {
+	case "圖片":
+		format = models.CardFormatImage
+	case "文字":
+		format = models.CardFormatText
+	default:
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.CardFormatStatus, messages.Data{"Format": userConfig.CardFormat}))
+		return
+	}
+
+	if err := h.userConfigRepo.SetCardFormat(userID, format); err != nil {
+		h.logger.WithError(err).Error("Failed to save card format")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.CardFormatFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.CardFormatUpdated, messages.Data{"Format": format}))
+}
+
+// handlePronunciation 設定每日單字推播是否附上發音音檔
+func (h *Handler) handlePronunciation(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+
+	var enabled bool
+	switch action {
+	case "開啟":
+		if h.killSwitches.Disabled(models.KillSwitchTTS) {
+			h.linebotClient.ReplyMessage(replyToken, utils.MaintenanceMessage(models.KillSwitchTTS))
+			return
+		}
+		// 發音是付費功能，支持者方案或試用中才能開啟；檢查失敗時放行，每日推播時會再檢查一次
+		allowed, err := h.featureGate.Allowed(*userConfig, models.PremiumFeatureAudio)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to check pronunciation audio access, allowing")
+			allowed = true
+		}
+		if !allowed {
+			h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.PronunciationLocked, messages.Data{
+				"TrialDays": utils.TrialDays(models.PremiumFeatureAudio),
+				"CanPay":    h.payments != nil,
+			}))
+			return
+		}
+		enabled = true
+	case "關閉":
+		enabled = false
+	default:
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.PronunciationStatus, messages.Data{"Enabled": userConfig.PronunciationAudio}))
+		return
+	}
+
+	if err := h.userConfigRepo.SetPronunciationAudio(userID, enabled); err != nil {
+		h.logger.WithError(err).Error("Failed to save pronunciation audio setting")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.PronunciationFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.PronunciationUpdated, messages.Data{"Enabled": enabled}))
+}
+
+// handleModel 設定翻譯與每日單字使用的 OpenAI 模型，只接受 utils.AllowedOpenAIModels 內的模型
+func (h *Handler) handleModel(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+
+	model := strings.ToLower(action)
+	if action == "預設" {
+		model = ""
+	} else if action == "" || !utils.ValidOpenAIModel(model) {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ModelStatus, messages.Data{"Model": userConfig.Model, "Models": utils.AllowedOpenAIModels}))
+		return
+	}
+
+	if err := h.userConfigRepo.SetModel(userID, model); err != nil {
+		h.logger.WithError(err).Error("Failed to save model setting")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ModelFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ModelUpdated, messages.Data{"Model": model}))
+}
+
+// handleGrammarMode 開啟或關閉文法模式，不帶參數時顯示目前的設定
+func (h *Handler) handleGrammarMode(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+
+	var enabled bool
+	switch action {
+	case "開啟":
+		enabled = true
+	case "關閉":
+		enabled = false
+	default:
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.GrammarModeStatus, messages.Data{"Enabled": userConfig.GrammarMode}))
+		return
+	}
+
+	if err := h.userConfigRepo.SetGrammarMode(userID, enabled); err != nil {
+		h.logger.WithError(err).Error("Failed to save grammar mode setting")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.GrammarModeFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.GrammarModeUpdated, messages.Data{"Enabled": enabled}))
+}
+
+// requireTerms 是事件路由中服務條款的 middleware：服務條款更新（TERMS_VERSION）後，尚未同意新版本的用戶第一次傳訊息時
+// 推播一次同意按鈕，之後照常處理；同意前使用付費功能的指令時改回覆同意按鈕。回傳 false 表示事件已處理，不再往下分派
+func (h *Handler) requireTerms(replyToken, userID, text string, userConfig *models.UserConfig) bool {
+	terms := h.envVars.terms
+	// 還沒有用戶資料的用戶也還不能使用付費功能，等建立資料後再提示
+	if userConfig == nil || terms.Accepted(*userConfig) {
+		return true
+	}
+
+	if utils.IsPremiumCommand(text) {
+		if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, messages.TermsTemplate(terms.Version, terms.URL)); err != nil {
+			h.logger.Error("Failed to reply terms prompt: ", err)
+		}
+		return false
+	}
+
+	if terms.NeedsPrompt(*userConfig) {
+		// reply token 留給這則訊息本身的回覆，條款提示改用 push；同時處理的其他事件只會有一個提示成功
+		prompted, err := h.userConfigRepo.MarkTermsPrompted(userID, terms.Version)
+		if err != nil {
+			h.logger.WithError(err).WithField("userID", userID).Warn("Failed to mark terms prompted")
+		} else if prompted {
+			if err := h.linebotClient.PushMessages(userID, messages.TermsTemplate(terms.Version, terms.URL)); err != nil {
+				h.logger.WithError(err).WithField("userID", userID).Warn("Failed to push terms prompt")
+			}
+		}
+	}
+	return true
+}
+
+// handleTermsAccept 記錄用戶同意的服務條款版本；按下的是舊版本的按鈕時改回覆目前版本的條款
+func (h *Handler) handleTermsAccept(replyToken, userID, version string) {
+	terms := h.envVars.terms
+	if terms == nil {
+		return
+	}
+	if version != terms.Version {
+		h.linebotClient.ReplyMessageWithMultiple(replyToken, messages.TermsTemplate(terms.Version, terms.URL))
+		return
+	}
+
+	if err := h.userConfigRepo.AcceptTerms(userID, version, time.Now()); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to accept terms")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.TermsAcceptFailed))
+		return
+	}
+	h.logger.WithFields(logrus.Fields{"userID": userID, "termsVersion": version}).Info("User accepted terms")
+	h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.TermsAccepted))
+}
+
+// consentNames 隱私設定中各同意項目顯示的名稱
+var consentNames = map[models.ConsentType]messages.Key{
+	models.ConsentAnalytics: messages.ConsentAnalyticsName,
+	models.ConsentMarketing: messages.ConsentMarketingName,
+	models.ConsentAIData:    messages.ConsentAIDataName,
+}
+
+// handlePrivacySettings 顯示目前的隱私設定，並附上切換每個同意項目的按鈕。不需要完成設定，追蹤後隨時可以修改
+func (h *Handler) handlePrivacySettings(replyToken string, userConfig *models.UserConfig) {
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, consentSettingsMessage(userConfig)); err != nil {
+		h.logger.Error("Failed to reply privacy settings: ", err)
+	}
+}
+
+// consentSettingsMessage 隱私設定的狀態訊息，每個項目一個按鈕，按下後切換為相反的設定
+func consentSettingsMessage(userConfig *models.UserConfig) linebot.SendingMessage {
+	var current models.UserConfig
+	if userConfig != nil {
+		current = *userConfig
+	}
+
+	var labels, postbackData []string
+	for _, consent := range models.ConsentTypes {
+		grant := !current.HasConsent(consent)
+		labels = append(labels, messages.Render(messages.ConsentToggleLabel, messages.Data{"Name": messages.Text(consentNames[consent]), "Grant": grant}))
+		values := url.Values{}
+		values.Set("action", messages.PostbackConsent)
+		values.Set("type", string(consent))
+		values.Set("granted", strconv.FormatBool(grant))
+		postbackData = append(postbackData, values.Encode())
+	}
+
+	return linebot.NewTextMessage(messages.Render(messages.ConsentStatus, messages.Data{
+		"Analytics": current.ConsentAnalytics,
+		"Marketing": current.ConsentMarketing,
+		"AIData":    current.ConsentAIData,
+		"Answered":  current.ConsentUpdatedAt != "",
+	})).WithQuickReplies(messages.ConsentToggleReplies(labels, postbackData))
+}
+
+// handleConsentPostback 處理 onboarding 詢問（全部同意 / 只保留必要 / 個別設定）與隱私設定切換單一項目的按鈕
+func (h *Handler) handleConsentPostback(replyToken, userID string, values url.Values) {
+	consents := map[models.ConsentType]bool{}
+	switch choice := values.Get("choice"); choice {
+	case messages.ConsentChoiceAll, messages.ConsentChoiceEssential:
+		for _, consent := range models.ConsentTypes {
+			consents[consent] = choice == messages.ConsentChoiceAll
+		}
+	case messages.ConsentChoiceCustom:
+		userConfig, err := h.userConfigRepo.GetUserConfig(userID)
+		if err != nil {
+			h.logger.WithError(err).WithField("userID", userID).Error("Failed to get user config")
+		}
+		h.handlePrivacySettings(replyToken, userConfig)
+		return
+	default:
+		consent, err := models.ParseConsentType(values.Get("type"))
+		if err != nil {
+			h.logger.WithError(err).Warn("Unknown consent type in postback")
+			return
+		}
+		granted, err := strconv.ParseBool(values.Get("granted"))
+		if err != nil {
+			h.logger.WithField("granted", values.Get("granted")).Warn("Invalid consent value in postback")
+			return
+		}
+		consents[consent] = granted
+	}
+
+	if err := h.userConfigRepo.SetConsents(userID, consents, time.Now()); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Error("Failed to save consents")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ConsentFailed))
+		return
+	}
+	h.consents.Forget(userID)
+	h.logger.WithFields(logrus.Fields{"userID": userID, "consents": consents}).Info("Updated consents")
+
+	reply := []linebot.SendingMessage{linebot.NewTextMessage(messages.Text(messages.ConsentUpdated))}
+	if userConfig, err := h.userConfigRepo.GetUserConfig(userID); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to reload consents")
+	} else {
+		reply = append(reply, consentSettingsMessage(userConfig))
+	}
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, reply...); err != nil {
+		h.logger.Error("Failed to reply consent update: ", err)
+	}
+}
+
+// handleInteractiveReview 開啟或關閉每晚的互動回顧，不帶參數時顯示目前的設定
+func (h *Handler) handleInteractiveReview(replyToken, userID string, userConfig *models.UserConfig, action string) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+
+	var enabled bool
+	switch action {
+	case "開啟":
+		enabled = true
+	case "關閉":
+		enabled = false
+	default:
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.InteractiveReviewStatus, messages.Data{"Enabled": userConfig.InteractiveReview}))
+		return
+	}
+
+	if err := h.userConfigRepo.SetInteractiveReview(userID, enabled); err != nil {
+		h.logger.WithError(err).Error("Failed to save interactive review setting")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.InteractiveReviewFailed))
+		return
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.InteractiveReviewUpdated, messages.Data{"Enabled": enabled}))
+}
+
+// handleReviewResponse 記錄互動回顧中「我記得 / 忘記了」的回答並更新間隔複習紀錄，接著顯示答案與下一個單字
+func (h *Handler) handleReviewResponse(replyToken, userID string, values url.Values) {
+	index, err := strconv.Atoi(values.Get("i"))
+	if err != nil {
+		h.logger.WithField("data", values.Encode()).Warn("Invalid review response postback")
+		return
+	}
+	response := values.Get("r")
+
+	session, err := h.reviewRepo.GetReviewSession(userID, values.Get("date"))
+	if err != nil || session == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.InteractiveReviewExpired))
+		return
+	}
+
+	if err := utils.AnswerReview(session, index, response); err != nil {
+		// 重複點擊或點到舊單字的按鈕
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.InteractiveReviewStale))
+		return
+	}
+	if session.Completed() {
+		session.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if err := h.reviewRepo.SaveReviewSession(*session); err != nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.InteractiveReviewExpired))
+		return
+	}
+
+	item := session.Items[index]
+	h.recordInteraction(userID, models.FeatureReview, models.InteractionReviewResponse, response, 0)
+	record, err := h.reviewRepo.GetReviewRecord(userID, item.Word)
+	if err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to get review record, starting a new one")
+	}
+	next := utils.NextReviewRecord(record, item.Word, response, session.Date, time.Now())
+	if err := h.reviewRepo.SaveReviewRecord(userID, next); err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to save review record")
+	}
+
+	answer := linebot.NewTextMessage(messages.Render(messages.InteractiveReviewAnswer, messages.Data{
+		"Remembered":  response == models.ReviewRemembered,
+		"Word":        item.Word,
+		"Translation": item.Translation,
+		"Sentence":    item.Sentence,
+	}))
+	var following linebot.SendingMessage
+	if session.Completed() {
+		h.recordStreak(userID, nil)
+		following = linebot.NewTextMessage(messages.Render(messages.InteractiveReviewFinished, messages.Data{
+			"Remembered": session.Remembered,
+			"Total":      len(session.Items),
+		}))
+	} else {
+		following = utils.ReviewCardMessage(session)
+	}
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, answer, following); err != nil {
+		h.logger.WithError(err).Error("Failed to reply review response")
+	}
+}
+
+func (h *Handler) publicProfileURL(slug string) string {
+	return fmt.Sprintf("%s/u/%s", strings.TrimRight(h.envVars.profileBaseURL, "/"), slug)
+}
+
+func (h *Handler) handlePushSettings(replyToken, userID string, userConfig *models.UserConfig) {
+	if userConfig != nil && userConfig.Course != "" {
+		// 用戶已有課程設定，直接進入單字量選擇
+		replies := messages.Build(messages.PushSettingsDailyWords, messages.Data{"CourseName": courses.DisplayName(userConfig.Course)})
+
+		// 暫存用戶已有的課程
+		h.saveSetupDraft(models.SetupDraft{UserID: userID, Course: userConfig.Course})
+
+		if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, replies...); err != nil {
+			h.logger.Error("Failed to send daily words selection: ", err)
+		}
+	} else {
+		// 用戶沒有課程設定，顯示課程選擇
+		h.handlePushSettingsStart(replyToken)
+	}
+}
+
+func (h *Handler) handleSkipPushSettings(replyToken, userID string, userConfig *models.UserConfig) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+
+	// 使用預設設定：10個單字，早上8:00推播（時區沿用用戶的設定，未設定時為台灣時間）
+	userConfig.DailyWords = 10    // 預設每日單字數量
+	userConfig.PushTime = "08:00" // 預設推播時間
+	if userConfig.Timezone == "" {
+		userConfig.Timezone = models.DefaultTimezone
+	}
+
+	// 使用預設設定：10個單字，早上8:00推播
+	if err := h.userConfigRepo.SaveUserConfig(userID, userConfig.DisplayName, userConfig.Course, userConfig.Level, userConfig.DailyWords, userConfig.PushTime, userConfig.Timezone); err != nil {
+		h.logger.WithError(err).Error("Failed to save default push settings")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupFailed))
+		return
+	}
+	h.recordInteraction(userID, models.FeaturePushSettings, models.InteractionPushConfigured, "default", 0)
+
+	message := messages.Render(messages.PushSettingsDefaultDone, messages.Data{
+		"CourseName": courses.DisplayName(userConfig.Course),
+		"NextPush":   h.nextPushText(userConfig.PushTime, userConfig.Timezone),
+	})
+
+	// 設定推播排程並立即推播
+	if err := h.setupUserPushSchedule(userID, userConfig.PushTime, userConfig.Timezone, "default push settings selected"); err != nil {
+		errorMessage := messages.Text(messages.ScheduleFailed)
+		if replyErr := h.linebotClient.ReplyMessage(replyToken, errorMessage); replyErr != nil {
+			h.logger.Error("Failed to send error message: ", replyErr)
+		}
+		return
+	}
+
+	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
+		h.logger.Error("Failed to send default settings confirmation: ", err)
+	}
+}
+
+func (h *Handler) handleDailyWordsSelection(replyToken, userID string, dailyWords int) {
+	// 推播時間選擇的 Quick Reply
+	replies := messages.Build(messages.DailyWordsSelected, messages.Data{"DailyWords": dailyWords})
+
+	// 暫存用戶選擇的單字量
+	h.saveSetupDraft(models.SetupDraft{UserID: userID, DailyWords: dailyWords})
+	h.recordInteraction(userID, models.FeaturePushSettings, models.InteractionDailyWordsSelected, strconv.Itoa(dailyWords), 0)
+
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, replies...); err != nil {
+		h.logger.Error("Failed to send push time selection: ", err)
+	}
+}
+
+// handlePushTimeSelection 暫存推播時間，接著詢問時區
+func (h *Handler) handlePushTimeSelection(replyToken, userID, pushTime string) {
+	h.saveSetupDraft(models.SetupDraft{UserID: userID, PushTime: pushTime})
+
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, messages.Build(messages.PushTimeSelected, messages.Data{"PushTime": pushTime})...); err != nil {
+		h.logger.Error("Failed to send timezone selection: ", err)
+	}
+}
+
+// handleTimezoneInput 驗證用戶輸入的 IANA 時區名稱，無效時請用戶重新輸入或點選按鈕
+func (h *Handler) handleTimezoneInput(replyToken, userID, text string, userConfig *models.UserConfig) {
+	timezone, err := utils.ValidateTimezone(text)
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Info("User entered an invalid timezone")
+		if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, messages.Build(messages.TimezoneInvalid, messages.Data{"Timezone": strings.TrimSpace(text)})...); err != nil {
+			h.logger.Error("Failed to send invalid timezone message: ", err)
+		}
+		return
+	}
+	h.handleTimezoneSelection(replyToken, userID, timezone, userConfig)
+}
+
+// handleTimezoneSelection 推播設定的最後一步：儲存單字量、推播時間與時區，並建立推播排程
+func (h *Handler) handleTimezoneSelection(replyToken, userID, timezone string, userConfig *models.UserConfig) {
+	// 獲取暫存的推播時間、單字量和課程
+	draft := h.setupDraft(userID)
+	pushTime := draft.PushTime
+	if pushTime == "" {
+		pushTime = "08:00" // 預設值
+	}
+
+	dailyWords := draft.DailyWords
+	if dailyWords == 0 {
+		dailyWords = 10 // 預設值
+	}
+
+	tempCourse := draft.Course
+
+	// 確定最終的課程和等級
+	var finalCourse string
+	var finalLevel int
+	var displayName string
+
+	if tempCourse != "" {
+		// 從推播設定流程來的
+		finalCourse = tempCourse
+		finalLevel = 0 // 預設 level
+		if userConfig != nil {
+			finalLevel = userConfig.Level
+			displayName = userConfig.DisplayName
+		}
+		h.logger.Info("Handling push settings flow")
+	} else {
+		// 從分數設定後的推播設定來的，需要重新獲取用戶設定
+		var err error
+		userConfig, err = h.userConfigRepo.GetUserConfig(userID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to get user config")
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupFailed))
+			return
+		}
+
+		if userConfig == nil {
+			h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+			return
+		}
+
+		finalCourse = userConfig.Course
+		finalLevel = userConfig.Level
+		displayName = userConfig.DisplayName
+		h.logger.Info("Handling score input flow")
+	}
+
+	// 統一更新用戶設定
+	if err := h.userConfigRepo.SaveUserConfig(userID, displayName, finalCourse, finalLevel, dailyWords, pushTime, timezone); err != nil {
+		h.logger.WithError(err).Error("Failed to update user config with push settings")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupFailed))
+		return
+	}
+	h.recordInteraction(userID, models.FeaturePushSettings, models.InteractionPushConfigured, "custom", 0)
+
+	// 清理暫存的選擇
+	if err := h.setupDraftRepo.DeleteSetupDraft(userID); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to delete setup draft")
+	}
+
+	// 統一的成功訊息處理
+	message := messages.Render(messages.PushSettingsDone, messages.Data{
+		"CourseName": courses.DisplayName(finalCourse),
+		"DailyWords": dailyWords,
+		"PushTime":   pushTime,
+		"Timezone":   timezone,
+		"NextPush":   h.nextPushText(pushTime, timezone),
+	})
+
+	// 設定推播排程並立即推播
+	if err := h.setupUserPushSchedule(userID, pushTime, timezone, "push settings updated"); err != nil {
+		errorMessage := messages.Text(messages.ScheduleFailed)
+		if replyErr := h.linebotClient.ReplyMessage(replyToken, errorMessage); replyErr != nil {
+			h.logger.Error("Failed to send error message: ", replyErr)
+		}
+		return
+	}
+
+	if err := h.linebotClient.ReplyMessage(replyToken, message); err != nil {
+		h.logger.Error("Failed to send push settings confirmation: ", err)
+	}
+}
+
+// setupDraft 讀取用戶進行中的推播設定；沒有、已過期或讀取失敗時回傳零值，之後的步驟改用預設值
+func (h *Handler) setupDraft(userID string) models.SetupDraft {
+	draft, err := h.setupDraftRepo.GetSetupDraft(userID)
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to get setup draft")
+		return models.SetupDraft{}
+	}
+	if draft == nil || draft.ExpiresAt <= time.Now().Unix() {
+		return models.SetupDraft{}
+	}
+	return *draft
+}
+
+// saveSetupDraft 暫存推播設定其中一個步驟的選擇，draft 只需要填入這個步驟的欄位
+func (h *Handler) saveSetupDraft(draft models.SetupDraft) {
+	draft.ExpiresAt = time.Now().Add(setupDraftRetention).Unix()
+	if err := h.setupDraftRepo.UpdateSetupDraft(draft); err != nil {
+		h.logger.WithError(err).WithField("userID", draft.UserID).Warn("Failed to save setup draft")
+	}
+}
+
+func (h *Handler) handlePushSettingsStart(replyToken string) {
+	// 使用共用的課程選擇 CarouselTemplate
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, messages.Build(messages.PushSettingsStart, nil)...); err != nil {
+		h.logger.Error("Failed to send push settings course selection: ", err)
+	}
+}
+
+// triggerImmediateWordPush 立即invoke language-vocabulary lambda推播一次單字給用戶
+func (h *Handler) triggerImmediateWordPush(userID string) {
+	h.logger.WithField("userID", userID).Info("Triggering immediate word push")
+
+	// 構造 lambda invoke 請求
+	payloadBytes, err := utils.NewWordPushPayload(userID, models.WordPushSourceSettings)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to marshal lambda invoke payload")
+		return
+	}
+
+	// Invoke language-vocabulary lambda
+	input := &lambda.InvokeInput{
+		FunctionName:   aws.String("language-vocabulary"), // Lambda function name
+		InvocationType: "Event",                           // 異步調用，不等待回應
+		Payload:        payloadBytes,
+	}
+
+	ctx := context.Background()
+	_, err = h.lambdaClient.Invoke(ctx, input)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to invoke language-vocabulary lambda")
+		return
+	}
+
+	h.logger.WithField("userID", userID).Info("Successfully triggered immediate word push")
+}
+
+// deleteExistingSchedule 刪除現有的用戶排程（如果存在），包含舊版以 userID 命名的排程
+func (h *Handler) deleteExistingSchedule(userID, reason string) error {
+	scheduleNames := []string{utils.ScheduleName(userID)}
+	if legacyName := utils.LegacyScheduleName(userID); legacyName != "" {
+		scheduleNames = append(scheduleNames, legacyName)
+	}
+
+	for _, scheduleName := range scheduleNames {
+		if err := h.deleteScheduleIfExists(userID, scheduleName, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteScheduleIfExists 刪除指定名稱的排程（如果存在）
+func (h *Handler) deleteScheduleIfExists(userID, scheduleName, reason string) error {
+	h.logger.WithFields(logrus.Fields{
+		"userID":       userID,
 		"scheduleName": scheduleName,
 	}).Info("Checking for existing schedule")
 
 	// 先檢查排程是否存在
-	_, err := h.schedulerClient.GetSchedule(context.TODO(), &scheduler.GetScheduleInput{
-		Name:      aws.String(scheduleName),
-		GroupName: aws.String("default"),
-	})
-
+	exists, err := h.scheduleExists(scheduleName)
 	if err != nil {
+		return fmt.Errorf("failed to check existing schedule: %w", err)
+	}
+
+	if !exists {
 		// 如果排程不存在，直接返回 nil（這是正常情況）
-		h.logger.WithField("userID", userID).Info("No existing schedule found")
+		h.logger.WithField("scheduleName", scheduleName).Info("No existing schedule found")
 		return nil
 	}
 
 	// 排程存在，刪除它
-	h.logger.WithField("userID", userID).Info("Deleting existing schedule")
+	h.logger.WithField("scheduleName", scheduleName).Info("Deleting existing schedule")
 	_, err = h.schedulerClient.DeleteSchedule(context.TODO(), &scheduler.DeleteScheduleInput{
 		Name:      aws.String(scheduleName),
-		GroupName: aws.String("default"),
+		GroupName: aws.String(utils.ScheduleGroupName),
 	})
+	h.auditScheduleOperation(userID, scheduleName, models.ScheduleOperationDelete, reason, err)
 
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete existing schedule")
 		return fmt.Errorf("failed to delete existing schedule: %w", err)
 	}
 
-	h.logger.WithField("userID", userID).Info("Successfully deleted existing schedule")
+	h.logger.WithField("scheduleName", scheduleName).Info("Successfully deleted existing schedule")
 	return nil
 }
 
-// scheduleWordPush 為用戶創建 EventBridge Scheduler 排程
-func (h *Handler) scheduleWordPush(userID, pushTime, timezone string) error {
+const (
+	getScheduleMaxAttempts = 3
+	getScheduleBaseBackoff = 200 * time.Millisecond
+)
+
+// scheduleExists 查詢排程是否存在
+// 只有 ResourceNotFoundException 視為不存在；限流與服務端錯誤會重試，其他錯誤（例如權限不足）直接回傳
+func (h *Handler) scheduleExists(scheduleName string) (bool, error) {
+	var err error
+	for attempt := 1; attempt <= getScheduleMaxAttempts; attempt++ {
+		_, err = h.schedulerClient.GetSchedule(context.TODO(), &scheduler.GetScheduleInput{
+			Name:      aws.String(scheduleName),
+			GroupName: aws.String(utils.ScheduleGroupName),
+		})
+		if err == nil {
+			return true, nil
+		}
+
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+
+		if !isTransientSchedulerError(err) {
+			h.logger.WithError(err).WithField("scheduleName", scheduleName).Error("Failed to get schedule")
+			return false, err
+		}
+
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"scheduleName": scheduleName,
+			"attempt":      attempt,
+		}).Warn("Transient error getting schedule, retrying")
+
+		if attempt < getScheduleMaxAttempts {
+			time.Sleep(getScheduleBaseBackoff * time.Duration(1<<(attempt-1)))
+		}
+	}
+
+	return false, fmt.Errorf("get schedule still failing after %d attempts: %w", getScheduleMaxAttempts, err)
+}
+
+// isTransientSchedulerError 判斷是否為可重試的暫時性錯誤
+func isTransientSchedulerError(err error) bool {
+	var throttling *types.ThrottlingException
+	var internal *types.InternalServerException
+	return errors.As(err, &throttling) || errors.As(err, &internal)
+}
+
+// scheduleWordPush 為用戶創建 EventBridge Scheduler 排程；fan-out 模式下 utils.FanoutTimezone 的用戶改由
+// language-dispatcher 推播，只移除個人排程，其他時區的用戶仍使用個人排程
+func (h *Handler) scheduleWordPush(userID, pushTime, timezone, reason string) error {
+	if h.envVars.pushMode == utils.PushModeFanout && timezone == utils.FanoutTimezone {
+		return h.switchToFanoutPush(userID, reason)
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"userID":   userID,
 		"pushTime": pushTime,
 		"timezone": timezone,
 	}).Info("Creating EventBridge schedule for user")
 
+	// 先確認推播 Lambda 存在且可以呼叫，避免告訴用戶設定成功但每天推播都失敗
+	if err := h.validateScheduleTarget(); err != nil {
+		return err
+	}
+
 	// 先刪除現有的排程（如果存在）
-	if err := h.deleteExistingSchedule(userID); err != nil {
+	if err := h.deleteExistingSchedule(userID, reason); err != nil {
 		return fmt.Errorf("failed to delete existing schedule: %w", err)
 	}
 
@@ -866,97 +2827,594 @@ func (h *Handler) scheduleWordPush(userID, pushTime, timezone string) error {
 	}
 
 	// 準備 Lambda target payload
-	payload, err := json.Marshal(map[string]string{
-		"userId": userID,
-	})
+	payload, err := utils.NewWordPushPayload(userID, models.WordPushSourceSchedule)
 	if err != nil {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	// 創建 schedule
-	scheduleName := fmt.Sprintf("daily-vocab-%s", userID)
+	// 創建 schedule（名稱以 userID 雜湊產生，避免超過長度限制或包含非法字元）
+	scheduleName := utils.ScheduleName(userID)
 
 	h.logger.WithFields(logrus.Fields{
 		"scheduleName": scheduleName,
 		"expression":   scheduleExpression,
 		"targetArn":    h.envVars.vocabularyFunctionArn,
 		"roleArn":      h.envVars.schedulerRoleArn,
-		"groupName":    "default",
+		"groupName":    utils.ScheduleGroupName,
 	}).Info("Creating EventBridge schedule")
 
+	// 先以停用狀態建立，Scheduler 會在建立時驗證 role 與 target；確認無誤後再啟用，
+	// 避免建立一半的排程在錯誤的設定下觸發
+	flexibleTimeWindow := &types.FlexibleTimeWindow{
+		Mode: types.FlexibleTimeWindowModeOff,
+	}
+	target := &types.Target{
+		Arn:     aws.String(h.envVars.vocabularyFunctionArn),
+		RoleArn: aws.String(h.envVars.schedulerRoleArn),
+		Input:   aws.String(string(payload)),
+	}
 	scheduleOutput, err := h.schedulerClient.CreateSchedule(context.TODO(), &scheduler.CreateScheduleInput{
-		Name:      aws.String(scheduleName),
-		GroupName: aws.String("default"),
-		FlexibleTimeWindow: &types.FlexibleTimeWindow{
-			Mode: types.FlexibleTimeWindowModeOff,
-		},
+		Name:               aws.String(scheduleName),
+		GroupName:          aws.String(utils.ScheduleGroupName),
+		FlexibleTimeWindow: flexibleTimeWindow,
 		ScheduleExpression: aws.String(scheduleExpression),
-		Target: &types.Target{
-			Arn:     aws.String(h.envVars.vocabularyFunctionArn),
-			RoleArn: aws.String(h.envVars.schedulerRoleArn),
-			Input:   aws.String(string(payload)),
-		},
+		Target:             target,
+		State:              types.ScheduleStateDisabled,
 	})
+	h.auditScheduleOperation(userID, scheduleName, models.ScheduleOperationCreate, reason, err)
 	if err != nil {
 		h.logger.WithError(err).Errorf("Failed to create EventBridge schedule: %s", err.Error())
 		return fmt.Errorf("failed to create schedule: %w", err)
 	}
 
+	_, err = h.schedulerClient.UpdateSchedule(context.TODO(), &scheduler.UpdateScheduleInput{
+		Name:               aws.String(scheduleName),
+		GroupName:          aws.String(utils.ScheduleGroupName),
+		FlexibleTimeWindow: flexibleTimeWindow,
+		ScheduleExpression: aws.String(scheduleExpression),
+		Target:             target,
+		State:              types.ScheduleStateEnabled,
+	})
+	h.auditScheduleOperation(userID, scheduleName, models.ScheduleOperationEnable, reason, err)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to enable EventBridge schedule")
+		// 移除停用中的排程，讓用戶重新設定時從頭開始
+		if deleteErr := h.deleteExistingSchedule(userID, reason); deleteErr != nil {
+			h.logger.WithError(deleteErr).Warn("Failed to clean up disabled schedule")
+		}
+		return fmt.Errorf("failed to enable schedule: %w", err)
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"scheduleName": scheduleName,
 		"userID":       userID,
 		"scheduleArn":  aws.ToString(scheduleOutput.ScheduleArn),
 	}).Info("Successfully created EventBridge schedule")
 
+	// 記錄排程名稱供反查用戶，失敗不影響排程本身
+	if err := h.userConfigRepo.SetScheduleName(userID, scheduleName); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to save schedule name to user config")
+	}
+
 	return nil
 }
 
-// createDailyCronExpression 創建每日 cron 表達式
-func (h *Handler) createDailyCronExpression(pushTime, timezone string) (string, error) {
-	// 解析時間 (格式: "HH:MM")
-	t, err := time.Parse("15:04", pushTime)
-	if err != nil {
-		return "", fmt.Errorf("invalid time format: %s", pushTime)
+// switchToFanoutPush 在 fan-out 模式下移除用戶的個人排程，改由 language-dispatcher 依 pushTime 每小時送出推播。
+// 個人排程刪除後才標記 fanoutPush，dispatcher 只處理有標記的用戶，避免個人排程與 dispatcher 重複推播
+func (h *Handler) switchToFanoutPush(userID, reason string) error {
+	if err := h.deleteExistingSchedule(userID, reason); err != nil {
+		return fmt.Errorf("failed to delete existing schedule: %w", err)
+	}
+	if err := h.userConfigRepo.SetFanoutPush(userID); err != nil {
+		return fmt.Errorf("failed to mark fan-out push: %w", err)
 	}
 
-	// 載入時區
-	loc, err := time.LoadLocation(timezone)
+	h.logger.WithField("userID", userID).Info("Daily push handled by the fan-out dispatcher")
+	return nil
+}
+
+// validateScheduleTarget 以 DryRun 呼叫推播 Lambda，確認 target ARN 存在且有呼叫權限（不會真的執行）
+func (h *Handler) validateScheduleTarget() error {
+	_, err := h.lambdaClient.Invoke(context.TODO(), &lambda.InvokeInput{
+		FunctionName:   aws.String(h.envVars.vocabularyFunctionArn),
+		InvocationType: "DryRun",
+	})
 	if err != nil {
-		return "", fmt.Errorf("invalid timezone: %s", timezone)
+		h.logger.WithError(err).WithField("targetArn", h.envVars.vocabularyFunctionArn).Error("Schedule target validation failed")
+		return fmt.Errorf("schedule target validation failed: %w", err)
 	}
+	return nil
+}
 
-	// 將時間轉換為 UTC（EventBridge Scheduler 使用 UTC）
-	now := time.Now().In(loc)
-	todayAtPushTime := time.Date(
-		now.Year(), now.Month(), now.Day(),
-		t.Hour(), t.Minute(), 0, 0, loc,
-	)
-	utcTime := todayAtPushTime.UTC()
-
-	// 創建 cron 表達式: 分 時 日 月 星期 年
-	// 每天在指定時間執行
-	cronExpression := fmt.Sprintf("cron(%d %d * * ? *)", utcTime.Minute(), utcTime.Hour())
+// createDailyCronExpression 創建每日 cron 表達式
+func (h *Handler) createDailyCronExpression(pushTime, timezone string) (string, error) {
+	cronExpression, err := utils.DailyCronExpression(pushTime, timezone, time.Now())
+	if err != nil {
+		return "", err
+	}
 
 	h.logger.WithFields(logrus.Fields{
 		"originalTime": pushTime,
 		"timezone":     timezone,
-		"utcTime":      utcTime.Format("15:04"),
 		"cronExpr":     cronExpression,
 	}).Info("Created daily cron expression")
 
 	return cronExpression, nil
 }
 
+// nextPushText 顯示下一次推播的日期、時間與剩餘時間，計算失敗時回傳空字串（訊息會退回只顯示推播時間）
+func (h *Handler) nextPushText(pushTime, timezone string) string {
+	now := time.Now()
+	next, err := utils.NextPushTime(pushTime, timezone, now)
+	if err != nil {
+		h.logger.WithError(err).WithField("pushTime", pushTime).Warn("Failed to compute next push time")
+		return ""
+	}
+
+	local := now.In(next.Location())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	pushDay := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, time.UTC)
+	until := next.Sub(now)
+	hours := 0 // 不到一小時改顯示分鐘
+	if until >= time.Hour {
+		hours = int(math.Round(until.Hours()))
+	}
+
+	return messages.Render(messages.NextPush, messages.Data{
+		"DaysAhead": int(pushDay.Sub(today).Hours() / 24),
+		"Date":      next.Format("1/2"),
+		"Time":      next.Format("15:04"),
+		"Timezone":  timezone,
+		"Hours":     hours,
+		"Minutes":   int(math.Ceil(until.Minutes())),
+	})
+}
+
 // setupUserPushSchedule 設定用戶推播排程並立即推播一次
-func (h *Handler) setupUserPushSchedule(userID, pushTime, timezone string) error {
+func (h *Handler) setupUserPushSchedule(userID, pushTime, timezone, reason string) error {
+	// 推播由 language-vocabulary 送出，先記錄用戶在哪個官方帳號完成設定
+	h.recordLineDestination(userID)
+
 	// 先建立每日推播排程
-	if err := h.scheduleWordPush(userID, pushTime, timezone); err != nil {
+	if err := h.scheduleWordPush(userID, pushTime, timezone, reason); err != nil {
 		h.logger.WithError(err).Error("Failed to create schedule")
 		return err
 	}
 
 	// 排程建立成功後，立即推播第一次單字
-	go h.triggerImmediateWordPush(userID)
+	h.shutdown.Go(func() { h.triggerImmediateWordPush(userID) })
+
+	return nil
+}
+
+// recordLineDestination 記錄用戶使用的官方帳號，其他 Lambda 推播時以 utils.LineClients.ClientFor 選擇 channel
+func (h *Handler) recordLineDestination(userID string) {
+	if err := h.userConfigRepo.SetLineDestination(userID, h.lineDestination); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to save line destination")
+	}
+}
+
+// auditScheduleOperation 將排程操作寫入稽核紀錄，寫入失敗不影響主要流程
+func (h *Handler) auditScheduleOperation(userID, scheduleName, operation, reason string, opErr error) {
+	entry := models.ScheduleAuditEntry{
+		UserID:       userID,
+		ScheduleName: scheduleName,
+		Operation:    operation,
+		Actor:        "user:" + userID,
+		Reason:       reason,
+		Result:       models.ScheduleResultSuccess,
+	}
+	if opErr != nil {
+		entry.Result = models.ScheduleResultFailure
+		entry.Error = opErr.Error()
+	}
+
+	if err := h.scheduleAuditRepo.RecordScheduleOperation(entry); err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"userID":    userID,
+			"operation": operation,
+		}).Warn("Failed to record schedule audit entry")
+	}
+}
+
+// handleArticleSummary 回覆長文的摘要翻譯與關鍵單字，並將關鍵單字存入單字紀錄
+func (h *Handler) handleArticleSummary(replyToken, userID, text, correlationID string) {
+	summary, err := h.openaiClient.SummarizeArticle(text, utils.ArticleKeyWordCount, h.consents.Allowed(userID, models.ConsentAIData))
+	if utils.IsRateLimited(err) {
+		h.logger.WithError(err).Warn("OpenAI rate limited article summary")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.OpenAIRateLimited))
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to summarize long input")
+		h.failureReporter.Report(userID, models.FailureTranslation, correlationID, err)
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.ArticleSummaryFailed))
+		return
+	}
+
+	for _, word := range summary.KeyVocabulary {
+		if err := h.vocabularyRepo.SaveWord(word.Word, word.PartOfSpeech, word.Meaning, word.Example.En, models.WordSourceTranslation, userID, h.recordExpiresAt()); err != nil {
+			h.logger.Error("Failed to save word: ", err)
+		}
+	}
+
+	if err := h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.ArticleSummary, summary)); err != nil {
+		h.logger.Error("Failed to reply article summary: ", err)
+	}
+}
+
+// groupRankingMedals 排行榜前三名的獎牌，之後的名次以數字表示
+var groupRankingMedals = []string{"🥇", "🥈", "🥉"}
+
+type groupRankingEntry struct {
+	Rank  string
+	Name  string
+	Words int
+}
+
+// handleGroupMessage 處理群組中的文字訊息；一般聊天內容不回覆，以免打擾群組對話
+func (h *Handler) handleGroupMessage(event *linebot.Event, text, requestID string) error {
+	switch {
+	case text == "/說明":
+		h.sendGroupGreeting(event.ReplyToken)
+	case text == "/排行榜":
+		h.handleGroupRanking(event.ReplyToken, event.Source.GroupID)
+	case strings.HasPrefix(text, "/翻譯"):
+		return h.handleGroupTranslation(event, strings.TrimSpace(strings.TrimPrefix(text, "/翻譯")), requestID)
+	}
+	return nil
+}
+
+func (h *Handler) sendGroupGreeting(replyToken string) {
+	if err := h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.GroupGreeting, messages.Data{"Days": utils.GroupRankingDays})); err != nil {
+		h.logger.Error("Failed to send group greeting: ", err)
+	}
+}
+
+// handleGroupTranslation 翻譯群組成員輸入的文字，並將單字存到群組的共用單字庫。
+// 翻譯額度計在查詢的成員；成員沒有提供 userId 時計在群組
+func (h *Handler) handleGroupTranslation(event *linebot.Event, text, requestID string) error {
+	groupID, userID := event.Source.GroupID, event.Source.UserID
+	if text == "" {
+		h.linebotClient.ReplyMessage(event.ReplyToken, messages.Text(messages.GroupTranslateUsage))
+		return nil
+	}
+
+	quotaID := userID
+	var userConfig *models.UserConfig
+	if quotaID == "" {
+		quotaID = groupID
+	} else {
+		config, err := h.userConfigRepo.GetUserConfig(userID)
+		if err != nil {
+			h.logger.WithError(err).Warn("Failed to get group member config")
+		}
+		userConfig = config
+	}
+	if !h.allowTranslation(event.ReplyToken, quotaID, userConfig) {
+		return nil
+	}
+
+	language := utils.TranslationLanguage(text, "")
+	translationResponse, _, err := h.translationCache.Translate(h.openaiClient, text, utils.TranslateOptions{Language: language, SampleFailures: userConfig != nil && userConfig.HasConsent(models.ConsentAIData)})
+	h.tokenUsage.Record(quotaID, utils.FeatureTranslation, translationResponse.Usage)
+	if utils.IsRateLimited(err) {
+		h.logger.WithError(err).Warn("OpenAI rate limited group translation")
+		h.linebotClient.ReplyMessage(event.ReplyToken, messages.Text(messages.OpenAIRateLimited))
+		return nil
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to translate group text")
+		h.failureReporter.Report(quotaID, models.FailureTranslation, requestID, err)
+		// 回傳 500 讓 LINE 重送，因此取消登記讓重送的事件可以重新處理
+		h.releaseWebhookEvent(event.WebhookEventID)
+		return err
+	}
+
+	if language == courses.LanguageEnglish {
+		regenerated, errs := utils.ValidateTranslationExamples(h.openaiClient, translationResponse.Translations)
+		for _, err := range errs {
+			h.logger.WithError(err).Warn("Failed to regenerate example sentence")
+		}
+		if regenerated > 0 {
+			h.logger.Infof("Regenerated %d mismatched example sentences", regenerated)
+		}
+	}
+
+	for _, translation := range translationResponse.Translations {
+		if err := h.vocabularyRepo.SaveGroupWord(groupID, userID, translation.Word, translation.PartOfSpeech, translation.Meaning, translation.Example.En, h.recordExpiresAt()); err != nil {
+			h.logger.WithError(err).Error("Failed to save group word")
+		}
+	}
 
+	if err := h.replyAndPushRest(event.ReplyToken, groupID, messages.TextMessages(translationResponse.Texts())); err != nil {
+		h.logger.Error("Failed to reply group translation: ", err)
+	}
 	return nil
 }
+
+// handleGroupRanking 依最近 utils.GroupRankingDays 天在群組查詢的單字數列出成員排行
+func (h *Handler) handleGroupRanking(replyToken, groupID string) {
+	// 單字紀錄的日期以 UTC 為準
+	now := time.Now().UTC()
+	from := now.AddDate(0, 0, -(utils.GroupRankingDays - 1)).Format("2006-01-02")
+	vocabularies, err := h.vocabularyRepo.GetUserVocabulariesBetween(groupID, from, now.Format("2006-01-02"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get group vocabularies")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.GroupRankingFailed))
+		return
+	}
+
+	contributors := utils.RankGroupContributors(vocabularies)
+	if len(contributors) == 0 {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.GroupRankingEmpty, messages.Data{"Days": utils.GroupRankingDays}))
+		return
+	}
+
+	entries := make([]groupRankingEntry, 0, min(len(contributors), utils.MaxGroupRankingEntries))
+	for i, contributor := range contributors[:cap(entries)] {
+		rank := fmt.Sprintf("%d.", i+1)
+		if i < len(groupRankingMedals) {
+			rank = groupRankingMedals[i]
+		}
+		entries = append(entries, groupRankingEntry{
+			Rank:  rank,
+			Name:  utils.GroupMemberName(h.linebotClient, groupID, contributor.UserID),
+			Words: contributor.Words,
+		})
+	}
+
+	if err := h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.GroupRanking, messages.Data{
+		"Days":    utils.GroupRankingDays,
+		"Entries": entries,
+	})); err != nil {
+		h.logger.Error("Failed to reply group ranking: ", err)
+	}
+}
+
+// handleGrammarCorrection 回覆修正後的句子與每個錯誤的中文說明，取代逐字翻譯
+func (h *Handler) handleGrammarCorrection(replyToken, userID, text, correlationID string, userConfig *models.UserConfig) {
+	correction, err := h.openaiClient.CorrectGrammar(text, userConfig.Model, userConfig.HasConsent(models.ConsentAIData))
+	if utils.IsRateLimited(err) {
+		h.logger.WithError(err).Warn("OpenAI rate limited grammar correction")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.OpenAIRateLimited))
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to correct grammar")
+		h.failureReporter.Report(userID, models.FailureTranslation, correlationID, err)
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.GrammarFailed))
+		return
+	}
+
+	h.recordStreak(userID, userConfig)
+	if err := h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.GrammarCorrection, correction)); err != nil {
+		h.logger.Error("Failed to reply grammar correction: ", err)
+	}
+}
+
+// claimWebhookEvent 登記事件 ID，回傳 false 表示是已處理過的重送事件。
+// 沒有事件 ID 或登記失敗時照常處理，寧可重複回覆也不要漏掉訊息
+func (h *Handler) claimWebhookEvent(event *linebot.Event) bool {
+	if event.WebhookEventID == "" {
+		return true
+	}
+
+	now := time.Now().UTC()
+	claimed, err := h.webhookEventRepo.ClaimWebhookEvent(models.WebhookEvent{
+		EventID:      event.WebhookEventID,
+		UserID:       event.Source.UserID,
+		Type:         string(event.Type),
+		IsRedelivery: event.DeliveryContext.IsRedelivery,
+		ReceivedAt:   now.Format(time.RFC3339),
+		ExpiresAt:    now.Add(webhookEventRetention).Unix(),
+	})
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to claim webhook event, processing anyway")
+		return true
+	}
+	if !claimed {
+		h.logger.WithFields(logrus.Fields{
+			"webhookEventId": event.WebhookEventID,
+			"isRedelivery":   event.DeliveryContext.IsRedelivery,
+		}).Info("Skipping duplicate webhook event")
+	}
+	return claimed
+}
+
+func (h *Handler) releaseWebhookEvent(eventID string) {
+	if eventID == "" {
+		return
+	}
+	if err := h.webhookEventRepo.ReleaseWebhookEvent(eventID); err != nil {
+		h.logger.WithError(err).Warn("Failed to release webhook event")
+	}
+}
+
+// selectTranslationPrompt 依照進行中的 rollout 決定用戶使用的翻譯 prompt 版本
+func (h *Handler) selectTranslationPrompt(userID string) (*models.PromptRollout, string) {
+	rollout, err := h.translationRollout.Get(func() (*models.PromptRollout, error) {
+		return h.promptRolloutRepo.GetPromptRollout(models.PromptTranslation)
+	})
+	if err != nil {
+		// 讀取失敗時沿用上一次的設定，沒有設定則使用 baseline
+		h.logger.WithError(err).Warn("Failed to load translation prompt rollout")
+	}
+
+	if rollout == nil || rollout.Status != models.PromptRolloutActive || !utils.HasTranslationPromptVersion(rollout.CandidateVersion) {
+		return nil, utils.BaselineTranslationPromptVersion
+	}
+
+	if utils.InPromptRollout(userID, rollout.RolloutID, rollout.Percent) {
+		return rollout, rollout.CandidateVersion
+	}
+	return rollout, utils.BaselineTranslationPromptVersion
+}
+
+// allowTranslation 計入一次翻譯類請求，超過方案的每日額度時回覆升級說明並回傳 false。
+// 計數失敗時放行，不因額度檢查影響翻譯
+func (h *Handler) allowTranslation(replyToken, userID string, userConfig *models.UserConfig) bool {
+	// 先檢查每小時的頻率限制，被限制的請求不計入每日額度
+	rateDecision, err := h.rateLimiter.AllowTranslation(userID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to check translation rate limit, allowing request")
+	} else if !rateDecision.Allowed {
+		h.logger.WithFields(logrus.Fields{
+			"limit":      rateDecision.Limit,
+			"retryAfter": rateDecision.RetryAfter.String(),
+		}).Warn("Translation rate limited")
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.TranslationRateLimited, messages.Data{
+			"Limit":   rateDecision.Limit,
+			"Minutes": max(int(math.Ceil(rateDecision.RetryAfter.Minutes())), 1),
+		}))
+		return false
+	}
+
+	config := models.UserConfig{UserID: userID}
+	if userConfig != nil {
+		config = *userConfig
+	}
+	decision, err := h.usageLimiter.AllowTranslation(config)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to check translation quota, allowing request")
+		return true
+	}
+	if decision.Allowed {
+		return true
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"plan":  decision.Plan,
+		"used":  decision.Used,
+		"limit": decision.Limit,
+	}).Info("Translation quota exceeded")
+	h.linebotClient.ReplyMessage(replyToken, h.quotaExceededMessage(decision))
+	return false
+}
+
+// quotaExceededMessage 額度用完時的回覆；開放線上付款時提示 /支持，否則在設定 UPGRADE_URL 時附上升級方案的連結
+func (h *Handler) quotaExceededMessage(decision utils.QuotaDecision) string {
+	return messages.Render(messages.TranslationQuotaExceeded, messages.Data{
+		"Limit":      decision.Limit,
+		"CanPay":     h.payments != nil,
+		"UpgradeURL": h.envVars.upgradeURL,
+	})
+}
+
+// handleSupporterPayment 建立支持者方案的 LINE Pay 付款並回覆付款按鈕，付款完成後由 language-payment 切換方案
+func (h *Handler) handleSupporterPayment(replyToken, userID string, userConfig *models.UserConfig) {
+	if userConfig != nil && userConfig.EffectivePlan() == models.PlanSupporter {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SupporterAlready))
+		return
+	}
+	if h.payments == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.SupporterUnavailable, messages.Data{"UpgradeURL": h.envVars.upgradeURL}))
+		return
+	}
+
+	paymentURL, err := h.payments.StartSupporterPayment(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to start supporter payment")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SupporterPaymentFailed))
+		return
+	}
+
+	price, currency := h.payments.Price()
+	if err := h.linebotClient.ReplyMessageWithMultiple(replyToken, messages.SupporterPaymentTemplate(price, currency, paymentURL)); err != nil {
+		h.logger.Error("Failed to send supporter payment: ", err)
+	}
+}
+
+// handleTrial 開始每日單字發音的試用並自動開啟發音，到期後由 reminder 關閉發音並通知
+func (h *Handler) handleTrial(replyToken, userID string, userConfig *models.UserConfig) {
+	if userConfig == nil {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.SetupRequired))
+		return
+	}
+	if userConfig.EffectivePlan() == models.PlanSupporter {
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.TrialNotNeeded))
+		return
+	}
+	// 發音維護期間不開始試用，以免試用天數在無法使用時流逝
+	if h.killSwitches.Disabled(models.KillSwitchTTS) {
+		h.linebotClient.ReplyMessage(replyToken, utils.MaintenanceMessage(models.KillSwitchTTS))
+		return
+	}
+
+	trial, started, err := h.featureGate.StartTrial(userID, models.PremiumFeatureAudio)
+	if err != nil || trial == nil {
+		h.logger.WithError(err).Error("Failed to start trial")
+		h.linebotClient.ReplyMessage(replyToken, messages.Text(messages.TrialFailed))
+		return
+	}
+	expiresOn := utils.TrialExpiryDate(*trial, userConfig.Timezone)
+	if !started {
+		h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.TrialAlreadyUsed, messages.Data{
+			"Active":    trial.Status == models.TrialActive,
+			"ExpiresOn": expiresOn,
+			"CanPay":    h.payments != nil,
+		}))
+		return
+	}
+
+	if err := h.userConfigRepo.SetPronunciationAudio(userID, true); err != nil {
+		// 試用已開始，用戶仍可自行輸入 /發音 開啟
+		h.logger.WithError(err).Warn("Failed to turn on pronunciation audio for trial")
+	}
+	h.linebotClient.ReplyMessage(replyToken, messages.Render(messages.TrialStarted, messages.Data{
+		"Days":      utils.TrialDays(models.PremiumFeatureAudio),
+		"ExpiresOn": expiresOn,
+	}))
+}
+
+// recordTranslationOutcome 記錄此次翻譯的結果，候選版本表現明顯較差時自動 rollback 並告警
+func (h *Handler) recordTranslationOutcome(rollout *models.PromptRollout, promptVersion string, translateErr error) {
+	if rollout == nil {
+		return
+	}
+
+	parseFailed := errors.Is(translateErr, utils.ErrResponseParse)
+	if translateErr != nil && !parseFailed {
+		// API 錯誤與 prompt 品質無關，不列入指標
+		return
+	}
+
+	if err := h.promptRolloutRepo.RecordPromptOutcome(rollout.Prompt, rollout.RolloutID, promptVersion, parseFailed); err != nil {
+		h.logger.WithError(err).Warn("Failed to record prompt outcome")
+		return
+	}
+
+	if promptVersion != rollout.CandidateVersion {
+		return
+	}
+
+	metrics, err := h.promptRolloutRepo.GetPromptMetrics(rollout.Prompt, rollout.RolloutID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to load prompt metrics")
+		return
+	}
+
+	rollback, reason := utils.EvaluatePromptRollout(metrics[rollout.BaselineVersion], metrics[rollout.CandidateVersion], utils.DefaultPromptRolloutThresholds)
+	if !rollback {
+		return
+	}
+
+	changed, err := h.promptRolloutRepo.EndPromptRollout(rollout.Prompt, rollout.RolloutID, models.PromptRolloutRolledBack, reason)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to roll back prompt rollout")
+		return
+	}
+
+	// 立即停止本 instance 的候選流量，其他 instance 會在快取過期後停止
+	h.translationRollout.Clear()
+
+	if changed {
+		h.logger.WithFields(logrus.Fields{
+			"alert":            "prompt_rollback",
+			"prompt":           rollout.Prompt,
+			"rolloutId":        rollout.RolloutID,
+			"candidateVersion": rollout.CandidateVersion,
+			"reason":           reason,
+		}).Error("Prompt rollout automatically rolled back")
+	}
+}
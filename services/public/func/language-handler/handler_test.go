@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+	"github.com/sirupsen/logrus"
+)
+
+// stubUserConfigRepo 只實作排程流程用到的 SetScheduleName 與 SetFanoutPush，其他方法未實作
+type stubUserConfigRepo struct {
+	utils.UserConfigRepository
+	scheduleNames map[string]string
+	fanoutPush    map[string]bool
+}
+
+func (r *stubUserConfigRepo) SetScheduleName(userID, scheduleName string) error {
+	r.scheduleNames[userID] = scheduleName
+	delete(r.fanoutPush, userID)
+	return nil
+}
+
+func (r *stubUserConfigRepo) SetFanoutPush(userID string) error {
+	delete(r.scheduleNames, userID)
+	r.fanoutPush[userID] = true
+	return nil
+}
+
+type stubScheduleAuditRepo struct {
+	utils.ScheduleAuditRepository
+	entries []models.ScheduleAuditEntry
+}
+
+func (r *stubScheduleAuditRepo) RecordScheduleOperation(entry models.ScheduleAuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+type scheduleTestHandler struct {
+	*Handler
+	lambda    *utils.MockLambdaInvoker
+	scheduler *utils.MockScheduler
+	users     *stubUserConfigRepo
+	audit     *stubScheduleAuditRepo
+}
+
+func newScheduleTestHandler(pushMode string) *scheduleTestHandler {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	h := &scheduleTestHandler{
+		lambda:    &utils.MockLambdaInvoker{},
+		scheduler: utils.NewMockScheduler(),
+		users:     &stubUserConfigRepo{scheduleNames: map[string]string{}, fanoutPush: map[string]bool{}},
+		audit:     &stubScheduleAuditRepo{},
+	}
+	h.Handler = &Handler{
+		logger: logrus.NewEntry(logger),
+		envVars: &EnvVars{
+			vocabularyFunctionArn: "arn:aws:lambda:ap-northeast-1:123456789012:function:language-vocabulary",
+			schedulerRoleArn:      "arn:aws:iam::123456789012:role/scheduler",
+			pushMode:              pushMode,
+		},
+		lambdaClient:      h.lambda,
+		schedulerClient:   h.scheduler,
+		userConfigRepo:    h.users,
+		scheduleAuditRepo: h.audit,
+	}
+	return h
+}
+
+func TestScheduleWordPush(t *testing.T) {
+	t.Run("creates an enabled schedule", func(t *testing.T) {
+		h := newScheduleTestHandler(utils.PushModeSchedule)
+		if err := h.scheduleWordPush("U1", "08:00", "Asia/Taipei", "test"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		name := utils.ScheduleName("U1")
+		schedule := h.scheduler.Schedule(name)
+		if schedule == nil || schedule.State != types.ScheduleStateEnabled || schedule.Expression != "cron(0 0 * * ? *)" {
+			t.Fatalf("Expected an enabled schedule at 00:00 UTC, got %+v", schedule)
+		}
+		payload, err := utils.ParseWordPushPayload([]byte(aws.ToString(schedule.Target.Input)))
+		if err != nil || payload.UserID != "U1" || payload.Source != models.WordPushSourceSchedule {
+			t.Errorf("Unexpected schedule payload %+v (err %v)", payload, err)
+		}
+		if len(h.lambda.Invocations("DryRun")) != 1 {
+			t.Errorf("Expected the target to be validated with a dry run, got %d", len(h.lambda.Inputs))
+		}
+		if h.users.scheduleNames["U1"] != name {
+			t.Errorf("Expected schedule name to be saved, got %q", h.users.scheduleNames["U1"])
+		}
+		if len(h.audit.entries) != 2 || h.audit.entries[0].Operation != models.ScheduleOperationCreate || h.audit.entries[1].Operation != models.ScheduleOperationEnable {
+			t.Errorf("Expected create and enable audit entries, got %+v", h.audit.entries)
+		}
+	})
+
+	t.Run("replaces an existing schedule", func(t *testing.T) {
+		h := newScheduleTestHandler(utils.PushModeSchedule)
+		if err := h.scheduleWordPush("U1", "08:00", "Asia/Taipei", "test"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := h.scheduleWordPush("U1", "21:00", "Asia/Taipei", "test"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if schedule := h.scheduler.Schedule(utils.ScheduleName("U1")); schedule == nil || schedule.Expression != "cron(0 13 * * ? *)" {
+			t.Errorf("Expected the schedule to move to 13:00 UTC, got %+v", schedule)
+		}
+	})
+
+	t.Run("cleans up when enabling fails", func(t *testing.T) {
+		h := newScheduleTestHandler(utils.PushModeSchedule)
+		h.scheduler.Errors["UpdateSchedule"] = errors.New("access denied")
+		if err := h.scheduleWordPush("U1", "08:00", "Asia/Taipei", "test"); err == nil {
+			t.Fatal("Expected enable failure to be returned")
+		}
+		if schedule := h.scheduler.Schedule(utils.ScheduleName("U1")); schedule != nil {
+			t.Errorf("Expected the disabled schedule to be removed, got %+v", schedule)
+		}
+		if _, saved := h.users.scheduleNames["U1"]; saved {
+			t.Error("Schedule name should not be saved when enabling fails")
+		}
+	})
+
+	t.Run("stops when the target cannot be invoked", func(t *testing.T) {
+		h := newScheduleTestHandler(utils.PushModeSchedule)
+		h.lambda.Err = errors.New("function not found")
+		if err := h.scheduleWordPush("U1", "08:00", "Asia/Taipei", "test"); err == nil {
+			t.Fatal("Expected target validation failure to be returned")
+		}
+		if len(h.scheduler.Calls) != 0 {
+			t.Errorf("Expected no scheduler calls, got %v", h.scheduler.Calls)
+		}
+	})
+
+	t.Run("fan-out mode drops the personal schedule", func(t *testing.T) {
+		h := newScheduleTestHandler(utils.PushModeSchedule)
+		if err := h.scheduleWordPush("U1", "08:00", "Asia/Taipei", "test"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		h.envVars.pushMode = utils.PushModeFanout
+		if err := h.scheduleWordPush("U1", "08:00", utils.FanoutTimezone, "test"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if schedule := h.scheduler.Schedule(utils.ScheduleName("U1")); schedule != nil {
+			t.Errorf("Expected the personal schedule to be deleted, got %+v", schedule)
+		}
+		if name, ok := h.users.scheduleNames["U1"]; ok {
+			t.Errorf("Expected the schedule name to be cleared, got %q", name)
+		}
+		if !h.users.fanoutPush["U1"] {
+			t.Error("Expected the user to be marked for fan-out push")
+		}
+	})
+}
+
+func TestTriggerImmediateWordPush(t *testing.T) {
+	h := newScheduleTestHandler(utils.PushModeSchedule)
+	h.triggerImmediateWordPush("U1")
+
+	events := h.lambda.Invocations("Event")
+	if len(events) != 1 || aws.ToString(events[0].FunctionName) != "language-vocabulary" {
+		t.Fatalf("Expected one async invoke of language-vocabulary, got %+v", h.lambda.Inputs)
+	}
+	payload, err := utils.ParseWordPushPayload(events[0].Payload)
+	if err != nil || payload.UserID != "U1" || payload.Source != models.WordPushSourceSettings {
+		t.Errorf("Unexpected payload %+v (err %v)", payload, err)
+	}
+}
@@ -3,13 +3,17 @@ package main
 import (
 	"context"
 	"errors"
+	"language-assistant/internal/models"
 	"language-assistant/internal/repository"
 	"language-assistant/internal/utils"
 	"os"
+	"time"
 
+	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
 	lambdaService "github.com/aws/aws-sdk-go-v2/service/lambda"
 	schedulerService "github.com/aws/aws-sdk-go-v2/service/scheduler"
 	"github.com/sirupsen/logrus"
@@ -24,34 +28,60 @@ const (
 )
 
 type EnvVars struct {
-	channelSecret         string
-	channelToken          string
+	lineChannels          *utils.LineChannels // 各官方帳號的 channel secret 與 token，依 webhook 的 destination 選擇
 	openaiBaseUrl         string
 	openaiApiKey          string
 	vocabularyTableName   string
 	userTableName         string
 	vocabularyFunctionArn string
 	schedulerRoleArn      string
+	auditTableName        string
+	exportBucketName      string
+	pairingTableName      string
+	analyticsTableName    string
+	dashboardURL          string
+	profileBaseURL        string
+	generationParams      map[utils.OpenAIFeature]utils.GenerationParams
+	openaiRetry           utils.RetryPolicy
+	mediaURLExpiry        map[utils.MediaKind]time.Duration
+	betaGate              *utils.BetaGate
+	errorBudget           utils.ErrorBudget
+	faultInjector         *utils.FaultInjector // nil 表示不注入錯誤
+	fakeOpenAI            bool                 // true 時以 FakeOpenaiClient 取代 OpenAI
+	pushMode              string               // utils.PushModeSchedule 或 utils.PushModeFanout
+	richMenuID            string               // 加入好友時綁定的圖文選單，空字串表示不綁定
+	recordExpiry          utils.RecordExpiry   // 單字紀錄與測驗、回顧 session 的保存期限
+	parseSampleRate       float64              // OpenAI 回應解析失敗時保存原始輸出的比例
+	upgradeURL            string               // 額度用完時提示升級的網址，空字串表示不提示
+	planQuotas            map[models.Plan]utils.PlanQuota
+	linePay               *utils.LinePayConfig // nil 表示未開放線上付款
+	eventStreamName       string               // 互動事件串流到資料倉儲的 Firehose，空字串表示不串流
+	anonymizer            *utils.Anonymizer    // nil 表示分析資料保留原本的 userId
+	translationRateLimit  int                  // 每位用戶每小時的翻譯次數上限，0 表示不限制
+	terms                 *utils.Terms         // nil 表示不要求同意服務條款
+	contentReportSuppress bool                 // true 時被回報的單字在審核前不會出現在每日單字
+	translationCacheTTL   time.Duration        // 常見單字翻譯的快取時間，0 表示不使用快取
 }
 
 func getEnvironmentVariables() (envVars *EnvVars, err error) {
-	channelSecret := os.Getenv("CHANNEL_SECRET")
-	if channelSecret == "" {
-		return nil, errors.New("CHANNEL_SECRET is not set")
+	lineChannels, err := utils.LoadLineChannels(os.Getenv)
+	if err != nil {
+		return nil, err
 	}
 
-	channelToken := os.Getenv("CHANNEL_TOKEN")
-	if channelToken == "" {
-		return nil, errors.New("CHANNEL_TOKEN is not set")
+	// OPENAI_FAKE 開啟時改用離線的 fake client，不需要 OpenAI 連線設定
+	fakeOpenAI, err := utils.LoadFakeOpenAI(os.Getenv)
+	if err != nil {
+		return nil, err
 	}
 
 	openaiBaseUrl := os.Getenv("OPENAI_BASE_URL")
-	if openaiBaseUrl == "" {
+	if openaiBaseUrl == "" && !fakeOpenAI {
 		return nil, errors.New("OPENAI_BASE_URL is not set")
 	}
 
 	openaiApiKey := os.Getenv("OPENAI_API_KEY")
-	if openaiApiKey == "" {
+	if openaiApiKey == "" && !fakeOpenAI {
 		return nil, errors.New("OPENAI_API_KEY is not set")
 	}
 
@@ -75,15 +105,145 @@ func getEnvironmentVariables() (envVars *EnvVars, err error) {
 		return nil, errors.New("SCHEDULER_ROLE_ARN is not set")
 	}
 
+	auditTableName := os.Getenv("AUDIT_TABLE_NAME")
+	if auditTableName == "" {
+		return nil, errors.New("AUDIT_TABLE_NAME is not set")
+	}
+
+	exportBucketName := os.Getenv("EXPORT_BUCKET_NAME")
+	if exportBucketName == "" {
+		return nil, errors.New("EXPORT_BUCKET_NAME is not set")
+	}
+
+	pairingTableName := os.Getenv("PAIRING_TABLE_NAME")
+	if pairingTableName == "" {
+		return nil, errors.New("PAIRING_TABLE_NAME is not set")
+	}
+
+	analyticsTableName := os.Getenv("ANALYTICS_TABLE_NAME")
+	if analyticsTableName == "" {
+		return nil, errors.New("ANALYTICS_TABLE_NAME is not set")
+	}
+
+	dashboardURL := os.Getenv("DASHBOARD_URL")
+	if dashboardURL == "" {
+		return nil, errors.New("DASHBOARD_URL is not set")
+	}
+
+	profileBaseURL := os.Getenv("PROFILE_BASE_URL")
+	if profileBaseURL == "" {
+		return nil, errors.New("PROFILE_BASE_URL is not set")
+	}
+
+	mediaURLExpiry, err := utils.LoadMediaURLExpiry(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	generationParams, err := utils.LoadGenerationParams(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	openaiRetry, err := utils.LoadOpenAIRetryPolicy(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	errorBudget, err := utils.LoadErrorBudget(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	faultInjector, err := utils.LoadFaultInjector(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	pushMode, err := utils.LoadPushMode(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	recordExpiry, err := utils.LoadRecordExpiry(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	parseFailureSampleRate, err := utils.LoadParseFailureSampleRate(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	planQuotas, err := utils.LoadPlanQuotas(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	anonymizer, err := utils.LoadAnonymizer(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	translationRateLimit, err := utils.LoadTranslationRateLimit(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	linePay, err := utils.LoadLinePayConfig(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	terms, err := utils.LoadTerms(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	contentReportSuppress, err := utils.LoadContentReportSuppress(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	translationCacheTTL, err := utils.LoadTranslationCacheTTL(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
 	return &EnvVars{
-		channelSecret:         channelSecret,
-		channelToken:          channelToken,
+		lineChannels:          lineChannels,
 		openaiBaseUrl:         openaiBaseUrl,
 		openaiApiKey:          openaiApiKey,
 		vocabularyTableName:   vocabularyTableName,
 		userTableName:         userTableName,
 		vocabularyFunctionArn: vocabularyFunctionArn,
 		schedulerRoleArn:      schedulerRoleArn,
+		auditTableName:        auditTableName,
+		exportBucketName:      exportBucketName,
+		pairingTableName:      pairingTableName,
+		analyticsTableName:    analyticsTableName,
+		dashboardURL:          dashboardURL,
+		profileBaseURL:        profileBaseURL,
+		generationParams:      generationParams,
+		openaiRetry:           openaiRetry,
+		mediaURLExpiry:        mediaURLExpiry,
+		betaGate:              utils.LoadBetaGate(os.Getenv),
+		errorBudget:           errorBudget,
+		faultInjector:         faultInjector,
+		fakeOpenAI:            fakeOpenAI,
+		pushMode:              pushMode,
+		richMenuID:            os.Getenv("RICH_MENU_ID"),
+		recordExpiry:          recordExpiry,
+		parseSampleRate:       parseFailureSampleRate,
+		planQuotas:            planQuotas,
+		upgradeURL:            os.Getenv("UPGRADE_URL"),
+		linePay:               linePay,
+		eventStreamName:       os.Getenv("EVENT_STREAM_NAME"),
+		anonymizer:            anonymizer,
+		translationRateLimit:  translationRateLimit,
+		terms:                 terms,
+		contentReportSuppress: contentReportSuppress,
+		translationCacheTTL:   translationCacheTTL,
 	}, nil
 }
 
@@ -102,35 +262,154 @@ func main() {
 		logger.WithError(err).Error("Failed to get environment variables")
 		panic(err)
 	}
+	if envVars.faultInjector != nil {
+		logger.WithField("faultInjection", os.Getenv("FAULT_INJECTION")).Warn("Fault injection enabled")
+	}
 
-	linebotClient, err := utils.NewLineBotClient(envVars.channelSecret, envVars.channelToken)
+	// create AWS clients
+	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
-		logger.WithError(err).Error("Failed to initialize LINE Bot")
 		panic(err)
 	}
+	dynamodbClient := utils.WithDynamoDBFaults(dynamodb.NewFromConfig(cfg), envVars.faultInjector)
 
-	openaiClient, err := utils.NewOpenAIClient(envVars.openaiApiKey, envVars.openaiBaseUrl)
-	if err != nil {
+	schemas := []utils.TableSchema{
+		utils.VocabularyTableSchema(envVars.vocabularyTableName),
+		utils.UserTableSchema(envVars.userTableName),
+		utils.ScheduleAuditTableSchema(envVars.auditTableName),
+		utils.PairingTableSchema(envVars.pairingTableName),
+		utils.AnalyticsTableSchema(envVars.analyticsTableName),
+	}
+	if err := utils.ValidateTableSchemas(dynamodbClient, schemas...); err != nil {
+		logger.WithError(err).Error("DynamoDB table schema mismatch")
 		panic(err)
 	}
 
-	// create AWS clients
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		panic(err)
+	// OpenAI 重試不超過本次 invoke 剩餘的時間
+	invocationDeadline := &utils.InvocationDeadline{}
+	parseFailureRecorder := utils.NewParseFailureRecorder(logger, repository.NewParseFailureRepository(logger, dynamodbClient, envVars.vocabularyTableName), envVars.parseSampleRate)
+	var openaiClient utils.OpenaiAPI
+	if envVars.fakeOpenAI {
+		logger.Warn("OPENAI_FAKE enabled, using the offline fake OpenAI client")
+		openaiClient = utils.NewFakeOpenAIClient()
+	} else {
+		openaiClient, err = utils.NewOpenAIClient(envVars.openaiApiKey, envVars.openaiBaseUrl, envVars.generationParams, envVars.openaiRetry, invocationDeadline, parseFailureRecorder)
+		if err != nil {
+			panic(err)
+		}
 	}
-	dynamodbClient := dynamodb.NewFromConfig(cfg)
+	openaiClient = utils.WithOpenAIFaults(openaiClient, envVars.faultInjector)
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Tables(schemas...).
+		OpenAI(envVars.generationParams, envVars.openaiRetry, envVars.fakeOpenAI).
+		ErrorBudget(envVars.errorBudget).
+		Feature("faultInjection", envVars.faultInjector != nil).
+		Feature("richMenu", envVars.richMenuID != "").
+		Secret("channelSecret", envVars.lineChannels.Default.Secret).
+		Secret("channelToken", envVars.lineChannels.Default.Token).
+		Setting("lineChannels", envVars.lineChannels.Destinations()).
+		Secret("openaiApiKey", envVars.openaiApiKey).
+		Setting("pushMode", envVars.pushMode).
+		Setting("scheduleGroup", utils.ScheduleGroupName).
+		Setting("betaFeatures", envVars.betaGate.Gated()).
+		Setting("exportBucket", envVars.exportBucketName).
+		Setting("recordRetention", envVars.recordExpiry.Retention.String()).
+		Setting("parseFailureSampleRate", envVars.parseSampleRate).
+		Setting("planQuotas", envVars.planQuotas).
+		Setting("translationRateLimitPerHour", envVars.translationRateLimit).
+		Feature("upgradePrompt", envVars.upgradeURL != "").
+		Feature("linePay", envVars.linePay != nil).
+		Setting("eventStream", envVars.eventStreamName).
+		Setting("analyticsSaltRotationDays", envVars.anonymizer.RotationDays()).
+		Feature("anonymizedAnalytics", envVars.anonymizer != nil).
+		Setting("terms", envVars.terms).
+		Feature("contentReportSuppress", envVars.contentReportSuppress).
+		Setting("translationCacheTTL", envVars.translationCacheTTL.String()).
+		Log(logger)
 	lambdaClient := lambdaService.NewFromConfig(cfg)
 	schedulerClient := schedulerService.NewFromConfig(cfg)
 
 	vocabularyRepo := repository.NewVocabularyRepository(logger, dynamodbClient, envVars.vocabularyTableName)
 	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
+	scheduleAuditRepo := repository.NewScheduleAuditRepository(logger, dynamodbClient, envVars.auditTableName)
+	pushLogRepo := repository.NewPushLogRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	media := utils.NewMediaService(utils.NewS3Client(cfg, envVars.exportBucketName), envVars.mediaURLExpiry)
+	pairingRepo := repository.NewPairingRepository(logger, dynamodbClient, envVars.pairingTableName)
+	promptRolloutRepo := repository.NewPromptRolloutRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	experimentRepo := repository.NewCardFormatExperimentRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	var eventStream *utils.EventStream
+	if envVars.eventStreamName != "" {
+		eventStream = utils.NewEventStream(logger, firehose.NewFromConfig(cfg), envVars.eventStreamName, envVars.anonymizer)
+	}
+	// 同意檢查要在去識別化之前，才能用原始 userId 讀取隱私設定
+	consents := utils.NewConsentChecker(logger, userConfigRepo, utils.ConsentCacheTTL)
+	interactionRepo := utils.ConsentedInteractions(utils.StreamInteractions(utils.AnonymizeInteractions(repository.NewInteractionRepository(logger, dynamodbClient, envVars.vocabularyTableName), envVars.anonymizer), eventStream), consents)
+	analyticsRepo := utils.ConsentedAnalytics(utils.AnonymizeAnalytics(repository.NewAnalyticsRepository(logger, dynamodbClient, envVars.analyticsTableName), envVars.anonymizer), consents)
+	supportTicketRepo := repository.NewSupportTicketRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	quizRepo := repository.NewQuizRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	reviewRepo := repository.NewReviewRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	deckRepo := repository.NewDeckRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	wordHistoryRepo := repository.NewWordHistoryRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	bloomFilterRepo := repository.NewBloomFilterRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	webhookEventRepo := repository.NewWebhookEventRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	streakRepo := repository.NewStreakRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	contentReportRepo := repository.NewContentReportRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	setupDraftRepo := repository.NewSetupDraftRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	failureReporter := utils.NewFailureReporter(logger, supportTicketRepo, envVars.errorBudget)
+	usageRepo := repository.NewUsageRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	usageLimiter := utils.NewUsageLimiter(usageRepo, envVars.planQuotas)
+	rateLimiter := utils.NewRateLimiter(repository.NewRateLimiterRepository(logger, dynamodbClient, envVars.vocabularyTableName), envVars.translationRateLimit)
+	tokenUsage := utils.NewTokenUsageRecorder(logger, usageRepo)
+	var translationCache *utils.TranslationCache
+	if envVars.translationCacheTTL > 0 {
+		translationCache = utils.NewTranslationCache(logger, repository.NewTranslationCacheRepository(logger, dynamodbClient, envVars.vocabularyTableName), envVars.translationCacheTTL)
+	}
+	shutdown := utils.NewShutdown(logger)
+
+	var payments *utils.PaymentService
+	if envVars.linePay != nil {
+		paymentRepo := repository.NewPaymentRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+		payments = utils.NewPaymentService(logger, utils.NewLinePayClient(*envVars.linePay), paymentRepo, userConfigRepo, *envVars.linePay)
+	}
+
+	killSwitches := utils.NewKillSwitchCache(logger, repository.NewKillSwitchRepository(logger, dynamodbClient, envVars.vocabularyTableName), utils.KillSwitchCacheTTL)
+	featureGate := utils.NewFeatureGate(repository.NewSubscriptionRepository(logger, dynamodbClient, envVars.vocabularyTableName), userConfigRepo)
 
-	handler, err := NewHandler(logger, envVars, linebotClient, openaiClient, vocabularyRepo, userConfigRepo, lambdaClient, schedulerClient)
+	// 每個官方帳號各自一個 LINE client 與 handler，其餘依賴共用
+	lineClients, err := utils.NewLineClients(envVars.lineChannels, func(client utils.LinebotAPI) utils.LinebotAPI {
+		return utils.WithLinebotFaults(client, envVars.faultInjector)
+	})
 	if err != nil {
-		logger.WithError(err).Error("Failed to create handler")
+		logger.WithError(err).Error("Failed to initialize LINE Bot")
 		panic(err)
 	}
+	newChannelHandler := func(destination string, linebotClient utils.LinebotAPI) *Handler {
+		handler, err := NewHandler(logger, envVars, linebotClient, destination, openaiClient, vocabularyRepo, userConfigRepo, scheduleAuditRepo, pushLogRepo, media, pairingRepo, promptRolloutRepo, experimentRepo, interactionRepo, analyticsRepo, quizRepo, reviewRepo, deckRepo, wordHistoryRepo, bloomFilterRepo, webhookEventRepo, streakRepo, contentReportRepo, setupDraftRepo, failureReporter, usageLimiter, rateLimiter, translationCache, tokenUsage, payments, featureGate, killSwitches, consents, lambdaClient, schedulerClient, shutdown)
+		if err != nil {
+			logger.WithError(err).Error("Failed to create handler")
+			panic(err)
+		}
+		return handler
+	}
+	defaultHandler := newChannelHandler("", lineClients.Default)
+	channelHandlers := map[string]*Handler{}
+	for destination, linebotClient := range lineClients.ByDestination {
+		channelHandlers[destination] = newChannelHandler(destination, linebotClient)
+	}
+
+	lambda.StartWithOptions(func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		invocationDeadline.Set(ctx)
+		// 回覆後 container 會被凍結，先等背景工作（例如首次推播）完成再回傳
+		defer shutdown.Flush(ctx)
 
-	lambda.Start(handler.EventHandler)
+		// 依收到事件的官方帳號選擇 handler，未設定的帳號使用預設 channel，簽章不符時由 handler 回傳 400
+		handler := defaultHandler
+		if destination, err := utils.WebhookDestination(request.Body); err == nil {
+			if channelHandler, ok := channelHandlers[destination]; ok {
+				handler = channelHandler
+			}
+		}
+		return handler.EventHandler(request)
+	}, lambda.WithEnableSIGTERM(shutdown.OnSIGTERM))
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/migrations"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MigrationEvent 手動 invoke 時的 payload，例如：
+//
+//	aws lambda invoke --function-name language-migration --payload '{"migration":"20250601-per-word-items","dryRun":true}' out.json
+//
+// verify 為 true 時只掃描並回傳 migrations.VerifyResult（剩餘待轉換的 item 數與各項計數），不寫入資料
+type MigrationEvent struct {
+	Migration string `json:"migration"`
+	DryRun    bool   `json:"dryRun"`
+	Verify    bool   `json:"verify"`
+	Reset     bool   `json:"reset"`
+	PageSize  int32  `json:"pageSize"`
+	MaxPages  int    `json:"maxPages"`
+}
+
+// Handler runs data migrations. Holds no mutable state; concurrent runs of the same migration
+// would race on its checkpoint, so the function is deployed with reserved concurrency 1
+type Handler struct {
+	logger  *logrus.Entry
+	envVars *EnvVars
+	runner  *migrations.Runner
+}
+
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, runner *migrations.Runner) (*Handler, error) {
+	return &Handler{
+		logger:  logger,
+		envVars: envVars,
+		runner:  runner,
+	}, nil
+}
+
+// EventHandler 執行一次 migration，接近 Lambda timeout 時會停在 checkpoint；
+// 回傳狀態為 running 時以同樣的 payload 再 invoke 一次即可繼續。verify 時回傳 *migrations.VerifyResult
+func (h *Handler) EventHandler(ctx context.Context, event MigrationEvent) (interface{}, error) {
+	if event.Migration == "" {
+		return nil, errors.New("migration is required")
+	}
+
+	migration, err := migrations.Get(event.Migration)
+	if err != nil {
+		h.logger.WithError(err).WithField("migrationId", event.Migration).Error("Unknown migration")
+		return nil, err
+	}
+
+	if event.Verify {
+		return h.runner.Verify(ctx, migration, event.PageSize)
+	}
+
+	return h.runner.Run(ctx, migration, migrations.Options{
+		DryRun:   event.DryRun,
+		Reset:    event.Reset,
+		PageSize: event.PageSize,
+		MaxPages: event.MaxPages,
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/migrations"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SEVERITY    = "severity"
+	MESSAGE     = "message"
+	TIMESTAMP   = "timestamp"
+	COMPONENT   = "component"
+	SERVICENAME = "language-migration"
+)
+
+type EnvVars struct {
+	vocabularyTableName string
+	tables              map[string]string
+}
+
+func getEnvironmentVariables() (envVars *EnvVars, err error) {
+	// checkpoint 存放在 vocabulary table
+	vocabularyTableName := os.Getenv("VOCABULARY_TABLE_NAME")
+	if vocabularyTableName == "" {
+		return nil, errors.New("VOCABULARY_TABLE_NAME is not set")
+	}
+
+	return &EnvVars{
+		vocabularyTableName: vocabularyTableName,
+		tables:              migrations.TablesFromEnv(os.Getenv),
+	}, nil
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  TIMESTAMP,
+			logrus.FieldKeyLevel: SEVERITY,
+			logrus.FieldKeyMsg:   MESSAGE,
+		},
+	})
+	logger := logrus.WithField(COMPONENT, SERVICENAME)
+
+	envVars, err := getEnvironmentVariables()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get environment variables")
+		panic(err)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		panic(err)
+	}
+	dynamodbClient := dynamodb.NewFromConfig(cfg)
+
+	checkpointRepo := repository.NewMigrationRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	runner := migrations.NewRunner(logger, dynamodbClient, checkpointRepo, envVars.tables)
+
+	report := utils.NewStartupReport(SERVICENAME, os.Getenv).Table("vocabulary", envVars.vocabularyTableName)
+	for role, tableName := range envVars.tables {
+		report.Table(role, tableName)
+	}
+	report.Log(logger)
+
+	handler, err := NewHandler(logger, envVars, runner)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create handler")
+		panic(err)
+	}
+
+	lambda.Start(handler.EventHandler)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"html/template"
+	"language-assistant/internal/messages"
+	"language-assistant/internal/utils"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed result.html
+var resultHTML string
+
+var resultTemplate = template.Must(template.New("result").Parse(resultHTML))
+
+// Handler receives the LINE Pay redirects after the user approves or cancels a supporter payment
+type Handler struct {
+	logger         *logrus.Entry
+	payments       *utils.PaymentService
+	userConfigRepo utils.UserConfigRepository
+	lineClients    *utils.LineClients // 依用戶加入的官方帳號選擇推播的 channel
+}
+
+func NewHandler(logger *logrus.Entry, payments *utils.PaymentService, userConfigRepo utils.UserConfigRepository, lineClients *utils.LineClients) (*Handler, error) {
+	return &Handler{
+		logger:         logger,
+		payments:       payments,
+		userConfigRepo: userConfigRepo,
+		lineClients:    lineClients,
+	}, nil
+}
+
+type resultPage struct {
+	Title   string
+	Message string
+	OrderID string
+}
+
+func (h *Handler) EventHandler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	switch request.Resource {
+	case utils.LinePayConfirmPath:
+		return h.handleConfirm(request.QueryStringParameters["orderId"], request.QueryStringParameters["transactionId"]), nil
+	case utils.LinePayCancelPath:
+		return h.handleCancel(request.QueryStringParameters["orderId"]), nil
+	default:
+		return h.render(http.StatusNotFound, resultPage{Title: "找不到頁面", Message: "請回到 LINE 重新操作。"}), nil
+	}
+}
+
+// handleConfirm 用戶在 LINE Pay 授權付款後導回這裡，確認扣款並升級方案；只有實際完成確認的那次會推播通知
+func (h *Handler) handleConfirm(orderID, transactionID string) events.APIGatewayProxyResponse {
+	logger := h.logger.WithField("orderId", orderID)
+	if orderID == "" || transactionID == "" {
+		return h.render(http.StatusBadRequest, resultPage{Title: "付款資訊不完整", Message: "請回到 LINE 輸入 /支持 重新付款。"})
+	}
+
+	payment, confirmed, err := h.payments.ConfirmPayment(orderID, transactionID)
+	if errors.Is(err, utils.ErrPaymentNotFound) {
+		logger.Warn("Confirm redirect for unknown payment")
+		return h.render(http.StatusNotFound, resultPage{Title: "找不到這筆付款", Message: "請回到 LINE 輸入 /支持 重新付款。"})
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to confirm payment")
+		return h.render(http.StatusBadGateway, resultPage{
+			Title:   "付款確認失敗",
+			Message: "若已被扣款，請提供訂單編號與我們聯繫，我們會盡快為你處理。",
+			OrderID: orderID,
+		})
+	}
+
+	if confirmed {
+		userConfig, err := h.userConfigRepo.GetUserConfig(payment.UserID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to get user config, pushing from the default channel")
+		}
+		if err := h.lineClients.ClientFor(userConfig).PushMessage(payment.UserID, messages.Text(messages.SupporterPaymentConfirmed)); err != nil {
+			logger.WithError(err).Warn("Failed to push payment confirmation")
+		}
+	}
+	return h.render(http.StatusOK, resultPage{
+		Title:   "🎉 付款完成",
+		Message: "已為你升級支持者方案，感謝你的支持！現在可以回到 LINE 繼續使用。",
+		OrderID: orderID,
+	})
+}
+
+// handleCancel 用戶在 LINE Pay 取消付款
+func (h *Handler) handleCancel(orderID string) events.APIGatewayProxyResponse {
+	if orderID != "" {
+		if err := h.payments.CancelPayment(orderID); err != nil && !errors.Is(err, utils.ErrPaymentNotFound) {
+			h.logger.WithError(err).WithField("orderId", orderID).Error("Failed to cancel payment")
+		}
+	}
+	return h.render(http.StatusOK, resultPage{Title: "已取消付款", Message: "沒有任何扣款。想升級時，隨時在 LINE 輸入 /支持 即可。"})
+}
+
+func (h *Handler) render(statusCode int, page resultPage) events.APIGatewayProxyResponse {
+	var buf bytes.Buffer
+	if err := resultTemplate.Execute(&buf, page); err != nil {
+		h.logger.WithError(err).Error("Failed to render payment result")
+		return htmlResponse(http.StatusInternalServerError, "Internal Server Error")
+	}
+	return htmlResponse(statusCode, buf.String())
+}
+
+func htmlResponse(statusCode int, body string) events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers: map[string]string{
+			"Content-Type":  "text/html; charset=utf-8",
+			"Cache-Control": "no-store",
+		},
+		Body: body,
+	}
+}
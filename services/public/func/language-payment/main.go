@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SEVERITY    = "severity"
+	MESSAGE     = "message"
+	TIMESTAMP   = "timestamp"
+	COMPONENT   = "component"
+	SERVICENAME = "language-payment"
+)
+
+type EnvVars struct {
+	lineChannels        *utils.LineChannels // 各官方帳號的 channel，付款通知由用戶加入的帳號推播
+	userTableName       string
+	vocabularyTableName string
+	linePay             utils.LinePayConfig
+}
+
+func getEnvironmentVariables() (envVars *EnvVars, err error) {
+	lineChannels, err := utils.LoadLineChannels(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	userTableName := os.Getenv("USER_TABLE_NAME")
+	if userTableName == "" {
+		return nil, errors.New("USER_TABLE_NAME is not set")
+	}
+
+	vocabularyTableName := os.Getenv("VOCABULARY_TABLE_NAME")
+	if vocabularyTableName == "" {
+		return nil, errors.New("VOCABULARY_TABLE_NAME is not set")
+	}
+
+	linePay, err := utils.LoadLinePayConfig(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	if linePay == nil {
+		return nil, errors.New("LINEPAY_CHANNEL_ID is not set")
+	}
+
+	return &EnvVars{
+		lineChannels:        lineChannels,
+		userTableName:       userTableName,
+		vocabularyTableName: vocabularyTableName,
+		linePay:             *linePay,
+	}, nil
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  TIMESTAMP,
+			logrus.FieldKeyLevel: SEVERITY,
+			logrus.FieldKeyMsg:   MESSAGE,
+		},
+	})
+	logger := logrus.WithField(COMPONENT, SERVICENAME)
+
+	envVars, err := getEnvironmentVariables()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get environment variables")
+		panic(err)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		panic(err)
+	}
+	dynamodbClient := dynamodb.NewFromConfig(cfg)
+
+	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
+	paymentRepo := repository.NewPaymentRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	payments := utils.NewPaymentService(logger, utils.NewLinePayClient(envVars.linePay), paymentRepo, userConfigRepo, envVars.linePay)
+
+	lineClients, err := utils.NewLineClients(envVars.lineChannels, nil)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create LINE Bot client")
+		panic(err)
+	}
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Table("user", envVars.userTableName).
+		Table("vocabulary", envVars.vocabularyTableName).
+		Secret("channelSecret", envVars.lineChannels.Default.Secret).
+		Secret("channelToken", envVars.lineChannels.Default.Token).
+		Setting("lineChannels", envVars.lineChannels.Destinations()).
+		Secret("linePayChannelSecret", envVars.linePay.ChannelSecret).
+		Setting("linePayAPI", envVars.linePay.APIURL).
+		Setting("supporterPrice", envVars.linePay.Price).
+		Setting("supporterCurrency", envVars.linePay.Currency).
+		Log(logger)
+
+	handler, err := NewHandler(logger, payments, userConfigRepo, lineClients)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create handler")
+		panic(err)
+	}
+
+	lambda.Start(handler.EventHandler)
+}
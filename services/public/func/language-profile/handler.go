@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed profile.html
+var profileHTML string
+
+var profileTemplate = template.Must(template.New("profile").Parse(profileHTML))
+
+// 同一個 Lambda instance 內的渲染快取，搭配 Cache-Control 讓瀏覽器/CDN 也能快取
+const profileCacheTTL = 5 * time.Minute
+
+type cachedProfile struct {
+	body      string
+	expiresAt time.Time
+}
+
+// Handler serves public profile pages. Safe for concurrent use: the profile cache is guarded by mu
+type Handler struct {
+	logger         *logrus.Entry
+	envVars        *EnvVars
+	userConfigRepo utils.UserConfigRepository
+	vocabularyRepo utils.VocabularyRepository
+
+	mu    sync.Mutex
+	cache map[string]cachedProfile
+}
+
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, userConfigRepo utils.UserConfigRepository, vocabularyRepo utils.VocabularyRepository) (*Handler, error) {
+	return &Handler{
+		logger:         logger,
+		envVars:        envVars,
+		userConfigRepo: userConfigRepo,
+		vocabularyRepo: vocabularyRepo,
+		cache:          map[string]cachedProfile{},
+	}, nil
+}
+
+type profilePage struct {
+	Name        string
+	Stats       models.LearningStats
+	GeneratedAt string
+}
+
+// EventHandler 回傳公開個人頁面，只顯示統計數字與徽章，不包含任何單字內容
+func (h *Handler) EventHandler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	slug := request.PathParameters["slug"]
+	if slug == "" {
+		return htmlResponse(http.StatusNotFound, "Not Found", false), nil
+	}
+
+	if body, ok := h.getCached(slug); ok {
+		return htmlResponse(http.StatusOK, body, true), nil
+	}
+
+	userConfig, err := h.userConfigRepo.GetUserConfigByProfileSlug(slug)
+	if err != nil {
+		return htmlResponse(http.StatusInternalServerError, "Internal Server Error", false), nil
+	}
+	if userConfig == nil {
+		return htmlResponse(http.StatusNotFound, "Not Found", false), nil
+	}
+
+	vocabularies, err := h.vocabularyRepo.GetAllUserVocabularies(userConfig.UserID)
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", userConfig.UserID).Error("Failed to get vocabularies for public profile")
+		return htmlResponse(http.StatusInternalServerError, "Internal Server Error", false), nil
+	}
+
+	now := time.Now().UTC()
+	page := profilePage{
+		Name:        userConfig.DisplayName,
+		Stats:       utils.CalculateLearningStats(vocabularies, now),
+		GeneratedAt: now.Format("2006-01-02 15:04"),
+	}
+	if page.Name == "" {
+		page.Name = "匿名學習者"
+	}
+
+	var buf bytes.Buffer
+	if err := profileTemplate.Execute(&buf, page); err != nil {
+		h.logger.WithError(err).Error("Failed to render public profile")
+		return htmlResponse(http.StatusInternalServerError, "Internal Server Error", false), nil
+	}
+
+	h.setCached(slug, buf.String())
+	return htmlResponse(http.StatusOK, buf.String(), true), nil
+}
+
+func (h *Handler) getCached(slug string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entry, ok := h.cache[slug]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(h.cache, slug)
+		return "", false
+	}
+	return entry.body, true
+}
+
+func (h *Handler) setCached(slug, body string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cache[slug] = cachedProfile{body: body, expiresAt: time.Now().Add(profileCacheTTL)}
+}
+
+func htmlResponse(statusCode int, body string, cacheable bool) events.APIGatewayProxyResponse {
+	headers := map[string]string{"Content-Type": "text/html; charset=utf-8"}
+	if cacheable {
+		headers["Cache-Control"] = "public, max-age=300"
+	} else {
+		headers["Cache-Control"] = "no-store"
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       body,
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SEVERITY    = "severity"
+	MESSAGE     = "message"
+	TIMESTAMP   = "timestamp"
+	COMPONENT   = "component"
+	SERVICENAME = "language-profile"
+)
+
+type EnvVars struct {
+	userTableName       string
+	vocabularyTableName string
+}
+
+func getEnvironmentVariables() (envVars *EnvVars, err error) {
+	userTableName := os.Getenv("USER_TABLE_NAME")
+	if userTableName == "" {
+		return nil, errors.New("USER_TABLE_NAME is not set")
+	}
+
+	vocabularyTableName := os.Getenv("VOCABULARY_TABLE_NAME")
+	if vocabularyTableName == "" {
+		return nil, errors.New("VOCABULARY_TABLE_NAME is not set")
+	}
+
+	return &EnvVars{
+		userTableName:       userTableName,
+		vocabularyTableName: vocabularyTableName,
+	}, nil
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  TIMESTAMP,
+			logrus.FieldKeyLevel: SEVERITY,
+			logrus.FieldKeyMsg:   MESSAGE,
+		},
+	})
+	logger := logrus.WithField(COMPONENT, SERVICENAME)
+
+	envVars, err := getEnvironmentVariables()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get environment variables")
+		panic(err)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		panic(err)
+	}
+	dynamodbClient := dynamodb.NewFromConfig(cfg)
+
+	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
+	vocabularyRepo := repository.NewVocabularyRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Table("user", envVars.userTableName).
+		Table("vocabulary", envVars.vocabularyTableName).
+		Log(logger)
+
+	handler, err := NewHandler(logger, envVars, userConfigRepo, vocabularyRepo)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create handler")
+		panic(err)
+	}
+
+	lambda.Start(handler.EventHandler)
+}
@@ -4,26 +4,41 @@ import (
 	"context"
 	"time"
 
+	"language-assistant/internal/messages"
 	"language-assistant/internal/models"
 	"language-assistant/internal/utils"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/line/line-bot-sdk-go/v7/linebot"
 	"github.com/sirupsen/logrus"
 )
 
+// Handler sends reminders. Holds no mutable state; safe for concurrent use
 type Handler struct {
-	logger        *logrus.Entry
-	envVars       *EnvVars
-	reminderRepo  utils.ReminderRepository
-	linebotClient utils.LinebotAPI
+	logger         *logrus.Entry
+	envVars        *EnvVars
+	reminderRepo   utils.ReminderRepository
+	streakRepo     utils.StreakRepository
+	userConfigRepo utils.UserConfigRepository
+	pushLogRepo    utils.PushLogRepository
+	quizRepo       utils.QuizRepository
+	reviewRepo     utils.ReviewRepository
+	featureGate    *utils.FeatureGate
+	lineClients    *utils.LineClients // 依用戶加入的官方帳號選擇推播的 channel
 }
 
-func NewHandler(logger *logrus.Entry, envVars *EnvVars, reminderRepo utils.ReminderRepository, linebotClient utils.LinebotAPI) (*Handler, error) {
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, reminderRepo utils.ReminderRepository, streakRepo utils.StreakRepository, userConfigRepo utils.UserConfigRepository, pushLogRepo utils.PushLogRepository, quizRepo utils.QuizRepository, reviewRepo utils.ReviewRepository, featureGate *utils.FeatureGate, lineClients *utils.LineClients) (*Handler, error) {
 	return &Handler{
-		logger:        logger,
-		envVars:       envVars,
-		reminderRepo:  reminderRepo,
-		linebotClient: linebotClient,
+		logger:         logger,
+		envVars:        envVars,
+		reminderRepo:   reminderRepo,
+		streakRepo:     streakRepo,
+		userConfigRepo: userConfigRepo,
+		pushLogRepo:    pushLogRepo,
+		quizRepo:       quizRepo,
+		reviewRepo:     reviewRepo,
+		featureGate:    featureGate,
+		lineClients:    lineClients,
 	}, nil
 }
 
@@ -34,6 +49,9 @@ func (h *Handler) EventHandler(ctx context.Context, event events.CloudWatchEvent
 		"eventTime":  event.Time,
 	}).Info("Daily reminder cron job triggered")
 
+	h.sendStreakMilestones()
+	h.processTrials()
+
 	date := time.Now().Format("2006-01-02")
 	userVocaList, err := h.reminderRepo.GetUserVocabulariesByDate(date)
 	if err != nil {
@@ -47,18 +65,205 @@ func (h *Handler) EventHandler(ctx context.Context, event events.CloudWatchEvent
 		return nil
 	}
 
-	for index, dailyUserData := range userVocaList {
-		h.logger.WithFields(logrus.Fields{
-			"userIndex": index,
-			"userID":    dailyUserData.UserID,
-			"wordCount": len(dailyUserData.Words),
-		}).Info("Sending daily reminder to user")
+	// 逐一推播在用戶多時會超過 Lambda timeout，改為有上限的平行推播；一個用戶失敗不影響其他用戶
+	summary := utils.PushConcurrently(userVocaList, h.envVars.concurrency, reminderTarget, func(dailyUserData models.UserVocabulary) (bool, error) {
+		return h.remind(dailyUserData, date)
+	})
+	h.logger.WithFields(summary.Fields()).WithField("date", date).Info("Daily reminder push finished")
+	return nil
+}
 
-		messageText := models.FormatWordRecords(dailyUserData.Words)
-		if err := h.linebotClient.PushMessage(dailyUserData.UserID, messageText); err != nil {
-			h.logger.WithError(err).WithField("userID", dailyUserData.UserID).Error("Failed to send reminder message")
-			continue // 繼續處理其他用戶，不要因為一個用戶失敗就中斷整個流程
+// reminderTarget 回顧推播的對象：群組的單字庫推播到群組，其他推播給用戶
+func reminderTarget(vocabulary models.UserVocabulary) string {
+	if vocabulary.GroupID != "" {
+		return vocabulary.GroupID
+	}
+	return vocabulary.UserID
+}
+
+// remind 推播一位用戶（或一個群組）當天的回顧，回傳是否有推播；沒有要回顧的單字時略過
+func (h *Handler) remind(dailyUserData models.UserVocabulary, date string) (bool, error) {
+	h.logger.WithFields(logrus.Fields{
+		"userID":    dailyUserData.UserID,
+		"wordCount": len(dailyUserData.Words),
+	}).Info("Sending daily reminder to user")
+
+	// 群組的單字庫改推播群組回顧，不套用個人的回顧設定
+	if dailyUserData.GroupID != "" {
+		sent, err := h.sendGroupRecap(dailyUserData)
+		if err != nil {
+			h.logger.WithError(err).WithField("groupID", dailyUserData.GroupID).Error("Failed to send group recap")
+		}
+		return sent, err
+	}
+
+	userConfig, err := h.userConfigRepo.GetUserConfig(dailyUserData.UserID)
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", dailyUserData.UserID).Warn("Failed to get user config, reviewing all sources")
+	}
+
+	words := h.reviewWords(dailyUserData, userConfig, date)
+	if len(words) == 0 {
+		h.logger.WithField("userID", dailyUserData.UserID).Info("No new words to review today, skipping reminder")
+		return false, nil
+	}
+
+	linebotClient := h.lineClients.ClientFor(userConfig)
+	if userConfig != nil && userConfig.InteractiveReview {
+		if err := h.sendInteractiveReview(linebotClient, dailyUserData.UserID, date, words); err != nil {
+			h.logger.WithError(err).WithField("userID", dailyUserData.UserID).Error("Failed to send interactive review")
+			return false, err
+		}
+		return true, nil
+	}
+
+	texts := models.FormatWordRecords(words)
+	if err := utils.PushInBatches(linebotClient, dailyUserData.UserID, messages.TextMessages(texts)); err != nil {
+		h.logger.WithError(err).WithField("userID", dailyUserData.UserID).Error("Failed to send reminder message")
+		return false, err
+	}
+	return true, nil
+}
+
+// reviewWords 挑出當天要回顧的單字：依用戶的回顧來源設定（/複習來源）過濾，並略過當天早上已推播過與測驗中已熟練的單字，
+// 再依互動回顧的紀錄把該複習與常忘記的單字排在前面。userConfig 為 nil 時（讀取失敗）回顧所有來源。
+// 讀取紀錄失敗時只記 log，改用較寬鬆的條件，不影響回顧推播
+func (h *Handler) reviewWords(vocabulary models.UserVocabulary, userConfig *models.UserConfig, date string) []models.WordRecord {
+	logger := h.logger.WithField("userID", vocabulary.UserID)
+	words := vocabulary.Words
+	if userConfig != nil {
+		words = models.FilterWordsBySource(words, userConfig.ReviewSource)
+	}
+
+	excluded := map[string]bool{}
+	logs, err := h.pushLogRepo.GetPushLogsByDate(vocabulary.UserID, date)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get push logs, not excluding pushed words")
+	}
+	for word := range utils.PushedWordsOn(logs) {
+		excluded[word] = true
+	}
+
+	mastered, err := h.quizRepo.GetMasteredWords(vocabulary.UserID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get mastered words, not excluding mastered words")
+	}
+	for word := range mastered {
+		excluded[word] = true
+	}
+	words = utils.ReminderWords(words, excluded)
+
+	records, err := h.reviewRepo.GetReviewRecords(vocabulary.UserID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get review records, keeping saved order")
+		return words
+	}
+	return utils.PrioritizeReviewWords(words, records, date)
+}
+
+// sendGroupRecap 推播群組當天一起查過的單字，並點名查最多單字的成員；回傳是否有推播。
+// 群組沒有記錄加入的官方帳號，一律由預設 channel 推播
+func (h *Handler) sendGroupRecap(vocabulary models.UserVocabulary) (bool, error) {
+	words := utils.ReminderWords(vocabulary.Words, nil)
+	if len(words) == 0 {
+		return false, nil
+	}
+	linebotClient := h.lineClients.Default
+
+	data := messages.Data{"Count": len(words)}
+	if contributors := utils.RankGroupContributors([]models.UserVocabulary{vocabulary}); len(contributors) > 0 {
+		data["TopName"] = utils.GroupMemberName(linebotClient, vocabulary.GroupID, contributors[0].UserID)
+		data["TopWords"] = contributors[0].Words
+	}
+
+	texts := append([]string{messages.Render(messages.GroupRecapHeader, data)}, models.FormatWordRecords(words)...)
+	if err := utils.PushInBatches(linebotClient, vocabulary.GroupID, messages.TextMessages(texts)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sendInteractiveReview 建立當天的互動回顧並推播第一個單字，之後每個單字由 language-handler 在用戶回答後回覆
+func (h *Handler) sendInteractiveReview(linebotClient utils.LinebotAPI, userID, date string, words []models.WordRecord) error {
+	session := utils.NewReviewSession(userID, date, words)
+	session.ExpiresAt = h.envVars.recordExpiry.ExpiresAtUnix(time.Now())
+	if err := h.reviewRepo.SaveReviewSession(session); err != nil {
+		return err
+	}
+
+	intro := messages.Render(messages.InteractiveReviewIntro, messages.Data{"Total": len(session.Items)})
+	return linebotClient.PushMessages(userID, linebot.NewTextMessage(intro), utils.ReviewCardMessage(&session))
+}
+
+// processTrials 提醒即將到期的試用，並將到期的試用降級後通知用戶；失敗的試用保持 active，下次排程再處理
+func (h *Handler) processTrials() {
+	expired, ending, err := h.featureGate.DueTrials()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get due trials")
+		return
+	}
+
+	for _, trial := range ending {
+		logger := h.logger.WithFields(logrus.Fields{"userID": trial.UserID, "feature": trial.Feature})
+		userConfig := h.userConfig(trial.UserID)
+		timezone := ""
+		if userConfig != nil {
+			timezone = userConfig.Timezone
+		}
+		message := messages.Render(messages.TrialEndingSoon, messages.Data{
+			"ExpiresOn": utils.TrialExpiryDate(trial, timezone),
+			"CanPay":    h.envVars.canPay,
+		})
+		if err := h.lineClients.ClientFor(userConfig).PushMessage(trial.UserID, message); err != nil {
+			logger.WithError(err).Error("Failed to send trial ending message")
+			continue
+		}
+		if err := h.featureGate.MarkEndingNotified(trial); err != nil {
+			logger.WithError(err).Warn("Failed to mark trial ending notified")
+		}
+	}
+
+	for _, trial := range expired {
+		logger := h.logger.WithFields(logrus.Fields{"userID": trial.UserID, "feature": trial.Feature})
+		notify, err := h.featureGate.ExpireTrial(trial)
+		if err != nil {
+			logger.WithError(err).Error("Failed to expire trial")
+		}
+		if !notify {
+			continue
+		}
+		if err := h.lineClients.ClientFor(h.userConfig(trial.UserID)).PushMessage(trial.UserID, messages.Render(messages.TrialExpired, messages.Data{"CanPay": h.envVars.canPay})); err != nil {
+			logger.WithError(err).Error("Failed to send trial expired message")
+		}
+	}
+}
+
+// userConfig 讀取用戶設定供選擇推播 channel 與時區，讀取失敗時回傳 nil（預設 channel，時間以 UTC 顯示）
+func (h *Handler) userConfig(userID string) *models.UserConfig {
+	userConfig, err := h.userConfigRepo.GetUserConfig(userID)
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to get user config")
+		return nil
+	}
+	return userConfig
+}
+
+// sendStreakMilestones 推播連續學習里程碑的恭喜訊息；推播失敗的保留到下次再試
+func (h *Handler) sendStreakMilestones() {
+	milestones, err := h.streakRepo.GetPendingMilestones()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get streak milestones")
+		return
+	}
+
+	for _, milestone := range milestones {
+		message := messages.Render(messages.StreakMilestone, messages.Data{"Days": milestone.Days})
+		if err := h.lineClients.ClientFor(h.userConfig(milestone.UserID)).PushMessage(milestone.UserID, message); err != nil {
+			h.logger.WithError(err).WithField("userID", milestone.UserID).Error("Failed to send streak milestone message")
+			continue
+		}
+		if err := h.streakRepo.DeletePendingMilestone(milestone.UserID); err != nil {
+			h.logger.WithError(err).WithField("userID", milestone.UserID).Warn("Failed to delete streak milestone")
 		}
 	}
-	return nil
 }
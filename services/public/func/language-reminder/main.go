@@ -23,6 +23,11 @@ const (
 
 type EnvVars struct {
 	vocabularyTableName string
+	userTableName       string
+	faultInjector       *utils.FaultInjector // nil 表示不注入錯誤
+	recordExpiry        utils.RecordExpiry   // 互動回顧 session 的保存期限
+	canPay              bool                 // 是否開放 LINE Pay 線上付款，試用到期通知是否提示 /支持
+	concurrency         int                  // 每晚回顧同時推播的用戶數
 }
 
 func getEnvironmentVariables() (envVars *EnvVars, err error) {
@@ -31,8 +36,33 @@ func getEnvironmentVariables() (envVars *EnvVars, err error) {
 		return nil, errors.New("VOCABULARY_TABLE_NAME is not set")
 	}
 
+	userTableName := os.Getenv("USER_TABLE_NAME")
+	if userTableName == "" {
+		return nil, errors.New("USER_TABLE_NAME is not set")
+	}
+
+	faultInjector, err := utils.LoadFaultInjector(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	recordExpiry, err := utils.LoadRecordExpiry(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency, err := utils.LoadReminderConcurrency(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
 	return &EnvVars{
 		vocabularyTableName: vocabularyTableName,
+		userTableName:       userTableName,
+		faultInjector:       faultInjector,
+		recordExpiry:        recordExpiry,
+		canPay:              os.Getenv("LINEPAY_CHANNEL_ID") != "",
+		concurrency:         concurrency,
 	}, nil
 }
 
@@ -51,33 +81,56 @@ func main() {
 		logger.WithError(err).Error("Failed to get environment variables")
 		panic(err)
 	}
+	if envVars.faultInjector != nil {
+		logger.WithField("faultInjection", os.Getenv("FAULT_INJECTION")).Warn("Fault injection enabled")
+	}
 
 	cfg, err := config.LoadDefaultConfig(context.TODO())
 	if err != nil {
 		panic(err)
 	}
-	dynamodbClient := dynamodb.NewFromConfig(cfg)
+	dynamodbClient := utils.WithDynamoDBFaults(dynamodb.NewFromConfig(cfg), envVars.faultInjector)
+
+	schemas := []utils.TableSchema{
+		utils.VocabularyTableSchema(envVars.vocabularyTableName),
+		utils.UserTableSchema(envVars.userTableName),
+	}
+	if err := utils.ValidateTableSchemas(dynamodbClient, schemas...); err != nil {
+		logger.WithError(err).Error("DynamoDB table schema mismatch")
+		panic(err)
+	}
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Tables(schemas...).
+		Feature("faultInjection", envVars.faultInjector != nil).
+		Setting("maxInteractiveReviewWords", utils.MaxInteractiveReviewWords).
+		Feature("linePay", envVars.canPay).
+		Setting("concurrency", envVars.concurrency).
+		Log(logger)
 
 	reminderRepo := repository.NewReminderRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	streakRepo := repository.NewStreakRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
+	pushLogRepo := repository.NewPushLogRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	quizRepo := repository.NewQuizRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	reviewRepo := repository.NewReviewRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	featureGate := utils.NewFeatureGate(repository.NewSubscriptionRepository(logger, dynamodbClient, envVars.vocabularyTableName), userConfigRepo)
 
 	// Get environment variables for LINE Bot
-	channelSecret := os.Getenv("CHANNEL_SECRET")
-	if channelSecret == "" {
-		panic(errors.New("CHANNEL_SECRET is not set"))
-	}
-
-	channelToken := os.Getenv("CHANNEL_TOKEN")
-	if channelToken == "" {
-		panic(errors.New("CHANNEL_TOKEN is not set"))
+	lineChannels, err := utils.LoadLineChannels(os.Getenv)
+	if err != nil {
+		panic(err)
 	}
 
-	linebotClient, err := utils.NewLineBotClient(channelSecret, channelToken)
+	lineClients, err := utils.NewLineClients(lineChannels, func(client utils.LinebotAPI) utils.LinebotAPI {
+		return utils.WithLinebotFaults(client, envVars.faultInjector)
+	})
 	if err != nil {
 		logger.WithError(err).Error("Failed to create LINE Bot client")
 		panic(err)
 	}
 
-	handler, err := NewHandler(logger, envVars, reminderRepo, linebotClient)
+	handler, err := NewHandler(logger, envVars, reminderRepo, streakRepo, userConfigRepo, pushLogRepo, quizRepo, reviewRepo, featureGate, lineClients)
 	if err != nil {
 		logger.WithError(err).Error("Failed to create handler")
 		panic(err)
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// 沒有指定日期時統計最近幾天（不含今天，今天的用量還在累加）
+	defaultReportDays = 7
+	// 一次最多統計幾天，token 用量只保留 90 天
+	maxReportDays = 90
+	// 沒有指定時列出費用最高的用戶數
+	defaultTopUsers = 20
+)
+
+// ReportEvent 手動 invoke 時的 payload，所有欄位皆可省略，例如：
+//
+//	aws lambda invoke --function-name language-usage-report --payload '{"from":"2026-10-01","to":"2026-10-07","top":10}' out.json
+//
+// 日期為 UTC 的 YYYY-MM-DD，包含 from 與 to 當天
+type ReportEvent struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Top  int    `json:"top"`
+}
+
+// Handler summarizes the recorded OpenAI token usage into a cost report. Holds no mutable state; safe for
+// concurrent use
+type Handler struct {
+	logger    *logrus.Entry
+	envVars   *EnvVars
+	usageRepo utils.UsageRepository
+	now       func() time.Time
+}
+
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, usageRepo utils.UsageRepository) (*Handler, error) {
+	return &Handler{
+		logger:    logger,
+		envVars:   envVars,
+		usageRepo: usageRepo,
+		now:       time.Now,
+	}, nil
+}
+
+// EventHandler 彙整期間內各功能的費用與費用最高的用戶，回傳 utils.TokenUsageReport 並寫入 log
+func (h *Handler) EventHandler(ctx context.Context, event ReportEvent) (*utils.TokenUsageReport, error) {
+	from, to, err := h.reportRange(event)
+	if err != nil {
+		return nil, err
+	}
+	top := event.Top
+	if top <= 0 {
+		top = defaultTopUsers
+	}
+
+	var usages []models.TokenUsage
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		daily, err := h.usageRepo.GetTokenUsage(date.Format("2006-01-02"))
+		if err != nil {
+			h.logger.WithError(err).WithField("date", date.Format("2006-01-02")).Error("Failed to load token usage")
+			return nil, err
+		}
+		usages = append(usages, daily...)
+	}
+
+	report := utils.SummarizeTokenUsage(from.Format("2006-01-02"), to.Format("2006-01-02"), usages, top)
+	h.logger.WithFields(logrus.Fields{
+		"from":     report.From,
+		"to":       report.To,
+		"total":    report.Total,
+		"features": report.Features,
+		"topUsers": report.TopUsers,
+	}).Info("Token usage report")
+	return &report, nil
+}
+
+// reportRange 解析報表期間；沒有指定時為昨天以前的 defaultReportDays 天
+func (h *Handler) reportRange(event ReportEvent) (time.Time, time.Time, error) {
+	today := h.now().UTC().Truncate(24 * time.Hour)
+	to := today.AddDate(0, 0, -1)
+	if event.To != "" {
+		parsed, err := time.Parse("2006-01-02", event.To)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date %q: %w", event.To, err)
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(defaultReportDays - 1))
+	if event.From != "" {
+		parsed, err := time.Parse("2006-01-02", event.From)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date %q: %w", event.From, err)
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from %s is after to %s", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxReportDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("report covers %d days, at most %d are kept", days, maxReportDays)
+	}
+	return from, to, nil
+}
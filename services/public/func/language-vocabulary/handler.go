@@ -1,55 +1,110 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"language-assistant/internal/courses"
+	"language-assistant/internal/messages"
+	"language-assistant/internal/models"
 	"language-assistant/internal/utils"
+	"math/rand"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+	schedulertypes "github.com/aws/aws-sdk-go-v2/service/scheduler/types"
+	"github.com/line/line-bot-sdk-go/v7/linebot"
 	"github.com/sirupsen/logrus"
 )
 
+// wordTextSeparator 文字版每日單字中各單字之間的分隔
+const wordTextSeparator = "\n\n"
+
+// scheduledPushClaimTTL 每日推播登記保留的時間，涵蓋各時區的同一天
+const scheduledPushClaimTTL = 48 * time.Hour
+
+// Handler pushes the daily words. Safe for concurrent use: dependencies are read-only after NewHandler
+// and rnd is a locked source; per-push state stays on the stack
 type Handler struct {
-	logger          *logrus.Entry
-	envVars         *EnvVars
-	openaiClient    utils.OpenaiAPI
-	linebotClient   utils.LinebotAPI
-	userConfigRepo  utils.UserConfigRepository
-	bloomFilterRepo utils.BloomFilterRepository
+	logger            *logrus.Entry
+	envVars           *EnvVars
+	openaiClient      utils.OpenaiAPI
+	lineClients       *utils.LineClients // 依用戶加入的官方帳號選擇推播的 channel
+	userConfigRepo    utils.UserConfigRepository
+	bloomFilterRepo   utils.BloomFilterRepository
+	wordHistoryRepo   utils.WordHistoryRepository
+	pushLogRepo       utils.PushLogRepository
+	media             *utils.MediaService
+	audioCache        *utils.AudioCache
+	ttsClient         utils.TTSAPI
+	cardRenderer      *utils.WordCardRenderer // nil 表示不支援圖片字卡
+	experimentRepo    utils.CardFormatExperimentRepository
+	failureReporter   *utils.FailureReporter
+	scheduleAuditRepo utils.ScheduleAuditRepository
+	schedulerClient   utils.SchedulerAPI
+	featureGate       *utils.FeatureGate
+	killSwitches      *utils.KillSwitchCache
+	tokenUsage        *utils.TokenUsageRecorder
+	contentReportRepo utils.ContentReportRepository
+	rnd               *rand.Rand
 }
 
-func NewHandler(logger *logrus.Entry, envVars *EnvVars, openaiClient utils.OpenaiAPI, linebotClient utils.LinebotAPI, userConfigRepo utils.UserConfigRepository, bloomFilterRepo utils.BloomFilterRepository) (*Handler, error) {
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, openaiClient utils.OpenaiAPI, lineClients *utils.LineClients, userConfigRepo utils.UserConfigRepository, bloomFilterRepo utils.BloomFilterRepository, wordHistoryRepo utils.WordHistoryRepository, pushLogRepo utils.PushLogRepository, media *utils.MediaService, audioCache *utils.AudioCache, ttsClient utils.TTSAPI, cardRenderer *utils.WordCardRenderer, experimentRepo utils.CardFormatExperimentRepository, failureReporter *utils.FailureReporter, scheduleAuditRepo utils.ScheduleAuditRepository, schedulerClient utils.SchedulerAPI, featureGate *utils.FeatureGate, killSwitches *utils.KillSwitchCache, tokenUsage *utils.TokenUsageRecorder, contentReportRepo utils.ContentReportRepository) (*Handler, error) {
 	return &Handler{
-		logger:          logger,
-		envVars:         envVars,
-		openaiClient:    openaiClient,
-		linebotClient:   linebotClient,
-		userConfigRepo:  userConfigRepo,
-		bloomFilterRepo: bloomFilterRepo,
+		logger:            logger,
+		envVars:           envVars,
+		openaiClient:      openaiClient,
+		lineClients:       lineClients,
+		userConfigRepo:    userConfigRepo,
+		bloomFilterRepo:   bloomFilterRepo,
+		wordHistoryRepo:   wordHistoryRepo,
+		pushLogRepo:       pushLogRepo,
+		media:             media,
+		audioCache:        audioCache,
+		ttsClient:         ttsClient,
+		cardRenderer:      cardRenderer,
+		experimentRepo:    experimentRepo,
+		failureReporter:   failureReporter,
+		scheduleAuditRepo: scheduleAuditRepo,
+		schedulerClient:   schedulerClient,
+		featureGate:       featureGate,
+		killSwitches:      killSwitches,
+		tokenUsage:        tokenUsage,
+		contentReportRepo: contentReportRepo,
+		rnd:               utils.NewConcurrentRand(time.Now().UnixNano()),
 	}, nil
 }
 
-type WordPushRequest struct {
-	UserID string `json:"userId"`
-}
-
 type WordPushResponse struct {
 	Status  string      `json:"status"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// HandleWordPush 處理 Lambda invoke 的請求
-func (h *Handler) HandleWordPush(request map[string]string) (map[string]interface{}, error) {
+// HandleWordPush 處理 Lambda invoke 的請求，payload 格式見 models.WordPushPayload
+func (h *Handler) HandleWordPush(rawPayload []byte, correlationID string) (map[string]interface{}, error) {
 	h.logger.Info("Received direct word push request")
 
-	userID := request["userId"]
-	if userID == "" {
-		h.logger.Error("User ID is required")
+	payload, err := utils.ParseWordPushPayload(rawPayload)
+	if err != nil {
+		h.logger.WithError(err).WithField("payload", string(rawPayload)).Error("Invalid word push payload")
 		return map[string]interface{}{
 			"status":  "error",
-			"message": "User ID is required",
+			"message": err.Error(),
 		}, nil
 	}
+	userID := payload.UserID
+	h.logger.WithFields(logrus.Fields{
+		"userId":    userID,
+		"version":   payload.Version,
+		"source":    payload.Source,
+		"dryRun":    payload.DryRun,
+		"wordCount": payload.WordCount,
+	}).Info("Parsed word push payload")
 
 	// Get user configuration
 	userConfig, err := h.userConfigRepo.GetUserConfig(userID)
@@ -68,35 +123,212 @@ func (h *Handler) HandleWordPush(request map[string]string) (map[string]interfac
 			"message": "User configuration not found",
 		}, nil
 	}
+	if userConfig.DeactivatedAt != "" {
+		// 排程應已在取消追蹤時刪除，保險起見不推播給已封鎖的用戶
+		h.logger.WithField("userId", userID).Warn("Skipping push for deactivated user")
+		return map[string]interface{}{
+			"status":  "skipped",
+			"message": "User is deactivated",
+		}, nil
+	}
+
+	if userConfig.TranslationOnly {
+		// 只用翻譯的用戶不推播，也不提醒完成設定
+		h.logger.WithField("userId", userID).Warn("Skipping push for translation-only user")
+		if !payload.DryRun {
+			if err := h.disableSchedule(userID, "translation only"); err != nil {
+				h.logger.WithError(err).WithField("userId", userID).Error("Failed to disable daily push schedule")
+			}
+		}
+		return map[string]interface{}{
+			"status":  "skipped",
+			"message": "User uses translation only",
+		}, nil
+	}
+
+	linebotClient := h.lineClients.ClientFor(userConfig)
+
+	// 維運人員關閉每日推播時不產生單字，改推播維護中的說明；排程保持不變，恢復後自動繼續推播
+	if h.killSwitches.Disabled(models.KillSwitchDailyPush) {
+		if !payload.DryRun {
+			if err := linebotClient.PushMessage(userID, utils.MaintenanceMessage(models.KillSwitchDailyPush)); err != nil {
+				h.logger.WithError(err).WithField("userId", userID).Warn("Failed to send daily push maintenance message")
+			}
+		}
+		return map[string]interface{}{
+			"status":  "skipped",
+			"message": "Daily push is disabled by kill switch",
+		}, nil
+	}
+
+	// 排程存在但設定不完整（例如中途更換課程）時，提醒用戶完成設定並暫停排程，避免每天都失敗
+	if step := utils.MissingSetupStep(userConfig); step != "" {
+		if !payload.DryRun {
+			h.handleIncompleteSetup(userConfig, step)
+		}
+		return map[string]interface{}{
+			"status":  "skipped",
+			"message": "User setup is incomplete",
+			"data": map[string]interface{}{
+				"userId":      userID,
+				"missingStep": step,
+			},
+		}, nil
+	}
+
+	// 上次推播因封鎖失敗時，先用免費的 GetProfile 確認用戶仍可觸及，避免為收不到的用戶產生單字
+	if userConfig.BlockedPushes > 0 && !payload.DryRun {
+		if _, err := linebotClient.GetProfile(userID); utils.IsUserUnreachable(err) {
+			h.handleBlockedPush(userConfig, err)
+			return map[string]interface{}{
+				"status":  "skipped",
+				"message": "User has blocked the bot",
+			}, nil
+		}
+	}
+
+	// 個人排程與 dispatcher 同一天只推播一次，避免尚未刪除的舊排程造成重複推播
+	claimedDate := ""
+	if !payload.DryRun && (payload.Source == models.WordPushSourceSchedule || payload.Source == models.WordPushSourceDispatcher) {
+		date := userLocalDate(userConfig, time.Now())
+		claimed, err := h.pushLogRepo.ClaimScheduledPush(userID, date, time.Now().Add(scheduledPushClaimTTL).Unix())
+		switch {
+		case err != nil:
+			h.logger.WithError(err).WithField("userId", userID).Warn("Failed to claim scheduled push") // Non-critical error
+		case !claimed:
+			h.logger.WithFields(logrus.Fields{
+				"userId": userID,
+				"source": payload.Source,
+				"date":   date,
+			}).Warn("Skipping duplicate scheduled push")
+			return map[string]interface{}{
+				"status":  "skipped",
+				"message": "Scheduled push already sent today",
+			}, nil
+		default:
+			claimedDate = date
+		}
+	}
+
+	wordCount := userConfig.DailyWords
+	if payload.WordCount > 0 {
+		wordCount = payload.WordCount
+	}
+
 	h.logger.WithFields(logrus.Fields{
 		"userId":     userID,
 		"userName":   userConfig.DisplayName,
 		"course":     userConfig.Course,
 		"level":      userConfig.Level,
 		"dailyWords": userConfig.DailyWords,
+		"wordCount":  wordCount,
+		"cardFormat": userConfig.CardFormat,
+		"dryRun":     payload.DryRun,
 	}).Info("Push words started")
 
-	// Generate words based on user configuration with Bloom Filter
-	words, err := h.generateWordsWithBloomFilter(userID, userConfig.Course, userConfig.DailyWords, userConfig.Level)
+	// Generate words based on user configuration, skipping words already pushed.
+	// 選擇「複習」的用戶程度提升後，較低級距推播過的單字可以再次出現
+	band := utils.LevelBand(userConfig.Course, userConfig.Level)
+	minBand := ""
+	if userConfig.LowerBandWords == models.LowerBandWordsReview {
+		minBand = band
+	}
+	target := utils.TargetCEFR(userConfig.Course, userConfig.Level, userConfig.DifficultyOffset)
+	words, err := h.generateNewWords(userID, userConfig.Course, wordCount, userConfig.Level, userConfig.Model, minBand, target)
+	if utils.IsRateLimited(err) {
+		// OpenAI 暫時限流不是用戶個別的問題，記錄推播失敗但不開 support ticket
+		h.logger.WithError(err).Warn("OpenAI rate limited word generation")
+		if !payload.DryRun {
+			h.recordPushLog(userConfig, nil, "", "", false, err)
+			h.releaseScheduledPush(userID, claimedDate)
+		}
+		return map[string]interface{}{
+			"status":  "error",
+			"message": "OpenAI rate limited",
+		}, nil
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to generate words")
+		if !payload.DryRun {
+			h.recordPushLog(userConfig, nil, "", "", false, err)
+			h.releaseScheduledPush(userID, claimedDate)
+			h.failureReporter.Report(userID, models.FailurePush, correlationID, err)
+		}
 		return map[string]interface{}{
 			"status":  "error",
 			"message": "Failed to generate words",
 		}, nil
 	}
 
+	if payload.DryRun {
+		h.logger.WithFields(logrus.Fields{
+			"userId": userID,
+			"course": userConfig.Course,
+			"count":  len(words),
+		}).Info("Dry run, generated words without pushing")
+		return map[string]interface{}{
+			"status":  "success",
+			"message": "Dry run, words not sent",
+			"data": map[string]interface{}{
+				"userId":    userID,
+				"course":    userConfig.Course,
+				"wordCount": len(words),
+				"words":     words,
+			},
+		}, nil
+	}
+
+	// 尚未選擇字卡格式的用戶隨機分派文字或圖片，比較兩種格式的互動率
+	format, experiment := utils.PickCardFormat(userConfig.CardFormat, h.cardRenderer != nil, h.rnd)
+
 	// Send words to user via LINE Bot
-	err = h.sendWordsToUser(userID, words, userConfig.Course)
+	message, format, err := h.sendWordsToUser(linebotClient, userID, words, userConfig.Course, format, experiment)
+	h.recordPushLog(userConfig, words, message, format, experiment, err)
+	if err != nil {
+		h.releaseScheduledPush(userID, claimedDate)
+	}
+	if err != nil && utils.ConfirmUserUnreachable(linebotClient, userID, err) {
+		// 用戶封鎖不是系統錯誤，不開立 support ticket
+		h.handleBlockedPush(userConfig, err)
+		return map[string]interface{}{
+			"status":  "skipped",
+			"message": "User has blocked the bot",
+		}, nil
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to send words to user")
+		h.failureReporter.Report(userID, models.FailurePush, correlationID, err)
 		return map[string]interface{}{
 			"status":  "error",
 			"message": "Failed to send words to user",
 		}, nil
 	}
 
-	// Add sent words to Bloom Filter
+	if experiment {
+		if err := h.experimentRepo.RecordCardFormatPush(time.Now().UTC().Format("2006-01-02"), format); err != nil {
+			h.logger.WithError(err).Warn("Failed to record card format push") // Non-critical error
+		}
+	}
+
+	if userConfig.BlockedPushes > 0 {
+		if err := h.userConfigRepo.ResetBlockedPushes(userID); err != nil {
+			h.logger.WithError(err).Warn("Failed to reset blocked pushes") // Non-critical error
+		}
+	}
+
+	// 發音關閉維護時只略過音檔，單字推播照常
+	if userConfig.PronunciationAudio && h.audioAllowed(*userConfig) && !h.killSwitches.Disabled(models.KillSwitchTTS) {
+		h.pushPronunciationAudio(linebotClient, userID, words)
+	}
+
+	// 記錄到單字歷史供精確比對；bloom filter 仍持續更新，歷史過大時作為備援
+	pushedWords := make([]string, 0, len(words))
+	for _, word := range words {
+		pushedWords = append(pushedWords, word.Word)
+	}
+	if err := h.wordHistoryRepo.RecordPushedWords(userID, userConfig.Course, band, pushedWords, time.Now()); err != nil {
+		h.logger.WithError(err).Warn("Failed to record word history") // Non-critical error
+	}
 	err = h.bloomFilterRepo.AddWordsToBloomFilter(userID, userConfig.Course, words)
 	if err != nil {
 		h.logger.WithError(err).Warn("Failed to add words to bloom filter") // Non-critical error
@@ -119,41 +351,244 @@ func (h *Handler) HandleWordPush(request map[string]string) (map[string]interfac
 	}, nil
 }
 
-func (h *Handler) generateWords(course string, wordCount int, level int) ([]utils.Word, error) {
-	wordResponse, err := h.openaiClient.GenerateWord(course, wordCount, level)
+// HandleWordPushEvent 處理 utils.ParseWordPushEvent 解析後的 event：直接 invoke 與 EventBridge 事件只有一個 payload，
+// 回傳該次推播的結果；fan-out 模式由 SQS 送來的批次逐則推播。
+// 個別推播失敗已由 HandleWordPush 記錄並回報，不回傳錯誤讓 SQS 重送以免重複推播；
+// 只有 Lambda 逾時或當機時訊息才會重送，超過重送次數進入 DLQ
+func (h *Handler) HandleWordPushEvent(event utils.WordPushEvent, correlationID string) (map[string]interface{}, error) {
+	if event.Kind != utils.WordPushEventSQS {
+		h.logger.WithField("kind", event.Kind).Info("Received word push event")
+		return h.HandleWordPush(event.Records[0].Payload, correlationID)
+	}
+
+	results := make([]map[string]interface{}, 0, len(event.Records))
+	for _, record := range event.Records {
+		h.logger.WithField("messageId", record.MessageID).Info("Received queued word push")
+		result, err := h.HandleWordPush(record.Payload, correlationID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return map[string]interface{}{
+		"status": "success",
+		"data":   results,
+	}, nil
+}
+
+// handleIncompleteSetup 推播「請完成設定」提醒並停用每日排程；用戶完成設定時 language-handler 會重新建立排程
+func (h *Handler) handleIncompleteSetup(userConfig *models.UserConfig, step string) {
+	logger := h.logger.WithFields(logrus.Fields{"userId": userConfig.UserID, "missingStep": step})
+	logger.Warn("User setup incomplete, pausing daily push")
+
+	var nudge []linebot.SendingMessage
+	switch step {
+	case models.InteractionCourseSelected:
+		nudge = messages.Build(messages.SetupNudgeCourse, nil)
+	case models.InteractionScoreSet:
+		course, _ := courses.Get(userConfig.Course)
+		nudge = messages.Build(messages.SetupNudgeScore, messages.Data{
+			"CourseName":   courses.DisplayName(userConfig.Course),
+			"ScoreRange":   course.ScoreRange(),
+			"ScoreExample": course.ScoreExample,
+			"NamedLevels":  course.NamedLevels(),
+		})
+		if last, ok := nudge[len(nudge)-1].(*linebot.TextMessage); ok && course.NamedLevels() {
+			nudge[len(nudge)-1] = last.WithQuickReplies(messages.LevelReplies(course.Levels))
+		}
+	default:
+		nudge = messages.Build(messages.SetupNudgePush, nil)
+	}
+	if err := h.lineClients.ClientFor(userConfig).PushMessages(userConfig.UserID, nudge...); err != nil {
+		logger.WithError(err).Error("Failed to push setup nudge")
+	}
+
+	if err := h.disableSchedule(userConfig.UserID, "setup incomplete: "+step); err != nil {
+		logger.WithError(err).Error("Failed to disable daily push schedule")
+	}
+}
+
+// handleBlockedPush 記錄一次因用戶封鎖而失敗的推播，連續達 utils.MaxBlockedPushes 次時停用排程並將用戶標記為停用；
+// 用戶重新加入好友時 language-handler 會清除標記並重建排程
+func (h *Handler) handleBlockedPush(userConfig *models.UserConfig, pushErr error) {
+	userID := userConfig.UserID
+	count, err := h.userConfigRepo.RecordBlockedPush(userID)
+	if err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Error("Failed to record blocked push")
+		return
+	}
+
+	logger := h.logger.WithError(pushErr).WithFields(logrus.Fields{"userId": userID, "blockedPushes": count})
+	if count < utils.MaxBlockedPushes {
+		logger.Warn("Push failed because the user is unreachable")
+		return
+	}
+
+	logger.Warn("User unreachable for consecutive pushes, disabling schedule")
+	if err := h.disableSchedule(userID, "user blocked bot"); err != nil {
+		logger.WithError(err).Error("Failed to disable schedule for blocked user")
+	}
+	if err := h.userConfigRepo.DeactivateUser(userID, time.Now()); err != nil {
+		logger.WithError(err).Error("Failed to deactivate blocked user")
+	}
+}
+
+// disableSchedule 將用戶的每日排程改為 DISABLED；UpdateSchedule 會覆寫所有欄位，因此先讀取現有設定再帶回去
+func (h *Handler) disableSchedule(userID, reason string) error {
+	names := []string{utils.ScheduleName(userID)}
+	if legacyName := utils.LegacyScheduleName(userID); legacyName != "" {
+		names = append(names, legacyName)
+	}
+
+	for _, name := range names {
+		schedule, err := h.schedulerClient.GetSchedule(context.TODO(), &scheduler.GetScheduleInput{
+			Name:      aws.String(name),
+			GroupName: aws.String(utils.ScheduleGroupName),
+		})
+		var notFound *schedulertypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get schedule: %w", err)
+		}
+		if schedule.State == schedulertypes.ScheduleStateDisabled {
+			return nil
+		}
+
+		_, err = h.schedulerClient.UpdateSchedule(context.TODO(), &scheduler.UpdateScheduleInput{
+			Name:                       schedule.Name,
+			GroupName:                  schedule.GroupName,
+			ScheduleExpression:         schedule.ScheduleExpression,
+			ScheduleExpressionTimezone: schedule.ScheduleExpressionTimezone,
+			FlexibleTimeWindow:         schedule.FlexibleTimeWindow,
+			Target:                     schedule.Target,
+			Description:                schedule.Description,
+			StartDate:                  schedule.StartDate,
+			EndDate:                    schedule.EndDate,
+			KmsKeyArn:                  schedule.KmsKeyArn,
+			ActionAfterCompletion:      schedule.ActionAfterCompletion,
+			State:                      schedulertypes.ScheduleStateDisabled,
+		})
+		h.auditScheduleOperation(userID, name, reason, err)
+		if err != nil {
+			return fmt.Errorf("failed to disable schedule: %w", err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// auditScheduleOperation 將排程停用寫入稽核紀錄，寫入失敗不影響主要流程
+func (h *Handler) auditScheduleOperation(userID, scheduleName, reason string, opErr error) {
+	entry := models.ScheduleAuditEntry{
+		UserID:       userID,
+		ScheduleName: scheduleName,
+		Operation:    models.ScheduleOperationDisable,
+		Actor:        "system:language-vocabulary",
+		Reason:       reason,
+		Result:       models.ScheduleResultSuccess,
+	}
+	if opErr != nil {
+		entry.Result = models.ScheduleResultFailure
+		entry.Error = opErr.Error()
+	}
+
+	if err := h.scheduleAuditRepo.RecordScheduleOperation(entry); err != nil {
+		h.logger.WithError(err).WithField("userID", userID).Warn("Failed to record schedule audit entry")
+	}
+}
+
+// audioAllowed 發音是付費功能，只推給支持者方案或試用中的用戶；試用到期但尚未被 reminder 關閉發音的用戶也不再推播。
+// 服務條款更新後，用戶同意新版本前也暫停推播發音。檢查失敗時照常推播
+func (h *Handler) audioAllowed(userConfig models.UserConfig) bool {
+	if !h.envVars.terms.Accepted(userConfig) {
+		h.logger.WithField("userId", userConfig.UserID).Info("Terms not accepted, skipping pronunciation audio")
+		return false
+	}
+	allowed, err := h.featureGate.Allowed(userConfig, models.PremiumFeatureAudio)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to check pronunciation audio access, pushing audio")
+		return true
+	}
+	return allowed
+}
+
+// pushPronunciationAudio 在單字推播後依序推送每個單字的發音；發音是附加內容，失敗只記錄 log 不影響推播結果
+func (h *Handler) pushPronunciationAudio(linebotClient utils.LinebotAPI, userID string, words []utils.Word) {
+	for _, word := range words {
+		url, cached, err := h.audioCache.GetOrSynthesize(word.Word, h.envVars.ttsVoice, h.ttsClient.Synthesize)
+		if err != nil {
+			h.logger.WithError(err).WithField("word", word.Word).Warn("Failed to get pronunciation audio")
+			continue
+		}
+		if err := linebotClient.PushAudioMessage(userID, url, utils.EstimateSpeechDuration(word.Word)); err != nil {
+			// LINE 推播失敗（例如額度用完）時後面的單字也會失敗，直接停止
+			h.logger.WithError(err).Warn("Failed to push pronunciation audio")
+			return
+		}
+		h.logger.WithFields(logrus.Fields{"word": word.Word, "cached": cached}).Debug("Pushed pronunciation audio")
+	}
+}
+
+func (h *Handler) generateWords(userID, course string, wordCount int, level int, model, target string) ([]utils.Word, error) {
+	wordResponse, err := h.openaiClient.GenerateWord(utils.GenerateWordOptions{Course: course, WordCount: wordCount, Level: level, Model: model, Target: target})
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate words: %w", err)
 	}
+	h.tokenUsage.Record(userID, utils.FeatureWordGeneration, wordResponse.Usage)
 
 	return wordResponse.Words, nil
 }
 
-func (h *Handler) generateWordsWithBloomFilter(userID, course string, wordCount int, level int) ([]utils.Word, error) {
+// generateNewWords 生成 wordCount 個沒推播過、難度在 target（CEFR）附近的單字；難度超出範圍的單字會重新生成，
+// 重試後仍不足時才用來補足數量
+func (h *Handler) generateNewWords(userID, course string, wordCount int, level int, model, minBand, target string) ([]utils.Word, error) {
+	filterWords, err := h.pushedWordFilter(userID, course, minBand)
+	if err != nil {
+		return nil, err
+	}
+	// 被回報、等待審核的單字暫停使用；讀取失敗時照常推播，不讓審核佇列影響每日單字
+	suppressed, err := h.contentReportRepo.GetSuppressedWords()
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to load suppressed words")
+	}
+
 	// Generate more words than needed to account for filtering
 	generateCount := wordCount * 3 // Generate 3x to account for duplicates
 	maxAttempts := 5
 
-	var finalWords []utils.Word
+	var finalWords, outOfBandWords []utils.Word
 
 	for attempt := 1; attempt <= maxAttempts && len(finalWords) < wordCount; attempt++ {
 		h.logger.Infof("Attempt %d to generate %d words for user %s", attempt, generateCount, userID)
 
 		// Generate words using OpenAI
-		words, err := h.generateWords(course, generateCount, level)
+		words, err := h.generateWords(userID, course, generateCount, level, model, target)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate words on attempt %d: %w", attempt, err)
 		}
 
 		h.logger.Infof("OpenAI returned %d words", len(words))
 
-		// Filter out words already in Bloom Filter
-		newWords, err := h.bloomFilterRepo.FilterWords(userID, course, words)
+		// Filter out words already pushed
+		newWords, err := filterWords(words)
 		if err != nil {
 			return nil, fmt.Errorf("failed to filter words: %w", err)
 		}
+		newWords = utils.FilterUnseenWords(newWords, suppressed)
+
+		inBand, outOfBand := utils.SplitByDifficulty(newWords, target)
+		outOfBandWords = append(outOfBandWords, outOfBand...)
+		if len(outOfBand) > 0 {
+			h.logger.WithFields(logrus.Fields{
+				"target":   target,
+				"rejected": len(outOfBand),
+			}).Info("Regenerating words outside the target difficulty")
+		}
 
 		// Add new words to our final list
-		for _, word := range newWords {
+		for _, word := range inBand {
 			if len(finalWords) < wordCount {
 				finalWords = append(finalWords, word)
 			} else {
@@ -161,8 +596,8 @@ func (h *Handler) generateWordsWithBloomFilter(userID, course string, wordCount
 			}
 		}
 
-		h.logger.Infof("Generated %d words, filtered to %d new words, total collected: %d/%d",
-			len(words), len(newWords), len(finalWords), wordCount)
+		h.logger.Infof("Generated %d words, filtered to %d new words (%d within difficulty), total collected: %d/%d",
+			len(words), len(newWords), len(inBand), len(finalWords), wordCount)
 
 		// If we have enough words, break early
 		if len(finalWords) >= wordCount {
@@ -173,6 +608,20 @@ func (h *Handler) generateWordsWithBloomFilter(userID, course string, wordCount
 		generateCount = wordCount * 5 // Increase more aggressively
 	}
 
+	// 重試後難度符合的單字仍不足時，以難度不符的新單字補足，推播數量優先於難度
+	if len(finalWords) < wordCount && len(outOfBandWords) > 0 {
+		picked := make(map[string]bool, len(finalWords))
+		for _, word := range finalWords {
+			picked[utils.NormalizeHistoryWord(word.Word)] = true
+		}
+		for _, word := range utils.FilterUnseenWords(outOfBandWords, picked) {
+			if len(finalWords) >= wordCount {
+				break
+			}
+			finalWords = append(finalWords, word)
+		}
+	}
+
 	if len(finalWords) == 0 {
 		return nil, fmt.Errorf("failed to generate any new words after %d attempts", maxAttempts)
 	}
@@ -182,47 +631,233 @@ func (h *Handler) generateWordsWithBloomFilter(userID, course string, wordCount
 		finalWords = finalWords[:wordCount]
 	}
 
+	// 檢查例句的中英文是否對應，不一致的重新生成（檢查規則只適用英文例句）
+	if exam, ok := courses.Get(course); !ok || exam.Language == courses.LanguageEnglish {
+		regenerated, errs := utils.ValidateWordExamples(h.openaiClient, finalWords)
+		for _, err := range errs {
+			h.logger.WithError(err).Warn("Failed to regenerate example sentence")
+		}
+		if regenerated > 0 {
+			h.logger.Infof("Regenerated %d mismatched example sentences", regenerated)
+		}
+	}
+
 	h.logger.Infof("Successfully generated %d unique words for user %s", len(finalWords), userID)
 	return finalWords, nil
 }
 
-func (h *Handler) sendWordsToUser(userID string, words []utils.Word, course string) error {
+// pushedWordFilter 回傳過濾已推播單字的函式。單字歷史不超過 MaxExactWordHistory 時精確比對（沒有 bloom filter 的誤判），
+// 否則退回 bloom filter，避免每次推播都讀取上千個 item（bloom filter 不區分級距，minBand 只對單字歷史有效）
+func (h *Handler) pushedWordFilter(userID, course, minBand string) (func([]utils.Word) ([]utils.Word, error), error) {
+	seen, complete, err := h.wordHistoryRepo.GetPushedWords(userID, course, minBand, utils.MaxExactWordHistory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load word history: %w", err)
+	}
+
+	if !complete {
+		h.logger.WithField("userId", userID).Info("Word history too large for exact check, falling back to bloom filter")
+		return func(words []utils.Word) ([]utils.Word, error) {
+			return h.bloomFilterRepo.FilterWords(userID, course, words)
+		}, nil
+	}
+
+	return func(words []utils.Word) ([]utils.Word, error) {
+		newWords := utils.FilterUnseenWords(words, seen)
+		// 標記為已選，之後的重試不會再挑到同一個單字
+		for _, word := range newWords {
+			seen[utils.NormalizeHistoryWord(word.Word)] = true
+		}
+		return newWords, nil
+	}, nil
+}
+
+// sendWordsToUser 推播單字給用戶，回傳實際推播的訊息內容（圖片字卡同樣回傳文字版本供推播紀錄使用）與實際使用的格式
+func (h *Handler) sendWordsToUser(linebotClient utils.LinebotAPI, userID string, words []utils.Word, course string, cardFormat models.CardFormat, experiment bool) (string, models.CardFormat, error) {
 	if len(words) == 0 {
-		return fmt.Errorf("no words to send")
+		return "", cardFormat, fmt.Errorf("no words to send")
+	}
+
+	header := messages.Render(messages.DailyPushHeader, messages.Data{"Course": course, "Count": len(words)})
+	texts := formatWordsText(header, words)
+	finalMessage := strings.Join(texts, wordTextSeparator)
+	sentAt := time.Now()
+
+	if cardFormat == models.CardFormatImage && h.cardRenderer != nil {
+		imageMessages, err := h.buildWordCardMessages(userID, words)
+		if err == nil {
+			// 「記住了」按鈕掛在最後一張字卡上
+			ack := withReportButtons(messages.WordAckReplies(utils.WordAckPostbackData(models.CardFormatImage, sentAt, experiment)), words)
+			sendingMessages := []linebot.SendingMessage{linebot.NewTextMessage(header)}
+			for i, m := range imageMessages {
+				if i == len(imageMessages)-1 {
+					sendingMessages = append(sendingMessages, m.WithQuickReplies(ack))
+					continue
+				}
+				sendingMessages = append(sendingMessages, m)
+			}
+			if err := utils.PushInBatches(linebotClient, userID, sendingMessages); err != nil {
+				return finalMessage, models.CardFormatImage, fmt.Errorf("failed to push word cards to user: %w", err)
+			}
+			return finalMessage, models.CardFormatImage, nil
+		}
+		// 圖片產生失敗時改用文字推播，確保用戶仍收到當日單字
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to build word cards, falling back to text")
 	}
 
-	var messages []string
-	messages = append(messages, fmt.Sprintf("📚 今日%s單字推播 (%d個)", course, len(words)))
-	messages = append(messages, "")
+	ack := messages.WordAckReplies(utils.WordAckPostbackData(models.CardFormatText, sentAt, experiment))
+	sent, err := h.pushWordCarousels(linebotClient, userID, header, words, ack)
+	if err == nil {
+		return finalMessage, models.CardFormatText, nil
+	}
+	if sent {
+		return finalMessage, models.CardFormatText, fmt.Errorf("failed to push flex message to user: %w", err)
+	}
+	// 第一則就推播失敗（例如內容不符 Flex 規格）時改用純文字，確保用戶仍收到當日單字
+	h.logger.WithError(err).WithField("userId", userID).Warn("Failed to push flex message, falling back to plain text")
 
-	for i, word := range words {
-		wordText := fmt.Sprintf("%d. 【%s】(%s)\n意思：%s\n例句：%s\n中文：%s",
-			i+1,
-			word.Word,
-			word.PartOfSpeech,
-			word.Meaning,
-			word.Example.En,
-			word.Example.Zh,
-		)
+	textMessages := messages.TextMessages(texts)
+	textMessages[len(textMessages)-1] = linebot.NewTextMessage(texts[len(texts)-1]).WithQuickReplies(withReportButtons(ack, words))
+	err = utils.PushInBatches(linebotClient, userID, textMessages)
+	if err != nil {
+		return finalMessage, models.CardFormatText, fmt.Errorf("failed to push message to user: %w", err)
+	}
 
-		if len(word.Synonyms) > 0 {
-			wordText += fmt.Sprintf("\n同義詞：%s", strings.Join(word.Synonyms, ", "))
+	return finalMessage, models.CardFormatText, nil
+}
+
+// maxQuickReplyButtons LINE 一則訊息最多可帶的 quick reply 按鈕數
+const maxQuickReplyButtons = 13
+
+// withReportButtons 圖片與純文字的每日單字沒有 Flex 字卡上的按鈕，在「記住了」之後附上每個單字的「回報問題」，
+// 超過 LINE 的按鈕上限時後面的單字不附上
+func withReportButtons(ack *linebot.QuickReplyItems, words []utils.Word) *linebot.QuickReplyItems {
+	var reportWords, reportData []string
+	for _, word := range words {
+		if data := utils.ContentReportPostbackData(models.ContentKindDailyWord, word.Word, word.PartOfSpeech, word.Meaning, word.Example.En, ""); data != "" {
+			reportWords = append(reportWords, word.Word)
+			reportData = append(reportData, data)
 		}
+	}
+
+	buttons := append([]*linebot.QuickReplyButton{}, ack.Items...)
+	buttons = append(buttons, messages.ContentReportButtons(reportWords, reportData)...)
+	if len(buttons) > maxQuickReplyButtons {
+		buttons = buttons[:maxQuickReplyButtons]
+	}
+	return linebot.NewQuickReplyItems(buttons...)
+}
 
-		if len(word.Antonyms) > 0 {
-			wordText += fmt.Sprintf("\n反義詞：%s", strings.Join(word.Antonyms, ", "))
+// pushWordCarousels 以 Flex carousel 推播每日單字，「記住了」按鈕掛在最後一則上。
+// sent 表示是否已有部分訊息送達，呼叫端據此決定能否改用純文字重送
+func (h *Handler) pushWordCarousels(linebotClient utils.LinebotAPI, userID, altText string, words []utils.Word, ack *linebot.QuickReplyItems) (bool, error) {
+	carousels := utils.BuildWordCarousels(words, utils.ReviewAddPostbackData)
+	for i, carousel := range carousels {
+		var quickReplies *linebot.QuickReplyItems
+		if i == len(carousels)-1 {
+			quickReplies = ack
 		}
+		if err := linebotClient.PushFlexMessage(userID, altText, carousel, quickReplies); err != nil {
+			return i > 0, err
+		}
+	}
+	return true, nil
+}
 
-		messages = append(messages, wordText)
-		messages = append(messages, "")
+// buildWordCardMessages 將每個單字繪製成圖片並上傳，回傳對應的 LINE 圖片訊息
+func (h *Handler) buildWordCardMessages(userID string, words []utils.Word) ([]*linebot.ImageMessage, error) {
+	// 以 userId 雜湊當路徑，避免在網址中暴露 LINE userId
+	hash := sha256.Sum256([]byte(userID))
+	prefix := fmt.Sprintf("cards/%s/%s", hex.EncodeToString(hash[:8]), time.Now().UTC().Format("20060102T150405"))
+
+	result := make([]*linebot.ImageMessage, 0, len(words))
+	for i, word := range words {
+		card, err := h.cardRenderer.Render(word, i+1, len(words))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render card for %q: %w", word.Word, err)
+		}
+
+		url, err := h.media.Publish(utils.MediaImage, fmt.Sprintf("%s/%d.png", prefix, i+1), card)
+		if err != nil {
+			return nil, fmt.Errorf("failed to publish card for %q: %w", word.Word, err)
+		}
+		result = append(result, linebot.NewImageMessage(url, url))
+	}
+
+	return result, nil
+}
+
+// formatWordsText 組合文字版每日單字訊息，內容超過 LINE 單則上限時在單字之間分成多則
+func formatWordsText(header string, words []utils.Word) []string {
+	parts := []string{header}
+	for i, word := range words {
+		wordText := messages.Render(messages.DailyPushWord, messages.Data{
+			"Index":           i + 1,
+			"Word":            word.Word,
+			"Reading":         utils.DisplayReading(word.Word, word.Reading),
+			"IPA":             utils.DisplayIPA(word.IPA),
+			"PartOfSpeech":    word.PartOfSpeech,
+			"Meaning":         word.Meaning,
+			"ExampleEn":       word.Example.En,
+			"ExampleZh":       word.Example.Zh,
+			"ExampleReading":  word.Example.Reading,
+			"Synonyms":        strings.Join(word.Synonyms, ", "),
+			"Antonyms":        strings.Join(word.Antonyms, ", "),
+			"CEFR":            utils.NormalizeCEFR(word.Difficulty),
+			"DifficultyEmoji": utils.DifficultyOf(word.Difficulty).Emoji(),
+			"ExamTags":        utils.FormatExamTags(word.ExamTags),
+		})
+		parts = append(parts, wordText)
 	}
 
-	finalMessage := strings.Join(messages, "\n")
+	return messages.Split(parts, wordTextSeparator)
+}
 
-	err := h.linebotClient.PushMessage(userID, finalMessage)
+// userLocalDate 回傳用戶時區的日期，時區無效時使用 UTC
+func userLocalDate(userConfig *models.UserConfig, now time.Time) string {
+	loc, err := time.LoadLocation(userConfig.Timezone)
 	if err != nil {
-		return fmt.Errorf("failed to push message to user: %w", err)
+		loc = time.UTC
 	}
+	return now.In(loc).Format("2006-01-02")
+}
 
-	return nil
+// releaseScheduledPush 推播失敗時取消當天的登記，讓下一次排程可以重新推播
+func (h *Handler) releaseScheduledPush(userID, date string) {
+	if date == "" {
+		return
+	}
+	if err := h.pushLogRepo.ReleaseScheduledPush(userID, date); err != nil {
+		h.logger.WithError(err).WithField("userId", userID).Warn("Failed to release scheduled push") // Non-critical error
+	}
+}
+
+// recordPushLog 記錄本次推播結果，寫入失敗不影響推播流程
+func (h *Handler) recordPushLog(userConfig *models.UserConfig, words []utils.Word, message string, cardFormat models.CardFormat, experiment bool, pushErr error) {
+	now := time.Now()
+
+	wordList := make([]string, 0, len(words))
+	for _, word := range words {
+		wordList = append(wordList, word.Word)
+	}
+
+	pushLog := models.PushLog{
+		UserID:     userConfig.UserID,
+		Date:       userLocalDate(userConfig, now), // 日期以用戶時區為準，方便用戶對照
+		PushedAt:   now.UTC().Format(time.RFC3339),
+		Course:     userConfig.Course,
+		WordCount:  len(words),
+		Status:     models.PushStatusDelivered,
+		Words:      wordList,
+		Message:    message,
+		CardFormat: cardFormat,
+		Experiment: experiment,
+	}
+	if pushErr != nil {
+		pushLog.Status = models.PushStatusFailed
+		pushLog.Error = pushErr.Error()
+	}
+
+	if err := h.pushLogRepo.SavePushLog(pushLog); err != nil {
+		h.logger.WithError(err).WithField("userId", userConfig.UserID).Warn("Failed to save push log")
+	}
 }
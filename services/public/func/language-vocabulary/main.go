@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"language-assistant/internal/repository"
 	"language-assistant/internal/utils"
 	"os"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
 	"github.com/sirupsen/logrus"
 )
 
@@ -26,18 +31,37 @@ type EnvVars struct {
 	openaiApiKey        string
 	userTableName       string
 	vocabularyTableName string
-	channelToken        string
-	channelSecret       string
+	auditTableName      string
+	lineChannels        *utils.LineChannels // 各官方帳號的 channel，依用戶加入的帳號推播
+	generationParams    map[utils.OpenAIFeature]utils.GenerationParams
+	openaiRetry         utils.RetryPolicy
+	mediaBucketName     string
+	mediaURLExpiry      map[utils.MediaKind]time.Duration
+	cardFontPath        string // 未設定時不支援圖片字卡，一律以文字推播
+	ttsVoice            string // 單字發音使用的聲線
+	errorBudget         utils.ErrorBudget
+	faultInjector       *utils.FaultInjector // nil 表示不注入錯誤
+	fakeOpenAI          bool                 // true 時以 fake client 取代 OpenAI 與 TTS
+	parseSampleRate     float64              // OpenAI 回應解析失敗時保存原始輸出的比例
+	eventStreamName     string               // 推播事件串流到資料倉儲的 Firehose，空字串表示不串流
+	anonymizer          *utils.Anonymizer    // nil 表示分析資料保留原本的 userId
+	terms               *utils.Terms         // nil 表示不要求同意服務條款
 }
 
 func getEnvVars() (*EnvVars, error) {
+	// OPENAI_FAKE 開啟時改用離線的 fake client，不需要 OpenAI 連線設定
+	fakeOpenAI, err := utils.LoadFakeOpenAI(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
 	openaiBaseUrl := os.Getenv("OPENAI_BASE_URL")
-	if openaiBaseUrl == "" {
+	if openaiBaseUrl == "" && !fakeOpenAI {
 		return nil, errors.New("OPENAI_BASE_URL is not set")
 	}
 
 	openaiApiKey := os.Getenv("OPENAI_API_KEY")
-	if openaiApiKey == "" {
+	if openaiApiKey == "" && !fakeOpenAI {
 		return nil, errors.New("OPENAI_API_KEY is not set")
 	}
 
@@ -51,14 +75,64 @@ func getEnvVars() (*EnvVars, error) {
 		return nil, errors.New("VOCABULARY_TABLE_NAME is not set")
 	}
 
-	channelToken := os.Getenv("CHANNEL_TOKEN")
-	if channelToken == "" {
-		return nil, errors.New("CHANNEL_TOKEN is not set")
+	auditTableName := os.Getenv("AUDIT_TABLE_NAME")
+	if auditTableName == "" {
+		return nil, errors.New("AUDIT_TABLE_NAME is not set")
+	}
+
+	lineChannels, err := utils.LoadLineChannels(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	generationParams, err := utils.LoadGenerationParams(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	openaiRetry, err := utils.LoadOpenAIRetryPolicy(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaBucketName := os.Getenv("MEDIA_BUCKET_NAME")
+	if mediaBucketName == "" {
+		return nil, errors.New("MEDIA_BUCKET_NAME is not set")
 	}
 
-	channelSecret := os.Getenv("CHANNEL_SECRET")
-	if channelSecret == "" {
-		return nil, errors.New("CHANNEL_SECRET is not set")
+	mediaURLExpiry, err := utils.LoadMediaURLExpiry(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	errorBudget, err := utils.LoadErrorBudget(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	faultInjector, err := utils.LoadFaultInjector(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	parseFailureSampleRate, err := utils.LoadParseFailureSampleRate(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	anonymizer, err := utils.LoadAnonymizer(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	terms, err := utils.LoadTerms(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	ttsVoice := os.Getenv("TTS_VOICE")
+	if ttsVoice == "" {
+		ttsVoice = utils.DefaultTTSVoice
 	}
 
 	return &EnvVars{
@@ -66,13 +140,29 @@ func getEnvVars() (*EnvVars, error) {
 		openaiApiKey:        openaiApiKey,
 		userTableName:       userTableName,
 		vocabularyTableName: vocabularyTableName,
-		channelToken:        channelToken,
-		channelSecret:       channelSecret,
+		auditTableName:      auditTableName,
+		lineChannels:        lineChannels,
+		generationParams:    generationParams,
+		openaiRetry:         openaiRetry,
+		mediaBucketName:     mediaBucketName,
+		mediaURLExpiry:      mediaURLExpiry,
+		cardFontPath:        os.Getenv("CARD_FONT_PATH"),
+		ttsVoice:            ttsVoice,
+		errorBudget:         errorBudget,
+		faultInjector:       faultInjector,
+		fakeOpenAI:          fakeOpenAI,
+		parseSampleRate:     parseFailureSampleRate,
+		eventStreamName:     os.Getenv("EVENT_STREAM_NAME"),
+		anonymizer:          anonymizer,
+		terms:               terms,
 	}, nil
 }
 
 var handler *Handler
 
+// invocationDeadline 讓 OpenAI 重試不超過本次 invoke 剩餘的時間
+var invocationDeadline = &utils.InvocationDeadline{}
+
 func init() {
 	logrus.SetFormatter(&logrus.JSONFormatter{
 		FieldMap: logrus.FieldMap{
@@ -88,6 +178,9 @@ func init() {
 		logger.WithError(err).Error("Failed to get environment variables")
 		panic(err)
 	}
+	if envVars.faultInjector != nil {
+		logger.WithField("faultInjection", os.Getenv("FAULT_INJECTION")).Warn("Fault injection enabled")
+	}
 
 	cfg, err := config.LoadDefaultConfig(context.Background())
 	if err != nil {
@@ -95,31 +188,122 @@ func init() {
 		panic(err)
 	}
 
-	dynamodbClient := dynamodb.NewFromConfig(cfg)
+	dynamodbClient := utils.WithDynamoDBFaults(dynamodb.NewFromConfig(cfg), envVars.faultInjector)
 
-	openaiClient, err := utils.NewOpenAIClient(envVars.openaiApiKey, envVars.openaiBaseUrl)
-	if err != nil {
+	schemas := []utils.TableSchema{
+		utils.VocabularyTableSchema(envVars.vocabularyTableName),
+		utils.UserTableSchema(envVars.userTableName),
+		utils.ScheduleAuditTableSchema(envVars.auditTableName),
+	}
+	if err := utils.ValidateTableSchemas(dynamodbClient, schemas...); err != nil {
+		logger.WithError(err).Error("DynamoDB table schema mismatch")
 		panic(err)
 	}
 
-	linebotClient, err := utils.NewLineBotClient(envVars.channelSecret, envVars.channelToken)
+	parseFailureRecorder := utils.NewParseFailureRecorder(logger, repository.NewParseFailureRepository(logger, dynamodbClient, envVars.vocabularyTableName), envVars.parseSampleRate)
+	var openaiClient utils.OpenaiAPI
+	if envVars.fakeOpenAI {
+		logger.Warn("OPENAI_FAKE enabled, using the offline fake OpenAI client")
+		openaiClient = utils.NewFakeOpenAIClient()
+	} else {
+		openaiClient, err = utils.NewOpenAIClient(envVars.openaiApiKey, envVars.openaiBaseUrl, envVars.generationParams, envVars.openaiRetry, invocationDeadline, parseFailureRecorder)
+		if err != nil {
+			panic(err)
+		}
+	}
+	openaiClient = utils.WithOpenAIFaults(openaiClient, envVars.faultInjector)
+
+	lineClients, err := utils.NewLineClients(envVars.lineChannels, func(client utils.LinebotAPI) utils.LinebotAPI {
+		return utils.WithLinebotFaults(client, envVars.faultInjector)
+	})
 	if err != nil {
 		panic(err)
 	}
 
 	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
 	bloomFilterRepo := repository.NewBloomFilterRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	wordHistoryRepo := repository.NewWordHistoryRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	var eventStream *utils.EventStream
+	if envVars.eventStreamName != "" {
+		eventStream = utils.NewEventStream(logger, firehose.NewFromConfig(cfg), envVars.eventStreamName, envVars.anonymizer)
+	}
+	consents := utils.NewConsentChecker(logger, userConfigRepo, utils.ConsentCacheTTL)
+	pushLogRepo := utils.StreamPushLogs(repository.NewPushLogRepository(logger, dynamodbClient, envVars.vocabularyTableName), eventStream, consents)
+	experimentRepo := repository.NewCardFormatExperimentRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	supportTicketRepo := repository.NewSupportTicketRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	failureReporter := utils.NewFailureReporter(logger, supportTicketRepo, envVars.errorBudget)
+	scheduleAuditRepo := repository.NewScheduleAuditRepository(logger, dynamodbClient, envVars.auditTableName)
+	schedulerClient := scheduler.NewFromConfig(cfg)
+
+	media := utils.NewMediaService(utils.NewS3Client(cfg, envVars.mediaBucketName), envVars.mediaURLExpiry)
+
+	audioCache := utils.NewAudioCache(media)
+	killSwitches := utils.NewKillSwitchCache(logger, repository.NewKillSwitchRepository(logger, dynamodbClient, envVars.vocabularyTableName), utils.KillSwitchCacheTTL)
+	tokenUsage := utils.NewTokenUsageRecorder(logger, repository.NewUsageRepository(logger, dynamodbClient, envVars.vocabularyTableName))
+	contentReportRepo := repository.NewContentReportRepository(logger, dynamodbClient, envVars.vocabularyTableName)
+	featureGate := utils.NewFeatureGate(repository.NewSubscriptionRepository(logger, dynamodbClient, envVars.vocabularyTableName), userConfigRepo)
+	var ttsClient utils.TTSAPI = utils.NewOpenAITTSClient(envVars.openaiApiKey, envVars.openaiBaseUrl)
+	if envVars.fakeOpenAI {
+		ttsClient = utils.FakeTTSClient{}
+	}
+
+	var cardRenderer *utils.WordCardRenderer
+	if envVars.cardFontPath != "" {
+		cardRenderer, err = utils.LoadWordCardRenderer(envVars.cardFontPath)
+		if err != nil {
+			// 字型載入失敗時退回文字推播，不影響每日推播
+			logger.WithError(err).Warn("Failed to load word card font, image cards disabled")
+			cardRenderer = nil
+		}
+	}
 
-	handler, err = NewHandler(logger, envVars, openaiClient, linebotClient, userConfigRepo, bloomFilterRepo)
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Tables(schemas...).
+		OpenAI(envVars.generationParams, envVars.openaiRetry, envVars.fakeOpenAI).
+		ErrorBudget(envVars.errorBudget).
+		Feature("faultInjection", envVars.faultInjector != nil).
+		Feature("imageCards", cardRenderer != nil).
+		Secret("channelSecret", envVars.lineChannels.Default.Secret).
+		Secret("channelToken", envVars.lineChannels.Default.Token).
+		Setting("lineChannels", envVars.lineChannels.Destinations()).
+		Secret("openaiApiKey", envVars.openaiApiKey).
+		Setting("ttsVoice", envVars.ttsVoice).
+		Setting("scheduleGroup", utils.ScheduleGroupName).
+		Setting("mediaBucket", envVars.mediaBucketName).
+		Setting("parseFailureSampleRate", envVars.parseSampleRate).
+		Setting("eventStream", envVars.eventStreamName).
+		Feature("anonymizedAnalytics", envVars.anonymizer != nil).
+		Setting("terms", envVars.terms).
+		Log(logger)
+
+	handler, err = NewHandler(logger, envVars, openaiClient, lineClients, userConfigRepo, bloomFilterRepo, wordHistoryRepo, pushLogRepo, media, audioCache, ttsClient, cardRenderer, experimentRepo, failureReporter, scheduleAuditRepo, schedulerClient, featureGate, killSwitches, tokenUsage, contentReportRepo)
 	if err != nil {
 		logger.WithError(err).Error("Failed to create handler")
 		panic(err)
 	}
 }
 
-// HandleRequest 處理直接 Lambda invoke（JSON payload）
-func HandleRequest(ctx context.Context, request map[string]string) (map[string]interface{}, error) {
-	return handler.HandleWordPush(request)
+// HandleRequest 處理排程、EventBridge 事件與直接 Lambda invoke（JSON payload），以及 fan-out 模式的 SQS 事件。
+// 以原始 JSON 接收，event 種類交給 utils.ParseWordPushEvent 判斷，版本與欄位驗證交給 utils.ParseWordPushPayload，
+// 避免 payload 欄位型別改變時 Lambda runtime 直接解析失敗
+func HandleRequest(ctx context.Context, request json.RawMessage) (map[string]interface{}, error) {
+	invocationDeadline.Set(ctx)
+
+	// 以 Lambda request ID 作為 correlation ID，開立 support ticket 時可對照 log
+	var correlationID string
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		correlationID = lc.AwsRequestID
+	}
+
+	event, err := utils.ParseWordPushEvent(request)
+	if err != nil {
+		handler.logger.WithError(err).Error("Invalid word push event")
+		return map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+		}, nil
+	}
+	return handler.HandleWordPushEvent(event, correlationID)
 }
 
 func main() {
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"language-assistant/internal/models"
+	"language-assistant/internal/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/sirupsen/logrus"
+)
+
+const webSessionValidity = 7 * 24 * time.Hour
+
+// Handler serves the web pairing pages. Holds no mutable state; safe for concurrent use
+type Handler struct {
+	logger         *logrus.Entry
+	envVars        *EnvVars
+	pairingRepo    utils.PairingRepository
+	userConfigRepo utils.UserConfigRepository
+}
+
+func NewHandler(logger *logrus.Entry, envVars *EnvVars, pairingRepo utils.PairingRepository, userConfigRepo utils.UserConfigRepository) (*Handler, error) {
+	return &Handler{
+		logger:         logger,
+		envVars:        envVars,
+		pairingRepo:    pairingRepo,
+		userConfigRepo: userConfigRepo,
+	}, nil
+}
+
+type routeKey struct {
+	method   string
+	resource string
+}
+
+// EventHandler 處理網頁版 API 請求，依照 method + resource 分派到對應的 handler
+func (h *Handler) EventHandler(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	h.logger.WithFields(logrus.Fields{
+		"method":   request.HTTPMethod,
+		"resource": request.Resource,
+	}).Info("Web API request")
+
+	routes := map[routeKey]func(events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error){
+		{http.MethodPost, "/web/pairing/token"}: h.handleExchangePairingCode,
+		{http.MethodGet, "/web/me"}:             h.handleGetMe,
+	}
+
+	route, ok := routes[routeKey{request.HTTPMethod, request.Resource}]
+	if !ok {
+		return h.jsonResponse(http.StatusNotFound, map[string]string{"error": "route not found"})
+	}
+	return route(request)
+}
+
+type pairingTokenRequest struct {
+	Code string `json:"code"`
+}
+
+// handleExchangePairingCode 以 LINE 上取得的配對代碼換取綁定該用戶的 session token
+func (h *Handler) handleExchangePairingCode(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var body pairingTokenRequest
+	if err := json.Unmarshal([]byte(request.Body), &body); err != nil || body.Code == "" {
+		return h.jsonResponse(http.StatusBadRequest, map[string]string{"error": "code is required"})
+	}
+
+	pairing, err := h.pairingRepo.ConsumePairingCode(utils.NormalizePairingCode(body.Code))
+	if err != nil {
+		return h.jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to verify pairing code"})
+	}
+	if pairing == nil {
+		return h.jsonResponse(http.StatusUnauthorized, map[string]string{"error": "invalid or expired pairing code"})
+	}
+
+	token, err := utils.GenerateSessionToken()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate session token")
+		return h.jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to create session"})
+	}
+
+	now := time.Now()
+	session := models.WebSession{
+		TokenHash: utils.HashSessionToken(token),
+		UserID:    pairing.UserID,
+		CreatedAt: now.UTC().Format(time.RFC3339),
+		ExpiresAt: now.Add(webSessionValidity).Unix(),
+	}
+	if err := h.pairingRepo.SaveWebSession(session); err != nil {
+		return h.jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to create session"})
+	}
+
+	h.logger.WithField("userID", pairing.UserID).Info("Web dashboard paired")
+
+	return h.jsonResponse(http.StatusOK, map[string]interface{}{
+		"token":     token,
+		"userId":    session.UserID,
+		"expiresAt": session.ExpiresAt,
+	})
+}
+
+// handleGetMe 回傳目前 session 綁定的用戶設定
+func (h *Handler) handleGetMe(request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	session, resp, ok := h.authenticate(request)
+	if !ok {
+		return resp, nil
+	}
+
+	userConfig, err := h.userConfigRepo.GetUserConfig(session.UserID)
+	if err != nil {
+		h.logger.WithError(err).WithField("userID", session.UserID).Error("Failed to get user config")
+		return h.jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to get user config"})
+	}
+
+	return h.jsonResponse(http.StatusOK, map[string]interface{}{
+		"userId": session.UserID,
+		"config": userConfig,
+	})
+}
+
+// authenticate 驗證 Authorization: Bearer <token>，失敗時回傳要直接回覆的 response
+func (h *Handler) authenticate(request events.APIGatewayProxyRequest) (*models.WebSession, events.APIGatewayProxyResponse, bool) {
+	header := request.Headers["Authorization"]
+	if header == "" {
+		header = request.Headers["authorization"]
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	if token == "" || token == header {
+		resp, _ := h.jsonResponse(http.StatusUnauthorized, map[string]string{"error": "missing bearer token"})
+		return nil, resp, false
+	}
+
+	session, err := h.pairingRepo.GetWebSession(utils.HashSessionToken(token))
+	if err != nil {
+		resp, _ := h.jsonResponse(http.StatusInternalServerError, map[string]string{"error": "failed to verify session"})
+		return nil, resp, false
+	}
+	if session == nil {
+		resp, _ := h.jsonResponse(http.StatusUnauthorized, map[string]string{"error": "invalid or expired session"})
+		return nil, resp, false
+	}
+
+	return session, events.APIGatewayProxyResponse{}, true
+}
+
+func (h *Handler) jsonResponse(statusCode int, body interface{}) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type":                 "application/json",
+		"Access-Control-Allow-Origin":  h.envVars.dashboardOrigin,
+		"Access-Control-Allow-Headers": "Content-Type,Authorization",
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    headers,
+			Body:       "Internal Server Error",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       string(payload),
+	}, nil
+}
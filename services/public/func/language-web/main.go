@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"language-assistant/internal/repository"
+	"language-assistant/internal/utils"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SEVERITY    = "severity"
+	MESSAGE     = "message"
+	TIMESTAMP   = "timestamp"
+	COMPONENT   = "component"
+	SERVICENAME = "language-web"
+)
+
+type EnvVars struct {
+	pairingTableName string
+	userTableName    string
+	dashboardOrigin  string
+}
+
+func getEnvironmentVariables() (envVars *EnvVars, err error) {
+	pairingTableName := os.Getenv("PAIRING_TABLE_NAME")
+	if pairingTableName == "" {
+		return nil, errors.New("PAIRING_TABLE_NAME is not set")
+	}
+
+	userTableName := os.Getenv("USER_TABLE_NAME")
+	if userTableName == "" {
+		return nil, errors.New("USER_TABLE_NAME is not set")
+	}
+
+	dashboardOrigin := os.Getenv("DASHBOARD_URL")
+	if dashboardOrigin == "" {
+		return nil, errors.New("DASHBOARD_URL is not set")
+	}
+
+	return &EnvVars{
+		pairingTableName: pairingTableName,
+		userTableName:    userTableName,
+		dashboardOrigin:  dashboardOrigin,
+	}, nil
+}
+
+func main() {
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  TIMESTAMP,
+			logrus.FieldKeyLevel: SEVERITY,
+			logrus.FieldKeyMsg:   MESSAGE,
+		},
+	})
+	logger := logrus.WithField(COMPONENT, SERVICENAME)
+
+	envVars, err := getEnvironmentVariables()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get environment variables")
+		panic(err)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		panic(err)
+	}
+	dynamodbClient := dynamodb.NewFromConfig(cfg)
+
+	pairingRepo := repository.NewPairingRepository(logger, dynamodbClient, envVars.pairingTableName)
+	userConfigRepo := repository.NewUserConfigRepository(logger, dynamodbClient, envVars.userTableName)
+
+	utils.NewStartupReport(SERVICENAME, os.Getenv).
+		Table("pairing", envVars.pairingTableName).
+		Table("user", envVars.userTableName).
+		Setting("dashboardOrigin", envVars.dashboardOrigin).
+		Log(logger)
+
+	handler, err := NewHandler(logger, envVars, pairingRepo, userConfigRepo)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create handler")
+		panic(err)
+	}
+
+	lambda.Start(handler.EventHandler)
+}